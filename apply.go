@@ -0,0 +1,116 @@
+package kubeaudit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// ApplyOptions configures how fixes are applied to the resources of a cluster
+type ApplyOptions = k8sinternal.ApplyOptions
+
+// AppliedFix is a resource of a cluster which was changed or created by the fixes of a report
+type AppliedFix struct {
+	// Original is the resource before the fixes were applied, or nil if the resource was created by a fix
+	Original k8s.Resource
+	// Fixed is the resource with the fixes applied, as it was sent to the API server
+	Fixed k8s.Resource
+	// Applied is the resource returned by the API server, or nil if applying the resource failed
+	Applied k8s.Resource
+	// Err is the error returned when applying the resource
+	Err error
+}
+
+// ApplyFixesCluster applies the fixes of a report created by AuditCluster to the cluster in which Kubeaudit is
+// running, using server-side apply. Only the resources changed or created by the fixes are applied. Errors applying
+// individual resources are returned in the AppliedFix of the resource
+func (r *Report) ApplyFixesCluster(options ApplyOptions) ([]AppliedFix, error) {
+	if !k8sinternal.IsRunningInCluster(k8sinternal.DefaultClient) {
+		return nil, errors.New("failed to apply fixes in cluster mode: not running in cluster")
+	}
+
+	client, err := k8sinternal.NewKubeClientCluster(k8sinternal.DefaultClient, options.ConnectionOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return applyFixes(client, r.RawResults(), options)
+}
+
+// ApplyFixesLocal applies the fixes of a report created by AuditLocal to the cluster of the provided Kubernetes config
+// file, using server-side apply. Only the resources changed or created by the fixes are applied. Errors applying
+// individual resources are returned in the AppliedFix of the resource
+func (r *Report) ApplyFixesLocal(configpath string, context string, options ApplyOptions) ([]AppliedFix, error) {
+	client, err := k8sinternal.NewKubeClientLocal(configpath, context, options.ConnectionOptions)
+	if err == k8sinternal.ErrNoReadableKubeConfig {
+		return nil, fmt.Errorf("failed to open kubeconfig file %s", configpath)
+	} else if err != nil {
+		return nil, err
+	}
+
+	return applyFixes(client, r.RawResults(), options)
+}
+
+func applyFixes(client k8sinternal.KubeClient, results []Result, options ApplyOptions) ([]AppliedFix, error) {
+	// Fixes can change other resources than the one they were reported for (eg. a NetworkPolicy of a namespace), so
+	// every resource is compared to its original to find the ones to apply
+	originals := make([]k8s.Resource, len(results))
+	for i, result := range results {
+		if resource := result.GetResource().Object(); resource != nil {
+			originals[i] = resource.DeepCopyObject()
+		}
+	}
+
+	var newResources []k8s.Resource
+	for _, result := range results {
+		newResources = append(newResources, fixResource(result)...)
+	}
+
+	var appliedFixes []AppliedFix
+	for i, result := range results {
+		fixed := result.GetResource().Object()
+		if fixed == nil {
+			continue
+		}
+
+		changed, err := isChanged(originals[i], fixed)
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			appliedFixes = append(appliedFixes, applyFix(client, originals[i], fixed, options))
+		}
+	}
+
+	for _, newResource := range newResources {
+		appliedFixes = append(appliedFixes, applyFix(client, nil, newResource, options))
+	}
+
+	return appliedFixes, nil
+}
+
+func applyFix(client k8sinternal.KubeClient, original, fixed k8s.Resource, options ApplyOptions) AppliedFix {
+	applied, err := client.Apply(fixed, options)
+	return AppliedFix{
+		Original: original,
+		Fixed:    fixed,
+		Applied:  applied,
+		Err:      err,
+	}
+}
+
+// isChanged returns true if the fixes changed the resource
+func isChanged(original, fixed k8s.Resource) (bool, error) {
+	originalContent, err := k8sinternal.ToUnstructured(original)
+	if err != nil {
+		return false, err
+	}
+	fixedContent, err := k8sinternal.ToUnstructured(fixed)
+	if err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(originalContent.Object, fixedContent.Object), nil
+}
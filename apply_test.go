@@ -0,0 +1,137 @@
+package kubeaudit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyFixes(t *testing.T) {
+	namespace := k8s.NewNamespace()
+	namespace.Name = "namespace"
+
+	networkPolicy := k8s.NewNetworkPolicy()
+	networkPolicy.Name = "networkpolicy"
+	networkPolicy.Namespace = "namespace"
+
+	hostNetworkPod := k8s.NewPod()
+	hostNetworkPod.Name = "host-network"
+	hostNetworkPod.Namespace = "namespace"
+	hostNetworkPod.Spec.HostNetwork = true
+
+	pod := k8s.NewPod()
+	pod.Name = "pod"
+	pod.Namespace = "namespace"
+
+	failingPod := k8s.NewPod()
+	failingPod.Name = "failing"
+	failingPod.Namespace = "namespace"
+	failingPod.Spec.HostNetwork = true
+
+	resources := []KubeResource{}
+	for _, resource := range []k8s.Resource{namespace, networkPolicy, hostNetworkPod, pod, failingPod} {
+		resources = append(resources, &kubeResource{object: resource})
+	}
+	results, err := auditResources(resources, []Auditable{&applyTestAuditor{}}, 1, nil, false)
+	require.NoError(t, err)
+
+	client := &applyTestClient{failing: "failing"}
+	appliedFixes, err := applyFixes(client, results, ApplyOptions{DryRun: true})
+	require.NoError(t, err)
+
+	// The NetworkPolicy is changed by the fix of the namespace, the pod without findings is not applied and the
+	// created NetworkPolicy is applied last
+	names := []string{}
+	for _, appliedFix := range appliedFixes {
+		names = append(names, k8s.GetObjectMeta(appliedFix.Fixed).GetName())
+	}
+	assert.Equal(t, []string{"networkpolicy", "host-network", "failing", "created"}, names)
+	assert.True(t, client.options.DryRun)
+
+	networkPolicyFix := appliedFixes[0]
+	assert.Empty(t, k8s.GetLabels(networkPolicyFix.Original))
+	assert.Equal(t, "true", k8s.GetObjectMeta(networkPolicyFix.Applied).GetLabels()["fixed"])
+
+	hostNetworkFix := appliedFixes[1]
+	require.NoError(t, hostNetworkFix.Err)
+	assert.True(t, hostNetworkFix.Original.(*k8s.PodV1).Spec.HostNetwork)
+	assert.False(t, hostNetworkFix.Applied.(*k8s.PodV1).Spec.HostNetwork)
+
+	failingFix := appliedFixes[2]
+	assert.Error(t, failingFix.Err)
+	assert.Nil(t, failingFix.Applied)
+
+	createdFix := appliedFixes[3]
+	assert.Nil(t, createdFix.Original)
+	assert.NotNil(t, createdFix.Applied)
+}
+
+// applyTestAuditor sets hostNetwork to false in pods, and labels the NetworkPolicies of namespaces and creates a new
+// one
+type applyTestAuditor struct{}
+
+func (a *applyTestAuditor) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*AuditResult, error) {
+	switch kubeType := resource.(type) {
+	case *k8s.PodV1:
+		if kubeType.Spec.HostNetwork {
+			return []*AuditResult{{Rule: "HostNetworkTrue", Severity: Error, PendingFix: &fixHostNetwork{pod: kubeType}}}, nil
+		}
+	case *k8s.NamespaceV1:
+		return []*AuditResult{{Rule: "NetworkPolicy", Severity: Error, PendingFix: &fixNetworkPolicies{resources: resources, namespace: kubeType.Name}}}, nil
+	}
+	return nil, nil
+}
+
+type fixHostNetwork struct {
+	pod *k8s.PodV1
+}
+
+func (f *fixHostNetwork) Plan() string {
+	return "Set hostNetwork to false"
+}
+
+func (f *fixHostNetwork) Apply(resource k8s.Resource) []k8s.Resource {
+	f.pod.Spec.HostNetwork = false
+	return nil
+}
+
+type fixNetworkPolicies struct {
+	resources []k8s.Resource
+	namespace string
+}
+
+func (f *fixNetworkPolicies) Plan() string {
+	return "Label the NetworkPolicies of the namespace and create a new one"
+}
+
+func (f *fixNetworkPolicies) Apply(resource k8s.Resource) []k8s.Resource {
+	for _, resource := range f.resources {
+		if networkPolicy, ok := resource.(*k8s.NetworkPolicyV1); ok {
+			networkPolicy.Labels = map[string]string{"fixed": "true"}
+		}
+	}
+
+	networkPolicy := k8s.NewNetworkPolicy()
+	networkPolicy.Name = "created"
+	networkPolicy.Namespace = f.namespace
+	return []k8s.Resource{networkPolicy}
+}
+
+// applyTestClient returns the applied resources as they are, except for the resource with the failing name
+type applyTestClient struct {
+	k8sinternal.KubeClient
+	failing string
+	options ApplyOptions
+}
+
+func (c *applyTestClient) Apply(resource k8s.Resource, options ApplyOptions) (k8s.Resource, error) {
+	c.options = options
+	if k8s.GetObjectMeta(resource).GetName() == c.failing {
+		return nil, errors.New("apply failed")
+	}
+	return resource.DeepCopyObject(), nil
+}
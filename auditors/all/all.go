@@ -5,98 +5,233 @@ import (
 	"fmt"
 
 	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/annotations"
 	"github.com/Shopify/kubeaudit/auditors/apparmor"
 	"github.com/Shopify/kubeaudit/auditors/asat"
 	"github.com/Shopify/kubeaudit/auditors/capabilities"
 	"github.com/Shopify/kubeaudit/auditors/deprecatedapis"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	"github.com/Shopify/kubeaudit/auditors/etcd"
+	"github.com/Shopify/kubeaudit/auditors/exposure"
+	"github.com/Shopify/kubeaudit/auditors/hostnet"
 	"github.com/Shopify/kubeaudit/auditors/hostns"
+	"github.com/Shopify/kubeaudit/auditors/hostprocess"
 	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	"github.com/Shopify/kubeaudit/auditors/labels"
+	"github.com/Shopify/kubeaudit/auditors/lifecycle"
 	"github.com/Shopify/kubeaudit/auditors/limits"
 	"github.com/Shopify/kubeaudit/auditors/mounts"
 	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
 	"github.com/Shopify/kubeaudit/auditors/nonroot"
+	"github.com/Shopify/kubeaudit/auditors/ports"
 	"github.com/Shopify/kubeaudit/auditors/privesc"
 	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/Shopify/kubeaudit/auditors/rego"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
 	"github.com/Shopify/kubeaudit/auditors/rootfs"
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
 	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	"github.com/Shopify/kubeaudit/auditors/volumes"
+	"github.com/Shopify/kubeaudit/auditors/vulns"
 	"github.com/Shopify/kubeaudit/config"
 )
 
 var ErrUnknownAuditor = errors.New("Unknown auditor")
 
 var AuditorNames = []string{
+	annotations.Name,
 	apparmor.Name,
 	asat.Name,
 	capabilities.Name,
 	deprecatedapis.Name,
+	egress.Name,
+	ephemeral.Name,
+	etcd.Name,
+	exposure.Name,
+	hostnet.Name,
 	hostns.Name,
+	hostprocess.Name,
 	image.Name,
+	imagepolicy.Name,
+	labels.Name,
+	lifecycle.Name,
 	limits.Name,
 	mounts.Name,
 	netpols.Name,
+	nodecoverage.Name,
 	nonroot.Name,
+	ports.Name,
 	privesc.Name,
 	privileged.Name,
+	pss.Name,
+	rbac.Name,
+	rego.Name,
+	requests.Name,
+	resilience.Name,
 	rootfs.Name,
+	runtimeclass.Name,
 	seccomp.Name,
+	secrets.Name,
+	volumes.Name,
+	vulns.Name,
 }
 
+// OptionalAuditorNames are the auditors which are disabled unless they are explicitly enabled in the config
+var OptionalAuditorNames = []string{
+	egress.Name,
+	imagepolicy.Name,
+	lifecycle.Name,
+	pss.Name,
+	requests.Name,
+	resilience.Name,
+	vulns.Name,
+}
+
+// Auditors creates the auditors enabled in the config. If the config has rule configuration, the audit results of
+// disabled rules are removed and the severity of the others is replaced as configured, then adjusted for the namespace
+// classes of their resources
 func Auditors(conf config.KubeauditConfig) ([]kubeaudit.Auditable, error) {
+	rules, err := newRuleConfigs(conf)
+	if err != nil {
+		return nil, err
+	}
+	classes, err := newNamespaceClasses(conf)
+	if err != nil {
+		return nil, err
+	}
+
 	auditors := []kubeaudit.Auditable{}
 	for _, auditorName := range getEnabledAuditors(conf) {
 		auditor, err := initAuditor(auditorName, conf)
 		if err != nil {
 			return nil, err
 		}
+		// Namespace classes apply before the rules config, so the severity and warnUntil of rules take precedence
+		if len(classes) > 0 {
+			auditor = &namespaceClassAuditor{Auditable: auditor, classes: classes}
+		}
+		if len(rules) > 0 {
+			auditor = &ruleConfigAuditor{Auditable: auditor, rules: rules}
+		}
 		auditors = append(auditors, auditor)
 	}
 
 	return auditors, nil
 }
 
-// getEnabledAuditors returns a list of all auditors excluding any explicitly disabled in the config
+// EnabledAuditorNames returns the names of the auditors the config enables, in the order of AuditorNames
+func EnabledAuditorNames(conf config.KubeauditConfig) []string {
+	return getEnabledAuditors(conf)
+}
+
+// getEnabledAuditors returns a list of all auditors excluding any explicitly disabled in the config, and any optional
+// auditors not explicitly enabled in the config
 func getEnabledAuditors(conf config.KubeauditConfig) []string {
 	auditors := []string{}
 	for _, auditorName := range AuditorNames {
 		// if value is not found in the `conf.GetEnabledAuditors()` map, this means
 		// it wasn't added to the config file, so it should be enabled by default
-		if enabled, ok := conf.GetEnabledAuditors()[auditorName]; !ok || enabled {
+		// unless it is optional
+		enabled, ok := conf.GetEnabledAuditors()[auditorName]
+		if (!ok && !isOptional(auditorName)) || enabled {
 			auditors = append(auditors, auditorName)
 		}
 	}
 	return auditors
 }
 
+func isOptional(auditorName string) bool {
+	for _, optionalAuditorName := range OptionalAuditorNames {
+		if auditorName == optionalAuditorName {
+			return true
+		}
+	}
+	return false
+}
+
 func initAuditor(name string, conf config.KubeauditConfig) (kubeaudit.Auditable, error) {
 	switch name {
+	case annotations.Name:
+		return annotations.New(conf.GetAuditorConfigs().Annotations)
 	case apparmor.Name:
 		return apparmor.New(), nil
 	case asat.Name:
-		return asat.New(), nil
+		return asat.NewWithConfig(conf.GetAuditorConfigs().ASAT), nil
 	case capabilities.Name:
 		return capabilities.New(conf.GetAuditorConfigs().Capabilities), nil
 	case deprecatedapis.Name:
 		return deprecatedapis.New(conf.GetAuditorConfigs().DeprecatedAPIs)
+	case egress.Name:
+		return egress.New(conf.GetAuditorConfigs().Egress), nil
+	case ephemeral.Name:
+		return ephemeral.New(conf.GetAuditorConfigs().Ephemeral)
+	case etcd.Name:
+		return etcd.New(conf.GetAuditorConfigs().Etcd)
+	case exposure.Name:
+		return exposure.New(), nil
+	case hostnet.Name:
+		return hostnet.New(), nil
 	case hostns.Name:
 		return hostns.New(), nil
+	case hostprocess.Name:
+		return hostprocess.New(), nil
 	case image.Name:
-		return image.New(conf.GetAuditorConfigs().Image), nil
+		imageConfig := conf.GetAuditorConfigs().Image
+		imageConfig.Offline = conf.Offline
+		return image.New(imageConfig), nil
+	case imagepolicy.Name:
+		return imagepolicy.New(conf.GetAuditorConfigs().ImagePolicy)
+	case labels.Name:
+		return labels.New(conf.GetAuditorConfigs().Labels)
+	case lifecycle.Name:
+		return lifecycle.New(), nil
 	case limits.Name:
 		return limits.New(conf.GetAuditorConfigs().Limits)
 	case mounts.Name:
 		return mounts.New(conf.GetAuditorConfigs().Mounts), nil
 	case netpols.Name:
 		return netpols.New(), nil
+	case nodecoverage.Name:
+		return nodecoverage.New(conf.GetAuditorConfigs().NodeCoverage), nil
 	case nonroot.Name:
 		return nonroot.New(), nil
+	case ports.Name:
+		return ports.New(conf.GetAuditorConfigs().Ports)
 	case privesc.Name:
 		return privesc.New(), nil
 	case privileged.Name:
 		return privileged.New(), nil
+	case pss.Name:
+		return pss.New(conf.GetAuditorConfigs().PSS)
+	case rbac.Name:
+		return rbac.New(), nil
+	case rego.Name:
+		return rego.New(conf.GetAuditorConfigs().Rego)
+	case requests.Name:
+		return requests.New(conf.GetAuditorConfigs().Requests)
+	case resilience.Name:
+		return resilience.New(conf.GetAuditorConfigs().Resilience)
 	case rootfs.Name:
 		return rootfs.New(), nil
+	case runtimeclass.Name:
+		return runtimeclass.New(conf.GetAuditorConfigs().RuntimeClass)
 	case seccomp.Name:
 		return seccomp.New(), nil
+	case secrets.Name:
+		return secrets.New(conf.GetAuditorConfigs().Secrets)
+	case volumes.Name:
+		return volumes.New(conf.GetAuditorConfigs().Volumes), nil
+	case vulns.Name:
+		vulnsConfig := conf.GetAuditorConfigs().Vulns
+		vulnsConfig.Offline = conf.Offline
+		return vulns.New(vulnsConfig)
 	}
 
 	return nil, fmt.Errorf("unknown auditor %s: %w", name, ErrUnknownAuditor)
@@ -5,23 +5,45 @@ import (
 	"testing"
 
 	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/annotations"
 	"github.com/Shopify/kubeaudit/auditors/apparmor"
 	"github.com/Shopify/kubeaudit/auditors/asat"
 	"github.com/Shopify/kubeaudit/auditors/capabilities"
 	"github.com/Shopify/kubeaudit/auditors/deprecatedapis"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	"github.com/Shopify/kubeaudit/auditors/etcd"
+	"github.com/Shopify/kubeaudit/auditors/exposure"
 	"github.com/Shopify/kubeaudit/auditors/mounts"
 
+	"github.com/Shopify/kubeaudit/auditors/hostnet"
 	"github.com/Shopify/kubeaudit/auditors/hostns"
+	"github.com/Shopify/kubeaudit/auditors/hostprocess"
 	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	"github.com/Shopify/kubeaudit/auditors/labels"
+	"github.com/Shopify/kubeaudit/auditors/lifecycle"
 	"github.com/Shopify/kubeaudit/auditors/limits"
 	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
 	"github.com/Shopify/kubeaudit/auditors/nonroot"
+	"github.com/Shopify/kubeaudit/auditors/ports"
 	"github.com/Shopify/kubeaudit/auditors/privesc"
 	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/Shopify/kubeaudit/auditors/rego"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
 	"github.com/Shopify/kubeaudit/auditors/rootfs"
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
 	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	"github.com/Shopify/kubeaudit/auditors/volumes"
+	"github.com/Shopify/kubeaudit/auditors/vulns"
 	"github.com/Shopify/kubeaudit/config"
 	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/plugin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -103,16 +125,24 @@ func TestAllWithConfig(t *testing.T) {
 }
 
 func TestGetEnabledAuditors(t *testing.T) {
+	// Optional auditors are only enabled if they are explicitly enabled
+	defaultAuditors := []string{}
+	for _, auditorName := range AuditorNames {
+		if auditorName != egress.Name && auditorName != imagepolicy.Name && auditorName != lifecycle.Name && auditorName != pss.Name && auditorName != requests.Name && auditorName != resilience.Name && auditorName != vulns.Name {
+			defaultAuditors = append(defaultAuditors, auditorName)
+		}
+	}
+
 	cases := []struct {
 		testName         string
 		enabledAuditors  map[string]bool
 		expectedAuditors []string
 	}{
 		{
-			// If no config is provided, all auditors should be enabled
+			// If no config is provided, all auditors except the optional ones should be enabled
 			testName:         "No config",
 			enabledAuditors:  map[string]bool{},
-			expectedAuditors: AuditorNames,
+			expectedAuditors: defaultAuditors,
 		},
 		{
 			// If some auditors are explicitly disabled, the rest should default to being enabled
@@ -122,18 +152,32 @@ func TestGetEnabledAuditors(t *testing.T) {
 				"rootfs":   false,
 			},
 			expectedAuditors: []string{
+				annotations.Name,
 				asat.Name,
 				capabilities.Name,
 				deprecatedapis.Name,
+				ephemeral.Name,
+				etcd.Name,
+				exposure.Name,
+				hostnet.Name,
 				hostns.Name,
+				hostprocess.Name,
 				image.Name,
+				labels.Name,
 				limits.Name,
 				mounts.Name,
 				netpols.Name,
+				nodecoverage.Name,
 				nonroot.Name,
+				ports.Name,
 				privesc.Name,
 				privileged.Name,
+				rbac.Name,
+				rego.Name,
+				runtimeclass.Name,
 				seccomp.Name,
+				secrets.Name,
+				volumes.Name,
 			},
 		},
 		{
@@ -142,6 +186,19 @@ func TestGetEnabledAuditors(t *testing.T) {
 				"apparmor": true,
 				"rootfs":   true,
 			},
+			expectedAuditors: defaultAuditors,
+		},
+		{
+			testName: "Optional enabled",
+			enabledAuditors: map[string]bool{
+				"egress":      true,
+				"imagepolicy": true,
+				"lifecycle":   true,
+				"pss":         true,
+				"requests":    true,
+				"resilience":  true,
+				"vulns":       true,
+			},
 			expectedAuditors: AuditorNames,
 		},
 		{
@@ -154,18 +211,32 @@ func TestGetEnabledAuditors(t *testing.T) {
 				"rootfs":       false,
 			},
 			expectedAuditors: []string{
+				annotations.Name,
 				asat.Name,
 				capabilities.Name,
 				deprecatedapis.Name,
+				ephemeral.Name,
+				etcd.Name,
+				exposure.Name,
+				hostnet.Name,
 				hostns.Name,
+				hostprocess.Name,
 				image.Name,
+				labels.Name,
 				limits.Name,
 				mounts.Name,
 				netpols.Name,
+				nodecoverage.Name,
 				nonroot.Name,
+				ports.Name,
 				privesc.Name,
 				privileged.Name,
+				rbac.Name,
+				rego.Name,
+				runtimeclass.Name,
 				seccomp.Name,
+				secrets.Name,
+				volumes.Name,
 			},
 		},
 	}
@@ -191,3 +262,23 @@ func enabledAuditorsToMap(enabledAuditors []string) map[string]bool {
 	}
 	return enabledAuditorMap
 }
+
+func TestPlugins(t *testing.T) {
+	teams := plugin.New("/usr/local/bin/kubeaudit-auditor-teams")
+	costCenter := plugin.New("/usr/local/bin/kubeaudit-auditor-costcenter")
+
+	conf := config.KubeauditConfig{EnabledAuditors: map[string]bool{"costcenter": false}}
+	plugins, err := Plugins(conf, []*plugin.Plugin{costCenter, teams})
+	require.NoError(t, err)
+	assert.Equal(t, []kubeaudit.Auditable{teams}, plugins)
+
+	disabled := false
+	conf = config.KubeauditConfig{Rules: map[string]config.RuleConfig{"TeamMissing": {Enabled: &disabled}}}
+	plugins, err = Plugins(conf, []*plugin.Plugin{teams})
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+	assert.IsType(t, &ruleConfigAuditor{}, plugins[0])
+
+	_, err = Plugins(config.KubeauditConfig{}, []*plugin.Plugin{plugin.New("/usr/local/bin/kubeaudit-auditor-privileged")})
+	assert.EqualError(t, err, "plugin /usr/local/bin/kubeaudit-auditor-privileged has the name of a built-in auditor")
+}
@@ -0,0 +1,135 @@
+package all
+
+import (
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/asat"
+	"github.com/Shopify/kubeaudit/auditors/capabilities"
+	"github.com/Shopify/kubeaudit/auditors/exposure"
+	"github.com/Shopify/kubeaudit/auditors/hostnet"
+	"github.com/Shopify/kubeaudit/auditors/hostns"
+	"github.com/Shopify/kubeaudit/auditors/hostprocess"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	"github.com/Shopify/kubeaudit/auditors/lifecycle"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/mounts"
+	"github.com/Shopify/kubeaudit/auditors/nonroot"
+	"github.com/Shopify/kubeaudit/auditors/privesc"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
+	"github.com/Shopify/kubeaudit/auditors/rootfs"
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
+	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	"github.com/Shopify/kubeaudit/auditors/volumes"
+	"github.com/Shopify/kubeaudit/auditors/vulns"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const containerField = kubeaudit.ContainerFieldPrefix
+
+// auditorFields are the fields the rules of an auditor are about, unless the rule is in ruleFields
+var auditorFields = map[string]string{
+	capabilities.Name: containerField + "securityContext.capabilities",
+	image.Name:        containerField + "image",
+	imagepolicy.Name:  containerField + "image",
+	limits.Name:       containerField + "resources.limits",
+	mounts.Name:       containerField + "volumeMounts",
+	privesc.Name:      containerField + "securityContext.allowPrivilegeEscalation",
+	privileged.Name:   containerField + "securityContext.privileged",
+	requests.Name:     containerField + "resources.requests",
+	rootfs.Name:       containerField + "securityContext.readOnlyRootFilesystem",
+	runtimeclass.Name: "runtimeClassName",
+	volumes.Name:      "volumes",
+	vulns.Name:        containerField + "image",
+}
+
+// ruleFields are the fields of rules which are about a different field than the other rules of their auditor
+var ruleFields = map[string]string{
+	asat.AutomountServiceAccountTokenDeprecated:       "serviceAccount",
+	asat.AutomountServiceAccountTokenTrueAndDefaultSA: "automountServiceAccountToken",
+	exposure.ServiceNodePortExposed:                   "spec.type",
+	exposure.ServiceSourceRangesMissing:               "spec.loadBalancerSourceRanges",
+	exposure.IngressTLSMissing:                        "spec.tls",
+	hostnet.HostPortSet:                               containerField + "ports",
+	hostnet.HostAliasesSet:                            "hostAliases",
+	hostnet.DNSPolicyHostNetWithoutHostNetwork:        "dnsPolicy",
+	hostns.NamespaceHostNetworkTrue:                   "hostNetwork",
+	hostns.NamespaceHostIPCTrue:                       "hostIPC",
+	hostns.NamespaceHostPIDTrue:                       "hostPID",
+	hostprocess.HostProcessTruePod:                    "securityContext.windowsOptions.hostProcess",
+	hostprocess.HostProcessTrueContainer:              containerField + "securityContext.windowsOptions.hostProcess",
+	image.ImageRunsAsRoot:                             containerField + "securityContext.runAsUser",
+	image.ImageRunAsNonRootConflict:                   containerField + "securityContext.runAsNonRoot",
+	image.ImageHealthcheckIgnored:                     containerField + "livenessProbe",
+	lifecycle.TerminationGracePeriodZero:              "terminationGracePeriodSeconds",
+	lifecycle.RestartPolicyNotAlways:                  "restartPolicy",
+	lifecycle.ActiveDeadlineSecondsSet:                "activeDeadlineSeconds",
+	resilience.LivenessProbeMissing:                   containerField + "livenessProbe",
+	resilience.ReadinessProbeMissing:                  containerField + "readinessProbe",
+	nonroot.RunAsUserCSCRoot:                          containerField + "securityContext.runAsUser",
+	nonroot.RunAsUserPSCRoot:                          "securityContext.runAsUser",
+	nonroot.RunAsNonRootCSCFalse:                      containerField + "securityContext.runAsNonRoot",
+	nonroot.RunAsNonRootPSCNilCSCNil:                  containerField + "securityContext.runAsNonRoot",
+	nonroot.RunAsNonRootPSCFalseCSCNil:                "securityContext.runAsNonRoot",
+	pss.PSSNamespaceEnforceMissing:                    "metadata.labels",
+	pss.PSSNamespaceEnforceLevelTooLow:                "metadata.labels",
+	seccomp.SeccompProfileMissing:                     "securityContext.seccompProfile",
+	seccomp.SeccompDisabledPod:                        "securityContext.seccompProfile",
+	seccomp.SeccompDisabledContainer:                  containerField + "securityContext.seccompProfile",
+	secrets.SecretEnvVarRef:                           containerField + "env",
+	secrets.SecretEnvFromRef:                          containerField + "envFrom",
+	secrets.SecretEnvVarLiteral:                       containerField + "env",
+}
+
+// fieldsAuditor sets the field each audit result of an auditor is about
+type fieldsAuditor struct {
+	kubeaudit.Auditable
+}
+
+// WithFields returns the auditors with the field each of their audit results is about set, such as
+// "container.securityContext.privileged", so results can be located at the line and column of the field in
+// manifests. Audit results which already have a field, and results of rules without a known field, are unchanged
+func WithFields(auditors []kubeaudit.Auditable) []kubeaudit.Auditable {
+	withFields := make([]kubeaudit.Auditable, 0, len(auditors))
+	for _, auditor := range auditors {
+		withFields = append(withFields, &fieldsAuditor{Auditable: auditor})
+	}
+	return withFields
+}
+
+// Audit returns the audit results of the auditor with their fields
+func (a *fieldsAuditor) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	auditResults, err := a.Auditable.Audit(resource, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, auditResult := range auditResults {
+		if auditResult.Field == "" {
+			auditResult.Field = getField(auditResult.Auditor, auditResult.Rule)
+		}
+	}
+	return auditResults, nil
+}
+
+// Unwrap returns the wrapped auditor
+func (a *fieldsAuditor) Unwrap() kubeaudit.Auditable {
+	return a.Auditable
+}
+
+// getField returns the field of a rule reported by an auditor. Overridden rules have the field of the rule they
+// override
+func getField(auditor, rule string) string {
+	for _, name := range []string{rule, strings.TrimSuffix(rule, override.GetOverriddenResultName(""))} {
+		if field, ok := ruleFields[name]; ok {
+			return field
+		}
+	}
+	return auditorFields[strings.ToLower(auditor)]
+}
@@ -0,0 +1,81 @@
+package all
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/config"
+)
+
+// getenv is replaced in tests
+var getenv = os.Getenv
+
+// messageData is what message templates are executed with
+type messageData struct {
+	Rule     string
+	Auditor  string
+	Severity string
+	Message  string
+	Metadata kubeaudit.Metadata
+}
+
+func parseMessage(rule, message string) (*template.Template, error) {
+	tmpl, err := template.New(rule).Option("missingkey=zero").Parse(message)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing the message of rule %s: %w", rule, err)
+	}
+	return tmpl, nil
+}
+
+// formatMessage returns the message of the audit result executed with the template
+func formatMessage(tmpl *template.Template, auditResult *kubeaudit.AuditResult) (string, error) {
+	var message bytes.Buffer
+	err := tmpl.Execute(&message, messageData{
+		Rule:     auditResult.Rule,
+		Auditor:  auditResult.Auditor,
+		Severity: auditResult.Severity.String(),
+		Message:  auditResult.Message,
+		Metadata: auditResult.Metadata,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error formatting the message of rule %s: %w", auditResult.Rule, err)
+	}
+	return message.String(), nil
+}
+
+// getMessageCatalog returns the messages of the catalog of the locale, which is the catalog of the language if there is
+// no catalog for the region of the locale, such as "fr" for "fr_CA.UTF-8"
+func getMessageCatalog(conf config.KubeauditConfig) map[string]string {
+	if len(conf.Messages) == 0 {
+		return nil
+	}
+	locale := getLocale(conf)
+	if locale == "" {
+		return nil
+	}
+	if messages, ok := conf.Messages[locale]; ok {
+		return messages
+	}
+	if language := strings.SplitN(locale, "_", 2)[0]; language != locale {
+		return conf.Messages[language]
+	}
+	return nil
+}
+
+// getLocale returns the locale of the config or the environment, without its encoding and modifier
+func getLocale(conf config.KubeauditConfig) string {
+	locale := conf.Locale
+	for _, variable := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale != "" {
+			break
+		}
+		locale = getenv(variable)
+	}
+	locale = strings.SplitN(locale, ".", 2)[0]
+	locale = strings.SplitN(locale, "@", 2)[0]
+	return strings.Replace(locale, "-", "_", 1)
+}
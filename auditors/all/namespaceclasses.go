@@ -0,0 +1,136 @@
+package all
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// namespaceClass is a config.NamespaceClass with its severities parsed
+type namespaceClass struct {
+	name       string
+	namespaces []string
+	adjust     int
+	severities map[string]kubeaudit.SeverityLevel
+}
+
+// namespaceClassAuditor adjusts the severity of the audit results of the resources of the namespace classes of the
+// kubeaudit config
+type namespaceClassAuditor struct {
+	kubeaudit.Auditable
+	classes []namespaceClass
+}
+
+func newNamespaceClasses(conf config.KubeauditConfig) ([]namespaceClass, error) {
+	classes := make([]namespaceClass, 0, len(conf.NamespaceClasses))
+	for _, classConf := range conf.NamespaceClasses {
+		class := namespaceClass{
+			name:       classConf.Name,
+			namespaces: classConf.Namespaces,
+			adjust:     classConf.Adjust,
+			severities: make(map[string]kubeaudit.SeverityLevel, len(classConf.Severities)),
+		}
+		for _, pattern := range classConf.Namespaces {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("error configuring namespace class %s: invalid namespace pattern %q: %w", classConf.Name, pattern, err)
+			}
+		}
+		for rule, name := range classConf.Severities {
+			severity, err := kubeaudit.ParseSeverity(name)
+			if err != nil {
+				return nil, fmt.Errorf("error configuring namespace class %s: %w", classConf.Name, err)
+			}
+			class.severities[rule] = severity
+		}
+		classes = append(classes, class)
+	}
+	return classes, nil
+}
+
+// Audit replaces the severity of the audit results of the resources of a namespace class with the severity of their
+// rule in the class, or else adjusts it by the levels of the class. Suppressed audit results are left as they are
+func (a *namespaceClassAuditor) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	auditResults, err := a.Auditable.Audit(resource, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	class, ok := a.getClass(resourceNamespace(resource))
+	if !ok {
+		return auditResults, nil
+	}
+	for _, auditResult := range auditResults {
+		if auditResult.SuppressedBy != "" {
+			continue
+		}
+		if severity, ok := class.severities[auditResult.Rule]; ok {
+			auditResult.Severity = severity
+			continue
+		}
+		auditResult.Severity = auditResult.Severity.Adjust(class.adjust)
+	}
+	return auditResults, nil
+}
+
+// Unwrap returns the wrapped auditor
+func (a *namespaceClassAuditor) Unwrap() kubeaudit.Auditable {
+	return a.Auditable
+}
+
+// getClass returns the first class with a pattern which matches the namespace
+func (a *namespaceClassAuditor) getClass(namespace string) (namespaceClass, bool) {
+	if namespace == "" {
+		return namespaceClass{}, false
+	}
+	for _, class := range a.classes {
+		for _, pattern := range class.namespaces {
+			if matched, _ := filepath.Match(pattern, namespace); matched {
+				return class, true
+			}
+		}
+	}
+	return namespaceClass{}, false
+}
+
+// clusterScopedKinds are the kinds of the cluster-scoped resources which can be audited, which are in no namespace
+var clusterScopedKinds = map[string]bool{
+	"APIService":                     true,
+	"ClusterRole":                    true,
+	"ClusterRoleBinding":             true,
+	"CustomResourceDefinition":       true,
+	"IngressClass":                   true,
+	"MutatingWebhookConfiguration":   true,
+	"Node":                           true,
+	"PersistentVolume":               true,
+	"PodSecurityPolicy":              true,
+	"PriorityClass":                  true,
+	"RuntimeClass":                   true,
+	"StorageClass":                   true,
+	"ValidatingWebhookConfiguration": true,
+}
+
+// resourceNamespace returns the namespace of a resource, which is the name of Namespace resources. Namespaced
+// resources without a namespace, as in manifests, are in the default namespace, and cluster-scoped resources are in
+// none
+func resourceNamespace(resource k8s.Resource) string {
+	switch resource := resource.(type) {
+	case *k8s.NamespaceV1:
+		return resource.GetName()
+	case *k8s.ClusterRoleV1, *k8s.ClusterRoleBindingV1, *k8s.NodeV1:
+		return ""
+	}
+	if clusterScopedKinds[resource.GetObjectKind().GroupVersionKind().Kind] {
+		return ""
+	}
+	objectMeta := k8s.GetObjectMeta(resource)
+	if objectMeta == nil {
+		return ""
+	}
+	if namespace := objectMeta.GetNamespace(); namespace != "" {
+		return namespace
+	}
+	return "default"
+}
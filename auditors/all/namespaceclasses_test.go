@@ -0,0 +1,128 @@
+package all
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditorsWithNamespaceClasses(t *testing.T) {
+	cases := []struct {
+		testName string
+		classes  []config.NamespaceClass
+		expected kubeaudit.SeverityLevel
+	}{
+		{"No class", nil, kubeaudit.Error},
+		{"Other namespaces", []config.NamespaceClass{{Name: "platform", Namespaces: []string{"kube-*"}, Adjust: -2}}, kubeaudit.Error},
+		{"Adjust", []config.NamespaceClass{{Name: "platform", Namespaces: []string{"kube-*", "privileged-*"}, Adjust: -1}}, kubeaudit.Warn},
+		{"Rule severity", []config.NamespaceClass{{
+			Name:       "platform",
+			Namespaces: []string{"privileged-true"},
+			Adjust:     -1,
+			Severities: map[string]string{privileged.PrivilegedTrue: "info"},
+		}}, kubeaudit.Info},
+		{"First class", []config.NamespaceClass{
+			{Name: "sandbox", Namespaces: []string{"privileged-*"}, Adjust: -2},
+			{Name: "tenant", Namespaces: []string{"*"}, Adjust: 1},
+		}, kubeaudit.Info},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			conf := config.KubeauditConfig{
+				EnabledAuditors:  enabledAuditorsToMap([]string{privileged.Name}),
+				NamespaceClasses: tc.classes,
+			}
+			auditors, err := Auditors(conf)
+			require.NoError(t, err)
+
+			report := test.AuditMultiple(t, "../privileged/fixtures", "privileged-true.yml", auditors, []string{privileged.PrivilegedTrue}, "", test.MANIFEST_MODE)
+			assert.Equal(t, tc.expected, report.Results()[0].GetAuditResults()[0].Severity)
+		})
+	}
+}
+
+func TestAuditorsWithNamespaceClassesDefaultNamespace(t *testing.T) {
+	conf := config.KubeauditConfig{
+		EnabledAuditors:  enabledAuditorsToMap([]string{privileged.Name}),
+		NamespaceClasses: []config.NamespaceClass{{Name: "tenant", Namespaces: []string{"default"}, Adjust: -1}},
+	}
+	auditors, err := Auditors(conf)
+	require.NoError(t, err)
+	auditor, err := kubeaudit.New(auditors)
+	require.NoError(t, err)
+
+	// The pod has no namespace, so it is in the default namespace
+	manifest := `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+    - name: container
+      image: scratch
+      securityContext:
+        privileged: true
+`
+	report, err := auditor.AuditManifest("", strings.NewReader(manifest))
+	require.NoError(t, err)
+	require.Len(t, report.Results(), 1)
+	auditResults := report.Results()[0].GetAuditResults()
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, kubeaudit.Warn, auditResults[0].Severity)
+}
+
+func TestAuditorsWithNamespaceClassesAndRuleConfig(t *testing.T) {
+	defer func() { now = time.Now }()
+	now = func() time.Time { return time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC) }
+
+	cases := []struct {
+		testName string
+		class    config.NamespaceClass
+		rule     config.RuleConfig
+		expected kubeaudit.SeverityLevel
+	}{
+		{
+			"Adjust before warnUntil",
+			config.NamespaceClass{Name: "tenant", Namespaces: []string{"privileged-*"}, Adjust: 1},
+			config.RuleConfig{WarnUntil: "2026-12-01"},
+			kubeaudit.Warn,
+		},
+		{
+			"Class severity before rule severity",
+			config.NamespaceClass{Name: "platform", Namespaces: []string{"privileged-*"}, Severities: map[string]string{privileged.PrivilegedTrue: "info"}},
+			config.RuleConfig{Severity: "error"},
+			kubeaudit.Error,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			conf := config.KubeauditConfig{
+				EnabledAuditors:  enabledAuditorsToMap([]string{privileged.Name}),
+				Rules:            map[string]config.RuleConfig{privileged.PrivilegedTrue: tc.rule},
+				NamespaceClasses: []config.NamespaceClass{tc.class},
+			}
+			auditors, err := Auditors(conf)
+			require.NoError(t, err)
+
+			report := test.AuditMultiple(t, "../privileged/fixtures", "privileged-true.yml", auditors, []string{privileged.PrivilegedTrue}, "", test.MANIFEST_MODE)
+			assert.Equal(t, tc.expected, report.Results()[0].GetAuditResults()[0].Severity)
+		})
+	}
+}
+
+func TestAuditorsWithInvalidNamespaceClass(t *testing.T) {
+	conf := config.KubeauditConfig{
+		NamespaceClasses: []config.NamespaceClass{{Name: "platform", Namespaces: []string{"kube-*", "["}}},
+	}
+	_, err := Auditors(conf)
+	assert.EqualError(t, err, `error configuring namespace class platform: invalid namespace pattern "[": syntax error in pattern`)
+}
@@ -0,0 +1,64 @@
+package all
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/pkg/plugin"
+)
+
+// Plugins returns the plugins which are not disabled in the enabledAuditors of the config, which are enabled by
+// default like the other auditors. If the config has rule configuration, it applies to the audit results of the
+// plugins as well. A plugin can't have the name of a built-in auditor
+func Plugins(conf config.KubeauditConfig, plugins []*plugin.Plugin) ([]kubeaudit.Auditable, error) {
+	rules, err := newRuleConfigs(conf)
+	if err != nil {
+		return nil, err
+	}
+	classes, err := newNamespaceClasses(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	auditors := []kubeaudit.Auditable{}
+	for _, p := range plugins {
+		if isBuiltin(p.Name) {
+			return nil, fmt.Errorf("plugin %s has the name of a built-in auditor", p.Path)
+		}
+		if enabled, ok := conf.GetEnabledAuditors()[p.Name]; ok && !enabled {
+			continue
+		}
+
+		var auditor kubeaudit.Auditable = p
+		// Namespace classes apply before the rules config, so the severity and warnUntil of rules take precedence
+		if len(classes) > 0 {
+			auditor = &namespaceClassAuditor{Auditable: auditor, classes: classes}
+		}
+		if len(rules) > 0 {
+			auditor = &ruleConfigAuditor{Auditable: auditor, rules: rules}
+		}
+		auditors = append(auditors, auditor)
+	}
+
+	return auditors, nil
+}
+
+// CheckEnabledAuditors returns config.Errors for the auditors of enabledAuditors in the config which are neither
+// built-in auditors nor plugins
+func CheckEnabledAuditors(conf config.KubeauditConfig, plugins []*plugin.Plugin) error {
+	known := append([]string{}, AuditorNames...)
+	for _, p := range plugins {
+		known = append(known, p.Name)
+	}
+	return conf.CheckEnabledAuditors(known)
+}
+
+func isBuiltin(auditorName string) bool {
+	for _, builtinAuditorName := range AuditorNames {
+		if auditorName == builtinAuditorName {
+			return true
+		}
+	}
+	return false
+}
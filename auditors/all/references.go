@@ -0,0 +1,42 @@
+package all
+
+import (
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/references"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// referencesAuditor attaches the references of the rules to the audit results of an auditor
+type referencesAuditor struct {
+	kubeaudit.Auditable
+}
+
+// WithReferences returns the auditors with the references of their rules attached to their audit results, such as the
+// documentation of the rule and the CIS controls it fails. Audit results which already have references are unchanged
+func WithReferences(auditors []kubeaudit.Auditable) []kubeaudit.Auditable {
+	withReferences := make([]kubeaudit.Auditable, 0, len(auditors))
+	for _, auditor := range auditors {
+		withReferences = append(withReferences, &referencesAuditor{Auditable: auditor})
+	}
+	return withReferences
+}
+
+// Audit returns the audit results of the auditor with their references
+func (a *referencesAuditor) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	auditResults, err := a.Auditable.Audit(resource, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, auditResult := range auditResults {
+		if len(auditResult.References) == 0 {
+			auditResult.References = references.Get(auditResult.Auditor, auditResult.Rule)
+		}
+	}
+	return auditResults, nil
+}
+
+// Unwrap returns the wrapped auditor
+func (a *referencesAuditor) Unwrap() kubeaudit.Auditable {
+	return a.Auditable
+}
@@ -0,0 +1,176 @@
+package all
+
+import (
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+// ruleConfig is a config.RuleConfig with its severity and message parsed
+type ruleConfig struct {
+	disabled    bool
+	severity    kubeaudit.SeverityLevel
+	hasSeverity bool
+	// warnUntil is the start of the day until which the error results of the rule are reported as warnings
+	warnUntil time.Time
+	message   *template.Template
+}
+
+// warnUntilLayout is the layout of the dates of config.RuleConfig.WarnUntil
+const warnUntilLayout = "2006-01-02"
+
+// now returns the current time, which decides whether the warnUntil date of a rule has passed
+var now = time.Now
+
+// ruleConfigAuditor applies the rule configuration of the kubeaudit config to the audit results of an auditor
+type ruleConfigAuditor struct {
+	kubeaudit.Auditable
+	rules map[string]ruleConfig
+}
+
+func newRuleConfigs(conf config.KubeauditConfig) (map[string]ruleConfig, error) {
+	rules := make(map[string]ruleConfig, len(conf.GetRuleConfigs()))
+	for rule, ruleConf := range conf.GetRuleConfigs() {
+		parsed := ruleConfig{disabled: ruleConf.Enabled != nil && !*ruleConf.Enabled}
+		if ruleConf.Severity != "" {
+			severity, err := kubeaudit.ParseSeverity(ruleConf.Severity)
+			if err != nil {
+				return nil, fmt.Errorf("error configuring rule %s: %w", rule, err)
+			}
+			parsed.severity = severity
+			parsed.hasSeverity = true
+		}
+		if ruleConf.WarnUntil != "" {
+			warnUntil, err := time.Parse(warnUntilLayout, ruleConf.WarnUntil)
+			if err != nil {
+				return nil, fmt.Errorf("error configuring rule %s: invalid warnUntil %q, expected a date in the form YYYY-MM-DD", rule, ruleConf.WarnUntil)
+			}
+			parsed.warnUntil = warnUntil
+		}
+		if ruleConf.Message != "" {
+			message, err := parseMessage(rule, ruleConf.Message)
+			if err != nil {
+				return nil, err
+			}
+			parsed.message = message
+		}
+		rules[rule] = parsed
+	}
+
+	// The message catalog of the locale takes precedence over the messages of the rules, since those are usually
+	// written in the default language of the team
+	for rule, catalogMessage := range getMessageCatalog(conf) {
+		message, err := parseMessage(rule, catalogMessage)
+		if err != nil {
+			return nil, err
+		}
+		parsed := rules[rule]
+		parsed.message = message
+		rules[rule] = parsed
+	}
+	return rules, nil
+}
+
+// Audit suppresses the audit results of disabled rules and replaces the severity and message of the others. Errors of
+// rules with a warnUntil date are reported as warnings until the date. Disabling a rule also suppresses its overridden
+// audit results, but their severity is not replaced. Suppressed results are left
+// out of reports and counted in their suppressions
+func (a *ruleConfigAuditor) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	auditResults, err := a.Auditable.Audit(resource, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, auditResult := range auditResults {
+		if rule, ok := a.rules[auditResult.Rule]; ok {
+			if rule.disabled {
+				auditResult.SuppressedBy = kubeaudit.SuppressedByConfig
+				continue
+			}
+			if rule.hasSeverity {
+				auditResult.Severity = rule.severity
+			}
+			if auditResult.Severity == kubeaudit.Error && now().Before(rule.warnUntil) {
+				auditResult.Severity = kubeaudit.Warn
+			}
+			if rule.message != nil {
+				message, err := formatMessage(rule.message, auditResult)
+				if err != nil {
+					return nil, err
+				}
+				auditResult.Message = message
+			}
+		} else if rule, ok := a.getOverriddenRule(auditResult.Rule); ok && rule.disabled {
+			auditResult.SuppressedBy = kubeaudit.SuppressedByConfig
+		}
+	}
+	return auditResults, nil
+}
+
+// Unwrap returns the wrapped auditor
+func (a *ruleConfigAuditor) Unwrap() kubeaudit.Auditable {
+	return a.Auditable
+}
+
+func (a *ruleConfigAuditor) getOverriddenRule(resultRule string) (ruleConfig, bool) {
+	for name, rule := range a.rules {
+		if override.GetOverriddenResultName(name) == resultRule {
+			return rule, true
+		}
+	}
+	return ruleConfig{}, false
+}
+
+// ruleFilterAuditor only keeps the audit results of the selected rules
+type ruleFilterAuditor struct {
+	kubeaudit.Auditable
+	rules map[string]bool
+}
+
+// OnlyRules limits the audit results of the auditors to the rules, such as CapabilityShouldDropAll. The overridden
+// audit results of the rules are kept. Audit results of other rules are left out of reports and are not counted in
+// their suppressions, since the other rules are not audited rather than suppressed
+func OnlyRules(auditors []kubeaudit.Auditable, rules []string) []kubeaudit.Auditable {
+	selected := map[string]bool{}
+	for _, rule := range rules {
+		if rule != "" {
+			selected[rule] = true
+			selected[override.GetOverriddenResultName(rule)] = true
+		}
+	}
+	if len(selected) == 0 {
+		return auditors
+	}
+
+	filtered := make([]kubeaudit.Auditable, 0, len(auditors))
+	for _, auditor := range auditors {
+		filtered = append(filtered, &ruleFilterAuditor{Auditable: auditor, rules: selected})
+	}
+	return filtered
+}
+
+// Audit returns the audit results of the selected rules
+func (a *ruleFilterAuditor) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	auditResults, err := a.Auditable.Audit(resource, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []*kubeaudit.AuditResult
+	for _, auditResult := range auditResults {
+		if a.rules[auditResult.Rule] {
+			selected = append(selected, auditResult)
+		}
+	}
+	return selected, nil
+}
+
+// Unwrap returns the wrapped auditor
+func (a *ruleFilterAuditor) Unwrap() kubeaudit.Auditable {
+	return a.Auditable
+}
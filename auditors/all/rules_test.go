@@ -0,0 +1,223 @@
+package all
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/apparmor"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditorsWithRuleConfig(t *testing.T) {
+	conf := config.KubeauditConfig{
+		EnabledAuditors: enabledAuditorsToMap([]string{apparmor.Name, seccomp.Name}),
+		Rules: map[string]config.RuleConfig{
+			apparmor.AppArmorAnnotationMissing: {Enabled: k8s.NewFalse()},
+			seccomp.SeccompProfileMissing:      {Severity: "warning"},
+		},
+	}
+	auditors, err := Auditors(conf)
+	require.NoError(t, err)
+
+	report := test.AuditMultiple(t, fixtureDir, "deployment-apps-v1.yml", auditors, []string{seccomp.SeccompProfileMissing}, "", test.MANIFEST_MODE)
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			assert.Equal(t, kubeaudit.Warn, auditResult.Severity)
+		}
+	}
+	assert.False(t, report.HasErrors())
+}
+
+func TestAuditorsWithRuleConfigPromotesSeverity(t *testing.T) {
+	conf := config.KubeauditConfig{
+		EnabledAuditors: enabledAuditorsToMap([]string{privileged.Name}),
+		Rules: map[string]config.RuleConfig{
+			privileged.PrivilegedNil: {Severity: "error"},
+		},
+	}
+	auditors, err := Auditors(conf)
+	require.NoError(t, err)
+
+	report := test.AuditMultiple(t, "../privileged/fixtures", "privileged-nil.yml", auditors, []string{privileged.PrivilegedNil}, "", test.MANIFEST_MODE)
+	assert.True(t, report.HasErrors())
+}
+
+func TestAuditorsWithRuleConfigDisablesOverriddenResults(t *testing.T) {
+	conf := config.KubeauditConfig{
+		EnabledAuditors: enabledAuditorsToMap([]string{privileged.Name}),
+		Rules: map[string]config.RuleConfig{
+			privileged.PrivilegedTrue: {Enabled: k8s.NewFalse()},
+		},
+	}
+	auditors, err := Auditors(conf)
+	require.NoError(t, err)
+
+	test.AuditMultiple(t, "../privileged/fixtures", "privileged-true-allowed.yml", auditors, nil, "", test.MANIFEST_MODE)
+}
+
+func TestAuditorsWithRuleConfigWarnUntil(t *testing.T) {
+	defer func() { now = time.Now }()
+
+	conf := config.KubeauditConfig{
+		EnabledAuditors: enabledAuditorsToMap([]string{privileged.Name}),
+		Rules: map[string]config.RuleConfig{
+			privileged.PrivilegedTrue: {WarnUntil: "2026-12-01"},
+		},
+	}
+	auditors, err := Auditors(conf)
+	require.NoError(t, err)
+
+	cases := []struct {
+		now      time.Time
+		expected kubeaudit.SeverityLevel
+	}{
+		{time.Date(2026, 11, 30, 23, 59, 0, 0, time.UTC), kubeaudit.Warn},
+		{time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), kubeaudit.Error},
+	}
+	for _, tc := range cases {
+		now = func() time.Time { return tc.now }
+		report := test.AuditMultiple(t, "../privileged/fixtures", "privileged-true.yml", auditors, []string{privileged.PrivilegedTrue}, "", test.MANIFEST_MODE)
+		assert.Equal(t, tc.expected, report.Results()[0].GetAuditResults()[0].Severity, tc.now)
+	}
+
+	conf.Rules[privileged.PrivilegedTrue] = config.RuleConfig{WarnUntil: "next week"}
+	_, err = Auditors(conf)
+	assert.Error(t, err)
+}
+
+func TestAuditorsWithInvalidRuleSeverity(t *testing.T) {
+	conf := config.KubeauditConfig{
+		Rules: map[string]config.RuleConfig{
+			seccomp.SeccompProfileMissing: {Severity: "critical"},
+		},
+	}
+	_, err := Auditors(conf)
+	assert.Error(t, err)
+}
+
+func TestAuditorsWithRuleMessage(t *testing.T) {
+	conf := config.KubeauditConfig{
+		EnabledAuditors: enabledAuditorsToMap([]string{privileged.Name}),
+		Rules: map[string]config.RuleConfig{
+			privileged.PrivilegedNil: {Severity: "error", Message: "[{{.Severity}}] Container {{.Metadata.Container}} may be privileged{{.Metadata.Missing}}. See https://runbooks.example.com/{{.Rule}}"},
+		},
+	}
+	auditors, err := Auditors(conf)
+	require.NoError(t, err)
+
+	report := test.AuditMultiple(t, "../privileged/fixtures", "privileged-nil.yml", auditors, []string{privileged.PrivilegedNil}, "", test.MANIFEST_MODE)
+	auditResult := report.Results()[0].GetAuditResults()[0]
+	assert.Equal(t, "[error] Container container may be privileged. See https://runbooks.example.com/PrivilegedNil", auditResult.Message)
+}
+
+func TestAuditorsWithMessageCatalog(t *testing.T) {
+	messages := map[string]map[string]string{
+		"fr":    {privileged.PrivilegedNil: "Le conteneur {{.Metadata.Container}} peut être privilégié"},
+		"pt_BR": {privileged.PrivilegedNil: "O contêiner {{.Metadata.Container}} pode ser privilegiado"},
+	}
+	ruleMessage := map[string]config.RuleConfig{privileged.PrivilegedNil: {Message: "Container {{.Metadata.Container}} may be privileged"}}
+
+	cases := []struct {
+		description string
+		locale      string
+		env         map[string]string
+		rules       map[string]config.RuleConfig
+		expected    string
+	}{
+		{"locale of the config", "fr", nil, nil, "Le conteneur container peut être privilégié"},
+		{"region falls back to the language", "fr_CA", nil, nil, "Le conteneur container peut être privilégié"},
+		{"locale of the environment", "", map[string]string{"LANG": "pt-BR.UTF-8"}, nil, "O contêiner container pode ser privilegiado"},
+		{"LC_ALL takes precedence", "", map[string]string{"LC_ALL": "fr_FR.UTF-8", "LANG": "pt_BR.UTF-8"}, nil, "Le conteneur container peut être privilégié"},
+		{"catalog takes precedence over the rule message", "fr", nil, ruleMessage, "Le conteneur container peut être privilégié"},
+		{"rule message without a catalog for the locale", "de", nil, ruleMessage, "Container container may be privileged"},
+		{"original message without a catalog for the locale", "de", nil, nil, "privileged is not set in container SecurityContext. Privileged defaults to 'false' but it should be explicitly set to 'false'."},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.description, func(t *testing.T) {
+			getenv = func(key string) string { return tc.env[key] }
+			defer func() { getenv = os.Getenv }()
+
+			conf := config.KubeauditConfig{
+				EnabledAuditors: enabledAuditorsToMap([]string{privileged.Name}),
+				Rules:           tc.rules,
+				Locale:          tc.locale,
+				Messages:        messages,
+			}
+			auditors, err := Auditors(conf)
+			require.NoError(t, err)
+
+			report := test.AuditMultiple(t, "../privileged/fixtures", "privileged-nil.yml", auditors, []string{privileged.PrivilegedNil}, "", test.MANIFEST_MODE)
+			assert.Equal(t, tc.expected, report.Results()[0].GetAuditResults()[0].Message)
+		})
+	}
+}
+
+func TestAuditorsWithInvalidRuleMessage(t *testing.T) {
+	conf := config.KubeauditConfig{
+		Rules: map[string]config.RuleConfig{
+			seccomp.SeccompProfileMissing: {Message: "{{.Message"},
+		},
+	}
+	_, err := Auditors(conf)
+	assert.Error(t, err)
+
+	conf = config.KubeauditConfig{
+		Locale:   "fr",
+		Messages: map[string]map[string]string{"fr": {seccomp.SeccompProfileMissing: "{{.Message"}},
+	}
+	_, err = Auditors(conf)
+	assert.Error(t, err)
+}
+
+func TestOnlyRules(t *testing.T) {
+	auditors := OnlyRules([]kubeaudit.Auditable{privileged.New(), seccomp.New()}, []string{seccomp.SeccompProfileMissing, ""})
+	test.AuditMultiple(t, "../privileged/fixtures", "privileged-true.yml", auditors, []string{seccomp.SeccompProfileMissing}, "", test.MANIFEST_MODE)
+
+	// The overridden results of the rules are kept
+	auditors = OnlyRules([]kubeaudit.Auditable{privileged.New(), seccomp.New()}, []string{privileged.PrivilegedTrue})
+	test.AuditMultiple(t, "../privileged/fixtures", "privileged-true-allowed.yml", auditors, []string{override.GetOverriddenResultName(privileged.PrivilegedTrue)}, "", test.MANIFEST_MODE)
+
+	// No rules keeps every audit result
+	auditors = OnlyRules([]kubeaudit.Auditable{privileged.New()}, nil)
+	test.AuditMultiple(t, "../privileged/fixtures", "privileged-true.yml", auditors, []string{privileged.PrivilegedTrue}, "", test.MANIFEST_MODE)
+}
+
+func TestWithReferences(t *testing.T) {
+	auditors := WithReferences([]kubeaudit.Auditable{privileged.New()})
+	report := test.AuditMultiple(t, "../privileged/fixtures", "privileged-true.yml", auditors, []string{privileged.PrivilegedTrue}, "", test.MANIFEST_MODE)
+
+	for _, finding := range report.Findings() {
+		assert.Contains(t, finding.References, kubeaudit.Reference{
+			Type: kubeaudit.ReferenceDocs,
+			URL:  "https://github.com/Shopify/kubeaudit/blob/main/docs/auditors/privileged.md",
+		})
+		assert.Contains(t, finding.References, kubeaudit.Reference{Type: kubeaudit.ReferenceCIS, ID: "5.2.2", URL: "https://www.cisecurity.org/benchmark/kubernetes"})
+	}
+}
+
+func TestWithFields(t *testing.T) {
+	auditors := WithFields([]kubeaudit.Auditable{privileged.New(), seccomp.New()})
+	report := test.AuditMultiple(t, "../privileged/fixtures", "privileged-true.yml", auditors, []string{privileged.PrivilegedTrue, seccomp.SeccompProfileMissing}, "", test.MANIFEST_MODE)
+
+	fields := map[string]string{}
+	for _, finding := range report.Findings() {
+		fields[finding.Rule] = finding.AuditResult.Field
+	}
+	assert.Equal(t, map[string]string{
+		privileged.PrivilegedTrue:     kubeaudit.ContainerFieldPrefix + "securityContext.privileged",
+		seccomp.SeccompProfileMissing: "securityContext.seccompProfile",
+	}, fields)
+
+	// Overridden results have the field of the rule they override
+	assert.Equal(t, "securityContext.seccompProfile", getField(seccomp.Name, override.GetOverriddenResultName(seccomp.SeccompProfileMissing)))
+}
@@ -0,0 +1,170 @@
+package annotations
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const Name = "annotations"
+
+const (
+	// AnnotationMissing occurs when no annotation of the resource matches a required annotation pattern
+	AnnotationMissing = "AnnotationMissing"
+	// AnnotationValueInvalid occurs when a required annotation is present but its value does not match the required
+	// value pattern
+	AnnotationValueInvalid = "AnnotationValueInvalid"
+	// AnnotationForbidden occurs when an annotation of the resource or its pod template matches a forbidden
+	// annotation pattern
+	AnnotationForbidden = "AnnotationForbidden"
+)
+
+const OverrideLabel = "allow-annotation-policy-violation"
+
+// Annotations implements Auditable
+type Annotations struct {
+	required  []compiledPattern
+	forbidden []compiledPattern
+}
+
+func New(config Config) (*Annotations, error) {
+	required, err := compilePatterns(config.Required)
+	if err != nil {
+		return nil, fmt.Errorf("error creating annotations auditor: %w", err)
+	}
+
+	forbidden, err := compilePatterns(config.Forbidden)
+	if err != nil {
+		return nil, fmt.Errorf("error creating annotations auditor: %w", err)
+	}
+
+	return &Annotations{
+		required:  required,
+		forbidden: forbidden,
+	}, nil
+}
+
+// Audit checks that workloads and namespaces have the required annotations and do not have forbidden annotations
+func (a *Annotations) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	if !k8s.IsNamespaceV1(resource) && k8s.GetPodSpec(resource) == nil {
+		return nil, nil
+	}
+	if len(a.required) == 0 && len(a.forbidden) == 0 {
+		return nil, nil
+	}
+
+	objectMeta := k8s.GetObjectMeta(resource)
+	if objectMeta == nil {
+		return nil, nil
+	}
+	annotations := objectMeta.GetAnnotations()
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, pattern := range a.required {
+		if auditResult := auditRequired(pattern, annotations); auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+
+	// Debug and development toggles are often set on the pod template rather than the workload
+	forbiddenAnnotations := annotations
+	if podObjectMeta := k8s.GetPodObjectMeta(resource); podObjectMeta != nil && podObjectMeta != objectMeta {
+		forbiddenAnnotations = mergeAnnotations(annotations, podObjectMeta.GetAnnotations())
+	}
+	for _, pattern := range a.forbidden {
+		auditResults = append(auditResults, auditForbidden(pattern, forbiddenAnnotations)...)
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, OverrideLabel)
+	}
+	return auditResults, nil
+}
+
+func auditRequired(pattern compiledPattern, annotations map[string]string) *kubeaudit.AuditResult {
+	var invalidKey string
+	for _, key := range sortedKeys(annotations) {
+		if !pattern.matchesKey(key) {
+			continue
+		}
+		if pattern.matchesValue(annotations[key]) {
+			return nil
+		}
+		if invalidKey == "" {
+			invalidKey = key
+		}
+	}
+
+	if invalidKey != "" {
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     AnnotationValueInvalid,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Annotation %s does not have a valid value. Its value should match \"%s\".", invalidKey, pattern.Value),
+			Metadata: kubeaudit.Metadata{
+				"Annotation":    invalidKey,
+				"Value":         annotations[invalidKey],
+				"ExpectedValue": pattern.Value,
+			},
+		}
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     AnnotationMissing,
+		Severity: kubeaudit.Error,
+		Message:  fmt.Sprintf("Required annotation is missing. An annotation matching \"%s\" should be added.", pattern.String()),
+		Metadata: kubeaudit.Metadata{
+			"Annotation": pattern.String(),
+		},
+	}
+}
+
+func auditForbidden(pattern compiledPattern, annotations map[string]string) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+	for _, key := range sortedKeys(annotations) {
+		if !pattern.matchesKey(key) || !pattern.matchesValue(annotations[key]) {
+			continue
+		}
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     AnnotationForbidden,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Forbidden annotation %s is set. It matches \"%s\" and should be removed.", key, pattern.String()),
+			Metadata: kubeaudit.Metadata{
+				"Annotation": key,
+				"Pattern":    pattern.String(),
+			},
+		})
+	}
+	return auditResults
+}
+
+func mergeAnnotations(annotations ...map[string]string) map[string]string {
+	merged := map[string]string{}
+	for _, m := range annotations {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,85 @@
+package annotations
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+var testConfig = Config{
+	Required: []Pattern{
+		{Key: "owner"},
+		{Key: "data-classification", Value: "public|internal|confidential"},
+	},
+	Forbidden: []Pattern{
+		{Key: `debug\..*`},
+		{Key: `dev\.example\.com/.*`, Value: "true"},
+	},
+}
+
+func TestAuditAnnotations(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"annotations-valid.yml", nil},
+		{"annotations-missing.yml", []string{AnnotationMissing}},
+		{"annotation-value-invalid.yml", []string{AnnotationValueInvalid}},
+		{"annotation-forbidden.yml", []string{AnnotationForbidden}},
+		{"namespace-annotations-missing.yml", []string{AnnotationMissing}},
+		{"annotations-missing-allowed.yml", []string{override.GetOverriddenResultName(AnnotationMissing)}},
+		{"annotations-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	auditor, err := New(testConfig)
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditAnnotationsForbiddenPodTemplate(t *testing.T) {
+	auditor, err := New(testConfig)
+	require.NoError(t, err)
+
+	report := test.GetReport(t, fixtureDir, "annotation-forbidden.yml", []kubeaudit.Auditable{auditor}, "", test.MANIFEST_MODE)
+
+	var forbidden []string
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			forbidden = append(forbidden, auditResult.Metadata["Annotation"])
+		}
+	}
+	assert.Equal(t, []string{"debug.example.com/enabled", "dev.example.com/hot-reload"}, forbidden)
+}
+
+func TestAuditAnnotationsNoConfig(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+	test.AuditManifest(t, fixtureDir, "annotations-missing.yml", auditor, nil)
+}
+
+func TestNewInvalidPattern(t *testing.T) {
+	_, err := New(Config{Required: []Pattern{{Key: "owner", Value: "("}}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Forbidden: []Pattern{{Value: "true"}}})
+	assert.Error(t, err)
+}
+
+func TestParsePattern(t *testing.T) {
+	assert.Equal(t, Pattern{Key: "owner"}, ParsePattern("owner"))
+	assert.Equal(t, Pattern{Key: "data-classification", Value: "public|internal"}, ParsePattern("data-classification=public|internal"))
+}
@@ -0,0 +1,77 @@
+package annotations
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Config struct {
+	// Required lists the annotations which must be present. Each pattern must match at least one annotation
+	Required []Pattern `yaml:"required"`
+	// Forbidden lists the annotations which must not be present
+	Forbidden []Pattern `yaml:"forbidden"`
+}
+
+// Pattern matches annotations by key and optionally by value. Both are regular expressions which must match the
+// whole key or value. An empty value matches any value
+type Pattern struct {
+	Key   string `yaml:"key"`
+	Value string `yaml:"value"`
+}
+
+// ParsePattern parses a pattern of the form "key" or "key=value"
+func ParsePattern(value string) Pattern {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) == 1 {
+		return Pattern{Key: parts[0]}
+	}
+	return Pattern{Key: parts[0], Value: parts[1]}
+}
+
+func (p Pattern) String() string {
+	if p.Value == "" {
+		return p.Key
+	}
+	return p.Key + "=" + p.Value
+}
+
+// compiledPattern is a Pattern with its regular expressions compiled
+type compiledPattern struct {
+	Pattern
+	key   *regexp.Regexp
+	value *regexp.Regexp
+}
+
+func compilePatterns(patterns []Pattern) ([]compiledPattern, error) {
+	compiled := make([]compiledPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern.Key == "" {
+			return nil, fmt.Errorf("annotation pattern %q has no key", pattern.String())
+		}
+
+		key, err := regexp.Compile("^(?:" + pattern.Key + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid annotation key pattern %q: %w", pattern.Key, err)
+		}
+
+		var value *regexp.Regexp
+		if pattern.Value != "" {
+			value, err = regexp.Compile("^(?:" + pattern.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid annotation value pattern %q: %w", pattern.Value, err)
+			}
+		}
+
+		compiled = append(compiled, compiledPattern{Pattern: pattern, key: key, value: value})
+	}
+	return compiled, nil
+}
+
+func (p compiledPattern) matchesKey(key string) bool {
+	return p.key.MatchString(key)
+}
+
+func (p compiledPattern) matchesValue(value string) bool {
+	return p.value == nil || p.value.MatchString(value)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/Shopify/kubeaudit/pkg/fix"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/Shopify/kubeaudit/pkg/windows"
 )
 
 const Name = "apparmor"
@@ -64,7 +65,7 @@ func (a *AppArmor) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.
 
 	auditResults = append(auditResults, auditPodAnnotations(resource, containerNames)...)
 
-	return auditResults, nil
+	return windows.LinuxOnly(resource, auditResults), nil
 }
 
 func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudit.AuditResult {
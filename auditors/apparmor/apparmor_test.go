@@ -71,3 +71,16 @@ func TestFixAppArmor(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditAppArmorInitAndEphemeralContainers(t *testing.T) {
+	report := test.AuditManifest(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, New(), []string{AppArmorAnnotationMissing})
+	assert.Equal(t, test.InitAndEphemeralContainers, test.AuditedContainers(report, AppArmorAnnotationMissing))
+
+	resources, _ := test.FixSetup(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, New())
+	for _, resource := range resources {
+		annotations := k8s.GetAnnotations(resource)
+		for _, container := range k8s.GetContainers(resource) {
+			assert.Equal(t, ProfileRuntimeDefault, annotations[getContainerAnnotation(container)])
+		}
+	}
+}
@@ -14,21 +14,43 @@ const (
 	// AutomountServiceAccountTokenTrueAndDefaultSA occurs when automountServiceAccountToken is either not set
 	// (which defaults to true) or explicitly set to true, and serviceAccountName is either not set or set to "default"
 	AutomountServiceAccountTokenTrueAndDefaultSA = "AutomountServiceAccountTokenTrueAndDefaultSA"
+	// AutomountServiceAccountTokenTrueInNamespaceDefaultSA occurs when the default service account of a namespace does
+	// not set automountServiceAccountToken to false, or the namespace has no default service account. It is only
+	// reported if Config.NamespaceDefaultServiceAccount is set
+	AutomountServiceAccountTokenTrueInNamespaceDefaultSA = "AutomountServiceAccountTokenTrueInNamespaceDefaultSA"
 )
 
 const OverrideLabel = "allow-automount-service-account-token"
 
 // AutomountServiceAccountToken implements Auditable
-type AutomountServiceAccountToken struct{}
+type AutomountServiceAccountToken struct {
+	namespaceDefaultServiceAccount bool
+}
 
 func New() *AutomountServiceAccountToken {
-	return &AutomountServiceAccountToken{}
+	return NewWithConfig(Config{})
+}
+
+// NewWithConfig returns the auditor with the checks enabled by the config
+func NewWithConfig(config Config) *AutomountServiceAccountToken {
+	return &AutomountServiceAccountToken{
+		namespaceDefaultServiceAccount: config.NamespaceDefaultServiceAccount,
+	}
 }
 
 // Audit checks that the deprecated serviceAccount field is not used and that the default service account is not
-// being automatically mounted
+// being automatically mounted. If the namespace default service account check is enabled, it also checks that the
+// default service account of each namespace does not automount its token
 func (a *AutomountServiceAccountToken) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
-	auditResult := auditResource(resource, resources)
+	var auditResult *kubeaudit.AuditResult
+	if k8s.IsNamespaceV1(resource) {
+		if !a.namespaceDefaultServiceAccount {
+			return nil, nil
+		}
+		auditResult = auditNamespace(resource, resources)
+	} else {
+		auditResult = auditResource(resource, resources)
+	}
 	auditResult = override.ApplyOverride(auditResult, Name, "", resource, OverrideLabel)
 	if auditResult != nil {
 		return []*kubeaudit.AuditResult{auditResult}, nil
@@ -75,6 +97,33 @@ func auditResource(resource k8s.Resource, resources []k8s.Resource) *kubeaudit.A
 	return nil
 }
 
+func auditNamespace(resource k8s.Resource, resources []k8s.Resource) *kubeaudit.AuditResult {
+	namespace := k8s.GetObjectMeta(resource).GetName()
+	serviceAccount := getNamespaceDefaultServiceAccount(namespace, resources)
+	if serviceAccount != nil && serviceAccount.AutomountServiceAccountToken != nil && !*serviceAccount.AutomountServiceAccountToken {
+		return nil
+	}
+
+	message := "Default service account of the namespace automounts its token. automountServiceAccountToken should be set to 'false' on the default ServiceAccount."
+	if serviceAccount == nil {
+		message = "Namespace has no default service account with automountServiceAccountToken set to 'false'. The default ServiceAccount created by Kubernetes automounts its token."
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     AutomountServiceAccountTokenTrueInNamespaceDefaultSA,
+		Severity: kubeaudit.Warn,
+		Message:  message,
+		PendingFix: &fixNamespaceDefaultServiceAccount{
+			namespace:             namespace,
+			defaultServiceAccount: serviceAccount,
+		},
+		Metadata: kubeaudit.Metadata{
+			"Namespace": namespace,
+		},
+	}
+}
+
 func isDeprecatedServiceAccountName(podSpec *k8s.PodSpecV1) bool {
 	return podSpec.DeprecatedServiceAccount != ""
 }
@@ -106,3 +155,13 @@ func getDefaultServiceAccount(resources []k8s.Resource) (serviceAccount *k8s.Ser
 	}
 	return
 }
+
+func getNamespaceDefaultServiceAccount(namespace string, resources []k8s.Resource) *k8s.ServiceAccountV1 {
+	for _, resource := range resources {
+		serviceAccount, ok := resource.(*k8s.ServiceAccountV1)
+		if ok && serviceAccount.Name == "default" && serviceAccount.Namespace == namespace {
+			return serviceAccount
+		}
+	}
+	return nil
+}
@@ -44,3 +44,32 @@ func TestAuditAutomountServiceAccountToken(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditNamespaceDefaultServiceAccount(t *testing.T) {
+	auditor := NewWithConfig(Config{NamespaceDefaultServiceAccount: true})
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"namespace-default-sa-automount-false.yml", []string{}},
+		{"namespace-default-sa-automount-nil.yml", []string{AutomountServiceAccountTokenTrueInNamespaceDefaultSA}},
+		{"namespace-default-sa-automount-true.yml", []string{AutomountServiceAccountTokenTrueInNamespaceDefaultSA}},
+		{"namespace-default-sa-missing.yml", []string{AutomountServiceAccountTokenTrueInNamespaceDefaultSA}},
+		{"namespace-default-sa-other-namespace.yml", []string{AutomountServiceAccountTokenTrueInNamespaceDefaultSA}},
+		{"namespace-default-sa-allowed.yml", []string{
+			override.GetOverriddenResultName(AutomountServiceAccountTokenTrueInNamespaceDefaultSA)},
+		},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+
+	// The namespace default service account check is disabled by default
+	test.AuditManifest(t, fixtureDir, "namespace-default-sa-missing.yml", New(), []string{})
+}
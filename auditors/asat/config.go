@@ -0,0 +1,7 @@
+package asat
+
+type Config struct {
+	// NamespaceDefaultServiceAccount enables the AutomountServiceAccountTokenTrueInNamespaceDefaultSA rule, which
+	// checks that the default service account of each namespace doesn't automount its token
+	NamespaceDefaultServiceAccount bool `yaml:"namespaceDefaultServiceAccount"`
+}
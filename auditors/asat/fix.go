@@ -3,6 +3,7 @@ package asat
 import (
 	"fmt"
 
+	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 )
 
@@ -47,3 +48,40 @@ func (f *fixDefaultServiceAccountWithAutomountToken) Apply(resource k8s.Resource
 	}
 	return nil
 }
+
+type fixNamespaceDefaultServiceAccount struct {
+	namespace             string
+	defaultServiceAccount *k8s.ServiceAccountV1
+}
+
+func (f *fixNamespaceDefaultServiceAccount) Plan() string {
+	if f.defaultServiceAccount == nil {
+		return fmt.Sprintf("Create a default ServiceAccount with automountServiceAccountToken set to 'false' in namespace %s", f.namespace)
+	}
+	return fmt.Sprintf("Set automountServiceAccountToken to 'false' in the default ServiceAccount of namespace %s", f.namespace)
+}
+
+func (f *fixNamespaceDefaultServiceAccount) Apply(resource k8s.Resource) []k8s.Resource {
+	if f.defaultServiceAccount == nil {
+		serviceAccount := k8s.NewServiceAccount()
+		serviceAccount.Name = "default"
+		serviceAccount.Namespace = f.namespace
+		serviceAccount.AutomountServiceAccountToken = k8s.NewFalse()
+		return []k8s.Resource{serviceAccount}
+	}
+
+	f.defaultServiceAccount.AutomountServiceAccountToken = k8s.NewFalse()
+	return nil
+}
+
+// Patch implements kubeaudit.PatchableFix. Kubernetes creates the default ServiceAccount of each namespace, so it is
+// patched even if it was not found
+func (f *fixNamespaceDefaultServiceAccount) Patch() *kubeaudit.Patch {
+	return &kubeaudit.Patch{
+		APIVersion: "v1",
+		Kind:       "ServiceAccount",
+		Namespace:  f.namespace,
+		Name:       "default",
+		MergePatch: `{"automountServiceAccountToken":false}`,
+	}
+}
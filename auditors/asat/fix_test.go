@@ -1,11 +1,16 @@
 package asat
 
 import (
+	"bytes"
+	"fmt"
+	"strings"
 	"testing"
 
+	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/internal/test"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFixAutomountServiceAccountToken(t *testing.T) {
@@ -64,3 +69,36 @@ func TestFixAutomountServiceAccountToken(t *testing.T) {
 		})
 	}
 }
+
+func TestFixNamespaceDefaultServiceAccount(t *testing.T) {
+	files := []string{
+		"namespace-default-sa-automount-nil.yml",
+		"namespace-default-sa-automount-true.yml",
+		"namespace-default-sa-missing.yml",
+		"namespace-default-sa-other-namespace.yml",
+	}
+
+	for _, file := range files {
+		t.Run(file, func(t *testing.T) {
+			resources, _ := test.FixSetup(t, fixtureDir, file, NewWithConfig(Config{NamespaceDefaultServiceAccount: true}))
+			namespace := strings.Split(file, ".")[0]
+
+			var found bool
+			for _, resource := range resources {
+				serviceAccount, ok := resource.(*k8s.ServiceAccountV1)
+				if !ok || serviceAccount.Name != "default" || serviceAccount.Namespace != namespace {
+					continue
+				}
+				found = true
+				assert.Equal(t, k8s.NewFalse(), serviceAccount.AutomountServiceAccountToken)
+			}
+			assert.True(t, found, "Expected a default ServiceAccount in namespace %s", namespace)
+
+			// In local and cluster mode the default ServiceAccount is patched instead
+			report := test.GetReport(t, fixtureDir, file, []kubeaudit.Auditable{NewWithConfig(Config{NamespaceDefaultServiceAccount: true})}, "", test.MANIFEST_MODE)
+			var patches bytes.Buffer
+			require.NoError(t, report.WritePatches(&patches))
+			assert.Contains(t, patches.String(), fmt.Sprintf(`kubectl patch serviceaccount default --namespace %s --type merge --patch '{"automountServiceAccountToken":false}'`, namespace))
+		})
+	}
+}
@@ -2,11 +2,13 @@ package capabilities
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/Shopify/kubeaudit/pkg/windows"
 )
 
 const Name = "capabilities"
@@ -18,10 +20,17 @@ const (
 	CapabilityShouldDropAll = "CapabilityShouldDropAll"
 	// CapabilityOrSecurityContextMissing  occurs when either the Security Context or Capabilities are not specified
 	CapabilityOrSecurityContextMissing = "CapabilityOrSecurityContextMissing"
+	// CapabilityProfileUnknown occurs when a container selects a capability profile which is not in the config
+	CapabilityProfileUnknown = "CapabilityProfileUnknown"
 )
 
 const overrideLabelPrefix = "allow-capability-"
 
+// ProfileLabel selects the capability profile of a container, whose capabilities the container may add. It is set
+// like override labels, for a container as "container.kubeaudit.io/[container name].capability-profile" or for the
+// whole pod as "kubeaudit.io/capability-profile", with the name of the profile as its value
+const ProfileLabel = "capability-profile"
+
 var DefaultDropList = []string{"ALL"}
 
 var DefaultAllowAddList = []string{""}
@@ -29,11 +38,13 @@ var DefaultAllowAddList = []string{""}
 // Capabilities implements Auditable
 type Capabilities struct {
 	allowAddList []string
+	profiles     map[string][]string
 }
 
 func New(config Config) *Capabilities {
 	return &Capabilities{
 		allowAddList: config.GetAllowAddList(),
+		profiles:     config.GetProfiles(),
 	}
 }
 
@@ -47,8 +58,13 @@ func (a *Capabilities) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeau
 			auditResults = append(auditResults, auditResult)
 		}
 
+		profile, allowAddList, auditResult := a.getAllowAddList(container, resource)
+		if auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+
 		for _, capability := range uniqueCapabilities(container) {
-			for _, auditResult := range auditContainer(container, capability, a.allowAddList) {
+			for _, auditResult := range auditContainer(container, capability, allowAddList, profile) {
 				auditResult = override.ApplyOverride(auditResult, Name, container.Name, resource, getOverrideLabel(capability))
 				if auditResult != nil {
 					auditResults = append(auditResults, auditResult)
@@ -57,14 +73,51 @@ func (a *Capabilities) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeau
 		}
 	}
 
-	return auditResults, nil
+	return windows.LinuxOnly(resource, auditResults), nil
+}
+
+// getAllowAddList returns the capability profile the container selects with the capability-profile label, if any, and
+// the capabilities the container may add: those of the allow add list and of its profile. An audit result is returned
+// if the profile is not in the config, in which case only the capabilities of the allow add list may be added
+func (a *Capabilities) getAllowAddList(container *k8s.ContainerV1, resource k8s.Resource) (string, []string, *kubeaudit.AuditResult) {
+	hasProfile, profile := override.GetContainerOverrideReason(container.Name, resource, ProfileLabel)
+	if !hasProfile {
+		return "", a.allowAddList, nil
+	}
+
+	capabilities, ok := a.profiles[profile]
+	if !ok {
+		names := make([]string, 0, len(a.profiles))
+		for name := range a.profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		known := "No capability profiles are defined in the kubeaudit config."
+		if len(names) > 0 {
+			known = fmt.Sprintf("It should be one of the capability profiles of the kubeaudit config: %s.", strings.Join(names, ", "))
+		}
+		return "", a.allowAddList, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     CapabilityProfileUnknown,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Capability profile \"%s\" is unknown, so only the capabilities of the allow add list may be added. %s", profile, known),
+			Metadata: kubeaudit.Metadata{
+				"Container":         container.Name,
+				"CapabilityProfile": profile,
+			},
+		}
+	}
+
+	allowAddList := make([]string, 0, len(a.allowAddList)+len(capabilities))
+	allowAddList = append(allowAddList, a.allowAddList...)
+	return profile, append(allowAddList, capabilities...), nil
 }
 
 func getOverrideLabel(capability string) string {
 	return overrideLabelPrefix + strings.Replace(strings.ToLower(capability), "_", "-", -1)
 }
 
-func auditContainer(container *k8s.ContainerV1, capability string, allowAddList []string) []*kubeaudit.AuditResult {
+func auditContainer(container *k8s.ContainerV1, capability string, allowAddList []string, profile string) []*kubeaudit.AuditResult {
 	var auditResults []*kubeaudit.AuditResult
 
 	if isCapabilityInArray(capability, allowAddList) {
@@ -74,6 +127,9 @@ func auditContainer(container *k8s.ContainerV1, capability string, allowAddList
 	if SecurityContextOrCapabilities(container) {
 		if IsCapabilityInAddList(container, capability) {
 			message := fmt.Sprintf("Capability \"%s\" added. It should be removed from the capability add list. If you need this capability, add an override label such as '%s: SomeReason'.", capability, override.GetContainerOverrideLabel(container.Name, getOverrideLabel(capability)))
+			if profile != "" {
+				message = fmt.Sprintf("Capability \"%s\" added, which is not in the capability profile \"%s\" of the container. It should be removed from the capability add list. If you need this capability, add it to the profile or add an override label such as '%s: SomeReason'.", capability, profile, override.GetContainerOverrideLabel(container.Name, getOverrideLabel(capability)))
+			}
 			auditResult := &kubeaudit.AuditResult{
 				Auditor:  Name,
 				Rule:     CapabilityAdded,
@@ -88,6 +144,9 @@ func auditContainer(container *k8s.ContainerV1, capability string, allowAddList
 					"Metadata":  capability,
 				},
 			}
+			if profile != "" {
+				auditResult.Metadata["CapabilityProfile"] = profile
+			}
 			auditResults = append(auditResults, auditResult)
 		}
 	}
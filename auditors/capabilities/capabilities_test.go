@@ -5,7 +5,9 @@ import (
 	"testing"
 
 	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
 )
 
 const fixtureDir = "fixtures"
@@ -56,3 +58,57 @@ func TestAuditCapabilities(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditCapabilitiesProfiles(t *testing.T) {
+	profiles := map[string][]string{
+		"net-tools": {"NET_RAW", "NET_ADMIN"},
+		"debug":     {"SYS_PTRACE"},
+	}
+	cases := []struct {
+		file           string
+		config         Config
+		expectedErrors []string
+	}{
+		{"capabilities-profile.yml", Config{Profiles: profiles}, []string{CapabilityAdded}},
+		{"capabilities-profile.yml", Config{Profiles: profiles, AllowAddList: []string{"SYS_ADMIN", "NET_RAW"}}, []string{}},
+		{"capabilities-profile.yml", Config{}, []string{CapabilityProfileUnknown, CapabilityAdded}},
+		{"capabilities-profile-unknown.yml", Config{Profiles: profiles}, []string{CapabilityProfileUnknown, CapabilityAdded}},
+	}
+
+	for _, tc := range cases {
+		test.AuditManifest(t, fixtureDir, tc.file, New(tc.config), tc.expectedErrors)
+	}
+
+	report := test.AuditManifest(t, fixtureDir, "capabilities-profile.yml", New(Config{Profiles: profiles}), []string{CapabilityAdded})
+	auditResults := report.Results()[0].GetAuditResults()
+	if assert.Len(t, auditResults, 2) {
+		assert.Equal(t, "container", auditResults[0].Metadata["Container"])
+		assert.Equal(t, "SYS_ADMIN", auditResults[0].Metadata["Metadata"])
+		assert.Equal(t, "net-tools", auditResults[0].Metadata["CapabilityProfile"])
+		assert.Contains(t, auditResults[0].Message, `not in the capability profile "net-tools"`)
+		assert.Equal(t, "debug", auditResults[1].Metadata["Container"])
+		assert.Equal(t, "NET_RAW", auditResults[1].Metadata["Metadata"])
+		assert.Equal(t, "debug", auditResults[1].Metadata["CapabilityProfile"])
+	}
+
+	report = test.AuditManifest(t, fixtureDir, "capabilities-profile-unknown.yml", New(Config{Profiles: profiles}), []string{CapabilityProfileUnknown, CapabilityAdded})
+	for _, auditResult := range report.Results()[0].GetAuditResults() {
+		if auditResult.Rule == CapabilityProfileUnknown {
+			assert.Equal(t, "net-tool", auditResult.Metadata["CapabilityProfile"])
+			assert.Contains(t, auditResult.Message, "It should be one of the capability profiles of the kubeaudit config: debug, net-tools.")
+		}
+	}
+}
+
+func TestAuditCapabilitiesInitAndEphemeralContainers(t *testing.T) {
+	auditor := New(Config{})
+	report := test.AuditManifest(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, auditor, []string{CapabilityOrSecurityContextMissing})
+	assert.Equal(t, test.InitAndEphemeralContainers, test.AuditedContainers(report, CapabilityOrSecurityContextMissing))
+
+	resources, _ := test.FixSetup(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, auditor)
+	for _, resource := range resources {
+		for _, container := range k8s.GetContainers(resource) {
+			assertCapabilitiesEqual(t, container.SecurityContext.Capabilities.Drop, []string{"ALL"})
+		}
+	}
+}
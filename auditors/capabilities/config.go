@@ -2,6 +2,9 @@ package capabilities
 
 type Config struct {
 	AllowAddList []string `yaml:"allowAddList"`
+	// Profiles are named lists of capabilities, such as "net-tools: [NET_RAW, NET_ADMIN]". Containers may add the
+	// capabilities of the profile they select with the capability-profile label, on top of the allow add list
+	Profiles map[string][]string `yaml:"profiles"`
 }
 
 func (config *Config) GetAllowAddList() []string {
@@ -11,3 +14,10 @@ func (config *Config) GetAllowAddList() []string {
 
 	return config.AllowAddList
 }
+
+func (config *Config) GetProfiles() map[string][]string {
+	if config == nil {
+		return nil
+	}
+	return config.Profiles
+}
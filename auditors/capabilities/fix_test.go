@@ -143,6 +143,41 @@ func TestFixCapabilities(t *testing.T) {
 	})
 }
 
+func TestFixCapabilitiesProfile(t *testing.T) {
+	auditor := New(Config{Profiles: map[string][]string{"net-tools": {"NET_RAW", "NET_ADMIN"}}})
+
+	cases := []struct {
+		testName    string
+		labels      map[string]string
+		expectedAdd []string
+	}{
+		{"Pod profile", map[string]string{override.GetOverrideLabel(ProfileLabel): "net-tools"}, []string{"NET_RAW", "NET_ADMIN"}},
+		{"Container profile", map[string]string{override.GetContainerOverrideLabel("mycontainer", ProfileLabel): "net-tools"}, []string{"NET_RAW", "NET_ADMIN"}},
+		{"Profile of another container", map[string]string{override.GetContainerOverrideLabel("other", ProfileLabel): "net-tools"}, []string{}},
+		{"Unknown profile", map[string]string{override.GetOverrideLabel(ProfileLabel): "debug"}, []string{}},
+		{"Expired profile", map[string]string{override.GetOverrideLabel(ProfileLabel): "2020-01-01_net-tools"}, []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			resource := newPod([]string{"NET_RAW", "NET_ADMIN", "SYS_ADMIN", "ALL"}, []string{"NET_BIND_SERVICE"}, nil)
+			k8s.GetPodObjectMeta(resource).SetLabels(tc.labels)
+
+			auditResults, err := auditor.Audit(resource, nil)
+			if !assert.Nil(t, err) {
+				return
+			}
+			for _, auditResult := range auditResults {
+				auditResult.Fix(resource)
+			}
+
+			capabilities := k8s.GetContainers(resource)[0].SecurityContext.Capabilities
+			assertCapabilitiesEqual(t, capabilities.Add, tc.expectedAdd)
+			assertCapabilitiesEqual(t, capabilities.Drop, []string{"ALL"})
+		})
+	}
+}
+
 func assertCapabilitiesEqual(t *testing.T, capabilities []k8s.CapabilityV1, expected []string) {
 	assert := assert.New(t)
 
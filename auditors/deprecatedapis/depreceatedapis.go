@@ -7,6 +7,7 @@ import (
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/internal/k8sinternal"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 )
@@ -18,6 +19,8 @@ const (
 	DeprecatedAPIUsed = "DeprecatedAPIUsed"
 )
 
+const OverrideLabel = "allow-deprecated-api"
+
 // DeprecatedAPIs implements Auditable
 type DeprecatedAPIs struct {
 	CurrentVersion  *Version
@@ -68,6 +71,8 @@ type apiLifecycleIntroduced interface {
 // Audit checks that the resource API version is not deprecated
 func (deprecatedAPIs *DeprecatedAPIs) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
 	var auditResults []*kubeaudit.AuditResult
+	// The override labels are the labels of the resource, not of its last applied configuration
+	labelled := resource
 	lastApplied, ok := k8s.GetAnnotations(resource)[v1.LastAppliedConfigAnnotation]
 	if ok && len(lastApplied) > 0 {
 		resource, _ = k8sinternal.DecodeResource([]byte(lastApplied))
@@ -97,9 +102,9 @@ func (deprecatedAPIs *DeprecatedAPIs) Audit(resource k8s.Resource, _ []k8s.Resou
 						deprecationMessage = deprecationMessage + fmt.Sprintf(", unavailable in v%d.%d+", removedMajor, removedMinor)
 						metadata["RemovedMajor"] = strconv.Itoa(removedMajor)
 						metadata["RemovedMinor"] = strconv.Itoa(removedMinor)
-					}
-					if deprecatedAPIs.TargetedVersion != nil && deprecatedAPIs.TargetedVersion.Major >= removedMajor && deprecatedAPIs.TargetedVersion.Minor >= removedMinor {
-						severity = kubeaudit.Error
+						if deprecatedAPIs.TargetedVersion != nil && (deprecatedAPIs.TargetedVersion.Major > removedMajor || deprecatedAPIs.TargetedVersion.Major == removedMajor && deprecatedAPIs.TargetedVersion.Minor >= removedMinor) {
+							severity = kubeaudit.Error
+						}
 					}
 				}
 				if introduced, hasIntroduced := resource.(apiLifecycleIntroduced); hasIntroduced {
@@ -130,5 +135,16 @@ func (deprecatedAPIs *DeprecatedAPIs) Audit(resource k8s.Resource, _ []k8s.Resou
 		}
 
 	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", labelled, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", labelled, OverrideLabel)
+	}
 	return auditResults, nil
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -29,18 +30,41 @@ func TestAuditDeprecatedAPIs(t *testing.T) {
 		{"cronjob.yml", "1.21", "", kubeaudit.Warn},      // Warn, deprecated in the current version
 		{"cronjob.yml", "", "1.20", kubeaudit.Warn},      // Warn is the serverity by default if no current version
 		{"cronjob.yml", "", "1.25", kubeaudit.Error},     // Error, not available in the targeted version
+		{"cronjob.yml", "", "1.29", kubeaudit.Error},     // Error, removed before the targeted version
+		{"cronjob.yml", "", "2.0", kubeaudit.Error},      // Error, the targeted major version is after the removal
+		{"podsecuritypolicy.yml", "1.20", "1.24", kubeaudit.Info},
+		{"podsecuritypolicy.yml", "1.23", "1.24", kubeaudit.Warn},
+		{"podsecuritypolicy.yml", "1.23", "1.29", kubeaudit.Error},
 	}
 
-	message := "batch/v1beta1 CronJob is deprecated in v1.21+, unavailable in v1.25+, introduced in v1.8+; use batch/v1 CronJob"
-	metadata := kubeaudit.Metadata{
-		"DeprecatedMajor":  "1",
-		"DeprecatedMinor":  "21",
-		"RemovedMajor":     "1",
-		"RemovedMinor":     "25",
-		"IntroducedMajor":  "1",
-		"IntroducedMinor":  "8",
-		"ReplacementGroup": "batch/v1",
-		"ReplacementKind":  "CronJob",
+	expected := map[string]struct {
+		message  string
+		metadata kubeaudit.Metadata
+	}{
+		"cronjob.yml": {
+			message: "batch/v1beta1 CronJob is deprecated in v1.21+, unavailable in v1.25+, introduced in v1.8+; use batch/v1 CronJob",
+			metadata: kubeaudit.Metadata{
+				"DeprecatedMajor":  "1",
+				"DeprecatedMinor":  "21",
+				"RemovedMajor":     "1",
+				"RemovedMinor":     "25",
+				"IntroducedMajor":  "1",
+				"IntroducedMinor":  "8",
+				"ReplacementGroup": "batch/v1",
+				"ReplacementKind":  "CronJob",
+			},
+		},
+		"podsecuritypolicy.yml": {
+			message: "policy/v1beta1 PodSecurityPolicy is deprecated in v1.21+, unavailable in v1.25+, introduced in v1.10+",
+			metadata: kubeaudit.Metadata{
+				"DeprecatedMajor": "1",
+				"DeprecatedMinor": "21",
+				"RemovedMajor":    "1",
+				"RemovedMinor":    "25",
+				"IntroducedMajor": "1",
+				"IntroducedMinor": "10",
+			},
+		},
 	}
 
 	for i, tc := range cases {
@@ -52,17 +76,27 @@ func TestAuditDeprecatedAPIs(t *testing.T) {
 			auditor, err := New(Config{CurrentVersion: tc.currentVersion, TargetedVersion: tc.targetedVersion})
 			require.Nil(t, err)
 			report := test.AuditManifest(t, fixtureDir, tc.file, auditor, []string{DeprecatedAPIUsed})
-			assertReport(t, report, tc.expectedSeverity, message, metadata)
+			assertReport(t, report, tc.expectedSeverity, expected[tc.file].message, expected[tc.file].metadata)
 
 			report = test.AuditLocal(t, fixtureDir, tc.file, auditor, fmt.Sprintf("%s-%d", strings.Split(tc.file, ".")[0], i), []string{DeprecatedAPIUsed})
 
 			if report != nil {
-				assertReport(t, report, tc.expectedSeverity, message, metadata)
+				assertReport(t, report, tc.expectedSeverity, expected[tc.file].message, expected[tc.file].metadata)
 			}
 		})
 	}
 }
 
+func TestAuditDeprecatedAPIsOverride(t *testing.T) {
+	auditor, err := New(Config{})
+	require.Nil(t, err)
+	report := test.AuditManifest(t, fixtureDir, "cronjob-allowed.yml", auditor, []string{override.GetOverriddenResultName(DeprecatedAPIUsed)})
+	auditResult := report.Results()[0].GetAuditResults()[0]
+	assert.Equal(t, kubeaudit.Info, auditResult.Severity)
+	assert.Equal(t, "SomeReason", auditResult.Metadata["OverrideReason"])
+	assert.Equal(t, "kubeaudit.io/allow-deprecated-api", auditResult.OverrideLabel)
+}
+
 func assertReport(t *testing.T, report *kubeaudit.Report, expectedSeverity kubeaudit.SeverityLevel, message string, metadata map[string]string) {
 	assert.Equal(t, 1, len(report.Results()))
 	for _, result := range report.Results() {
@@ -0,0 +1,31 @@
+package egress
+
+const (
+	// DefaultDNSNamespace is the namespace the cluster DNS pods run in by default
+	DefaultDNSNamespace = "kube-system"
+)
+
+// DefaultDNSPodLabels are the labels of the cluster DNS pods by default
+var DefaultDNSPodLabels = map[string]string{"k8s-app": "kube-dns"}
+
+type Config struct {
+	// DNSNamespace is the namespace of the cluster DNS pods. Egress traffic to these pods is allowed by the generated
+	// default deny egress NetworkPolicy
+	DNSNamespace string `yaml:"dnsNamespace"`
+	// DNSPodLabels are the labels of the cluster DNS pods. If empty, DNS traffic to all pods in DNSNamespace is allowed
+	DNSPodLabels map[string]string `yaml:"dnsPodLabels"`
+}
+
+func (config *Config) GetDNSNamespace() string {
+	if config == nil || config.DNSNamespace == "" {
+		return DefaultDNSNamespace
+	}
+	return config.DNSNamespace
+}
+
+func (config *Config) GetDNSPodLabels() map[string]string {
+	if config == nil || config.DNSPodLabels == nil {
+		return DefaultDNSPodLabels
+	}
+	return config.DNSPodLabels
+}
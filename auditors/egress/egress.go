@@ -0,0 +1,192 @@
+package egress
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const Name = "egress"
+
+const (
+	// NamespaceEgressUnrestricted occurs when there is no NetworkPolicy restricting egress traffic for all pods in
+	// a namespace
+	NamespaceEgressUnrestricted = "NamespaceEgressUnrestricted"
+	// WorkloadEgressUnrestricted occurs when a workload's pods are not selected by any NetworkPolicy restricting
+	// egress traffic
+	WorkloadEgressUnrestricted = "WorkloadEgressUnrestricted"
+)
+
+const OverrideLabel = "allow-unrestricted-egress"
+
+const egressPolicyType = "Egress"
+
+// EgressNetworkPolicies implements Auditable
+type EgressNetworkPolicies struct {
+	dnsNamespace string
+	dnsPodLabels map[string]string
+}
+
+func New(config Config) *EgressNetworkPolicies {
+	return &EgressNetworkPolicies{
+		dnsNamespace: config.GetDNSNamespace(),
+		dnsPodLabels: config.GetDNSPodLabels(),
+	}
+}
+
+// Audit checks that egress traffic is restricted by a NetworkPolicy for each namespace and for the pods of each
+// workload
+func (a *EgressNetworkPolicies) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResult *kubeaudit.AuditResult
+
+	switch kubeType := resource.(type) {
+	case *k8s.NamespaceV1:
+		auditResult = a.auditNamespace(kubeType, resources)
+	case *k8s.PodTemplateV1:
+		return nil, nil
+	default:
+		if k8s.GetPodSpec(resource) == nil {
+			return nil, nil
+		}
+		auditResult = auditWorkload(resource, resources)
+	}
+
+	auditResult = override.ApplyOverride(auditResult, Name, "", resource, OverrideLabel)
+	if auditResult != nil {
+		return []*kubeaudit.AuditResult{auditResult}, nil
+	}
+	return nil, nil
+}
+
+func (a *EgressNetworkPolicies) auditNamespace(namespace *k8s.NamespaceV1, resources []k8s.Resource) *kubeaudit.AuditResult {
+	hasDefaultDeny := false
+	for _, networkPolicy := range getEgressNetworkPolicies(resources, namespace.Name) {
+		if !isCatchAllNetworkPolicy(networkPolicy) {
+			continue
+		}
+
+		// NetworkPolicies are additive so a default deny NetworkPolicy would not restrict anything
+		if allEgressTrafficAllowed(networkPolicy) {
+			return &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     NamespaceEgressUnrestricted,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("Egress traffic is not restricted for all pods in namespace %s because NetworkPolicy %s allows all egress traffic.", namespace.Name, networkPolicy.Name),
+				Metadata: kubeaudit.Metadata{
+					"Namespace":  namespace.Name,
+					"PolicyName": networkPolicy.Name,
+				},
+			}
+		}
+		hasDefaultDeny = true
+	}
+
+	if hasDefaultDeny {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     NamespaceEgressUnrestricted,
+		Severity: kubeaudit.Error,
+		Message:  fmt.Sprintf("Egress traffic is not restricted for all pods in namespace %s. A default deny egress NetworkPolicy should be added.", namespace.Name),
+		Metadata: kubeaudit.Metadata{
+			"Namespace": namespace.Name,
+		},
+		PendingFix: &fixByAddingDefaultDenyEgressNetworkPolicy{
+			namespace:    namespace.Name,
+			dnsNamespace: a.dnsNamespace,
+			dnsPodLabels: a.dnsPodLabels,
+		},
+	}
+}
+
+// auditWorkload checks that the workload's pods are selected by at least one egress NetworkPolicy and that none of
+// the selecting NetworkPolicies allow all egress traffic, since NetworkPolicies are additive
+func auditWorkload(resource k8s.Resource, resources []k8s.Resource) *kubeaudit.AuditResult {
+	namespace := getNamespace(resource)
+	podLabels := labels.Set(k8s.GetLabels(resource))
+
+	restricted := false
+	for _, networkPolicy := range getEgressNetworkPolicies(resources, namespace) {
+		selector, err := metav1.LabelSelectorAsSelector(&networkPolicy.Spec.PodSelector)
+		if err != nil || !selector.Matches(podLabels) {
+			continue
+		}
+		if allEgressTrafficAllowed(networkPolicy) {
+			restricted = false
+			break
+		}
+		restricted = true
+	}
+
+	if restricted {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     WorkloadEgressUnrestricted,
+		Severity: kubeaudit.Warn,
+		Message:  "Egress traffic is not restricted for the pods of this workload. A NetworkPolicy restricting egress traffic should select these pods.",
+		Metadata: kubeaudit.Metadata{
+			"Namespace": namespace,
+		},
+	}
+}
+
+// getEgressNetworkPolicies returns the NetworkPolicies in the namespace which apply to egress traffic
+func getEgressNetworkPolicies(resources []k8s.Resource, namespace string) []*k8s.NetworkPolicyV1 {
+	var networkPolicies []*k8s.NetworkPolicyV1
+	for _, resource := range resources {
+		networkPolicy, ok := resource.(*k8s.NetworkPolicyV1)
+		if ok && getNamespace(networkPolicy) == namespace && isEgressNetworkPolicy(networkPolicy) {
+			networkPolicies = append(networkPolicies, networkPolicy)
+		}
+	}
+	return networkPolicies
+}
+
+// isEgressNetworkPolicy returns true if the NetworkPolicy applies to egress traffic. If no policy types are set, the
+// NetworkPolicy applies to egress traffic only if it has egress rules
+func isEgressNetworkPolicy(networkPolicy *k8s.NetworkPolicyV1) bool {
+	if len(networkPolicy.Spec.PolicyTypes) == 0 {
+		return len(networkPolicy.Spec.Egress) > 0
+	}
+	for _, policyType := range networkPolicy.Spec.PolicyTypes {
+		if string(policyType) == egressPolicyType {
+			return true
+		}
+	}
+	return false
+}
+
+// allEgressTrafficAllowed returns true if the NetworkPolicy has an egress rule without any destinations or ports,
+// which allows all egress traffic
+func allEgressTrafficAllowed(networkPolicy *k8s.NetworkPolicyV1) bool {
+	for _, egress := range networkPolicy.Spec.Egress {
+		if len(egress.To) == 0 && len(egress.Ports) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func isCatchAllNetworkPolicy(networkPolicy *k8s.NetworkPolicyV1) bool {
+	return len(networkPolicy.Spec.PodSelector.MatchLabels) == 0 && len(networkPolicy.Spec.PodSelector.MatchExpressions) == 0
+}
+
+// getNamespace returns the namespace of the resource, treating an unset namespace as the default namespace
+func getNamespace(resource k8s.Resource) string {
+	if namespace, ok := resource.(*k8s.NamespaceV1); ok {
+		return namespace.Name
+	}
+	if objectMeta := k8s.GetObjectMeta(resource); objectMeta != nil && objectMeta.GetNamespace() != "" {
+		return objectMeta.GetNamespace()
+	}
+	return "default"
+}
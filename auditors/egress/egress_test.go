@@ -0,0 +1,39 @@
+package egress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditEgressNetworkPolicies(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"namespace-egress-unrestricted.yml", []string{NamespaceEgressUnrestricted, WorkloadEgressUnrestricted}},
+		{"namespace-egress-default-deny.yml", nil},
+		{"namespace-egress-allow-all.yml", []string{NamespaceEgressUnrestricted, WorkloadEgressUnrestricted}},
+		{"workload-egress-restricted.yml", []string{NamespaceEgressUnrestricted}},
+		{"namespace-egress-unrestricted-allowed.yml", []string{
+			override.GetOverriddenResultName(NamespaceEgressUnrestricted),
+			override.GetOverriddenResultName(WorkloadEgressUnrestricted),
+		}},
+		{"namespace-egress-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, New(Config{}), tc.expectedErrors)
+			test.AuditLocal(t, fixtureDir, tc.file, New(Config{}), strings.Split(tc.file, ".")[0], tc.expectedErrors)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package egress
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const DefaultDenyEgressNetworkPolicyName = "default-deny-egress"
+
+// namespaceNameLabel is set automatically on every namespace by Kubernetes 1.21+
+const namespaceNameLabel = "kubernetes.io/metadata.name"
+
+type fixByAddingDefaultDenyEgressNetworkPolicy struct {
+	namespace    string
+	dnsNamespace string
+	dnsPodLabels map[string]string
+}
+
+func (f *fixByAddingDefaultDenyEgressNetworkPolicy) Plan() string {
+	return fmt.Sprintf("Create a new NetworkPolicy resource which denies all egress traffic except DNS to namespace %s", f.dnsNamespace)
+}
+
+func (f *fixByAddingDefaultDenyEgressNetworkPolicy) Apply(resource k8s.Resource) []k8s.Resource {
+	return []k8s.Resource{newDefaultDenyEgressNetworkPolicy(f.namespace, f.dnsNamespace, f.dnsPodLabels)}
+}
+
+func newDefaultDenyEgressNetworkPolicy(namespace, dnsNamespace string, dnsPodLabels map[string]string) k8s.Resource {
+	dnsPeer := k8s.NetworkPolicyPeerV1{
+		NamespaceSelector: &k8s.LabelSelectorV1{
+			MatchLabels: map[string]string{namespaceNameLabel: dnsNamespace},
+		},
+	}
+	if len(dnsPodLabels) > 0 {
+		dnsPeer.PodSelector = &k8s.LabelSelectorV1{MatchLabels: dnsPodLabels}
+	}
+
+	dnsPort := intstr.FromInt(53)
+	udp := k8s.ProtocolV1("UDP")
+	tcp := k8s.ProtocolV1("TCP")
+
+	networkPolicy := &k8s.NetworkPolicyV1{
+		ObjectMeta: k8s.ObjectMetaV1{
+			Name:      DefaultDenyEgressNetworkPolicyName,
+			Namespace: namespace,
+		},
+		Spec: k8s.NetworkPolicySpecV1{
+			PolicyTypes: []k8s.PolicyTypeV1{egressPolicyType},
+			Egress: []k8s.NetworkPolicyEgressRuleV1{
+				{
+					To: []k8s.NetworkPolicyPeerV1{dnsPeer},
+					Ports: []k8s.NetworkPolicyPortV1{
+						{Protocol: &udp, Port: &dnsPort},
+						{Protocol: &tcp, Port: &dnsPort},
+					},
+				},
+			},
+		},
+	}
+
+	networkPolicy.Kind = "NetworkPolicy"
+	networkPolicy.APIVersion = "networking.k8s.io/v1"
+
+	return networkPolicy
+}
@@ -0,0 +1,54 @@
+package egress
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixEgressNetworkPolicies(t *testing.T) {
+	cases := []struct {
+		config               Config
+		expectedDNSPodLabels map[string]string
+	}{
+		{Config{}, DefaultDNSPodLabels},
+		{Config{DNSNamespace: "dns", DNSPodLabels: map[string]string{}}, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.config.GetDNSNamespace(), func(t *testing.T) {
+			auditor := New(tc.config)
+			resources, report := test.FixSetup(t, fixtureDir, "namespace-egress-unrestricted.yml", auditor)
+			require.NotNil(t, report)
+
+			var networkPolicy *k8s.NetworkPolicyV1
+			for _, resource := range resources {
+				if np, ok := resource.(*k8s.NetworkPolicyV1); ok && np.Name == DefaultDenyEgressNetworkPolicyName {
+					networkPolicy = np
+				}
+			}
+			require.NotNil(t, networkPolicy)
+			require.Len(t, networkPolicy.Spec.Egress, 1)
+
+			peers := networkPolicy.Spec.Egress[0].To
+			require.Len(t, peers, 1)
+			assert.Equal(t, tc.config.GetDNSNamespace(), peers[0].NamespaceSelector.MatchLabels[namespaceNameLabel])
+			if tc.expectedDNSPodLabels == nil {
+				assert.Nil(t, peers[0].PodSelector)
+			} else {
+				assert.Equal(t, tc.expectedDNSPodLabels, peers[0].PodSelector.MatchLabels)
+			}
+			assert.Len(t, networkPolicy.Spec.Egress[0].Ports, 2)
+
+			// The generated policy restricts egress for the namespace and all of its workloads
+			for _, resource := range resources {
+				auditResults, err := auditor.Audit(resource, resources)
+				require.NoError(t, err)
+				assert.Empty(t, auditResults)
+			}
+		})
+	}
+}
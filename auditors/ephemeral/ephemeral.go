@@ -0,0 +1,101 @@
+package ephemeral
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const Name = "ephemeral"
+
+const (
+	// EphemeralContainerInProduction occurs when an ephemeral container, such as a debug container added by
+	// kubectl debug, is running in a pod in a production namespace
+	EphemeralContainerInProduction = "EphemeralContainerInProduction"
+)
+
+const OverrideLabel = "allow-ephemeral-container"
+
+// Ephemeral implements Auditable
+type Ephemeral struct {
+	productionNamespaceSelector k8slabels.Selector
+}
+
+func New(config Config) (*Ephemeral, error) {
+	selector, err := k8slabels.ValidatedSelectorFromSet(config.GetProductionNamespaceSelector())
+	if err != nil {
+		return nil, fmt.Errorf("error creating ephemeral auditor: invalid production namespace selector: %w", err)
+	}
+
+	return &Ephemeral{productionNamespaceSelector: selector}, nil
+}
+
+// Audit checks that no ephemeral container is running in a pod in a production namespace. Ephemeral containers are
+// added to running pods without going through the manifests of their workloads, so they are usually debug sessions
+// which were left behind. The security context of ephemeral containers is audited by the other auditors, like the
+// one of any other container
+func (a *Ephemeral) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	if k8s.GetPodSpec(resource) == nil {
+		return nil, nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, container := range k8s.GetEphemeralContainers(resource) {
+		if isTerminated(resource, container.Name) || !a.isProductionNamespace(resource, resources) {
+			continue
+		}
+		auditResult := &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     EphemeralContainerInProduction,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("Ephemeral container %s is running in a pod in a production namespace. Ephemeral containers can't be removed from a pod and are not part of the manifest of its workload. The debugging session should be ended and the pod should be replaced.", container.Name),
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+			},
+		}
+		auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, container.Name, resource, OverrideLabel))
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+	return auditResults, nil
+}
+
+// isProductionNamespace returns true if the namespace of the resource is one of the resources and is selected by the
+// production namespace selector
+func (a *Ephemeral) isProductionNamespace(resource k8s.Resource, resources []k8s.Resource) bool {
+	namespace := k8s.GetObjectMeta(resource).GetNamespace()
+	if namespace == "" {
+		return false
+	}
+
+	for _, r := range resources {
+		if k8s.IsNamespaceV1(r) && k8s.GetObjectMeta(r).GetName() == namespace {
+			return a.productionNamespaceSelector.Matches(k8slabels.Set(k8s.GetObjectMeta(r).GetLabels()))
+		}
+	}
+	return false
+}
+
+// isTerminated returns true if the status of the pod shows that its ephemeral container has terminated. Ephemeral
+// containers without a status, such as the ones of pods in manifests, are considered to be running
+func isTerminated(resource k8s.Resource, containerName string) bool {
+	pod, ok := resource.(*k8s.PodV1)
+	if !ok {
+		return false
+	}
+
+	for _, status := range pod.Status.EphemeralContainerStatuses {
+		if status.Name == containerName {
+			return status.State.Terminated != nil
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package ephemeral
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditEphemeral(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"ephemeral-container-production.yml", []string{EphemeralContainerInProduction}},
+		{"ephemeral-container-staging.yml", nil},
+		{"ephemeral-container-terminated.yml", []string{EphemeralContainerInProduction}},
+		{"ephemeral-container-production-allowed.yml", []string{override.GetOverriddenResultName(EphemeralContainerInProduction)}},
+		{"ephemeral-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditEphemeralTerminated(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	report := test.AuditManifest(t, fixtureDir, "ephemeral-container-terminated.yml", auditor, []string{EphemeralContainerInProduction})
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			assert.Equal(t, "running-debugger", auditResult.Metadata["Container"])
+		}
+	}
+}
+
+func TestAuditEphemeralProductionNamespaceSelector(t *testing.T) {
+	auditor, err := New(Config{ProductionNamespaceSelector: map[string]string{"env": "staging"}})
+	require.NoError(t, err)
+
+	test.AuditManifest(t, fixtureDir, "ephemeral-container-staging.yml", auditor, []string{EphemeralContainerInProduction})
+	test.AuditManifest(t, fixtureDir, "ephemeral-container-production.yml", auditor, nil)
+}
+
+func TestNewInvalidProductionNamespaceSelector(t *testing.T) {
+	_, err := New(Config{ProductionNamespaceSelector: map[string]string{"env": "not a value"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,14 @@
+package etcd
+
+type Config struct {
+	// EncryptionConfigPath is the path to a copy of the kube-apiserver EncryptionConfiguration file. If set, the
+	// configuration is inspected to check that secrets are encrypted by the first provider
+	EncryptionConfigPath string `yaml:"encryptionConfigPath"`
+}
+
+func (config *Config) GetEncryptionConfigPath() string {
+	if config == nil {
+		return ""
+	}
+	return config.EncryptionConfigPath
+}
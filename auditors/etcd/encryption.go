@@ -0,0 +1,65 @@
+package etcd
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"gopkg.in/yaml.v3"
+)
+
+const encryptionConfigurationKind = "EncryptionConfiguration"
+
+// encryptionConfiguration is the subset of the kube-apiserver EncryptionConfiguration needed to determine how
+// secrets are stored (see https://kubernetes.io/docs/tasks/administer-cluster/encrypt-data/)
+type encryptionConfiguration struct {
+	Kind      string `yaml:"kind"`
+	Resources []struct {
+		Resources []string                 `yaml:"resources"`
+		Providers []map[string]interface{} `yaml:"providers"`
+	} `yaml:"resources"`
+}
+
+func readEncryptionConfiguration(path string) (*encryptionConfiguration, error) {
+	configBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading encryption configuration: %w", err)
+	}
+
+	conf := &encryptionConfiguration{}
+	if err := yaml.Unmarshal(configBytes, conf); err != nil {
+		return nil, fmt.Errorf("error parsing encryption configuration: %w", err)
+	}
+	if conf.Kind != encryptionConfigurationKind {
+		return nil, fmt.Errorf("error parsing encryption configuration: expected kind %s, got %q", encryptionConfigurationKind, conf.Kind)
+	}
+	return conf, nil
+}
+
+// secretsEncrypted returns true if secrets are written encrypted. The first entry matching secrets applies and
+// secrets are written with the first provider of that entry. If secrets are not encrypted, it also returns the reason
+func (conf *encryptionConfiguration) secretsEncrypted() (bool, string) {
+	for _, entry := range conf.Resources {
+		if !coversSecrets(entry.Resources) {
+			continue
+		}
+		if len(entry.Providers) == 0 {
+			return false, "no providers are configured for secrets"
+		}
+		if _, ok := entry.Providers[0]["identity"]; ok {
+			return false, "the first provider for secrets is identity, which does not encrypt"
+		}
+		return true, ""
+	}
+	return false, "secrets are not included in the encryption configuration"
+}
+
+// coversSecrets returns true if the resource list includes secrets, either by name or by a wildcard
+func coversSecrets(resources []string) bool {
+	for _, resource := range resources {
+		switch resource {
+		case "secrets", "secrets.", "*.", "*.*":
+			return true
+		}
+	}
+	return false
+}
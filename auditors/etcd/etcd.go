@@ -0,0 +1,246 @@
+package etcd
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const Name = "etcd"
+
+const (
+	// SecretsEncryptionAtRestDisabled occurs when the kube-apiserver is not configured with an encryption provider,
+	// so secrets are stored unencrypted in etcd
+	SecretsEncryptionAtRestDisabled = "SecretsEncryptionAtRestDisabled"
+	// SecretsStoredUnencrypted occurs when the encryption configuration does not encrypt secrets
+	SecretsStoredUnencrypted = "SecretsStoredUnencrypted"
+	// EtcdConnectionInsecure occurs when the kube-apiserver connects to etcd without TLS or without a client
+	// certificate
+	EtcdConnectionInsecure = "EtcdConnectionInsecure"
+	// EtcdClientCertAuthDisabled occurs when etcd does not require client certificates
+	EtcdClientCertAuthDisabled = "EtcdClientCertAuthDisabled"
+	// EtcdClientURLInsecure occurs when etcd serves clients over plain HTTP
+	EtcdClientURLInsecure = "EtcdClientURLInsecure"
+	// EtcdPeerCertAuthDisabled occurs when etcd does not require peer certificates
+	EtcdPeerCertAuthDisabled = "EtcdPeerCertAuthDisabled"
+)
+
+const OverrideLabel = "allow-insecure-etcd"
+
+const (
+	apiServerCommand = "kube-apiserver"
+	etcdCommand      = "etcd"
+)
+
+// Etcd implements Auditable
+type Etcd struct {
+	encryptionConfigPath string
+	encryptionConfig     *encryptionConfiguration
+}
+
+func New(config Config) (*Etcd, error) {
+	encryptionConfigPath := config.GetEncryptionConfigPath()
+	if encryptionConfigPath == "" {
+		return &Etcd{}, nil
+	}
+
+	encryptionConfig, err := readEncryptionConfiguration(encryptionConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("error creating etcd auditor: %w", err)
+	}
+
+	return &Etcd{
+		encryptionConfigPath: encryptionConfigPath,
+		encryptionConfig:     encryptionConfig,
+	}, nil
+}
+
+// Audit checks that the kube-apiserver encrypts secrets at rest and connects to etcd securely, and that etcd only
+// accepts authenticated TLS connections
+func (a *Etcd) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResults []*kubeaudit.AuditResult
+
+	for _, container := range k8s.GetContainers(resource) {
+		var containerResults []*kubeaudit.AuditResult
+		switch getCommand(container) {
+		case apiServerCommand:
+			containerResults = a.auditAPIServer(container)
+		case etcdCommand:
+			containerResults = auditEtcd(container)
+		default:
+			continue
+		}
+
+		if len(containerResults) == 0 {
+			if auditResult := override.ApplyOverride(nil, Name, container.Name, resource, OverrideLabel); auditResult != nil {
+				auditResults = append(auditResults, auditResult)
+			}
+			continue
+		}
+		for _, auditResult := range containerResults {
+			auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, container.Name, resource, OverrideLabel))
+		}
+	}
+
+	return auditResults, nil
+}
+
+func (a *Etcd) auditAPIServer(container *k8s.ContainerV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+	flags := parseFlags(container)
+
+	if _, ok := flags["encryption-provider-config"]; !ok {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     SecretsEncryptionAtRestDisabled,
+			Severity: kubeaudit.Error,
+			Message:  "The kube-apiserver is not configured with an encryption provider so secrets are stored unencrypted in etcd. The '--encryption-provider-config' flag should be set.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+			},
+		})
+	} else if a.encryptionConfig != nil {
+		if encrypted, reason := a.encryptionConfig.secretsEncrypted(); !encrypted {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     SecretsStoredUnencrypted,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("Secrets are stored unencrypted in etcd: %s.", reason),
+				Metadata: kubeaudit.Metadata{
+					"Container":        container.Name,
+					"EncryptionConfig": a.encryptionConfigPath,
+					"Reason":           reason,
+				},
+			})
+		}
+	}
+
+	var reasons []string
+	for _, url := range strings.Split(flags["etcd-servers"], ",") {
+		if strings.HasPrefix(url, "http://") {
+			reasons = append(reasons, fmt.Sprintf("etcd server %s does not use TLS", url))
+		}
+	}
+	for _, flag := range []string{"etcd-cafile", "etcd-certfile", "etcd-keyfile"} {
+		if flags[flag] == "" {
+			reasons = append(reasons, fmt.Sprintf("--%s is not set", flag))
+		}
+	}
+	if len(reasons) > 0 {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     EtcdConnectionInsecure,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("The kube-apiserver does not connect to etcd securely: %s.", strings.Join(reasons, ", ")),
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Reason":    strings.Join(reasons, ", "),
+			},
+		})
+	}
+
+	return auditResults
+}
+
+func auditEtcd(container *k8s.ContainerV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+	flags := parseFlags(container)
+
+	if flags["client-cert-auth"] != "true" {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     EtcdClientCertAuthDisabled,
+			Severity: kubeaudit.Error,
+			Message:  "etcd does not require client certificates so anyone who can reach it can read all cluster data. '--client-cert-auth' should be set to true.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+			},
+		})
+	}
+
+	var insecureURLs []string
+	for _, flag := range []string{"listen-client-urls", "advertise-client-urls"} {
+		for _, url := range strings.Split(flags[flag], ",") {
+			if strings.HasPrefix(url, "http://") {
+				insecureURLs = append(insecureURLs, url)
+			}
+		}
+	}
+	if len(insecureURLs) > 0 {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     EtcdClientURLInsecure,
+			Severity: kubeaudit.Error,
+			Message:  "etcd serves clients without TLS. Client URLs should use https.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"URLs":      strings.Join(insecureURLs, ","),
+			},
+		})
+	}
+
+	if flags["peer-client-cert-auth"] != "true" {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     EtcdPeerCertAuthDisabled,
+			Severity: kubeaudit.Warn,
+			Message:  "etcd does not require peer certificates. '--peer-client-cert-auth' should be set to true.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+			},
+		})
+	}
+
+	return auditResults
+}
+
+// IsControlPlane returns true if one of the containers of the resource runs the kube-apiserver or etcd, which are the
+// containers the auditor checks
+func IsControlPlane(resource k8s.Resource) bool {
+	for _, container := range k8s.GetContainers(resource) {
+		if command := getCommand(container); command == apiServerCommand || command == etcdCommand {
+			return true
+		}
+	}
+	return false
+}
+
+// getCommand returns the name of the binary the container runs. If the container uses the image entrypoint, the
+// container name is used instead
+func getCommand(container *k8s.ContainerV1) string {
+	if len(container.Command) > 0 {
+		return path.Base(container.Command[0])
+	}
+	return container.Name
+}
+
+// parseFlags parses the long flags passed to the container. Flags without a value are set to "true"
+func parseFlags(container *k8s.ContainerV1) map[string]string {
+	flags := map[string]string{}
+
+	args := append(append([]string{}, container.Command...), container.Args...)
+	for i := 0; i < len(args); i++ {
+		if !strings.HasPrefix(args[i], "--") {
+			continue
+		}
+
+		flag := strings.TrimPrefix(args[i], "--")
+		if parts := strings.SplitN(flag, "=", 2); len(parts) == 2 {
+			flags[parts[0]] = parts[1]
+			continue
+		}
+
+		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+			flags[flag] = args[i+1]
+			i++
+			continue
+		}
+		flags[flag] = "true"
+	}
+
+	return flags
+}
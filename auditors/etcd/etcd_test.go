@@ -0,0 +1,59 @@
+package etcd
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditEtcd(t *testing.T) {
+	cases := []struct {
+		file             string
+		encryptionConfig string
+		expectedErrors   []string
+	}{
+		{"apiserver-secure.yml", "", nil},
+		{"apiserver-secure.yml", "encryption-config-aescbc.yml", nil},
+		{"apiserver-secure.yml", "encryption-config-identity.yml", []string{SecretsStoredUnencrypted}},
+		{"apiserver-secure.yml", "encryption-config-configmaps.yml", []string{SecretsStoredUnencrypted}},
+		{"apiserver-insecure.yml", "", []string{SecretsEncryptionAtRestDisabled, EtcdConnectionInsecure}},
+		{"apiserver-insecure-allowed.yml", "", []string{
+			override.GetOverriddenResultName(SecretsEncryptionAtRestDisabled),
+			override.GetOverriddenResultName(EtcdConnectionInsecure),
+		}},
+		{"etcd-secure.yml", "", nil},
+		{"etcd-insecure.yml", "", []string{EtcdClientCertAuthDisabled, EtcdClientURLInsecure, EtcdPeerCertAuthDisabled}},
+		{"etcd-redundant-override.yml", "", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file+" "+tc.encryptionConfig, func(t *testing.T) {
+			t.Parallel()
+			config := Config{}
+			if tc.encryptionConfig != "" {
+				config.EncryptionConfigPath = filepath.Join(fixtureDir, tc.encryptionConfig)
+			}
+			auditor, err := New(config)
+			require.NoError(t, err)
+			// Control plane pods are not created in local mode so only manifest mode is tested
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestNewInvalidEncryptionConfig(t *testing.T) {
+	_, err := New(Config{EncryptionConfigPath: filepath.Join(fixtureDir, "missing.yml")})
+	assert.Error(t, err)
+
+	_, err = New(Config{EncryptionConfigPath: filepath.Join(fixtureDir, "etcd-secure.yml")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,206 @@
+package exposure
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const Name = "exposure"
+
+const (
+	// ServiceNodePortExposed occurs when a Service of type NodePort exposes its ports on every node
+	ServiceNodePortExposed = "ServiceNodePortExposed"
+	// ServiceSourceRangesMissing occurs when a Service of type LoadBalancer doesn't restrict the source ranges of its
+	// clients and is not an internal load balancer
+	ServiceSourceRangesMissing = "ServiceSourceRangesMissing"
+	// IngressTLSMissing occurs when an Ingress serves a host without TLS
+	IngressTLSMissing = "IngressTLSMissing"
+)
+
+const OverrideLabel = "allow-public-exposure"
+
+// SourceRangesAnnotation restricts the source ranges of the clients of a load balancer, like
+// spec.loadBalancerSourceRanges
+const SourceRangesAnnotation = "service.beta.kubernetes.io/load-balancer-source-ranges"
+
+// internalLoadBalancerAnnotations are the annotations which make cloud providers create a load balancer which is only
+// reachable from the network of the cluster, and the values which do
+var internalLoadBalancerAnnotations = map[string][]string{
+	"service.beta.kubernetes.io/aws-load-balancer-internal":   {"true", "0.0.0.0/0"},
+	"service.beta.kubernetes.io/aws-load-balancer-scheme":     {"internal"},
+	"service.beta.kubernetes.io/azure-load-balancer-internal": {"true"},
+	"service.beta.kubernetes.io/oci-load-balancer-internal":   {"true"},
+	"networking.gke.io/load-balancer-type":                    {"Internal"},
+	"cloud.google.com/load-balancer-type":                     {"Internal"},
+}
+
+// Exposure implements Auditable
+type Exposure struct{}
+
+func New() *Exposure {
+	return &Exposure{}
+}
+
+// Audit checks that Services are not exposed outside of the cluster to any client, and that Ingresses serve their
+// hosts over TLS
+func (a *Exposure) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResult *kubeaudit.AuditResult
+	switch resource := resource.(type) {
+	case *k8s.ServiceV1:
+		auditResult = auditService(resource)
+	case *k8s.IngressV1:
+		auditResult = auditIngress(resource)
+	default:
+		return nil, nil
+	}
+
+	if auditResult = override.ApplyOverride(auditResult, Name, "", resource, OverrideLabel); auditResult != nil {
+		return []*kubeaudit.AuditResult{auditResult}, nil
+	}
+	return nil, nil
+}
+
+func auditService(service *k8s.ServiceV1) *kubeaudit.AuditResult {
+	switch service.Spec.Type {
+	case apiv1.ServiceTypeNodePort:
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ServiceNodePortExposed,
+			Severity: kubeaudit.Warn,
+			Message:  "Service of type NodePort exposes its ports on every node, to any client which can reach the nodes. It should be of type ClusterIP behind an Ingress, or of type LoadBalancer with loadBalancerSourceRanges.",
+			Metadata: kubeaudit.Metadata{
+				"NodePorts": getNodePorts(service),
+			},
+		}
+	case apiv1.ServiceTypeLoadBalancer:
+		if isInternalLoadBalancer(service) {
+			return nil
+		}
+		sourceRanges := getSourceRanges(service)
+		if len(sourceRanges) == 0 {
+			return &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     ServiceSourceRangesMissing,
+				Severity: kubeaudit.Warn,
+				Message:  fmt.Sprintf("Service of type LoadBalancer accepts clients from any address. loadBalancerSourceRanges or the %s annotation should be set to the ranges of its clients.", SourceRangesAnnotation),
+			}
+		}
+		for _, sourceRange := range sourceRanges {
+			if sourceRange == "0.0.0.0/0" || sourceRange == "::/0" {
+				return &kubeaudit.AuditResult{
+					Auditor:  Name,
+					Rule:     ServiceSourceRangesMissing,
+					Severity: kubeaudit.Warn,
+					Message:  fmt.Sprintf("Service of type LoadBalancer allows the source range %s, which is any address. The source ranges should be the ranges of its clients.", sourceRange),
+					Metadata: kubeaudit.Metadata{
+						"SourceRanges": strings.Join(sourceRanges, ", "),
+					},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// getSourceRanges returns the source ranges of the load balancer of the Service. The field takes precedence over the
+// annotation, as it does for the cloud providers
+func getSourceRanges(service *k8s.ServiceV1) []string {
+	if len(service.Spec.LoadBalancerSourceRanges) > 0 {
+		return service.Spec.LoadBalancerSourceRanges
+	}
+
+	var sourceRanges []string
+	for _, sourceRange := range strings.Split(service.Annotations[SourceRangesAnnotation], ",") {
+		if sourceRange = strings.TrimSpace(sourceRange); sourceRange != "" {
+			sourceRanges = append(sourceRanges, sourceRange)
+		}
+	}
+	return sourceRanges
+}
+
+func isInternalLoadBalancer(service *k8s.ServiceV1) bool {
+	for annotation, internalValues := range internalLoadBalancerAnnotations {
+		value, ok := service.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		for _, internalValue := range internalValues {
+			if strings.EqualFold(value, internalValue) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getNodePorts returns the node ports of the Service, or "auto" for the ports whose node port is allocated by the
+// cluster
+func getNodePorts(service *k8s.ServiceV1) string {
+	nodePorts := make([]string, 0, len(service.Spec.Ports))
+	for _, port := range service.Spec.Ports {
+		if port.NodePort == 0 {
+			nodePorts = append(nodePorts, "auto")
+			continue
+		}
+		nodePorts = append(nodePorts, strconv.Itoa(int(port.NodePort)))
+	}
+	return strings.Join(nodePorts, ", ")
+}
+
+func auditIngress(ingress *k8s.IngressV1) *kubeaudit.AuditResult {
+	if len(ingress.Spec.TLS) == 0 {
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     IngressTLSMissing,
+			Severity: kubeaudit.Warn,
+			Message:  "Ingress doesn't set tls, so its hosts are served over plain HTTP. tls should be set with a certificate for the hosts.",
+		}
+	}
+
+	var hosts []string
+	for _, rule := range ingress.Spec.Rules {
+		if rule.Host != "" && !isTLSHost(ingress, rule.Host) {
+			hosts = append(hosts, rule.Host)
+		}
+	}
+	if len(hosts) == 0 {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     IngressTLSMissing,
+		Severity: kubeaudit.Warn,
+		Message:  fmt.Sprintf("Ingress serves hosts over plain HTTP: %s. The hosts should be added to tls.", strings.Join(hosts, ", ")),
+		Metadata: kubeaudit.Metadata{
+			"Hosts": strings.Join(hosts, ", "),
+		},
+	}
+}
+
+// isTLSHost returns true if a tls entry of the Ingress covers the host. Entries without hosts cover every host, and
+// wildcard hosts cover the hosts one level below them
+func isTLSHost(ingress *k8s.IngressV1, host string) bool {
+	for _, tls := range ingress.Spec.TLS {
+		if len(tls.Hosts) == 0 {
+			return true
+		}
+		for _, tlsHost := range tls.Hosts {
+			if tlsHost == host {
+				return true
+			}
+			if strings.HasPrefix(tlsHost, "*.") {
+				if i := strings.Index(host, "."); i > 0 && host[i:] == tlsHost[1:] {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
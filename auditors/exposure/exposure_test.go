@@ -0,0 +1,53 @@
+package exposure
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditExposure(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"service-cluster-ip.yml", nil},
+		{"service-node-port.yml", []string{ServiceNodePortExposed}},
+		{"service-load-balancer.yml", []string{ServiceSourceRangesMissing}},
+		{"service-load-balancer-source-ranges.yml", nil},
+		{"service-load-balancer-source-ranges-annotation.yml", nil},
+		{"service-load-balancer-any-source.yml", []string{ServiceSourceRangesMissing}},
+		{"service-load-balancer-internal.yml", nil},
+		{"service-load-balancer-allowed.yml", []string{override.GetOverriddenResultName(ServiceSourceRangesMissing)}},
+		{"service-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+		{"ingress-tls.yml", nil},
+		{"ingress-tls-wildcard.yml", nil},
+		{"ingress-tls-missing.yml", []string{IngressTLSMissing}},
+		{"ingress-tls-host-missing.yml", []string{IngressTLSMissing}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, New(), tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditIngressHosts(t *testing.T) {
+	report := test.AuditManifest(t, fixtureDir, "ingress-tls-host-missing.yml", New(), []string{IngressTLSMissing})
+
+	// Only the host without a tls entry is reported
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			assert.Equal(t, "api.example.com", auditResult.Metadata["Hosts"])
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package hostnet
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+type fixHostPort struct {
+	container *k8s.ContainerV1
+	port      *apiv1.ContainerPort
+}
+
+func (f *fixHostPort) Plan() string {
+	return fmt.Sprintf("Remove hostPort %d from port %d of container %s", f.port.HostPort, f.port.ContainerPort, f.container.Name)
+}
+
+func (f *fixHostPort) Apply(resource k8s.Resource) []k8s.Resource {
+	f.port.HostPort = 0
+	return nil
+}
+
+type fixDNSPolicy struct {
+	podSpec *k8s.PodSpecV1
+}
+
+func (f *fixDNSPolicy) Plan() string {
+	return "Set dnsPolicy to 'ClusterFirst' in PodSpec"
+}
+
+func (f *fixDNSPolicy) Apply(resource k8s.Resource) []k8s.Resource {
+	f.podSpec.DNSPolicy = apiv1.DNSClusterFirst
+	return nil
+}
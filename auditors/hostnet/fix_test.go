@@ -0,0 +1,47 @@
+package hostnet
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestFixHostNetworking(t *testing.T) {
+	cases := []struct {
+		file              string
+		expectedHostPort  int32
+		expectedDNSPolicy apiv1.DNSPolicy
+	}{
+		{"host-port.yml", 0, ""},
+		{"host-port-allowed.yml", 8080, ""},
+		{"dns-policy-host-net-without-host-network.yml", 0, apiv1.DNSClusterFirst},
+		{"dns-policy-host-net-without-host-network-allowed.yml", 0, apiv1.DNSClusterFirstWithHostNet},
+		{"dns-policy-host-net-with-host-network.yml", 0, apiv1.DNSClusterFirstWithHostNet},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			resources, _ := test.FixSetup(t, fixtureDir, tc.file, New())
+			for _, resource := range resources {
+				podSpec := k8s.GetPodSpec(resource)
+				assert.Equal(t, tc.expectedDNSPolicy, podSpec.DNSPolicy)
+				for _, container := range podSpec.Containers {
+					for _, port := range container.Ports {
+						assert.Equal(t, tc.expectedHostPort, port.HostPort)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestFixHostAliases(t *testing.T) {
+	// hostAliases are not removed since the pod may rely on them to resolve the hostnames
+	resources, _ := test.FixSetup(t, fixtureDir, "host-aliases.yml", New())
+	for _, resource := range resources {
+		assert.Len(t, k8s.GetPodSpec(resource).HostAliases, 1)
+	}
+}
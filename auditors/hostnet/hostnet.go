@@ -0,0 +1,139 @@
+package hostnet
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const Name = "hostnet"
+
+const (
+	// HostPortSet occurs when a container port is bound to a port of the node with hostPort
+	HostPortSet = "HostPortSet"
+	// HostAliasesSet occurs when hostAliases adds entries to the hosts file of the pod
+	HostAliasesSet = "HostAliasesSet"
+	// DNSPolicyHostNetWithoutHostNetwork occurs when dnsPolicy is set to ClusterFirstWithHostNet in a pod which
+	// doesn't use the host network
+	DNSPolicyHostNetWithoutHostNetwork = "DNSPolicyHostNetWithoutHostNetwork"
+)
+
+const HostPortOverrideLabel = "allow-host-port"
+const HostAliasesOverrideLabel = "allow-host-aliases"
+const DNSPolicyOverrideLabel = "allow-dns-policy-cluster-first-with-host-net"
+
+// HostNetworking implements Auditable
+type HostNetworking struct{}
+
+func New() *HostNetworking {
+	return &HostNetworking{}
+}
+
+// Audit checks that containers don't bind host ports, and that pods don't override host name resolution with
+// hostAliases or use the host network DNS policy without the host network
+func (a *HostNetworking) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResults []*kubeaudit.AuditResult
+
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return nil, nil
+	}
+
+	for _, container := range k8s.GetContainers(resource) {
+		containerResults := auditHostPorts(container)
+		if len(containerResults) == 0 {
+			if auditResult := override.ApplyOverride(nil, Name, container.Name, resource, HostPortOverrideLabel); auditResult != nil {
+				auditResults = append(auditResults, auditResult)
+			}
+			continue
+		}
+
+		for _, auditResult := range containerResults {
+			auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, container.Name, resource, HostPortOverrideLabel))
+		}
+	}
+
+	for _, check := range []struct {
+		auditFunc     func(*k8s.PodSpecV1) *kubeaudit.AuditResult
+		overrideLabel string
+	}{
+		{auditHostAliases, HostAliasesOverrideLabel},
+		{auditDNSPolicy, DNSPolicyOverrideLabel},
+	} {
+		auditResult := check.auditFunc(podSpec)
+		auditResult = override.ApplyOverride(auditResult, Name, "", resource, check.overrideLabel)
+		if auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+
+	return auditResults, nil
+}
+
+func auditHostPorts(container *k8s.ContainerV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	for i := range container.Ports {
+		port := &container.Ports[i]
+		if port.HostPort == 0 {
+			continue
+		}
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     HostPortSet,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Container port %d is bound to port %d of the node with hostPort. The port is exposed on the node's IP address to anything which can reach the node, and only one pod per node can bind it. hostPort should not be set.", port.ContainerPort, port.HostPort),
+			PendingFix: &fixHostPort{
+				container: container,
+				port:      port,
+			},
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Port":      strconv.Itoa(int(port.ContainerPort)),
+				"HostPort":  strconv.Itoa(int(port.HostPort)),
+			},
+		})
+	}
+
+	return auditResults
+}
+
+func auditHostAliases(podSpec *k8s.PodSpecV1) *kubeaudit.AuditResult {
+	if len(podSpec.HostAliases) == 0 {
+		return nil
+	}
+
+	var hostnames int
+	for _, hostAlias := range podSpec.HostAliases {
+		hostnames += len(hostAlias.Hostnames)
+	}
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     HostAliasesSet,
+		Severity: kubeaudit.Warn,
+		Message:  "hostAliases is set in PodSpec. Entries in the hosts file of the pod take precedence over DNS, so they can silently redirect traffic and go stale when the addresses change. Services or DNS records should be used instead.",
+		Metadata: kubeaudit.Metadata{
+			"Hostnames": strconv.Itoa(hostnames),
+		},
+	}
+}
+
+func auditDNSPolicy(podSpec *k8s.PodSpecV1) *kubeaudit.AuditResult {
+	if podSpec.DNSPolicy != apiv1.DNSClusterFirstWithHostNet || podSpec.HostNetwork {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     DNSPolicyHostNetWithoutHostNetwork,
+		Severity: kubeaudit.Warn,
+		Message:  "dnsPolicy is set to 'ClusterFirstWithHostNet' but hostNetwork is not set to 'true' in PodSpec. The policy only applies to pods using the host network, which suggests the pod spec was copied from a host network pod. dnsPolicy should be set to 'ClusterFirst'.",
+		PendingFix: &fixDNSPolicy{
+			podSpec: podSpec,
+		},
+	}
+}
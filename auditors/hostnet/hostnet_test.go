@@ -0,0 +1,39 @@
+package hostnet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditHostNetworking(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"host-port.yml", []string{HostPortSet}},
+		{"host-port-allowed.yml", []string{override.GetOverriddenResultName(HostPortSet)}},
+		{"container-port.yml", nil},
+		{"host-aliases.yml", []string{HostAliasesSet}},
+		{"host-aliases-allowed.yml", []string{override.GetOverriddenResultName(HostAliasesSet)}},
+		{"dns-policy-host-net-without-host-network.yml", []string{DNSPolicyHostNetWithoutHostNetwork}},
+		{"dns-policy-host-net-without-host-network-allowed.yml", []string{override.GetOverriddenResultName(DNSPolicyHostNetWithoutHostNetwork)}},
+		{"dns-policy-host-net-with-host-network.yml", nil},
+		{"host-networking-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, New(), tc.expectedErrors)
+			test.AuditLocal(t, fixtureDir, tc.file, New(), strings.Split(tc.file, ".")[0], tc.expectedErrors)
+		})
+	}
+}
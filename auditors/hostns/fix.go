@@ -1,17 +1,27 @@
 package hostns
 
-import "github.com/Shopify/kubeaudit/pkg/k8s"
+import (
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
+)
 
 type fixHostNetworkTrue struct {
 	podSpec *k8s.PodSpecV1
 }
 
 func (f *fixHostNetworkTrue) Plan() string {
+	if f.podSpec.DNSPolicy == apiv1.DNSClusterFirstWithHostNet {
+		return "Set hostNetwork to 'false' and dnsPolicy to 'ClusterFirst' in PodSpec"
+	}
 	return "Set hostNetwork to 'false' in PodSpec"
 }
 
+// Apply also replaces the ClusterFirstWithHostNet DNS policy, which is only meant for pods using the host network
 func (f *fixHostNetworkTrue) Apply(resource k8s.Resource) []k8s.Resource {
 	f.podSpec.HostNetwork = false
+	if f.podSpec.DNSPolicy == apiv1.DNSClusterFirstWithHostNet {
+		f.podSpec.DNSPolicy = apiv1.DNSClusterFirst
+	}
 	return nil
 }
 
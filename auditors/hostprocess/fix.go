@@ -0,0 +1,33 @@
+package hostprocess
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+type fixHostProcessPod struct {
+	podSpec *k8s.PodSpecV1
+}
+
+func (f *fixHostProcessPod) Plan() string {
+	return "Set hostProcess to 'false' in the windowsOptions of the pod SecurityContext"
+}
+
+func (f *fixHostProcessPod) Apply(resource k8s.Resource) []k8s.Resource {
+	f.podSpec.SecurityContext.WindowsOptions.HostProcess = k8s.NewFalse()
+	return nil
+}
+
+type fixHostProcessContainer struct {
+	container *k8s.ContainerV1
+}
+
+func (f *fixHostProcessContainer) Plan() string {
+	return fmt.Sprintf("Set hostProcess to 'false' in the windowsOptions of the container SecurityContext for container %s", f.container.Name)
+}
+
+func (f *fixHostProcessContainer) Apply(resource k8s.Resource) []k8s.Resource {
+	f.container.SecurityContext.WindowsOptions.HostProcess = k8s.NewFalse()
+	return nil
+}
@@ -0,0 +1,28 @@
+package hostprocess
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixHostProcess(t *testing.T) {
+	resources, _ := test.FixSetup(t, fixtureDir, "host-process-pod.yml", New())
+	require.Len(t, resources, 1)
+	podSpec := k8s.GetPodSpec(resources[0])
+	assert.False(t, *podSpec.SecurityContext.WindowsOptions.HostProcess)
+
+	resources, _ = test.FixSetup(t, fixtureDir, "host-process-container.yml", New())
+	require.Len(t, resources, 1)
+	assert.False(t, *k8s.GetContainers(resources[0])[0].SecurityContext.WindowsOptions.HostProcess)
+
+	// The container which is allowed to run as a HostProcess container is not fixed
+	resources, _ = test.FixSetup(t, fixtureDir, "host-process-allowed-container-scoped.yml", New())
+	require.Len(t, resources, 1)
+	for _, container := range k8s.GetContainers(resources[0]) {
+		assert.Equal(t, container.Name == "container2", *container.SecurityContext.WindowsOptions.HostProcess, container.Name)
+	}
+}
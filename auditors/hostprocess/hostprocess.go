@@ -0,0 +1,87 @@
+package hostprocess
+
+import (
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const Name = "hostprocess"
+
+const (
+	// HostProcessTruePod occurs when hostProcess is set to true in the windowsOptions of the pod SecurityContext
+	HostProcessTruePod = "HostProcessTruePod"
+	// HostProcessTrueContainer occurs when hostProcess is set to true in the windowsOptions of the container
+	// SecurityContext
+	HostProcessTrueContainer = "HostProcessTrueContainer"
+)
+
+const OverrideLabel = "allow-host-process"
+
+// HostProcess implements Auditable
+type HostProcess struct{}
+
+func New() *HostProcess {
+	return &HostProcess{}
+}
+
+// Audit checks that Windows pods and containers don't run as HostProcess containers, which run directly on the host
+// with the privileges of the node
+func (a *HostProcess) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return nil, nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+
+	podHostProcess := podSpec.SecurityContext != nil && isHostProcess(podSpec.SecurityContext.WindowsOptions)
+	if podHostProcess {
+		auditResult := &kubeaudit.AuditResult{
+			Auditor:    Name,
+			Rule:       HostProcessTruePod,
+			Severity:   kubeaudit.Error,
+			Message:    "hostProcess is set to 'true' in the windowsOptions of the pod SecurityContext. It should be set to 'false'.",
+			PendingFix: &fixHostProcessPod{podSpec: podSpec},
+		}
+		if auditResult = override.ApplyOverride(auditResult, Name, "", resource, OverrideLabel); auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+
+	for _, container := range k8s.GetContainers(resource) {
+		auditResult := auditContainer(container)
+		// Containers inherit hostProcess from the pod, so an override of the pod result is not redundant for them
+		if auditResult == nil && podHostProcess {
+			continue
+		}
+		auditResult = override.ApplyOverride(auditResult, Name, container.Name, resource, OverrideLabel)
+		if auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+
+	return auditResults, nil
+}
+
+func auditContainer(container *k8s.ContainerV1) *kubeaudit.AuditResult {
+	if container.SecurityContext == nil || !isHostProcess(container.SecurityContext.WindowsOptions) {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:    Name,
+		Rule:       HostProcessTrueContainer,
+		Severity:   kubeaudit.Error,
+		Message:    "hostProcess is set to 'true' in the windowsOptions of the container SecurityContext. It should be set to 'false'.",
+		PendingFix: &fixHostProcessContainer{container: container},
+		Metadata: kubeaudit.Metadata{
+			"Container": container.Name,
+		},
+	}
+}
+
+func isHostProcess(windowsOptions *apiv1.WindowsSecurityContextOptions) bool {
+	return windowsOptions != nil && windowsOptions.HostProcess != nil && *windowsOptions.HostProcess
+}
@@ -0,0 +1,37 @@
+package hostprocess
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditHostProcess(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"host-process-pod.yml", []string{HostProcessTruePod}},
+		{"host-process-container.yml", []string{HostProcessTrueContainer}},
+		{"host-process-false.yml", nil},
+		{"host-process-allowed.yml", []string{override.GetOverriddenResultName(HostProcessTruePod)}},
+		{"host-process-allowed-container-scoped.yml", []string{
+			HostProcessTrueContainer,
+			override.GetOverriddenResultName(HostProcessTrueContainer)},
+		},
+		{"host-process-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, New(), tc.expectedErrors)
+		})
+	}
+}
@@ -2,6 +2,13 @@ package image
 
 type Config struct {
 	Image string `yaml:"image"`
+	// Inspect enables the checks of the image config, which is fetched from the registry of the image
+	Inspect bool `yaml:"inspect"`
+	// Architectures are the architectures images must be built for, in addition to those of the audited nodes
+	Architectures []string `yaml:"architectures"`
+	// Offline skips the checks of the image config, which can't be fetched from registries without the network. It is
+	// set by the offline setting of the kubeaudit config rather than per auditor
+	Offline bool `yaml:"-"`
 }
 
 func (config *Config) GetImage() string {
@@ -10,3 +17,17 @@ func (config *Config) GetImage() string {
 	}
 	return config.Image
 }
+
+func (config *Config) GetInspect() bool {
+	if config == nil {
+		return false
+	}
+	return config.Inspect
+}
+
+func (config *Config) GetArchitectures() []string {
+	if config == nil {
+		return nil
+	}
+	return config.Architectures
+}
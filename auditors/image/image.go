@@ -5,7 +5,9 @@ import (
 	"strings"
 
 	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/registry"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
 )
 
 const Name = "image"
@@ -19,19 +21,33 @@ const (
 	ImageCorrect = "ImageCorrect"
 )
 
+const OverrideLabel = "allow-image-risk"
+
 // Image implements Auditable
 type Image struct {
 	image string
+	// inspector fetches the image configs from registries. It is nil if image configs are not inspected
+	inspector     inspector
+	architectures []string
 }
 
 func New(config Config) *Image {
-	return &Image{
-		image: config.GetImage(),
+	image := &Image{
+		image:         config.GetImage(),
+		architectures: config.GetArchitectures(),
+	}
+	switch {
+	case config.GetInspect() && config.Offline:
+		image.inspector = offlineInspector{}
+	case config.GetInspect():
+		image.inspector = registry.NewClient()
 	}
+	return image
 }
 
-// Audit checks that the container image matches the provided image
-func (image *Image) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+// Audit checks that the container image matches the provided image, and checks the container against the image config
+// if image configs are inspected
+func (image *Image) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
 	var auditResults []*kubeaudit.AuditResult
 
 	for _, container := range k8s.GetContainers(resource) {
@@ -39,8 +55,21 @@ func (image *Image) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit
 		if auditResult != nil {
 			auditResults = append(auditResults, auditResult)
 		}
+		if image.inspector != nil {
+			auditResults = append(auditResults, image.auditImageConfig(container, resource, resources)...)
+		}
 	}
 
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, OverrideLabel)
+	}
 	return auditResults, nil
 }
 
@@ -1,12 +1,17 @@
 package image
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"testing"
 
+	"github.com/Shopify/kubeaudit/internal/registry"
 	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const fixtureDir = "fixtures"
@@ -41,6 +46,7 @@ func TestAuditImage(t *testing.T) {
 	}{
 		{"image-tag-missing.yml", "scratch:1.6", []string{ImageTagMissing}},
 		{"image-tag-missing.yml", "", []string{ImageTagMissing}},
+		{"image-tag-missing-allowed.yml", "", []string{override.GetOverriddenResultName(ImageTagMissing)}},
 		{"image-tag-present.yml", "scratch:1.6", []string{ImageTagIncorrect}},
 		{"image-tag-present.yml", "", []string{}},
 		{"image-tag-present.yml", "scratch:1.5", []string{ImageCorrect}},
@@ -57,3 +63,80 @@ func TestAuditImage(t *testing.T) {
 		})
 	}
 }
+
+// fakeInspector returns the configs of the images of the image-config fixtures
+type fakeInspector struct{}
+
+func (fakeInspector) Inspect(_ context.Context, image string) (*registry.Image, error) {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+	amd64 := registry.Platform{OS: "linux", Architecture: "amd64"}
+	arm64 := registry.Platform{OS: "linux", Architecture: "arm64"}
+	inspected := &registry.Image{Reference: ref, Platforms: []registry.Platform{amd64, arm64}}
+	switch image {
+	case "example.com/root:1.0":
+	case "example.com/nonroot:1.0":
+		inspected.User = "nonroot"
+	case "example.com/healthcheck:1.0":
+		inspected.User = "1000"
+		inspected.Healthcheck = []string{"CMD", "curl", "-f", "http://localhost:8080/healthz"}
+	case "gcr.io/distroless/static:nonroot":
+		inspected.User = "65532:65532"
+	case "example.com/amd64:1.0":
+		inspected.User = "1000"
+		inspected.Platforms = []registry.Platform{amd64}
+	default:
+		return nil, errors.New("404 Not Found")
+	}
+	return inspected, nil
+}
+
+func TestAuditImageConfig(t *testing.T) {
+	cases := []struct {
+		file           string
+		architectures  []string
+		expectedErrors []string
+	}{
+		{"image-config-root.yml", nil, []string{ImageRunsAsRoot}},
+		{"image-config-root-run-as-user.yml", nil, []string{}},
+		{"image-config-run-as-non-root-user-name.yml", nil, []string{ImageRunAsNonRootConflict}},
+		{"image-config-healthcheck.yml", nil, []string{ImageHealthcheckIgnored}},
+		{"image-config-healthcheck-liveness-probe.yml", nil, []string{}},
+		{"image-config-distroless-shell.yml", nil, []string{ImageShellMissing}},
+		{"image-config-architecture-node-selector.yml", nil, []string{ImageArchitectureMismatch}},
+		{"image-config-architecture-nodes.yml", nil, []string{ImageArchitectureMismatch}},
+		{"image-config-inspection-failed.yml", nil, []string{ImageInspectionFailed}},
+		{"image-config-root-run-as-user.yml", []string{"arm64"}, []string{}},
+		{"image-config-healthcheck-liveness-probe.yml", []string{"amd64", "arm64"}, []string{}},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.file+" "+strings.Join(tc.architectures, ","), func(t *testing.T) {
+			t.Parallel()
+			auditor := New(Config{Architectures: tc.architectures})
+			auditor.inspector = fakeInspector{}
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditImageConfigOffline(t *testing.T) {
+	auditor := New(Config{Inspect: true, Offline: true})
+	test.AuditManifest(t, fixtureDir, "image-config-root.yml", auditor, []string{ImageInspectionSkipped})
+}
+
+func TestAuditImageConfigArchitectures(t *testing.T) {
+	auditor := New(Config{Architectures: []string{"arm64", "s390x"}})
+	auditor.inspector = fakeInspector{}
+	report := test.AuditManifest(t, fixtureDir, "image-config-architecture-nodes.yml", auditor, []string{ImageArchitectureMismatch})
+
+	results := report.Results()
+	require.Len(t, results, 1)
+	auditResults := results[0].GetAuditResults()
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, "arm64, s390x", auditResults[0].Metadata["MissingArchitectures"])
+	assert.Equal(t, "linux/amd64", auditResults[0].Metadata["Platforms"])
+}
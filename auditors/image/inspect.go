@@ -0,0 +1,360 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/registry"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ImageRunsAsRoot occurs when the image runs as root and the container does not set runAsUser or runAsNonRoot
+	ImageRunsAsRoot = "ImageRunsAsRoot"
+	// ImageRunAsNonRootConflict occurs when runAsNonRoot is true without runAsUser, and the image runs as root or as a
+	// user name. The kubelet can't verify that a user name is not root, so it refuses to start the container
+	ImageRunAsNonRootConflict = "ImageRunAsNonRootConflict"
+	// ImageHealthcheckIgnored occurs when the image has a HEALTHCHECK, which Kubernetes ignores, and the container has
+	// no livenessProbe
+	ImageHealthcheckIgnored = "ImageHealthcheckIgnored"
+	// ImageShellMissing occurs when a distroless image, which has no shell, runs a command or probe with a shell
+	ImageShellMissing = "ImageShellMissing"
+	// ImageArchitectureMismatch occurs when the image is not built for the architecture of nodes the pod can run on
+	ImageArchitectureMismatch = "ImageArchitectureMismatch"
+	// ImageInspectionFailed occurs when the image config can't be fetched from the registry
+	ImageInspectionFailed = "ImageInspectionFailed"
+	// ImageInspectionSkipped occurs when image configs are inspected but kubeaudit runs offline, so the image config
+	// is not fetched from the registry
+	ImageInspectionSkipped = "ImageInspectionSkipped"
+)
+
+// shells are the executables of shells, which distroless images don't have
+var shells = map[string]bool{"sh": true, "bash": true, "ash": true, "dash": true, "zsh": true}
+
+// inspector returns the config of images. It is implemented by registry.Client
+type inspector interface {
+	Inspect(ctx context.Context, image string) (*registry.Image, error)
+}
+
+// errOffline is returned by offlineInspector
+var errOffline = errors.New("the registry of the image can't be reached offline")
+
+// offlineInspector is the inspector when kubeaudit runs offline, which doesn't inspect any image
+type offlineInspector struct{}
+
+func (offlineInspector) Inspect(context.Context, string) (*registry.Image, error) {
+	return nil, errOffline
+}
+
+// auditImageConfig checks the container against the config of its image
+func (image *Image) auditImageConfig(container *k8s.ContainerV1, resource k8s.Resource, resources []k8s.Resource) []*kubeaudit.AuditResult {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil || container.Image == "" {
+		return nil
+	}
+
+	inspected, err := image.inspector.Inspect(context.Background(), container.Image)
+	if errors.Is(err, errOffline) {
+		return []*kubeaudit.AuditResult{{
+			Auditor:  Name,
+			Rule:     ImageInspectionSkipped,
+			Severity: kubeaudit.Info,
+			Message:  "Image config was not fetched from the registry since kubeaudit runs offline, so it was not inspected.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Reason":    err.Error(),
+			},
+		}}
+	}
+	if err != nil {
+		return []*kubeaudit.AuditResult{{
+			Auditor:  Name,
+			Rule:     ImageInspectionFailed,
+			Severity: kubeaudit.Info,
+			Message:  "Image config could not be fetched from the registry, so it was not inspected.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Error":     err.Error(),
+			},
+		}}
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, auditResult := range []*kubeaudit.AuditResult{
+		auditUser(container, podSpec, inspected),
+		auditHealthcheck(container, inspected),
+		auditShell(container, inspected),
+		image.auditArchitectures(container, podSpec, resources, inspected),
+	} {
+		if auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+	return auditResults
+}
+
+func auditUser(container *k8s.ContainerV1, podSpec *k8s.PodSpecV1, inspected *registry.Image) *kubeaudit.AuditResult {
+	runAsUser, runAsNonRoot := getRunAs(container, podSpec)
+	if runAsUser != nil {
+		return nil
+	}
+
+	// The user of the image can be followed by a group, eg. "65532:65532"
+	user, _, _ := strings.Cut(inspected.User, ":")
+	if user == "" {
+		user = "root"
+	}
+	uid, err := strconv.ParseInt(user, 10, 64)
+	isRoot := user == "root" || (err == nil && uid == 0)
+	metadata := kubeaudit.Metadata{
+		"Container": container.Name,
+		"User":      user,
+	}
+
+	switch {
+	case runAsNonRoot && isRoot:
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ImageRunAsNonRootConflict,
+			Severity: kubeaudit.Error,
+			Message:  "runAsNonRoot is true but the image runs as root, so the container will not start. The image should be built with a non-root USER, or runAsUser should be set to a non-root UID.",
+			Metadata: metadata,
+		}
+	case runAsNonRoot && err != nil:
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ImageRunAsNonRootConflict,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("runAsNonRoot is true but the image runs as the user name '%s', which the kubelet can't verify is not root, so the container will not start. runAsUser should be set to the UID of the user.", user),
+			Metadata: metadata,
+		}
+	case !runAsNonRoot && isRoot:
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ImageRunsAsRoot,
+			Severity: kubeaudit.Warn,
+			Message:  "Image runs as root and neither runAsUser nor runAsNonRoot is set. The image should be built with a non-root USER, or runAsUser should be set to a non-root UID.",
+			Metadata: metadata,
+		}
+	}
+	return nil
+}
+
+// getRunAs returns the runAsUser and runAsNonRoot of the container, which default to those of the pod
+func getRunAs(container *k8s.ContainerV1, podSpec *k8s.PodSpecV1) (runAsUser *int64, runAsNonRoot bool) {
+	var nonRoot *bool
+	if sc := podSpec.SecurityContext; sc != nil {
+		runAsUser, nonRoot = sc.RunAsUser, sc.RunAsNonRoot
+	}
+	if sc := container.SecurityContext; sc != nil {
+		if sc.RunAsUser != nil {
+			runAsUser = sc.RunAsUser
+		}
+		if sc.RunAsNonRoot != nil {
+			nonRoot = sc.RunAsNonRoot
+		}
+	}
+	return runAsUser, nonRoot != nil && *nonRoot
+}
+
+func auditHealthcheck(container *k8s.ContainerV1, inspected *registry.Image) *kubeaudit.AuditResult {
+	if len(inspected.Healthcheck) == 0 || container.LivenessProbe != nil {
+		return nil
+	}
+
+	// The test is ["CMD", args...] or ["CMD-SHELL", command]
+	healthcheck := strings.Join(inspected.Healthcheck[1:], " ")
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     ImageHealthcheckIgnored,
+		Severity: kubeaudit.Warn,
+		Message:  "Image has a HEALTHCHECK, which Kubernetes ignores, and the container has no livenessProbe. A livenessProbe which checks the same as the HEALTHCHECK should be added.",
+		Metadata: kubeaudit.Metadata{
+			"Container":   container.Name,
+			"Healthcheck": healthcheck,
+		},
+	}
+}
+
+func auditShell(container *k8s.ContainerV1, inspected *registry.Image) *kubeaudit.AuditResult {
+	// Debug variants of distroless images have a busybox shell
+	if !strings.Contains(inspected.Reference.Repository, "distroless") || strings.Contains(inspected.Reference.Tag, "debug") {
+		return nil
+	}
+
+	for _, command := range getCommands(container) {
+		if len(command.args) == 0 || !shells[path.Base(command.args[0])] {
+			continue
+		}
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ImageShellMissing,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("Image is distroless and has no shell, so the %s of the container will fail. It should run an executable of the image instead of a shell.", command.name),
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Command":   strings.Join(command.args, " "),
+			},
+		}
+	}
+	return nil
+}
+
+type command struct {
+	name string
+	args []string
+}
+
+// getCommands returns the commands the container runs in the container image
+func getCommands(container *k8s.ContainerV1) []command {
+	commands := []command{{"command", container.Command}}
+	for _, probe := range []struct {
+		name  string
+		probe *v1.Probe
+	}{
+		{"livenessProbe", container.LivenessProbe},
+		{"readinessProbe", container.ReadinessProbe},
+		{"startupProbe", container.StartupProbe},
+	} {
+		if probe.probe != nil && probe.probe.Exec != nil {
+			commands = append(commands, command{probe.name, probe.probe.Exec.Command})
+		}
+	}
+	if lifecycle := container.Lifecycle; lifecycle != nil {
+		if lifecycle.PostStart != nil && lifecycle.PostStart.Exec != nil {
+			commands = append(commands, command{"postStart hook", lifecycle.PostStart.Exec.Command})
+		}
+		if lifecycle.PreStop != nil && lifecycle.PreStop.Exec != nil {
+			commands = append(commands, command{"preStop hook", lifecycle.PreStop.Exec.Command})
+		}
+	}
+	return commands
+}
+
+func (image *Image) auditArchitectures(container *k8s.ContainerV1, podSpec *k8s.PodSpecV1, resources []k8s.Resource, inspected *registry.Image) *kubeaudit.AuditResult {
+	os := getOS(podSpec)
+	available := map[string]bool{}
+	platforms := make([]string, 0, len(inspected.Platforms))
+	for _, platform := range inspected.Platforms {
+		if platform.Architecture == "" {
+			continue
+		}
+		platforms = append(platforms, platform.String())
+		if platform.OS == os {
+			available[platform.Architecture] = true
+		}
+	}
+	// Images without a platform in their config can't be checked
+	if len(platforms) == 0 {
+		return nil
+	}
+
+	var missing, missingPlatforms []string
+	for _, arch := range image.getArchitectures(podSpec, os, resources) {
+		if !available[arch] {
+			missing = append(missing, arch)
+			missingPlatforms = append(missingPlatforms, os+"/"+arch)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     ImageArchitectureMismatch,
+		Severity: kubeaudit.Error,
+		Message:  fmt.Sprintf("Image is not built for %s, the platform of nodes the pod can run on, so the container will fail to start on them. The image should be built for it, or the pod should be restricted to the architectures of the image with a kubernetes.io/arch node selector.", strings.Join(missingPlatforms, " and ")),
+		Metadata: kubeaudit.Metadata{
+			"Container":            container.Name,
+			"MissingArchitectures": strings.Join(missing, ", "),
+			"Platforms":            strings.Join(platforms, ", "),
+		},
+	}
+}
+
+// getOS returns the operating system of the nodes the pod runs on, as set by its OS or its node selector
+func getOS(podSpec *k8s.PodSpecV1) string {
+	if podSpec.OS != nil {
+		return string(podSpec.OS.Name)
+	}
+	if os := podSpec.NodeSelector[v1.LabelOSStable]; os != "" {
+		return os
+	}
+	return string(v1.Linux)
+}
+
+// getArchitectures returns the architectures of the nodes the pod can run on. They are the architectures the pod is
+// restricted to by its node selector or required node affinity if it is, and the architectures of the audited nodes
+// and the configured architectures otherwise
+func (image *Image) getArchitectures(podSpec *k8s.PodSpecV1, os string, resources []k8s.Resource) []string {
+	if arch := podSpec.NodeSelector[v1.LabelArchStable]; arch != "" {
+		return []string{arch}
+	}
+	if architectures := getAffinityArchitectures(podSpec); len(architectures) > 0 {
+		return architectures
+	}
+
+	architectures := map[string]bool{}
+	for _, arch := range image.architectures {
+		architectures[arch] = true
+	}
+	for _, resource := range resources {
+		node, ok := resource.(*k8s.NodeV1)
+		if !ok {
+			continue
+		}
+		nodeOS, arch := node.Labels[v1.LabelOSStable], node.Labels[v1.LabelArchStable]
+		if nodeOS == "" {
+			nodeOS = node.Status.NodeInfo.OperatingSystem
+		}
+		if arch == "" {
+			arch = node.Status.NodeInfo.Architecture
+		}
+		if arch != "" && (nodeOS == "" || nodeOS == os) {
+			architectures[arch] = true
+		}
+	}
+	return sortedKeys(architectures)
+}
+
+// getAffinityArchitectures returns the architectures the pod is restricted to by its required node affinity, or nil if
+// any of its node selector terms allows any architecture
+func getAffinityArchitectures(podSpec *k8s.PodSpecV1) []string {
+	if podSpec.Affinity == nil || podSpec.Affinity.NodeAffinity == nil || podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return nil
+	}
+
+	architectures := map[string]bool{}
+	for _, term := range podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms {
+		restricted := false
+		for _, expression := range term.MatchExpressions {
+			if expression.Key == v1.LabelArchStable && expression.Operator == v1.NodeSelectorOpIn {
+				restricted = true
+				for _, arch := range expression.Values {
+					architectures[arch] = true
+				}
+			}
+		}
+		if !restricted {
+			return nil
+		}
+	}
+	return sortedKeys(architectures)
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
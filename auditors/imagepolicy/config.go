@@ -0,0 +1,40 @@
+package imagepolicy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type Config struct {
+	// AllowedRegistries are the registries and repositories containers may pull images from, such as 'gcr.io/mycorp'
+	// or 'gcr.io/mycorp/*'. A '*' matches any characters. If none are set, images from any registry are allowed
+	AllowedRegistries []string `yaml:"allowedRegistries"`
+}
+
+func (c *Config) GetAllowedRegistries() []string {
+	if c == nil {
+		return nil
+	}
+	return c.AllowedRegistries
+}
+
+// compileAllowedRegistries compiles the allowed registries into patterns which match the whole repository of an image.
+// An allowed registry without a '*' also matches the repositories under it, so 'gcr.io/mycorp' matches
+// 'gcr.io/mycorp/app'
+func compileAllowedRegistries(allowedRegistries []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(allowedRegistries))
+	for _, allowedRegistry := range allowedRegistries {
+		allowedRegistry = strings.TrimSpace(allowedRegistry)
+		if allowedRegistry == "" || strings.ContainsAny(allowedRegistry, "@ ") {
+			return nil, fmt.Errorf("invalid allowed registry %q", allowedRegistry)
+		}
+
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(strings.TrimSuffix(allowedRegistry, "/")), `\*`, ".*")
+		if !strings.Contains(allowedRegistry, "*") {
+			pattern += "(?:/.*)?"
+		}
+		patterns = append(patterns, regexp.MustCompile("^"+pattern+"$"))
+	}
+	return patterns, nil
+}
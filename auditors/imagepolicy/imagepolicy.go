@@ -0,0 +1,122 @@
+package imagepolicy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const Name = "imagepolicy"
+
+const (
+	// ImageRegistryNotAllowed occurs when a container image is not from one of the allowed registries
+	ImageRegistryNotAllowed = "ImageRegistryNotAllowed"
+	// ImageTagLatest occurs when a container image uses the latest tag, or no tag which defaults to latest
+	ImageTagLatest = "ImageTagLatest"
+	// ImageDigestMissing occurs when a container image is referenced by a mutable tag instead of a digest
+	ImageDigestMissing = "ImageDigestMissing"
+)
+
+const OverrideLabel = "allow-image-policy-violation"
+
+// ImagePolicy implements Auditable
+type ImagePolicy struct {
+	allowedRegistries []string
+	patterns          []*regexp.Regexp
+}
+
+func New(config Config) (*ImagePolicy, error) {
+	patterns, err := compileAllowedRegistries(config.GetAllowedRegistries())
+	if err != nil {
+		return nil, fmt.Errorf("error creating imagepolicy auditor: %w", err)
+	}
+
+	return &ImagePolicy{
+		allowedRegistries: config.GetAllowedRegistries(),
+		patterns:          patterns,
+	}, nil
+}
+
+// Audit checks that container images are from the allowed registries and are pinned to a digest
+func (a *ImagePolicy) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResults []*kubeaudit.AuditResult
+
+	for _, container := range k8s.GetContainers(resource) {
+		containerResults := a.auditContainer(container)
+		if len(containerResults) == 0 {
+			if auditResult := override.ApplyOverride(nil, Name, container.Name, resource, OverrideLabel); auditResult != nil {
+				auditResults = append(auditResults, auditResult)
+			}
+			continue
+		}
+
+		for _, auditResult := range containerResults {
+			auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, container.Name, resource, OverrideLabel))
+		}
+	}
+
+	return auditResults, nil
+}
+
+func (a *ImagePolicy) auditContainer(container *k8s.ContainerV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+	ref := parseImage(container.Image)
+
+	if !a.isAllowed(ref) {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ImageRegistryNotAllowed,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Image repository %s is not in the allowed registries. Images should be pulled from one of: %s.", ref.repository, strings.Join(a.allowedRegistries, ", ")),
+			Metadata: kubeaudit.Metadata{
+				"Container":  container.Name,
+				"Image":      container.Image,
+				"Repository": ref.repository,
+			},
+		})
+	}
+
+	switch {
+	case ref.isLatest():
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ImageTagLatest,
+			Severity: kubeaudit.Error,
+			Message:  "Image uses the 'latest' tag, either explicitly or because it has no tag. The image can change every time it is pulled. The image should be pinned to a digest.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Image":     container.Image,
+			},
+		})
+	case ref.digest == "":
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ImageDigestMissing,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("Image is referenced by the tag '%s' instead of a digest. Tags are mutable, so the image can be replaced in the registry. The image should be pinned to a digest (eg. '%s@sha256:...').", ref.tag, container.Image),
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Image":     container.Image,
+			},
+		})
+	}
+
+	return auditResults
+}
+
+// isAllowed returns true if the repository of the image matches one of the allowed registries, or if there are none
+func (a *ImagePolicy) isAllowed(ref imageReference) bool {
+	if len(a.patterns) == 0 {
+		return true
+	}
+	for _, pattern := range a.patterns {
+		if pattern.MatchString(ref.repository) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package imagepolicy
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditImagePolicy(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"image-digest.yml", nil},
+		{"image-tag-and-digest.yml", nil},
+		{"image-tag.yml", []string{ImageDigestMissing}},
+		{"image-tag-latest.yml", []string{ImageTagLatest}},
+		{"image-tag-missing.yml", []string{ImageTagLatest}},
+		{"image-registry-not-allowed.yml", []string{ImageRegistryNotAllowed}},
+		{"image-policy-allowed.yml", []string{override.GetOverriddenResultName(ImageDigestMissing)}},
+		{"image-policy-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	auditor, err := New(Config{AllowedRegistries: []string{"gcr.io/mycorp/*"}})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditNoAllowedRegistries(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	// Images from any registry are allowed if no registries are configured
+	test.AuditManifest(t, fixtureDir, "image-registry-not-allowed.yml", auditor, nil)
+}
+
+func TestParseImage(t *testing.T) {
+	cases := []struct {
+		image    string
+		expected imageReference
+	}{
+		{"nginx", imageReference{repository: "docker.io/library/nginx"}},
+		{"nginx:1.23", imageReference{repository: "docker.io/library/nginx", tag: "1.23"}},
+		{"mycorp/app:1.0", imageReference{repository: "docker.io/mycorp/app", tag: "1.0"}},
+		{"docker.io/nginx", imageReference{repository: "docker.io/library/nginx"}},
+		{"index.docker.io/mycorp/app", imageReference{repository: "docker.io/mycorp/app"}},
+		{"gcr.io/mycorp/team/app:v2", imageReference{repository: "gcr.io/mycorp/team/app", tag: "v2"}},
+		{"localhost/app", imageReference{repository: "localhost/app"}},
+		{"registry.local:5000/app", imageReference{repository: "registry.local:5000/app"}},
+		{"registry.local:5000/app:1.0", imageReference{repository: "registry.local:5000/app", tag: "1.0"}},
+		{"gcr.io/app@sha256:abc", imageReference{repository: "gcr.io/app", digest: "sha256:abc"}},
+		{"gcr.io/app:1.0@sha256:abc", imageReference{repository: "gcr.io/app", tag: "1.0", digest: "sha256:abc"}},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, parseImage(tc.image), tc.image)
+	}
+}
+
+func TestAllowedRegistries(t *testing.T) {
+	cases := []struct {
+		allowedRegistry string
+		repository      string
+		expected        bool
+	}{
+		{"gcr.io/mycorp", "gcr.io/mycorp/app", true},
+		{"gcr.io/mycorp", "gcr.io/mycorp", true},
+		{"gcr.io/mycorp/", "gcr.io/mycorp/team/app", true},
+		{"gcr.io/mycorp", "gcr.io/mycorpevil/app", false},
+		{"gcr.io/mycorp/*", "gcr.io/mycorp/team/app", true},
+		{"gcr.io/mycorp/*", "gcr.io/other/app", false},
+		{"*.dkr.ecr.us-east-1.amazonaws.com/*", "123456789012.dkr.ecr.us-east-1.amazonaws.com/app", true},
+		{"*.dkr.ecr.us-east-1.amazonaws.com/*", "evil.com/123.dkr.ecr.us-east-1.amazonaws.com", false},
+		{"docker.io/library", "docker.io/library/nginx", true},
+	}
+
+	for _, tc := range cases {
+		auditor, err := New(Config{AllowedRegistries: []string{tc.allowedRegistry}})
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, auditor.isAllowed(imageReference{repository: tc.repository}), tc.allowedRegistry+" "+tc.repository)
+	}
+}
+
+func TestNewInvalidAllowedRegistry(t *testing.T) {
+	for _, allowedRegistry := range []string{"", " ", "gcr.io/app@sha256"} {
+		_, err := New(Config{AllowedRegistries: []string{allowedRegistry}})
+		assert.Error(t, err, allowedRegistry)
+	}
+}
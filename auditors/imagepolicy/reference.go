@@ -0,0 +1,63 @@
+package imagepolicy
+
+import "strings"
+
+const (
+	defaultRegistry  = "docker.io"
+	officialImageOrg = "library"
+	latestTag        = "latest"
+)
+
+// imageReference is a container image split into its repository, tag and digest
+type imageReference struct {
+	// repository is the fully qualified repository of the image, including the registry. Docker Hub images are
+	// qualified the same way as the container runtime does, so 'nginx' is 'docker.io/library/nginx'
+	repository string
+	tag        string
+	digest     string
+}
+
+func parseImage(image string) imageReference {
+	var ref imageReference
+
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		ref.digest = name[i+1:]
+		name = name[:i]
+	}
+	// The registry can have a port, so the tag is only after the last path component
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		ref.tag = name[i+1:]
+		name = name[:i]
+	}
+
+	ref.repository = qualifyRepository(name)
+	return ref
+}
+
+// qualifyRepository adds the default registry to repositories without one. The first component of a repository is a
+// registry if it is a hostname, ie. it contains a '.' or a ':', or is 'localhost'
+func qualifyRepository(name string) string {
+	components := strings.SplitN(name, "/", 2)
+	if len(components) == 2 && (strings.ContainsAny(components[0], ".:") || components[0] == "localhost") {
+		if components[0] == "index.docker.io" {
+			name = defaultRegistry + "/" + components[1]
+			components[0] = defaultRegistry
+		}
+		if components[0] == defaultRegistry && !strings.Contains(components[1], "/") {
+			return defaultRegistry + "/" + officialImageOrg + "/" + components[1]
+		}
+		return name
+	}
+
+	if len(components) == 1 {
+		return defaultRegistry + "/" + officialImageOrg + "/" + name
+	}
+	return defaultRegistry + "/" + name
+}
+
+// isLatest returns true if the image is not pinned to a digest and has the latest tag, which is the tag used when the
+// image has no tag
+func (ref imageReference) isLatest() bool {
+	return ref.digest == "" && (ref.tag == "" || ref.tag == latestTag)
+}
@@ -0,0 +1,83 @@
+package labels
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DefaultPlaceholder is the value of the labels added by autofix if no placeholder is configured
+const DefaultPlaceholder = "kubeaudit-review-required"
+
+type Config struct {
+	// Required lists the labels which must be present
+	Required []Rule `yaml:"required"`
+	// Placeholder is the value of the labels added by autofix. The labels are reported until the placeholder is
+	// replaced with a real value
+	Placeholder string `yaml:"placeholder"`
+}
+
+// Rule requires a label. Value is a regular expression which must match the whole label value. An empty value
+// matches any value. If NamespaceSelector is set, the rule only applies to namespaces with all of the given labels
+// and to the resources in those namespaces
+type Rule struct {
+	Key               string            `yaml:"key"`
+	Value             string            `yaml:"value"`
+	NamespaceSelector map[string]string `yaml:"namespaceSelector"`
+}
+
+// ParseRule parses a rule of the form "key" or "key=value"
+func ParseRule(value string) Rule {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) == 1 {
+		return Rule{Key: parts[0]}
+	}
+	return Rule{Key: parts[0], Value: parts[1]}
+}
+
+func (c *Config) GetPlaceholder() string {
+	if c == nil || c.Placeholder == "" {
+		return DefaultPlaceholder
+	}
+	return c.Placeholder
+}
+
+// compiledRule is a Rule with its value pattern and namespace selector compiled
+type compiledRule struct {
+	Rule
+	value             *regexp.Regexp
+	namespaceSelector k8slabels.Selector
+}
+
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if errs := validation.IsQualifiedName(rule.Key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid label key %q: %s", rule.Key, strings.Join(errs, ", "))
+		}
+
+		var value *regexp.Regexp
+		if rule.Value != "" {
+			var err error
+			value, err = regexp.Compile("^(?:" + rule.Value + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid label value pattern %q: %w", rule.Value, err)
+			}
+		}
+
+		namespaceSelector, err := k8slabels.ValidatedSelectorFromSet(rule.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector for label %q: %w", rule.Key, err)
+		}
+
+		compiled = append(compiled, compiledRule{Rule: rule, value: value, namespaceSelector: namespaceSelector})
+	}
+	return compiled, nil
+}
+
+func (r compiledRule) matchesValue(value string) bool {
+	return r.value == nil || r.value.MatchString(value)
+}
@@ -0,0 +1,59 @@
+package labels
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixLabels(t *testing.T) {
+	cases := []struct {
+		file           string
+		config         Config
+		expectedLabels map[string]string
+	}{
+		{"labels-missing.yml", testConfig, map[string]string{
+			"app.kubernetes.io/name": DefaultPlaceholder,
+			"team":                   DefaultPlaceholder,
+		}},
+		{"labels-missing.yml", Config{Required: testConfig.Required, Placeholder: "todo"}, map[string]string{
+			"app.kubernetes.io/name": "todo",
+			"team":                   "todo",
+		}},
+		// Invalid values are not replaced since they may only need a small correction
+		{"label-value-invalid.yml", testConfig, map[string]string{
+			"app.kubernetes.io/name": "app",
+			"team":                   "Platform",
+		}},
+		{"labels-missing-allowed.yml", testConfig, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			auditor, err := New(tc.config)
+			require.NoError(t, err)
+
+			resources, _ := test.FixSetup(t, fixtureDir, tc.file, auditor)
+			require.Len(t, resources, 1)
+			assert.Equal(t, tc.expectedLabels, k8s.GetObjectMeta(resources[0]).GetLabels())
+		})
+	}
+}
+
+func TestFixLabelsFlagsPlaceholderForReview(t *testing.T) {
+	auditor, err := New(testConfig)
+	require.NoError(t, err)
+
+	resources, _ := test.FixSetup(t, fixtureDir, "labels-missing.yml", auditor)
+	require.Len(t, resources, 1)
+
+	auditResults, err := auditor.Audit(resources[0], resources)
+	require.NoError(t, err)
+	require.Len(t, auditResults, 2)
+	for _, auditResult := range auditResults {
+		assert.Equal(t, LabelPlaceholderValue, auditResult.Rule)
+	}
+}
@@ -0,0 +1,154 @@
+package labels
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const Name = "labels"
+
+const (
+	// LabelMissing occurs when a required label is not set
+	LabelMissing = "LabelMissing"
+	// LabelValueInvalid occurs when a required label is set but its value does not match the required value pattern
+	LabelValueInvalid = "LabelValueInvalid"
+	// LabelPlaceholderValue occurs when a required label still has the placeholder value added by autofix
+	LabelPlaceholderValue = "LabelPlaceholderValue"
+)
+
+const OverrideLabel = "allow-label-policy-violation"
+
+// Labels implements Auditable
+type Labels struct {
+	required    []compiledRule
+	placeholder string
+}
+
+func New(config Config) (*Labels, error) {
+	required, err := compileRules(config.Required)
+	if err != nil {
+		return nil, fmt.Errorf("error creating labels auditor: %w", err)
+	}
+
+	placeholder := config.GetPlaceholder()
+	if errs := validation.IsValidLabelValue(placeholder); len(errs) > 0 {
+		return nil, fmt.Errorf("error creating labels auditor: invalid placeholder %q: %s", placeholder, strings.Join(errs, ", "))
+	}
+
+	return &Labels{
+		required:    required,
+		placeholder: placeholder,
+	}, nil
+}
+
+// Audit checks that workloads and namespaces have the required labels
+func (a *Labels) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	if !k8s.IsNamespaceV1(resource) && k8s.GetPodSpec(resource) == nil {
+		return nil, nil
+	}
+	if len(a.required) == 0 {
+		return nil, nil
+	}
+
+	objectMeta := k8s.GetObjectMeta(resource)
+	if objectMeta == nil {
+		return nil, nil
+	}
+	labels := objectMeta.GetLabels()
+	namespaceLabels, hasNamespace := getNamespaceLabels(resource, resources)
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, rule := range a.required {
+		if !rule.namespaceSelector.Empty() && (!hasNamespace || !rule.namespaceSelector.Matches(k8slabels.Set(namespaceLabels))) {
+			continue
+		}
+		if auditResult := a.auditRequired(rule, labels); auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, OverrideLabel)
+	}
+	return auditResults, nil
+}
+
+func (a *Labels) auditRequired(rule compiledRule, labels map[string]string) *kubeaudit.AuditResult {
+	value, ok := labels[rule.Key]
+	if !ok {
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     LabelMissing,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Required label %s is missing. It should be added.", rule.Key),
+			PendingFix: &fixLabelMissing{
+				key:         rule.Key,
+				placeholder: a.placeholder,
+			},
+			Metadata: kubeaudit.Metadata{
+				"Label": rule.Key,
+			},
+		}
+	}
+
+	if value == a.placeholder {
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     LabelPlaceholderValue,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("Label %s has the placeholder value %s added by autofix. It should be reviewed and replaced with a real value.", rule.Key, value),
+			Metadata: kubeaudit.Metadata{
+				"Label": rule.Key,
+			},
+		}
+	}
+
+	if !rule.matchesValue(value) {
+		return &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     LabelValueInvalid,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Label %s does not have a valid value. Its value should match \"%s\".", rule.Key, rule.Value),
+			Metadata: kubeaudit.Metadata{
+				"Label":         rule.Key,
+				"Value":         value,
+				"ExpectedValue": rule.Value,
+			},
+		}
+	}
+
+	return nil
+}
+
+// getNamespaceLabels returns the labels of the namespace of the resource, or of the resource itself if it is a
+// namespace. The returned boolean is false if the namespace is not one of the audited resources
+func getNamespaceLabels(resource k8s.Resource, resources []k8s.Resource) (map[string]string, bool) {
+	if k8s.IsNamespaceV1(resource) {
+		return k8s.GetObjectMeta(resource).GetLabels(), true
+	}
+
+	namespace := k8s.GetObjectMeta(resource).GetNamespace()
+	if namespace == "" {
+		return nil, false
+	}
+
+	for _, r := range resources {
+		if k8s.IsNamespaceV1(r) && k8s.GetObjectMeta(r).GetName() == namespace {
+			return k8s.GetObjectMeta(r).GetLabels(), true
+		}
+	}
+	return nil, false
+}
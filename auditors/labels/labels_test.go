@@ -0,0 +1,90 @@
+package labels
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+var testConfig = Config{
+	Required: []Rule{
+		{Key: "app.kubernetes.io/name"},
+		{Key: "team", Value: "team-[a-z]+"},
+		{Key: "cost-center", NamespaceSelector: map[string]string{"env": "production"}},
+	},
+}
+
+func TestAuditLabels(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"labels-valid.yml", nil},
+		{"labels-missing.yml", []string{LabelMissing}},
+		{"label-value-invalid.yml", []string{LabelValueInvalid}},
+		{"label-placeholder.yml", []string{LabelPlaceholderValue}},
+		{"namespace-selector.yml", []string{LabelMissing}},
+		{"namespace-selector-no-match.yml", nil},
+		{"labels-missing-allowed.yml", []string{override.GetOverriddenResultName(LabelMissing)}},
+		{"labels-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	auditor, err := New(testConfig)
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditLabelsNamespaceSelector(t *testing.T) {
+	auditor, err := New(testConfig)
+	require.NoError(t, err)
+
+	report := test.GetReport(t, fixtureDir, "namespace-selector.yml", []kubeaudit.Auditable{auditor}, "", test.MANIFEST_MODE)
+
+	// The namespace and the deployment in it are both missing the label required in production namespaces
+	var missing []string
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			missing = append(missing, result.GetResource().Object().GetObjectKind().GroupVersionKind().Kind+"/"+auditResult.Metadata["Label"])
+		}
+	}
+	assert.ElementsMatch(t, []string{"Namespace/cost-center", "Deployment/cost-center"}, missing)
+}
+
+func TestAuditLabelsNoConfig(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+	test.AuditManifest(t, fixtureDir, "labels-missing.yml", auditor, nil)
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	cases := []Config{
+		{Required: []Rule{{Key: "team", Value: "("}}},
+		{Required: []Rule{{Key: "not a label"}}},
+		{Required: []Rule{{Key: "team", NamespaceSelector: map[string]string{"env": "not a value"}}}},
+		{Placeholder: "not a label value"},
+	}
+
+	for _, config := range cases {
+		_, err := New(config)
+		assert.Error(t, err)
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	assert.Equal(t, Rule{Key: "team"}, ParseRule("team"))
+	assert.Equal(t, Rule{Key: "team", Value: "team-[a-z]+"}, ParseRule("team=team-[a-z]+"))
+}
@@ -0,0 +1,45 @@
+package lifecycle
+
+import (
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+type fixByRemovingTerminationGracePeriod struct {
+	podSpec *k8s.PodSpecV1
+}
+
+func (f *fixByRemovingTerminationGracePeriod) Plan() string {
+	return "Remove terminationGracePeriodSeconds from the PodSpec so the default grace period is used"
+}
+
+func (f *fixByRemovingTerminationGracePeriod) Apply(resource k8s.Resource) []k8s.Resource {
+	f.podSpec.TerminationGracePeriodSeconds = nil
+	return nil
+}
+
+type fixBySettingRestartPolicyAlways struct {
+	podSpec *k8s.PodSpecV1
+}
+
+func (f *fixBySettingRestartPolicyAlways) Plan() string {
+	return "Set restartPolicy to 'Always' in the PodSpec"
+}
+
+func (f *fixBySettingRestartPolicyAlways) Apply(resource k8s.Resource) []k8s.Resource {
+	f.podSpec.RestartPolicy = v1.RestartPolicyAlways
+	return nil
+}
+
+type fixByRemovingActiveDeadlineSeconds struct {
+	podSpec *k8s.PodSpecV1
+}
+
+func (f *fixByRemovingActiveDeadlineSeconds) Plan() string {
+	return "Remove activeDeadlineSeconds from the PodSpec"
+}
+
+func (f *fixByRemovingActiveDeadlineSeconds) Apply(resource k8s.Resource) []k8s.Resource {
+	f.podSpec.ActiveDeadlineSeconds = nil
+	return nil
+}
@@ -0,0 +1,40 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestFixLifecycle(t *testing.T) {
+	cases := []struct {
+		file                                  string
+		expectedTerminationGracePeriodSeconds *int64
+		expectedRestartPolicy                 v1.RestartPolicy
+		expectedActiveDeadlineSeconds         *int64
+	}{
+		{"termination-grace-period-zero.yml", nil, "", nil},
+		{"termination-grace-period-zero-allowed.yml", int64Ptr(0), "", nil},
+		{"restart-policy-never.yml", nil, v1.RestartPolicyAlways, nil},
+		{"active-deadline-seconds-set.yml", nil, "", nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			resources, _ := test.FixSetup(t, fixtureDir, tc.file, New())
+			for _, resource := range resources {
+				podSpec := k8s.GetPodSpec(resource)
+				assert.Equal(t, tc.expectedTerminationGracePeriodSeconds, podSpec.TerminationGracePeriodSeconds)
+				assert.Equal(t, tc.expectedRestartPolicy, podSpec.RestartPolicy)
+				assert.Equal(t, tc.expectedActiveDeadlineSeconds, podSpec.ActiveDeadlineSeconds)
+			}
+		})
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,143 @@
+package lifecycle
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	v1 "k8s.io/api/core/v1"
+)
+
+const Name = "lifecycle"
+
+const (
+	// TerminationGracePeriodZero occurs when terminationGracePeriodSeconds is set to 0 in the PodSpec
+	TerminationGracePeriodZero = "TerminationGracePeriodZero"
+	// RestartPolicyNotAlways occurs when a long-running workload sets a restartPolicy other than Always
+	RestartPolicyNotAlways = "RestartPolicyNotAlways"
+	// ActiveDeadlineSecondsSet occurs when a long-running workload sets activeDeadlineSeconds in the PodSpec
+	ActiveDeadlineSecondsSet = "ActiveDeadlineSecondsSet"
+	// JobTTLSecondsAfterFinishedNil occurs when a Job which is not created by a CronJob does not set
+	// ttlSecondsAfterFinished
+	JobTTLSecondsAfterFinishedNil = "JobTTLSecondsAfterFinishedNil"
+)
+
+const OverrideLabel = "allow-lifecycle-risk"
+
+// Lifecycle implements Auditable
+type Lifecycle struct{}
+
+func New() *Lifecycle {
+	return &Lifecycle{}
+}
+
+// Audit checks that pods are terminated gracefully, that long-running workloads are restarted and not killed by a
+// deadline, and that finished Jobs are cleaned up
+func (a *Lifecycle) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return nil, nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	if auditResult := auditTerminationGracePeriod(podSpec); auditResult != nil {
+		auditResults = append(auditResults, auditResult)
+	}
+	if isLongRunning(resource) {
+		auditResults = append(auditResults, auditLongRunningPodSpec(podSpec)...)
+	}
+	if job, ok := resource.(*k8s.JobV1); ok {
+		if auditResult := auditJob(job); auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, OverrideLabel)
+	}
+	return auditResults, nil
+}
+
+func auditTerminationGracePeriod(podSpec *k8s.PodSpecV1) *kubeaudit.AuditResult {
+	if podSpec.TerminationGracePeriodSeconds == nil || *podSpec.TerminationGracePeriodSeconds != 0 {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     TerminationGracePeriodZero,
+		Severity: kubeaudit.Warn,
+		Message:  "terminationGracePeriodSeconds is set to 0 so containers are killed without being able to shut down gracefully. Every deletion of the pod is a force deletion (kubectl delete --grace-period=0 --force), and the containers may keep running after the pod is removed. terminationGracePeriodSeconds should be removed or set to the time the containers need to shut down.",
+		PendingFix: &fixByRemovingTerminationGracePeriod{
+			podSpec: podSpec,
+		},
+	}
+}
+
+func auditLongRunningPodSpec(podSpec *k8s.PodSpecV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	if podSpec.RestartPolicy != "" && podSpec.RestartPolicy != v1.RestartPolicyAlways {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     RestartPolicyNotAlways,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("restartPolicy is set to '%s' in a long-running workload. The API server rejects the workload, and containers which exit would not be restarted. restartPolicy should be set to 'Always'.", podSpec.RestartPolicy),
+			PendingFix: &fixBySettingRestartPolicyAlways{
+				podSpec: podSpec,
+			},
+			Metadata: kubeaudit.Metadata{
+				"RestartPolicy": string(podSpec.RestartPolicy),
+			},
+		})
+	}
+
+	if podSpec.ActiveDeadlineSeconds != nil {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ActiveDeadlineSecondsSet,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("activeDeadlineSeconds is set to %d in a long-running workload. The API server rejects the workload, and pods would be killed once the deadline passes. activeDeadlineSeconds should be removed.", *podSpec.ActiveDeadlineSeconds),
+			PendingFix: &fixByRemovingActiveDeadlineSeconds{
+				podSpec: podSpec,
+			},
+			Metadata: kubeaudit.Metadata{
+				"ActiveDeadlineSeconds": fmt.Sprintf("%d", *podSpec.ActiveDeadlineSeconds),
+			},
+		})
+	}
+
+	return auditResults
+}
+
+// auditJob checks that finished Jobs are deleted. Jobs created by CronJobs are not checked since the CronJob history
+// limits delete them
+func auditJob(job *k8s.JobV1) *kubeaudit.AuditResult {
+	if job.Spec.TTLSecondsAfterFinished != nil || len(job.OwnerReferences) > 0 {
+		return nil
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     JobTTLSecondsAfterFinishedNil,
+		Severity: kubeaudit.Warn,
+		Message:  "ttlSecondsAfterFinished is not set in the Job spec. The Job and its pods are kept after the Job finishes until they are deleted manually. ttlSecondsAfterFinished should be set so finished Jobs are cleaned up.",
+	}
+}
+
+// isLongRunning returns true if the pods of the resource are expected to run until they are deleted
+func isLongRunning(resource k8s.Resource) bool {
+	switch resource.(type) {
+	case *k8s.DaemonSetV1, *k8s.DeploymentV1, *k8s.ReplicationControllerV1, *k8s.StatefulSetV1:
+		return true
+	}
+	return false
+}
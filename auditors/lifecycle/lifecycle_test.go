@@ -0,0 +1,39 @@
+package lifecycle
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditLifecycle(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"termination-grace-period-zero.yml", []string{TerminationGracePeriodZero}},
+		{"termination-grace-period-set.yml", nil},
+		{"restart-policy-never.yml", []string{RestartPolicyNotAlways}},
+		{"restart-policy-always.yml", nil},
+		{"restart-policy-never-pod.yml", nil},
+		{"active-deadline-seconds-set.yml", []string{ActiveDeadlineSecondsSet}},
+		{"job-ttl-nil.yml", []string{JobTTLSecondsAfterFinishedNil}},
+		{"job-ttl-set.yml", nil},
+		{"cronjob.yml", nil},
+		{"termination-grace-period-zero-allowed.yml", []string{override.GetOverriddenResultName(TerminationGracePeriodZero)}},
+		{"lifecycle-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, New(), tc.expectedErrors)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
 	v1 "k8s.io/api/core/v1"
 	k8sResource "k8s.io/apimachinery/pkg/api/resource"
 )
@@ -24,6 +25,8 @@ const (
 	LimitsMemoryExceeded = "LimitsMemoryExceeded"
 )
 
+const OverrideLabel = "allow-limits-violation"
+
 // Limits implements Auditable
 type Limits struct {
 	maxCPU    k8sResource.Quantity
@@ -52,6 +55,10 @@ func (limits *Limits) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaud
 	var auditResults []*kubeaudit.AuditResult
 
 	for _, container := range k8s.GetContainers(resource) {
+		// Ephemeral containers can't have resources, which are those of the pod
+		if k8s.IsEphemeralContainer(resource, container) {
+			continue
+		}
 		for _, auditResult := range limits.auditContainer(container) {
 			if auditResult != nil {
 				auditResults = append(auditResults, auditResult)
@@ -59,6 +66,16 @@ func (limits *Limits) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaud
 		}
 	}
 
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, OverrideLabel)
+	}
 	return auditResults, nil
 }
 
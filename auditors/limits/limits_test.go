@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const fixtureDir = "fixtures"
@@ -19,6 +21,7 @@ func TestAuditLimits(t *testing.T) {
 		expectedErrors []string
 	}{
 		{"resources-limit-nil.yml", "", "", []string{LimitsNotSet}},
+		{"resources-limit-nil-allowed.yml", "", "", []string{override.GetOverriddenResultName(LimitsNotSet)}},
 		{"resources-limit-no-cpu.yml", "", "", []string{LimitsCPUNotSet}},
 		{"resources-limit-no-memory.yml", "", "", []string{LimitsMemoryNotSet}},
 		{"resources-limit.yml", "", "", []string{}},
@@ -49,3 +52,12 @@ func TestAuditLimits(t *testing.T) {
 		assert.NotNil(t, err)
 	})
 }
+
+func TestAuditLimitsSkipsEphemeralContainers(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	// Ephemeral containers can't set resources, so only the container and the init container are reported
+	report := test.AuditManifest(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, auditor, []string{LimitsNotSet})
+	assert.Equal(t, []string{"container", "init-container"}, test.AuditedContainers(report, LimitsNotSet))
+}
@@ -18,6 +18,7 @@ func TestFixDefaultDenyNetworkPolicies(t *testing.T) {
 		{"namespace-missing-default-deny-egress-netpol.yml", true, true},
 		{"namespace-missing-default-deny-ingress-netpol.yml", true, true},
 		{"namespace-has-default-deny-netpol.yml", true, true},
+		{"namespace-catch-all-egress-rules-netpol.yml", true, true},
 		{"namespace-has-default-deny-and-allow-all-netpol.yml", true, true},
 		{"namespace-missing-default-deny-netpol-allowed.yml", false, false},
 		{"namespace-missing-default-deny-egress-netpol-allowed.yml", true, false},
@@ -117,10 +117,7 @@ func auditNetworkPoliciesForDenyAll(resource k8s.Resource, resources []k8s.Resou
 				Metadata: kubeaudit.Metadata{
 					"Namespace": namespace,
 				},
-				PendingFix: &fixByAddingPolicyToNetPol{
-					networkPolicy: catchAllNetPol,
-					policyType:    Ingress,
-				},
+				PendingFix: newDefaultDenyFix(catchAllNetPol, namespace, Ingress),
 			}
 			auditResult = override.ApplyOverride(auditResult, Name, "", resource, IngressOverrideLabel)
 			auditResults = append(auditResults, auditResult)
@@ -135,10 +132,7 @@ func auditNetworkPoliciesForDenyAll(resource k8s.Resource, resources []k8s.Resou
 				Metadata: kubeaudit.Metadata{
 					"Namespace": namespace,
 				},
-				PendingFix: &fixByAddingPolicyToNetPol{
-					networkPolicy: catchAllNetPol,
-					policyType:    Egress,
-				},
+				PendingFix: newDefaultDenyFix(catchAllNetPol, namespace, Egress),
 			}
 			auditResult = override.ApplyOverride(auditResult, Name, "", resource, EgressOverrideLabel)
 			auditResults = append(auditResults, auditResult)
@@ -218,3 +212,25 @@ func auditNetworkPoliciesForDenyAll(resource k8s.Resource, resources []k8s.Resou
 
 	return auditResults
 }
+
+// newDefaultDenyFix returns the fix which denies all traffic of the policy type by default. Adding the policy type to
+// the catch-all NetworkPolicy only denies the traffic if the NetworkPolicy has no rules allowing it, otherwise a new
+// default deny NetworkPolicy is created
+func newDefaultDenyFix(catchAllNetPol *k8s.NetworkPolicyV1, namespace, policyType string) kubeaudit.PendingFix {
+	hasRules := len(catchAllNetPol.Spec.Ingress) > 0
+	if policyType == Egress {
+		hasRules = len(catchAllNetPol.Spec.Egress) > 0
+	}
+
+	if hasRules || isNetworkPolicyType(catchAllNetPol, policyType) {
+		return &fixByAddingNetworkPolicy{
+			policyList: []string{policyType},
+			namespace:  namespace,
+		}
+	}
+
+	return &fixByAddingPolicyToNetPol{
+		networkPolicy: catchAllNetPol,
+		policyType:    policyType,
+	}
+}
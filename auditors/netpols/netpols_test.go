@@ -19,6 +19,7 @@ func TestAuditDefaultDenyNetworkPolicies(t *testing.T) {
 		{"namespace-missing-default-deny-egress-netpol.yml", []string{MissingDefaultDenyEgressNetworkPolicy}},
 		{"namespace-missing-default-deny-ingress-netpol.yml", []string{MissingDefaultDenyIngressNetworkPolicy}},
 		{"namespace-has-default-deny-netpol.yml", nil},
+		{"namespace-catch-all-egress-rules-netpol.yml", []string{MissingDefaultDenyEgressNetworkPolicy}},
 		{"namespace-has-default-deny-and-allow-all-netpol.yml", []string{AllowAllIngressNetworkPolicyExists, AllowAllEgressNetworkPolicyExists}},
 		{"namespace-missing-default-deny-netpol-allowed.yml", []string{override.GetOverriddenResultName(MissingDefaultDenyIngressAndEgressNetworkPolicy)}},
 		{"namespace-missing-default-deny-egress-netpol-allowed.yml", []string{override.GetOverriddenResultName(MissingDefaultDenyEgressNetworkPolicy)}},
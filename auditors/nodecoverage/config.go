@@ -0,0 +1,14 @@
+package nodecoverage
+
+type Config struct {
+	// DaemonSets is the list of security-critical DaemonSets which should run on every node. Each entry is either a
+	// DaemonSet name or a "namespace/name" pair
+	DaemonSets []string `yaml:"daemonSets"`
+}
+
+func (config *Config) GetDaemonSets() []string {
+	if config == nil {
+		return nil
+	}
+	return config.DaemonSets
+}
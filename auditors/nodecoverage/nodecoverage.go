@@ -0,0 +1,247 @@
+package nodecoverage
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+)
+
+const Name = "nodecoverage"
+
+const (
+	// SecurityAgentMissing occurs when a configured security-critical DaemonSet is not found
+	SecurityAgentMissing = "SecurityAgentMissing"
+	// NodeNotCoveredBySecurityAgent occurs when a configured security-critical DaemonSet cannot be scheduled on a node
+	// because of its node selector, node affinity or a taint it does not tolerate
+	NodeNotCoveredBySecurityAgent = "NodeNotCoveredBySecurityAgent"
+	// SecurityAgentNotToleratingAllTaints occurs when a configured security-critical DaemonSet does not tolerate all
+	// taints, so tainting a node is enough to remove it from the DaemonSet's coverage
+	SecurityAgentNotToleratingAllTaints = "SecurityAgentNotToleratingAllTaints"
+)
+
+const OverrideLabel = "allow-node-without-security-agent"
+
+// daemonSetTolerations are the tolerations the DaemonSet controller adds to every DaemonSet pod
+// (see https://kubernetes.io/docs/concepts/workloads/controllers/daemonset/#taints-and-tolerations)
+var daemonSetTolerations = []v1.Toleration{
+	{Key: v1.TaintNodeNotReady, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+	{Key: v1.TaintNodeUnreachable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoExecute},
+	{Key: v1.TaintNodeDiskPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	{Key: v1.TaintNodeMemoryPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	{Key: v1.TaintNodePIDPressure, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+	{Key: v1.TaintNodeUnschedulable, Operator: v1.TolerationOpExists, Effect: v1.TaintEffectNoSchedule},
+}
+
+// NodeCoverage implements Auditable
+type NodeCoverage struct {
+	daemonSets []string
+}
+
+func New(config Config) *NodeCoverage {
+	return &NodeCoverage{
+		daemonSets: config.GetDaemonSets(),
+	}
+}
+
+// Audit checks that every node is covered by each configured security-critical DaemonSet, and that those DaemonSets
+// tolerate all taints
+func (a *NodeCoverage) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	if len(a.daemonSets) == 0 {
+		return nil, nil
+	}
+
+	switch kubeType := resource.(type) {
+	case *k8s.DaemonSetV1:
+		if a.isSecurityAgent(kubeType) {
+			return auditDaemonSet(kubeType), nil
+		}
+	case *k8s.NodeV1:
+		return a.auditNode(kubeType, resources), nil
+	}
+
+	return nil, nil
+}
+
+func auditDaemonSet(daemonSet *k8s.DaemonSetV1) []*kubeaudit.AuditResult {
+	for _, toleration := range daemonSet.Spec.Template.Spec.Tolerations {
+		if toleration.Key == "" && toleration.Effect == "" && toleration.Operator == v1.TolerationOpExists {
+			return nil
+		}
+	}
+
+	return []*kubeaudit.AuditResult{
+		{
+			Auditor:  Name,
+			Rule:     SecurityAgentNotToleratingAllTaints,
+			Severity: kubeaudit.Warn,
+			Message:  "Security-critical DaemonSet does not tolerate all taints. Nodes with taints it does not tolerate will not be covered. A toleration with 'operator: Exists' and no key should be added.",
+			Metadata: kubeaudit.Metadata{
+				"DaemonSet": daemonSet.Name,
+			},
+		},
+	}
+}
+
+func (a *NodeCoverage) auditNode(node *k8s.NodeV1, resources []k8s.Resource) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	for _, daemonSetName := range a.daemonSets {
+		daemonSet := findDaemonSet(daemonSetName, resources)
+		if daemonSet == nil {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     SecurityAgentMissing,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("Security-critical DaemonSet %s was not found so node %s is not covered by it.", daemonSetName, node.Name),
+				Metadata: kubeaudit.Metadata{
+					"Node":      node.Name,
+					"DaemonSet": daemonSetName,
+				},
+			})
+			continue
+		}
+
+		if covered, reason := coversNode(daemonSet, node); !covered {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     NodeNotCoveredBySecurityAgent,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("Security-critical DaemonSet %s cannot be scheduled on node %s: %s.", daemonSetName, node.Name, reason),
+				Metadata: kubeaudit.Metadata{
+					"Node":      node.Name,
+					"DaemonSet": daemonSetName,
+					"Reason":    reason,
+				},
+			})
+		}
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", node, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}
+		}
+		return nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", node, OverrideLabel)
+	}
+	return auditResults
+}
+
+func (a *NodeCoverage) isSecurityAgent(daemonSet *k8s.DaemonSetV1) bool {
+	for _, daemonSetName := range a.daemonSets {
+		if matchesDaemonSet(daemonSetName, daemonSet) {
+			return true
+		}
+	}
+	return false
+}
+
+func findDaemonSet(daemonSetName string, resources []k8s.Resource) *k8s.DaemonSetV1 {
+	for _, resource := range resources {
+		if daemonSet, ok := resource.(*k8s.DaemonSetV1); ok && matchesDaemonSet(daemonSetName, daemonSet) {
+			return daemonSet
+		}
+	}
+	return nil
+}
+
+// matchesDaemonSet returns true if the DaemonSet matches the configured name, which is either a name or a
+// "namespace/name" pair
+func matchesDaemonSet(daemonSetName string, daemonSet *k8s.DaemonSetV1) bool {
+	if parts := strings.SplitN(daemonSetName, "/", 2); len(parts) == 2 {
+		return daemonSet.Namespace == parts[0] && daemonSet.Name == parts[1]
+	}
+	return daemonSet.Name == daemonSetName
+}
+
+// coversNode returns true if the DaemonSet's pods can be scheduled on the node. If not, it also returns the reason
+func coversNode(daemonSet *k8s.DaemonSetV1, node *k8s.NodeV1) (bool, string) {
+	podSpec := daemonSet.Spec.Template.Spec
+
+	if !labels.SelectorFromSet(podSpec.NodeSelector).Matches(labels.Set(node.Labels)) {
+		return false, "node does not match the DaemonSet's node selector"
+	}
+
+	if !matchesNodeAffinity(podSpec.Affinity, node) {
+		return false, "node does not match the DaemonSet's required node affinity"
+	}
+
+	tolerations := append(append([]v1.Toleration{}, podSpec.Tolerations...), daemonSetTolerations...)
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect == v1.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !toleratesTaint(tolerations, taint) {
+			return false, fmt.Sprintf("taint %s is not tolerated", taint.ToString())
+		}
+	}
+
+	return true, ""
+}
+
+func toleratesTaint(tolerations []v1.Toleration, taint *v1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesNodeAffinity returns true if the node matches at least one of the required node selector terms. Only label
+// expressions are evaluated
+func matchesNodeAffinity(affinity *v1.Affinity, node *k8s.NodeV1) bool {
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		return true
+	}
+
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) == 0 {
+		return true
+	}
+
+	for _, term := range terms {
+		selector, err := nodeSelectorTermToSelector(term)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(node.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+func nodeSelectorTermToSelector(term v1.NodeSelectorTerm) (labels.Selector, error) {
+	operators := map[v1.NodeSelectorOperator]selection.Operator{
+		v1.NodeSelectorOpIn:           selection.In,
+		v1.NodeSelectorOpNotIn:        selection.NotIn,
+		v1.NodeSelectorOpExists:       selection.Exists,
+		v1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+		v1.NodeSelectorOpGt:           selection.GreaterThan,
+		v1.NodeSelectorOpLt:           selection.LessThan,
+	}
+
+	selector := labels.NewSelector()
+	for _, expression := range term.MatchExpressions {
+		operator, ok := operators[expression.Operator]
+		if !ok {
+			return nil, fmt.Errorf("unknown node selector operator %s", expression.Operator)
+		}
+		requirement, err := labels.NewRequirement(expression.Key, operator, expression.Values)
+		if err != nil {
+			return nil, err
+		}
+		selector = selector.Add(*requirement)
+	}
+	return selector, nil
+}
@@ -0,0 +1,39 @@
+package nodecoverage
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditNodeCoverage(t *testing.T) {
+	cases := []struct {
+		file           string
+		daemonSets     []string
+		expectedErrors []string
+	}{
+		{"node-covered.yml", []string{"falco"}, nil},
+		{"node-covered.yml", []string{"node-covered/falco"}, nil},
+		{"node-covered.yml", nil, nil},
+		{"node-not-covered-taint.yml", []string{"falco"}, []string{NodeNotCoveredBySecurityAgent, SecurityAgentNotToleratingAllTaints}},
+		{"node-not-covered-selector.yml", []string{"falco"}, []string{NodeNotCoveredBySecurityAgent}},
+		{"node-missing-agent.yml", []string{"falco"}, []string{SecurityAgentMissing}},
+		{"node-missing-agent.yml", []string{"other-namespace/something-else"}, []string{SecurityAgentMissing}},
+		{"node-missing-agent-allowed.yml", []string{"falco"}, []string{override.GetOverriddenResultName(SecurityAgentMissing)}},
+		{"node-redundant-override.yml", []string{"falco"}, []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			// Nodes are cluster-scoped so only manifest mode is tested
+			test.AuditManifest(t, fixtureDir, tc.file, New(Config{DaemonSets: tc.daemonSets}), tc.expectedErrors)
+		})
+	}
+}
@@ -8,9 +8,13 @@ import (
 
 type fixRunAsNonRoot struct {
 	container *k8s.ContainerV1
+	podSpec   *k8s.PodSpecV1
 }
 
 func (f *fixRunAsNonRoot) Plan() string {
+	if !isPodRunAsUserNil(f.podSpec) && *f.podSpec.SecurityContext.RunAsUser == 0 {
+		return fmt.Sprintf("Set runAsNonRoot to 'true' in container SecurityContext for container %s and remove runAsUser 0 from the PodSecurityContext", f.container.Name)
+	}
 	return fmt.Sprintf("Set runAsNonRoot to 'true' in container SecurityContext for container %s", f.container.Name)
 }
 
@@ -23,6 +27,31 @@ func (f *fixRunAsNonRoot) Apply(resource k8s.Resource) []k8s.Resource {
 		f.container.SecurityContext.RunAsUser = nil
 	}
 
+	// The container would inherit runAsUser 0 from the PodSecurityContext, which the kubelet refuses to run with
+	// runAsNonRoot set to true
+	if f.container.SecurityContext.RunAsUser == nil && !isPodRunAsUserNil(f.podSpec) && *f.podSpec.SecurityContext.RunAsUser == 0 {
+		removePodRunAsUserRoot(resource, f.podSpec, f.container)
+	}
+
 	f.container.SecurityContext.RunAsNonRoot = k8s.NewTrue()
 	return nil
 }
+
+// removePodRunAsUserRoot removes runAsUser 0 from the PodSecurityContext. The other containers which inherit it and
+// have not been fixed yet keep running as root by setting runAsUser 0 in their container SecurityContext, so that
+// overridden containers are not changed and the fixes of the remaining containers still apply
+func removePodRunAsUserRoot(resource k8s.Resource, podSpec *k8s.PodSpecV1, fixedContainer *k8s.ContainerV1) {
+	for _, container := range k8s.GetContainers(resource) {
+		if container == fixedContainer || !isContainerRunAsUserNil(container) {
+			continue
+		}
+		if !isContainerRunAsNonRootNil(container) && *container.SecurityContext.RunAsNonRoot {
+			continue
+		}
+		if container.SecurityContext == nil {
+			container.SecurityContext = &k8s.SecurityContextV1{}
+		}
+		container.SecurityContext.RunAsUser = podSpec.SecurityContext.RunAsUser
+	}
+	podSpec.SecurityContext.RunAsUser = nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/Shopify/kubeaudit/internal/test"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFixRunAsNonRoot(t *testing.T) {
@@ -31,6 +32,7 @@ func TestFixRunAsNonRoot(t *testing.T) {
 		{"run-as-user-0-allowed.yml", fixtureDir, nil},
 		{"run-as-user-psc-0.yml", fixtureDir, k8s.NewTrue()},
 		{"run-as-user-psc-0-allowed.yml", fixtureDir, nil},
+		{test.InitAndEphemeralContainersFixture, test.SharedFixturesDir, k8s.NewTrue()},
 		{"run-as-user-psc-1.yml", fixtureDir, nil},
 		{"run-as-user-psc-1-csc-0.yml", fixtureDir, k8s.NewTrue()},
 		{"run-as-user-psc-0-csc-0.yml", fixtureDir, k8s.NewTrue()},
@@ -59,7 +61,9 @@ func TestFixRunAsNonRoot(t *testing.T) {
 					if tc.expectedValue == nil {
 						assert.True(t, (container.SecurityContext == nil || container.SecurityContext.RunAsNonRoot == nil))
 					} else {
-						assert.Equal(t, *tc.expectedValue, *container.SecurityContext.RunAsNonRoot)
+						runAsNonRoot := getRunAsNonRoot(resource, container)
+						require.NotNil(t, runAsNonRoot)
+						assert.Equal(t, *tc.expectedValue, *runAsNonRoot)
 					}
 				}
 			}
@@ -80,10 +84,64 @@ func TestFixRunAsNonRoot(t *testing.T) {
 					case "container1":
 						assert.True(t, (container.SecurityContext == nil || container.SecurityContext.RunAsNonRoot == nil))
 					case "container2":
-						assert.True(t, *container.SecurityContext.RunAsNonRoot)
+						assert.True(t, *getRunAsNonRoot(resource, container))
 					}
 				}
 			}
 		})
 	}
 }
+
+func TestFixRunAsUserPSCRoot(t *testing.T) {
+	cases := []struct {
+		file                 string
+		expectedRunAsUserCSC map[string]*int64
+	}{
+		{"run-as-user-psc-0.yml", map[string]*int64{"container": nil}},
+		{"run-as-user-psc-0-csc-0.yml", map[string]*int64{"container": nil}},
+		{"run-as-user-psc-0-csc-nil-multiple-cont.yml", map[string]*int64{"container1": int64Ptr(1), "container2": nil}},
+		// The overridden container keeps running as root
+		{"run-as-user-psc-0-csc-nil-allowed-multiple-cont.yml", map[string]*int64{"container1": int64Ptr(0), "container2": nil}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			resources, report := test.FixSetup(t, fixtureDir, tc.file, New())
+			for _, resource := range resources {
+				podSpec := k8s.GetPodSpec(resource)
+				require.NotNil(t, podSpec)
+				assert.True(t, isPodRunAsUserNil(podSpec))
+				for _, container := range k8s.GetContainers(resource) {
+					var runAsUser *int64
+					if container.SecurityContext != nil {
+						runAsUser = container.SecurityContext.RunAsUser
+					}
+					assert.Equal(t, tc.expectedRunAsUserCSC[container.Name], runAsUser, container.Name)
+				}
+			}
+			for _, result := range report.Results() {
+				for _, auditResult := range result.GetAuditResults() {
+					assert.NotEqual(t, RunAsUserPSCRoot, auditResult.Rule)
+				}
+			}
+		})
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+// getRunAsNonRoot returns the runAsNonRoot the container runs with, which is inherited from the PodSecurityContext if
+// it is not set in the container SecurityContext. Autofix moves runAsNonRoot to the PodSecurityContext when all the
+// containers set it to the same value
+func getRunAsNonRoot(resource k8s.Resource, container *k8s.ContainerV1) *bool {
+	if !isContainerRunAsNonRootNil(container) {
+		return container.SecurityContext.RunAsNonRoot
+	}
+	podSpec := k8s.GetPodSpec(resource)
+	if isPodRunAsNonRootNil(podSpec) {
+		return nil
+	}
+	return podSpec.SecurityContext.RunAsNonRoot
+}
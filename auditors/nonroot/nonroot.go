@@ -62,6 +62,7 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 				Message:  "runAsUser is set to UID 0 (root user) in the container SecurityContext. Either set it to a value > 0 or remove it and set runAsNonRoot to true.",
 				PendingFix: &fixRunAsNonRoot{
 					container: container,
+					podSpec:   podSpec,
 				},
 				Metadata: kubeaudit.Metadata{
 					"Container": container.Name,
@@ -95,6 +96,7 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 				Message:  "runAsUser is set to UID 0 (root user) in the PodSecurityContext. Either set it to a value > 0 or remove it and set runAsNonRoot to true.",
 				PendingFix: &fixRunAsNonRoot{
 					container: container,
+					podSpec:   podSpec,
 				},
 				Metadata: kubeaudit.Metadata{
 					"Container": container.Name,
@@ -113,6 +115,7 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 			Message:  "runAsNonRoot is set to false in the container SecurityContext. Either set it to true or set runAsUser to a value > 0.",
 			PendingFix: &fixRunAsNonRoot{
 				container: container,
+				podSpec:   podSpec,
 			},
 			Metadata: kubeaudit.Metadata{
 				"Container": container.Name,
@@ -129,6 +132,7 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 				Message:  "runAsNonRoot should be set to true or runAsUser should be set to a value > 0 either in the container SecurityContext or PodSecurityContext.",
 				PendingFix: &fixRunAsNonRoot{
 					container: container,
+					podSpec:   podSpec,
 				},
 				Metadata: kubeaudit.Metadata{
 					"Container": container.Name,
@@ -144,6 +148,7 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 				Message:  "runAsNonRoot is set to false in the PodSecurityContext. Either set it to true or set runAsUser to a value > 0.",
 				PendingFix: &fixRunAsNonRoot{
 					container: container,
+					podSpec:   podSpec,
 				},
 				Metadata: kubeaudit.Metadata{
 					"Container": container.Name,
@@ -7,6 +7,7 @@ import (
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/internal/test"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
 )
 
 const fixtureDir = "fixtures"
@@ -49,6 +50,9 @@ func TestAuditRunAsNonRoot(t *testing.T) {
 		{"run-as-user-redundant-override-pod.yml", fixtureDir, []string{kubeaudit.RedundantAuditorOverride}},
 		{"run-as-user-psc-0-csc-nil-multiple-cont.yml", fixtureDir, []string{RunAsUserPSCRoot}},
 		{"run-as-user-psc-0-csc-1-multiple-cont.yml", fixtureDir, []string{RunAsUserPSCRoot}},
+		{"run-as-user-psc-0-csc-nil-allowed-multiple-cont.yml", fixtureDir, []string{
+			override.GetOverriddenResultName(RunAsUserPSCRoot), RunAsUserPSCRoot,
+		}},
 		{"run-as-user-psc-0-allowed-multi-containers-multi-labels.yml", fixtureDir, []string{
 			override.GetOverriddenResultName(RunAsUserPSCRoot),
 		}},
@@ -75,3 +79,8 @@ func TestAuditRunAsNonRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditRunAsNonRootInitAndEphemeralContainers(t *testing.T) {
+	report := test.AuditManifest(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, New(), []string{RunAsNonRootPSCNilCSCNil})
+	assert.Equal(t, test.InitAndEphemeralContainers, test.AuditedContainers(report, RunAsNonRootPSCNilCSCNil))
+}
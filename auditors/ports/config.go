@@ -0,0 +1,13 @@
+package ports
+
+type Config struct {
+	// ForbiddenPorts are the ports which containers must not expose, as container ports or host ports
+	ForbiddenPorts []int `yaml:"forbiddenPorts"`
+}
+
+func (c *Config) GetForbiddenPorts() []int {
+	if c == nil {
+		return nil
+	}
+	return c.ForbiddenPorts
+}
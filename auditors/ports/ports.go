@@ -0,0 +1,215 @@
+package ports
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	apiv1 "k8s.io/api/core/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const Name = "ports"
+
+const (
+	// PrivilegedPortExposed occurs when a container exposes a port below 1024
+	PrivilegedPortExposed = "PrivilegedPortExposed"
+	// ForbiddenPortExposed occurs when a container exposes a port which is forbidden by the config, as a container
+	// port or a host port
+	ForbiddenPortExposed = "ForbiddenPortExposed"
+	// ServiceTargetPortUndeclared occurs when a Service targets a port which none of the containers of the pods it
+	// selects declare
+	ServiceTargetPortUndeclared = "ServiceTargetPortUndeclared"
+)
+
+const OverrideLabel = "allow-port-exposure"
+
+// MaxPrivilegedPort is the highest port which requires root or the NET_BIND_SERVICE capability to bind to
+const MaxPrivilegedPort = 1023
+
+// Ports implements Auditable
+type Ports struct {
+	forbiddenPorts map[int32]bool
+}
+
+func New(config Config) (*Ports, error) {
+	forbiddenPorts := map[int32]bool{}
+	for _, port := range config.GetForbiddenPorts() {
+		if port < 1 || port > 65535 {
+			return nil, fmt.Errorf("error creating ports auditor: invalid forbidden port %d", port)
+		}
+		forbiddenPorts[int32(port)] = true
+	}
+
+	return &Ports{forbiddenPorts: forbiddenPorts}, nil
+}
+
+// Audit checks that containers do not expose privileged or forbidden ports, and that Services only target ports
+// declared by the containers of the pods they select
+func (a *Ports) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	if service, ok := resource.(*k8s.ServiceV1); ok {
+		return auditService(service, resources), nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, container := range k8s.GetContainers(resource) {
+		containerResults := a.auditContainer(container)
+		if len(containerResults) == 0 {
+			if auditResult := override.ApplyOverride(nil, Name, container.Name, resource, OverrideLabel); auditResult != nil {
+				auditResults = append(auditResults, auditResult)
+			}
+			continue
+		}
+
+		for _, auditResult := range containerResults {
+			auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, container.Name, resource, OverrideLabel))
+		}
+	}
+
+	return auditResults, nil
+}
+
+func (a *Ports) auditContainer(container *k8s.ContainerV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	for _, port := range container.Ports {
+		if forbiddenPort, ok := a.getForbiddenPort(port); ok {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     ForbiddenPortExposed,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("Container exposes port %d which is forbidden by the kubeaudit config.", forbiddenPort),
+				Metadata: kubeaudit.Metadata{
+					"Container": container.Name,
+					"Port":      strconv.Itoa(int(forbiddenPort)),
+				},
+			})
+			continue
+		}
+
+		if port.ContainerPort > 0 && port.ContainerPort <= MaxPrivilegedPort {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     PrivilegedPortExposed,
+				Severity: kubeaudit.Warn,
+				Message:  fmt.Sprintf("Container exposes privileged port %d. Binding to a port below 1024 requires root or the NET_BIND_SERVICE capability. The container should listen on a port above 1023 instead.", port.ContainerPort),
+				Metadata: kubeaudit.Metadata{
+					"Container": container.Name,
+					"Port":      strconv.Itoa(int(port.ContainerPort)),
+				},
+			})
+		}
+	}
+
+	return auditResults
+}
+
+// getForbiddenPort returns the container port or the host port if it is forbidden
+func (a *Ports) getForbiddenPort(port apiv1.ContainerPort) (int32, bool) {
+	for _, p := range []int32{port.ContainerPort, port.HostPort} {
+		if a.forbiddenPorts[p] {
+			return p, true
+		}
+	}
+	return 0, false
+}
+
+func auditService(service *k8s.ServiceV1, resources []k8s.Resource) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	containers := getSelectedContainers(service, resources)
+	// If no pods are selected, for example because they are in a different manifest, there is nothing to compare to
+	if len(containers) > 0 {
+		for _, servicePort := range service.Spec.Ports {
+			if isTargetPortDeclared(servicePort, containers) {
+				continue
+			}
+
+			targetPort := getTargetPort(servicePort)
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     ServiceTargetPortUndeclared,
+				Severity: kubeaudit.Warn,
+				Message:  fmt.Sprintf("Service targets port %s which is not declared by any container of the pods it selects. The targetPort should match a declared container port.", targetPort.String()),
+				Metadata: kubeaudit.Metadata{
+					"Port":       strconv.Itoa(int(servicePort.Port)),
+					"TargetPort": targetPort.String(),
+				},
+			})
+		}
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", service, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}
+		}
+		return nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", service, OverrideLabel)
+	}
+	return auditResults
+}
+
+// getSelectedContainers returns the containers of the workloads in the namespace of the Service whose pods are
+// selected by the Service
+func getSelectedContainers(service *k8s.ServiceV1, resources []k8s.Resource) []*k8s.ContainerV1 {
+	if len(service.Spec.Selector) == 0 || service.Spec.Type == apiv1.ServiceTypeExternalName {
+		return nil
+	}
+
+	selector := k8slabels.SelectorFromSet(service.Spec.Selector)
+	var containers []*k8s.ContainerV1
+	for _, resource := range resources {
+		if k8s.GetPodSpec(resource) == nil || k8s.GetObjectMeta(resource).GetNamespace() != service.Namespace {
+			continue
+		}
+		if selector.Matches(k8slabels.Set(k8s.GetLabels(resource))) {
+			containers = append(containers, k8s.GetContainers(resource)...)
+		}
+	}
+	return containers
+}
+
+// getTargetPort returns the port targeted by the Service port. The target port defaults to the Service port
+func getTargetPort(servicePort apiv1.ServicePort) intstr.IntOrString {
+	if servicePort.TargetPort.Type == intstr.String && servicePort.TargetPort.StrVal != "" {
+		return servicePort.TargetPort
+	}
+	if servicePort.TargetPort.Type == intstr.Int && servicePort.TargetPort.IntVal != 0 {
+		return servicePort.TargetPort
+	}
+	return intstr.FromInt(int(servicePort.Port))
+}
+
+func isTargetPortDeclared(servicePort apiv1.ServicePort, containers []*k8s.ContainerV1) bool {
+	targetPort := getTargetPort(servicePort)
+	protocol := getProtocol(servicePort.Protocol)
+
+	for _, container := range containers {
+		for _, port := range container.Ports {
+			if getProtocol(port.Protocol) != protocol {
+				continue
+			}
+			if targetPort.Type == intstr.String && port.Name == targetPort.StrVal {
+				return true
+			}
+			if targetPort.Type == intstr.Int && port.ContainerPort == targetPort.IntVal {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getProtocol returns the protocol of a port, which defaults to TCP
+func getProtocol(protocol apiv1.Protocol) apiv1.Protocol {
+	if protocol == "" {
+		return apiv1.ProtocolTCP
+	}
+	return protocol
+}
@@ -0,0 +1,70 @@
+package ports
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditPorts(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"ports-unprivileged.yml", nil},
+		{"ports-privileged.yml", []string{PrivilegedPortExposed}},
+		{"ports-forbidden.yml", nil},
+		{"ports-privileged-allowed.yml", []string{override.GetOverriddenResultName(PrivilegedPortExposed)}},
+		{"ports-privileged-container-allowed.yml", []string{override.GetOverriddenResultName(PrivilegedPortExposed)}},
+		{"ports-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+		{"service-target-port-declared.yml", nil},
+		{"service-target-port-default.yml", nil},
+		{"service-target-port-undeclared.yml", []string{ServiceTargetPortUndeclared}},
+		{"service-target-port-named-undeclared.yml", []string{ServiceTargetPortUndeclared}},
+		{"service-target-port-protocol-mismatch.yml", []string{ServiceTargetPortUndeclared}},
+		{"service-no-selected-pods.yml", nil},
+		{"service-target-port-undeclared-allowed.yml", []string{override.GetOverriddenResultName(ServiceTargetPortUndeclared)}},
+	}
+
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditForbiddenPorts(t *testing.T) {
+	auditor, err := New(Config{ForbiddenPorts: []int{8080, 2375}})
+	require.NoError(t, err)
+
+	report := test.AuditManifest(t, fixtureDir, "ports-forbidden.yml", auditor, []string{ForbiddenPortExposed})
+
+	// Both the forbidden container port and the forbidden host port are reported
+	var ports []string
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			ports = append(ports, auditResult.Metadata["Port"])
+		}
+	}
+	assert.ElementsMatch(t, []string{"8080", "2375"}, ports)
+}
+
+func TestNewInvalidForbiddenPort(t *testing.T) {
+	_, err := New(Config{ForbiddenPorts: []int{0}})
+	assert.Error(t, err)
+
+	_, err = New(Config{ForbiddenPorts: []int{65536}})
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ func TestFixPrivilegeEscalation(t *testing.T) {
 		{"allow-privilege-escalation-true-allowed.yml", fixtureDir, true},
 		{"allow-privilege-escalation-true-multi-allowed-multi-containers.yml", fixtureDir, true},
 		{"allow-privilege-escalation-true.yml", fixtureDir, false},
+		{test.InitAndEphemeralContainersFixture, test.SharedFixturesDir, false},
 	}
 
 	for _, tc := range cases {
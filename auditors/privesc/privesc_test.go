@@ -7,6 +7,7 @@ import (
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/internal/test"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
 )
 
 const fixtureDir = "fixtures"
@@ -35,3 +36,8 @@ func TestAuditPrivilegeEscalation(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditPrivilegeEscalationInitAndEphemeralContainers(t *testing.T) {
+	report := test.AuditManifest(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, New(), []string{AllowPrivilegeEscalationNil})
+	assert.Equal(t, test.InitAndEphemeralContainers, test.AuditedContainers(report, AllowPrivilegeEscalationNil))
+}
@@ -19,6 +19,7 @@ func TestFixPrivileged(t *testing.T) {
 		{"privileged-true-allowed.yml", fixtureDir, true},
 		{"privileged-redundant-override.yml", fixtureDir, false},
 		{"privileged-true-allowed-multi-containers-multi-labels.yml", fixtureDir, true},
+		{test.InitAndEphemeralContainersFixture, test.SharedFixturesDir, false},
 	}
 
 	for _, tc := range cases {
@@ -1,9 +1,12 @@
 package privileged
 
 import (
+	"fmt"
+
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/Shopify/kubeaudit/pkg/windows"
 )
 
 const Name = "privileged"
@@ -14,6 +17,9 @@ const (
 	// PrivilegedNil occurs when privileged is not set in the container SecurityContext.
 	// Privileged defaults to false so this is ok
 	PrivilegedNil = "PrivilegedNil"
+	// PrivilegedSuspended occurs when privileged is set to true in a container of a suspended CronJob or Job, which
+	// runs the privileged container as soon as it is resumed
+	PrivilegedSuspended = "PrivilegedSuspended"
 )
 
 const OverrideLabel = "allow-privileged"
@@ -31,13 +37,19 @@ func (a *Privileged) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudi
 
 	for _, container := range k8s.GetContainers(resource) {
 		auditResult := auditContainer(container, resource)
+		if auditResult != nil && auditResult.Rule == PrivilegedTrue && k8s.IsSuspended(resource) {
+			suspendedResult := override.ApplyOverride(auditSuspended(container, resource), Name, container.Name, resource, OverrideLabel)
+			if suspendedResult != nil {
+				auditResults = append(auditResults, suspendedResult)
+			}
+		}
 		auditResult = override.ApplyOverride(auditResult, Name, container.Name, resource, OverrideLabel)
 		if auditResult != nil {
 			auditResults = append(auditResults, auditResult)
 		}
 	}
 
-	return auditResults, nil
+	return windows.LinuxOnly(resource, auditResults), nil
 }
 
 func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudit.AuditResult {
@@ -74,6 +86,22 @@ func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudi
 	return nil
 }
 
+// auditSuspended returns the audit result of a privileged container of a suspended CronJob or Job. Suspended workloads
+// are easily forgotten, but anyone who can update them can resume them
+func auditSuspended(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudit.AuditResult {
+	kind := resource.GetObjectKind().GroupVersionKind().Kind
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     PrivilegedSuspended,
+		Severity: kubeaudit.Warn,
+		Message:  fmt.Sprintf("privileged is set to 'true' in the container SecurityContext of a suspended %s. The container doesn't run while the %s is suspended, but runs privileged as soon as it is resumed. The %s should be deleted if it is no longer used.", kind, kind, kind),
+		Metadata: kubeaudit.Metadata{
+			"Container": container.Name,
+			"Kind":      kind,
+		},
+	}
+}
+
 func isPrivilegedTrue(container *k8s.ContainerV1) bool {
 	if isPrivilegedNil(container) {
 		return false
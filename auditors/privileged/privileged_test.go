@@ -7,6 +7,7 @@ import (
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/internal/test"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
 )
 
 const fixtureDir = "fixtures"
@@ -26,6 +27,17 @@ func TestAuditPrivileged(t *testing.T) {
 			PrivilegedTrue,
 			override.GetOverriddenResultName(PrivilegedTrue)},
 		},
+		{"privileged-true-allowed-expired.yml", fixtureDir, []string{PrivilegedTrue, kubeaudit.ExpiredAuditorOverride}},
+		{"privileged-true-allowed-annotation.yml", fixtureDir, []string{override.GetOverriddenResultName(PrivilegedTrue)}},
+		{"privileged-true-allowed-container-scoped.yml", fixtureDir, []string{
+			PrivilegedTrue,
+			override.GetOverriddenResultName(PrivilegedTrue)},
+		},
+		{"privileged-true-suspended.yml", fixtureDir, []string{PrivilegedTrue, PrivilegedSuspended}},
+		{"privileged-true-suspended-allowed.yml", fixtureDir, []string{
+			override.GetOverriddenResultName(PrivilegedTrue),
+			override.GetOverriddenResultName(PrivilegedSuspended)},
+		},
 	}
 
 	for _, tc := range cases {
@@ -38,3 +50,8 @@ func TestAuditPrivileged(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditPrivilegedInitAndEphemeralContainers(t *testing.T) {
+	report := test.AuditManifest(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, New(), []string{PrivilegedNil})
+	assert.Equal(t, test.InitAndEphemeralContainers, test.AuditedContainers(report, PrivilegedNil))
+}
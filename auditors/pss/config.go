@@ -0,0 +1,19 @@
+package pss
+
+import "fmt"
+
+type Config struct {
+	// Level is the Pod Security Standards level workloads are required to satisfy: "baseline" or "restricted"
+	Level string `yaml:"level"`
+}
+
+func (config *Config) GetLevel() (string, error) {
+	if config == nil || config.Level == "" {
+		return LevelRestricted, nil
+	}
+	switch config.Level {
+	case LevelBaseline, LevelRestricted:
+		return config.Level, nil
+	}
+	return "", fmt.Errorf("invalid Pod Security Standards level %q (must be %q or %q)", config.Level, LevelBaseline, LevelRestricted)
+}
@@ -0,0 +1,429 @@
+package pss
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	// PSSBaselineHostProcess occurs when a Windows pod or container runs as a HostProcess container
+	PSSBaselineHostProcess = "PSSBaselineHostProcess"
+	// PSSBaselineHostNamespaces occurs when a pod shares the host's network, PID or IPC namespace
+	PSSBaselineHostNamespaces = "PSSBaselineHostNamespaces"
+	// PSSBaselinePrivilegedContainers occurs when a container runs as privileged
+	PSSBaselinePrivilegedContainers = "PSSBaselinePrivilegedContainers"
+	// PSSBaselineCapabilities occurs when a container adds a capability outside of the baseline allowed list
+	PSSBaselineCapabilities = "PSSBaselineCapabilities"
+	// PSSBaselineHostPathVolumes occurs when a pod mounts a hostPath volume
+	PSSBaselineHostPathVolumes = "PSSBaselineHostPathVolumes"
+	// PSSBaselineHostPorts occurs when a container uses a host port
+	PSSBaselineHostPorts = "PSSBaselineHostPorts"
+	// PSSBaselineAppArmor occurs when AppArmor is disabled or set to a custom profile type for a container
+	PSSBaselineAppArmor = "PSSBaselineAppArmor"
+	// PSSBaselineSELinux occurs when a custom SELinux type, user or role is set
+	PSSBaselineSELinux = "PSSBaselineSELinux"
+	// PSSBaselineProcMount occurs when a container uses a non-default /proc mount type
+	PSSBaselineProcMount = "PSSBaselineProcMount"
+	// PSSBaselineSeccomp occurs when the seccomp profile is explicitly set to Unconfined
+	PSSBaselineSeccomp = "PSSBaselineSeccomp"
+	// PSSBaselineSysctls occurs when a pod sets a sysctl outside of the safe set
+	PSSBaselineSysctls = "PSSBaselineSysctls"
+	// PSSRestrictedVolumeTypes occurs when a pod uses a volume type outside of the restricted allowed list
+	PSSRestrictedVolumeTypes = "PSSRestrictedVolumeTypes"
+	// PSSRestrictedPrivilegeEscalation occurs when a container does not set allowPrivilegeEscalation to false
+	PSSRestrictedPrivilegeEscalation = "PSSRestrictedPrivilegeEscalation"
+	// PSSRestrictedRunningAsNonRoot occurs when a container is not required to run as non-root
+	PSSRestrictedRunningAsNonRoot = "PSSRestrictedRunningAsNonRoot"
+	// PSSRestrictedRunningAsNonRootUser occurs when a pod or container sets runAsUser to 0
+	PSSRestrictedRunningAsNonRootUser = "PSSRestrictedRunningAsNonRootUser"
+	// PSSRestrictedSeccomp occurs when a container does not use the RuntimeDefault or a Localhost seccomp profile
+	PSSRestrictedSeccomp = "PSSRestrictedSeccomp"
+	// PSSRestrictedCapabilities occurs when a container does not drop ALL capabilities or adds a capability other than
+	// NET_BIND_SERVICE
+	PSSRestrictedCapabilities = "PSSRestrictedCapabilities"
+)
+
+const appArmorContainerAnnotationKeyPrefix = "container.apparmor.security.beta.kubernetes.io/"
+
+// Control is a Pod Security Standards control
+type Control struct {
+	// Rule is the audit result rule reported when the control fails
+	Rule string
+	// Name is the official name of the control
+	Name string
+	// Level is the Pod Security Standards level the control belongs to
+	Level string
+
+	check func(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation
+}
+
+type violation struct {
+	container string
+	detail    string
+}
+
+// Controls are the Pod Security Standards controls, in the order of the upstream documentation
+var Controls = []Control{
+	{PSSBaselineHostProcess, "HostProcess", LevelBaseline, checkHostProcess},
+	{PSSBaselineHostNamespaces, "Host Namespaces", LevelBaseline, checkHostNamespaces},
+	{PSSBaselinePrivilegedContainers, "Privileged Containers", LevelBaseline, checkPrivileged},
+	{PSSBaselineCapabilities, "Capabilities", LevelBaseline, checkBaselineCapabilities},
+	{PSSBaselineHostPathVolumes, "HostPath Volumes", LevelBaseline, checkHostPathVolumes},
+	{PSSBaselineHostPorts, "Host Ports", LevelBaseline, checkHostPorts},
+	{PSSBaselineAppArmor, "AppArmor", LevelBaseline, checkAppArmor},
+	{PSSBaselineSELinux, "SELinux", LevelBaseline, checkSELinux},
+	{PSSBaselineProcMount, "/proc Mount Type", LevelBaseline, checkProcMount},
+	{PSSBaselineSeccomp, "Seccomp", LevelBaseline, checkBaselineSeccomp},
+	{PSSBaselineSysctls, "Sysctls", LevelBaseline, checkSysctls},
+	{PSSRestrictedVolumeTypes, "Volume Types", LevelRestricted, checkVolumeTypes},
+	{PSSRestrictedPrivilegeEscalation, "Privilege Escalation", LevelRestricted, linuxOnly(checkPrivilegeEscalation)},
+	{PSSRestrictedRunningAsNonRoot, "Running as Non-root", LevelRestricted, checkRunAsNonRoot},
+	{PSSRestrictedRunningAsNonRootUser, "Running as Non-root user", LevelRestricted, checkRunAsNonRootUser},
+	{PSSRestrictedSeccomp, "Seccomp", LevelRestricted, linuxOnly(checkRestrictedSeccomp)},
+	{PSSRestrictedCapabilities, "Capabilities", LevelRestricted, linuxOnly(checkRestrictedCapabilities)},
+}
+
+// linuxOnly exempts pods which set their OS to Windows from a control of Linux settings, as the Pod Security Standards
+// do. Like the Pod Security admission controller, the node selector of the pod is not considered
+func linuxOnly(check func(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation) func(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	return func(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation {
+		if podSpec.OS != nil && podSpec.OS.Name == apiv1.Windows {
+			return nil
+		}
+		return check(podSpec, resource)
+	}
+}
+
+// GetControl returns the control reported with the given audit result rule
+func GetControl(rule string) (Control, bool) {
+	for _, control := range Controls {
+		if control.Rule == rule {
+			return control, true
+		}
+	}
+	return Control{}, false
+}
+
+var baselineAllowedCapabilities = map[apiv1.Capability]bool{
+	"AUDIT_WRITE":      true,
+	"CHOWN":            true,
+	"DAC_OVERRIDE":     true,
+	"FOWNER":           true,
+	"FSETID":           true,
+	"KILL":             true,
+	"MKNOD":            true,
+	"NET_BIND_SERVICE": true,
+	"SETFCAP":          true,
+	"SETGID":           true,
+	"SETPCAP":          true,
+	"SETUID":           true,
+	"SYS_CHROOT":       true,
+}
+
+var allowedSELinuxTypes = map[string]bool{
+	"":                 true,
+	"container_t":      true,
+	"container_init_t": true,
+	"container_kvm_t":  true,
+}
+
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced":              true,
+	"net.ipv4.ip_local_port_range":        true,
+	"net.ipv4.ip_local_reserved_ports":    true,
+	"net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.tcp_syncookies":             true,
+	"net.ipv4.ping_group_range":           true,
+	"net.ipv4.tcp_keepalive_time":         true,
+	"net.ipv4.tcp_fin_timeout":            true,
+	"net.ipv4.tcp_keepalive_intvl":        true,
+	"net.ipv4.tcp_keepalive_probes":       true,
+}
+
+// getAllContainers returns the containers, init containers and ephemeral containers of the resource since the controls
+// apply to all of them
+func getAllContainers(resource k8s.Resource) []*k8s.ContainerV1 {
+	return k8s.GetContainers(resource)
+}
+
+func checkHostProcess(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	if sc := podSpec.SecurityContext; sc != nil && sc.WindowsOptions != nil && isTrue(sc.WindowsOptions.HostProcess) {
+		violations = append(violations, violation{detail: "hostProcess is set to true in the pod SecurityContext"})
+	}
+	for _, container := range getAllContainers(resource) {
+		if sc := container.SecurityContext; sc != nil && sc.WindowsOptions != nil && isTrue(sc.WindowsOptions.HostProcess) {
+			violations = append(violations, violation{container.Name, "hostProcess is set to true"})
+		}
+	}
+	return violations
+}
+
+func checkHostNamespaces(podSpec *k8s.PodSpecV1, _ k8s.Resource) []violation {
+	var namespaces []string
+	if podSpec.HostNetwork {
+		namespaces = append(namespaces, "hostNetwork")
+	}
+	if podSpec.HostPID {
+		namespaces = append(namespaces, "hostPID")
+	}
+	if podSpec.HostIPC {
+		namespaces = append(namespaces, "hostIPC")
+	}
+	if len(namespaces) == 0 {
+		return nil
+	}
+	return []violation{{detail: strings.Join(namespaces, ", ") + " must not be set to true"}}
+}
+
+func checkPrivileged(_ *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	for _, container := range getAllContainers(resource) {
+		if sc := container.SecurityContext; sc != nil && isTrue(sc.Privileged) {
+			violations = append(violations, violation{container.Name, "privileged is set to true"})
+		}
+	}
+	return violations
+}
+
+func checkBaselineCapabilities(_ *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	for _, container := range getAllContainers(resource) {
+		var disallowed []string
+		for _, capability := range getAddedCapabilities(container) {
+			if !baselineAllowedCapabilities[capability] {
+				disallowed = append(disallowed, string(capability))
+			}
+		}
+		if len(disallowed) > 0 {
+			violations = append(violations, violation{container.Name, fmt.Sprintf("capabilities %s must not be added", strings.Join(disallowed, ", "))})
+		}
+	}
+	return violations
+}
+
+func checkHostPathVolumes(podSpec *k8s.PodSpecV1, _ k8s.Resource) []violation {
+	var violations []violation
+	for _, volume := range podSpec.Volumes {
+		if volume.HostPath != nil {
+			violations = append(violations, violation{detail: fmt.Sprintf("volume %s must not be a hostPath volume", volume.Name)})
+		}
+	}
+	return violations
+}
+
+func checkHostPorts(_ *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	for _, container := range getAllContainers(resource) {
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				violations = append(violations, violation{container.Name, fmt.Sprintf("host port %d must not be used", port.HostPort)})
+			}
+		}
+	}
+	return violations
+}
+
+func checkAppArmor(_ *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	annotations := k8s.GetAnnotations(resource)
+	for _, container := range getAllContainers(resource) {
+		profile, ok := annotations[appArmorContainerAnnotationKeyPrefix+container.Name]
+		if !ok || profile == "runtime/default" || strings.HasPrefix(profile, "localhost/") {
+			continue
+		}
+		violations = append(violations, violation{container.Name, fmt.Sprintf("AppArmor profile %s must not be used", profile)})
+	}
+	return violations
+}
+
+func checkSELinux(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	if sc := podSpec.SecurityContext; sc != nil {
+		if detail := checkSELinuxOptions(sc.SELinuxOptions); detail != "" {
+			violations = append(violations, violation{detail: detail + " in the pod SecurityContext"})
+		}
+	}
+	for _, container := range getAllContainers(resource) {
+		if sc := container.SecurityContext; sc != nil {
+			if detail := checkSELinuxOptions(sc.SELinuxOptions); detail != "" {
+				violations = append(violations, violation{container.Name, detail})
+			}
+		}
+	}
+	return violations
+}
+
+func checkSELinuxOptions(options *apiv1.SELinuxOptions) string {
+	if options == nil {
+		return ""
+	}
+	if !allowedSELinuxTypes[options.Type] {
+		return fmt.Sprintf("SELinux type %s must not be used", options.Type)
+	}
+	if options.User != "" || options.Role != "" {
+		return "SELinux user and role must not be set"
+	}
+	return ""
+}
+
+func checkProcMount(_ *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	for _, container := range getAllContainers(resource) {
+		sc := container.SecurityContext
+		if sc != nil && sc.ProcMount != nil && *sc.ProcMount != apiv1.DefaultProcMount {
+			violations = append(violations, violation{container.Name, fmt.Sprintf("procMount %s must not be used", *sc.ProcMount)})
+		}
+	}
+	return violations
+}
+
+func checkBaselineSeccomp(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	if sc := podSpec.SecurityContext; sc != nil && isSeccompUnconfined(sc.SeccompProfile) {
+		violations = append(violations, violation{detail: "seccomp profile must not be Unconfined in the pod SecurityContext"})
+	}
+	for _, container := range getAllContainers(resource) {
+		if sc := container.SecurityContext; sc != nil && isSeccompUnconfined(sc.SeccompProfile) {
+			violations = append(violations, violation{container.Name, "seccomp profile must not be Unconfined"})
+		}
+	}
+	return violations
+}
+
+func checkSysctls(podSpec *k8s.PodSpecV1, _ k8s.Resource) []violation {
+	if podSpec.SecurityContext == nil {
+		return nil
+	}
+	var violations []violation
+	for _, sysctl := range podSpec.SecurityContext.Sysctls {
+		if !safeSysctls[sysctl.Name] {
+			violations = append(violations, violation{detail: fmt.Sprintf("sysctl %s is not in the safe set", sysctl.Name)})
+		}
+	}
+	return violations
+}
+
+func checkVolumeTypes(podSpec *k8s.PodSpecV1, _ k8s.Resource) []violation {
+	var violations []violation
+	for _, volume := range podSpec.Volumes {
+		source := volume.VolumeSource
+		if source.ConfigMap != nil || source.CSI != nil || source.DownwardAPI != nil || source.EmptyDir != nil ||
+			source.Ephemeral != nil || source.PersistentVolumeClaim != nil || source.Projected != nil || source.Secret != nil {
+			continue
+		}
+		violations = append(violations, violation{detail: fmt.Sprintf("volume %s uses a volume type which is not allowed", volume.Name)})
+	}
+	return violations
+}
+
+func checkPrivilegeEscalation(_ *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	for _, container := range getAllContainers(resource) {
+		sc := container.SecurityContext
+		if sc == nil || sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			violations = append(violations, violation{container.Name, "allowPrivilegeEscalation must be set to false"})
+		}
+	}
+	return violations
+}
+
+func checkRunAsNonRoot(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	podRunAsNonRoot := podSpec.SecurityContext != nil && isTrue(podSpec.SecurityContext.RunAsNonRoot)
+
+	var violations []violation
+	for _, container := range getAllContainers(resource) {
+		sc := container.SecurityContext
+		if sc != nil && sc.RunAsNonRoot != nil {
+			if !*sc.RunAsNonRoot {
+				violations = append(violations, violation{container.Name, "runAsNonRoot must not be set to false"})
+			}
+			continue
+		}
+		if !podRunAsNonRoot {
+			violations = append(violations, violation{container.Name, "runAsNonRoot must be set to true in the pod or container SecurityContext"})
+		}
+	}
+	return violations
+}
+
+func checkRunAsNonRootUser(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	if sc := podSpec.SecurityContext; sc != nil && sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+		violations = append(violations, violation{detail: "runAsUser must not be set to 0 in the pod SecurityContext"})
+	}
+	for _, container := range getAllContainers(resource) {
+		if sc := container.SecurityContext; sc != nil && sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			violations = append(violations, violation{container.Name, "runAsUser must not be set to 0"})
+		}
+	}
+	return violations
+}
+
+func checkRestrictedSeccomp(podSpec *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	podAllowed := podSpec.SecurityContext != nil && isSeccompRestricted(podSpec.SecurityContext.SeccompProfile)
+
+	var violations []violation
+	for _, container := range getAllContainers(resource) {
+		sc := container.SecurityContext
+		if sc != nil && sc.SeccompProfile != nil {
+			if !isSeccompRestricted(sc.SeccompProfile) {
+				violations = append(violations, violation{container.Name, fmt.Sprintf("seccomp profile type must be RuntimeDefault or Localhost, not %s", sc.SeccompProfile.Type)})
+			}
+			continue
+		}
+		if !podAllowed {
+			violations = append(violations, violation{container.Name, "seccomp profile type must be set to RuntimeDefault or Localhost in the pod or container SecurityContext"})
+		}
+	}
+	return violations
+}
+
+func checkRestrictedCapabilities(_ *k8s.PodSpecV1, resource k8s.Resource) []violation {
+	var violations []violation
+	for _, container := range getAllContainers(resource) {
+		if !dropsAllCapabilities(container) {
+			violations = append(violations, violation{container.Name, "capabilities must drop ALL"})
+		}
+		for _, capability := range getAddedCapabilities(container) {
+			if capability != "NET_BIND_SERVICE" {
+				violations = append(violations, violation{container.Name, fmt.Sprintf("capability %s must not be added", capability)})
+			}
+		}
+	}
+	return violations
+}
+
+func getAddedCapabilities(container *k8s.ContainerV1) []apiv1.Capability {
+	if container.SecurityContext == nil || container.SecurityContext.Capabilities == nil {
+		return nil
+	}
+	return container.SecurityContext.Capabilities.Add
+}
+
+func dropsAllCapabilities(container *k8s.ContainerV1) bool {
+	if container.SecurityContext == nil || container.SecurityContext.Capabilities == nil {
+		return false
+	}
+	for _, capability := range container.SecurityContext.Capabilities.Drop {
+		if strings.ToUpper(string(capability)) == "ALL" {
+			return true
+		}
+	}
+	return false
+}
+
+func isSeccompUnconfined(profile *apiv1.SeccompProfile) bool {
+	return profile != nil && profile.Type == apiv1.SeccompProfileTypeUnconfined
+}
+
+func isSeccompRestricted(profile *apiv1.SeccompProfile) bool {
+	return profile != nil && (profile.Type == apiv1.SeccompProfileTypeRuntimeDefault || profile.Type == apiv1.SeccompProfileTypeLocalhost)
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
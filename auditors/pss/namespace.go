@@ -0,0 +1,55 @@
+package pss
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+// EnforceLabel is the Pod Security admission label which sets the Pod Security Standards level the pods of a namespace
+// must satisfy (see https://kubernetes.io/docs/concepts/security/pod-security-admission/)
+const EnforceLabel = "pod-security.kubernetes.io/enforce"
+
+const (
+	// PSSNamespaceEnforceMissing occurs when a namespace doesn't set the Pod Security admission enforce label
+	PSSNamespaceEnforceMissing = "PSSNamespaceEnforceMissing"
+	// PSSNamespaceEnforceLevelTooLow occurs when a namespace enforces a less restrictive level than the configured level
+	PSSNamespaceEnforceLevelTooLow = "PSSNamespaceEnforceLevelTooLow"
+)
+
+// auditNamespace checks that the namespace enforces at least the configured level with Pod Security admission, so the
+// cluster rejects pods which fail its controls
+func (a *PodSecurityStandards) auditNamespace(namespace *k8s.NamespaceV1) []*kubeaudit.AuditResult {
+	var auditResult *kubeaudit.AuditResult
+
+	level, ok := namespace.Labels[EnforceLabel]
+	if !ok {
+		auditResult = &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     PSSNamespaceEnforceMissing,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("Namespace doesn't enforce a Pod Security Standards level, so pods of any level are admitted. The %s label should be set to %s.", EnforceLabel, a.level),
+			Metadata: kubeaudit.Metadata{
+				"Label": EnforceLabel,
+			},
+		}
+	} else if _, valid := levelOrder[level]; !valid || levelOrder[level] < levelOrder[a.level] {
+		auditResult = &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     PSSNamespaceEnforceLevelTooLow,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("Namespace enforces the Pod Security Standards level %q. The %s label should be set to %s.", level, EnforceLabel, a.level),
+			Metadata: kubeaudit.Metadata{
+				"Label": EnforceLabel,
+				"Level": level,
+			},
+		}
+	}
+
+	if auditResult = override.ApplyOverride(auditResult, Name, "", namespace, OverrideLabel); auditResult != nil {
+		return []*kubeaudit.AuditResult{auditResult}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package pss
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const Name = "pss"
+
+// PodSecurityStandardLevel is reported for every workload with the most restrictive Pod Security Standards level it
+// satisfies
+const PodSecurityStandardLevel = "PodSecurityStandardLevel"
+
+const OverrideLabel = "allow-pod-security-standards-violation"
+
+// Pod Security Standards levels (see https://kubernetes.io/docs/concepts/security/pod-security-standards/)
+const (
+	LevelPrivileged = "privileged"
+	LevelBaseline   = "baseline"
+	LevelRestricted = "restricted"
+)
+
+var levelOrder = map[string]int{
+	LevelPrivileged: 0,
+	LevelBaseline:   1,
+	LevelRestricted: 2,
+}
+
+// PodSecurityStandards implements Auditable
+type PodSecurityStandards struct {
+	level string
+}
+
+func New(config Config) (*PodSecurityStandards, error) {
+	level, err := config.GetLevel()
+	if err != nil {
+		return nil, fmt.Errorf("error creating Pod Security Standards auditor: %w", err)
+	}
+
+	return &PodSecurityStandards{
+		level: level,
+	}, nil
+}
+
+// Audit evaluates the workload against each Pod Security Standards control. Failed controls up to the configured
+// level are reported as errors and failed controls of stricter levels are reported as info. A summary result
+// reports the most restrictive level the workload satisfies. Namespaces are checked to enforce the configured level
+// with Pod Security admission.
+func (a *PodSecurityStandards) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	if namespace, ok := resource.(*k8s.NamespaceV1); ok {
+		return a.auditNamespace(namespace), nil
+	}
+
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return nil, nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	var failedControls []string
+	satisfiedLevel := LevelRestricted
+	required := false
+
+	for _, control := range Controls {
+		violations := control.check(podSpec, resource)
+		if len(violations) == 0 {
+			continue
+		}
+
+		failedControls = append(failedControls, fmt.Sprintf("%s (%s)", control.Name, control.Level))
+		if levelOrder[control.Level] <= levelOrder[satisfiedLevel] {
+			satisfiedLevel = lowerLevel(control.Level)
+		}
+
+		severity := kubeaudit.Info
+		if levelOrder[control.Level] <= levelOrder[a.level] {
+			severity = kubeaudit.Error
+			required = true
+		}
+
+		for _, v := range violations {
+			auditResult := &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     control.Rule,
+				Severity: severity,
+				Message:  fmt.Sprintf("Pod Security Standards %s control %q failed: %s.", control.Level, control.Name, v.detail),
+				Metadata: kubeaudit.Metadata{
+					"Control": control.Name,
+					"Level":   control.Level,
+				},
+			}
+			if v.container != "" {
+				auditResult.Metadata["Container"] = v.container
+			}
+			if severity == kubeaudit.Error {
+				auditResult = override.ApplyOverride(auditResult, Name, v.container, resource, OverrideLabel)
+			}
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+
+	if !required {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+
+	summary := &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     PodSecurityStandardLevel,
+		Severity: kubeaudit.Info,
+		Message:  fmt.Sprintf("Workload satisfies the %s Pod Security Standards level.", satisfiedLevel),
+		Metadata: kubeaudit.Metadata{
+			"Level": satisfiedLevel,
+		},
+	}
+	if len(failedControls) > 0 {
+		summary.Metadata["FailedControls"] = strings.Join(failedControls, ", ")
+	}
+
+	return append(auditResults, summary), nil
+}
+
+// SatisfiedLevel returns the most restrictive Pod Security Standards level the workload satisfies, as reported by the
+// PodSecurityStandardLevel result of the auditor, or an empty string if the resource has no pod spec
+func SatisfiedLevel(resource k8s.Resource) string {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return ""
+	}
+
+	satisfiedLevel := LevelRestricted
+	for _, control := range Controls {
+		if levelOrder[control.Level] <= levelOrder[satisfiedLevel] && len(control.check(podSpec, resource)) > 0 {
+			satisfiedLevel = lowerLevel(control.Level)
+		}
+	}
+	return satisfiedLevel
+}
+
+// lowerLevel returns the level below the given level, which is the most restrictive level a workload failing a
+// control of the given level can satisfy
+func lowerLevel(level string) string {
+	if level == LevelRestricted {
+		return LevelBaseline
+	}
+	return LevelPrivileged
+}
@@ -0,0 +1,167 @@
+package pss
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditPodSecurityStandards(t *testing.T) {
+	restrictedErrors := []string{
+		PSSRestrictedPrivilegeEscalation,
+		PSSRestrictedRunningAsNonRoot,
+		PSSRestrictedSeccomp,
+		PSSRestrictedCapabilities,
+	}
+
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"restricted.yml", []string{PodSecurityStandardLevel}},
+		{"baseline.yml", append([]string{PodSecurityStandardLevel}, restrictedErrors...)},
+		{"privileged.yml", []string{
+			PodSecurityStandardLevel,
+			PSSBaselineHostNamespaces,
+			PSSBaselinePrivilegedContainers,
+			PSSBaselineCapabilities,
+			PSSBaselineHostPathVolumes,
+			PSSBaselineHostPorts,
+			PSSBaselineAppArmor,
+			PSSBaselineSeccomp,
+			PSSBaselineSysctls,
+			PSSRestrictedVolumeTypes,
+			PSSRestrictedSeccomp,
+			PSSRestrictedCapabilities,
+		}},
+		{"baseline-allowed.yml", []string{
+			PodSecurityStandardLevel,
+			override.GetOverriddenResultName(PSSRestrictedPrivilegeEscalation),
+			override.GetOverriddenResultName(PSSRestrictedRunningAsNonRoot),
+			override.GetOverriddenResultName(PSSRestrictedSeccomp),
+			override.GetOverriddenResultName(PSSRestrictedCapabilities),
+		}},
+		{"restricted-redundant-override.yml", []string{PodSecurityStandardLevel, kubeaudit.RedundantAuditorOverride}},
+		// Windows pods are exempt from the controls of Linux settings
+		{"restricted-windows.yml", []string{PodSecurityStandardLevel}},
+	}
+
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+			// The namespace created for the test doesn't enforce a Pod Security Standards level
+			localErrors := append([]string{PSSNamespaceEnforceMissing}, tc.expectedErrors...)
+			test.AuditLocal(t, fixtureDir, tc.file, auditor, strings.Split(tc.file, ".")[0], localErrors)
+		})
+	}
+}
+
+func TestAuditNamespace(t *testing.T) {
+	cases := []struct {
+		file           string
+		level          string
+		expectedErrors []string
+	}{
+		{"namespace-enforce-restricted.yml", LevelRestricted, nil},
+		{"namespace-enforce-baseline.yml", LevelRestricted, []string{PSSNamespaceEnforceLevelTooLow}},
+		{"namespace-enforce-baseline.yml", LevelBaseline, nil},
+		{"namespace-enforce-missing.yml", LevelBaseline, []string{PSSNamespaceEnforceMissing}},
+		{"namespace-enforce-missing-allowed.yml", LevelRestricted, []string{override.GetOverriddenResultName(PSSNamespaceEnforceMissing)}},
+		{"namespace-enforce-restricted.yml", LevelBaseline, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file+"/"+tc.level, func(t *testing.T) {
+			auditor, err := New(Config{Level: tc.level})
+			require.NoError(t, err)
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestPodSecurityStandardsLevel(t *testing.T) {
+	cases := []struct {
+		file          string
+		level         string
+		expectedLevel string
+		errorRules    []string
+	}{
+		{"restricted.yml", LevelRestricted, LevelRestricted, nil},
+		{"restricted-windows.yml", LevelRestricted, LevelRestricted, nil},
+		{"baseline.yml", LevelRestricted, LevelBaseline, []string{
+			PSSRestrictedPrivilegeEscalation,
+			PSSRestrictedRunningAsNonRoot,
+			PSSRestrictedSeccomp,
+			PSSRestrictedCapabilities,
+		}},
+		// Failed restricted controls are only informational when the required level is baseline
+		{"baseline.yml", LevelBaseline, LevelBaseline, nil},
+		{"privileged.yml", LevelBaseline, LevelPrivileged, []string{
+			PSSBaselineHostNamespaces,
+			PSSBaselinePrivilegedContainers,
+			PSSBaselineCapabilities,
+			PSSBaselineHostPathVolumes,
+			PSSBaselineHostPorts,
+			PSSBaselineAppArmor,
+			PSSBaselineSeccomp,
+			PSSBaselineSysctls,
+		}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file+"/"+tc.level, func(t *testing.T) {
+			auditor, err := New(Config{Level: tc.level})
+			require.NoError(t, err)
+
+			report := test.GetReport(t, fixtureDir, tc.file, []kubeaudit.Auditable{auditor}, "", test.MANIFEST_MODE)
+
+			var level string
+			errorRules := map[string]bool{}
+			for _, result := range report.Results() {
+				for _, auditResult := range result.GetAuditResults() {
+					if auditResult.Rule == PodSecurityStandardLevel {
+						level = auditResult.Metadata["Level"]
+						assert.Equal(t, level, SatisfiedLevel(result.GetResource().Object()))
+					}
+					if auditResult.Severity == kubeaudit.Error {
+						errorRules[auditResult.Rule] = true
+					}
+				}
+			}
+
+			assert.Equal(t, tc.expectedLevel, level)
+			expectedErrorRules := map[string]bool{}
+			for _, rule := range tc.errorRules {
+				expectedErrorRules[rule] = true
+			}
+			assert.Equal(t, expectedErrorRules, errorRules)
+		})
+	}
+}
+
+func TestPodSecurityStandardsInitAndEphemeralContainers(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	// Each container is reported once, whether it is a container, an init container or an ephemeral container
+	report := test.GetReport(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, []kubeaudit.Auditable{auditor}, "", test.MANIFEST_MODE)
+	assert.Equal(t, test.InitAndEphemeralContainers, test.AuditedContainers(report, PSSRestrictedPrivilegeEscalation))
+}
+
+func TestNewInvalidLevel(t *testing.T) {
+	_, err := New(Config{Level: "strict"})
+	assert.Error(t, err)
+}
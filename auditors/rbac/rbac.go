@@ -0,0 +1,247 @@
+package rbac
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const Name = "rbac"
+
+const (
+	// AggregationRuleSelectsAllClusterRoles occurs when a ClusterRole aggregation rule has an empty label selector,
+	// so the permissions of every ClusterRole are aggregated into it
+	AggregationRuleSelectsAllClusterRoles = "AggregationRuleSelectsAllClusterRoles"
+	// AggregationRuleSelectsEscalatingClusterRole occurs when a ClusterRole aggregation rule selects a ClusterRole
+	// which grants the bind, escalate or impersonate verbs
+	AggregationRuleSelectsEscalatingClusterRole = "AggregationRuleSelectsEscalatingClusterRole"
+	// RoleGrantsBind occurs when a Role or ClusterRole grants the bind verb on roles or clusterroles
+	RoleGrantsBind = "RoleGrantsBind"
+	// RoleGrantsEscalate occurs when a Role or ClusterRole grants the escalate verb on roles or clusterroles
+	RoleGrantsEscalate = "RoleGrantsEscalate"
+	// RoleGrantsImpersonate occurs when a Role or ClusterRole grants the impersonate verb on users, groups, service
+	// accounts or other identity attributes
+	RoleGrantsImpersonate = "RoleGrantsImpersonate"
+	// ServiceAccountBoundToClusterAdmin occurs when the service account of a workload is bound to the cluster-admin
+	// ClusterRole
+	ServiceAccountBoundToClusterAdmin = "ServiceAccountBoundToClusterAdmin"
+	// ServiceAccountGrantedWildcard occurs when the service account of a workload is bound to a role which grants
+	// wildcard verbs or resources
+	ServiceAccountGrantedWildcard = "ServiceAccountGrantedWildcard"
+	// ServiceAccountCanReadSecretsInAllNamespaces occurs when the service account of a workload is bound by a
+	// ClusterRoleBinding to a role which allows reading secrets
+	ServiceAccountCanReadSecretsInAllNamespaces = "ServiceAccountCanReadSecretsInAllNamespaces"
+)
+
+const (
+	// OverrideLabel is placed on Roles and ClusterRoles
+	OverrideLabel = "allow-rbac-escalation"
+	// ServiceAccountOverrideLabel is placed on workloads
+	ServiceAccountOverrideLabel = "allow-service-account-rbac-exposure"
+)
+
+// bootstrappingLabel is set on the default roles created by the kube-apiserver, which are not audited
+const bootstrappingLabel = "kubernetes.io/bootstrapping"
+
+const (
+	rbacAPIGroup           = "rbac.authorization.k8s.io"
+	authenticationAPIGroup = "authentication.k8s.io"
+	wildcard               = "*"
+)
+
+// escalationVerb is a verb which lets a user gain permissions they were not granted directly
+type escalationVerb struct {
+	verb      string
+	rule      string
+	apiGroups []string
+	resources []string
+	message   string
+}
+
+var escalationVerbs = []escalationVerb{
+	{
+		verb:      "bind",
+		rule:      RoleGrantsBind,
+		apiGroups: []string{rbacAPIGroup},
+		resources: []string{"roles", "clusterroles"},
+		message:   "The bind verb allows binding roles with permissions which are not granted to the user.",
+	},
+	{
+		verb:      "escalate",
+		rule:      RoleGrantsEscalate,
+		apiGroups: []string{rbacAPIGroup},
+		resources: []string{"roles", "clusterroles"},
+		message:   "The escalate verb allows creating or updating roles with permissions which are not granted to the user.",
+	},
+	{
+		verb:      "impersonate",
+		rule:      RoleGrantsImpersonate,
+		apiGroups: []string{"", authenticationAPIGroup},
+		resources: []string{"users", "groups", "serviceaccounts", "uids", "userextras"},
+		message:   "The impersonate verb allows acting as other users, groups or service accounts and using their permissions.",
+	},
+}
+
+// RBAC implements Auditable
+type RBAC struct{}
+
+func New() *RBAC {
+	return &RBAC{}
+}
+
+// Audit checks that Roles and ClusterRoles do not grant verbs which allow privilege escalation, that ClusterRole
+// aggregation rules do not aggregate permissions from arbitrary or escalating ClusterRoles, and that the service
+// accounts of workloads are not bound to dangerous roles
+func (a *RBAC) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResults []*kubeaudit.AuditResult
+	overrideLabel := OverrideLabel
+
+	switch kubeType := resource.(type) {
+	case *k8s.RoleV1:
+		if isBootstrapRole(kubeType) {
+			return nil, nil
+		}
+		auditResults = auditRules(kubeType.Rules)
+	case *k8s.ClusterRoleV1:
+		if isBootstrapRole(kubeType) {
+			return nil, nil
+		}
+		auditResults = append(auditRules(kubeType.Rules), auditAggregationRule(kubeType, resources)...)
+	default:
+		if k8s.GetPodSpec(resource) == nil {
+			return nil, nil
+		}
+		auditResults = auditServiceAccount(resource, resources)
+		overrideLabel = ServiceAccountOverrideLabel
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, overrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, overrideLabel)
+	}
+	return auditResults, nil
+}
+
+func auditRules(rules []k8s.PolicyRuleV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	for _, escalation := range escalationVerbs {
+		for _, rule := range rules {
+			if !grantsVerb(rule, escalation) {
+				continue
+			}
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     escalation.rule,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("Role grants the %s verb. %s", escalation.verb, escalation.message),
+				Metadata: kubeaudit.Metadata{
+					"Verbs":     strings.Join(rule.Verbs, ","),
+					"APIGroups": strings.Join(rule.APIGroups, ","),
+					"Resources": strings.Join(rule.Resources, ","),
+				},
+			})
+			break
+		}
+	}
+
+	return auditResults
+}
+
+func auditAggregationRule(clusterRole *k8s.ClusterRoleV1, resources []k8s.Resource) []*kubeaudit.AuditResult {
+	if clusterRole.AggregationRule == nil {
+		return nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	var selectors []labels.Selector
+
+	for i := range clusterRole.AggregationRule.ClusterRoleSelectors {
+		labelSelector := &clusterRole.AggregationRule.ClusterRoleSelectors[i]
+		if len(labelSelector.MatchLabels) == 0 && len(labelSelector.MatchExpressions) == 0 {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     AggregationRuleSelectsAllClusterRoles,
+				Severity: kubeaudit.Error,
+				Message:  "ClusterRole aggregation rule has an empty selector so the permissions of every ClusterRole, including any created later, are aggregated into it.",
+			})
+			continue
+		}
+
+		selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+		if err != nil {
+			continue
+		}
+		selectors = append(selectors, selector)
+	}
+
+	for _, resource := range resources {
+		aggregated, ok := resource.(*k8s.ClusterRoleV1)
+		if !ok || aggregated.Name == clusterRole.Name || !matchesAny(selectors, aggregated.Labels) {
+			continue
+		}
+
+		for _, auditResult := range auditRules(aggregated.Rules) {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     AggregationRuleSelectsEscalatingClusterRole,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("ClusterRole aggregates the permissions of ClusterRole %s, which grants escalation verbs. Anyone bound to this ClusterRole is granted them too.", aggregated.Name),
+				Metadata: kubeaudit.Metadata{
+					"AggregatedClusterRole": aggregated.Name,
+					"AggregatedRule":        auditResult.Rule,
+				},
+			})
+		}
+	}
+
+	return auditResults
+}
+
+// grantsVerb returns true if the policy rule grants the verb on one of the verb's resources. Wildcards in the rule
+// are taken into account
+func grantsVerb(rule k8s.PolicyRuleV1, escalation escalationVerb) bool {
+	return containsAny(rule.Verbs, escalation.verb) &&
+		containsAny(rule.APIGroups, escalation.apiGroups...) &&
+		containsAny(rule.Resources, escalation.resources...)
+}
+
+// containsAny returns true if the list contains the wildcard or any of the values
+func containsAny(list []string, values ...string) bool {
+	for _, item := range list {
+		if item == wildcard {
+			return true
+		}
+		for _, value := range values {
+			if item == value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesAny(selectors []labels.Selector, roleLabels map[string]string) bool {
+	for _, selector := range selectors {
+		if selector.Matches(labels.Set(roleLabels)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBootstrapRole(resource k8s.Resource) bool {
+	objectMeta := k8s.GetObjectMeta(resource)
+	return objectMeta != nil && objectMeta.GetLabels()[bootstrappingLabel] == "rbac-defaults"
+}
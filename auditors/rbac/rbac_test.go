@@ -0,0 +1,75 @@
+package rbac
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditRBAC(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"role-grants-escalation-verbs.yml", []string{RoleGrantsBind, RoleGrantsEscalate, RoleGrantsImpersonate}},
+		{"role-grants-wildcard.yml", []string{RoleGrantsBind, RoleGrantsEscalate, RoleGrantsImpersonate}},
+		{"role-grants-no-escalation-verbs.yml", nil},
+		{"role-bootstrap.yml", nil},
+		{"aggregation-rule-selects-all.yml", []string{AggregationRuleSelectsAllClusterRoles}},
+		{"aggregation-rule-selects-escalating.yml", []string{AggregationRuleSelectsEscalatingClusterRole, RoleGrantsImpersonate}},
+		{"role-grants-escalation-verbs-allowed.yml", []string{override.GetOverriddenResultName(RoleGrantsBind)}},
+		{"role-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+		{"service-account-cluster-admin.yml", []string{ServiceAccountBoundToClusterAdmin}},
+		{"service-account-group-cluster-admin.yml", []string{ServiceAccountBoundToClusterAdmin}},
+		{"service-account-wildcard.yml", []string{ServiceAccountGrantedWildcard}},
+		{"service-account-secrets-all-namespaces.yml", []string{ServiceAccountCanReadSecretsInAllNamespaces}},
+		{"service-account-secrets-namespace.yml", nil},
+		{"service-account-other-namespace.yml", nil},
+		{"service-account-cluster-admin-allowed.yml", []string{override.GetOverriddenResultName(ServiceAccountBoundToClusterAdmin)}},
+		{"service-account-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, New(), tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditRBACAggregatedClusterRole(t *testing.T) {
+	report := test.GetReport(t, fixtureDir, "aggregation-rule-selects-escalating.yml", []kubeaudit.Auditable{New()}, "", test.MANIFEST_MODE)
+
+	var aggregated []string
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			if auditResult.Rule == AggregationRuleSelectsEscalatingClusterRole {
+				aggregated = append(aggregated, auditResult.Metadata["AggregatedClusterRole"])
+			}
+		}
+	}
+	assert.Equal(t, []string{"monitoring-impersonate"}, aggregated)
+}
+
+func TestAuditRBACServiceAccountBinding(t *testing.T) {
+	report := test.GetReport(t, fixtureDir, "service-account-secrets-all-namespaces.yml", []kubeaudit.Auditable{New()}, "", test.MANIFEST_MODE)
+
+	var metadata []kubeaudit.Metadata
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			metadata = append(metadata, auditResult.Metadata)
+		}
+	}
+	assert.Equal(t, []kubeaudit.Metadata{{
+		"ServiceAccount": "secret-reader",
+		"Binding":        "ClusterRoleBinding/secret-reader",
+		"Role":           "ClusterRole/secret-reader",
+	}}, metadata)
+}
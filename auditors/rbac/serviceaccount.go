@@ -0,0 +1,216 @@
+package rbac
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+const (
+	clusterAdminRole        = "cluster-admin"
+	defaultNamespace        = "default"
+	defaultServiceAccount   = "default"
+	serviceAccountKind      = "ServiceAccount"
+	groupKind               = "Group"
+	clusterRoleKind         = "ClusterRole"
+	roleKind                = "Role"
+	roleBindingKind         = "RoleBinding"
+	clusterRoleBindingKind  = "ClusterRoleBinding"
+	allServiceAccountsGroup = "system:serviceaccounts"
+)
+
+// secretsReadVerbs are the verbs which return the contents of secrets
+var secretsReadVerbs = []string{"get", "list", "watch"}
+
+// roleBinding is a RoleBinding or ClusterRoleBinding. The namespace of a ClusterRoleBinding is empty
+type roleBinding struct {
+	kind      string
+	name      string
+	namespace string
+	subjects  []k8s.SubjectV1
+	roleRef   k8s.RoleRefV1
+}
+
+// auditServiceAccount checks that the service account of a workload is not bound to roles which give it control of
+// the cluster or namespace. Bindings and roles are looked up in the other resources, so in manifest mode only the
+// bindings in the same manifest are taken into account
+func auditServiceAccount(resource k8s.Resource, resources []k8s.Resource) []*kubeaudit.AuditResult {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return nil
+	}
+
+	namespace := defaultNamespace
+	if objectMeta := k8s.GetObjectMeta(resource); objectMeta != nil {
+		namespace = namespaceOrDefault(objectMeta.GetNamespace())
+	}
+	serviceAccount := getServiceAccountName(podSpec)
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, binding := range getRoleBindings(resources) {
+		if binding.namespace != "" && binding.namespace != namespace {
+			continue
+		}
+		if !binding.bindsServiceAccount(serviceAccount, namespace) {
+			continue
+		}
+		auditResults = append(auditResults, auditBinding(binding, serviceAccount, resources)...)
+	}
+	return auditResults
+}
+
+func auditBinding(binding roleBinding, serviceAccount string, resources []k8s.Resource) []*kubeaudit.AuditResult {
+	metadata := kubeaudit.Metadata{
+		"ServiceAccount": serviceAccount,
+		"Binding":        fmt.Sprintf("%s/%s", binding.kind, binding.name),
+		"Role":           fmt.Sprintf("%s/%s", binding.roleRef.Kind, binding.roleRef.Name),
+	}
+
+	// cluster-admin is created by the kube-apiserver so it is usually not in the manifest
+	if binding.roleRef.Kind == clusterRoleKind && binding.roleRef.Name == clusterAdminRole {
+		scope := "the whole cluster"
+		if binding.namespace != "" {
+			scope = fmt.Sprintf("the namespace %s", binding.namespace)
+		}
+		return []*kubeaudit.AuditResult{{
+			Auditor:  Name,
+			Rule:     ServiceAccountBoundToClusterAdmin,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("ServiceAccount %s is bound to the cluster-admin ClusterRole by %s %s. Anyone who can run code in the workload has full control of %s.", serviceAccount, binding.kind, binding.name, scope),
+			Metadata: metadata,
+		}}
+	}
+
+	rules, ok := getRoleRules(binding, resources)
+	if !ok {
+		return nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, rule := range rules {
+		if containsWildcard(rule.Verbs) || containsWildcard(rule.Resources) {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     ServiceAccountGrantedWildcard,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("ServiceAccount %s is granted wildcard verbs or resources by %s %s. Wildcards grant every current and future permission, the service account should only be granted the verbs and resources it uses.", serviceAccount, binding.kind, binding.name),
+				Metadata: metadata,
+			})
+			break
+		}
+	}
+
+	// A RoleBinding to a ClusterRole only grants its permissions in the namespace of the RoleBinding
+	if binding.namespace != "" {
+		return auditResults
+	}
+	for _, rule := range rules {
+		if len(rule.ResourceNames) == 0 && containsAny(rule.Verbs, secretsReadVerbs...) &&
+			containsAny(rule.APIGroups, "") && containsAny(rule.Resources, "secrets") {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     ServiceAccountCanReadSecretsInAllNamespaces,
+				Severity: kubeaudit.Error,
+				Message:  fmt.Sprintf("ServiceAccount %s can read secrets in all namespaces through ClusterRoleBinding %s, including the tokens of other service accounts. Secrets should be read with a RoleBinding in the namespaces which need them.", serviceAccount, binding.name),
+				Metadata: metadata,
+			})
+			break
+		}
+	}
+
+	return auditResults
+}
+
+// bindsServiceAccount returns true if one of the subjects of the binding is the service account or a group it belongs to
+func (binding roleBinding) bindsServiceAccount(serviceAccount, namespace string) bool {
+	for _, subject := range binding.subjects {
+		switch subject.Kind {
+		case serviceAccountKind:
+			// The namespace of a service account subject is required, but the namespace of the RoleBinding is
+			// commonly used for it in manifests
+			subjectNamespace := subject.Namespace
+			if subjectNamespace == "" {
+				subjectNamespace = namespaceOrDefault(binding.namespace)
+			}
+			if subject.Name == serviceAccount && subjectNamespace == namespace {
+				return true
+			}
+		case groupKind:
+			if subject.Name == allServiceAccountsGroup || subject.Name == allServiceAccountsGroup+":"+namespace {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getRoleRules returns the rules of the role referenced by the binding, if it is one of the resources
+func getRoleRules(binding roleBinding, resources []k8s.Resource) ([]k8s.PolicyRuleV1, bool) {
+	for _, resource := range resources {
+		switch kubeType := resource.(type) {
+		case *k8s.ClusterRoleV1:
+			if binding.roleRef.Kind == clusterRoleKind && kubeType.Name == binding.roleRef.Name {
+				return kubeType.Rules, true
+			}
+		case *k8s.RoleV1:
+			if binding.roleRef.Kind == roleKind && kubeType.Name == binding.roleRef.Name &&
+				namespaceOrDefault(kubeType.Namespace) == binding.namespace {
+				return kubeType.Rules, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func getRoleBindings(resources []k8s.Resource) []roleBinding {
+	var bindings []roleBinding
+	for _, resource := range resources {
+		switch kubeType := resource.(type) {
+		case *k8s.RoleBindingV1:
+			bindings = append(bindings, roleBinding{
+				kind:      roleBindingKind,
+				name:      kubeType.Name,
+				namespace: namespaceOrDefault(kubeType.Namespace),
+				subjects:  kubeType.Subjects,
+				roleRef:   kubeType.RoleRef,
+			})
+		case *k8s.ClusterRoleBindingV1:
+			bindings = append(bindings, roleBinding{
+				kind:     clusterRoleBindingKind,
+				name:     kubeType.Name,
+				subjects: kubeType.Subjects,
+				roleRef:  kubeType.RoleRef,
+			})
+		}
+	}
+	return bindings
+}
+
+// getServiceAccountName returns the name of the service account the pods run as, which is the default service
+// account of the namespace if none is set
+func getServiceAccountName(podSpec *k8s.PodSpecV1) string {
+	if podSpec.ServiceAccountName != "" {
+		return podSpec.ServiceAccountName
+	}
+	if podSpec.DeprecatedServiceAccount != "" {
+		return podSpec.DeprecatedServiceAccount
+	}
+	return defaultServiceAccount
+}
+
+func namespaceOrDefault(namespace string) string {
+	if namespace == "" {
+		return defaultNamespace
+	}
+	return namespace
+}
+
+func containsWildcard(list []string) bool {
+	for _, item := range list {
+		if item == wildcard {
+			return true
+		}
+	}
+	return false
+}
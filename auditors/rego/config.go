@@ -0,0 +1,46 @@
+package rego
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+)
+
+// defaultOPA is the OPA executable which evaluates the policies, looked up in the PATH
+const defaultOPA = "opa"
+
+type Config struct {
+	// PolicyDir is the directory of the Rego policies, which is searched recursively. If it is not set, the 'rego'
+	// auditor produces no results
+	PolicyDir string `yaml:"policyDir"`
+	// Severity is the severity of the results of policies which don't set one, one of "error", "warning", "info" or a
+	// custom severity. Defaults to "error"
+	Severity string `yaml:"severity"`
+	// OPA is the path of the OPA executable which evaluates the policies. Defaults to "opa" in the PATH
+	OPA string `yaml:"opa"`
+}
+
+func (c *Config) GetPolicyDir() string {
+	if c == nil {
+		return ""
+	}
+	return c.PolicyDir
+}
+
+func (c *Config) GetSeverity() (kubeaudit.SeverityLevel, error) {
+	if c == nil || c.Severity == "" {
+		return kubeaudit.Error, nil
+	}
+	severity, err := kubeaudit.ParseSeverity(c.Severity)
+	if err != nil {
+		return 0, fmt.Errorf("invalid severity: %w", err)
+	}
+	return severity, nil
+}
+
+func (c *Config) GetOPA() string {
+	if c == nil || c.OPA == "" {
+		return defaultOPA
+	}
+	return c.OPA
+}
@@ -0,0 +1,352 @@
+package rego
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const Name = "rego"
+
+const OverrideLabel = "allow-rego-policy-violation"
+
+// timeout is the time OPA has to evaluate the policies for the resources of an audit
+const timeout = 2 * time.Minute
+
+// ruleNames are the names of the rules of policies which hold their findings. deny is the convention of conftest and
+// violation the convention of Gatekeeper
+var ruleNames = []string{"deny", "violation"}
+
+// packagePattern matches the package declaration of a Rego file
+var packagePattern = regexp.MustCompile(`(?m)^\s*package\s+([A-Za-z_][\w.]*)`)
+
+// Rego implements Auditable
+type Rego struct {
+	policyDir string
+	// packages are the packages of the policies, eg. "kubeaudit.images"
+	packages []string
+	severity kubeaudit.SeverityLevel
+	opa      string
+
+	mu sync.Mutex
+	// batch is the evaluation of the policies for the resources of the last audit
+	batch *batch
+}
+
+// batch is the evaluation of the policies for all the resources of an audit, which is run once, so OPA is started
+// once per audit rather than once per resource
+type batch struct {
+	resources []k8s.Resource
+	once      sync.Once
+	values    map[k8s.Resource][][]json.RawMessage
+	err       error
+}
+
+// New returns an auditor which evaluates the Rego policies of the policy directory with OPA. The policies are checked
+// for errors when the auditor is created
+func New(config Config) (*Rego, error) {
+	severity, err := config.GetSeverity()
+	if err != nil {
+		return nil, fmt.Errorf("error creating rego auditor: %w", err)
+	}
+
+	policyDir := config.GetPolicyDir()
+	if policyDir == "" {
+		return &Rego{severity: severity}, nil
+	}
+
+	opa, err := exec.LookPath(config.GetOPA())
+	if err != nil {
+		return nil, fmt.Errorf("error creating rego auditor: OPA is needed to evaluate Rego policies: %w", err)
+	}
+
+	packages, err := findPackages(policyDir)
+	if err != nil {
+		return nil, fmt.Errorf("error creating rego auditor: %w", err)
+	}
+	if len(packages) == 0 {
+		return nil, fmt.Errorf("error creating rego auditor: no Rego policies in %s", policyDir)
+	}
+
+	if output, err := exec.Command(opa, "check", policyDir).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("error creating rego auditor: invalid Rego policies in %s: %s", policyDir, strings.TrimSpace(string(output)))
+	}
+
+	return &Rego{
+		policyDir: policyDir,
+		packages:  packages,
+		severity:  severity,
+		opa:       opa,
+	}, nil
+}
+
+// findPackages returns the packages of the Rego files in the directory, except tests
+func findPackages(dir string) ([]string, error) {
+	packages := map[string]bool{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".rego" || strings.HasSuffix(path, "_test.rego") {
+			return nil
+		}
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if match := packagePattern.FindSubmatch(source); match != nil {
+			packages[string(match[1])] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sorted := make([]string, 0, len(packages))
+	for pkg := range packages {
+		sorted = append(sorted, pkg)
+	}
+	sort.Strings(sorted)
+	return sorted, nil
+}
+
+// Audit evaluates the policies with the resource as their input, and reports each result of their deny and violation
+// rules. The policies are evaluated for all the resources of the audit the first time one of them is audited
+func (a *Rego) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	if a.policyDir == "" {
+		return nil, nil
+	}
+
+	values, err := a.evalResource(resource, resources)
+	if err != nil {
+		return nil, err
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	for i, pkg := range a.packages {
+		for j := range ruleNames {
+			index := i*len(ruleNames) + j
+			if index >= len(values) {
+				break
+			}
+			for _, value := range values[index] {
+				auditResult, err := a.newAuditResult(pkg, value)
+				if err != nil {
+					return nil, err
+				}
+				auditResults = append(auditResults, auditResult)
+			}
+		}
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, OverrideLabel)
+	}
+	return auditResults, nil
+}
+
+// query returns a query of the results of the deny and violation rules of each package, as an array with the results
+// of each rule in the order of the packages. Rules which are not defined have no results
+func (a *Rego) query() string {
+	rules := make([]string, 0, len(a.packages)*len(ruleNames))
+	for _, pkg := range a.packages {
+		for _, ruleName := range ruleNames {
+			rules = append(rules, fmt.Sprintf("object.get(data.%s, %q, [])", pkg, ruleName))
+		}
+	}
+	return "[" + strings.Join(rules, ", ") + "]"
+}
+
+// batchQuery returns a query of the results of query for each resource of the input, which is an array of resources,
+// as an object with the results of each resource by its index
+func (a *Rego) batchQuery() string {
+	return "{i: r | x := input[i]; r := " + a.query() + " with input as x}"
+}
+
+// evalResource returns the results of each rule for the resource. The results of the resources of an audit are
+// evaluated together the first time, and resources which are not part of the audit are evaluated on their own
+func (a *Rego) evalResource(resource k8s.Resource, resources []k8s.Resource) ([][]json.RawMessage, error) {
+	if len(resources) == 0 {
+		return a.evalOnce(resource)
+	}
+
+	a.mu.Lock()
+	b := a.batch
+	if b == nil || len(b.resources) != len(resources) || &b.resources[0] != &resources[0] {
+		b = &batch{resources: resources}
+		a.batch = b
+	}
+	a.mu.Unlock()
+
+	b.once.Do(func() {
+		b.values, b.err = a.eval(b.resources)
+	})
+	if b.err != nil {
+		return nil, b.err
+	}
+	if values, ok := b.values[resource]; ok {
+		return values, nil
+	}
+	return a.evalOnce(resource)
+}
+
+// evalOnce evaluates the policies for a single resource
+func (a *Rego) evalOnce(resource k8s.Resource) ([][]json.RawMessage, error) {
+	values, err := a.eval([]k8s.Resource{resource})
+	if err != nil {
+		return nil, err
+	}
+	return values[resource], nil
+}
+
+// eval evaluates the policies for the resources with a single run of OPA and returns the results of each rule for each
+// resource
+func (a *Rego) eval(resources []k8s.Resource) (map[k8s.Resource][][]json.RawMessage, error) {
+	inputs := make([]json.RawMessage, 0, len(resources))
+	for _, resource := range resources {
+		obj, err := k8sinternal.ToUnstructured(resource)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding resource for Rego policies: %w", err)
+		}
+		input, err := obj.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("error encoding resource for Rego policies: %w", err)
+		}
+		inputs = append(inputs, input)
+	}
+	input, err := json.Marshal(inputs)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding resources for Rego policies: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, a.opa, "eval", "--format", "json", "--data", a.policyDir, "--stdin-input", a.batchQuery())
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("Rego policies were not evaluated within %s", timeout)
+		}
+		return nil, fmt.Errorf("error evaluating Rego policies: %w: %s", err, strings.TrimSpace(stderr.String()+stdout.String()))
+	}
+
+	var output struct {
+		Result []struct {
+			Expressions []struct {
+				Value map[string][][]json.RawMessage `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("invalid output of OPA: %w", err)
+	}
+
+	// Resources without results are kept, so they aren't evaluated again
+	values := make(map[k8s.Resource][][]json.RawMessage, len(resources))
+	for _, resource := range resources {
+		values[resource] = nil
+	}
+	if len(output.Result) == 0 || len(output.Result[0].Expressions) == 0 {
+		return values, nil
+	}
+	for key, value := range output.Result[0].Expressions[0].Value {
+		i, err := strconv.Atoi(key)
+		if err != nil || i < 0 || i >= len(resources) {
+			return nil, fmt.Errorf("invalid output of OPA: unexpected resource %q", key)
+		}
+		values[resources[i]] = value
+	}
+	return values, nil
+}
+
+// violation is a result of a policy which is an object. The message can also be the whole result
+type violation struct {
+	Msg      string                     `json:"msg"`
+	Message  string                     `json:"message"`
+	Rule     string                     `json:"rule"`
+	Severity string                     `json:"severity"`
+	Details  map[string]json.RawMessage `json:"details"`
+}
+
+func (a *Rego) newAuditResult(pkg string, value json.RawMessage) (*kubeaudit.AuditResult, error) {
+	auditResult := &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     getRuleName(pkg),
+		Severity: a.severity,
+		Metadata: kubeaudit.Metadata{"Policy": pkg},
+	}
+
+	var message string
+	if err := json.Unmarshal(value, &message); err == nil {
+		auditResult.Message = message
+		return auditResult, nil
+	}
+
+	var v violation
+	if err := json.Unmarshal(value, &v); err != nil {
+		auditResult.Message = string(value)
+		return auditResult, nil
+	}
+
+	auditResult.Message = v.Msg
+	if auditResult.Message == "" {
+		auditResult.Message = v.Message
+	}
+	if v.Rule != "" {
+		auditResult.Rule = v.Rule
+	}
+	if v.Severity != "" {
+		severity, err := kubeaudit.ParseSeverity(v.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid severity of Rego policy %s: %w", pkg, err)
+		}
+		auditResult.Severity = severity
+	}
+	for key, detail := range v.Details {
+		var s string
+		if err := json.Unmarshal(detail, &s); err != nil {
+			s = string(detail)
+		}
+		auditResult.Metadata[key] = s
+	}
+	return auditResult, nil
+}
+
+// getRuleName returns the rule of the results of a package which don't set one, which is the last part of the package
+// in CamelCase, eg. "RequiredLabels" for "kubeaudit.required_labels"
+func getRuleName(pkg string) string {
+	name := pkg[strings.LastIndex(pkg, ".")+1:]
+	var rule strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		rule.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return rule.String()
+}
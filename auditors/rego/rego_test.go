@@ -0,0 +1,124 @@
+package rego
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+var policyDir = filepath.Join("testdata", "policies")
+
+// writeOPA writes a fake OPA executable, which writes the output to OPA eval and appends the arguments of each run of
+// OPA eval to the file "args" in its directory
+func writeOPA(t *testing.T, output string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake OPA is a shell script")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "opa")
+	script := `#!/bin/sh
+case "$1" in
+check) exit 0 ;;
+eval) echo "$@" >> "$(dirname "$0")/args"; cat > /dev/null; echo '` + output + `' ;;
+esac
+`
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func TestAuditRego(t *testing.T) {
+	opa := writeOPA(t, `{"result":[{"expressions":[{"value":{"0":[[],[{"msg":"Pod pod has no cost-center label.","rule":"CostCenterMissing","severity":"warning","details":{"Label":"cost-center","Required":true}}],["Container container uses the latest tag."],[]]}}]}]}`)
+
+	auditor, err := New(Config{PolicyDir: policyDir, OPA: opa})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kubeaudit.labels", "kubeaudit.latest_tag"}, auditor.packages)
+
+	report := test.AuditManifest(t, fixtureDir, "pod.yml", auditor, []string{"CostCenterMissing", "LatestTag"})
+	auditResults := report.Results()[0].GetAuditResults()
+	require.Len(t, auditResults, 2)
+
+	assert.Equal(t, kubeaudit.Warn, auditResults[0].Severity)
+	assert.Equal(t, "Pod pod has no cost-center label.", auditResults[0].Message)
+	assert.Equal(t, kubeaudit.Metadata{"Policy": "kubeaudit.labels", "Label": "cost-center", "Required": "true"}, auditResults[0].Metadata)
+	assert.Equal(t, kubeaudit.Error, auditResults[1].Severity)
+	assert.Equal(t, "Container container uses the latest tag.", auditResults[1].Message)
+
+	args, err := os.ReadFile(filepath.Join(filepath.Dir(opa), "args"))
+	require.NoError(t, err)
+	assert.Equal(t, "eval --format json --data "+policyDir+" --stdin-input "+auditor.batchQuery(), strings.TrimSpace(string(args)))
+	assert.Equal(t, `[object.get(data.kubeaudit.labels, "deny", []), object.get(data.kubeaudit.labels, "violation", []), `+
+		`object.get(data.kubeaudit.latest_tag, "deny", []), object.get(data.kubeaudit.latest_tag, "violation", [])]`, auditor.query())
+}
+
+func TestAuditRegoSeverity(t *testing.T) {
+	opa := writeOPA(t, `{"result":[{"expressions":[{"value":{"0":[[],[],["Container container uses the latest tag."],[]]}}]}]}`)
+
+	auditor, err := New(Config{PolicyDir: policyDir, OPA: opa, Severity: "info"})
+	require.NoError(t, err)
+	report := test.AuditManifest(t, fixtureDir, "pod.yml", auditor, []string{"LatestTag"})
+	assert.Equal(t, kubeaudit.Info, report.Results()[0].GetAuditResults()[0].Severity)
+
+	// Results are overridden like the results of the other auditors
+	test.AuditManifest(t, fixtureDir, "pod-allowed.yml", auditor, []string{override.GetOverriddenResultName("LatestTag")})
+
+	// Policies without results have an empty result
+	opa = writeOPA(t, `{}`)
+	auditor, err = New(Config{PolicyDir: policyDir, OPA: opa})
+	require.NoError(t, err)
+	test.AuditManifest(t, fixtureDir, "pod.yml", auditor, []string{})
+}
+
+func TestAuditRegoBatch(t *testing.T) {
+	opa := writeOPA(t, `{"result":[{"expressions":[{"value":{"1":[[],[],["Container container uses the latest tag."],[]]}}]}]}`)
+
+	auditor, err := New(Config{PolicyDir: policyDir, OPA: opa})
+	require.NoError(t, err)
+	resources := []k8s.Resource{k8s.NewPod(), k8s.NewPod()}
+
+	// OPA is run once for all the resources of the audit
+	auditResults, err := auditor.Audit(resources[0], resources)
+	require.NoError(t, err)
+	assert.Empty(t, auditResults)
+	auditResults, err = auditor.Audit(resources[1], resources)
+	require.NoError(t, err)
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, "Container container uses the latest tag.", auditResults[0].Message)
+
+	args, err := os.ReadFile(filepath.Join(filepath.Dir(opa), "args"))
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(args)), "\n"), 1)
+	assert.Equal(t, `{i: r | x := input[i]; r := `+auditor.query()+` with input as x}`, auditor.batchQuery())
+}
+
+func TestNewRego(t *testing.T) {
+	// Without policies the auditor produces no results, and doesn't need OPA
+	auditor, err := New(Config{OPA: "missing-opa"})
+	require.NoError(t, err)
+	test.AuditManifest(t, fixtureDir, "pod.yml", auditor, []string{})
+
+	_, err = New(Config{PolicyDir: policyDir, OPA: "missing-opa"})
+	assert.ErrorContains(t, err, "OPA is needed to evaluate Rego policies")
+
+	_, err = New(Config{PolicyDir: policyDir, Severity: "fatal"})
+	assert.ErrorContains(t, err, "invalid severity")
+
+	_, err = New(Config{PolicyDir: t.TempDir(), OPA: writeOPA(t, "")})
+	assert.ErrorContains(t, err, "no Rego policies")
+}
+
+func TestGetRuleName(t *testing.T) {
+	assert.Equal(t, "RequiredLabels", getRuleName("kubeaudit.required_labels"))
+	assert.Equal(t, "Main", getRuleName("main"))
+	assert.Equal(t, "NoLatest", getRuleName("policies.no-latest"))
+}
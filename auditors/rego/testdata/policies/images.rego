@@ -0,0 +1,9 @@
+package kubeaudit.latest_tag
+
+import rego.v1
+
+deny contains msg if {
+	some container in input.spec.containers
+	endswith(container.image, ":latest")
+	msg := sprintf("Container %s uses the latest tag.", [container.name])
+}
@@ -0,0 +1,8 @@
+package kubeaudit.labels
+
+import rego.v1
+
+violation contains {"msg": msg, "rule": "CostCenterMissing", "severity": "warning", "details": {"Label": "cost-center"}} if {
+	not input.metadata.labels["cost-center"]
+	msg := sprintf("%s %s has no cost-center label.", [input.kind, input.metadata.name])
+}
@@ -0,0 +1,9 @@
+package kubeaudit.labels_test
+
+import rego.v1
+
+import data.kubeaudit.labels
+
+test_cost_center_missing if {
+	count(labels.violation) == 1 with input as {"kind": "Pod", "metadata": {"name": "pod"}}
+}
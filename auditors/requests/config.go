@@ -0,0 +1,60 @@
+package requests
+
+import (
+	"fmt"
+
+	k8sResource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// DefaultCPU is the CPU request set by the autofix if the config doesn't set one
+	DefaultCPU = "100m"
+	// DefaultMemory is the memory request set by the autofix if the config doesn't set one
+	DefaultMemory = "128Mi"
+	// DefaultMaxLimitRatio is the highest ratio of a limit to its request if the config doesn't set one
+	DefaultMaxLimitRatio = 10
+)
+
+type Config struct {
+	// CPU is the CPU request the autofix sets on containers which don't have one
+	CPU string `yaml:"cpu"`
+	// Memory is the memory request the autofix sets on containers which don't have one
+	Memory string `yaml:"memory"`
+	// MaxLimitRatio is the highest ratio of the CPU or memory limit of a container to its request. A container whose
+	// limit is far above its request is scheduled on nodes which can't provide the limit
+	MaxLimitRatio float64 `yaml:"maxLimitRatio"`
+}
+
+func (config *Config) GetCPU() (k8sResource.Quantity, error) {
+	cpuArg := DefaultCPU
+	if config != nil && config.CPU != "" {
+		cpuArg = config.CPU
+	}
+	cpu, err := k8sResource.ParseQuantity(cpuArg)
+	if err != nil {
+		return cpu, fmt.Errorf("error parsing default CPU request: %w", err)
+	}
+	return cpu, nil
+}
+
+func (config *Config) GetMemory() (k8sResource.Quantity, error) {
+	memoryArg := DefaultMemory
+	if config != nil && config.Memory != "" {
+		memoryArg = config.Memory
+	}
+	memory, err := k8sResource.ParseQuantity(memoryArg)
+	if err != nil {
+		return memory, fmt.Errorf("error parsing default memory request: %w", err)
+	}
+	return memory, nil
+}
+
+func (config *Config) GetMaxLimitRatio() (float64, error) {
+	if config == nil || config.MaxLimitRatio == 0 {
+		return DefaultMaxLimitRatio, nil
+	}
+	if config.MaxLimitRatio < 1 {
+		return 0, fmt.Errorf("invalid max limit ratio %v, it must be at least 1", config.MaxLimitRatio)
+	}
+	return config.MaxLimitRatio, nil
+}
@@ -0,0 +1,34 @@
+package requests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	v1 "k8s.io/api/core/v1"
+)
+
+type fixBySettingRequests struct {
+	container *k8s.ContainerV1
+	requests  v1.ResourceList
+}
+
+func (f *fixBySettingRequests) Plan() string {
+	requests := make([]string, 0, len(f.requests))
+	for resourceName, quantity := range f.requests {
+		requests = append(requests, fmt.Sprintf("%s: %s", resourceName, quantity.String()))
+	}
+	sort.Strings(requests)
+	return fmt.Sprintf("Set the requests of container %s to %s", f.container.Name, strings.Join(requests, ", "))
+}
+
+func (f *fixBySettingRequests) Apply(resource k8s.Resource) []k8s.Resource {
+	if f.container.Resources.Requests == nil {
+		f.container.Resources.Requests = v1.ResourceList{}
+	}
+	for resourceName, quantity := range f.requests {
+		f.container.Resources.Requests[resourceName] = quantity
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package requests
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	k8sResource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestFixRequests(t *testing.T) {
+	cases := []struct {
+		file           string
+		config         Config
+		expectedCPU    string
+		expectedMemory string
+	}{
+		{"requests-nil.yml", Config{}, DefaultCPU, DefaultMemory},
+		{"requests-nil.yml", Config{CPU: "250m", Memory: "64Mi"}, "250m", "64Mi"},
+		{"requests-nil-limits-set.yml", Config{CPU: "1", Memory: "1Gi"}, "750m", "512Mi"},
+		{"requests-no-cpu.yml", Config{}, DefaultCPU, "256Mi"},
+		{"requests-no-memory.yml", Config{}, "500m", DefaultMemory},
+		{"requests-exceed-limits.yml", Config{}, "500m", "256Mi"},
+		{"requests-ratio-exceeded.yml", Config{}, "100m", "128Mi"},
+		{"requests-allowed.yml", Config{}, "0", "0"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			auditor, err := New(tc.config)
+			require.NoError(t, err)
+			resources, _ := test.FixSetup(t, fixtureDir, tc.file, auditor)
+			for _, resource := range resources {
+				for _, container := range k8s.GetContainers(resource) {
+					assert.Equal(t, tc.expectedCPU, container.Resources.Requests.Cpu().String())
+					assert.Equal(t, tc.expectedMemory, container.Resources.Requests.Memory().String())
+				}
+			}
+		})
+	}
+}
+
+func TestFixPlan(t *testing.T) {
+	fix := &fixBySettingRequests{
+		container: &k8s.ContainerV1{Name: "container"},
+		requests:  v1.ResourceList{v1.ResourceMemory: k8sResource.MustParse("128Mi"), v1.ResourceCPU: k8sResource.MustParse("100m")},
+	}
+	assert.Equal(t, "Set the requests of container container to cpu: 100m, memory: 128Mi", fix.Plan())
+}
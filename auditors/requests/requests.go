@@ -0,0 +1,213 @@
+package requests
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	v1 "k8s.io/api/core/v1"
+	k8sResource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+const Name = "requests"
+
+const (
+	// RequestsNotSet occurs when there are no cpu and memory requests specified for a container
+	RequestsNotSet = "RequestsNotSet"
+	// RequestsCPUNotSet occurs when there is no cpu request specified for a container
+	RequestsCPUNotSet = "RequestsCPUNotSet"
+	// RequestsMemoryNotSet occurs when there is no memory request specified for a container
+	RequestsMemoryNotSet = "RequestsMemoryNotSet"
+	// RequestsCPUExceedsLimit occurs when the cpu request of a container is higher than its cpu limit
+	RequestsCPUExceedsLimit = "RequestsCPUExceedsLimit"
+	// RequestsMemoryExceedsLimit occurs when the memory request of a container is higher than its memory limit
+	RequestsMemoryExceedsLimit = "RequestsMemoryExceedsLimit"
+	// RequestsCPURatioExceeded occurs when the cpu limit of a container is more than the max ratio times its request
+	RequestsCPURatioExceeded = "RequestsCPURatioExceeded"
+	// RequestsMemoryRatioExceeded occurs when the memory limit of a container is more than the max ratio times its
+	// request
+	RequestsMemoryRatioExceeded = "RequestsMemoryRatioExceeded"
+)
+
+const OverrideLabel = "allow-requests-violation"
+
+// Requests implements Auditable
+type Requests struct {
+	defaultRequests v1.ResourceList
+	maxLimitRatio   float64
+}
+
+func New(config Config) (*Requests, error) {
+	cpu, err := config.GetCPU()
+	if err != nil {
+		return nil, fmt.Errorf("error creating Requests auditor: %w", err)
+	}
+
+	memory, err := config.GetMemory()
+	if err != nil {
+		return nil, fmt.Errorf("error creating Requests auditor: %w", err)
+	}
+
+	maxLimitRatio, err := config.GetMaxLimitRatio()
+	if err != nil {
+		return nil, fmt.Errorf("error creating Requests auditor: %w", err)
+	}
+
+	return &Requests{
+		defaultRequests: v1.ResourceList{v1.ResourceCPU: cpu, v1.ResourceMemory: memory},
+		maxLimitRatio:   maxLimitRatio,
+	}, nil
+}
+
+// Audit checks that containers request cpu and memory, and that the requests are consistent with the limits
+func (a *Requests) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResults []*kubeaudit.AuditResult
+
+	for _, container := range k8s.GetContainers(resource) {
+		// Ephemeral containers can't have resources, which are those of the pod
+		if k8s.IsEphemeralContainer(resource, container) {
+			continue
+		}
+		containerResults := a.auditContainer(container)
+		if len(containerResults) == 0 {
+			if auditResult := override.ApplyOverride(nil, Name, container.Name, resource, OverrideLabel); auditResult != nil {
+				auditResults = append(auditResults, auditResult)
+			}
+			continue
+		}
+
+		for _, auditResult := range containerResults {
+			auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, container.Name, resource, OverrideLabel))
+		}
+	}
+
+	return auditResults, nil
+}
+
+func (a *Requests) auditContainer(container *k8s.ContainerV1) []*kubeaudit.AuditResult {
+	if len(container.Resources.Requests) == 0 {
+		return []*kubeaudit.AuditResult{a.auditRequestsNotSet(container)}
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, check := range []struct {
+		resourceName    v1.ResourceName
+		label           string
+		notSetRule      string
+		exceedsRule     string
+		ratioRule       string
+		requestMetadata string
+		limitMetadata   string
+	}{
+		{v1.ResourceCPU, "CPU", RequestsCPUNotSet, RequestsCPUExceedsLimit, RequestsCPURatioExceeded, "ContainerCpuRequest", "ContainerCpuLimit"},
+		{v1.ResourceMemory, "Memory", RequestsMemoryNotSet, RequestsMemoryExceedsLimit, RequestsMemoryRatioExceeded, "ContainerMemoryRequest", "ContainerMemoryLimit"},
+	} {
+		request, hasRequest := getQuantity(container.Resources.Requests, check.resourceName)
+		limit, hasLimit := getQuantity(container.Resources.Limits, check.resourceName)
+
+		switch {
+		case !hasRequest:
+			// The request defaults to the limit, so a container with a limit still reserves the resource
+			severity := kubeaudit.Warn
+			message := fmt.Sprintf("Resource %s request not set. The container is scheduled without reserving any %s, so it competes for it with the other containers of the node.", check.label, check.label)
+			if hasLimit {
+				severity = kubeaudit.Info
+				message = fmt.Sprintf("Resource %s request not set. The %s limit of '%s' is used as the request.", check.label, check.label, limit.String())
+			}
+			requests := v1.ResourceList{check.resourceName: a.defaultRequest(container, check.resourceName)}
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:    Name,
+				Rule:       check.notSetRule,
+				Severity:   severity,
+				Message:    message,
+				PendingFix: &fixBySettingRequests{container: container, requests: requests},
+				Metadata: kubeaudit.Metadata{
+					"Container": container.Name,
+				},
+			})
+		case hasLimit && request.Cmp(limit) > 0:
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:    Name,
+				Rule:       check.exceedsRule,
+				Severity:   kubeaudit.Error,
+				Message:    fmt.Sprintf("Resource %s request exceeds the limit. It is set to '%s' which exceeds the %s limit of '%s', so the API server rejects the container.", check.label, request.String(), check.label, limit.String()),
+				PendingFix: &fixBySettingRequests{container: container, requests: v1.ResourceList{check.resourceName: limit}},
+				Metadata: kubeaudit.Metadata{
+					"Container":           container.Name,
+					check.requestMetadata: request.String(),
+					check.limitMetadata:   limit.String(),
+				},
+			})
+		case hasLimit && a.exceedsRatio(request, limit):
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     check.ratioRule,
+				Severity: kubeaudit.Warn,
+				Message:  fmt.Sprintf("Resource %s limit is too far above the request. The limit of '%s' is more than %v times the request of '%s', so the container is scheduled on nodes which may not be able to provide the limit. The request should be raised or the limit lowered.", check.label, limit.String(), a.maxLimitRatio, request.String()),
+				Metadata: kubeaudit.Metadata{
+					"Container":           container.Name,
+					check.requestMetadata: request.String(),
+					check.limitMetadata:   limit.String(),
+					"MaxLimitRatio":       fmt.Sprintf("%v", a.maxLimitRatio),
+				},
+			})
+		}
+	}
+
+	return auditResults
+}
+
+func (a *Requests) auditRequestsNotSet(container *k8s.ContainerV1) *kubeaudit.AuditResult {
+	severity := kubeaudit.Warn
+	message := "Resource requests not set. The container is scheduled without reserving any CPU or memory, so it is the first to be evicted when the node runs out of memory."
+	_, hasCPULimit := getQuantity(container.Resources.Limits, v1.ResourceCPU)
+	_, hasMemoryLimit := getQuantity(container.Resources.Limits, v1.ResourceMemory)
+	if hasCPULimit && hasMemoryLimit {
+		// The requests default to the limits
+		severity = kubeaudit.Info
+		message = "Resource requests not set. The limits are used as the requests."
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     RequestsNotSet,
+		Severity: severity,
+		Message:  message,
+		PendingFix: &fixBySettingRequests{
+			container: container,
+			requests: v1.ResourceList{
+				v1.ResourceCPU:    a.defaultRequest(container, v1.ResourceCPU),
+				v1.ResourceMemory: a.defaultRequest(container, v1.ResourceMemory),
+			},
+		},
+		Metadata: kubeaudit.Metadata{
+			"Container": container.Name,
+		},
+	}
+}
+
+// defaultRequest returns the configured default request for the resource, lowered to the limit of the container so
+// the fixed container is valid
+func (a *Requests) defaultRequest(container *k8s.ContainerV1, resourceName v1.ResourceName) k8sResource.Quantity {
+	request := a.defaultRequests[resourceName]
+	if limit, ok := getQuantity(container.Resources.Limits, resourceName); ok && request.Cmp(limit) > 0 {
+		return limit
+	}
+	return request
+}
+
+func (a *Requests) exceedsRatio(request, limit k8sResource.Quantity) bool {
+	if request.IsZero() {
+		return true
+	}
+	return limit.AsApproximateFloat64()/request.AsApproximateFloat64() > a.maxLimitRatio
+}
+
+func getQuantity(resources v1.ResourceList, resourceName v1.ResourceName) (k8sResource.Quantity, bool) {
+	quantity, ok := resources[resourceName]
+	if !ok || quantity.IsZero() {
+		return k8sResource.Quantity{}, false
+	}
+	return quantity, true
+}
@@ -0,0 +1,91 @@
+package requests
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditRequests(t *testing.T) {
+	cases := []struct {
+		file           string
+		maxLimitRatio  float64
+		expectedErrors []string
+	}{
+		{"requests-nil.yml", 0, []string{RequestsNotSet}},
+		{"requests-nil-limits-set.yml", 0, []string{RequestsNotSet}},
+		{"requests-no-cpu.yml", 0, []string{RequestsCPUNotSet}},
+		{"requests-no-memory.yml", 0, []string{RequestsMemoryNotSet}},
+		{"requests.yml", 0, []string{}},
+		{"requests.yml", 1.2, []string{RequestsCPURatioExceeded, RequestsMemoryRatioExceeded}},
+		{"requests-exceed-limits.yml", 0, []string{RequestsCPUExceedsLimit, RequestsMemoryExceedsLimit}},
+		{"requests-ratio-exceeded.yml", 0, []string{RequestsCPURatioExceeded, RequestsMemoryRatioExceeded}},
+		{"requests-ratio-exceeded.yml", 20, []string{}},
+		{"requests-allowed.yml", 0, []string{override.GetOverriddenResultName(RequestsNotSet)}},
+		{"requests-redundant-override.yml", 0, []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for i, tc := range cases {
+		// These lines are needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		i := i
+		t.Run(fmt.Sprintf("%s %v", tc.file, tc.maxLimitRatio), func(t *testing.T) {
+			t.Parallel()
+			auditor, err := New(Config{MaxLimitRatio: tc.maxLimitRatio})
+			require.NoError(t, err)
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+			test.AuditLocal(t, fixtureDir, tc.file, auditor, fmt.Sprintf("%s%d", strings.Split(tc.file, ".")[0], i), tc.expectedErrors)
+		})
+	}
+
+	t.Run("Bad arguments", func(t *testing.T) {
+		_, err := New(Config{CPU: "badvalue"})
+		assert.Error(t, err)
+
+		_, err = New(Config{Memory: "badvalue"})
+		assert.Error(t, err)
+
+		_, err = New(Config{MaxLimitRatio: 0.5})
+		assert.Error(t, err)
+	})
+}
+
+func TestAuditRequestsSeverity(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	// Containers without requests use their limits as requests, so they are only reported as info
+	cases := []struct {
+		file             string
+		expectedSeverity kubeaudit.SeverityLevel
+	}{
+		{"requests-nil.yml", kubeaudit.Warn},
+		{"requests-nil-limits-set.yml", kubeaudit.Info},
+	}
+
+	for _, tc := range cases {
+		report := test.AuditManifest(t, fixtureDir, tc.file, auditor, []string{RequestsNotSet})
+		for _, result := range report.Results() {
+			for _, auditResult := range result.GetAuditResults() {
+				assert.Equal(t, tc.expectedSeverity, auditResult.Severity, tc.file)
+			}
+		}
+	}
+}
+
+func TestAuditRequestsSkipsEphemeralContainers(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	// Ephemeral containers can't set resources, so only the container and the init container are reported
+	report := test.AuditManifest(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, auditor, []string{RequestsNotSet})
+	assert.Equal(t, []string{"container", "init-container"}, test.AuditedContainers(report, RequestsNotSet))
+}
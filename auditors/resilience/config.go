@@ -0,0 +1,17 @@
+package resilience
+
+// DefaultProductionNamespaceSelector selects the production namespaces if no selector is configured
+var DefaultProductionNamespaceSelector = map[string]string{"env": "production"}
+
+type Config struct {
+	// ProductionNamespaceSelector selects the namespaces in which single-replica workloads are reported. A namespace
+	// is selected if it has all of the given labels
+	ProductionNamespaceSelector map[string]string `yaml:"productionNamespaceSelector"`
+}
+
+func (c *Config) GetProductionNamespaceSelector() map[string]string {
+	if c == nil || len(c.ProductionNamespaceSelector) == 0 {
+		return DefaultProductionNamespaceSelector
+	}
+	return c.ProductionNamespaceSelector
+}
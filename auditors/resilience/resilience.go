@@ -0,0 +1,288 @@
+package resilience
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const Name = "resilience"
+
+const (
+	// SingleReplicaInProduction occurs when a Deployment or StatefulSet in a production namespace has a single replica
+	SingleReplicaInProduction = "SingleReplicaInProduction"
+	// ReplicasPinnedToSingleNode occurs when all the replicas of a workload can only be scheduled on one node
+	ReplicasPinnedToSingleNode = "ReplicasPinnedToSingleNode"
+	// ReplicasPinnedToSingleZone occurs when all the replicas of a workload can only be scheduled in one zone
+	ReplicasPinnedToSingleZone = "ReplicasPinnedToSingleZone"
+	// TopologySpreadConstraintsMissing occurs when a workload with multiple replicas does not define
+	// topologySpreadConstraints or pod anti-affinity
+	TopologySpreadConstraintsMissing = "TopologySpreadConstraintsMissing"
+	// PodDisruptionBudgetMissing occurs when a workload with multiple replicas is not selected by a PodDisruptionBudget
+	// in its namespace
+	PodDisruptionBudgetMissing = "PodDisruptionBudgetMissing"
+	// LivenessProbeMissing occurs when a container of a workload does not define a liveness probe
+	LivenessProbeMissing = "LivenessProbeMissing"
+	// ReadinessProbeMissing occurs when a container of a workload does not define a readiness probe
+	ReadinessProbeMissing = "ReadinessProbeMissing"
+)
+
+const OverrideLabel = "allow-resilience-risk"
+
+// The node labels which identify a node and a zone. The beta zone label is deprecated but still set by some providers
+var (
+	nodeTopologyKeys = []string{v1.LabelHostname}
+	zoneTopologyKeys = []string{v1.LabelTopologyZone, v1.LabelFailureDomainBetaZone}
+)
+
+// Resilience implements Auditable
+type Resilience struct {
+	productionNamespaceSelector k8slabels.Selector
+}
+
+func New(config Config) (*Resilience, error) {
+	selector, err := k8slabels.ValidatedSelectorFromSet(config.GetProductionNamespaceSelector())
+	if err != nil {
+		return nil, fmt.Errorf("error creating resilience auditor: invalid production namespace selector: %w", err)
+	}
+
+	return &Resilience{productionNamespaceSelector: selector}, nil
+}
+
+// Audit checks that replicated workloads can survive the loss of a node or a zone, that voluntary disruptions can't
+// evict all of their replicas at once, and that their containers are restarted and taken out of service when unhealthy
+func (a *Resilience) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	replicas, ok := getReplicas(resource)
+	if !ok {
+		return nil, nil
+	}
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return nil, nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	if replicas == 1 {
+		if a.isProductionNamespace(resource, resources) {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     SingleReplicaInProduction,
+				Severity: kubeaudit.Warn,
+				Message:  "Workload in a production namespace has a single replica. It is unavailable whenever its pod is restarted or its node fails. The number of replicas should be increased.",
+			})
+		}
+	} else if replicas > 1 {
+		auditResults = append(auditResults, auditPlacement(podSpec)...)
+		if !hasPodDisruptionBudget(resource, resources) {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     PodDisruptionBudgetMissing,
+				Severity: kubeaudit.Warn,
+				Message:  "Workload with multiple replicas is not selected by a PodDisruptionBudget. Node drains and other voluntary disruptions may evict all of its replicas at once. A PodDisruptionBudget selecting its pods should be added.",
+			})
+		}
+	}
+	auditResults = append(auditResults, auditProbes(podSpec)...)
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, auditResults[i].Metadata["Container"], resource, OverrideLabel)
+	}
+	return auditResults, nil
+}
+
+// auditProbes checks that each container has a liveness probe, so it is restarted when it hangs, and a readiness probe,
+// so it doesn't receive traffic before it is ready. Init containers run to completion so they don't need probes
+func auditProbes(podSpec *k8s.PodSpecV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+	for _, container := range podSpec.Containers {
+		if container.LivenessProbe == nil {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     LivenessProbeMissing,
+				Severity: kubeaudit.Warn,
+				Message:  "livenessProbe is not set in the container. The container is not restarted if it hangs without exiting. A liveness probe should be added.",
+				Metadata: kubeaudit.Metadata{
+					"Container": container.Name,
+				},
+			})
+		}
+		if container.ReadinessProbe == nil {
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     ReadinessProbeMissing,
+				Severity: kubeaudit.Warn,
+				Message:  "readinessProbe is not set in the container. The pod receives traffic as soon as it starts, before it is ready, and while it is unhealthy. A readiness probe should be added.",
+				Metadata: kubeaudit.Metadata{
+					"Container": container.Name,
+				},
+			})
+		}
+	}
+	return auditResults
+}
+
+// hasPodDisruptionBudget returns true if a PodDisruptionBudget in the namespace of the workload selects its pods
+func hasPodDisruptionBudget(resource k8s.Resource, resources []k8s.Resource) bool {
+	namespace := k8s.GetObjectMeta(resource).GetNamespace()
+	podLabels := k8slabels.Set(k8s.GetLabels(resource))
+
+	for _, r := range resources {
+		pdb, ok := r.(*k8s.PodDisruptionBudgetV1)
+		if !ok || pdb.Namespace != namespace {
+			continue
+		}
+		// A PodDisruptionBudget without a selector selects no pods, and one with an empty selector selects every pod
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err == nil && selector.Matches(podLabels) {
+			return true
+		}
+	}
+	return false
+}
+
+func auditPlacement(podSpec *k8s.PodSpecV1) []*kubeaudit.AuditResult {
+	if node, ok := getPinnedNode(podSpec); ok {
+		// A workload pinned to a single node is also pinned to a single zone, and spreading it is impossible
+		return []*kubeaudit.AuditResult{{
+			Auditor:  Name,
+			Rule:     ReplicasPinnedToSingleNode,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("All replicas can only be scheduled on node %s. The workload is unavailable if the node fails. The node selector, node affinity or nodeName should allow other nodes.", node),
+			Metadata: kubeaudit.Metadata{
+				"Node": node,
+			},
+		}}
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	if zone, ok := getPinnedValue(podSpec, zoneTopologyKeys); ok {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     ReplicasPinnedToSingleZone,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("All replicas can only be scheduled in zone %s. The workload is unavailable if the zone fails. The node selector or node affinity should allow other zones.", zone),
+			Metadata: kubeaudit.Metadata{
+				"Zone": zone,
+			},
+		})
+	}
+
+	if len(podSpec.TopologySpreadConstraints) == 0 && !hasPodAntiAffinity(podSpec) {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     TopologySpreadConstraintsMissing,
+			Severity: kubeaudit.Warn,
+			Message:  "Neither topologySpreadConstraints nor pod anti-affinity are set in the PodSpec. All replicas may be scheduled on the same node or zone. topologySpreadConstraints should be added to spread the replicas across nodes and zones.",
+		})
+	}
+
+	return auditResults
+}
+
+// hasPodAntiAffinity returns true if the pods are kept apart from each other or other pods by pod anti-affinity, which
+// also spreads the replicas
+func hasPodAntiAffinity(podSpec *k8s.PodSpecV1) bool {
+	if podSpec.Affinity == nil || podSpec.Affinity.PodAntiAffinity == nil {
+		return false
+	}
+	antiAffinity := podSpec.Affinity.PodAntiAffinity
+	return len(antiAffinity.RequiredDuringSchedulingIgnoredDuringExecution) > 0 ||
+		len(antiAffinity.PreferredDuringSchedulingIgnoredDuringExecution) > 0
+}
+
+func (a *Resilience) isProductionNamespace(resource k8s.Resource, resources []k8s.Resource) bool {
+	namespace := k8s.GetObjectMeta(resource).GetNamespace()
+	if namespace == "" {
+		return false
+	}
+
+	for _, r := range resources {
+		if k8s.IsNamespaceV1(r) && k8s.GetObjectMeta(r).GetName() == namespace {
+			return a.productionNamespaceSelector.Matches(k8slabels.Set(k8s.GetObjectMeta(r).GetLabels()))
+		}
+	}
+	return false
+}
+
+// getReplicas returns the number of replicas of a Deployment or StatefulSet. The number of replicas defaults to 1 if
+// it is not set
+func getReplicas(resource k8s.Resource) (int32, bool) {
+	var replicas *int32
+	switch kubeType := resource.(type) {
+	case *k8s.DeploymentV1:
+		replicas = kubeType.Spec.Replicas
+	case *k8s.StatefulSetV1:
+		replicas = kubeType.Spec.Replicas
+	default:
+		return 0, false
+	}
+
+	if replicas == nil {
+		return 1, true
+	}
+	return *replicas, true
+}
+
+func getPinnedNode(podSpec *k8s.PodSpecV1) (string, bool) {
+	if podSpec.NodeName != "" {
+		return podSpec.NodeName, true
+	}
+	return getPinnedValue(podSpec, nodeTopologyKeys)
+}
+
+// getPinnedValue returns the value of the node label the pods are restricted to, if the node selector or the
+// required node affinity only allow a single value for one of the given node labels
+func getPinnedValue(podSpec *k8s.PodSpecV1, keys []string) (string, bool) {
+	for _, key := range keys {
+		if value, ok := podSpec.NodeSelector[key]; ok {
+			return value, true
+		}
+	}
+
+	if podSpec.Affinity == nil || podSpec.Affinity.NodeAffinity == nil {
+		return "", false
+	}
+	required := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil || len(required.NodeSelectorTerms) == 0 {
+		return "", false
+	}
+
+	// Node selector terms are ORed, so the pods are only pinned if every term allows the same single value
+	pinned := ""
+	for _, term := range required.NodeSelectorTerms {
+		value, ok := getTermPinnedValue(term, keys)
+		if !ok || (pinned != "" && value != pinned) {
+			return "", false
+		}
+		pinned = value
+	}
+	return pinned, true
+}
+
+// getTermPinnedValue returns the value of the node label a node selector term is restricted to. The requirements of
+// a term are ANDed, so a single requirement allowing a single value is enough
+func getTermPinnedValue(term v1.NodeSelectorTerm, keys []string) (string, bool) {
+	for _, expression := range term.MatchExpressions {
+		if expression.Operator != v1.NodeSelectorOpIn || len(expression.Values) != 1 {
+			continue
+		}
+		for _, key := range keys {
+			if expression.Key == key {
+				return expression.Values[0], true
+			}
+		}
+	}
+	return "", false
+}
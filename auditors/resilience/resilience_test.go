@@ -0,0 +1,60 @@
+package resilience
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestAuditResilience(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"single-replica-production.yml", []string{SingleReplicaInProduction}},
+		{"single-replica-staging.yml", nil},
+		{"single-replica-no-namespace.yml", nil},
+		{"replicas-spread.yml", nil},
+		{"topology-spread-constraints-missing.yml", []string{TopologySpreadConstraintsMissing}},
+		{"pinned-node-selector.yml", []string{ReplicasPinnedToSingleNode}},
+		{"pinned-node-name.yml", []string{ReplicasPinnedToSingleNode}},
+		{"pinned-zone-affinity.yml", []string{ReplicasPinnedToSingleZone}},
+		{"zone-affinity-multiple-zones.yml", nil},
+		{"pod-anti-affinity.yml", nil},
+		{"pod-disruption-budget-missing.yml", []string{PodDisruptionBudgetMissing}},
+		{"probes-missing.yml", []string{LivenessProbeMissing, ReadinessProbeMissing}},
+		{"single-replica-production-allowed.yml", []string{override.GetOverriddenResultName(SingleReplicaInProduction)}},
+		{"resilience-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditResilienceProductionNamespaceSelector(t *testing.T) {
+	auditor, err := New(Config{ProductionNamespaceSelector: map[string]string{"env": "staging"}})
+	require.NoError(t, err)
+
+	test.AuditManifest(t, fixtureDir, "single-replica-staging.yml", auditor, []string{SingleReplicaInProduction})
+	test.AuditManifest(t, fixtureDir, "single-replica-production.yml", auditor, nil)
+}
+
+func TestNewInvalidProductionNamespaceSelector(t *testing.T) {
+	_, err := New(Config{ProductionNamespaceSelector: map[string]string{"env": "not a value"}})
+	assert.Error(t, err)
+}
@@ -19,6 +19,7 @@ func TestFixReadOnlyRootFilesystem(t *testing.T) {
 		{"read-only-root-filesystem-false-allowed.yml", fixtureDir, false},
 		{"read-only-root-filesystem-redundant-override.yml", fixtureDir, true},
 		{"read-only-root-filesystem-false-allowed-multi-labels.yml", fixtureDir, false},
+		{test.InitAndEphemeralContainersFixture, test.SharedFixturesDir, true},
 	}
 
 	for _, tc := range cases {
@@ -4,6 +4,7 @@ import (
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/Shopify/kubeaudit/pkg/windows"
 )
 
 const Name = "rootfs"
@@ -37,7 +38,7 @@ func (a *ReadOnlyRootFilesystem) Audit(resource k8s.Resource, _ []k8s.Resource)
 		}
 	}
 
-	return auditResults, nil
+	return windows.LinuxOnly(resource, auditResults), nil
 }
 
 func auditContainer(container *k8s.ContainerV1, resource k8s.Resource) *kubeaudit.AuditResult {
@@ -7,6 +7,7 @@ import (
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/internal/test"
 	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
 )
 
 const fixtureDir = "fixtures"
@@ -37,3 +38,8 @@ func TestAuditReadOnlyRootFilesystem(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditReadOnlyRootFilesystemInitAndEphemeralContainers(t *testing.T) {
+	report := test.AuditManifest(t, test.SharedFixturesDir, test.InitAndEphemeralContainersFixture, New(), []string{ReadOnlyRootFilesystemNil})
+	assert.Equal(t, test.InitAndEphemeralContainers, test.AuditedContainers(report, ReadOnlyRootFilesystemNil))
+}
@@ -0,0 +1,60 @@
+package runtimeclass
+
+import (
+	"fmt"
+	"strings"
+
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+type Config struct {
+	// Allowed lists the runtime classes pods may use. Pods which don't set runtimeClassName use the default runtime of
+	// their node and are allowed. Any runtime class is allowed if the list is empty
+	Allowed []string `yaml:"allowed"`
+	// Required lists the runtime classes pods must use, such as sandboxed runtimes like gVisor or Kata Containers for
+	// the namespaces of untrusted workloads
+	Required []Rule `yaml:"required"`
+}
+
+// Rule requires pods to use one of RuntimeClasses. If NamespaceSelector is set, the rule only applies to the pods of
+// namespaces with all of the given labels
+type Rule struct {
+	RuntimeClasses    []string          `yaml:"runtimeClasses"`
+	NamespaceSelector map[string]string `yaml:"namespaceSelector"`
+}
+
+// compiledRule is a Rule with its namespace selector compiled
+type compiledRule struct {
+	Rule
+	namespaceSelector k8slabels.Selector
+}
+
+func compileRules(rules []Rule) ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if len(rule.RuntimeClasses) == 0 {
+			return nil, fmt.Errorf("required rule has no runtime classes")
+		}
+		if err := validateRuntimeClasses(rule.RuntimeClasses); err != nil {
+			return nil, err
+		}
+
+		namespaceSelector, err := k8slabels.ValidatedSelectorFromSet(rule.NamespaceSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace selector for runtime classes %s: %w", strings.Join(rule.RuntimeClasses, ", "), err)
+		}
+
+		compiled = append(compiled, compiledRule{Rule: rule, namespaceSelector: namespaceSelector})
+	}
+	return compiled, nil
+}
+
+func validateRuntimeClasses(runtimeClasses []string) error {
+	for _, runtimeClass := range runtimeClasses {
+		if errs := validation.IsDNS1123Subdomain(runtimeClass); len(errs) > 0 {
+			return fmt.Errorf("invalid runtime class %q: %s", runtimeClass, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
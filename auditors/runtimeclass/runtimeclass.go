@@ -0,0 +1,138 @@
+package runtimeclass
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	k8slabels "k8s.io/apimachinery/pkg/labels"
+)
+
+const Name = "runtimeclass"
+
+const (
+	// RuntimeClassNotAllowed occurs when a pod uses a runtime class which is not allowed
+	RuntimeClassNotAllowed = "RuntimeClassNotAllowed"
+	// RuntimeClassRequired occurs when a pod doesn't use one of the runtime classes required in its namespace
+	RuntimeClassRequired = "RuntimeClassRequired"
+)
+
+const OverrideLabel = "allow-runtime-class"
+
+// RuntimeClass implements Auditable
+type RuntimeClass struct {
+	allowed  []string
+	required []compiledRule
+}
+
+func New(config Config) (*RuntimeClass, error) {
+	if err := validateRuntimeClasses(config.Allowed); err != nil {
+		return nil, fmt.Errorf("error creating runtimeclass auditor: %w", err)
+	}
+	required, err := compileRules(config.Required)
+	if err != nil {
+		return nil, fmt.Errorf("error creating runtimeclass auditor: %w", err)
+	}
+
+	return &RuntimeClass{
+		allowed:  config.Allowed,
+		required: required,
+	}, nil
+}
+
+// Audit checks that pods use an allowed runtime class, and one of the runtime classes required in their namespace
+func (a *RuntimeClass) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil || (len(a.allowed) == 0 && len(a.required) == 0) {
+		return nil, nil
+	}
+
+	runtimeClass := ""
+	if podSpec.RuntimeClassName != nil {
+		runtimeClass = *podSpec.RuntimeClassName
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	if runtimeClass != "" && len(a.allowed) > 0 && !contains(a.allowed, runtimeClass) {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     RuntimeClassNotAllowed,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Runtime class %s is not allowed. It should be one of: %s.", runtimeClass, strings.Join(a.allowed, ", ")),
+			Metadata: kubeaudit.Metadata{
+				"RuntimeClass": runtimeClass,
+			},
+		})
+	}
+
+	namespaceLabels, hasNamespace := getNamespaceLabels(resource, resources)
+	for _, rule := range a.required {
+		if !rule.namespaceSelector.Empty() && (!hasNamespace || !rule.namespaceSelector.Matches(k8slabels.Set(namespaceLabels))) {
+			continue
+		}
+		if contains(rule.RuntimeClasses, runtimeClass) {
+			continue
+		}
+		auditResults = append(auditResults, auditRequired(rule, runtimeClass))
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, OverrideLabel)
+	}
+	return auditResults, nil
+}
+
+func auditRequired(rule compiledRule, runtimeClass string) *kubeaudit.AuditResult {
+	required := strings.Join(rule.RuntimeClasses, ", ")
+	message := fmt.Sprintf("runtimeClassName is not set. It should be set to one of: %s.", required)
+	if runtimeClass != "" {
+		message = fmt.Sprintf("Runtime class %s is not one of the runtime classes required in the namespace. It should be one of: %s.", runtimeClass, required)
+	}
+
+	metadata := kubeaudit.Metadata{"RequiredRuntimeClass": required}
+	if runtimeClass != "" {
+		metadata["RuntimeClass"] = runtimeClass
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     RuntimeClassRequired,
+		Severity: kubeaudit.Error,
+		Message:  message,
+		Metadata: metadata,
+	}
+}
+
+// getNamespaceLabels returns the labels of the namespace of the resource. The returned boolean is false if the
+// namespace is not one of the audited resources
+func getNamespaceLabels(resource k8s.Resource, resources []k8s.Resource) (map[string]string, bool) {
+	namespace := k8s.GetObjectMeta(resource).GetNamespace()
+	if namespace == "" {
+		return nil, false
+	}
+
+	for _, r := range resources {
+		if k8s.IsNamespaceV1(r) && k8s.GetObjectMeta(r).GetName() == namespace {
+			return k8s.GetObjectMeta(r).GetLabels(), true
+		}
+	}
+	return nil, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
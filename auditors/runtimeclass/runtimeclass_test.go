@@ -0,0 +1,78 @@
+package runtimeclass
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+var testConfig = Config{
+	Allowed: []string{"runc", "gvisor", "kata"},
+	Required: []Rule{
+		{RuntimeClasses: []string{"gvisor", "kata"}, NamespaceSelector: map[string]string{"trust": "untrusted"}},
+	},
+}
+
+func TestAuditRuntimeClass(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"runtime-class-allowed.yml", nil},
+		{"runtime-class-not-set.yml", nil},
+		{"runtime-class-not-allowed.yml", []string{RuntimeClassNotAllowed}},
+		{"runtime-class-not-allowed-allowed.yml", []string{override.GetOverriddenResultName(RuntimeClassNotAllowed)}},
+		{"runtime-class-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+		{"runtime-class-required.yml", []string{RuntimeClassRequired}},
+		{"runtime-class-required-other.yml", []string{RuntimeClassRequired}},
+		{"runtime-class-required-valid.yml", nil},
+	}
+
+	auditor, err := New(testConfig)
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditRuntimeClassRequiredForAllNamespaces(t *testing.T) {
+	auditor, err := New(Config{Required: []Rule{{RuntimeClasses: []string{"gvisor"}}}})
+	require.NoError(t, err)
+
+	test.AuditManifest(t, fixtureDir, "runtime-class-allowed.yml", auditor, nil)
+	report := test.AuditManifest(t, fixtureDir, "runtime-class-not-set.yml", auditor, []string{RuntimeClassRequired})
+	auditResults := report.Results()[0].GetAuditResults()
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, "runtimeClassName is not set. It should be set to one of: gvisor.", auditResults[0].Message)
+}
+
+func TestAuditRuntimeClassNoConfig(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+	test.AuditManifest(t, fixtureDir, "runtime-class-not-allowed.yml", auditor, nil)
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	cases := []Config{
+		{Allowed: []string{"Not_Valid"}},
+		{Required: []Rule{{}}},
+		{Required: []Rule{{RuntimeClasses: []string{"gvisor"}, NamespaceSelector: map[string]string{"trust": "not a value"}}}},
+	}
+
+	for _, config := range cases {
+		_, err := New(config)
+		assert.Error(t, err)
+	}
+}
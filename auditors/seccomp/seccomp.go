@@ -7,6 +7,8 @@ import (
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/pkg/fix"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/Shopify/kubeaudit/pkg/windows"
 	apiv1 "k8s.io/api/core/v1"
 )
 
@@ -34,6 +36,8 @@ const (
 	PodAnnotationKey = apiv1.SeccompPodAnnotationKey
 )
 
+const OverrideLabel = "allow-disabled-seccomp"
+
 // Seccomp implements Auditable
 type Seccomp struct{}
 
@@ -56,7 +60,17 @@ func (a *Seccomp) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.A
 		auditResults = appendNotNil(auditResults, auditResult)
 	}
 
-	return auditResults, nil
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return windows.LinuxOnly(resource, []*kubeaudit.AuditResult{auditResult}), nil
+		}
+		return nil, nil
+	}
+
+	for i := range auditResults {
+		auditResults[i] = override.ApplyOverride(auditResults[i], Name, "", resource, OverrideLabel)
+	}
+	return windows.LinuxOnly(resource, auditResults), nil
 }
 
 func appendNotNil(auditResults []*kubeaudit.AuditResult, auditResult *kubeaudit.AuditResult) []*kubeaudit.AuditResult {
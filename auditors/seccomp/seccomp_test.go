@@ -4,7 +4,11 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestAuditSeccomp(t *testing.T) {
@@ -19,8 +23,12 @@ func TestAuditSeccomp(t *testing.T) {
 		{"seccomp-disabled-pod.yml", []string{SeccompDisabledPod}, true},
 		{"seccomp-disabled.yml", []string{SeccompDisabledContainer}, true},
 		{"seccomp-disabled-localhost.yml", []string{SeccompDisabledContainer}, true},
+		{"seccomp-disabled-allowed.yml", []string{override.GetOverriddenResultName(SeccompDisabledContainer)}, true},
 		{"seccomp-enabled-pod.yml", nil, true},
 		{"seccomp-enabled.yml", nil, true},
+		{"seccomp-windows-pod.yml", nil, false},
+		{"seccomp-windows-node-selector.yml", nil, false},
+		{"seccomp-windows-options.yml", []string{SeccompProfileMissing}, false},
 	}
 
 	for _, tc := range cases {
@@ -35,3 +43,13 @@ func TestAuditSeccomp(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditSeccompWindowsOptions(t *testing.T) {
+	// The pod may run on Windows, where seccomp profiles are ignored, so the missing profile is only a warning
+	report := test.AuditManifest(t, fixtureDir, "seccomp-windows-options.yml", New(), []string{SeccompProfileMissing})
+	require.Len(t, report.Results(), 1)
+	auditResults := report.Results()[0].GetAuditResults()
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, kubeaudit.Warn, auditResults[0].Severity)
+	assert.Contains(t, auditResults[0].Message, "windowsOptions")
+}
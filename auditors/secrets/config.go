@@ -0,0 +1,44 @@
+package secrets
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit"
+)
+
+// defaultMinEntropy is the default Shannon entropy, in bits per character, above which values of unknown format are
+// reported as secrets
+const defaultMinEntropy = 4.0
+
+type Config struct {
+	// SecretRefSeverity is the severity of the results of containers which inject secrets into environment variables,
+	// one of "error", "warning" and "info". Defaults to "warning"
+	SecretRefSeverity string `yaml:"secretRefSeverity"`
+	// MinEntropy is the Shannon entropy, in bits per character, above which random looking values are reported as
+	// secrets when their format is not known. Defaults to 4.0
+	MinEntropy float64 `yaml:"minEntropy"`
+	// MountSecretRefs makes autofix mount the secrets referenced by environment variables as projected volumes instead
+	MountSecretRefs bool `yaml:"mountSecretRefs"`
+}
+
+func (c *Config) GetSecretRefSeverity() (kubeaudit.SeverityLevel, error) {
+	if c == nil || c.SecretRefSeverity == "" {
+		return kubeaudit.Warn, nil
+	}
+	severity, err := kubeaudit.ParseSeverity(c.SecretRefSeverity)
+	if err != nil {
+		return 0, fmt.Errorf("invalid secretRefSeverity: %w", err)
+	}
+	return severity, nil
+}
+
+func (c *Config) GetMinEntropy() float64 {
+	if c == nil || c.MinEntropy <= 0 {
+		return defaultMinEntropy
+	}
+	return c.MinEntropy
+}
+
+func (c *Config) GetMountSecretRefs() bool {
+	return c != nil && c.MountSecretRefs
+}
@@ -0,0 +1,115 @@
+package secrets
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// secretPatterns are the formats of well-known secrets, which are reported wherever they appear
+var secretPatterns = []struct {
+	name   string
+	regexp *regexp.Regexp
+}{
+	{"private key", regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----`)},
+	{"AWS access key ID", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"GitHub token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`)},
+	{"Slack token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"Google API key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{"JSON Web Token", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`)},
+	{"URL credentials", regexp.MustCompile(`(?i)\b[a-z][a-z0-9+.-]*://[^/\s:@]+:[^/\s@]+@`)},
+	{"credential assignment", regexp.MustCompile(`(?i)\b(?:password|passwd|secret|token|api[_-]?key)"?\s*[:=]\s*"?[^\s"',}$]{8,}`)},
+}
+
+// credentialName matches the names of environment variables and annotations which usually hold credentials, such as
+// DB_PASSWORD or API_KEY
+var credentialName = regexp.MustCompile(`(?i)(passw(?:or)?d|passphrase|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)`)
+
+// referenceName matches the names of credential variables which refer to a credential rather than hold it, such as
+// DB_PASSWORD_FILE or SECRET_NAME
+var referenceName = regexp.MustCompile(`(?i)[_.-](file|path|dir|name|ref)$`)
+
+// minCredentialLength and minCredentialEntropy are the length and entropy above which the values of credential
+// variables are reported. They are lower than for values of unknown variables since the name says what they hold
+const minCredentialLength = 8
+const minCredentialEntropy = 3.0
+
+// minRandomLength is the length above which values of unknown format are checked for entropy
+const minRandomLength = 20
+
+// detectSecret returns a description of the secret the value of the variable or annotation with the name looks like,
+// or an empty string if it does not look like a secret
+func detectSecret(name, value string, minEntropy float64) string {
+	for _, pattern := range secretPatterns {
+		if pattern.regexp.MatchString(value) {
+			return pattern.name
+		}
+	}
+
+	if !isLiteral(value) {
+		return ""
+	}
+
+	if credentialName.MatchString(name) && !referenceName.MatchString(name) &&
+		len(value) >= minCredentialLength && entropy(value) >= minCredentialEntropy {
+		return "credential value"
+	}
+
+	if len(value) >= minRandomLength && hasMixedCharacters(value) && entropy(value) >= minEntropy {
+		return "high entropy value"
+	}
+
+	return ""
+}
+
+// isLiteral returns false for values which are not secrets themselves, such as paths, numbers, booleans, values with
+// spaces and references to other environment variables
+func isLiteral(value string) bool {
+	if value == "" || strings.HasPrefix(value, "/") || strings.Contains(value, "$(") ||
+		strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+		return false
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return false
+	}
+	if _, err := strconv.ParseBool(value); err == nil {
+		return false
+	}
+	return true
+}
+
+// hasMixedCharacters returns true if the value has upper case letters, lower case letters and digits, which leaves out
+// hashes, UUIDs and identifiers
+func hasMixedCharacters(value string) bool {
+	var upper, lower, digit bool
+	for _, r := range value {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+// entropy returns the Shannon entropy of the value in bits per character
+func entropy(value string) float64 {
+	counts := map[rune]int{}
+	var length int
+	for _, r := range value {
+		counts[r]++
+		length++
+	}
+
+	var bits float64
+	for _, count := range counts {
+		p := float64(count) / float64(length)
+		bits -= p * math.Log2(p)
+	}
+	return bits
+}
@@ -0,0 +1,130 @@
+package secrets
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// secretsMountPath is the directory the secrets referenced by environment variables are mounted under by autofix
+const secretsMountPath = "/etc/secrets"
+
+type fixMountSecretKeyRef struct {
+	podSpec   *k8s.PodSpecV1
+	container *k8s.ContainerV1
+	envName   string
+	secretRef apiv1.SecretKeySelector
+}
+
+func (f *fixMountSecretKeyRef) Plan() string {
+	return fmt.Sprintf("Mount key %s of secret %s at %s/%s/%s instead of setting environment variable %s of container %s",
+		f.secretRef.Key, f.secretRef.Name, secretsMountPath, f.secretRef.Name, f.secretRef.Key, f.envName, f.container.Name)
+}
+
+func (f *fixMountSecretKeyRef) Apply(resource k8s.Resource) []k8s.Resource {
+	projection := mountSecret(f.podSpec, f.container, f.secretRef.Name, f.secretRef.Optional)
+	if projection.Items != nil && !hasKey(projection.Items, f.secretRef.Key) {
+		projection.Items = append(projection.Items, apiv1.KeyToPath{Key: f.secretRef.Key, Path: f.secretRef.Key})
+	}
+
+	env := f.container.Env[:0]
+	for _, envVar := range f.container.Env {
+		if envVar.Name != f.envName || envVar.ValueFrom == nil || envVar.ValueFrom.SecretKeyRef == nil {
+			env = append(env, envVar)
+		}
+	}
+	f.container.Env = env
+	return nil
+}
+
+type fixMountSecretEnvFrom struct {
+	podSpec   *k8s.PodSpecV1
+	container *k8s.ContainerV1
+	secretRef apiv1.SecretEnvSource
+}
+
+func (f *fixMountSecretEnvFrom) Plan() string {
+	return fmt.Sprintf("Mount secret %s at %s/%s instead of setting environment variables of container %s from it",
+		f.secretRef.Name, secretsMountPath, f.secretRef.Name, f.container.Name)
+}
+
+func (f *fixMountSecretEnvFrom) Apply(resource k8s.Resource) []k8s.Resource {
+	// All the keys of the secret are mounted
+	mountSecret(f.podSpec, f.container, f.secretRef.Name, f.secretRef.Optional).Items = nil
+
+	envFrom := f.container.EnvFrom[:0]
+	for _, source := range f.container.EnvFrom {
+		if source.SecretRef == nil || source.SecretRef.Name != f.secretRef.Name {
+			envFrom = append(envFrom, source)
+		}
+	}
+	f.container.EnvFrom = envFrom
+	return nil
+}
+
+// mountSecret adds a projected volume for the secret to the pod spec, and mounts it in the container read-only, unless
+// they already are. It returns the projection of the secret, with no items if the volume is new so items can be
+// added, or nil items if all the keys of the secret are already mounted
+func mountSecret(podSpec *k8s.PodSpecV1, container *k8s.ContainerV1, secretName string, optional *bool) *apiv1.SecretProjection {
+	volumeName := getVolumeName(secretName)
+
+	var projection *apiv1.SecretProjection
+	for i := range podSpec.Volumes {
+		volume := &podSpec.Volumes[i]
+		if volume.Name == volumeName && volume.Projected != nil && len(volume.Projected.Sources) > 0 {
+			projection = volume.Projected.Sources[0].Secret
+		}
+	}
+	if projection == nil {
+		podSpec.Volumes = append(podSpec.Volumes, apiv1.Volume{
+			Name: volumeName,
+			VolumeSource: apiv1.VolumeSource{
+				Projected: &apiv1.ProjectedVolumeSource{
+					Sources: []apiv1.VolumeProjection{{
+						Secret: &apiv1.SecretProjection{
+							LocalObjectReference: apiv1.LocalObjectReference{Name: secretName},
+							Items:                []apiv1.KeyToPath{},
+							Optional:             optional,
+						},
+					}},
+				},
+			},
+		})
+		projection = podSpec.Volumes[len(podSpec.Volumes)-1].Projected.Sources[0].Secret
+	}
+
+	for _, volumeMount := range container.VolumeMounts {
+		if volumeMount.Name == volumeName {
+			return projection
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, apiv1.VolumeMount{
+		Name:      volumeName,
+		MountPath: secretsMountPath + "/" + secretName,
+		ReadOnly:  true,
+	})
+	return projection
+}
+
+var invalidVolumeNameCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// getVolumeName returns the name of the volume of a secret, which must be a DNS label
+func getVolumeName(secretName string) string {
+	name := "secret-" + invalidVolumeNameCharacters.ReplaceAllString(strings.ToLower(secretName), "-")
+	if len(name) > 63 {
+		name = name[:63]
+	}
+	return strings.TrimRight(name, "-")
+}
+
+func hasKey(items []apiv1.KeyToPath, key string) bool {
+	for _, item := range items {
+		if item.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package secrets
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestFixSecretRefs(t *testing.T) {
+	auditor, err := New(Config{MountSecretRefs: true})
+	require.NoError(t, err)
+
+	cases := []struct {
+		file          string
+		expectedItems []apiv1.KeyToPath
+	}{
+		{"secret-env-var-ref.yml", []apiv1.KeyToPath{{Key: "password", Path: "password"}}},
+		{"secret-env-from-ref.yml", nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.file, func(t *testing.T) {
+			resources, _ := test.FixSetup(t, fixtureDir, tc.file, auditor)
+			require.Len(t, resources, 1)
+			podSpec := k8s.GetPodSpec(resources[0])
+
+			require.Len(t, podSpec.Volumes, 1)
+			assert.Equal(t, "secret-db", podSpec.Volumes[0].Name)
+			projection := podSpec.Volumes[0].Projected.Sources[0].Secret
+			assert.Equal(t, "db", projection.Name)
+			assert.Equal(t, tc.expectedItems, projection.Items)
+
+			container := podSpec.Containers[0]
+			assert.Equal(t, []apiv1.VolumeMount{{Name: "secret-db", MountPath: "/etc/secrets/db", ReadOnly: true}}, container.VolumeMounts)
+			assert.Empty(t, container.Env)
+			for _, envFrom := range container.EnvFrom {
+				assert.Nil(t, envFrom.SecretRef)
+			}
+		})
+	}
+}
+
+func TestFixSecretRefsDisabled(t *testing.T) {
+	auditor, err := New(Config{})
+	require.NoError(t, err)
+
+	resources, _ := test.FixSetup(t, fixtureDir, "secret-env-var-ref.yml", auditor)
+	podSpec := k8s.GetPodSpec(resources[0])
+	assert.Empty(t, podSpec.Volumes)
+	assert.Len(t, podSpec.Containers[0].Env, 1)
+}
+
+func TestGetVolumeName(t *testing.T) {
+	assert.Equal(t, "secret-db", getVolumeName("db"))
+	assert.Equal(t, "secret-db-credentials-v2", getVolumeName("db.credentials.v2"))
+	assert.LessOrEqual(t, len(getVolumeName("a-very-long-secret-name-which-does-not-fit-in-a-volume-name-label")), 63)
+}
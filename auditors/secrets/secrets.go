@@ -0,0 +1,214 @@
+package secrets
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const Name = "secrets"
+
+const (
+	// SecretEnvVarRef occurs when an environment variable of a container is set from a secret with secretKeyRef
+	SecretEnvVarRef = "SecretEnvVarRef"
+	// SecretEnvFromRef occurs when the environment variables of a container are set from a secret with envFrom
+	SecretEnvFromRef = "SecretEnvFromRef"
+	// SecretEnvVarLiteral occurs when the literal value of an environment variable of a container looks like a secret
+	SecretEnvVarLiteral = "SecretEnvVarLiteral"
+	// SecretInAnnotation occurs when the value of an annotation of a resource or its pod template looks like a secret
+	SecretInAnnotation = "SecretInAnnotation"
+)
+
+const SecretRefOverrideLabel = "allow-secret-env-refs"
+const SecretLiteralOverrideLabel = "allow-secret-env-literals"
+const SecretAnnotationOverrideLabel = "allow-secret-annotations"
+
+// lastAppliedConfigAnnotation holds the whole manifest applied by kubectl, so its secrets are reported where they
+// are set instead
+const lastAppliedConfigAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+// Secrets implements Auditable
+type Secrets struct {
+	secretRefSeverity kubeaudit.SeverityLevel
+	minEntropy        float64
+	mountSecretRefs   bool
+}
+
+func New(config Config) (*Secrets, error) {
+	secretRefSeverity, err := config.GetSecretRefSeverity()
+	if err != nil {
+		return nil, fmt.Errorf("error creating secrets auditor: %w", err)
+	}
+
+	return &Secrets{
+		secretRefSeverity: secretRefSeverity,
+		minEntropy:        config.GetMinEntropy(),
+		mountSecretRefs:   config.GetMountSecretRefs(),
+	}, nil
+}
+
+// Audit checks that containers don't inject secrets into environment variables or set them as literal values, and
+// that annotations don't hold secrets
+func (a *Secrets) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResults []*kubeaudit.AuditResult
+
+	podSpec := k8s.GetPodSpec(resource)
+	for _, container := range k8s.GetContainers(resource) {
+		for _, check := range []struct {
+			auditFunc     func(*k8s.PodSpecV1, *k8s.ContainerV1) []*kubeaudit.AuditResult
+			overrideLabel string
+		}{
+			{a.auditSecretRefs, SecretRefOverrideLabel},
+			{a.auditLiterals, SecretLiteralOverrideLabel},
+		} {
+			containerResults := check.auditFunc(podSpec, container)
+			if len(containerResults) == 0 {
+				if auditResult := override.ApplyOverride(nil, Name, container.Name, resource, check.overrideLabel); auditResult != nil {
+					auditResults = append(auditResults, auditResult)
+				}
+				continue
+			}
+
+			for _, auditResult := range containerResults {
+				auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, container.Name, resource, check.overrideLabel))
+			}
+		}
+	}
+
+	annotationResults := a.auditAnnotations(resource)
+	if len(annotationResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, SecretAnnotationOverrideLabel); auditResult != nil {
+			auditResults = append(auditResults, auditResult)
+		}
+	}
+	for _, auditResult := range annotationResults {
+		auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, "", resource, SecretAnnotationOverrideLabel))
+	}
+
+	return auditResults, nil
+}
+
+func (a *Secrets) auditSecretRefs(podSpec *k8s.PodSpecV1, container *k8s.ContainerV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	for _, env := range container.Env {
+		if env.ValueFrom == nil || env.ValueFrom.SecretKeyRef == nil {
+			continue
+		}
+		secretKeyRef := env.ValueFrom.SecretKeyRef
+		auditResult := &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     SecretEnvVarRef,
+			Severity: a.secretRefSeverity,
+			Message:  fmt.Sprintf("Environment variable %s is set from key %s of secret %s. Environment variables are inherited by child processes and often end up in logs and crash reports. The secret should be mounted as a volume instead.", env.Name, secretKeyRef.Key, secretKeyRef.Name),
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"EnvVar":    env.Name,
+				"Secret":    secretKeyRef.Name,
+				"Key":       secretKeyRef.Key,
+			},
+		}
+		if a.mountSecretRefs {
+			auditResult.PendingFix = &fixMountSecretKeyRef{
+				podSpec:   podSpec,
+				container: container,
+				envName:   env.Name,
+				secretRef: *secretKeyRef,
+			}
+		}
+		auditResults = append(auditResults, auditResult)
+	}
+
+	for _, envFrom := range container.EnvFrom {
+		if envFrom.SecretRef == nil {
+			continue
+		}
+		auditResult := &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     SecretEnvFromRef,
+			Severity: a.secretRefSeverity,
+			Message:  fmt.Sprintf("Environment variables are set from all the keys of secret %s with envFrom. Environment variables are inherited by child processes and often end up in logs and crash reports. The secret should be mounted as a volume instead.", envFrom.SecretRef.Name),
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Secret":    envFrom.SecretRef.Name,
+			},
+		}
+		if a.mountSecretRefs {
+			auditResult.PendingFix = &fixMountSecretEnvFrom{
+				podSpec:   podSpec,
+				container: container,
+				secretRef: *envFrom.SecretRef,
+			}
+		}
+		auditResults = append(auditResults, auditResult)
+	}
+
+	return auditResults
+}
+
+func (a *Secrets) auditLiterals(_ *k8s.PodSpecV1, container *k8s.ContainerV1) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	for _, env := range container.Env {
+		reason := detectSecret(env.Name, env.Value, a.minEntropy)
+		if reason == "" {
+			continue
+		}
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     SecretEnvVarLiteral,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Environment variable %s is set to a literal value which looks like a secret (%s). Anyone who can read the resource can read it. The value should be stored in a secret and mounted as a volume.", env.Name, reason),
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"EnvVar":    env.Name,
+				"Reason":    reason,
+			},
+		})
+	}
+
+	return auditResults
+}
+
+func (a *Secrets) auditAnnotations(resource k8s.Resource) []*kubeaudit.AuditResult {
+	annotations := map[string]string{}
+	for _, objectMeta := range []interface{ GetAnnotations() map[string]string }{
+		k8s.GetObjectMeta(resource), k8s.GetPodObjectMeta(resource),
+	} {
+		if objectMeta == nil {
+			continue
+		}
+		for k, v := range objectMeta.GetAnnotations() {
+			annotations[k] = v
+		}
+	}
+	delete(annotations, lastAppliedConfigAnnotation)
+
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, key := range keys {
+		reason := detectSecret(key, annotations[key], a.minEntropy)
+		if reason == "" {
+			continue
+		}
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     SecretInAnnotation,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Annotation %s has a value which looks like a secret (%s). Annotations can be read by anyone who can read the resource. The value should be stored in a secret.", key, reason),
+			Metadata: kubeaudit.Metadata{
+				"Annotation": key,
+				"Reason":     reason,
+			},
+		})
+	}
+	return auditResults
+}
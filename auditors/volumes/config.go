@@ -0,0 +1,21 @@
+package volumes
+
+// DefaultMaxTokenExpirationSeconds is the default maximum expiration of projected service account tokens, the
+// expiration of the tokens Kubernetes mounts in pods which automount their service account token
+const DefaultMaxTokenExpirationSeconds = 3607
+
+type Config struct {
+	// MaxTokenExpirationSeconds is the maximum expirationSeconds of projected service account tokens. Defaults to
+	// DefaultMaxTokenExpirationSeconds
+	MaxTokenExpirationSeconds int64 `yaml:"maxTokenExpirationSeconds"`
+	// AllowedCSIDrivers lists the CSI drivers pods may use in inline volumes, such as "secrets-store.csi.k8s.io".
+	// Inline volumes of every other driver are reported
+	AllowedCSIDrivers []string `yaml:"allowedCSIDrivers"`
+}
+
+func (config *Config) GetMaxTokenExpirationSeconds() int64 {
+	if config == nil || config.MaxTokenExpirationSeconds == 0 {
+		return DefaultMaxTokenExpirationSeconds
+	}
+	return config.MaxTokenExpirationSeconds
+}
@@ -0,0 +1,99 @@
+package volumes
+
+import (
+	"fmt"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// rootCAConfigMap is the ConfigMap with the CA certificate of the API server which Kubernetes creates in every
+// namespace
+const rootCAConfigMap = "kube-root-ca.crt"
+
+type fixLegacyServiceAccountToken struct {
+	volume *apiv1.Volume
+}
+
+func (f *fixLegacyServiceAccountToken) Plan() string {
+	return fmt.Sprintf("Replace the Secret of volume %s with a projected service account token, CA certificate and namespace", f.volume.Name)
+}
+
+// Apply replaces the token Secret with the projected volume Kubernetes mounts for service account tokens, which has
+// the same token, ca.crt and namespace files
+func (f *fixLegacyServiceAccountToken) Apply(resource k8s.Resource) []k8s.Resource {
+	f.volume.VolumeSource = apiv1.VolumeSource{
+		Projected: &apiv1.ProjectedVolumeSource{
+			DefaultMode: f.volume.Secret.DefaultMode,
+			Sources: []apiv1.VolumeProjection{
+				{ServiceAccountToken: &apiv1.ServiceAccountTokenProjection{Path: "token"}},
+				{ConfigMap: &apiv1.ConfigMapProjection{
+					LocalObjectReference: apiv1.LocalObjectReference{Name: rootCAConfigMap},
+					Items:                []apiv1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				}},
+				{DownwardAPI: &apiv1.DownwardAPIProjection{
+					Items: []apiv1.DownwardAPIVolumeFile{{
+						Path:     "namespace",
+						FieldRef: &apiv1.ObjectFieldSelector{APIVersion: "v1", FieldPath: "metadata.namespace"},
+					}},
+				}},
+			},
+		},
+	}
+	return nil
+}
+
+type fixTokenExpiration struct {
+	token             *apiv1.ServiceAccountTokenProjection
+	expirationSeconds int64
+}
+
+func (f *fixTokenExpiration) Plan() string {
+	return fmt.Sprintf("Set expirationSeconds to %d in the service account token projection", f.expirationSeconds)
+}
+
+func (f *fixTokenExpiration) Apply(resource k8s.Resource) []k8s.Resource {
+	expirationSeconds := f.expirationSeconds
+	f.token.ExpirationSeconds = &expirationSeconds
+	return nil
+}
+
+type fixSecretVolumeMode struct {
+	volume *apiv1.Volume
+}
+
+func (f *fixSecretVolumeMode) Plan() string {
+	return fmt.Sprintf("Remove the permissions of other users from the modes of volume %s", f.volume.Name)
+}
+
+// Apply removes the permissions of other users from the modes of the volume. The volume source is looked up when the
+// fix is applied, since the token Secret of the volume may have been replaced by a projected volume
+func (f *fixSecretVolumeMode) Apply(resource k8s.Resource) []k8s.Resource {
+	switch {
+	case f.volume.Secret != nil:
+		f.volume.Secret.DefaultMode = restrictMode(f.volume.Secret.DefaultMode)
+		restrictItemModes(f.volume.Secret.Items)
+	case f.volume.Projected != nil:
+		f.volume.Projected.DefaultMode = restrictMode(f.volume.Projected.DefaultMode)
+		for _, source := range f.volume.Projected.Sources {
+			if source.Secret != nil {
+				restrictItemModes(source.Secret.Items)
+			}
+		}
+	}
+	return nil
+}
+
+func restrictItemModes(items []apiv1.KeyToPath) {
+	for i := range items {
+		items[i].Mode = restrictMode(items[i].Mode)
+	}
+}
+
+func restrictMode(mode *int32) *int32 {
+	if mode == nil {
+		return nil
+	}
+	restricted := *mode &^ 0007
+	return &restricted
+}
@@ -0,0 +1,33 @@
+package volumes
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixVolumes(t *testing.T) {
+	resources, _ := test.FixSetup(t, fixtureDir, "legacy-token-secret.yml", New(testConfig))
+	require.Len(t, resources, 1)
+	volume := k8s.GetPodSpec(resources[0]).Volumes[0]
+	assert.Nil(t, volume.Secret)
+	require.NotNil(t, volume.Projected)
+	require.Len(t, volume.Projected.Sources, 3)
+	assert.Equal(t, "token", volume.Projected.Sources[0].ServiceAccountToken.Path)
+	assert.Equal(t, "kube-root-ca.crt", volume.Projected.Sources[1].ConfigMap.Name)
+	assert.Equal(t, "metadata.namespace", volume.Projected.Sources[2].DownwardAPI.Items[0].FieldRef.FieldPath)
+
+	resources, _ = test.FixSetup(t, fixtureDir, "token-expiration-too-long.yml", New(testConfig))
+	require.Len(t, resources, 1)
+	assert.Equal(t, int64(DefaultMaxTokenExpirationSeconds), *k8s.GetPodSpec(resources[0]).Volumes[0].Projected.Sources[0].ServiceAccountToken.ExpirationSeconds)
+
+	resources, _ = test.FixSetup(t, fixtureDir, "secret-world-readable.yml", New(testConfig))
+	require.Len(t, resources, 1)
+	volumes := k8s.GetPodSpec(resources[0]).Volumes
+	assert.Equal(t, int32(0640), *volumes[0].Secret.DefaultMode)
+	assert.Equal(t, int32(0770), *volumes[0].Secret.Items[0].Mode)
+	assert.Equal(t, int32(0440), *volumes[1].Projected.DefaultMode)
+}
@@ -0,0 +1,267 @@
+package volumes
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const Name = "volumes"
+
+const (
+	// LegacyServiceAccountTokenMounted occurs when a pod mounts the long-lived token Secret of a service account
+	// instead of a projected token which expires
+	LegacyServiceAccountTokenMounted = "LegacyServiceAccountTokenMounted"
+	// ServiceAccountTokenExpirationTooLong occurs when a projected service account token expires after more than the
+	// maximum expiration
+	ServiceAccountTokenExpirationTooLong = "ServiceAccountTokenExpirationTooLong"
+	// CSIInlineVolumeNotAllowed occurs when a pod has an inline CSI volume of a driver which is not allowed
+	CSIInlineVolumeNotAllowed = "CSIInlineVolumeNotAllowed"
+	// SecretVolumeWorldReadable occurs when the mode of the files of a Secret volume lets any user read them
+	SecretVolumeWorldReadable = "SecretVolumeWorldReadable"
+)
+
+const overrideLabelPrefix = "allow-volume-"
+
+const (
+	VolumeNameMetadataKey     = "Volume"
+	SecretNameMetadataKey     = "Secret"
+	ServiceAccountMetadataKey = "ServiceAccount"
+	ModeMetadataKey           = "Mode"
+)
+
+// legacyTokenSecretPattern matches the names of the token Secrets created for service accounts by Kubernetes before
+// 1.24, which are the name of the service account followed by "-token-" and 5 random characters
+var legacyTokenSecretPattern = regexp.MustCompile(`^(.+)-token-[bcdfghjklmnpqrstvwxz2456789]{5}$`)
+
+// Volumes implements Auditable
+type Volumes struct {
+	maxTokenExpirationSeconds int64
+	allowedCSIDrivers         map[string]bool
+}
+
+func New(config Config) *Volumes {
+	allowedCSIDrivers := make(map[string]bool)
+	for _, driver := range config.AllowedCSIDrivers {
+		allowedCSIDrivers[driver] = true
+	}
+	return &Volumes{
+		maxTokenExpirationSeconds: config.GetMaxTokenExpirationSeconds(),
+		allowedCSIDrivers:         allowedCSIDrivers,
+	}
+}
+
+// Audit checks that pods don't mount legacy service account token Secrets, projected service account tokens which
+// expire too late, inline CSI volumes of drivers which are not allowed and Secret volumes readable by any user
+func (a *Volumes) Audit(resource k8s.Resource, resources []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return nil, nil
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	for i := range podSpec.Volumes {
+		volume := &podSpec.Volumes[i]
+		volumeResults := a.auditVolume(resource, podSpec, volume, resources)
+		if len(volumeResults) == 0 {
+			volumeResults = []*kubeaudit.AuditResult{nil}
+		}
+		for _, auditResult := range volumeResults {
+			auditResult = override.ApplyOverride(auditResult, Name, "", resource, getOverrideLabel(volume.Name))
+			if auditResult != nil {
+				auditResults = append(auditResults, auditResult)
+			}
+		}
+	}
+
+	return auditResults, nil
+}
+
+func (a *Volumes) auditVolume(resource k8s.Resource, podSpec *k8s.PodSpecV1, volume *apiv1.Volume, resources []k8s.Resource) []*kubeaudit.AuditResult {
+	var auditResults []*kubeaudit.AuditResult
+
+	namespace := k8s.GetObjectMeta(resource).GetNamespace()
+	for _, secretName := range getSecretNames(volume) {
+		serviceAccount := getTokenServiceAccount(secretName, namespace, resources)
+		if serviceAccount == "" {
+			continue
+		}
+		auditResult := &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     LegacyServiceAccountTokenMounted,
+			Severity: kubeaudit.Error,
+			Message:  fmt.Sprintf("Volume %s mounts %s, the long-lived token Secret of service account %s, which never expires. A projected service account token should be mounted instead.", volume.Name, secretName, serviceAccount),
+			Metadata: kubeaudit.Metadata{
+				VolumeNameMetadataKey:     volume.Name,
+				SecretNameMetadataKey:     secretName,
+				ServiceAccountMetadataKey: serviceAccount,
+			},
+		}
+		// The projected token is the token of the service account of the pod, so the Secret can only be replaced by it
+		// if it is the token of the same service account
+		if volume.Secret != nil && len(volume.Secret.Items) == 0 && serviceAccount == getServiceAccountName(podSpec) {
+			auditResult.PendingFix = &fixLegacyServiceAccountToken{volume: volume}
+		}
+		auditResults = append(auditResults, auditResult)
+	}
+
+	if volume.Projected != nil {
+		for i := range volume.Projected.Sources {
+			token := volume.Projected.Sources[i].ServiceAccountToken
+			if token == nil || token.ExpirationSeconds == nil || *token.ExpirationSeconds <= a.maxTokenExpirationSeconds {
+				continue
+			}
+			auditResults = append(auditResults, &kubeaudit.AuditResult{
+				Auditor:  Name,
+				Rule:     ServiceAccountTokenExpirationTooLong,
+				Severity: kubeaudit.Warn,
+				Message:  fmt.Sprintf("The service account token of volume %s expires after %d seconds. expirationSeconds should be at most %d.", volume.Name, *token.ExpirationSeconds, a.maxTokenExpirationSeconds),
+				PendingFix: &fixTokenExpiration{
+					token:             token,
+					expirationSeconds: a.maxTokenExpirationSeconds,
+				},
+				Metadata: kubeaudit.Metadata{
+					VolumeNameMetadataKey: volume.Name,
+					"ExpirationSeconds":   fmt.Sprintf("%d", *token.ExpirationSeconds),
+				},
+			})
+		}
+	}
+
+	if volume.CSI != nil && !a.allowedCSIDrivers[volume.CSI.Driver] {
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     CSIInlineVolumeNotAllowed,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("Volume %s is an inline volume of CSI driver %s, which is not allowed. Inline CSI volumes bypass the admission of persistent volumes, and some drivers give access to the host. The driver should be added to the allowed CSI drivers if it is trusted.", volume.Name, volume.CSI.Driver),
+			Metadata: kubeaudit.Metadata{
+				VolumeNameMetadataKey: volume.Name,
+				"Driver":              volume.CSI.Driver,
+			},
+		})
+	}
+
+	if modes := getWorldReadableModes(volume); len(modes) > 0 {
+		description := "mode " + modes[0] + ", which lets"
+		if len(modes) > 1 {
+			description = "modes " + strings.Join(modes, ", ") + ", which let"
+		}
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:  Name,
+			Rule:     SecretVolumeWorldReadable,
+			Severity: kubeaudit.Warn,
+			Message:  fmt.Sprintf("Secret volume %s has %s any user of the containers read its files. The modes should not give permissions to other users, such as 0440.", volume.Name, description),
+			PendingFix: &fixSecretVolumeMode{
+				volume: volume,
+			},
+			Metadata: kubeaudit.Metadata{
+				VolumeNameMetadataKey: volume.Name,
+				ModeMetadataKey:       strings.Join(modes, ", "),
+			},
+		})
+	}
+
+	return auditResults
+}
+
+// getSecretNames returns the names of the Secrets of a Secret volume or a projected volume
+func getSecretNames(volume *apiv1.Volume) []string {
+	if volume.Secret != nil {
+		return []string{volume.Secret.SecretName}
+	}
+	var names []string
+	if volume.Projected != nil {
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil {
+				names = append(names, source.Secret.Name)
+			}
+		}
+	}
+	return names
+}
+
+// getTokenServiceAccount returns the name of the service account whose legacy token the Secret is, or an empty string
+// if it isn't a token Secret. Token Secrets are listed in the secrets of their service account, or recognized by the
+// name Kubernetes gave them since Secrets are not audited
+func getTokenServiceAccount(secretName, namespace string, resources []k8s.Resource) string {
+	for _, resource := range resources {
+		serviceAccount, ok := resource.(*k8s.ServiceAccountV1)
+		if !ok || serviceAccount.Namespace != namespace {
+			continue
+		}
+		for _, secret := range serviceAccount.Secrets {
+			if secret.Name == secretName {
+				return serviceAccount.Name
+			}
+		}
+	}
+
+	if match := legacyTokenSecretPattern.FindStringSubmatch(secretName); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+func getServiceAccountName(podSpec *k8s.PodSpecV1) string {
+	if podSpec.ServiceAccountName != "" {
+		return podSpec.ServiceAccountName
+	}
+	if podSpec.DeprecatedServiceAccount != "" {
+		return podSpec.DeprecatedServiceAccount
+	}
+	return "default"
+}
+
+// getWorldReadableModes returns the modes of the files of a volume with Secrets which let other users read them, in
+// octal. Only the modes which are set are checked. Projected volumes which only have a service account token, such as
+// the volumes Kubernetes mounts for automounted tokens, are left to the kubelet defaults
+func getWorldReadableModes(volume *apiv1.Volume) []string {
+	var modes []*int32
+	switch {
+	case volume.Secret != nil:
+		modes = append(modes, volume.Secret.DefaultMode)
+		for _, item := range volume.Secret.Items {
+			modes = append(modes, item.Mode)
+		}
+	case volume.Projected != nil:
+		hasSecrets := false
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil {
+				hasSecrets = true
+				for _, item := range source.Secret.Items {
+					modes = append(modes, item.Mode)
+				}
+			}
+		}
+		if !hasSecrets {
+			return nil
+		}
+		modes = append(modes, volume.Projected.DefaultMode)
+	}
+
+	var worldReadable []string
+	for _, mode := range modes {
+		if mode != nil && *mode&0004 != 0 {
+			worldReadable = appendUnique(worldReadable, fmt.Sprintf("%04o", *mode))
+		}
+	}
+	return worldReadable
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+func getOverrideLabel(volumeName string) string {
+	return overrideLabelPrefix + volumeName
+}
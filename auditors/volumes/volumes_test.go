@@ -0,0 +1,64 @@
+package volumes
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+var testConfig = Config{AllowedCSIDrivers: []string{"secrets-store.csi.k8s.io"}}
+
+func TestAuditVolumes(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"legacy-token-secret.yml", []string{LegacyServiceAccountTokenMounted}},
+		{"legacy-token-service-account.yml", []string{LegacyServiceAccountTokenMounted}},
+		{"legacy-token-secret-allowed.yml", []string{override.GetOverriddenResultName(LegacyServiceAccountTokenMounted)}},
+		{"token-expiration-too-long.yml", []string{ServiceAccountTokenExpirationTooLong}},
+		{"csi-inline-volume.yml", []string{CSIInlineVolumeNotAllowed}},
+		{"secret-world-readable.yml", []string{SecretVolumeWorldReadable}},
+		{"volumes-valid.yml", nil},
+		{"volumes-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			test.AuditManifest(t, fixtureDir, tc.file, New(testConfig), tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditVolumesConfig(t *testing.T) {
+	// Inline volumes of every CSI driver are reported if none are allowed
+	report := test.AuditManifest(t, fixtureDir, "csi-inline-volume.yml", New(Config{}), []string{CSIInlineVolumeNotAllowed})
+	assert.Len(t, report.Results()[0].GetAuditResults(), 2)
+
+	test.AuditManifest(t, fixtureDir, "token-expiration-too-long.yml", New(Config{MaxTokenExpirationSeconds: 86400}), nil)
+}
+
+func TestAuditVolumesMetadata(t *testing.T) {
+	report := test.AuditManifest(t, fixtureDir, "secret-world-readable.yml", New(testConfig), []string{SecretVolumeWorldReadable})
+	auditResults := report.Results()[0].GetAuditResults()
+	require.Len(t, auditResults, 2)
+	assert.Equal(t, "tls", auditResults[0].Metadata[VolumeNameMetadataKey])
+	assert.Equal(t, "0644, 0777", auditResults[0].Metadata[ModeMetadataKey])
+	assert.Equal(t, "credentials", auditResults[1].Metadata[VolumeNameMetadataKey])
+	assert.Equal(t, "0444", auditResults[1].Metadata[ModeMetadataKey])
+
+	report = test.AuditManifest(t, fixtureDir, "legacy-token-service-account.yml", New(testConfig), []string{LegacyServiceAccountTokenMounted})
+	auditResults = report.Results()[0].GetAuditResults()
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, "builder", auditResults[0].Metadata[ServiceAccountMetadataKey])
+	assert.Nil(t, auditResults[0].PendingFix, "the token of another service account can't be replaced by the token of the pod")
+}
@@ -0,0 +1,73 @@
+package vulns
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ScannerTrivy scans images with Trivy, either with its local database or with a Trivy server
+	ScannerTrivy = "trivy"
+	// ScannerGrype scans images with Grype
+	ScannerGrype = "grype"
+)
+
+// DefaultSeverities are the severities of the vulnerabilities which are reported if no severities are configured
+var DefaultSeverities = []string{SeverityCritical, SeverityHigh}
+
+type Config struct {
+	// Scanner is the scanner backend which scans the images, "trivy" or "grype". Defaults to "trivy"
+	Scanner string `yaml:"scanner"`
+	// Path is the path of the executable of the scanner. Defaults to the name of the scanner in the PATH
+	Path string `yaml:"path"`
+	// Server is the URL of a Trivy server the images are scanned with, instead of the local Trivy database
+	Server string `yaml:"server"`
+	// Severities are the severities of the vulnerabilities which are reported, among "CRITICAL", "HIGH", "MEDIUM"
+	// and "LOW". Defaults to "CRITICAL" and "HIGH"
+	Severities []string `yaml:"severities"`
+	// Offline keeps the scanners from updating their databases and pulling images, so only images available locally
+	// are scanned with the cached databases. It is set by the offline setting of the kubeaudit config rather than per
+	// auditor
+	Offline bool `yaml:"-"`
+}
+
+func (c *Config) GetScanner() (string, error) {
+	if c == nil || c.Scanner == "" {
+		return ScannerTrivy, nil
+	}
+	switch scanner := strings.ToLower(c.Scanner); scanner {
+	case ScannerTrivy, ScannerGrype:
+		return scanner, nil
+	}
+	return "", fmt.Errorf("unknown scanner %q, must be %q or %q", c.Scanner, ScannerTrivy, ScannerGrype)
+}
+
+func (c *Config) GetPath() string {
+	if c == nil || c.Path == "" {
+		scanner, _ := c.GetScanner()
+		return scanner
+	}
+	return c.Path
+}
+
+func (c *Config) GetServer() string {
+	if c == nil {
+		return ""
+	}
+	return c.Server
+}
+
+func (c *Config) GetSeverities() ([]string, error) {
+	if c == nil || len(c.Severities) == 0 {
+		return DefaultSeverities, nil
+	}
+	severities := make([]string, 0, len(c.Severities))
+	for _, severity := range c.Severities {
+		severity = strings.ToUpper(severity)
+		if _, ok := severityRules[severity]; !ok {
+			return nil, fmt.Errorf("unknown vulnerability severity %q", severity)
+		}
+		severities = append(severities, severity)
+	}
+	return severities, nil
+}
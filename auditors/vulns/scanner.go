@@ -0,0 +1,211 @@
+package vulns
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Vulnerability is a vulnerability of a package of an image
+type Vulnerability struct {
+	// ID is the identifier of the vulnerability, eg. "CVE-2023-12345" or "GHSA-xxxx-xxxx-xxxx"
+	ID               string
+	Package          string
+	InstalledVersion string
+	// FixedVersion is the version of the package which fixes the vulnerability, or empty if it isn't fixed yet
+	FixedVersion string
+	// Severity is the severity of the vulnerability, eg. "CRITICAL"
+	Severity string
+	// URL links to the description of the vulnerability
+	URL string
+}
+
+// ScanResult is the result of the scan of an image
+type ScanResult struct {
+	// Digest is the digest of the image which was scanned, or empty if the scanner didn't report it
+	Digest          string
+	Vulnerabilities []Vulnerability
+}
+
+// scanner scans images for vulnerabilities. It is implemented by the scanner backends
+type scanner interface {
+	Scan(ctx context.Context, image string) (*ScanResult, error)
+}
+
+// trivy scans images with the Trivy executable, which scans them with its local database or with a Trivy server
+type trivy struct {
+	path       string
+	server     string
+	severities []string
+	// offline scans with the cached databases and only the images of the local container runtimes
+	offline bool
+}
+
+func (t *trivy) Scan(ctx context.Context, image string) (*ScanResult, error) {
+	args := []string{"image", "--format", "json", "--quiet", "--severity", strings.Join(t.severities, ",")}
+	if t.server != "" {
+		args = append(args, "--server", t.server)
+	}
+	if t.offline {
+		args = append(args, "--skip-db-update", "--skip-java-db-update", "--offline-scan", "--image-src", "docker,containerd,podman")
+	}
+	output, err := run(ctx, t.path, nil, append(args, image)...)
+	if err != nil {
+		return nil, err
+	}
+	return parseTrivy(output)
+}
+
+// parseTrivy parses the JSON report of Trivy
+func parseTrivy(output []byte) (*ScanResult, error) {
+	var report struct {
+		Metadata struct {
+			RepoDigests []string `json:"RepoDigests"`
+		} `json:"Metadata"`
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID  string `json:"VulnerabilityID"`
+				PkgName          string `json:"PkgName"`
+				InstalledVersion string `json:"InstalledVersion"`
+				FixedVersion     string `json:"FixedVersion"`
+				Severity         string `json:"Severity"`
+				PrimaryURL       string `json:"PrimaryURL"`
+			} `json:"Vulnerabilities"`
+		} `json:"Results"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("invalid output of Trivy: %w", err)
+	}
+
+	result := &ScanResult{Digest: getRepoDigest(report.Metadata.RepoDigests)}
+	for _, target := range report.Results {
+		for _, vulnerability := range target.Vulnerabilities {
+			result.Vulnerabilities = append(result.Vulnerabilities, Vulnerability{
+				ID:               vulnerability.VulnerabilityID,
+				Package:          vulnerability.PkgName,
+				InstalledVersion: vulnerability.InstalledVersion,
+				FixedVersion:     vulnerability.FixedVersion,
+				Severity:         strings.ToUpper(vulnerability.Severity),
+				URL:              vulnerability.PrimaryURL,
+			})
+		}
+	}
+	return result, nil
+}
+
+// grype scans images with the Grype executable
+type grype struct {
+	path string
+	// offline scans with the cached database and only the images of the local Docker daemon
+	offline bool
+}
+
+func (g *grype) Scan(ctx context.Context, image string) (*ScanResult, error) {
+	var env []string
+	if g.offline {
+		env = []string{"GRYPE_DB_AUTO_UPDATE=false", "GRYPE_CHECK_FOR_APP_UPDATE=false"}
+		image = "docker:" + image
+	}
+	output, err := run(ctx, g.path, env, image, "--output", "json", "--quiet")
+	if err != nil {
+		return nil, err
+	}
+	return parseGrype(output)
+}
+
+// parseGrype parses the JSON report of Grype
+func parseGrype(output []byte) (*ScanResult, error) {
+	var report struct {
+		Matches []struct {
+			Vulnerability struct {
+				ID         string `json:"id"`
+				Severity   string `json:"severity"`
+				DataSource string `json:"dataSource"`
+				Fix        struct {
+					Versions []string `json:"versions"`
+				} `json:"fix"`
+			} `json:"vulnerability"`
+			Artifact struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"artifact"`
+		} `json:"matches"`
+		Source struct {
+			Target struct {
+				RepoDigests []string `json:"repoDigests"`
+			} `json:"target"`
+		} `json:"source"`
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("invalid output of Grype: %w", err)
+	}
+
+	result := &ScanResult{Digest: getRepoDigest(report.Source.Target.RepoDigests)}
+	for _, match := range report.Matches {
+		result.Vulnerabilities = append(result.Vulnerabilities, Vulnerability{
+			ID:               match.Vulnerability.ID,
+			Package:          match.Artifact.Name,
+			InstalledVersion: match.Artifact.Version,
+			FixedVersion:     strings.Join(match.Vulnerability.Fix.Versions, ", "),
+			Severity:         strings.ToUpper(match.Vulnerability.Severity),
+			URL:              match.Vulnerability.DataSource,
+		})
+	}
+	return result, nil
+}
+
+// getRepoDigest returns the digest of the first repo digest, eg. "sha256:..." for "nginx@sha256:..."
+func getRepoDigest(repoDigests []string) string {
+	for _, repoDigest := range repoDigests {
+		if i := strings.LastIndex(repoDigest, "@"); i >= 0 {
+			return repoDigest[i+1:]
+		}
+	}
+	return ""
+}
+
+// offlineScanner is the scanner when kubeaudit runs offline and the configured scanner needs the network
+type offlineScanner struct {
+	reason string
+}
+
+func (s offlineScanner) Scan(context.Context, string) (*ScanResult, error) {
+	return nil, &skippedError{reason: s.reason}
+}
+
+// skippedError is returned by scanners which skip the scan of an image
+type skippedError struct {
+	reason string
+}
+
+func (e *skippedError) Error() string {
+	return "image was not scanned: " + e.reason
+}
+
+// run runs the scanner with the additional environment variables and returns its standard output
+func run(ctx context.Context, path string, env []string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("image was not scanned within %s", timeout)
+		}
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
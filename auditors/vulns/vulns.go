@@ -0,0 +1,291 @@
+package vulns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/registry"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const Name = "vulns"
+
+const (
+	// CriticalVulnerability occurs when the image of a container has a vulnerability of critical severity
+	CriticalVulnerability = "CriticalVulnerability"
+	// HighVulnerability occurs when the image of a container has a vulnerability of high severity
+	HighVulnerability = "HighVulnerability"
+	// MediumVulnerability occurs when the image of a container has a vulnerability of medium severity
+	MediumVulnerability = "MediumVulnerability"
+	// LowVulnerability occurs when the image of a container has a vulnerability of low severity
+	LowVulnerability = "LowVulnerability"
+	// ImageScanFailed occurs when the image of a container can't be scanned
+	ImageScanFailed = "ImageScanFailed"
+	// ImageScanSkipped occurs when the image of a container isn't scanned since kubeaudit runs offline and the scanner
+	// needs the network
+	ImageScanSkipped = "ImageScanSkipped"
+)
+
+const OverrideLabel = "allow-vulnerable-image"
+
+// The severities of vulnerabilities, as reported by the scanners
+const (
+	SeverityCritical = "CRITICAL"
+	SeverityHigh     = "HIGH"
+	SeverityMedium   = "MEDIUM"
+	SeverityLow      = "LOW"
+)
+
+// timeout is the time a scanner has to scan an image, which includes pulling it
+const timeout = 10 * time.Minute
+
+// severityRule is the rule and the severity of the results of vulnerabilities of a severity
+type severityRule struct {
+	rule     string
+	severity kubeaudit.SeverityLevel
+	order    int
+}
+
+var severityRules = map[string]severityRule{
+	SeverityCritical: {CriticalVulnerability, kubeaudit.Error, 0},
+	SeverityHigh:     {HighVulnerability, kubeaudit.Warn, 1},
+	SeverityMedium:   {MediumVulnerability, kubeaudit.Info, 2},
+	SeverityLow:      {LowVulnerability, kubeaudit.Info, 3},
+}
+
+// Vulns implements Auditable
+type Vulns struct {
+	scanner    scanner
+	severities map[string]bool
+	cache      *cache
+}
+
+// New returns an auditor which scans the images of containers with the configured scanner. The executable of the
+// scanner is only run when images are audited, so if it is missing the images are reported as not scanned
+func New(config Config) (*Vulns, error) {
+	scannerName, err := config.GetScanner()
+	if err != nil {
+		return nil, fmt.Errorf("error creating vulns auditor: %w", err)
+	}
+	severities, err := config.GetSeverities()
+	if err != nil {
+		return nil, fmt.Errorf("error creating vulns auditor: %w", err)
+	}
+
+	path := config.GetPath()
+	var s scanner
+	switch scannerName {
+	case ScannerTrivy:
+		if config.Offline && config.GetServer() != "" {
+			s = offlineScanner{reason: "the Trivy server can't be reached offline"}
+			break
+		}
+		s = &trivy{path: path, server: config.GetServer(), severities: severities, offline: config.Offline}
+	case ScannerGrype:
+		if config.GetServer() != "" {
+			return nil, fmt.Errorf("error creating vulns auditor: only the %s scanner supports a server", ScannerTrivy)
+		}
+		s = &grype{path: path, offline: config.Offline}
+	}
+	return newVulns(s, severities), nil
+}
+
+func newVulns(s scanner, severities []string) *Vulns {
+	enabled := make(map[string]bool, len(severities))
+	for _, severity := range severities {
+		enabled[severity] = true
+	}
+	return &Vulns{scanner: s, severities: enabled, cache: newCache()}
+}
+
+// Audit scans the image of each container and reports its vulnerabilities of the configured severities. Each image is
+// only scanned once, even if it is used by many containers
+func (a *Vulns) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	var auditResults []*kubeaudit.AuditResult
+	for _, container := range k8s.GetContainers(resource) {
+		if container.Image == "" {
+			continue
+		}
+		for _, auditResult := range a.auditContainer(container, getDigest(resource, container)) {
+			auditResults = append(auditResults, override.ApplyOverride(auditResult, Name, container.Name, resource, OverrideLabel))
+		}
+	}
+
+	if len(auditResults) == 0 {
+		if auditResult := override.ApplyOverride(nil, Name, "", resource, OverrideLabel); auditResult != nil {
+			return []*kubeaudit.AuditResult{auditResult}, nil
+		}
+		return nil, nil
+	}
+	return auditResults, nil
+}
+
+func (a *Vulns) auditContainer(container *k8s.ContainerV1, digest string) []*kubeaudit.AuditResult {
+	result, err := a.cache.scan(a.scanner, container.Image, digest)
+	var skipped *skippedError
+	if errors.As(err, &skipped) {
+		return []*kubeaudit.AuditResult{{
+			Auditor:  Name,
+			Rule:     ImageScanSkipped,
+			Severity: kubeaudit.Info,
+			Message:  "Image was not scanned for vulnerabilities since kubeaudit runs offline.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Reason":    skipped.reason,
+			},
+		}}
+	}
+	if err != nil {
+		return []*kubeaudit.AuditResult{{
+			Auditor:  Name,
+			Rule:     ImageScanFailed,
+			Severity: kubeaudit.Info,
+			Message:  "Image could not be scanned for vulnerabilities.",
+			Metadata: kubeaudit.Metadata{
+				"Container": container.Name,
+				"Error":     err.Error(),
+			},
+		}}
+	}
+
+	var auditResults []*kubeaudit.AuditResult
+	for _, vulnerability := range a.filter(result.Vulnerabilities) {
+		auditResults = append(auditResults, newVulnerabilityResult(container, vulnerability))
+	}
+	return auditResults
+}
+
+// filter returns the vulnerabilities of the configured severities, without duplicates, from the most to the least
+// severe. Scanners report a vulnerability once for each target of the image which has the package
+func (a *Vulns) filter(vulnerabilities []Vulnerability) []Vulnerability {
+	seen := map[Vulnerability]bool{}
+	var filtered []Vulnerability
+	for _, vulnerability := range vulnerabilities {
+		if !a.severities[vulnerability.Severity] || seen[vulnerability] {
+			continue
+		}
+		seen[vulnerability] = true
+		filtered = append(filtered, vulnerability)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].Severity != filtered[j].Severity {
+			return severityRules[filtered[i].Severity].order < severityRules[filtered[j].Severity].order
+		}
+		return filtered[i].ID < filtered[j].ID
+	})
+	return filtered
+}
+
+func newVulnerabilityResult(container *k8s.ContainerV1, vulnerability Vulnerability) *kubeaudit.AuditResult {
+	severityRule := severityRules[vulnerability.Severity]
+	metadata := kubeaudit.Metadata{
+		"Container":        container.Name,
+		"VulnerabilityID":  vulnerability.ID,
+		"Package":          vulnerability.Package,
+		"InstalledVersion": vulnerability.InstalledVersion,
+	}
+	if vulnerability.URL != "" {
+		metadata["VulnerabilityURL"] = vulnerability.URL
+	}
+
+	fix := "No fixed version is available yet."
+	if vulnerability.FixedVersion != "" {
+		metadata["FixedVersion"] = vulnerability.FixedVersion
+		fix = fmt.Sprintf("The image should be rebuilt with version %s of the package.", vulnerability.FixedVersion)
+	}
+
+	return &kubeaudit.AuditResult{
+		Auditor:  Name,
+		Rule:     severityRule.rule,
+		Severity: severityRule.severity,
+		Message: fmt.Sprintf("Image %s has the %s vulnerability %s in version %s of package %s. %s", container.Image,
+			strings.ToLower(vulnerability.Severity), vulnerability.ID, vulnerability.InstalledVersion, vulnerability.Package, fix),
+		Metadata: metadata,
+	}
+}
+
+// getDigest returns the digest of the image of the container if it is known before scanning the image, from the
+// reference of the image or from the status of the container
+func getDigest(resource k8s.Resource, container *k8s.ContainerV1) string {
+	if ref, err := registry.ParseReference(container.Image); err == nil && ref.Digest != "" {
+		return ref.Digest
+	}
+
+	pod, ok := resource.(*k8s.PodV1)
+	if !ok {
+		return ""
+	}
+	for _, statuses := range [][]apiv1.ContainerStatus{
+		pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses, pod.Status.EphemeralContainerStatuses,
+	} {
+		for _, status := range statuses {
+			if status.Name == container.Name && status.Image == container.Image {
+				return getRepoDigest([]string{status.ImageID})
+			}
+		}
+	}
+	return ""
+}
+
+// cache holds the result of the scan of each image by digest, so images which are used by many containers are only
+// scanned once. Images whose digest is unknown before they are scanned are also cached by reference
+type cache struct {
+	mu       sync.Mutex
+	byDigest map[string]*scan
+	byImage  map[string]*scan
+}
+
+// scan is the scan of an image, which is run once
+type scan struct {
+	once   sync.Once
+	result *ScanResult
+	err    error
+}
+
+func newCache() *cache {
+	return &cache{byDigest: map[string]*scan{}, byImage: map[string]*scan{}}
+}
+
+// scan returns the result of the scan of the image with the digest, which may be empty, and scans it if it was not
+// scanned yet
+func (c *cache) scan(s scanner, image, digest string) (*ScanResult, error) {
+	c.mu.Lock()
+	cached := c.byDigest[digest]
+	if cached == nil {
+		cached = c.byImage[image]
+	}
+	if cached == nil {
+		cached = &scan{}
+		c.byImage[image] = cached
+	}
+	if digest != "" {
+		c.byDigest[digest] = cached
+	}
+	c.mu.Unlock()
+
+	cached.once.Do(func() {
+		cached.result, cached.err = s.Scan(context.Background(), image)
+	})
+	if cached.err != nil {
+		return nil, cached.err
+	}
+
+	// The digest reported by the scanner lets other references of the same image use the scan
+	if cached.result.Digest != "" {
+		c.mu.Lock()
+		if _, ok := c.byDigest[cached.result.Digest]; !ok {
+			c.byDigest[cached.result.Digest] = cached
+		}
+		c.mu.Unlock()
+	}
+	return cached.result, nil
+}
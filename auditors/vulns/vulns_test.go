@@ -0,0 +1,228 @@
+package vulns
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/override"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+// fakeScanner returns the vulnerabilities of the images of the fixtures and counts the scans of each image
+type fakeScanner struct {
+	mu    sync.Mutex
+	scans map[string]int
+}
+
+func (s *fakeScanner) Scan(_ context.Context, image string) (*ScanResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.scans == nil {
+		s.scans = map[string]int{}
+	}
+	s.scans[image]++
+
+	switch image {
+	case "registry.example.com/vulnerable:1.0",
+		"registry.example.com/vulnerable@sha256:1111111111111111111111111111111111111111111111111111111111111111":
+		return &ScanResult{
+			Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+			Vulnerabilities: []Vulnerability{
+				{ID: "CVE-2024-0002", Package: "zlib", InstalledVersion: "1.2.11", Severity: SeverityHigh},
+				{ID: "CVE-2024-0001", Package: "openssl", InstalledVersion: "3.0.1", FixedVersion: "3.0.2", Severity: SeverityCritical, URL: "https://avd.aquasec.com/nvd/cve-2024-0001"},
+				{ID: "CVE-2024-0001", Package: "openssl", InstalledVersion: "3.0.1", FixedVersion: "3.0.2", Severity: SeverityCritical, URL: "https://avd.aquasec.com/nvd/cve-2024-0001"},
+				{ID: "CVE-2024-0003", Package: "curl", InstalledVersion: "7.0.0", Severity: SeverityLow},
+			},
+		}, nil
+	case "registry.example.com/patched:1.0":
+		return &ScanResult{}, nil
+	}
+	return nil, errors.New("image not found")
+}
+
+func TestAuditVulns(t *testing.T) {
+	cases := []struct {
+		file           string
+		expectedErrors []string
+	}{
+		{"vulnerable-image.yml", []string{CriticalVulnerability, HighVulnerability}},
+		{"vulnerable-image-allowed.yml", []string{
+			override.GetOverriddenResultName(CriticalVulnerability),
+			override.GetOverriddenResultName(HighVulnerability),
+		}},
+		{"patched-image.yml", nil},
+		{"vulns-redundant-override.yml", []string{kubeaudit.RedundantAuditorOverride}},
+		{"unscannable-image.yml", []string{ImageScanFailed}},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.file, func(t *testing.T) {
+			t.Parallel()
+			auditor := newVulns(&fakeScanner{}, DefaultSeverities)
+			test.AuditManifest(t, fixtureDir, tc.file, auditor, tc.expectedErrors)
+		})
+	}
+}
+
+func TestAuditVulnsResults(t *testing.T) {
+	scanner := &fakeScanner{}
+	auditor := newVulns(scanner, DefaultSeverities)
+	report := test.AuditManifest(t, fixtureDir, "vulnerable-image.yml", auditor, []string{CriticalVulnerability, HighVulnerability})
+
+	results := report.Results()
+	require.Len(t, results, 1)
+	auditResults := results[0].GetAuditResults()
+	// The duplicate vulnerability is reported once for each container, and the most severe vulnerability first
+	require.Len(t, auditResults, 4)
+	assert.Equal(t, CriticalVulnerability, auditResults[0].Rule)
+	assert.Equal(t, kubeaudit.Error, auditResults[0].Severity)
+	assert.Equal(t, "Image registry.example.com/vulnerable:1.0 has the critical vulnerability CVE-2024-0001 in version 3.0.1 of package openssl. The image should be rebuilt with version 3.0.2 of the package.", auditResults[0].Message)
+	assert.Equal(t, "CVE-2024-0001", auditResults[0].Metadata["VulnerabilityID"])
+	assert.Equal(t, "3.0.2", auditResults[0].Metadata["FixedVersion"])
+	assert.Equal(t, "https://avd.aquasec.com/nvd/cve-2024-0001", auditResults[0].Metadata["VulnerabilityURL"])
+	assert.Equal(t, HighVulnerability, auditResults[1].Rule)
+	assert.Equal(t, kubeaudit.Warn, auditResults[1].Severity)
+	assert.Contains(t, auditResults[1].Message, "No fixed version is available yet.")
+	assert.Equal(t, "container2", auditResults[3].Metadata["Container"])
+
+	// Both containers use the same image, which is only scanned once
+	assert.Equal(t, map[string]int{"registry.example.com/vulnerable:1.0": 1}, scanner.scans)
+}
+
+func TestAuditVulnsSeverities(t *testing.T) {
+	auditor := newVulns(&fakeScanner{}, []string{SeverityLow})
+	test.AuditManifest(t, fixtureDir, "vulnerable-image.yml", auditor, []string{LowVulnerability})
+}
+
+func TestAuditVulnsCacheByDigest(t *testing.T) {
+	scanner := &fakeScanner{}
+	auditor := newVulns(scanner, DefaultSeverities)
+	test.AuditManifest(t, fixtureDir, "images-by-digest.yml", auditor, []string{CriticalVulnerability, HighVulnerability})
+
+	// The second container runs the image of the digest of the first one, according to its status
+	assert.Equal(t, map[string]int{
+		"registry.example.com/vulnerable@sha256:1111111111111111111111111111111111111111111111111111111111111111": 1,
+	}, scanner.scans)
+}
+
+func TestParseTrivy(t *testing.T) {
+	result, err := parseTrivy([]byte(`{
+		"ArtifactName": "nginx:1.25",
+		"Metadata": {"RepoDigests": ["nginx@sha256:abc"]},
+		"Results": [
+			{"Target": "nginx:1.25 (debian 12.1)", "Vulnerabilities": [
+				{"VulnerabilityID": "CVE-2023-1111", "PkgName": "libssl3", "InstalledVersion": "3.0.9", "FixedVersion": "3.0.11", "Severity": "CRITICAL", "PrimaryURL": "https://avd.aquasec.com/nvd/cve-2023-1111"}
+			]},
+			{"Target": "usr/local/bin/app", "Vulnerabilities": null}
+		]
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, &ScanResult{
+		Digest: "sha256:abc",
+		Vulnerabilities: []Vulnerability{
+			{ID: "CVE-2023-1111", Package: "libssl3", InstalledVersion: "3.0.9", FixedVersion: "3.0.11", Severity: SeverityCritical, URL: "https://avd.aquasec.com/nvd/cve-2023-1111"},
+		},
+	}, result)
+
+	_, err = parseTrivy([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestParseGrype(t *testing.T) {
+	result, err := parseGrype([]byte(`{
+		"matches": [
+			{
+				"vulnerability": {"id": "CVE-2023-2222", "severity": "High", "dataSource": "https://nvd.nist.gov/vuln/detail/CVE-2023-2222", "fix": {"versions": ["1.2.13"], "state": "fixed"}},
+				"artifact": {"name": "zlib", "version": "1.2.11"}
+			},
+			{
+				"vulnerability": {"id": "GHSA-xxxx-yyyy-zzzz", "severity": "Medium", "fix": {"versions": [], "state": "not-fixed"}},
+				"artifact": {"name": "golang.org/x/net", "version": "v0.1.0"}
+			}
+		],
+		"source": {"type": "image", "target": {"userInput": "nginx:1.25", "repoDigests": ["nginx@sha256:def"]}}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, &ScanResult{
+		Digest: "sha256:def",
+		Vulnerabilities: []Vulnerability{
+			{ID: "CVE-2023-2222", Package: "zlib", InstalledVersion: "1.2.11", FixedVersion: "1.2.13", Severity: SeverityHigh, URL: "https://nvd.nist.gov/vuln/detail/CVE-2023-2222"},
+			{ID: "GHSA-xxxx-yyyy-zzzz", Package: "golang.org/x/net", InstalledVersion: "v0.1.0", Severity: SeverityMedium},
+		},
+	}, result)
+}
+
+// writeScanner writes a fake scanner executable which records its arguments and prints the output
+func writeScanner(t *testing.T, output string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake scanner is a shell script")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "trivy")
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(dir, "args") + "\ncat <<'EOF'\n" + output + "\nEOF\n"
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func TestNewTrivyServer(t *testing.T) {
+	path := writeScanner(t, `{"Results": []}`)
+	auditor, err := New(Config{Path: path, Server: "http://trivy.example.com:4954"})
+	require.NoError(t, err)
+
+	test.AuditManifest(t, fixtureDir, "patched-image.yml", auditor, nil)
+	args, err := os.ReadFile(filepath.Join(filepath.Dir(path), "args"))
+	require.NoError(t, err)
+	assert.Equal(t, "image --format json --quiet --severity CRITICAL,HIGH --server http://trivy.example.com:4954 registry.example.com/patched:1.0\n", string(args))
+}
+
+func TestNewOffline(t *testing.T) {
+	path := writeScanner(t, `{"Results": []}`)
+	auditor, err := New(Config{Path: path, Offline: true})
+	require.NoError(t, err)
+
+	test.AuditManifest(t, fixtureDir, "patched-image.yml", auditor, nil)
+	args, err := os.ReadFile(filepath.Join(filepath.Dir(path), "args"))
+	require.NoError(t, err)
+	assert.Equal(t, "image --format json --quiet --severity CRITICAL,HIGH --skip-db-update --skip-java-db-update --offline-scan --image-src docker,containerd,podman registry.example.com/patched:1.0\n", string(args))
+}
+
+func TestNewOfflineTrivyServer(t *testing.T) {
+	path := writeScanner(t, `{"Results": []}`)
+	auditor, err := New(Config{Path: path, Server: "http://trivy.example.com:4954", Offline: true})
+	require.NoError(t, err)
+
+	test.AuditManifest(t, fixtureDir, "patched-image.yml", auditor, []string{ImageScanSkipped})
+	_, err = os.Stat(filepath.Join(filepath.Dir(path), "args"))
+	assert.True(t, os.IsNotExist(err), "the scanner should not run")
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	path := writeScanner(t, "")
+	for _, config := range []Config{
+		{Scanner: "clair", Path: path},
+		{Severities: []string{"URGENT"}, Path: path},
+		{Scanner: ScannerGrype, Path: path, Server: "http://trivy.example.com:4954"},
+	} {
+		_, err := New(config)
+		assert.Error(t, err)
+	}
+}
+
+func TestMissingScanner(t *testing.T) {
+	auditor, err := New(Config{Path: filepath.Join(t.TempDir(), "trivy")})
+	require.NoError(t, err)
+
+	test.AuditManifest(t, fixtureDir, "patched-image.yml", auditor, []string{ImageScanFailed})
+}
@@ -3,28 +3,63 @@ package commands
 import (
 	"os"
 
+	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/auditors/all"
 	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/exclusions"
+	"github.com/Shopify/kubeaudit/pkg/plugin"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+// configExclusions are the exclusions of the kubeaudit config, if one is loaded
+var configExclusions *exclusions.Exclusions
+
 var auditAllConfig struct {
 	configFile string
+	watch      bool
 }
 
 func auditAll(cmd *cobra.Command, args []string) {
-	conf := loadKubeAuditConfigFromFile(auditAllConfig.configFile)
+	auditors := getAllAuditors(cmd, auditAllConfig.configFile)
+	if auditAllConfig.watch {
+		runWatch(auditors...)
+		return
+	}
+	runAudit(auditors...)(cmd, args)
+}
+
+// getAllAuditors creates all the auditors enabled in the kubeaudit config file, configured by the config file and the
+// auditor flags of the command
+func getAllAuditors(cmd *cobra.Command, configFile string) []kubeaudit.Auditable {
+	conf := loadKubeAuditConfigFromFile(configFile)
 
 	// Config options set via flags override the config file
 	conf = setConfigFromFlags(cmd, conf)
 
+	registerPodSpecExtractors(conf.CustomResources...)
+	registerExcludedNamespaces(conf.ExcludedNamespaces)
+	registerExclusions(conf.Exclusions)
+	registerSeverities(conf.Severities...)
+	registerNotifications(conf.Notifications, conf.Offline)
+
 	auditors, err := all.Auditors(conf)
 	if err != nil {
 		log.WithError(err).Fatal("Error creating auditors")
 	}
 
-	runAudit(auditors...)(cmd, args)
+	// Plugins found in the PATH are run with the built-in auditors, and their results are merged into the report
+	discovered := plugin.Discover()
+	if err := all.CheckEnabledAuditors(conf, discovered); err != nil {
+		printConfigErrors(configFile, err)
+		log.Fatal("Error parsing config file ", configFile)
+	}
+	plugins, err := all.Plugins(conf, discovered)
+	if err != nil {
+		log.WithError(err).Fatal("Error creating plugins")
+	}
+
+	return append(auditors, plugins...)
 }
 
 func setConfigFromFlags(cmd *cobra.Command, conf config.KubeauditConfig) config.KubeauditConfig {
@@ -37,12 +72,76 @@ func setConfigFromFlags(cmd *cobra.Command, conf config.KubeauditConfig) config.
 		{imageFlagName, imageConfig.Image, &conf.AuditorConfig.Image.Image},
 		{limitCpuFlagName, limitsConfig.CPU, &conf.AuditorConfig.Limits.CPU},
 		{limitMemoryFlagName, limitsConfig.Memory, &conf.AuditorConfig.Limits.Memory},
+		{requestCpuFlagName, requestsConfig.CPU, &conf.AuditorConfig.Requests.CPU},
+		{requestMemoryFlagName, requestsConfig.Memory, &conf.AuditorConfig.Requests.Memory},
+		{pssLevelFlagName, pssConfig.Level, &conf.AuditorConfig.PSS.Level},
+		{encryptionConfigFlagName, etcdConfig.EncryptionConfigPath, &conf.AuditorConfig.Etcd.EncryptionConfigPath},
+		{labelPlaceholderFlagName, labelsConfig.Placeholder, &conf.AuditorConfig.Labels.Placeholder},
+		{policyDirFlagName, regoConfig.PolicyDir, &conf.AuditorConfig.Rego.PolicyDir},
+		{policySeverityFlagName, regoConfig.Severity, &conf.AuditorConfig.Rego.Severity},
+		{scannerFlagName, vulnsConfig.Scanner, &conf.AuditorConfig.Vulns.Scanner},
+		{scannerPathFlagName, vulnsConfig.Path, &conf.AuditorConfig.Vulns.Path},
+		{trivyServerFlagName, vulnsConfig.Server, &conf.AuditorConfig.Vulns.Server},
 	} {
 		if flagset.Changed(item.flag) {
 			*item.configVal = item.flagVal
 		}
 	}
 
+	if flagset.Changed(namespaceDefaultSAFlagName) {
+		conf.AuditorConfig.ASAT.NamespaceDefaultServiceAccount = asatConfig.NamespaceDefaultServiceAccount
+	}
+
+	if flagset.Changed(requiredAnnotationsFlagName) {
+		conf.AuditorConfig.Annotations.Required = getAnnotationsConfig().Required
+	}
+
+	if flagset.Changed(forbiddenAnnotationsFlagName) {
+		conf.AuditorConfig.Annotations.Forbidden = getAnnotationsConfig().Forbidden
+	}
+
+	if flagset.Changed(requiredLabelsFlagName) {
+		conf.AuditorConfig.Labels.Required = getLabelsConfig().Required
+	}
+
+	if flagset.Changed(inspectFlagName) {
+		conf.AuditorConfig.Image.Inspect = imageConfig.Inspect
+	}
+
+	if flagset.Changed(architecturesFlagName) {
+		conf.AuditorConfig.Image.Architectures = imageConfig.Architectures
+	}
+
+	if flagset.Changed(allowedRegistriesFlagName) {
+		conf.AuditorConfig.ImagePolicy.AllowedRegistries = imagePolicyConfig.AllowedRegistries
+	}
+
+	if flagset.Changed(forbiddenPortsFlagName) {
+		conf.AuditorConfig.Ports.ForbiddenPorts = portsConfig.ForbiddenPorts
+	}
+
+	if flagset.Changed(maxLimitRatioFlagName) {
+		conf.AuditorConfig.Requests.MaxLimitRatio = requestsConfig.MaxLimitRatio
+	}
+
+	if flagset.Changed(productionNamespaceSelectorFlagName) {
+		conf.AuditorConfig.Resilience.ProductionNamespaceSelector = resilienceConfig.ProductionNamespaceSelector
+		// The flag is shared by the auditors which report findings in production namespaces
+		conf.AuditorConfig.Ephemeral.ProductionNamespaceSelector = resilienceConfig.ProductionNamespaceSelector
+	}
+
+	if flagset.Changed(allowedRuntimeClassesFlagName) {
+		conf.AuditorConfig.RuntimeClass.Allowed = runtimeClassConfig.Allowed
+	}
+
+	if flagset.Changed(requiredRuntimeClassesFlagName) {
+		conf.AuditorConfig.RuntimeClass.Required = append(conf.AuditorConfig.RuntimeClass.Required, getRuntimeClassConfig().Required...)
+	}
+
+	if flagset.Changed(vulnSeveritiesFlagName) {
+		conf.AuditorConfig.Vulns.Severities = vulnsConfig.Severities
+	}
+
 	if flagset.Changed(capsAddFlagName) {
 		conf.AuditorConfig.Capabilities.AllowAddList = capabilitiesConfig.AllowAddList
 	}
@@ -51,9 +150,61 @@ func setConfigFromFlags(cmd *cobra.Command, conf config.KubeauditConfig) config.
 		conf.AuditorConfig.Mounts.SensitivePaths = mountsConfig.SensitivePaths
 	}
 
+	if flagset.Changed(maxTokenExpirationFlagName) {
+		conf.AuditorConfig.Volumes.MaxTokenExpirationSeconds = volumesConfig.MaxTokenExpirationSeconds
+	}
+
+	if flagset.Changed(allowedCSIDriversFlagName) {
+		conf.AuditorConfig.Volumes.AllowedCSIDrivers = volumesConfig.AllowedCSIDrivers
+	}
+
+	if flagset.Changed(dnsNamespaceFlagName) {
+		conf.AuditorConfig.Egress.DNSNamespace = egressConfig.DNSNamespace
+	}
+
+	if flagset.Changed(dnsPodLabelsFlagName) {
+		conf.AuditorConfig.Egress.DNSPodLabels = egressConfig.DNSPodLabels
+	}
+
+	if flagset.Changed(secretRefSeverityFlagName) {
+		conf.AuditorConfig.Secrets.SecretRefSeverity = secretsConfig.SecretRefSeverity
+	}
+
+	if flagset.Changed(minEntropyFlagName) {
+		conf.AuditorConfig.Secrets.MinEntropy = secretsConfig.MinEntropy
+	}
+
+	if flagset.Changed(daemonSetsFlagName) {
+		conf.AuditorConfig.NodeCoverage.DaemonSets = nodeCoverageConfig.DaemonSets
+	}
+
+	if flagset.Changed(offlineFlagName) {
+		conf.Offline = rootConfig.offline
+	}
+
+	if flagset.Changed(excludeNamespaceFlagName) {
+		conf.ExcludedNamespaces = rootConfig.excludedNamespaces
+	}
+
 	return conf
 }
 
+// registerExcludedNamespaces sets the namespaces excluded by the kubeaudit config in the audit options, which are
+// read from the root flags
+func registerExcludedNamespaces(excludedNamespaces []string) {
+	rootConfig.excludedNamespaces = excludedNamespaces
+}
+
+// registerExclusions sets the exclusions of the kubeaudit config, which leave the findings of the resources they
+// match out of the report
+func registerExclusions(conf config.Exclusions) {
+	var err error
+	configExclusions, err = exclusions.New(conf)
+	if err != nil {
+		log.WithError(err).Fatal("Error parsing the exclusions of the kubeaudit config")
+	}
+}
+
 func loadKubeAuditConfigFromFile(configFile string) config.KubeauditConfig {
 	if configFile == "" {
 		return config.KubeauditConfig{}
@@ -66,7 +217,8 @@ func loadKubeAuditConfigFromFile(configFile string) config.KubeauditConfig {
 
 	conf, err := config.New(reader)
 	if err != nil {
-		log.WithError(err).Fatal("Error parsing config file ", configFile)
+		printConfigErrors(configFile, err)
+		log.Fatal("Error parsing config file ", configFile)
 	}
 
 	return conf
@@ -80,6 +232,9 @@ var auditAllCmd = &cobra.Command{
 Example usage:
 kubeaudit all -f /path/to/yaml
 kubeaudit all -k /path/to/kubeaudit-config.yaml /path/to/yaml
+kubeaudit all -f /path/to/yaml --baseline baseline.json
+kubeaudit all --watch --format json
+kubeaudit all --watch --notify-url https://findings.example.com/kubeaudit --notify-spool-dir /var/lib/kubeaudit/spool
 `,
 	Run: auditAll,
 }
@@ -87,10 +242,31 @@ kubeaudit all -k /path/to/kubeaudit-config.yaml /path/to/yaml
 func init() {
 	RootCmd.AddCommand(auditAllCmd)
 	auditAllCmd.Flags().StringVarP(&auditAllConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+	auditAllCmd.Flags().BoolVar(&auditAllConfig.watch, "watch", false, "Watch the cluster and report findings as workloads are created or updated, until interrupted. Only used in cluster and local mode.")
+	setNotifyFlags(auditAllCmd)
+	setAllAuditorFlags(auditAllCmd)
+}
 
-	// Set flags for the auditors that have them
-	setImageFlags(auditAllCmd)
-	setLimitsFlags(auditAllCmd)
-	setCapabilitiesFlags(auditAllCmd)
-	setPathsFlags(auditAllCmd)
+// setAllAuditorFlags sets the flags for the auditors that have them
+func setAllAuditorFlags(cmd *cobra.Command) {
+	setASATFlags(cmd)
+	setImageFlags(cmd)
+	setLimitsFlags(cmd)
+	setRequestsFlags(cmd)
+	setCapabilitiesFlags(cmd)
+	setPathsFlags(cmd)
+	setNodeCoverageFlags(cmd)
+	setEgressFlags(cmd)
+	setPSSFlags(cmd)
+	setEtcdFlags(cmd)
+	setAnnotationsFlags(cmd)
+	setLabelsFlags(cmd)
+	setResilienceFlags(cmd)
+	setRuntimeClassFlags(cmd)
+	setPortsFlags(cmd)
+	setImagePolicyFlags(cmd)
+	setSecretsFlags(cmd)
+	setVolumesFlags(cmd)
+	setRegoFlags(cmd)
+	setVulnsFlags(cmd)
 }
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/annotations"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var annotationsFlags struct {
+	required  []string
+	forbidden []string
+}
+
+const (
+	requiredAnnotationsFlagName  = "required-annotations"
+	forbiddenAnnotationsFlagName = "forbidden-annotations"
+)
+
+var annotationsCmd = &cobra.Command{
+	Use:   "annotations",
+	Short: "Audit resources that do not follow the annotation policy",
+	Long: `This command determines which workloads and namespaces are missing required annotations or have forbidden
+annotations. Annotations are specified as "key" or "key=value", where the key and value are regular expressions which
+must match the whole annotation key or value. Forbidden annotations are also checked on the pod template.
+
+An ERROR result is generated for each of the following cases:
+  - No annotation matches a required annotation
+  - A required annotation is present but its value does not match
+  - An annotation matches a forbidden annotation
+
+Example usage:
+kubeaudit annotations --required-annotations "owner,data-classification=public|internal"
+kubeaudit annotations --forbidden-annotations "debug\..*,dev.example.com/hot-reload=true"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := annotations.New(getAnnotationsConfig())
+		if err != nil {
+			log.WithError(err).Fatal("failed to create annotations auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func getAnnotationsConfig() annotations.Config {
+	return annotations.Config{
+		Required:  parseAnnotationPatterns(annotationsFlags.required),
+		Forbidden: parseAnnotationPatterns(annotationsFlags.forbidden),
+	}
+}
+
+func parseAnnotationPatterns(values []string) []annotations.Pattern {
+	patterns := make([]annotations.Pattern, 0, len(values))
+	for _, value := range values {
+		patterns = append(patterns, annotations.ParsePattern(value))
+	}
+	return patterns
+}
+
+func setAnnotationsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&annotationsFlags.required, requiredAnnotationsFlagName, nil,
+		"List of annotations which must be present, in the form key or key=value")
+	cmd.Flags().StringSliceVar(&annotationsFlags.forbidden, forbiddenAnnotationsFlagName, nil,
+		"List of annotations which must not be present, in the form key or key=value")
+}
+
+func init() {
+	RootCmd.AddCommand(annotationsCmd)
+	setAnnotationsFlags(annotationsCmd)
+}
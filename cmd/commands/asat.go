@@ -5,6 +5,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var asatConfig asat.Config
+
+const namespaceDefaultSAFlagName = "namespace-default-sa"
+
 var asatCmd = &cobra.Command{
 	Use:     "asat",
 	Aliases: []string{"sat"},
@@ -18,11 +22,23 @@ An ERROR result is generated when a container matches one of the following:
 
 A WARN result is generated when a pod is found using the deprecated 'serviceAccount' field.
 
+With the '--namespace-default-sa' flag, a WARN result is also generated when the default service account of a
+namespace does not set automountServiceAccountToken to false.
+
 Example usage:
-kubeaudit asat`,
-	Run: runAudit(asat.New()),
+kubeaudit asat
+kubeaudit asat --namespace-default-sa`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runAudit(asat.NewWithConfig(asatConfig))(cmd, args)
+	},
+}
+
+func setASATFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&asatConfig.NamespaceDefaultServiceAccount, namespaceDefaultSAFlagName, false,
+		"Also audit namespaces whose default service account automounts its token")
 }
 
 func init() {
 	RootCmd.AddCommand(asatCmd)
+	setASATFlags(asatCmd)
 }
@@ -1,69 +1,437 @@
 package commands
 
 import (
-	"io"
+	"bytes"
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
 
+	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/internal/atomicfile"
+	"github.com/Shopify/kubeaudit/internal/diff"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
+const (
+	dryRunNone   = "none"
+	dryRunServer = "server"
+)
+
 var autofixConfig struct {
 	outFile             string
+	tempDir             string
 	kubeauditConfigFile string
+	cluster             bool
+	dryRun              string
+	diff                bool
+	fix                 []string
+	skip                []string
 }
 
 func autofix(cmd *cobra.Command, args []string) {
+	if rootConfig.kustomize != "" {
+		log.Fatal("Autofix is not supported for kustomizations. Use -f/--manifest instead")
+	}
+	if rootConfig.gitURL != "" {
+		log.Fatal("Autofix is not supported for Git repositories. Use -f/--manifest with a checkout of the repository instead")
+	}
+
+	// The chart templates can't be patched, so the fixed rendered manifest has to be written elsewhere
+	if rootConfig.helmChart != "" && autofixConfig.outFile == "" && !autofixConfig.diff {
+		log.Fatal("Autofix for Helm charts requires an output file. Use -o/--outfile to specify one")
+	}
+
+	// Resources in a cluster can't be fixed in place, so the patches which fix them are written instead unless the
+	// fixed resources are applied to the cluster
+	patchMode := len(rootConfig.manifests) == 0 && rootConfig.helmChart == ""
+
+	if autofixConfig.cluster && !patchMode {
+		log.Fatal("--cluster is only supported in cluster and local mode")
+	}
+	if autofixConfig.diff && patchMode {
+		log.Fatal("--diff is only supported in manifest mode")
+	}
+	if autofixConfig.diff && autofixConfig.outFile != "" {
+		log.Fatal("--diff writes the diff to stdout and can't be used with -o/--outfile")
+	}
+	if autofixConfig.dryRun != dryRunNone && autofixConfig.dryRun != dryRunServer {
+		log.Fatalf("invalid --dry-run %q, expected one of \"none\", \"server\"", autofixConfig.dryRun)
+	}
+	if autofixConfig.dryRun == dryRunServer && !autofixConfig.cluster {
+		log.Fatal("--dry-run=server requires --cluster")
+	}
+	// Server-side dry runs are not persisted, so they don't change the cluster
+	if autofixConfig.cluster && autofixConfig.dryRun != dryRunServer {
+		checkReadOnly("Applying fixes to the cluster")
+	}
+	checkFixSelection("--fix", autofixConfig.fix)
+	checkFixSelection("--skip", autofixConfig.skip)
+
 	conf := loadKubeAuditConfigFromFile(autofixConfig.kubeauditConfigFile)
 
 	conf = setConfigFromFlags(cmd, conf)
 
+	registerPodSpecExtractors(conf.CustomResources...)
+	registerExcludedNamespaces(conf.ExcludedNamespaces)
+	registerExclusions(conf.Exclusions)
+	registerSeverities(conf.Severities...)
+
 	auditors, err := all.Auditors(conf)
 
 	if err != nil {
 		log.WithError(err).Fatal("Error creating auditors")
 	}
 
-	report := getReport(auditors...)
+	// The manifest path is cleared when the manifest is read from stdin
+	manifestName := manifestName()
+
+	// The resources excluded by the config are not fixed either
+	report := applyExclusions(getReport(auditors...)).SelectFixes(autofixConfig.fix, autofixConfig.skip)
+
+	if autofixConfig.cluster {
+		applyFixes(report)
+		return
+	}
+
+	if patchMode {
+		writePatches(report)
+		return
+	}
+
+	if rootConfig.helmChart == "" && !isStdinManifest() && isManifestTree() {
+		fixManifestTree(initKubeaudit(auditors...), report)
+		return
+	}
+
+	var fixed bytes.Buffer
+	err = report.Fix(&fixed)
+	if err != nil {
+		log.WithError(err).Fatal("Error fixing manifest")
+	}
+
+	if autofixConfig.diff {
+		changed := writeDiff(report, fixed.Bytes(), manifestName)
+		verifyFix(initKubeaudit(auditors...), report, fixed.Bytes())
+		if changed {
+			os.Exit(1)
+		}
+		return
+	}
 
-	var f io.Writer
+	switch {
+	case autofixConfig.outFile != "":
+		writeManifest(autofixConfig.outFile, fixed.Bytes())
+	case isStdinManifest():
+		// A manifest read from stdin can't be fixed in place, so the fixed manifest is written to stdout
+		if _, err := os.Stdout.Write(fixed.Bytes()); err != nil {
+			log.WithError(err).Fatal("Error writing fixed manifest")
+		}
+	default:
+		writeManifest(rootConfig.manifests[0], fixed.Bytes())
+	}
+
+	verifyFix(initKubeaudit(auditors...), report, fixed.Bytes())
+}
+
+// manifestName returns the name of the audited manifest used in diffs
+func manifestName() string {
+	switch {
+	case rootConfig.helmChart != "":
+		return rootConfig.helmChart
+	case isStdinManifest():
+		return "stdin"
+	case len(rootConfig.manifests) == 1:
+		return rootConfig.manifests[0]
+	}
+	return ""
+}
+
+// fixManifestTree fixes the manifest files of directories, glob patterns and multiple -f/--manifest flags. Changed
+// files are written in place, or to the same relative path within the -o/--outfile directory so it mirrors the audited
+// tree. Files which the fixes don't change are left alone. With --diff, the diff of each changed file is written to
+// stdout instead
+func fixManifestTree(auditor *kubeaudit.Kubeaudit, report *kubeaudit.Report) {
+	fixedManifests, err := report.FixManifests()
+	if err != nil {
+		log.WithError(err).Fatal("Error fixing manifests")
+	}
+
+	rels := map[string]string{}
+	for _, file := range findManifests() {
+		rels[file.Path] = file.Rel
+	}
+
+	changed := 0
+	for _, fixedManifest := range fixedManifests {
+		if bytes.Equal(fixedManifest.Original, fixedManifest.Fixed) {
+			continue
+		}
+		changed++
+
+		switch {
+		case autofixConfig.diff:
+			name := filepath.ToSlash(fixedManifest.Path)
+			unified, err := diff.Unified(fixedManifest.Original, fixedManifest.Fixed, "a/"+name, "b/"+name)
+			if err != nil {
+				log.WithError(err).Fatalf("Error creating the diff of %s", fixedManifest.Path)
+			}
+			if _, err := fmt.Fprint(os.Stdout, unified); err != nil {
+				log.WithError(err).Fatal("Error writing diff")
+			}
+		case autofixConfig.outFile != "":
+			out := filepath.Join(autofixConfig.outFile, rels[fixedManifest.Path])
+			if err := os.MkdirAll(filepath.Dir(out), 0755); err != nil {
+				log.WithError(err).Fatal("Error creating out directory")
+			}
+			writeManifest(out, fixedManifest.Fixed)
+		default:
+			writeManifest(fixedManifest.Path, fixedManifest.Fixed)
+		}
+	}
+
+	if !autofixConfig.diff {
+		fmt.Fprintf(os.Stderr, "%d of %d manifest files fixed\n", changed, len(fixedManifests))
+	}
+
+	unresolved, err := auditor.VerifyFixManifests(report, fixedManifests)
+	if err != nil {
+		log.WithError(err).Fatal("Error verifying fixed manifests")
+	}
+	if len(unresolved.Results()) > 0 {
+		unresolved.PrintResults(
+			kubeaudit.WithWriter(os.Stderr),
+			kubeaudit.WithMinSeverity(kubeaudit.Info),
+			kubeaudit.WithColor(!rootConfig.noColor),
+		)
+		log.Fatal("Autofix did not resolve all of the findings it fixed")
+	}
+
+	if autofixConfig.diff && changed > 0 {
+		os.Exit(1)
+	}
+}
+
+// writeManifest writes a fixed manifest atomically, so an interrupted run never leaves a partially written manifest
+// behind. The temporary file is written to --temp-dir, or next to the manifest if it isn't set
+func writeManifest(path string, fixed []byte) {
+	if err := atomicfile.WriteFile(path, fixed, 0644, autofixConfig.tempDir); err != nil {
+		log.WithError(err).Fatalf("Error writing fixed manifest %s", path)
+	}
+}
+
+// writeDiff writes the unified diff from the audited manifest to the fixed manifest to stdout and returns true if
+// the fixes change the manifest. For Helm charts, the diff is of the rendered manifest
+func writeDiff(report *kubeaudit.Report, fixed []byte, name string) bool {
+	// The manifest is split into the documents of the results, so joining them gives the audited manifest back
+	var documents [][]byte
+	for _, result := range report.RawResults() {
+		documents = append(documents, result.GetResource().Bytes())
+	}
+	original := bytes.Join(documents, []byte("---"))
+
+	unified, err := diff.Unified(original, fixed, "a/"+name, "b/"+name)
+	if err != nil {
+		log.WithError(err).Fatal("Error creating the diff of the fixed manifest")
+	}
+	if _, err := fmt.Fprint(os.Stdout, unified); err != nil {
+		log.WithError(err).Fatal("Error writing diff")
+	}
+	return unified != ""
+}
+
+// verifyFix audits the fixed manifest again and exits with a non-zero exit code if any finding targeted by a fix
+// was not resolved, so that broken fixes are not mistaken for a secure manifest
+func verifyFix(auditor *kubeaudit.Kubeaudit, report *kubeaudit.Report, fixed []byte) {
+	unresolved, err := auditor.VerifyFix(report, fixed)
+	if err != nil {
+		log.WithError(err).Fatal("Error verifying fixed manifest")
+	}
+
+	if len(unresolved.Results()) == 0 {
+		return
+	}
+
+	unresolved.PrintResults(
+		kubeaudit.WithWriter(os.Stderr),
+		kubeaudit.WithMinSeverity(kubeaudit.Info),
+		kubeaudit.WithColor(!rootConfig.noColor),
+	)
+	log.Fatal("Autofix did not resolve all of the findings it fixed")
+}
+
+// writePatches writes the kubectl commands which fix the findings of a cluster to the out file, or to stdout if no
+// out file is specified
+func writePatches(report *kubeaudit.Report) {
+	f := os.Stdout
 	if autofixConfig.outFile != "" {
+		var err error
 		f, err = os.Create(autofixConfig.outFile)
 		if err != nil {
 			log.WithError(err).Fatal("Error opening out file")
 		}
+		defer f.Close()
+	}
+
+	if err := report.WritePatches(f); err != nil {
+		log.WithError(err).Fatal("Error writing patches")
+	}
+}
+
+// applyFixes applies the fixed resources to the cluster with server-side apply and writes the diff of each applied
+// resource to the out file, or to stdout if no out file is specified
+func applyFixes(report *kubeaudit.Report) {
+	options := kubeaudit.ApplyOptions{DryRun: autofixConfig.dryRun == dryRunServer, ConnectionOptions: getConnectionOptions()}
+
+	var appliedFixes []kubeaudit.AppliedFix
+	var err error
+	if k8sinternal.UseInClusterConfig(k8sinternal.DefaultClient, rootConfig.kubeConfig) {
+		appliedFixes, err = report.ApplyFixesCluster(options)
 	} else {
-		f, err = os.OpenFile(rootConfig.manifest, os.O_WRONLY|os.O_TRUNC, 0755)
+		appliedFixes, err = report.ApplyFixesLocal(rootConfig.kubeConfig, getContext(), options)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Error applying fixes")
+	}
+
+	f := os.Stdout
+	if autofixConfig.outFile != "" {
+		f, err = os.Create(autofixConfig.outFile)
 		if err != nil {
-			log.WithError(err).Fatal("Error opening manifest file")
+			log.WithError(err).Fatal("Error opening out file")
 		}
+		defer f.Close()
 	}
 
-	err = report.Fix(f)
-	if err != nil {
-		log.WithError(err).Fatal("Error fixing manifest")
+	appliedName := "applied"
+	if options.DryRun {
+		appliedName = "dry run"
+	}
+
+	failed := 0
+	for _, appliedFix := range appliedFixes {
+		name := resourceName(appliedFix.Fixed)
+		if appliedFix.Err != nil {
+			log.WithError(appliedFix.Err).Errorf("Error applying %s", name)
+			failed++
+			continue
+		}
+
+		original, err := diff.ResourceYAML(appliedFix.Original)
+		if err != nil {
+			log.WithError(err).Fatalf("Error encoding %s", name)
+		}
+		applied, err := diff.ResourceYAML(appliedFix.Applied)
+		if err != nil {
+			log.WithError(err).Fatalf("Error encoding %s", name)
+		}
+		unified, err := diff.Unified(original, applied, fmt.Sprintf("%s (live)", name), fmt.Sprintf("%s (%s)", name, appliedName))
+		if err != nil {
+			log.WithError(err).Fatalf("Error creating the diff of %s", name)
+		}
+		if _, err := fmt.Fprint(f, unified); err != nil {
+			log.WithError(err).Fatal("Error writing diff")
+		}
+	}
+
+	if failed > 0 {
+		log.Fatalf("Failed to apply %d of %d fixed resources", failed, len(appliedFixes))
 	}
 }
 
+// resourceName returns the kind, namespace and name of the resource, eg. "Deployment default/web"
+func resourceName(resource k8s.Resource) string {
+	kind := resource.GetObjectKind().GroupVersionKind().Kind
+	objectMeta := k8s.GetObjectMeta(resource)
+	if objectMeta == nil {
+		return kind
+	}
+	if objectMeta.GetNamespace() == "" {
+		return fmt.Sprintf("%s %s", kind, objectMeta.GetName())
+	}
+	return fmt.Sprintf("%s %s/%s", kind, objectMeta.GetNamespace(), objectMeta.GetName())
+}
+
+// checkFixSelection exits if an entry of --fix or --skip is neither an auditor nor a rule. Rules can't be listed
+// without running the auditors, so entries which start with an uppercase letter are assumed to be rules
+func checkFixSelection(flag string, names []string) {
+	for _, name := range names {
+		if isAuditorName(name) || (name != "" && unicode.IsUpper(rune(name[0]))) {
+			continue
+		}
+		log.Fatalf("invalid %s %q, expected the name of an auditor or a rule", flag, name)
+	}
+}
+
+func isAuditorName(name string) bool {
+	for _, auditorNames := range [][]string{all.AuditorNames, all.OptionalAuditorNames} {
+		for _, auditorName := range auditorNames {
+			if strings.EqualFold(name, auditorName) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 var autofixCmd = &cobra.Command{
 	Use:   "autofix",
 	Short: "Automagically make a manifest secure",
 	Long: `This command automatically fixes all identified security issues for a given manifest
 (ie. all ERROR results generated by 'kubeaudit all'). If no output file is specified using the -o flag,
-the source manifest will be modified. You can use the -k flag followed by the path to the kubeaudit
-config file to run fixes based on custom rules.
+the source manifest will be modified, or the fixed manifest is written to stdout if it was read
+from stdin. When directories, glob patterns or several -f flags are audited,
+the changed manifest files are modified in place, or the -o flag is the directory the changed files are
+written to, mirroring the layout of the audited tree. Files which are not changed by the fixes are left alone.
+Fixed manifests are written to a temporary file which is renamed into place once complete, so an interrupted
+run never leaves a partially written manifest. Use the --temp-dir flag to create the temporary files in another
+directory, which must be on the same file system as the manifests. You can use the -k flag followed by the path to the kubeaudit
+config file to run fixes based on custom rules. The fixed manifest is audited again, and the command exits
+with a non-zero exit code if any of the fixed findings is still reported. Use the --diff flag to print a
+unified diff of the fixes instead of writing the fixed manifest. The command then exits with exit code 1
+if the fixes would change the manifest.
+
+Use the --fix flag to only apply the fixes of some auditors or rules, and the --skip flag to leave out the
+fixes of others, so findings which are accepted are not fixed. Skipped findings are still reported.
+
+In cluster and local mode, resources can't be fixed in place. Instead, the kubectl commands which patch
+the resources are written to stdout, or to the file specified using the -o flag. Only some fixes, such as
+disabling automountServiceAccountToken on the default ServiceAccount of each namespace, support patches.
+
+With the --cluster flag, the live resources are fixed and applied back to the cluster with server-side
+apply instead, and the diff of each applied resource is written. Use --dry-run=server to preview the
+changes, as validated and defaulted by the API server, without persisting them.
 
 Example usage:
 kubeaudit autofix -f /path/to/yaml
 kubeaudit autofix -f /path/to/yaml -o /path/for/fixed/yaml
+kubeaudit autofix -f /path/to/yaml --diff
+kubeaudit autofix -f /path/to/manifests -o /path/for/fixed/manifests
+kubeaudit autofix -f /path/to/manifests -f '/path/to/other/*.yaml' --diff
+kubeaudit autofix -f /path/to/yaml --fix capabilities,seccomp --skip limits
+kubeaudit autofix -f /path/to/yaml --skip ReadOnlyRootFilesystemNil
 kubeaudit autofix -k /path/to/kubeaudit-config.yaml -f /path/to/yaml
+kubeaudit autofix --helm /path/to/chart --values /path/to/values.yaml -o /path/for/fixed/yaml
+kubeaudit autofix --kubeconfig /path/to/kubeconfig -o /path/for/patches.sh
+kubeaudit autofix --cluster --dry-run=server
+kubeaudit autofix --kubeconfig /path/to/kubeconfig --cluster -n my-namespace
 `,
 	Run: autofix,
 }
 
 func init() {
 	RootCmd.AddCommand(autofixCmd)
-	autofixCmd.Flags().StringVarP(&autofixConfig.outFile, "outfile", "o", "", "File to write fixed manifest to")
+	autofixCmd.Flags().StringVarP(&autofixConfig.outFile, "outfile", "o", "", "File to write fixed manifest, or the patches or diffs in cluster and local mode, to. When a directory tree of manifests is fixed, the directory to write the fixed manifest files to")
+	autofixCmd.Flags().StringVar(&autofixConfig.tempDir, "temp-dir", "", "Directory to write the temporary files of fixed manifests to before they are renamed into place. Must be on the same file system as the manifests. Defaults to the directory of each manifest")
 	autofixCmd.Flags().StringVarP(&autofixConfig.kubeauditConfigFile, "kconfig", "k", "", "Path to kubeaudit config")
+	autofixCmd.Flags().BoolVar(&autofixConfig.diff, "diff", false, "Print a unified diff of the fixes to stdout instead of writing the fixed manifest, and exit with exit code 1 if the manifest would change. Only used in manifest mode")
+	autofixCmd.Flags().StringSliceVar(&autofixConfig.fix, "fix", nil, "Only apply the fixes of these auditors or rules (eg. \"capabilities,SeccompProfileMissing\"). All fixes are applied if not set")
+	autofixCmd.Flags().StringSliceVar(&autofixConfig.skip, "skip", nil, "Don't apply the fixes of these auditors or rules (eg. \"limits,ReadOnlyRootFilesystemNil\")")
+	autofixCmd.Flags().BoolVar(&autofixConfig.cluster, "cluster", false, "Apply the fixed resources to the cluster with server-side apply instead of writing patches. Only used in cluster and local mode")
+	autofixCmd.Flags().StringVar(&autofixConfig.dryRun, "dry-run", dryRunNone, "Apply the fixed resources in server-side dry-run mode so they are not persisted (one of \"none\", \"server\"). Requires --cluster")
 }
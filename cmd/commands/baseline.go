@@ -0,0 +1,67 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Shopify/kubeaudit/internal/baseline"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var baselineGenerateConfig struct {
+	outFile    string
+	configFile string
+}
+
+func generateBaseline(cmd *cobra.Command, args []string) {
+	report := getReport(getAllAuditors(cmd, baselineGenerateConfig.configFile)...)
+	knownFindings := baseline.New(report)
+
+	f, err := os.Create(baselineGenerateConfig.outFile)
+	if err != nil {
+		log.WithError(err).Fatal("Error opening baseline file")
+	}
+	defer f.Close()
+
+	if err := knownFindings.Write(f); err != nil {
+		log.WithError(err).Fatal("Error writing baseline file")
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d findings to the baseline %s\n", len(knownFindings.Findings), baselineGenerateConfig.outFile)
+}
+
+var baselineCmd = &cobra.Command{
+	Use:   "baseline",
+	Short: "Manage baselines of known findings",
+	Long: `A baseline is a snapshot of the findings of an audit. When a baseline is passed to an audit with the --baseline
+flag, only findings which are not in the baseline are reported. This allows kubeaudit to be adopted on existing
+clusters and manifests by failing only on new findings, while the known findings are fixed over time.
+
+A finding is matched by its auditor, rule and metadata, and by the kind, namespace and name of the resource. The image
+and index of the container and the blame of its lines are left out of the metadata, so updating an image, reordering
+containers or changing the lines of a manifest does not make a known finding new. A finding whose other details
+change, such as the name of its container, is reported as a new finding.`,
+}
+
+var baselineGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate a baseline from the current findings",
+	Long: `This command runs all audits and writes their findings to a baseline file. The kubeaudit config and auditor
+flags are the same as for 'kubeaudit all', and should match the ones used with the baseline.
+
+Example usage:
+kubeaudit baseline generate -f /path/to/yaml
+kubeaudit baseline generate -k /path/to/kubeaudit-config.yaml -o /path/to/baseline.json
+kubeaudit all -k /path/to/kubeaudit-config.yaml --baseline /path/to/baseline.json
+`,
+	Run: generateBaseline,
+}
+
+func init() {
+	RootCmd.AddCommand(baselineCmd)
+	baselineCmd.AddCommand(baselineGenerateCmd)
+	baselineGenerateCmd.Flags().StringVarP(&baselineGenerateConfig.outFile, "outfile", "o", "baseline.json", "File to write the baseline to")
+	baselineGenerateCmd.Flags().StringVarP(&baselineGenerateConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+	setAllAuditorFlags(baselineGenerateCmd)
+}
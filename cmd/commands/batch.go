@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Shopify/kubeaudit/internal/batch"
+)
+
+var batchConfig struct {
+	configFile string
+}
+
+func runBatch(cmd *cobra.Command, args []string) {
+	// The auditors are created first, since the minimum severity can be a custom severity of the kubeaudit config
+	auditor := initKubeaudit(getAllAuditors(cmd, batchConfig.configFile)...)
+	registerCustomResourceFlags()
+
+	if err := batch.Serve(auditor, os.Stdin, os.Stdout, batch.Config{MinSeverity: getMinSeverity()}); err != nil {
+		log.WithError(err).Fatal("Error reading batch requests")
+	}
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Audit manifests sent as line-delimited JSON requests on stdin, for editor integrations",
+	Long: `Audit manifests sent as line-delimited JSON requests on stdin and write a line-delimited JSON response with the
+diagnostics of each manifest to stdout, so editor plugins can audit files as they are edited with a single kubeaudit
+process. The diagnostics are LSP diagnostics, with zero-based ranges.
+
+Each request is a JSON object on a single line, with the "path" of the file and its "content". The "id" of the
+request, if any, is returned in its response. Invalid requests are answered with an "error" and do not stop the
+command, which exits when stdin is closed.
+
+Example usage:
+echo '{"id": 1, "path": "pod.yaml", "content": "apiVersion: v1\nkind: Pod\n..."}' | kubeaudit batch
+kubeaudit batch -k /path/to/kubeaudit-config.yaml --minseverity warning`,
+	Run: runBatch,
+}
+
+func init() {
+	RootCmd.AddCommand(batchCmd)
+	batchCmd.Flags().StringVarP(&batchConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+	setAllAuditorFlags(batchCmd)
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/history"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var compareConfig struct {
+	configFile string
+}
+
+func runCompare(cmd *cobra.Command, args []string) {
+	conf := loadKubeAuditConfigFromFile(compareConfig.configFile)
+	registerSeverities(conf.Severities...)
+
+	older, err := history.Load(args[0])
+	if err != nil {
+		log.WithError(err).Fatal("Error loading the older report")
+	}
+	newer, err := history.Load(args[1])
+	if err != nil {
+		log.WithError(err).Fatal("Error loading the newer report")
+	}
+	comparison := history.Compare(older, newer)
+
+	switch rootConfig.format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(comparison)
+	case "pretty":
+		err = comparison.Write(os.Stdout, !rootConfig.noColor)
+	default:
+		log.Fatalf("invalid --format %q, expected one of \"pretty\", \"json\"", rootConfig.format)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Error writing the comparison")
+	}
+
+	if !rootConfig.noFail && hasNewFindingsToFailOn(comparison) {
+		os.Exit(rootConfig.exitCode)
+	}
+}
+
+// hasNewFindingsToFailOn returns true if a new finding has the severity set with --fail-on or higher
+func hasNewFindingsToFailOn(comparison *history.Comparison) bool {
+	failOn := getFailOn()
+	for _, finding := range comparison.New {
+		severity, err := kubeaudit.ParseSeverity(finding.Severity)
+		if err != nil {
+			log.WithError(err).Fatal("Unknown severity of a new finding, custom severities must be defined in the kubeaudit config set with -k")
+		}
+		if severity.AtLeast(failOn) {
+			return true
+		}
+	}
+	return false
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare <older report> <newer report>",
+	Short: "Compare the findings of two reports saved with --save-report",
+	Long: `This command compares the findings of two reports saved with the --save-report flag of an audit, such as the
+reports of two weekly audits, and lists the findings which are new, fixed and persisting in the newer report. Findings
+are matched the same way as with baselines: by their auditor, rule and metadata, and by the kind, namespace and name
+of their resource.
+
+kubeaudit exits with the code set with --exitcode if there are new findings with the severity set with --fail-on or
+higher, so CI only fails on newly introduced findings. Custom severities of the findings must be defined in the
+kubeaudit config set with -k.
+
+Example usage:
+kubeaudit all -f /path/to/yaml --save-report reports/2024-06-01.json
+kubeaudit compare reports/2024-05-25.json reports/2024-06-01.json
+kubeaudit compare reports/2024-05-25.json reports/2024-06-01.json --format json
+`,
+	Args: cobra.ExactArgs(2),
+	Run:  runCompare,
+}
+
+func init() {
+	RootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().StringVarP(&compareConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+}
@@ -0,0 +1,116 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/pkg/plugin"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func validateConfig(cmd *cobra.Command, args []string) {
+	path := args[0]
+	reader, err := os.Open(path)
+	if err != nil {
+		log.WithError(err).Fatal("Unable to open config file ", path)
+	}
+	defer reader.Close()
+
+	conf, err := config.New(reader)
+	if err == nil {
+		err = checkConfig(conf)
+	}
+	if err != nil {
+		printConfigErrors(path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("%s is valid\n", path)
+}
+
+// checkConfig returns the problems of a loaded config which depend on the auditors and plugins
+func checkConfig(conf config.KubeauditConfig) error {
+	if err := all.CheckEnabledAuditors(conf, plugin.Discover()); err != nil {
+		return err
+	}
+	for _, definition := range conf.Severities {
+		if _, err := kubeaudit.RegisterSeverity(definition); err != nil {
+			return err
+		}
+	}
+	if _, err := all.Auditors(conf); err != nil {
+		return err
+	}
+	return nil
+}
+
+// printConfigErrors prints the errors of a config file to stderr, one per line and prefixed with the path and line of
+// the config file like the errors of compilers, so editors can jump to them
+func printConfigErrors(path string, err error) {
+	var errs config.Errors
+	if !errors.As(err, &errs) {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", path, err)
+		return
+	}
+	for _, e := range errs {
+		if e.Line == 0 {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, e.Message)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%s:%d: %s\n", path, e.Line, e.Message)
+	}
+}
+
+func printConfigSchema(cmd *cobra.Command, args []string) {
+	schema, err := config.Schema()
+	if err != nil {
+		log.WithError(err).Fatal("Error generating the config schema")
+	}
+	os.Stdout.Write(schema)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Validate kubeaudit config files",
+	Long: `kubeaudit config files are checked strictly when they are loaded: unknown fields, such as misspelled fields or
+auditors, and values which are not one of the values a field accepts, such as unknown severities or capabilities, are
+errors.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate <config file>",
+	Short: "Validate a kubeaudit config file",
+	Long: `This command checks a kubeaudit config file without running an audit. Each problem is printed with the line of
+the config file it is at, and kubeaudit exits with 1 if there are any. Auditors of enabledAuditors must be built-in
+auditors or plugins in the PATH.
+
+Example usage:
+kubeaudit config validate /path/to/kubeaudit-config.yaml
+`,
+	Args: cobra.ExactArgs(1),
+	Run:  validateConfig,
+}
+
+var configSchemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON schema of kubeaudit config files",
+	Long: `This command prints the JSON schema of kubeaudit config files, generated from the config of the version of
+kubeaudit. Editors with YAML language support use the schema to complete and check config files. The schema of the
+main branch is published at ` + config.SchemaID + `
+
+Example usage:
+kubeaudit config schema > kubeaudit-config.schema.json
+`,
+	Args: cobra.NoArgs,
+	Run:  printConfigSchema,
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configCmd.AddCommand(configSchemaCmd)
+}
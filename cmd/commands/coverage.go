@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"os"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/coverage"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var coverageConfig struct {
+	configFile string
+}
+
+// resourceLister is an auditor without results, so the resources of an audit can be listed without auditing them
+type resourceLister struct{}
+
+func (resourceLister) Audit(_ k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	return nil, nil
+}
+
+func runCoverage(cmd *cobra.Command, args []string) {
+	conf := loadKubeAuditConfigFromFile(coverageConfig.configFile)
+	registerPodSpecExtractors(conf.CustomResources...)
+	registerExcludedNamespaces(conf.ExcludedNamespaces)
+
+	report := getReport(resourceLister{})
+	if err := coverage.Create(report, conf).Write(os.Stdout, !rootConfig.noColor); err != nil {
+		log.WithError(err).Fatal("Error writing the coverage report")
+	}
+}
+
+var coverageCmd = &cobra.Command{
+	Use:   "coverage",
+	Short: "List the auditors which apply to each kind of resource, and why the others are skipped",
+	Long: `List, for each kind of resource found in the manifests or the cluster, the auditors which apply to the resources
+of the kind and the auditors which are skipped, to show the blind spots of the current setup. An auditor is skipped
+because:
+  - unsupported kind: the auditor doesn't audit resources of the kind
+  - os mismatch: the auditor only checks Linux settings, and the pod runs on Windows
+  - config: the auditor is disabled by the kubeaudit config, or has nothing to check with it
+
+Example usage:
+kubeaudit coverage
+kubeaudit coverage -f /path/to/yaml
+kubeaudit coverage -k /path/to/kubeaudit-config.yaml -f /path/to/yaml
+`,
+	Run: runCoverage,
+}
+
+func init() {
+	RootCmd.AddCommand(coverageCmd)
+	coverageCmd.Flags().StringVarP(&coverageConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+}
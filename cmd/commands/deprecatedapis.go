@@ -24,7 +24,8 @@ An INFO result is generated for API version not yet deprecated in the current ve
 
 Example usage:
 kubeaudit deprecatedapis
-kubeaudit deprecatedapis --current-k8s-version 1.22 --targeted-k8s-version 1.24`,
+kubeaudit deprecatedapis --current-k8s-version 1.22 --targeted-k8s-version 1.24
+kubeaudit deprecatedapis --targeted-k8s-version 1.29`,
 	Run: func(cmd *cobra.Command, args []string) {
 		auditor, err := deprecatedapis.New(deprecatedapisConfig)
 		if err != nil {
@@ -0,0 +1,142 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/color"
+	"github.com/Shopify/kubeaudit/internal/doctor"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+)
+
+// doctorTimeout is the timeout of each request to the API server, so an unreachable server is reported quickly
+const doctorTimeout = 10 * time.Second
+
+var doctorConfig struct {
+	configFile string
+}
+
+func runDoctor(cmd *cobra.Command, args []string) {
+	checks := []doctor.Check{}
+	report := func(check doctor.Check) {
+		checks = append(checks, check)
+		printCheck(check)
+	}
+
+	configCheck, conf := doctor.CheckConfig(doctorConfig.configFile)
+	report(configCheck)
+	if conf == nil {
+		conf = &config.KubeauditConfig{}
+	}
+
+	var restConfig *rest.Config
+	if k8sinternal.UseInClusterConfig(k8sinternal.DefaultClient, rootConfig.kubeConfig) {
+		restConfig, _ = k8sinternal.DefaultClient.InClusterConfig()
+		report(doctor.Check{Name: "kubeconfig", Message: "Running inside the cluster, using the service account of the pod"})
+	} else {
+		var kubeconfigCheck doctor.Check
+		kubeconfigCheck, restConfig = doctor.CheckKubeconfig(rootConfig.kubeConfig, getContext())
+		report(kubeconfigCheck)
+	}
+
+	if restConfig != nil {
+		restConfig.Timeout = doctorTimeout
+		restConfig.WarningHandler = rest.NoWarnings{}
+		clientset, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			log.WithError(err).Fatal("Error creating the Kubernetes client")
+		}
+
+		apiServerCheck, serverVersion := doctor.CheckAPIServer(clientset.Discovery(), restConfig.Host)
+		report(apiServerCheck)
+		if serverVersion != nil {
+			report(doctor.CheckVersionSkew(serverVersion, clientGoVersion()))
+
+			var namespaces []string
+			if rootConfig.namespace != "" {
+				namespaces = strings.Split(rootConfig.namespace, ",")
+			}
+			for _, check := range doctor.CheckPermissions(clientset.AuthorizationV1().SelfSubjectAccessReviews(), all.EnabledAuditorNames(*conf), namespaces) {
+				report(check)
+			}
+		}
+	}
+
+	counts := map[doctor.Status]int{}
+	for _, check := range checks {
+		counts[check.Status]++
+	}
+	fmt.Printf("\n%d checks: %d ok, %d warnings, %d errors\n", len(checks), counts[doctor.OK], counts[doctor.Warning], counts[doctor.Failure])
+	if counts[doctor.Failure] > 0 {
+		os.Exit(1)
+	}
+}
+
+func printCheck(check doctor.Check) {
+	status := fmt.Sprintf("[%s]", check.Status)
+	if !rootConfig.noColor {
+		switch check.Status {
+		case doctor.OK:
+			status = color.Green(status)
+		case doctor.Warning:
+			status = color.Yellow(status)
+		case doctor.Failure:
+			status = color.Red(status)
+		}
+	}
+
+	fmt.Printf("%s %s: %s\n", status, check.Name, check.Message)
+	if check.Fix != "" {
+		fmt.Printf("    Fix: %s\n", check.Fix)
+	}
+}
+
+// clientGoVersion returns the version of the Kubernetes client libraries kubeaudit is built with, eg. "v0.24.3"
+func clientGoVersion() string {
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == "k8s.io/client-go" {
+			return dep.Version
+		}
+	}
+	return ""
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the kubeconfig, API server access, permissions and kubeaudit config",
+	Long: `Diagnose the environment kubeaudit runs in, and print how to fix the problems found.
+
+The doctor checks that:
+  - the kubeaudit config file is valid and has no unknown settings or auditors
+  - the kubeconfig file and context can be used (in local mode)
+  - the API server is reachable and accepts the credentials
+  - the Kubernetes version of the server is within one minor version of the one kubeaudit is built for
+  - each enabled auditor can list the resources it audits, in the namespaces set with -n/--namespace
+
+The doctor exits with 1 if any check fails.
+
+Example usage:
+kubeaudit doctor
+kubeaudit doctor -k /path/to/kubeaudit-config.yaml --context prod -n payments
+`,
+	Run: runDoctor,
+}
+
+func init() {
+	RootCmd.AddCommand(doctorCmd)
+	doctorCmd.Flags().StringVarP(&doctorConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+}
@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/spf13/cobra"
+)
+
+var egressConfig egress.Config
+
+const (
+	dnsNamespaceFlagName = "dns-namespace"
+	dnsPodLabelsFlagName = "dns-pod-labels"
+)
+
+var egressCmd = &cobra.Command{
+	Use:   "egress",
+	Short: "Audit namespaces and workloads without egress restrictions",
+	Long: `This command determines which namespaces and workloads have no NetworkPolicy restricting their egress traffic.
+
+An ERROR result is generated when a namespace does not have a default deny egress NetworkPolicy, or when a
+NetworkPolicy selecting all pods in the namespace allows all egress traffic.
+
+A WARN result is generated when the pods of a workload are not selected by any NetworkPolicy restricting egress
+traffic.
+
+The autofix for namespaces creates a default deny egress NetworkPolicy which still allows DNS traffic to the
+cluster DNS pods. These are selected with the '--dns-namespace' and '--dns-pod-labels' flags.
+
+Example usage:
+kubeaudit egress
+kubeaudit egress --dns-namespace kube-system --dns-pod-labels k8s-app=kube-dns`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runAudit(egress.New(egressConfig))(cmd, args)
+	},
+}
+
+func setEgressFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&egressConfig.DNSNamespace, dnsNamespaceFlagName, egress.DefaultDNSNamespace,
+		"Namespace of the cluster DNS pods which egress traffic is allowed to")
+	cmd.Flags().StringToStringVar(&egressConfig.DNSPodLabels, dnsPodLabelsFlagName, egress.DefaultDNSPodLabels,
+		"Labels of the cluster DNS pods which egress traffic is allowed to")
+}
+
+func init() {
+	RootCmd.AddCommand(egressCmd)
+	setEgressFlags(egressCmd)
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var ephemeralConfig ephemeral.Config
+
+var ephemeralCmd = &cobra.Command{
+	Use:   "ephemeral",
+	Short: "Audit ephemeral debug containers running in production namespaces",
+	Long: `This command determines which pods in production namespaces have running ephemeral containers, such as the
+debug containers added by 'kubectl debug'. Ephemeral containers can't be removed from a pod and are not part of the
+manifest of its workload, so they are usually debugging sessions which were left behind.
+
+A WARN result is generated for each ephemeral container which is not terminated in a pod in a production namespace.
+
+Production namespaces are selected by their labels using '--production-namespace-selector'. Ephemeral containers
+only exist in running pods, so this auditor is meant to be run in cluster mode. The security context of ephemeral
+containers is audited by the other auditors, like the one of any other container.
+
+Example usage:
+kubeaudit ephemeral
+kubeaudit ephemeral --production-namespace-selector "environment=prod"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := ephemeral.New(ephemeralConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create ephemeral auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setEphemeralFlags(cmd *cobra.Command) {
+	cmd.Flags().StringToStringVar(&ephemeralConfig.ProductionNamespaceSelector, productionNamespaceSelectorFlagName,
+		ephemeral.DefaultProductionNamespaceSelector, "Labels which select the production namespaces")
+}
+
+func init() {
+	RootCmd.AddCommand(ephemeralCmd)
+	setEphemeralFlags(ephemeralCmd)
+}
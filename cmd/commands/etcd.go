@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/etcd"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var etcdConfig etcd.Config
+
+const encryptionConfigFlagName = "encryption-config"
+
+var etcdCmd = &cobra.Command{
+	Use:   "etcd",
+	Short: "Audit secrets encryption at rest and etcd exposure",
+	Long: `This command inspects the kube-apiserver and etcd pods (such as the static pods of the control plane) to
+determine whether secrets are encrypted at rest and whether etcd is exposed to unauthenticated clients. In local and
+cluster mode, the control plane pods are only visible with sufficient permissions for the kube-system namespace.
+
+An ERROR result is generated for each of the following cases:
+  - The kube-apiserver does not set '--encryption-provider-config'
+  - The encryption configuration specified with '--encryption-config' does not encrypt secrets
+  - The kube-apiserver connects to etcd without TLS or without a client certificate
+  - etcd does not require client certificates or serves clients without TLS
+
+A WARN result is generated when etcd does not require peer certificates.
+
+Example usage:
+kubeaudit etcd
+kubeaudit etcd -f /etc/kubernetes/manifests/kube-apiserver.yaml --encryption-config /etc/kubernetes/enc/encryption-config.yaml`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := etcd.New(etcdConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create etcd auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setEtcdFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&etcdConfig.EncryptionConfigPath, encryptionConfigFlagName, "",
+		"Path to a copy of the kube-apiserver EncryptionConfiguration file to inspect")
+}
+
+func init() {
+	RootCmd.AddCommand(etcdCmd)
+	setEtcdFlags(etcdCmd)
+}
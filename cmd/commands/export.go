@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/playbook"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var exportPlaybookConfig struct {
+	outFile    string
+	configFile string
+	format     string
+}
+
+func exportPlaybook(cmd *cobra.Command, args []string) {
+	if exportPlaybookConfig.format != playbook.FormatMarkdown && exportPlaybookConfig.format != playbook.FormatHTML {
+		log.Fatalf("invalid --format %q, expected one of %q, %q", exportPlaybookConfig.format, playbook.FormatMarkdown, playbook.FormatHTML)
+	}
+	minSeverity, err := kubeaudit.ParseSeverity(rootConfig.minSeverity)
+	if err != nil {
+		log.WithError(err).Fatal("invalid --minseverity")
+	}
+
+	report := applyExclusions(getReport(getAllAuditors(cmd, exportPlaybookConfig.configFile)...))
+	if rootConfig.baseline != "" {
+		report = applyBaseline(report, rootConfig.baseline)
+	}
+
+	book, err := playbook.Create(report, minSeverity)
+	if err != nil {
+		log.WithError(err).Fatal("Error creating the playbook")
+	}
+
+	f := os.Stdout
+	if exportPlaybookConfig.outFile != "" {
+		f, err = os.Create(exportPlaybookConfig.outFile)
+		if err != nil {
+			log.WithError(err).Fatal("Error opening out file")
+		}
+		defer f.Close()
+	}
+
+	if err := book.Write(f, exportPlaybookConfig.format); err != nil {
+		log.WithError(err).Fatal("Error writing the playbook")
+	}
+
+	if exportPlaybookConfig.outFile != "" {
+		fmt.Fprintf(os.Stderr, "Wrote %d remediations of %d findings to the playbook %s\n", len(book.Remediations), book.Findings, exportPlaybookConfig.outFile)
+	}
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the findings of an audit as documents",
+}
+
+var exportPlaybookCmd = &cobra.Command{
+	Use:   "playbook",
+	Short: "Export a remediation playbook of the findings",
+	Long: `This command runs all audits and writes a remediation playbook of their findings, ready to hand to the teams
+which own the resources as a work package. The findings are grouped by rule, from the most to the least severe, with a
+step for each affected resource. Each step has the fix to make and, when autofix can fix the finding, the change it
+makes to the YAML of the resource as an example.
+
+The kubeaudit config and auditor flags are the same as for 'kubeaudit all'. Overridden findings and findings below
+--minseverity are left out, and only new findings are in the playbook when a baseline is passed with --baseline.
+
+Example usage:
+kubeaudit export playbook -f /path/to/yaml > playbook.md
+kubeaudit export playbook --format html -k /path/to/kubeaudit-config.yaml -o playbook.html
+kubeaudit export playbook --minseverity warning --baseline /path/to/baseline.json
+`,
+	Run: exportPlaybook,
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportPlaybookCmd)
+	exportPlaybookCmd.Flags().StringVarP(&exportPlaybookConfig.outFile, "outfile", "o", "", "File to write the playbook to (default is stdout)")
+	exportPlaybookCmd.Flags().StringVarP(&exportPlaybookConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+	exportPlaybookCmd.Flags().StringVarP(&exportPlaybookConfig.format, "format", "p", playbook.FormatMarkdown, "The format of the playbook (one of \"markdown\", \"html\")")
+	setAllAuditorFlags(exportPlaybookCmd)
+}
@@ -0,0 +1,26 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/exposure"
+	"github.com/spf13/cobra"
+)
+
+var exposureCmd = &cobra.Command{
+	Use:   "exposure",
+	Short: "Audit Services and Ingresses exposed outside of the cluster",
+	Long: `This command determines which Services are exposed outside of the cluster to any client, and which Ingresses
+serve hosts without TLS. Services of type NodePort expose their ports on every node, and Services of type LoadBalancer
+should restrict the source ranges of their clients with loadBalancerSourceRanges or the
+service.beta.kubernetes.io/load-balancer-source-ranges annotation, unless they are internal load balancers.
+
+A WARN result is generated for each Service of type NodePort, each Service of type LoadBalancer without source ranges
+and each Ingress with hosts not covered by its tls.
+
+Example usage:
+kubeaudit exposure`,
+	Run: runAudit(exposure.New()),
+}
+
+func init() {
+	RootCmd.AddCommand(exposureCmd)
+}
@@ -0,0 +1,27 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/hostnet"
+	"github.com/spf13/cobra"
+)
+
+var hostnetCmd = &cobra.Command{
+	Use:   "hostnet",
+	Short: "Audit containers binding host ports and pods overriding host name resolution",
+	Long: `This command determines which containers bind ports of the node with hostPort, and which pods set hostAliases or
+use the 'ClusterFirstWithHostNet' DNS policy without hostNetwork.
+
+An ERROR result is generated when a container sets hostPort on one of its ports.
+
+A WARN result is generated when a pod:
+  - sets hostAliases, which add entries to the hosts file of the pod
+  - sets dnsPolicy to 'ClusterFirstWithHostNet' without setting hostNetwork to 'true'
+
+Example usage:
+kubeaudit hostnet`,
+	Run: runAudit(hostnet.New()),
+}
+
+func init() {
+	RootCmd.AddCommand(hostnetCmd)
+}
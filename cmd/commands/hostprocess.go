@@ -0,0 +1,24 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/hostprocess"
+	"github.com/spf13/cobra"
+)
+
+var hostprocessCmd = &cobra.Command{
+	Use:   "hostprocess",
+	Short: "Audit Windows pods running as HostProcess containers",
+	Long: `This command determines which Windows pods and containers set hostProcess to 'true' in the windowsOptions of
+their SecurityContext. HostProcess containers run directly on the host with the privileges of the node, like
+privileged containers on Linux.
+
+An ERROR result is generated for each pod and container with hostProcess set to 'true'.
+
+Example usage:
+kubeaudit hostprocess`,
+	Run: runAudit(hostprocess.New()),
+}
+
+func init() {
+	RootCmd.AddCommand(hostprocessCmd)
+}
@@ -7,7 +7,11 @@ import (
 
 var imageConfig image.Config
 
-const imageFlagName = "image"
+const (
+	imageFlagName         = "image"
+	inspectFlagName       = "inspect"
+	architecturesFlagName = "architectures"
+)
 
 var imageCmd = &cobra.Command{
 	Use:   "image",
@@ -18,18 +22,33 @@ An ERROR result is generated when a container does not match the image:tag
 
 An INFO result is generated when a container has a matching image:tag.
 
+With '--inspect', the config of each image is fetched from its registry, with the credentials of the Docker config
+file, and cached by digest. The following results are generated:
+  - A WARN result when the image runs as root and the container sets neither runAsUser nor runAsNonRoot
+  - An ERROR result when runAsNonRoot is set but the image runs as root or as a user name, so the container won't start
+  - A WARN result when the image has a HEALTHCHECK, which Kubernetes ignores, and the container has no livenessProbe
+  - A WARN result when a distroless image, which has no shell, runs a command or probe with a shell
+  - An ERROR result when the image is not built for the architecture of nodes the pod can run on
+  - An INFO result when the image config can't be fetched
+
 This command is also a root command, check 'kubeaudit image --help'.
 
 Example usage:
 kubeaudit image --image gcr.io/google_containers/echoserver:1.7
-kubeaudit image -i gcr.io/google_containers/echoserver:1.7`,
+kubeaudit image -i gcr.io/google_containers/echoserver:1.7
+kubeaudit image --inspect --architectures amd64,arm64`,
 	Run: func(cmd *cobra.Command, args []string) {
+		imageConfig.Offline = rootConfig.offline
 		runAudit(image.New(imageConfig))(cmd, args)
 	},
 }
 
 func setImageFlags(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&imageConfig.Image, imageFlagName, "i", "", "Image to check against")
+	cmd.Flags().BoolVar(&imageConfig.Inspect, inspectFlagName, false,
+		"Fetch the image configs from the registries to check their user, HEALTHCHECK and architectures")
+	cmd.Flags().StringSliceVar(&imageConfig.Architectures, architecturesFlagName, nil,
+		"Architectures images must be built for, in addition to those of the audited nodes (eg. \"amd64,arm64\")")
 }
 
 func init() {
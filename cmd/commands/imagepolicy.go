@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var imagePolicyConfig imagepolicy.Config
+
+const allowedRegistriesFlagName = "allowed-registries"
+
+var imagePolicyCmd = &cobra.Command{
+	Use:   "imagepolicy",
+	Short: "Audit containers pulling images from unapproved registries or not pinned to a digest",
+	Long: `This command determines which containers pull images from registries which are not allowed, or use images
+which can change without the workload changing. This auditor is optional, so it is only run by 'kubeaudit all' if it
+is enabled in the kubeaudit config.
+
+An ERROR result is generated for each of the following cases:
+  - The image repository does not match any of the '--allowed-registries'
+  - The image uses the 'latest' tag, or has no tag which defaults to 'latest'
+
+A WARN result is generated when the image has a tag but is not pinned to a digest.
+
+Allowed registries match the whole repository of the image, including the registry. A '*' matches any characters,
+and an entry without a '*' also matches the repositories under it. Images without a registry are pulled from Docker
+Hub, so 'nginx' is matched as 'docker.io/library/nginx'. If no registries are allowed, images from any registry are
+allowed.
+
+Example usage:
+kubeaudit imagepolicy
+kubeaudit imagepolicy --allowed-registries "gcr.io/mycorp/*,registry.mycorp.com"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := imagepolicy.New(imagePolicyConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create imagepolicy auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setImagePolicyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&imagePolicyConfig.AllowedRegistries, allowedRegistriesFlagName, nil,
+		"List of registries and repositories which images may be pulled from (eg. \"gcr.io/mycorp/*\")")
+}
+
+func init() {
+	RootCmd.AddCommand(imagePolicyCmd)
+	setImagePolicyFlags(imagePolicyCmd)
+}
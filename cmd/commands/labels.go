@@ -0,0 +1,65 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/labels"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var labelsConfig labels.Config
+
+var labelsFlags struct {
+	required []string
+}
+
+const (
+	requiredLabelsFlagName   = "required-labels"
+	labelPlaceholderFlagName = "label-placeholder"
+)
+
+var labelsCmd = &cobra.Command{
+	Use:   "labels",
+	Short: "Audit resources that are missing required labels",
+	Long: `This command determines which workloads and namespaces are missing required labels or have labels with invalid
+values. Labels are specified as "key" or "key=value", where the value is a regular expression which must match the whole
+label value. Labels which only apply to some namespaces can be specified with a namespace selector in the kubeaudit
+config.
+
+An ERROR result is generated for each of the following cases:
+  - A required label is missing
+  - A required label is present but its value does not match
+
+Autofix adds missing labels with a placeholder value. A WARN result is generated for each label which still has the
+placeholder value, until it is reviewed and replaced with a real value.
+
+Example usage:
+kubeaudit labels --required-labels "app.kubernetes.io/name,team=team-[a-z]+"
+kubeaudit labels --required-labels "team" --label-placeholder "todo"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := labels.New(getLabelsConfig())
+		if err != nil {
+			log.WithError(err).Fatal("failed to create labels auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func getLabelsConfig() labels.Config {
+	config := labelsConfig
+	for _, value := range labelsFlags.required {
+		config.Required = append(config.Required, labels.ParseRule(value))
+	}
+	return config
+}
+
+func setLabelsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&labelsFlags.required, requiredLabelsFlagName, nil,
+		"List of labels which must be present, in the form key or key=value")
+	cmd.Flags().StringVar(&labelsConfig.Placeholder, labelPlaceholderFlagName, labels.DefaultPlaceholder,
+		"Value of the labels added by autofix")
+}
+
+func init() {
+	RootCmd.AddCommand(labelsCmd)
+	setLabelsFlags(labelsCmd)
+}
@@ -0,0 +1,30 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/lifecycle"
+	"github.com/spf13/cobra"
+)
+
+var lifecycleCmd = &cobra.Command{
+	Use:   "lifecycle",
+	Short: "Audit workloads with lifecycle anti-patterns",
+	Long: `This command determines which workloads have lifecycle settings which cause abrupt kills or pods which are
+never cleaned up. This auditor is optional, so it is only run by 'kubeaudit all' if it is enabled in the kubeaudit
+config.
+
+An ERROR result is generated for each of the following cases:
+  - A Deployment, StatefulSet, DaemonSet or ReplicationController sets a restartPolicy other than Always
+  - A Deployment, StatefulSet, DaemonSet or ReplicationController sets activeDeadlineSeconds
+
+A WARN result is generated for each of the following cases:
+  - terminationGracePeriodSeconds is set to 0
+  - A Job which is not created by a CronJob does not set ttlSecondsAfterFinished
+
+Example usage:
+kubeaudit lifecycle`,
+	Run: runAudit(lifecycle.New()),
+}
+
+func init() {
+	RootCmd.AddCommand(lifecycleCmd)
+}
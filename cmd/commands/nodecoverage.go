@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
+	"github.com/spf13/cobra"
+)
+
+var nodeCoverageConfig nodecoverage.Config
+
+const daemonSetsFlagName = "daemonsets"
+
+var nodeCoverageCmd = &cobra.Command{
+	Use:   "nodecoverage",
+	Short: "Audit nodes not covered by security-critical DaemonSets",
+	Long: `This command determines which nodes are not covered by the security-critical DaemonSets specified with the
+'--daemonsets' flag (for example falco or a node agent). Each DaemonSet is specified either by name or as
+"namespace/name". If no DaemonSets are specified, no results are generated.
+
+An ERROR result is generated for each of the following cases:
+  - A security-critical DaemonSet is not found
+  - A security-critical DaemonSet cannot be scheduled on a node because of its node selector, required node
+    affinity or a node taint it does not tolerate
+
+A WARN result is generated when a security-critical DaemonSet does not tolerate all taints.
+
+Example usage:
+kubeaudit nodecoverage --daemonsets falco,kube-system/node-agent`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runAudit(nodecoverage.New(nodeCoverageConfig))(cmd, args)
+	},
+}
+
+func setNodeCoverageFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&nodeCoverageConfig.DaemonSets, daemonSetsFlagName, nil,
+		"Comma separated list of security-critical DaemonSets which should run on every node")
+}
+
+func init() {
+	RootCmd.AddCommand(nodeCoverageCmd)
+	setNodeCoverageFlags(nodeCoverageCmd)
+}
@@ -0,0 +1,112 @@
+package commands
+
+import (
+	"context"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/notify"
+)
+
+var notifyConfig notify.Config
+
+// notifications are the receivers set in the kubeaudit config file
+var notifications []config.NotificationConfig
+
+// notificationsOffline is true if the kubeaudit config file sets offline, so notifications are not sent
+var notificationsOffline bool
+
+func registerNotifications(configs []config.NotificationConfig, offline bool) {
+	notifications = configs
+	notificationsOffline = offline
+}
+
+// setNotifyFlags sets the flags of the commands which send new findings to a notification endpoint
+func setNotifyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&notifyConfig.URL, "notify-url", "", "URL to POST new findings to as JSON, in batches. Failed requests are retried with exponential backoff.")
+	cmd.Flags().IntVar(&notifyConfig.BatchSize, "notify-batch-size", notify.DefaultBatchSize, "Maximum number of findings sent to a notification receiver in a request")
+	cmd.Flags().DurationVar(&notifyConfig.FlushInterval, "notify-flush-interval", notify.DefaultFlushInterval, "Longest time new findings are held before being sent to the notification receivers")
+	cmd.Flags().IntVar(&notifyConfig.Retries, "notify-retries", 5, "Number of times a request to a notification receiver is retried before the findings are kept for the next flush")
+	cmd.Flags().StringVar(&notifyConfig.SpoolDir, "notify-spool-dir", "", "Directory to keep the findings which were not sent to --notify-url in, so they are sent after kubeaudit restarts")
+}
+
+// startNotifier sends the findings passed to the returned notifiers until ctx is done. The returned function waits for
+// the last findings to be sent or spooled once ctx is done
+func startNotifier(ctx context.Context) (notify.Notifiers, func()) {
+	notifiers := newNotifiers()
+	if len(notifiers) == 0 {
+		return nil, func() {}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		notifiers.Run(ctx)
+		close(done)
+	}()
+	return notifiers, func() { <-done }
+}
+
+// notifyFindings sends the findings of a single audit to the notification receivers and waits for them to be sent
+func notifyFindings(report *kubeaudit.Report) {
+	notifiers := newNotifiers()
+	if len(notifiers) == 0 {
+		return
+	}
+	notifiers.Notify(notify.Findings(report, getMinSeverity())...)
+	notifiers.Flush()
+}
+
+// newNotifiers returns a notifier for --notify-url and for each receiver of the kubeaudit config file. The receivers
+// of the config file are sent batches the same way, but their findings are not spooled
+func newNotifiers() notify.Notifiers {
+	if rootConfig.offline || notificationsOffline {
+		if notifyConfig.URL != "" || len(notifications) > 0 {
+			log.Warn("Notifications are not sent offline, because the receivers are accessed over the network")
+		}
+		return nil
+	}
+
+	var notifiers notify.Notifiers
+	if notifyConfig.URL != "" {
+		notifiers = append(notifiers, newNotifier(notifyConfig))
+	}
+	for _, notification := range notifications {
+		notifiers = append(notifiers, newNotifier(notify.Config{
+			Type:          notification.Type,
+			URL:           os.ExpandEnv(notification.URL),
+			RoutingKey:    os.ExpandEnv(notification.RoutingKey),
+			MinSeverity:   notification.MinSeverity,
+			Template:      notification.Template,
+			BatchSize:     notifyConfig.BatchSize,
+			FlushInterval: notifyConfig.FlushInterval,
+			Retries:       notifyConfig.Retries,
+		}))
+	}
+	return notifiers
+}
+
+func newNotifier(config notify.Config) *notify.Notifier {
+	notifier, err := notify.New(config)
+	if err != nil {
+		log.WithError(err).Fatal("Error configuring notifications")
+	}
+	return notifier
+}
+
+// getNewFindings returns the findings whose fingerprints are not in previous, along with the fingerprints of all the
+// findings to compare the next findings to
+func getNewFindings(findings []notify.Finding, previous map[string]bool) ([]notify.Finding, map[string]bool) {
+	var newFindings []notify.Finding
+	fingerprints := map[string]bool{}
+	for _, finding := range findings {
+		if !previous[finding.Fingerprint] && !fingerprints[finding.Fingerprint] {
+			newFindings = append(newFindings, finding)
+		}
+		fingerprints[finding.Fingerprint] = true
+	}
+	return newFindings, fingerprints
+}
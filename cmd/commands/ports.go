@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/ports"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var portsConfig ports.Config
+
+const forbiddenPortsFlagName = "forbidden-ports"
+
+var portsCmd = &cobra.Command{
+	Use:   "ports",
+	Short: "Audit containers exposing privileged or forbidden ports",
+	Long: `This command determines which containers expose privileged ports (below 1024) or ports forbidden by the
+kubeaudit config, and which Services target ports that no container of the pods they select declares.
+
+An ERROR result is generated when a container exposes a forbidden port, as a container port or a host port.
+
+A WARN result is generated for each of the following cases:
+  - A container exposes a privileged port
+  - A Service targets a port which is not declared by any container of the pods it selects
+
+Services are only compared to the workloads they select which are audited, so in manifest mode the workloads have to
+be in the manifest.
+
+Example usage:
+kubeaudit ports
+kubeaudit ports --forbidden-ports 2375,2376`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := ports.New(portsConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create ports auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setPortsFlags(cmd *cobra.Command) {
+	cmd.Flags().IntSliceVar(&portsConfig.ForbiddenPorts, forbiddenPortsFlagName, nil,
+		"List of ports which containers must not expose")
+}
+
+func init() {
+	RootCmd.AddCommand(portsCmd)
+	setPortsFlags(portsCmd)
+}
@@ -0,0 +1,70 @@
+package commands
+
+import (
+	"os"
+	"runtime/pprof"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Shopify/kubeaudit"
+)
+
+// profileTop is the number of slowest auditors and resources printed with --profile
+const profileTop = 10
+
+// auditProfile records the time the auditors take when profiling with --profile or --cpu-profile
+var auditProfile *kubeaudit.Profile
+
+// cpuProfile is the file the CPU profile set with --cpu-profile is written to
+var cpuProfile *os.File
+
+// getProfileOptions returns the options which record the time the auditors take in auditProfile, if profiling is
+// enabled. Auditors are profiled with --cpu-profile too, so they run with the pprof label of their name
+func getProfileOptions() []kubeaudit.Option {
+	if !rootConfig.profile && rootConfig.cpuProfile == "" {
+		return nil
+	}
+	if auditProfile == nil {
+		auditProfile = kubeaudit.NewProfile()
+	}
+	return []kubeaudit.Option{kubeaudit.WithProfile(auditProfile)}
+}
+
+// startCPUProfile starts writing a pprof CPU profile to the file set with --cpu-profile
+func startCPUProfile() {
+	if rootConfig.cpuProfile == "" {
+		return
+	}
+	file, err := os.Create(rootConfig.cpuProfile)
+	if err != nil {
+		log.WithError(err).Fatal("Error creating the CPU profile")
+	}
+	if err := pprof.StartCPUProfile(file); err != nil {
+		log.WithError(err).Fatal("Error starting the CPU profile")
+	}
+	cpuProfile = file
+}
+
+// stopCPUProfile stops the CPU profile started with startCPUProfile() and closes its file
+func stopCPUProfile() {
+	if cpuProfile == nil {
+		return
+	}
+	pprof.StopCPUProfile()
+	if err := cpuProfile.Close(); err != nil {
+		log.WithError(err).Fatal("Error writing the CPU profile")
+	}
+	cpuProfile = nil
+}
+
+// writeProfile prints the slowest auditors and resources to stderr with --profile, so the output of the audit can still
+// be parsed
+func writeProfile() {
+	if !rootConfig.profile || auditProfile == nil {
+		return
+	}
+	os.Stderr.WriteString("\n")
+	if err := auditProfile.Write(os.Stderr, profileTop); err != nil {
+		log.WithError(err).Fatal("Error writing the profile")
+	}
+}
@@ -0,0 +1,45 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var pssConfig pss.Config
+
+const pssLevelFlagName = "pss-level"
+
+var pssCmd = &cobra.Command{
+	Use:   "pss",
+	Short: "Audit workloads against the Pod Security Standards",
+	Long: `This command evaluates workloads against the Pod Security Standards controls and reports the most restrictive
+level (privileged, baseline or restricted) each workload satisfies.
+
+An ERROR result is generated for each failed control of the level specified with the '--pss-level' flag or a lower
+level. The default level is "restricted".
+
+An INFO result is generated for each failed control of a level above the specified level, and for each workload with
+the level it satisfies.
+
+Example usage:
+kubeaudit pss
+kubeaudit pss --pss-level baseline`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := pss.New(pssConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create Pod Security Standards auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setPSSFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&pssConfig.Level, pssLevelFlagName, pss.LevelRestricted,
+		"Pod Security Standards level workloads are required to satisfy (one of \"baseline\", \"restricted\")")
+}
+
+func init() {
+	RootCmd.AddCommand(pssCmd)
+	setPSSFlags(pssCmd)
+}
@@ -0,0 +1,38 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/spf13/cobra"
+)
+
+var rbacCmd = &cobra.Command{
+	Use:   "rbac",
+	Short: "Audit roles which allow privilege escalation and workloads with dangerous RBAC grants",
+	Long: `This command determines which Roles and ClusterRoles allow their subjects to escalate privileges.
+
+An ERROR result is generated when a Role or ClusterRole:
+  - grants the 'bind' or 'escalate' verbs on roles or clusterroles
+  - grants the 'impersonate' verb on users, groups, service accounts or other identity attributes
+  - has an aggregation rule with an empty selector, which aggregates every ClusterRole into it
+  - has an aggregation rule selecting a ClusterRole which grants any of the above verbs
+
+Wildcards in verbs, API groups and resources are taken into account. The default roles created by Kubernetes
+  (labelled 'kubernetes.io/bootstrapping: rbac-defaults') are not audited.
+
+An ERROR result is also generated when the service account of a workload is bound by a RoleBinding or
+ClusterRoleBinding to a role which:
+  - is the cluster-admin ClusterRole
+  - grants wildcard verbs or resources
+  - allows reading secrets, if it is bound by a ClusterRoleBinding
+
+In cluster mode the bindings and roles of the cluster are used. In manifest mode only the RoleBindings,
+ClusterRoleBindings, Roles and ClusterRoles in the same manifest are used.
+
+Example usage:
+kubeaudit rbac`,
+	Run: runAudit(rbac.New()),
+}
+
+func init() {
+	RootCmd.AddCommand(rbacCmd)
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/rego"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var regoConfig rego.Config
+
+const (
+	policyDirFlagName      = "policy-dir"
+	policySeverityFlagName = "policy-severity"
+)
+
+var regoCmd = &cobra.Command{
+	Use:   "rego",
+	Short: "Audit resources against Rego policies",
+	Long: `This command evaluates the Rego policies of '--policy-dir' against each resource, so organizations can add
+their own rules to kubeaudit. The policies are evaluated with the OPA executable, which must be in the PATH.
+
+Each policy gets the resource as its input. A result is generated for each result of the 'deny' rules (the convention
+of conftest) and 'violation' rules (the convention of Gatekeeper) of each package. A result can be a message, or an
+object with a 'msg', and optionally a 'rule', a 'severity' and 'details' which are reported as metadata. The rule
+defaults to the last part of the package in CamelCase, eg. 'RequiredLabels' for 'package kubeaudit.required_labels'.
+
+An ERROR result is generated for each result of a policy which doesn't set a severity, or a result of the severity
+set by '--policy-severity'.
+
+Example usage:
+kubeaudit rego --policy-dir ./policies
+kubeaudit rego --policy-dir ./policies --policy-severity warning`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := rego.New(regoConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create rego auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setRegoFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&regoConfig.PolicyDir, policyDirFlagName, "", "Directory of the Rego policies to evaluate against each resource")
+	cmd.Flags().StringVar(&regoConfig.Severity, policySeverityFlagName, "error", "Severity of the results of policies which don't set one (error, warning, info or a custom severity)")
+}
+
+func init() {
+	RootCmd.AddCommand(regoCmd)
+	setRegoFlags(regoCmd)
+}
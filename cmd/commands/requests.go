@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var requestsConfig requests.Config
+
+const (
+	requestCpuFlagName    = "request-cpu"
+	requestMemoryFlagName = "request-memory"
+	maxLimitRatioFlagName = "max-limit-ratio"
+)
+
+var requestsCmd = &cobra.Command{
+	Use:   "requests",
+	Short: "Audit containers which don't request CPU and memory, or whose requests don't match their limits",
+	Long: `This command determines which containers have no CPU or memory requests configured, or requests which are
+inconsistent with their limits. This auditor is optional, so it is only run by 'kubeaudit all' if it is enabled in
+the kubeaudit config.
+
+An ERROR result is generated for each of the following cases:
+  - The CPU or memory request exceeds the limit
+
+A WARN result is generated for each of the following cases:
+  - The CPU or memory request is unset. It is reported as INFO if the limit is set, since the limit is used as the request
+  - The CPU or memory limit is more than '--max-limit-ratio' times the request
+
+The autofix sets the missing requests to '--request-cpu' and '--request-memory', lowered to the limits if needed, and
+lowers requests which exceed the limits to the limits.
+
+Example usage:
+kubeaudit requests
+kubeaudit requests --max-limit-ratio 4
+kubeaudit autofix -f "/path/to/manifest.yml" --fix requests --request-cpu 250m --request-memory 256Mi`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := requests.New(requestsConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create requests auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setRequestsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&requestsConfig.CPU, requestCpuFlagName, requests.DefaultCPU, "CPU request set by autofix on containers which don't have one")
+	cmd.Flags().StringVar(&requestsConfig.Memory, requestMemoryFlagName, requests.DefaultMemory, "Memory request set by autofix on containers which don't have one")
+	cmd.Flags().Float64Var(&requestsConfig.MaxLimitRatio, maxLimitRatioFlagName, requests.DefaultMaxLimitRatio, "Max ratio of the CPU and memory limits to the requests")
+}
+
+func init() {
+	RootCmd.AddCommand(requestsCmd)
+	setRequestsFlags(requestsCmd)
+}
@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/resilience"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var resilienceConfig resilience.Config
+
+const productionNamespaceSelectorFlagName = "production-namespace-selector"
+
+var resilienceCmd = &cobra.Command{
+	Use:   "resilience",
+	Short: "Audit workloads which are not resilient to node and zone failures",
+	Long: `This command determines which Deployments and StatefulSets would become unavailable if a single node or zone
+fails, if their nodes are drained, or if their containers become unhealthy. This auditor is optional, so it is only
+run by 'kubeaudit all' if it is enabled in the kubeaudit config.
+
+A WARN result is generated for each of the following cases:
+  - A workload in a production namespace has a single replica
+  - All replicas of a workload can only be scheduled on one node or in one zone
+  - A workload with multiple replicas sets neither topologySpreadConstraints nor pod anti-affinity
+  - A workload with multiple replicas is not selected by a PodDisruptionBudget in its namespace
+  - A container does not set a liveness probe or a readiness probe
+
+Production namespaces are selected by their labels using '--production-namespace-selector'. The namespace must be
+one of the audited resources, so in manifest mode it has to be in the manifest.
+
+Example usage:
+kubeaudit resilience
+kubeaudit resilience --production-namespace-selector "environment=prod"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := resilience.New(resilienceConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create resilience auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setResilienceFlags(cmd *cobra.Command) {
+	cmd.Flags().StringToStringVar(&resilienceConfig.ProductionNamespaceSelector, productionNamespaceSelectorFlagName,
+		resilience.DefaultProductionNamespaceSelector, "Labels which select the production namespaces")
+}
+
+func init() {
+	RootCmd.AddCommand(resilienceCmd)
+	setResilienceFlags(resilienceCmd)
+}
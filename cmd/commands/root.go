@@ -1,36 +1,109 @@
 package commands
 
 import (
+	"bytes"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 	apiv1 "k8s.io/api/core/v1"
 
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/auditors/all"
 	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/atomicfile"
+	"github.com/Shopify/kubeaudit/internal/baseline"
+	"github.com/Shopify/kubeaudit/internal/blame"
 	"github.com/Shopify/kubeaudit/internal/color"
+	"github.com/Shopify/kubeaudit/internal/compliance"
+	"github.com/Shopify/kubeaudit/internal/cyclonedx"
+	"github.com/Shopify/kubeaudit/internal/gitrepo"
+	"github.com/Shopify/kubeaudit/internal/history"
+	"github.com/Shopify/kubeaudit/internal/junit"
 	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/internal/manifests"
+	"github.com/Shopify/kubeaudit/internal/redact"
 	"github.com/Shopify/kubeaudit/internal/sarif"
+	"github.com/Shopify/kubeaudit/internal/summary"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
 )
 
 var rootConfig rootFlags
 
+const (
+	excludeNamespaceFlagName = "exclude-namespace"
+	readOnlyFlagName         = "read-only"
+	offlineFlagName          = "offline"
+)
+
 type rootFlags struct {
-	format           string
-	kubeConfig       string
-	context          string
-	manifest         string
-	namespace        string
-	minSeverity      string
-	exitCode         int
-	includeGenerated bool
-	noColor          bool
+	format             string
+	summary            bool
+	baseline           string
+	saveReport         string
+	kubeConfig         string
+	context            string
+	allContexts        bool
+	manifests          []string
+	kustomize          string
+	helmChart          string
+	gitURL             string
+	gitRef             string
+	gitPath            string
+	changedSince       string
+	changedFiles       []string
+	helmValues         []string
+	customResources    []string
+	namespace          string
+	excludedNamespaces []string
+	selector           string
+	fieldSelector      string
+	priorityNamespaces []string
+	minSeverity        string
+	exitCode           int
+	failOn             string
+	noFail             bool
+	samplePerRule      int
+	includeGenerated   bool
+	includeJobHistory  bool
+	excludeCluster     bool
+	readOnly           bool
+	noColor            bool
+	hyperlinks         string
+	groupBy            string
+	redactNames        bool
+	redact             string
+	blame              bool
+	signReport         string
+	signature          string
+	compliance         string
+	rules              []string
+	concurrency        int
+	strict             bool
+	offline            bool
+	chunkSize          int64
+	requestTimeout     time.Duration
+	cacheWarmupTimeout time.Duration
+	qps                float32
+	burst              int
+	profile            bool
+	cpuProfile         string
 }
 
+const (
+	hyperlinksAuto   = "auto"
+	hyperlinksAlways = "always"
+	hyperlinksNever  = "never"
+)
+
 // RootCmd defines the shell command usage for kubeaudit.
 var RootCmd = &cobra.Command{
 	Use:   "kubeaudit",
@@ -39,7 +112,7 @@ var RootCmd = &cobra.Command{
 
 kubeaudit has three modes:
   1. Manifest mode: If a Kubernetes manifest file is provided using the -f/--manifest flag, kubeaudit will audit the manifest file. Kubeaudit also supports autofixing in manifest mode using the 'autofix' command. This will fix the manifest in-place. The fixed manifest can be written to a different file using the -o/--out flag.
-  2. Cluster mode: If kubeaudit detects it is running in a cluster, it will audit the other resources in the cluster.
+  2. Cluster mode: If kubeaudit detects it is running in a cluster and no kubeconfig is found, it will audit the other resources in the cluster.
   3. Local mode: kubeaudit will try to connect to a cluster using the local kubeconfig file ($HOME/.kube/config). A different kubeconfig location can be specified using the -c/--kubeconfig flag
 `,
 }
@@ -52,97 +125,513 @@ func Execute() {
 }
 
 func init() {
-	RootCmd.PersistentFlags().StringVarP(&rootConfig.kubeConfig, "kubeconfig", "", "", "Path to local Kubernetes config file. Only used in local mode (default is $HOME/.kube/config)")
-	RootCmd.PersistentFlags().StringVarP(&rootConfig.context, "context", "c", "", "The name of the kubeconfig context to use")
-	RootCmd.PersistentFlags().StringVarP(&rootConfig.minSeverity, "minseverity", "m", "info", "Set the lowest severity level to report (one of \"error\", \"warning\", \"info\")")
-	RootCmd.PersistentFlags().StringVarP(&rootConfig.format, "format", "p", "pretty", "The output format to use (one of \"sarif\",\"pretty\", \"logrus\", \"json\")")
-	RootCmd.PersistentFlags().StringVarP(&rootConfig.namespace, "namespace", "n", apiv1.NamespaceAll, "Only audit resources in the specified namespace. Not currently supported in manifest mode.")
+	// Secret values are never written to the logs, including errors from loading kubeconfig files
+	log.AddHook(redact.Hook{})
+
+	RootCmd.PersistentFlags().StringVarP(&rootConfig.kubeConfig, "kubeconfig", "", "", "Path to local Kubernetes config file, or \"-\" to read it from stdin. Only used in local mode (default is the files in $KUBECONFIG, or $HOME/.kube/config)")
+	RootCmd.PersistentFlags().StringVarP(&rootConfig.context, "context", "c", "", "The name of the kubeconfig context to use. Several contexts can be audited at once in local mode, separated by commas, in which case every result is tagged with the context of its cluster.")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.allContexts, "all-contexts", false, "Audit the clusters of every context of the kubeconfig at once, tagging every result with the context of its cluster. Only used in local mode.")
+	RootCmd.PersistentFlags().StringVarP(&rootConfig.minSeverity, "minseverity", "m", "info", "Set the lowest severity level to report (one of \"error\", \"warning\", \"info\" or a custom severity)")
+	RootCmd.PersistentFlags().StringVarP(&rootConfig.format, "format", "p", "pretty", "The output format to use (one of \"sarif\", \"junit\", \"cyclonedx\", \"pretty\", \"logrus\", \"json\", \"summary\")")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.summary, "summary", false, "Also print the number of results per severity, context, auditor and namespace and the verdicts of the workloads after the results. The summary is printed to stderr for formats other than pretty, so their output can still be parsed, and as JSON for the json format.")
+	RootCmd.PersistentFlags().StringVarP(&rootConfig.namespace, "namespace", "n", apiv1.NamespaceAll, "Only audit resources in the specified namespaces, separated by commas. Not currently supported in manifest mode, except as the single namespace the release of --helm is rendered in (default \"default\").")
+	RootCmd.PersistentFlags().StringSliceVar(&rootConfig.excludedNamespaces, excludeNamespaceFlagName, nil, "Don't audit resources in the specified namespaces, separated by commas. Replaces the excludedNamespaces of the kubeaudit config. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().StringVarP(&rootConfig.selector, "selector", "l", "", "Only audit workloads whose labels match the selector (eg. \"app=payments\"). Other resources, such as namespaces and network policies, are not filtered. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.fieldSelector, "field-selector", "", "Only audit workloads whose fields match the selector (eg. \"metadata.name=payments\"). Workload types which don't support the fields are not audited. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().StringSliceVar(&rootConfig.priorityNamespaces, "priority-namespaces", nil, "Namespaces to audit and report first, in the order they are listed. The other namespaces are interleaved. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().Int64Var(&rootConfig.chunkSize, "chunk-size", k8sinternal.DefaultChunkSize, "Fetch large lists of resources from the API server in chunks of at most this many resources, like kubectl. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().DurationVar(&rootConfig.cacheWarmupTimeout, "cache-warmup-timeout", k8sinternal.DefaultCacheWarmupTimeout, "Maximum time to wait for the informer caches of --watch and the serve command to sync. The resources of types whose caches are not synced by then, such as types which can be listed but not watched, are handled once they are synced. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().DurationVar(&rootConfig.requestTimeout, "request-timeout", 0, "Maximum time of each request to the API server, like kubectl (eg. \"30s\"). 0 means no timeout. Not applied to the watch requests of --watch. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().Float32Var(&rootConfig.qps, "qps", k8sinternal.DefaultQPS, "Maximum number of requests per second to the API server. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().IntVar(&rootConfig.burst, "burst", k8sinternal.DefaultBurst, "Maximum number of requests to the API server at once, above --qps. Not supported in manifest mode.")
 	RootCmd.PersistentFlags().BoolVarP(&rootConfig.includeGenerated, "includegenerated", "g", false, "Include generated resources in scan  (eg. pods generated by deployments).")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.includeJobHistory, "include-job-history", false, "Include the Jobs created by CronJobs, which CronJobs keep up to their history limits, even though they are generated resources. Their results are attributed to their CronJob. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.excludeCluster, "exclude-cluster-scoped", false, "Don't audit cluster-scoped resources, such as namespaces, ClusterRoles and admission webhook configurations, so they don't have to be readable. Auditors which use namespaces as context don't see them either. Not supported in manifest mode.")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.readOnly, readOnlyFlagName, false, "Refuse to run anything which can change the cluster, such as applying fixes with 'autofix --cluster', for use with shared credentials. Server-side dry runs are still allowed.")
 	RootCmd.PersistentFlags().BoolVar(&rootConfig.noColor, "no-color", false, "Don't produce colored output.")
-	RootCmd.PersistentFlags().StringVarP(&rootConfig.manifest, "manifest", "f", "", "Path to the yaml configuration to audit. Only used in manifest mode.")
-	RootCmd.PersistentFlags().IntVarP(&rootConfig.exitCode, "exitcode", "e", 2, "Exit code to use if there are results with severity of \"error\". Conventionally, 0 is used for success and all non-zero codes for an error.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.hyperlinks, "hyperlinks", hyperlinksAuto, "Link rules to their documentation and resource kinds to their API reference in pretty output (one of \"auto\", \"always\", \"never\"). \"auto\" only adds links if the terminal supports them.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.groupBy, "group-by", "", "Print the results of the pretty format as one table per group, sorted, with the columns aligned and the messages wrapped to the width of the terminal (one of \"resource\", \"auditor\", \"namespace\", \"severity\"). By default, the results are printed per resource with their metadata.")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.redactNames, "redact-names", false, "Replace resource names and namespaces in the results with a hash, for reports shared externally.")
+	RootCmd.PersistentFlags().MarkDeprecated("redact-names", "use --redact, which also redacts image repositories and label values")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.redact, "redact", "", "Replace namespaces, resource names, image repositories and label values in all output formats, for reports shared externally (one of \"hash\", \"mask\"). \"hash\", the default if the flag has no value, keeps the same hash for the same name so results can be correlated across reports, and \"mask\" replaces every name with [REDACTED].")
+	RootCmd.PersistentFlags().Lookup("redact").NoOptDefVal = string(kubeaudit.RedactHash)
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.blame, "blame", false, "Add the last commit, author and date which changed the line of each result to its metadata, with git blame. Only used in manifest mode, for manifests in a Git repository. Not supported with --git.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.signReport, "sign-report", "", "Path to a PEM encoded private key to sign the report with. The detached signature is written to the file set with --signature. Not supported with the pretty format.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.signature, "signature", "", "File to write the signature of the report to when signing it with --sign-report, or to read it from with verify-report.")
+	RootCmd.PersistentFlags().StringArrayVarP(&rootConfig.manifests, "manifest", "f", nil, "Path to the yaml configuration to audit, a directory of manifests to audit recursively (*.yaml, *.yml and *.json), or a glob pattern. Can be specified multiple times, and \"-\" reads the manifest from stdin. Only used in manifest mode.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.kustomize, "kustomize", "", "Path to a kustomization directory to render and audit. Only used in manifest mode.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.helmChart, "helm", "", "Path to a Helm chart to render and audit. Only used in manifest mode.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.gitURL, "git", "", "URL of a Git repository to check out shallowly and audit the manifests of. Only used in manifest mode.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.gitRef, "ref", "", "Branch, tag or commit of the Git repository specified with --git to audit (default is the default branch)")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.gitPath, "path", "", "Directory or file within the Git repository specified with --git to audit (default is the whole repository)")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.changedSince, "changed-since", "", "Only audit the manifest files of -f/--manifest changed since the Git revision (eg. \"origin/main\"): the files changed since its merge base with HEAD and the uncommitted and untracked files, or the files changed in a range such as \"main..feature\". The other manifest files in the directories of the changed files are loaded as context, but not audited. Only used in manifest mode.")
+	RootCmd.PersistentFlags().StringSliceVar(&rootConfig.changedFiles, "changed-files", nil, "Only audit the manifest files of -f/--manifest in this list of changed files, separated by commas, such as the files changed by a pull request. The other manifest files in the directories of the changed files are loaded as context, but not audited. Only used in manifest mode.")
+	RootCmd.PersistentFlags().StringSliceVar(&rootConfig.helmValues, "values", nil, "Values files to use when rendering the Helm chart specified with --helm. Can be specified multiple times.")
+	RootCmd.PersistentFlags().StringSliceVar(&rootConfig.customResources, "custom-resource", nil, "Custom resource kind which embeds a PodSpec to audit, in the form <kind>.<group>=<path> (eg. \"Rollout.argoproj.io=.spec.template.spec\"). Can be specified multiple times.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.baseline, "baseline", "", "Path to a baseline file generated with 'kubeaudit baseline generate'. Only results which are not in the baseline are reported.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.saveReport, "save-report", "", "File to save the reported findings to, such as \"reports/2024-06-01.json\", to compare them with the findings of a later audit with 'kubeaudit compare'.")
+	RootCmd.PersistentFlags().StringSliceVar(&rootConfig.rules, "rules", nil, "Only report the results of the specified rules, separated by commas (eg. \"CapabilityShouldDropAll,SeccompProfileMissing\"). The overridden results of the rules are also reported, and autofix only fixes the results of the rules.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.compliance, "compliance", "", "Group the results by the controls of a benchmark (one of \"cis\", \"nsa\") and report which controls pass. Only supported with the pretty and json formats.")
+	RootCmd.PersistentFlags().IntVar(&rootConfig.concurrency, "concurrency", 1, "Number of resources to audit at the same time. The results are reported in the same order regardless of the concurrency.")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.offline, offlineFlagName, false, "Don't access the network other than the Kubernetes API server, for air-gapped environments. Auditors which need the network use their local caches or skip their checks, with the reason in their results, and notifications are not sent. Plugins and the opa command of the rego auditor are run as they are. Not supported with --git.")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.strict, "strict", false, "Fail the audit on the first error of an auditor or invalid manifest document. By default, they are reported as AuditorError and Manifest results of the resource and the other resources are still audited.")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.profile, "profile", false, "Print how long each auditor took in total, on average and for its slowest resource, and the slowest resources, to stderr after the results. The times of the auditors add up to more than the duration of the audit since they run concurrently.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.cpuProfile, "cpu-profile", "", "File to write a pprof CPU profile of the audit to, for 'go tool pprof'. Samples are labeled with the auditor they were taken in, eg. \"go tool pprof -tagfocus auditor=image.Image\".")
+	RootCmd.PersistentFlags().IntVar(&rootConfig.samplePerRule, "sample-per-rule", 0, "Maximum number of results to report for each rule, in all output formats. Results beyond the limit are still counted by the summary and the exit code. 0 reports all results.")
+	RootCmd.PersistentFlags().IntVarP(&rootConfig.exitCode, "exitcode", "e", 2, "Exit code to use if there are results with the severity set with --fail-on or higher. Conventionally, 0 is used for success and all non-zero codes for an error.")
+	RootCmd.PersistentFlags().StringVar(&rootConfig.failOn, "fail-on", "error", "Lowest severity level of the results which make kubeaudit exit with the code set with --exitcode (one of \"error\", \"warning\", \"info\" or a custom severity)")
+	RootCmd.PersistentFlags().BoolVar(&rootConfig.noFail, "no-fail", false, "Always exit with code 0 when the audit succeeds, regardless of the results, for report-only pipelines.")
 }
 
-// KubeauditLogLevels represents an enum for the supported log levels.
-var KubeauditLogLevels = map[string]kubeaudit.SeverityLevel{
-	"error":   kubeaudit.Error,
-	"warn":    kubeaudit.Warn,
-	"warning": kubeaudit.Warn,
-	"info":    kubeaudit.Info,
+// getMinSeverity returns the severity set with --minseverity, which can be a custom severity of the kubeaudit config.
+// Results of all severities are reported if it is invalid
+func getMinSeverity() kubeaudit.SeverityLevel {
+	minSeverity, _ := kubeaudit.ParseSeverity(rootConfig.minSeverity)
+	return minSeverity
 }
 
 func runAudit(auditable ...kubeaudit.Auditable) func(cmd *cobra.Command, args []string) {
 	return func(cmd *cobra.Command, args []string) {
+		checkRedactFlag()
+		// --fail-on is validated before the audit, so an invalid value doesn't waste a full audit
+		getFailOn()
+		startCPUProfile()
+		start := time.Now()
 		report := getReport(auditable...)
+		duration := time.Since(start)
+		stopCPUProfile()
+		if rootConfig.blame {
+			blameReport(report)
+		}
+		report = applyExclusions(report)
+		if rootConfig.baseline != "" {
+			report = applyBaseline(report, rootConfig.baseline)
+		}
+		report = redactReport(report)
+		if rootConfig.saveReport != "" {
+			saveReport(report, rootConfig.saveReport)
+		}
+		notifyFindings(report)
 
 		fmt.Fprintln(os.Stderr, color.Yellow("\n[WARNING]: kubernetes.io for override labels will soon be deprecated. Please, update them to use kubeaudit.io instead."))
 
-		printOptions := []kubeaudit.PrintOption{
-			kubeaudit.WithMinSeverity(KubeauditLogLevels[strings.ToLower(rootConfig.minSeverity)]),
-			kubeaudit.WithColor(!rootConfig.noColor),
+		printOptions := getPrintOptions()
+		// The printer samples the results itself, so it can report the totals of the sampled rules. The summary and the
+		// exit code count all the results
+		sampled := report.SamplePerRule(rootConfig.samplePerRule)
+
+		// The report is written to a buffer first when it is signed, so the signature covers the exact output
+		var out io.Writer = os.Stdout
+		var signed *bytes.Buffer
+		if rootConfig.signReport != "" {
+			checkSignReportFlags()
+			signed = &bytes.Buffer{}
+			out = signed
 		}
 
-		switch rootConfig.format {
-		case "sarif":
-			sarifReport, err := sarif.Create(report)
-			if err != nil {
+		switch {
+		case rootConfig.compliance != "":
+			writeComplianceReport(sampled, out)
+		case rootConfig.format == "sarif":
+			var options sarif.WriteOptions
+			if gitCheckout != nil {
+				options.VersionControlProvenance = sarif.NewVersionControlProvenance(gitCheckout.URL, gitCheckout.Ref, gitCheckout.Commit)
+			}
+			if err := sarif.Write(out, sampled, options); err != nil {
 				log.WithError(err).Fatal("Error generating the SARIF output")
 			}
-			sarifReport.PrettyWrite(os.Stdout)
-			return
-		case "json":
-			printOptions = append(printOptions, kubeaudit.WithFormatter(&log.JSONFormatter{}))
-		case "logrus":
-			printOptions = append(printOptions, kubeaudit.WithFormatter(&log.TextFormatter{}))
+		case rootConfig.format == "junit":
+			if err := junit.Create(sampled).Write(out); err != nil {
+				log.WithError(err).Fatal("Error generating the JUnit output")
+			}
+		case rootConfig.format == "cyclonedx":
+			options := cyclonedx.Options{MinSeverity: getMinSeverity(), ToolVersion: strings.TrimSpace(version)}
+			if err := cyclonedx.Write(out, sampled, options); err != nil {
+				log.WithError(err).Fatal("Error generating the CycloneDX output")
+			}
+		case rootConfig.format == "summary":
+			writeSummary(report, duration, out)
+		default:
+			report.PrintResults(append(printOptions, kubeaudit.WithWriter(out))...)
 		}
 
-		report.PrintResults(printOptions...)
+		// The summary is the overview of the clusters when several contexts are audited
+		if (rootConfig.summary || multiContextAudit) && rootConfig.format != "summary" {
+			if rootConfig.format == "pretty" && rootConfig.compliance == "" {
+				writeSummary(report, duration, out)
+			} else {
+				writeSummary(report, duration, os.Stderr)
+			}
+		}
+
+		if signed != nil {
+			writeSignedReport(signed.Bytes())
+		}
+		writeProfile()
 
-		if report.HasErrors() {
+		if shouldFail(report) {
 			os.Exit(rootConfig.exitCode)
 		}
 	}
 }
 
+// shouldFail returns true if the report has results with the severity set with --fail-on or higher, and failing isn't
+// disabled with --no-fail
+func shouldFail(report *kubeaudit.Report) bool {
+	if rootConfig.noFail {
+		return false
+	}
+
+	return report.HasResultsWithMinSeverity(getFailOn())
+}
+
+// getFailOn returns the severity set with --fail-on
+func getFailOn() kubeaudit.SeverityLevel {
+	failOn, err := kubeaudit.ParseSeverity(rootConfig.failOn)
+	if err != nil {
+		log.WithError(err).Fatal("invalid --fail-on")
+	}
+	return failOn
+}
+
+// writeComplianceReport writes the results of the controls of the benchmark set with --compliance
+func writeComplianceReport(report *kubeaudit.Report, out io.Writer) {
+	benchmark, ok := compliance.GetBenchmark(strings.ToLower(rootConfig.compliance))
+	if !ok {
+		log.Fatalf("invalid --compliance %q, expected one of \"cis\", \"nsa\"", rootConfig.compliance)
+	}
+
+	complianceReport := compliance.Create(report, benchmark)
+	var err error
+	switch rootConfig.format {
+	case "pretty":
+		err = complianceReport.Write(out, !rootConfig.noColor)
+	case "json":
+		err = complianceReport.WriteJSON(out)
+	default:
+		log.Fatalf("--compliance is not supported with the %s format", rootConfig.format)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Error writing the compliance report")
+	}
+}
+
+// writeSummary writes the number of results of the report per severity, auditor and namespace, with the minimum
+// severity set with --minseverity, and the verdicts of the workloads with the severity set with --fail-on. The
+// summary is written as JSON with the json format
+func writeSummary(report *kubeaudit.Report, duration time.Duration, out io.Writer) {
+	summaryReport := summary.Create(report, getMinSeverity(), getFailOn(), duration)
+	var err error
+	if rootConfig.format == "json" {
+		err = summaryReport.WriteJSON(out)
+	} else {
+		err = summaryReport.Write(out, !rootConfig.noColor)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Error writing the summary")
+	}
+}
+
+// getPrintOptions returns the print options set by the root flags. The sarif, junit and cyclonedx formats are not printed
+// by the printer so they use the default options
+func getPrintOptions() []kubeaudit.PrintOption {
+	printOptions := []kubeaudit.PrintOption{
+		kubeaudit.WithMinSeverity(getMinSeverity()),
+		kubeaudit.WithColor(!rootConfig.noColor),
+		kubeaudit.WithHyperlinks(useHyperlinks()),
+		kubeaudit.WithGroupBy(getGroupBy()),
+		kubeaudit.WithWidth(terminalWidth()),
+		kubeaudit.WithSamplePerRule(rootConfig.samplePerRule),
+	}
+
+	switch rootConfig.format {
+	case "json":
+		printOptions = append(printOptions, kubeaudit.WithFormatter(&log.JSONFormatter{}))
+	case "logrus":
+		printOptions = append(printOptions, kubeaudit.WithFormatter(&log.TextFormatter{}))
+	}
+
+	return printOptions
+}
+
+// checkRedactFlag exits if --redact is not one of the redaction modes
+func checkRedactFlag() {
+	if rootConfig.redact == "" {
+		return
+	}
+	for _, mode := range kubeaudit.RedactModes {
+		if rootConfig.redact == string(mode) {
+			return
+		}
+	}
+	log.Fatalf("invalid --redact %q, expected one of \"hash\", \"mask\"", rootConfig.redact)
+}
+
+// redactReport redacts the report as set by the --redact and --redact-names flags
+func redactReport(report *kubeaudit.Report) *kubeaudit.Report {
+	switch {
+	case rootConfig.redact != "":
+		return report.Redact(kubeaudit.RedactMode(rootConfig.redact))
+	case rootConfig.redactNames:
+		return report.RedactNames()
+	}
+	return report
+}
+
+// useHyperlinks returns true if the pretty output should include hyperlinks, as set by the --hyperlinks flag
+func useHyperlinks() bool {
+	switch rootConfig.hyperlinks {
+	case hyperlinksAlways:
+		return true
+	case hyperlinksNever:
+		return false
+	case hyperlinksAuto:
+		return color.SupportsHyperlinks(os.Stdout, os.Getenv)
+	}
+	log.Fatalf("invalid --hyperlinks %q, expected one of \"auto\", \"always\", \"never\"", rootConfig.hyperlinks)
+	return false
+}
+
+// getGroupBy returns the grouping of the pretty output set by the --group-by flag
+func getGroupBy() kubeaudit.GroupBy {
+	if rootConfig.groupBy == "" {
+		return ""
+	}
+	for _, groupBy := range kubeaudit.GroupBys {
+		if rootConfig.groupBy == string(groupBy) {
+			return groupBy
+		}
+	}
+	log.Fatalf("invalid --group-by %q, expected one of \"resource\", \"auditor\", \"namespace\", \"severity\"", rootConfig.groupBy)
+	return ""
+}
+
+// terminalWidth returns the width of the terminal stdout is, or else the COLUMNS environment variable, so the pretty
+// output can be wrapped to it. It returns 0 if the output isn't a terminal and COLUMNS isn't set
+func terminalWidth() int {
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	width, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return width
+}
+
+// getHelmNamespace returns the namespace to render the release of --helm in, which is the namespace of --namespace or
+// the default namespace
+func getHelmNamespace() string {
+	if rootConfig.namespace == apiv1.NamespaceAll {
+		return apiv1.NamespaceDefault
+	}
+	if strings.Contains(rootConfig.namespace, ",") {
+		log.Fatalf("invalid --namespace %q, the release of --helm is rendered in a single namespace", rootConfig.namespace)
+	}
+	return rootConfig.namespace
+}
+
 func getReport(auditors ...kubeaudit.Auditable) *kubeaudit.Report {
 	auditor := initKubeaudit(auditors...)
+	registerCustomResourceFlags()
 
-	if rootConfig.manifest != "" {
-		var f *os.File
-		if rootConfig.manifest == "-" {
-			f = os.Stdin
-			rootConfig.manifest = ""
-		} else {
-			manifest, err := os.Open(rootConfig.manifest)
-			if err != nil {
-				log.WithError(err).Fatal("Error opening manifest file")
-			}
+	if rootConfig.helmChart != "" {
+		report, err := auditor.AuditHelmChartWithNamespace(rootConfig.helmChart, rootConfig.helmValues, getHelmNamespace())
+		if err != nil {
+			log.WithError(err).Fatal("Error auditing helm chart")
+		}
+		return report
+	}
+
+	if rootConfig.kustomize != "" {
+		report, err := auditor.AuditKustomize(rootConfig.kustomize)
+		if err != nil {
+			log.WithError(err).Fatal("Error auditing kustomization")
+		}
+		return report
+	}
+
+	if rootConfig.gitURL != "" {
+		return auditGitRepository(auditor)
+	}
+
+	if isStdinManifest() {
+		report, err := auditor.AuditManifest("", os.Stdin)
+		if err != nil {
+			log.WithError(err).Fatal("Error auditing manifest")
+		}
+		return report
+	}
+
+	if rootConfig.changedSince != "" || len(rootConfig.changedFiles) > 0 {
+		if len(rootConfig.manifests) == 0 {
+			log.Fatal("--changed-since and --changed-files are only supported with manifest files and directories set with -f/--manifest")
+		}
+		return auditChangedManifests(auditor, findManifests())
+	}
 
-			f = manifest
+	if len(rootConfig.manifests) > 0 {
+		files := findManifests()
+		paths := make([]string, 0, len(files))
+		for _, file := range files {
+			paths = append(paths, file.Path)
 		}
 
-		report, err := auditor.AuditManifest(rootConfig.manifest, f)
+		report, err := auditor.AuditManifestFiles(paths)
 		if err != nil {
 			log.WithError(err).Fatal("Error auditing manifest")
 		}
+		if isManifestTree() {
+			fmt.Fprintf(os.Stderr, "%d manifest files audited\n", len(files))
+		}
 		return report
 	}
 
-	if k8sinternal.IsRunningInCluster(k8sinternal.DefaultClient) && rootConfig.kubeConfig == "" {
-		report, err := auditor.AuditCluster(k8sinternal.ClientOptions{Namespace: rootConfig.namespace, IncludeGenerated: rootConfig.includeGenerated})
+	if contexts, ok := getMultipleContexts(); ok {
+		return auditContexts(auditor, contexts)
+	}
+
+	if k8sinternal.UseInClusterConfig(k8sinternal.DefaultClient, rootConfig.kubeConfig) {
+		report, err := auditor.AuditCluster(getAuditOptions())
 		if err != nil {
 			log.WithError(err).Fatal("Error auditing cluster")
 		}
 		return report
 	}
 
-	report, err := auditor.AuditLocal(rootConfig.kubeConfig, rootConfig.context, kubeaudit.AuditOptions{Namespace: rootConfig.namespace, IncludeGenerated: rootConfig.includeGenerated})
+	report, err := auditor.AuditLocal(rootConfig.kubeConfig, rootConfig.context, getAuditOptions())
 	if err != nil {
 		log.WithError(err).Fatal("Error auditing cluster in local mode")
 	}
 	return report
 }
 
+// multiContextAudit is true if the clusters of several contexts were audited, so a summary of the clusters is written
+var multiContextAudit bool
+
+// getMultipleContexts returns the contexts set with --context when several are set, separated by commas, or no
+// contexts if every context is audited with --all-contexts. It returns false if a single cluster is audited
+func getMultipleContexts() ([]string, bool) {
+	if rootConfig.allContexts {
+		return nil, true
+	}
+	if !strings.Contains(rootConfig.context, ",") {
+		return nil, false
+	}
+
+	var contexts []string
+	for _, context := range strings.Split(rootConfig.context, ",") {
+		if context = strings.TrimSpace(context); context != "" {
+			contexts = append(contexts, context)
+		}
+	}
+	return contexts, true
+}
+
+// getContext returns the context set with --context for commands which only support a single cluster
+func getContext() string {
+	if _, ok := getMultipleContexts(); ok {
+		log.Fatal("Several contexts can only be audited at once by the audit commands")
+	}
+	return rootConfig.context
+}
+
+// auditContexts audits the clusters of the contexts concurrently, or of every context of the kubeconfig if none are
+// set
+func auditContexts(auditor *kubeaudit.Kubeaudit, contexts []string) *kubeaudit.Report {
+	report, err := auditor.AuditLocalContexts(rootConfig.kubeConfig, contexts, getAuditOptions())
+	if err != nil {
+		log.WithError(err).Fatal("Error auditing clusters in local mode")
+	}
+	multiContextAudit = true
+	return report
+}
+
+// getAuditOptions returns the options to audit a cluster with, as set by the root flags
+func getAuditOptions() kubeaudit.AuditOptions {
+	return kubeaudit.AuditOptions{
+		Namespace:            rootConfig.namespace,
+		ExcludedNamespaces:   rootConfig.excludedNamespaces,
+		IncludeGenerated:     rootConfig.includeGenerated,
+		IncludeJobHistory:    rootConfig.includeJobHistory,
+		ExcludeClusterScoped: rootConfig.excludeCluster,
+		LabelSelector:        rootConfig.selector,
+		FieldSelector:        rootConfig.fieldSelector,
+		PriorityNamespaces:   rootConfig.priorityNamespaces,
+		ChunkSize:            rootConfig.chunkSize,
+		CacheWarmupTimeout:   rootConfig.cacheWarmupTimeout,
+		ConnectionOptions:    getConnectionOptions(),
+	}
+}
+
+// getConnectionOptions returns the options to connect to the API server with, as set by the root flags
+func getConnectionOptions() k8sinternal.ConnectionOptions {
+	return k8sinternal.ConnectionOptions{
+		RequestTimeout: rootConfig.requestTimeout,
+		QPS:            rootConfig.qps,
+		Burst:          rootConfig.burst,
+	}
+}
+
+// checkReadOnly exits if kubeaudit runs in read-only mode, in which it refuses to do anything which can change the
+// cluster
+func checkReadOnly(action string) {
+	if rootConfig.readOnly {
+		log.Fatalf("%s is not allowed with --%s, which guarantees that the cluster is not changed", action, readOnlyFlagName)
+	}
+}
+
+// applyExclusions removes the findings of the resources excluded by the kubeaudit config from the report, and writes
+// the number of resources skipped because of each exclusion
+func applyExclusions(report *kubeaudit.Report) *kubeaudit.Report {
+	report, skipped := configExclusions.Filter(report)
+	if len(skipped) > 0 {
+		fmt.Fprintln(os.Stderr, "Resources skipped by the exclusions of the kubeaudit config:")
+		for _, s := range skipped {
+			fmt.Fprintf(os.Stderr, "  %s: %d\n", s.Exclusion, s.Resources)
+		}
+	}
+	return report
+}
+
+// applyBaseline removes the results which are in the baseline file from the report
+func applyBaseline(report *kubeaudit.Report, baselineFile string) *kubeaudit.Report {
+	knownFindings, err := baseline.Load(baselineFile)
+	if err != nil {
+		log.WithError(err).Fatal("Error loading baseline")
+	}
+
+	report, suppressed := knownFindings.Filter(report)
+	if suppressed > 0 {
+		fmt.Fprintf(os.Stderr, "%d results suppressed by the baseline %s\n", suppressed, baselineFile)
+	}
+	return report
+}
+
+// saveReport writes the findings of the report with the severity set with --minseverity or higher to the file, creating
+// its directory if needed
+func saveReport(report *kubeaudit.Report, path string) {
+	var buf bytes.Buffer
+	if err := history.New(report, getMinSeverity(), time.Now()).Write(&buf); err != nil {
+		log.WithError(err).Fatal("Error encoding the report")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log.WithError(err).Fatal("Error creating the report directory")
+	}
+	if err := atomicfile.WriteFile(path, buf.Bytes(), 0644, ""); err != nil {
+		log.WithError(err).Fatal("Error saving the report")
+	}
+}
+
+// blameReport adds the last commit which changed the line of each result to its metadata
+func blameReport(report *kubeaudit.Report) {
+	if rootConfig.gitURL != "" {
+		log.Fatal("--blame is not supported with --git, because the repository is checked out shallowly")
+	}
+	blamer, err := blame.New()
+	if err != nil {
+		log.WithError(err).Fatal("Error blaming manifests")
+	}
+	blamer.Annotate(report)
+}
+
 func initKubeaudit(auditable ...kubeaudit.Auditable) *kubeaudit.Kubeaudit {
 	if len(auditable) == 0 {
 		allAuditors, err := all.Auditors(config.KubeauditConfig{})
@@ -151,11 +640,193 @@ func initKubeaudit(auditable ...kubeaudit.Auditable) *kubeaudit.Kubeaudit {
 		}
 		auditable = allAuditors
 	}
+	auditable = all.WithFields(all.WithReferences(all.OnlyRules(auditable, rootConfig.rules)))
 
-	auditor, err := kubeaudit.New(auditable)
+	options := append([]kubeaudit.Option{kubeaudit.WithConcurrency(rootConfig.concurrency)}, getProfileOptions()...)
+	if rootConfig.strict {
+		options = append(options, kubeaudit.WithStrict())
+	}
+	auditor, err := kubeaudit.New(auditable, options...)
 	if err != nil {
 		log.WithError(err).Fatal("Error creating auditor")
 	}
 
 	return auditor
 }
+
+// isStdinManifest returns true if the manifest is read from stdin with "-f -"
+func isStdinManifest() bool {
+	return len(rootConfig.manifests) == 1 && rootConfig.manifests[0] == "-"
+}
+
+// isManifestTree returns true if several manifest files are audited, through directories, glob patterns or multiple
+// -f/--manifest flags, rather than a single manifest file
+func isManifestTree() bool {
+	if len(rootConfig.manifests) != 1 {
+		return true
+	}
+	info, err := os.Stat(rootConfig.manifests[0])
+	return err != nil || info.IsDir()
+}
+
+// findManifests returns the manifest files of the -f/--manifest flags
+func findManifests() []manifests.File {
+	for _, path := range rootConfig.manifests {
+		if path == "-" && len(rootConfig.manifests) > 1 {
+			log.Fatal("A manifest can only be read from stdin with a single -f/--manifest flag")
+		}
+	}
+
+	files, err := manifests.Find(rootConfig.manifests)
+	if err != nil {
+		log.WithError(err).Fatal("Error finding manifest files")
+	}
+	return files
+}
+
+// auditChangedManifests audits the manifest files changed as set by the --changed-since and --changed-files flags. The
+// other manifest files in the directories of the changed files are loaded as context, so that resources such as the
+// namespace or service account next to a changed workload are still seen by the auditors
+func auditChangedManifests(auditor *kubeaudit.Kubeaudit, files []manifests.File) *kubeaudit.Report {
+	changed := map[string]bool{}
+	for _, path := range rootConfig.changedFiles {
+		changed[canonicalPath(path)] = true
+	}
+	if rootConfig.changedSince != "" {
+		// The Git repository is the one of the manifests, which may not be the one of the working directory
+		dir := filepath.Dir(files[0].Path)
+		if info, err := os.Stat(files[0].Path); err == nil && info.IsDir() {
+			dir = files[0].Path
+		}
+		paths, err := gitrepo.ChangedFiles(dir, rootConfig.changedSince)
+		if err != nil {
+			log.WithError(err).Fatal("Error finding the changed manifest files")
+		}
+		for _, path := range paths {
+			changed[canonicalPath(path)] = true
+		}
+	}
+
+	var paths, contextPaths []string
+	changedDirs := map[string]bool{}
+	for _, file := range files {
+		if changed[canonicalPath(file.Path)] {
+			paths = append(paths, file.Path)
+			changedDirs[filepath.Dir(file.Path)] = true
+		}
+	}
+	for _, file := range files {
+		if !changed[canonicalPath(file.Path)] && changedDirs[filepath.Dir(file.Path)] {
+			contextPaths = append(contextPaths, file.Path)
+		}
+	}
+
+	report, err := auditor.AuditManifestFilesWithContext(paths, contextPaths)
+	if err != nil {
+		log.WithError(err).Fatal("Error auditing manifest")
+	}
+	fmt.Fprintf(os.Stderr, "%d of %d manifest files changed and audited\n", len(paths), len(files))
+	return report
+}
+
+// canonicalPath returns the absolute path of a file with its symbolic links resolved, so the paths of the changed files
+// given by Git match the paths of the manifest files
+func canonicalPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved
+	}
+	return abs
+}
+
+// gitCheckout is the checkout of the Git repository audited with --git, so the outputs can report its commit
+var gitCheckout *gitrepo.Checkout
+
+// auditGitRepository checks out the --ref of the --git repository in a temporary directory and audits the manifests of
+// --path within it. Results are attributed to the paths of the files within the repository
+func auditGitRepository(auditor *kubeaudit.Kubeaudit) *kubeaudit.Report {
+	if rootConfig.offline {
+		log.Fatal("--git is not supported with --offline, because the repository has to be cloned")
+	}
+	if path := filepath.Clean(rootConfig.gitPath); filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		log.Fatalf("invalid --path %q, it must be within the Git repository", rootConfig.gitPath)
+	}
+
+	dir, err := ioutil.TempDir("", "kubeaudit-git-")
+	if err != nil {
+		log.WithError(err).Fatal("Error creating the checkout directory")
+	}
+
+	// The checkout is removed before exiting on an error, since log.Fatal doesn't run deferred functions
+	report, err := auditGitCheckout(auditor, dir)
+	os.RemoveAll(dir)
+	if err != nil {
+		log.WithError(err).Fatal("Error auditing Git repository")
+	}
+	return report
+}
+
+// auditGitCheckout checks out the --ref of the --git repository in dir and audits the manifests of --path within it
+func auditGitCheckout(auditor *kubeaudit.Kubeaudit, dir string) (*kubeaudit.Report, error) {
+	checkout, err := gitrepo.Clone(rootConfig.gitURL, rootConfig.gitRef, dir)
+	if err != nil {
+		return nil, err
+	}
+	gitCheckout = checkout
+
+	files, err := manifests.Find([]string{filepath.Join(dir, rootConfig.gitPath)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find manifest files: %w", err)
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		paths = append(paths, file.Path)
+	}
+
+	report, err := auditor.AuditManifestFiles(paths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to audit manifests: %w", err)
+	}
+
+	for _, result := range report.RawResults() {
+		for _, auditResult := range result.GetAuditResults() {
+			if rel, err := filepath.Rel(dir, auditResult.FilePath); err == nil && !strings.HasPrefix(rel, "..") {
+				auditResult.FilePath = filepath.ToSlash(rel)
+			}
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "%d manifest files audited in %s at commit %s\n", len(files), checkout.URL, checkout.Commit)
+	return report, nil
+}
+
+// registerCustomResourceFlags registers the custom resource kinds set with the --custom-resource flag
+func registerCustomResourceFlags() {
+	for _, value := range rootConfig.customResources {
+		extractor, err := k8s.ParsePodSpecExtractor(value)
+		if err != nil {
+			log.WithError(err).Fatal("Error parsing custom resource")
+		}
+		registerPodSpecExtractors(extractor)
+	}
+}
+
+func registerPodSpecExtractors(extractors ...k8s.PodSpecExtractor) {
+	for _, extractor := range extractors {
+		if err := k8s.RegisterPodSpecExtractor(extractor); err != nil {
+			log.WithError(err).Fatal("Error registering custom resource")
+		}
+	}
+}
+
+// registerSeverities registers the custom severity levels of the kubeaudit config, in order
+func registerSeverities(definitions ...kubeaudit.SeverityDefinition) {
+	for _, definition := range definitions {
+		if _, err := kubeaudit.RegisterSeverity(definition); err != nil {
+			log.WithError(err).Fatal("Error registering custom severity")
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Shopify/kubeaudit/pkg/rules"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// catalogRule is a rule in the json output of the rules command. Fields are only ever added to it, so tools which read
+// the catalog keep working with newer versions of kubeaudit
+type catalogRule struct {
+	Auditor       string `json:"auditor"`
+	Rule          string `json:"rule"`
+	Severity      string `json:"severity"`
+	Description   string `json:"description"`
+	OverrideLabel string `json:"overrideLabel,omitempty"`
+	Autofix       bool   `json:"autofix"`
+}
+
+func runRules(cmd *cobra.Command, args []string) {
+	var err error
+	switch rootConfig.format {
+	case "json":
+		err = writeRulesJSON(os.Stdout, rules.Rules())
+	case "pretty":
+		err = writeRulesTable(os.Stdout, rules.Rules())
+	default:
+		log.Fatalf("invalid --format %q, expected one of \"pretty\", \"json\"", rootConfig.format)
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Error writing the rules")
+	}
+}
+
+func writeRulesJSON(w io.Writer, ruleList []rules.Rule) error {
+	catalog := make([]catalogRule, 0, len(ruleList))
+	for _, rule := range ruleList {
+		catalog = append(catalog, catalogRule{
+			Auditor:       rule.Auditor,
+			Rule:          rule.ID.String(),
+			Severity:      rule.Severity.String(),
+			Description:   rule.Description,
+			OverrideLabel: rule.OverrideLabel,
+			Autofix:       rule.Fixable,
+		})
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(catalog)
+}
+
+func writeRulesTable(w io.Writer, ruleList []rules.Rule) error {
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "AUDITOR\tRULE\tSEVERITY\tAUTOFIX\tOVERRIDE LABEL\tDESCRIPTION")
+	for _, rule := range ruleList {
+		auditor, autofix, overrideLabel := rule.Auditor, "no", rule.OverrideLabel
+		if auditor == "" {
+			auditor = "(any)"
+		}
+		if rule.Fixable {
+			autofix = "yes"
+		}
+		if overrideLabel == "" {
+			overrideLabel = "-"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%s\n", auditor, rule.ID, rule.Severity, autofix, overrideLabel, rule.Description)
+	}
+	return table.Flush()
+}
+
+var rulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "List the rules of the built-in auditors",
+	Long: `List every rule reported by the built-in auditors, with its default severity, a description, the label which
+overrides it and whether autofix can fix it. Some rules are reported with another severity depending on the kubeaudit
+config or the resource. Override labels are set on resources as "kubeaudit.io/<label>", or as
+"container.kubeaudit.io/<container>.<label>" to only override the results of a container.
+
+With --format json, the rules are written as an array of objects with the fields auditor, rule, severity, description,
+overrideLabel (omitted if the rule can't be overridden) and autofix. Fields are never removed or renamed, so the
+catalog can be used to generate documentation and configs. The rules of the rego auditor and of plugins are not listed,
+as they are defined by the policies and plugins.
+
+Example usage:
+kubeaudit rules
+kubeaudit rules --format json
+`,
+	Run: runRules,
+}
+
+func init() {
+	RootCmd.AddCommand(rulesCmd)
+}
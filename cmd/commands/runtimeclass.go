@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var runtimeClassConfig runtimeclass.Config
+
+var runtimeClassFlags struct {
+	required []string
+}
+
+const (
+	allowedRuntimeClassesFlagName  = "allowed-runtime-classes"
+	requiredRuntimeClassesFlagName = "required-runtime-classes"
+)
+
+var runtimeClassCmd = &cobra.Command{
+	Use:   "runtimeclass",
+	Short: "Audit pods using runtime classes which are not allowed or required",
+	Long: `This command determines which pods use a runtime class which is not allowed, or don't use one of the runtime
+classes they are required to use, such as sandboxed runtimes like gVisor or Kata Containers for untrusted workloads.
+Pods which don't set runtimeClassName use the default runtime of their node, which is always allowed. Runtime classes
+which are only required in some namespaces can be specified with a namespace selector in the kubeaudit config.
+
+An ERROR result is generated for each of the following cases:
+  - A pod uses a runtime class which is not in '--allowed-runtime-classes'
+  - A pod doesn't use one of the runtime classes required in its namespace
+
+Example usage:
+kubeaudit runtimeclass --allowed-runtime-classes "runc,gvisor,kata"
+kubeaudit runtimeclass --required-runtime-classes "gvisor,kata"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := runtimeclass.New(getRuntimeClassConfig())
+		if err != nil {
+			log.WithError(err).Fatal("failed to create runtimeclass auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func getRuntimeClassConfig() runtimeclass.Config {
+	config := runtimeClassConfig
+	if len(runtimeClassFlags.required) > 0 {
+		config.Required = append(config.Required, runtimeclass.Rule{RuntimeClasses: runtimeClassFlags.required})
+	}
+	return config
+}
+
+func setRuntimeClassFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&runtimeClassConfig.Allowed, allowedRuntimeClassesFlagName, nil,
+		"List of runtime classes pods may use. Any runtime class is allowed if not set")
+	cmd.Flags().StringSliceVar(&runtimeClassFlags.required, requiredRuntimeClassesFlagName, nil,
+		"List of runtime classes one of which every pod must use")
+}
+
+func init() {
+	RootCmd.AddCommand(runtimeClassCmd)
+	setRuntimeClassFlags(runtimeClassCmd)
+}
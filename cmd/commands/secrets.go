@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var secretsConfig secrets.Config
+
+const (
+	secretRefSeverityFlagName = "secret-ref-severity"
+	minEntropyFlagName        = "min-entropy"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Audit containers which expose secrets through environment variables, and annotations which hold secrets",
+	Long: `This command determines which containers inject secrets into environment variables or set environment
+variables to literal values which look like secrets, and which resources have annotations holding secrets.
+
+An ERROR result is generated for each of the following cases:
+  - The literal value of an environment variable looks like a secret
+  - The value of an annotation of the resource or its pod template looks like a secret
+
+A WARN result is generated for each of the following cases, with the severity set by '--secret-ref-severity':
+  - An environment variable is set from a secret with 'valueFrom.secretKeyRef'
+  - Environment variables are set from a secret with 'envFrom.secretRef'
+
+Values look like secrets if they match a well-known format, such as private keys, AWS access key IDs, GitHub tokens
+and credentials in URLs, if they are set in a variable or annotation named like a credential (eg. DB_PASSWORD), or if
+they are random looking with an entropy of more than '--min-entropy' bits per character.
+
+With 'mountSecretRefs' set in the secrets config of the kubeaudit config, autofix mounts the secrets referenced by
+environment variables as read-only projected volumes under /etc/secrets/[secret name] and removes the environment
+variables. The application must then read the secrets from the files.
+
+Example usage:
+kubeaudit secrets
+kubeaudit secrets --secret-ref-severity info
+kubeaudit autofix -f "/path/to/manifest.yml" -k "/path/to/kubeaudit-config.yml" --fix secrets`,
+	Run: func(cmd *cobra.Command, args []string) {
+		auditor, err := secrets.New(secretsConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create secrets auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setSecretsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&secretsConfig.SecretRefSeverity, secretRefSeverityFlagName, "warning", "Severity of containers which inject secrets into environment variables (error, warning or info)")
+	cmd.Flags().Float64Var(&secretsConfig.MinEntropy, minEntropyFlagName, 4.0, "Entropy in bits per character above which random looking values are reported as secrets")
+}
+
+func init() {
+	RootCmd.AddCommand(secretsCmd)
+	setSecretsFlags(secretsCmd)
+}
@@ -0,0 +1,353 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/auth"
+	"github.com/Shopify/kubeaudit/internal/baseline"
+	"github.com/Shopify/kubeaudit/internal/grpcserver"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/internal/metrics"
+	"github.com/Shopify/kubeaudit/internal/notify"
+	"github.com/Shopify/kubeaudit/internal/restserver"
+	apiv1 "github.com/Shopify/kubeaudit/pkg/api/v1"
+)
+
+const (
+	metricsAddrFlagName     = "metrics-addr"
+	auditIntervalFlagName   = "interval"
+	grpcAddrFlagName        = "grpc-addr"
+	grpcTLSCertFileFlagName = "grpc-tls-cert-file"
+	grpcTLSKeyFileFlagName  = "grpc-tls-private-key-file"
+	resolvedTTLFlagName     = "resolved-findings-ttl"
+	listenFlagName          = "listen"
+	listenTLSCertFlagName   = "listen-tls-cert-file"
+	listenTLSKeyFlagName    = "listen-tls-private-key-file"
+	authTokenFileFlagName   = "auth-token-file"
+	insecureNoAuthFlagName  = "insecure-no-auth"
+	maxConcurrentFlagName   = "max-concurrent-audits"
+)
+
+var serveConfig struct {
+	configFile      string
+	metricsAddr     string
+	auditInterval   time.Duration
+	grpcAddr        string
+	grpcTLSCertFile string
+	grpcTLSKeyFile  string
+	resolvedTTL     time.Duration
+	listenAddr      string
+	listenTLSCert   string
+	listenTLSKey    string
+	authTokenFile   string
+	insecureNoAuth  bool
+	maxConcurrent   int
+}
+
+func serve(cmd *cobra.Command, args []string) {
+	if len(rootConfig.manifests) > 0 || rootConfig.kustomize != "" || rootConfig.helmChart != "" || rootConfig.gitURL != "" {
+		log.Fatal("serve is only supported in cluster and local mode")
+	}
+	if serveConfig.auditInterval < 0 {
+		log.Fatalf("--%s must not be negative", auditIntervalFlagName)
+	}
+	if serveConfig.auditInterval == 0 && serveConfig.listenAddr == "" && serveConfig.grpcAddr == "" {
+		log.Fatalf("--%s 0 disables the audits of the cluster, so --%s or --%s must be set to audit manifests", auditIntervalFlagName, listenFlagName, grpcAddrFlagName)
+	}
+	if serveConfig.maxConcurrent < 1 {
+		log.Fatalf("--%s must be at least 1", maxConcurrentFlagName)
+	}
+	if serveConfig.resolvedTTL < 0 {
+		log.Fatalf("--%s must not be negative", resolvedTTLFlagName)
+	}
+	checkRedactFlag()
+
+	auditor := initKubeaudit(getAllAuditors(cmd, serveConfig.configFile)...)
+	registerCustomResourceFlags()
+
+	var knownFindings *baseline.Baseline
+	if rootConfig.baseline != "" {
+		var err error
+		knownFindings, err = baseline.Load(rootConfig.baseline)
+		if err != nil {
+			log.WithError(err).Fatal("Error loading baseline")
+		}
+	}
+
+	exporter := metrics.NewExporter(serveConfig.resolvedTTL)
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", exporter.Handler())
+	server := &http.Server{Addr: serveConfig.metricsAddr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	notifier, waitNotifier := startNotifier(ctx)
+	defer waitNotifier()
+	var notified map[string]bool
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Fatal("Error serving metrics")
+		}
+	}()
+	log.Infof("Serving metrics on %s/metrics", serveConfig.metricsAddr)
+
+	minSeverity := getMinSeverity()
+	tokens := loadAuthTokens()
+	grpcServer, apiServer := startGRPCServer(auditor, minSeverity, tokens)
+	restServer := startRESTServer(auditor, minSeverity, tokens)
+
+	shutdown := func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Error("Error shutting down the metrics server")
+		}
+		if restServer != nil {
+			if err := restServer.Shutdown(shutdownCtx); err != nil {
+				log.WithError(err).Error("Error shutting down the API server")
+			}
+		}
+		if grpcServer != nil {
+			apiServer.Close()
+			grpcServer.GracefulStop()
+		}
+	}
+
+	// Only manifests submitted to the APIs are audited without an interval
+	if serveConfig.auditInterval == 0 {
+		<-ctx.Done()
+		shutdown()
+		return
+	}
+
+	clusterCache, err := newClusterCache()
+	if err != nil {
+		log.WithError(err).Fatal("Error connecting to the cluster")
+	}
+	defer clusterCache.Close()
+
+	ticker := time.NewTicker(serveConfig.auditInterval)
+	defer ticker.Stop()
+	for {
+		start := time.Now()
+		report, err := auditor.AuditClusterCache(clusterCache, getAuditOptions())
+		exporter.SetCacheStats(clusterCache.Stats())
+		if err != nil {
+			log.WithError(err).Error("Error auditing cluster")
+			exporter.AuditFailed()
+		} else {
+			if knownFindings != nil {
+				report, _ = knownFindings.Filter(report)
+			}
+			report = redactReport(report)
+			exporter.Update(report, minSeverity, time.Since(start))
+			if apiServer != nil {
+				apiServer.Publish(report)
+			}
+
+			var newFindings []notify.Finding
+			newFindings, notified = getNewFindings(notify.Findings(report, minSeverity), notified)
+			notifier.Notify(newFindings...)
+		}
+
+		select {
+		case <-ctx.Done():
+			shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// loadAuthTokens returns the bearer tokens of --auth-token-file which the clients of the APIs must authenticate with,
+// or nil if it is not set. The APIs are only served without authentication with --insecure-no-auth
+func loadAuthTokens() auth.Tokens {
+	if serveConfig.authTokenFile != "" && serveConfig.insecureNoAuth {
+		log.Fatalf("--%s and --%s can't be set together", authTokenFileFlagName, insecureNoAuthFlagName)
+	}
+	if serveConfig.authTokenFile == "" {
+		if serveConfig.listenAddr == "" && serveConfig.grpcAddr == "" {
+			return nil
+		}
+		if !serveConfig.insecureNoAuth {
+			log.Fatalf("--%s must be set to serve the APIs, or --%s to serve them without authentication", authTokenFileFlagName, insecureNoAuthFlagName)
+		}
+		log.Warnf("--%s is set, so anyone who can connect to the APIs can audit manifests", insecureNoAuthFlagName)
+		return nil
+	}
+	tokens, err := auth.LoadTokens(serveConfig.authTokenFile)
+	if err != nil {
+		log.WithError(err).Fatalf("Error loading --%s", authTokenFileFlagName)
+	}
+	return tokens
+}
+
+// startRESTServer serves the HTTP API on --listen, or returns nil if it is not set
+func startRESTServer(auditor *kubeaudit.Kubeaudit, minSeverity kubeaudit.SeverityLevel, tokens auth.Tokens) *http.Server {
+	if serveConfig.listenAddr == "" {
+		return nil
+	}
+	if (serveConfig.listenTLSCert == "") != (serveConfig.listenTLSKey == "") {
+		log.Fatalf("--%s and --%s must be set together", listenTLSCertFlagName, listenTLSKeyFlagName)
+	}
+
+	var handler http.Handler = restserver.NewHandler(auditor, restserver.Config{
+		MinSeverity:         minSeverity,
+		MaxConcurrentAudits: serveConfig.maxConcurrent,
+		Redact:              redactReport,
+	})
+	if tokens != nil {
+		handler = tokens.Handler(handler)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/v1/audit", handler)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{Addr: serveConfig.listenAddr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	listener, err := net.Listen("tcp", serveConfig.listenAddr)
+	if err != nil {
+		log.WithError(err).Fatal("Error listening for the API")
+	}
+	go func() {
+		var err error
+		if serveConfig.listenTLSCert != "" {
+			err = server.ServeTLS(listener, serveConfig.listenTLSCert, serveConfig.listenTLSKey)
+		} else {
+			err = server.Serve(listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Fatal("Error serving the API")
+		}
+	}()
+	log.Infof("Serving the API on %s/v1/audit", serveConfig.listenAddr)
+
+	return server
+}
+
+// startGRPCServer serves the gRPC API on --grpc-addr, or returns nil servers if it is not set
+func startGRPCServer(auditor *kubeaudit.Kubeaudit, minSeverity kubeaudit.SeverityLevel, tokens auth.Tokens) (*grpc.Server, *grpcserver.Server) {
+	if serveConfig.grpcAddr == "" {
+		return nil, nil
+	}
+	if (serveConfig.grpcTLSCertFile == "") != (serveConfig.grpcTLSKeyFile == "") {
+		log.Fatalf("--%s and --%s must be set together", grpcTLSCertFileFlagName, grpcTLSKeyFileFlagName)
+	}
+
+	var options []grpc.ServerOption
+	if serveConfig.grpcTLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(serveConfig.grpcTLSCertFile, serveConfig.grpcTLSKeyFile)
+		if err != nil {
+			log.WithError(err).Fatal("Error loading the gRPC TLS certificate")
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+	if tokens != nil {
+		options = append(options, grpc.UnaryInterceptor(tokens.UnaryServerInterceptor()), grpc.StreamInterceptor(tokens.StreamServerInterceptor()))
+	}
+
+	listener, err := net.Listen("tcp", serveConfig.grpcAddr)
+	if err != nil {
+		log.WithError(err).Fatal("Error listening for gRPC")
+	}
+
+	grpcServer := grpc.NewServer(options...)
+	apiServer := grpcserver.New(auditor, grpcserver.Config{MinSeverity: minSeverity, Redact: redactReport})
+	apiv1.RegisterKubeauditServer(grpcServer, apiServer)
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil {
+			log.WithError(err).Fatal("Error serving gRPC")
+		}
+	}()
+	log.Infof("Serving gRPC on %s", serveConfig.grpcAddr)
+
+	return grpcServer, apiServer
+}
+
+// newClusterCache returns the cache of the cluster kubeaudit is running in, or of the cluster of the local kubeconfig,
+// which the cluster is audited from on every interval so its resources are only listed once
+func newClusterCache() (*kubeaudit.ClusterCache, error) {
+	options := getAuditOptions()
+	if k8sinternal.UseInClusterConfig(k8sinternal.DefaultClient, rootConfig.kubeConfig) {
+		return kubeaudit.NewClusterCache(options)
+	}
+	return kubeaudit.NewLocalClusterCache(rootConfig.kubeConfig, getContext(), options)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Periodically audit the cluster and expose the findings as Prometheus metrics",
+	Long: `Periodically audit the cluster with all the enabled auditors and expose the findings as Prometheus metrics,
+so that regressions can be alerted on and the security posture of the cluster can be graphed over time.
+
+The resources of the cluster are cached by informers which watch the cluster, so they are only listed from the API
+server once and every audit is served from the caches. The first audit waits up to --cache-warmup-timeout for the
+caches to sync. The number of audits of a resource type served from a warm cache and the number which were not are
+exported as kubeaudit_cache_hits_total and kubeaudit_cache_misses_total.
+
+The number of findings of the latest audit is exported as kubeaudit_findings{auditor,rule,severity,namespace,resource}.
+Findings which are fixed stop being exported after the next audit. Findings which were not in the previous audit
+can also be sent to an HTTP endpoint with --notify-url.
+
+The time each finding was first and last reported is exported as kubeaudit_finding_first_seen_timestamp_seconds and
+kubeaudit_finding_last_seen_timestamp_seconds. When a finding is no longer reported, the time since it was first
+reported is observed in the kubeaudit_finding_resolution_seconds histogram, and its timestamps are still exported for
+--resolved-findings-ttl. The history is kept in memory and starts over when kubeaudit restarts.
+
+With --grpc-addr, a gRPC API (see pkg/api/v1/kubeaudit.proto) is also served to audit manifests with the same
+auditors and to stream the new findings of every audit.
+
+With --listen, an HTTP API is served to audit manifests on demand, for developer portals and bots. POST one or more
+YAML or JSON documents to /v1/audit to get their findings as JSON, or as SARIF with ?format=sarif or an Accept header
+of application/sarif+json. ?minSeverity=warning overrides --minseverity. Up to --max-concurrent-audits manifests are
+audited at the same time and the other requests wait. With --interval 0 the cluster is not audited and only the
+manifests submitted to the APIs are, so kubeaudit serve can run outside of a cluster.
+
+The clients of both APIs must send one of the bearer tokens of --auth-token-file, one per line, in the Authorization
+header or the "authorization" gRPC metadata, eg. "Authorization: Bearer <token>".
+
+Example usage:
+kubeaudit serve
+kubeaudit serve --metrics-addr :9090 --interval 10m
+kubeaudit serve -k /path/to/kubeaudit-config.yaml --minseverity warning
+kubeaudit serve --notify-url https://findings.example.com/kubeaudit --notify-spool-dir /var/lib/kubeaudit/spool
+kubeaudit serve --grpc-addr :9443 --grpc-tls-cert-file /certs/tls.crt --grpc-tls-private-key-file /certs/tls.key --auth-token-file /secrets/tokens
+kubeaudit serve --listen :9090 --interval 0 --auth-token-file /secrets/tokens`,
+	Run: serve,
+}
+
+func init() {
+	RootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVarP(&serveConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+	serveCmd.Flags().StringVar(&serveConfig.metricsAddr, metricsAddrFlagName, ":8080", "Address to serve the metrics on")
+	serveCmd.Flags().DurationVar(&serveConfig.auditInterval, auditIntervalFlagName, 5*time.Minute, "Time between audits of the cluster. 0 disables the audits of the cluster, to only audit the manifests submitted to the APIs")
+	serveCmd.Flags().DurationVar(&serveConfig.resolvedTTL, resolvedTTLFlagName, 24*time.Hour, "Time the first and last seen timestamps of resolved findings are still exported for")
+	serveCmd.Flags().StringVar(&serveConfig.grpcAddr, grpcAddrFlagName, "", "Address to serve the gRPC API on. The gRPC API is not served if it is empty")
+	serveCmd.Flags().StringVar(&serveConfig.grpcTLSCertFile, grpcTLSCertFileFlagName, "", "Path to the TLS certificate of the gRPC API. The gRPC API is served without TLS if it is empty")
+	serveCmd.Flags().StringVar(&serveConfig.grpcTLSKeyFile, grpcTLSKeyFileFlagName, "", "Path to the TLS private key of the gRPC API")
+	serveCmd.Flags().StringVar(&serveConfig.listenAddr, listenFlagName, "", "Address to serve the HTTP API on, which audits the manifests POSTed to /v1/audit. The HTTP API is not served if it is empty")
+	serveCmd.Flags().StringVar(&serveConfig.listenTLSCert, listenTLSCertFlagName, "", "Path to the TLS certificate of the HTTP API. The HTTP API is served without TLS if it is empty")
+	serveCmd.Flags().StringVar(&serveConfig.listenTLSKey, listenTLSKeyFlagName, "", "Path to the TLS private key of the HTTP API")
+	serveCmd.Flags().StringVar(&serveConfig.authTokenFile, authTokenFileFlagName, "", "Path to a file of bearer tokens, one per line, which the clients of the HTTP and gRPC APIs must send. Required to serve the APIs unless --insecure-no-auth is set")
+	serveCmd.Flags().BoolVar(&serveConfig.insecureNoAuth, insecureNoAuthFlagName, false, "Serve the HTTP and gRPC APIs without authenticating clients, so anyone who can connect to them can audit manifests")
+	serveCmd.Flags().IntVar(&serveConfig.maxConcurrent, maxConcurrentFlagName, runtime.NumCPU(), "Maximum number of manifests submitted to the HTTP API to audit at the same time")
+	setNotifyFlags(serveCmd)
+	setAllAuditorFlags(serveCmd)
+}
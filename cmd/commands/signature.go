@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Shopify/kubeaudit/internal/signature"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var verifyReportConfig struct {
+	key string
+}
+
+// checkSignReportFlags exits if the flags used with --sign-report are invalid
+func checkSignReportFlags() {
+	if rootConfig.signature == "" {
+		log.Fatal("--signature is required with --sign-report")
+	}
+	if rootConfig.format == "pretty" {
+		log.Fatal("--sign-report does not support the pretty format")
+	}
+}
+
+// writeSignedReport writes the report to standard out and its signature to the file set with --signature
+func writeSignedReport(report []byte) {
+	keyData, err := os.ReadFile(rootConfig.signReport)
+	if err != nil {
+		log.WithError(err).Fatal("Error reading signing key")
+	}
+	key, err := signature.ParsePrivateKey(keyData)
+	if err != nil {
+		log.WithError(err).Fatal("Error parsing signing key")
+	}
+
+	reportSignature, err := signature.Sign(report, key)
+	if err != nil {
+		log.WithError(err).Fatal("Error signing report")
+	}
+
+	if _, err := os.Stdout.Write(report); err != nil {
+		log.WithError(err).Fatal("Error writing report")
+	}
+	if err := os.WriteFile(rootConfig.signature, append(reportSignature, '\n'), 0644); err != nil {
+		log.WithError(err).Fatal("Error writing signature file")
+	}
+}
+
+func verifyReport(cmd *cobra.Command, args []string) {
+	if rootConfig.signature == "" {
+		log.Fatal("--signature is required")
+	}
+
+	var report []byte
+	var err error
+	if args[0] == "-" {
+		report, err = io.ReadAll(os.Stdin)
+	} else {
+		report, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		log.WithError(err).Fatal("Error reading report")
+	}
+
+	keyData, err := os.ReadFile(verifyReportConfig.key)
+	if err != nil {
+		log.WithError(err).Fatal("Error reading public key")
+	}
+	key, err := signature.ParsePublicKey(keyData)
+	if err != nil {
+		log.WithError(err).Fatal("Error parsing public key")
+	}
+
+	reportSignature, err := os.ReadFile(rootConfig.signature)
+	if err != nil {
+		log.WithError(err).Fatal("Error reading signature file")
+	}
+
+	if err := signature.Verify(report, reportSignature, key); err != nil {
+		log.WithError(err).Fatal("Report verification failed")
+	}
+
+	reportName := args[0]
+	if reportName == "-" {
+		reportName = "the report"
+	}
+	fmt.Fprintf(os.Stderr, "The signature of %s is valid\n", reportName)
+}
+
+var verifyReportCmd = &cobra.Command{
+	Use:   "verify-report <report>",
+	Short: "Verify the signature of a report",
+	Long: `This command verifies the detached signature of a report signed with --sign-report, so archived reports can be
+checked for changes. The report must be byte for byte the same as the one kubeaudit wrote. Use - to read the report
+from standard in. kubeaudit exits with a non-zero code if the signature is not valid.
+
+Example usage:
+kubeaudit all -f /path/to/yaml --format sarif --sign-report private.pem --signature report.jws > report.sarif
+kubeaudit verify-report report.sarif --key public.pem --signature report.jws
+`,
+	Args: cobra.ExactArgs(1),
+	Run:  verifyReport,
+}
+
+func init() {
+	RootCmd.AddCommand(verifyReportCmd)
+	verifyReportCmd.Flags().StringVar(&verifyReportConfig.key, "key", "", "Path to the PEM encoded public key or certificate of the key the report was signed with")
+	verifyReportCmd.MarkFlagRequired("key")
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/volumes"
+	"github.com/spf13/cobra"
+)
+
+var volumesConfig volumes.Config
+
+const (
+	maxTokenExpirationFlagName = "max-token-expiration"
+	allowedCSIDriversFlagName  = "allowed-csi-drivers"
+)
+
+var volumesCmd = &cobra.Command{
+	Use:   "volumes",
+	Short: "Audit pods with risky service account token, CSI and Secret volumes",
+	Long: `This command determines which pods mount the long-lived token Secret of a service account instead of a projected
+token which expires, projected service account tokens which expire too late, inline CSI volumes of drivers which are
+not allowed and Secret volumes whose files can be read by any user.
+
+An ERROR result is generated when a pod mounts a legacy service account token Secret.
+
+A WARN result is generated for each of the following cases:
+  - A projected service account token expires after more than '--max-token-expiration' seconds
+  - A pod has an inline CSI volume of a driver which is not in '--allowed-csi-drivers'
+  - The defaultMode or the mode of an item of a Secret volume lets other users read its files
+
+Example usage:
+kubeaudit volumes --max-token-expiration 3600 --allowed-csi-drivers "secrets-store.csi.k8s.io"`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runAudit(volumes.New(volumesConfig))(cmd, args)
+	},
+}
+
+func setVolumesFlags(cmd *cobra.Command) {
+	cmd.Flags().Int64Var(&volumesConfig.MaxTokenExpirationSeconds, maxTokenExpirationFlagName, volumes.DefaultMaxTokenExpirationSeconds,
+		"Maximum expirationSeconds of projected service account tokens")
+	cmd.Flags().StringSliceVar(&volumesConfig.AllowedCSIDrivers, allowedCSIDriversFlagName, nil,
+		"List of CSI drivers pods may use in inline volumes")
+}
+
+func init() {
+	RootCmd.AddCommand(volumesCmd)
+	setVolumesFlags(volumesCmd)
+}
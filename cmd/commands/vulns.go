@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/vulns"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var vulnsConfig vulns.Config
+
+const (
+	scannerFlagName        = "scanner"
+	scannerPathFlagName    = "scanner-path"
+	trivyServerFlagName    = "trivy-server"
+	vulnSeveritiesFlagName = "vuln-severities"
+)
+
+var vulnsCmd = &cobra.Command{
+	Use:   "vulns",
+	Short: "Audit images with known vulnerabilities",
+	Long: `This command scans the image of each container for known vulnerabilities with Trivy or Grype, and reports
+the vulnerabilities of the severities set by '--vuln-severities'. The executable of the scanner must be in the PATH,
+or set with '--scanner-path'. With Trivy, images can be scanned by a Trivy server set with '--trivy-server' instead of
+the local Trivy database. This auditor is optional, so it is only run by 'kubeaudit all' if it is enabled in the
+kubeaudit config.
+
+Each image is only scanned once, even if many containers use it. Images are also identified by their digest when it is
+known, from their reference or from the status of the pods running them, so the tags of an image which was already
+scanned are not scanned again.
+
+An ERROR result is generated for each critical vulnerability, a WARN result for each high vulnerability and an INFO
+result for each medium and low vulnerability. An INFO result is generated for images which could not be scanned.
+
+Example usage:
+kubeaudit vulns
+kubeaudit vulns --scanner grype
+kubeaudit vulns --trivy-server http://trivy.trivy-system:4954 --vuln-severities CRITICAL,HIGH,MEDIUM`,
+	Run: func(cmd *cobra.Command, args []string) {
+		vulnsConfig.Offline = rootConfig.offline
+		auditor, err := vulns.New(vulnsConfig)
+		if err != nil {
+			log.WithError(err).Fatal("failed to create vulns auditor")
+		}
+		runAudit(auditor)(cmd, args)
+	},
+}
+
+func setVulnsFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&vulnsConfig.Scanner, scannerFlagName, vulns.ScannerTrivy, "Scanner which scans the images (trivy or grype)")
+	cmd.Flags().StringVar(&vulnsConfig.Path, scannerPathFlagName, "", "Path of the executable of the scanner (default the scanner in the PATH)")
+	cmd.Flags().StringVar(&vulnsConfig.Server, trivyServerFlagName, "", "URL of a Trivy server which scans the images")
+	cmd.Flags().StringSliceVar(&vulnsConfig.Severities, vulnSeveritiesFlagName, vulns.DefaultSeverities, "Severities of the vulnerabilities to report (CRITICAL, HIGH, MEDIUM or LOW)")
+}
+
+func init() {
+	RootCmd.AddCommand(vulnsCmd)
+	setVulnsFlags(vulnsCmd)
+}
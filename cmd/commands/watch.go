@@ -0,0 +1,110 @@
+package commands
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/baseline"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/internal/notify"
+)
+
+// runWatch watches the cluster and prints the results for each workload as it is created or updated, until kubeaudit
+// is interrupted. Results are only printed when the findings for a resource change, so resyncs and status updates
+// do not repeat them. New findings are also sent to --notify-url if it is set
+func runWatch(auditable ...kubeaudit.Auditable) {
+	if len(rootConfig.manifests) > 0 || rootConfig.kustomize != "" || rootConfig.helmChart != "" || rootConfig.gitURL != "" {
+		log.Fatal("--watch is only supported in cluster and local mode")
+	}
+	if rootConfig.format == "sarif" || rootConfig.format == "junit" || rootConfig.format == "cyclonedx" {
+		log.Fatalf("--watch does not support the %s format", rootConfig.format)
+	}
+	if rootConfig.signReport != "" {
+		log.Fatal("--watch does not support --sign-report")
+	}
+	checkRedactFlag()
+
+	auditor := initKubeaudit(auditable...)
+	registerCustomResourceFlags()
+
+	var knownFindings *baseline.Baseline
+	if rootConfig.baseline != "" {
+		var err error
+		knownFindings, err = baseline.Load(rootConfig.baseline)
+		if err != nil {
+			log.WithError(err).Fatal("Error loading baseline")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	notifier, waitNotifier := startNotifier(ctx)
+	defer waitNotifier()
+
+	printOptions := getPrintOptions()
+	minSeverity := getMinSeverity()
+	findings := map[string]string{}
+	notified := map[string]map[string]bool{}
+
+	handler := func(key string, report *kubeaudit.Report) {
+		if report == nil {
+			delete(findings, key)
+			delete(notified, key)
+			return
+		}
+
+		if knownFindings != nil {
+			report, _ = knownFindings.Filter(report)
+		}
+
+		fingerprints := getFingerprints(report, minSeverity)
+		if previous, ok := findings[key]; ok && previous == fingerprints {
+			return
+		}
+		findings[key] = fingerprints
+		if fingerprints == "" {
+			delete(notified, key)
+			return
+		}
+
+		report = redactReport(report)
+		report.PrintResults(printOptions...)
+
+		var newFindings []notify.Finding
+		newFindings, notified[key] = getNewFindings(notify.Findings(report, minSeverity), notified[key])
+		notifier.Notify(newFindings...)
+	}
+
+	options := getAuditOptions()
+	if k8sinternal.UseInClusterConfig(k8sinternal.DefaultClient, rootConfig.kubeConfig) {
+		if err := auditor.WatchCluster(ctx, options, handler); err != nil {
+			log.WithError(err).Fatal("Error watching cluster")
+		}
+		return
+	}
+
+	if err := auditor.WatchLocal(ctx, rootConfig.kubeConfig, getContext(), options, handler); err != nil {
+		log.WithError(err).Fatal("Error watching cluster in local mode")
+	}
+}
+
+// getFingerprints returns the sorted fingerprints of the results in the report with at least the minimum severity,
+// joined into a single string so the findings of a resource can be compared
+func getFingerprints(report *kubeaudit.Report, minSeverity kubeaudit.SeverityLevel) string {
+	var fingerprints []string
+	for _, result := range report.ResultsWithMinSeverity(minSeverity) {
+		for _, auditResult := range result.GetAuditResults() {
+			fingerprints = append(fingerprints, baseline.Fingerprint(result.GetResource(), auditResult))
+		}
+	}
+	sort.Strings(fingerprints)
+	return strings.Join(fingerprints, ",")
+}
@@ -0,0 +1,106 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/webhook"
+)
+
+const (
+	webhookAddrFlagName           = "addr"
+	webhookTLSCertFileFlagName    = "tls-cert-file"
+	webhookTLSKeyFileFlagName     = "tls-private-key-file"
+	webhookRejectSeverityFlagName = "reject-severity"
+	webhookAuditModeFlagName      = "audit-mode"
+)
+
+var webhookConfig struct {
+	configFile     string
+	addr           string
+	tlsCertFile    string
+	tlsKeyFile     string
+	rejectSeverity string
+	auditMode      bool
+}
+
+func runWebhook(cmd *cobra.Command, args []string) {
+	if webhookConfig.tlsCertFile == "" || webhookConfig.tlsKeyFile == "" {
+		log.Fatalf("--%s and --%s are required", webhookTLSCertFileFlagName, webhookTLSKeyFileFlagName)
+	}
+	// The auditors are created first, since the reject severity can be a custom severity of the kubeaudit config
+	auditor := initKubeaudit(getAllAuditors(cmd, webhookConfig.configFile)...)
+	rejectSeverity, err := kubeaudit.ParseSeverity(webhookConfig.rejectSeverity)
+	if err != nil {
+		log.WithError(err).Fatalf("invalid --%s", webhookRejectSeverityFlagName)
+	}
+	registerCustomResourceFlags()
+
+	mux := http.NewServeMux()
+	mux.Handle("/validate", webhook.NewHandler(auditor, webhook.Config{
+		RejectSeverity:   rejectSeverity,
+		AuditMode:        webhookConfig.auditMode,
+		IncludeGenerated: rootConfig.includeGenerated,
+	}))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	server := &http.Server{Addr: webhookConfig.addr, Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.WithError(err).Error("Error shutting down the webhook server")
+		}
+	}()
+
+	log.Infof("Serving admission webhook on %s/validate", webhookConfig.addr)
+	if err := server.ListenAndServeTLS(webhookConfig.tlsCertFile, webhookConfig.tlsKeyFile); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.WithError(err).Fatal("Error serving admission webhook")
+	}
+}
+
+var webhookCmd = &cobra.Command{
+	Use:   "webhook",
+	Short: "Run a validating admission webhook which audits workloads as they are admitted",
+	Long: `Run an HTTPS validating admission webhook which audits workloads as they are created or updated, and rejects
+those with findings of at least the reject severity. In audit mode the workloads are admitted and the findings are
+returned to the client as warnings.
+
+Each resource is audited on its own, so auditors which need other resources as context, such as netpols, do not
+report findings in the webhook. Generated resources, such as pods created by deployments, are admitted without being
+audited unless --includegenerated is set.
+
+The webhook serves admission reviews at /validate and a health check at /healthz.
+
+Example usage:
+kubeaudit webhook --tls-cert-file /certs/tls.crt --tls-private-key-file /certs/tls.key
+kubeaudit webhook --tls-cert-file /certs/tls.crt --tls-private-key-file /certs/tls.key --audit-mode
+kubeaudit webhook -k /path/to/kubeaudit-config.yaml --tls-cert-file /certs/tls.crt --tls-private-key-file /certs/tls.key --reject-severity warning`,
+	Run: runWebhook,
+}
+
+func init() {
+	RootCmd.AddCommand(webhookCmd)
+	webhookCmd.Flags().StringVarP(&webhookConfig.configFile, "kconfig", "k", "", "Path to kubeaudit config")
+	webhookCmd.Flags().StringVar(&webhookConfig.addr, webhookAddrFlagName, ":8443", "Address to serve the webhook on")
+	webhookCmd.Flags().StringVar(&webhookConfig.tlsCertFile, webhookTLSCertFileFlagName, "", "Path to the TLS certificate of the webhook")
+	webhookCmd.Flags().StringVar(&webhookConfig.tlsKeyFile, webhookTLSKeyFileFlagName, "", "Path to the TLS private key of the webhook")
+	webhookCmd.Flags().StringVar(&webhookConfig.rejectSeverity, webhookRejectSeverityFlagName, "error", "Lowest severity of the findings which cause a resource to be rejected (one of \"error\", \"warning\", \"info\")")
+	webhookCmd.Flags().BoolVar(&webhookConfig.auditMode, webhookAuditModeFlagName, false, "Admit resources with findings and return the findings as warnings instead of rejecting them")
+	setAllAuditorFlags(webhookCmd)
+}
@@ -1,36 +1,160 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
 
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/annotations"
+	"github.com/Shopify/kubeaudit/auditors/asat"
 	"github.com/Shopify/kubeaudit/auditors/deprecatedapis"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	"github.com/Shopify/kubeaudit/auditors/etcd"
 	"github.com/Shopify/kubeaudit/auditors/mounts"
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
+	"github.com/Shopify/kubeaudit/auditors/ports"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/rego"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	"github.com/Shopify/kubeaudit/auditors/volumes"
+	"github.com/Shopify/kubeaudit/auditors/vulns"
 
 	"github.com/Shopify/kubeaudit/auditors/capabilities"
 	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	"github.com/Shopify/kubeaudit/auditors/labels"
 	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"gopkg.in/yaml.v3"
 )
 
+// New loads a config. Unknown fields, such as misspelled fields, and values which are not one of the values a field
+// accepts are rejected with Errors which give the line of each problem
 func New(configData io.Reader) (KubeauditConfig, error) {
 	configBytes, err := ioutil.ReadAll(configData)
 	if err != nil {
 		return KubeauditConfig{}, err
 	}
 
+	root := &yaml.Node{}
+	if err := yaml.Unmarshal(configBytes, root); err != nil {
+		return KubeauditConfig{}, err
+	}
+
 	config := KubeauditConfig{}
-	err = yaml.Unmarshal(configBytes, &config)
-	if err != nil {
+	decoder := yaml.NewDecoder(bytes.NewReader(configBytes))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			return KubeauditConfig{}, newTypeErrors(root, typeErr)
+		}
 		return KubeauditConfig{}, err
 	}
+	config.node = root
 
+	if errs := config.validate(); len(errs) > 0 {
+		return KubeauditConfig{}, errs
+	}
 	return config, nil
 }
 
 type KubeauditConfig struct {
-	EnabledAuditors map[string]bool `yaml:"enabledAuditors"`
-	AuditorConfig   AuditorConfig   `yaml:"auditors"`
+	EnabledAuditors    map[string]bool        `yaml:"enabledAuditors"`
+	AuditorConfig      AuditorConfig          `yaml:"auditors"`
+	CustomResources    []k8s.PodSpecExtractor `yaml:"customResources"`
+	Rules              map[string]RuleConfig  `yaml:"rules"`
+	ExcludedNamespaces []string               `yaml:"excludedNamespaces"`
+	// Exclusions select the resources whose findings are not reported, such as the resources of vendored third-party
+	// manifests which can't be changed
+	Exclusions Exclusions `yaml:"exclusions"`
+	// Locale selects the catalog of Messages used for the audit results, such as "fr" or "pt_BR". The locale of the
+	// environment (LC_ALL, LC_MESSAGES or LANG) is used if it is not set
+	Locale string `yaml:"locale"`
+	// Messages are message catalogs by locale, which replace the messages of the audit results of rules
+	Messages map[string]map[string]string `yaml:"messages"`
+	// Severities are custom severity levels, ordered relative to the built-in levels and to each other, which can be
+	// used as the severity of rules and of the severity flags
+	Severities []kubeaudit.SeverityDefinition `yaml:"severities"`
+	// Offline keeps the auditors from accessing the network, for air-gapped environments, and notifications from being
+	// sent. Auditors which need the network use their local caches or skip their checks, with the reason in their
+	// results
+	Offline bool `yaml:"offline"`
+	// NamespaceClasses classify namespaces, such as kube-system as "platform" and the namespaces of teams as "tenant",
+	// to adjust the severity of the audit results of their resources to the risk accepted for each class. A namespace
+	// is in the first class which matches it
+	NamespaceClasses []NamespaceClass `yaml:"namespaceClasses"`
+	// Notifications are the receivers the findings are sent to after an audit of the all command, or as they are found
+	// in watch mode and with the serve command
+	Notifications []NotificationConfig `yaml:"notifications"`
+
+	// node is the YAML the config was loaded from, to find the lines of its values
+	node *yaml.Node
+}
+
+// NotificationConfig configures a receiver of findings
+type NotificationConfig struct {
+	// Type is the format of the requests: "webhook" (the default) POSTs the findings as JSON, "slack" as the message of
+	// a Slack incoming webhook and "pagerduty" as an alert of the PagerDuty Events API v2
+	Type string `yaml:"type"`
+	// URL is the endpoint the findings are POSTed to. It is optional with the pagerduty type. Environment variables are
+	// expanded, so secret URLs don't need to be in the config file
+	URL string `yaml:"url"`
+	// RoutingKey is the integration key of the PagerDuty service. Environment variables are expanded
+	RoutingKey string `yaml:"routingKey"`
+	// MinSeverity is the lowest severity of the findings sent to the receiver, such as "error"
+	MinSeverity string `yaml:"minSeverity"`
+	// Template replaces the body of the requests. It is a Go template with the Findings of the batch and a json
+	// function, such as {"text": {{printf "%d findings" (len .Findings) | json}}}
+	Template string `yaml:"template"`
+}
+
+// RuleConfig configures a single rule of an auditor, such as ImageTagMissing
+type RuleConfig struct {
+	// Enabled disables the rule if it is set to false
+	Enabled *bool `yaml:"enabled"`
+	// Severity replaces the severity of the audit results of the rule. One of "error", "warning", "info" or a custom
+	// severity
+	Severity string `yaml:"severity"`
+	// WarnUntil is a date in the form YYYY-MM-DD until which the error results of the rule are reported as warnings,
+	// such as for a rule added by an upgrade of kubeaudit. The results fail the audit again from the date on (UTC)
+	WarnUntil string `yaml:"warnUntil"`
+	// Message replaces the message of the audit results of the rule. It is a Go template with the Rule, Auditor,
+	// Severity, Message and Metadata of the audit result, such as "{{.Message}} See https://runbooks/{{.Rule}}"
+	Message string `yaml:"message"`
+}
+
+// NamespaceClass is a class of namespaces and the severity modifiers of the audit results of their resources. The
+// modifiers apply before the rules config, so the severity and warnUntil of a rule take precedence
+type NamespaceClass struct {
+	// Name is the name of the class, such as "platform"
+	Name string `yaml:"name"`
+	// Namespaces are glob patterns, with the syntax of filepath.Match, which match the whole names of the namespaces of
+	// the class, such as "kube-*"
+	Namespaces []string `yaml:"namespaces"`
+	// Adjust moves the severity of the audit results up by this number of severity levels, or down for negative
+	// values, counting the custom severities. Severities don't move past the highest and lowest levels
+	Adjust int `yaml:"adjust"`
+	// Severities replace the severity of the audit results of rules, such as "PrivilegedTrue: info". They take
+	// precedence over Adjust
+	Severities map[string]string `yaml:"severities"`
+}
+
+// Exclusions select resources by kind, name or manifest path. A resource is excluded if it matches any of them
+type Exclusions struct {
+	// Kinds are the kinds of the excluded resources, such as "Job". They are matched regardless of case
+	Kinds []string `yaml:"kinds"`
+	// Names are regular expressions which must match the whole name of the excluded resources
+	Names []string `yaml:"names"`
+	// Paths are glob patterns, with the syntax of filepath.Match, which match the manifest files of the excluded
+	// resources or one of their directories, such as "vendor/*". Only used in manifest mode
+	Paths []string `yaml:"paths"`
 }
 
 func (conf *KubeauditConfig) GetEnabledAuditors() map[string]bool {
@@ -40,6 +164,13 @@ func (conf *KubeauditConfig) GetEnabledAuditors() map[string]bool {
 	return conf.EnabledAuditors
 }
 
+func (conf *KubeauditConfig) GetRuleConfigs() map[string]RuleConfig {
+	if conf == nil {
+		return map[string]RuleConfig{}
+	}
+	return conf.Rules
+}
+
 func (conf *KubeauditConfig) GetAuditorConfigs() AuditorConfig {
 	if conf == nil {
 		return AuditorConfig{}
@@ -48,9 +179,26 @@ func (conf *KubeauditConfig) GetAuditorConfigs() AuditorConfig {
 }
 
 type AuditorConfig struct {
+	Annotations    annotations.Config    `yaml:"annotations"`
+	ASAT           asat.Config           `yaml:"asat"`
 	Capabilities   capabilities.Config   `yaml:"capabilities"`
-	DeprecatedAPIs deprecatedapis.Config `yaml:"config"`
+	DeprecatedAPIs deprecatedapis.Config `yaml:"deprecatedapis"`
+	Egress         egress.Config         `yaml:"egress"`
+	Ephemeral      ephemeral.Config      `yaml:"ephemeral"`
+	Etcd           etcd.Config           `yaml:"etcd"`
 	Image          image.Config          `yaml:"image"`
+	ImagePolicy    imagepolicy.Config    `yaml:"imagepolicy"`
+	Labels         labels.Config         `yaml:"labels"`
 	Limits         limits.Config         `yaml:"limits"`
 	Mounts         mounts.Config         `yaml:"mounts"`
+	NodeCoverage   nodecoverage.Config   `yaml:"nodecoverage"`
+	Ports          ports.Config          `yaml:"ports"`
+	PSS            pss.Config            `yaml:"pss"`
+	Rego           rego.Config           `yaml:"rego"`
+	Requests       requests.Config       `yaml:"requests"`
+	Resilience     resilience.Config     `yaml:"resilience"`
+	RuntimeClass   runtimeclass.Config   `yaml:"runtimeclass"`
+	Secrets        secrets.Config        `yaml:"secrets"`
+	Volumes        volumes.Config        `yaml:"volumes"`
+	Vulns          vulns.Config          `yaml:"vulns"`
 }
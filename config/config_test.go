@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/Shopify/kubeaudit/auditors/all"
@@ -21,4 +22,105 @@ func TestConfig(t *testing.T) {
 	require.NoError(t, err)
 
 	assert.Equal(t, len(all.AuditorNames), len(conf.GetEnabledAuditors()), "Config is missing auditors")
+
+	_, err = all.Auditors(conf)
+	assert.NoError(t, err, "Config is invalid")
+}
+
+// Test that the published schema is up to date with the config structs
+func TestSchema(t *testing.T) {
+	expected, err := os.ReadFile("schema.json")
+	require.NoError(t, err)
+
+	schema, err := config.Schema()
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(schema), "schema.json is outdated, regenerate it with make schema")
+}
+
+func TestNewInvalid(t *testing.T) {
+	cases := []struct {
+		testName       string
+		data           string
+		expectedErrors []string
+	}{
+		{
+			"unknown field",
+			"enabledAuditors:\n  apparmor: false\nauditor:\n  limits:\n    cpu: 750m\n",
+			[]string{`line 3: unknown field "auditor", did you mean "auditors"?`},
+		},
+		{
+			"unknown nested field",
+			"auditors:\n  pss:\n    levl: baseline\n",
+			[]string{`line 3: unknown field "auditors.pss.levl", did you mean "level"?`},
+		},
+		{
+			"unknown field in a list",
+			"notifications:\n  - type: slack\n    urll: https://hooks.slack.com/x\n",
+			[]string{`line 3: unknown field "notifications.0.urll", did you mean "url"?`},
+		},
+		{
+			"invalid type",
+			"enabledAuditors:\n  apparmor: nope\n",
+			[]string{"line 2: cannot unmarshal !!str `nope` into bool"},
+		},
+		{
+			"invalid severities",
+			"severities:\n  - name: critical\n    above: eror\nrules:\n  PrivilegedTrue:\n    severity: critical\n  ImageTagMissing:\n    severity: low\n",
+			[]string{
+				`line 3: invalid severity "eror" for severities.0.above, expected one of error, warning, info or a severity defined in severities`,
+				`line 8: invalid severity "low" for rules.ImageTagMissing.severity, expected one of error, warning, info or a severity defined in severities`,
+			},
+		},
+		{
+			"invalid capability",
+			"auditors:\n  capabilities:\n    allowAddList:\n      - KILL\n      - CAP_CHOWN\n      - TIME_TRAVEL\n",
+			[]string{
+				`line 5: unknown capability "CAP_CHOWN" in auditors.capabilities.allowAddList, did you mean "CHOWN"?`,
+				`line 6: unknown capability "TIME_TRAVEL" in auditors.capabilities.allowAddList`,
+			},
+		},
+		{
+			"invalid profile capability",
+			"auditors:\n  capabilities:\n    profiles:\n      net-tools: [NET_RAW, CAP_NET_ADMIN]\n",
+			[]string{`line 4: unknown capability "CAP_NET_ADMIN" in auditors.capabilities.profiles.net-tools, did you mean "NET_ADMIN"?`},
+		},
+		{
+			"invalid namespace class",
+			"namespaceClasses:\n  - name: platform\n    namespaces: [kube-*, \"[\"]\n    severities:\n      PrivilegedTrue: low\n  - name: platform\n",
+			[]string{
+				`line 3: invalid namespace pattern "[" of namespace class "platform": syntax error in pattern`,
+				`line 5: invalid severity "low" for namespaceClasses.0.severities.PrivilegedTrue, expected one of error, warning, info or a severity defined in severities`,
+				`line 6: duplicate namespace class "platform"`,
+			},
+		},
+		{
+			"invalid notification type",
+			"notifications:\n  - type: teams\n    url: https://example.com\n",
+			[]string{`line 2: invalid notification type "teams", expected one of webhook, slack, pagerduty`},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			_, err := config.New(strings.NewReader(tc.data))
+			require.Error(t, err)
+			assert.Equal(t, strings.Join(tc.expectedErrors, "\n"), err.Error())
+		})
+	}
+}
+
+func TestCheckEnabledAuditors(t *testing.T) {
+	conf, err := config.New(strings.NewReader("enabledAuditors:\n  privileged: true\n  apparmr: false\n  myplugin: true\n"))
+	require.NoError(t, err)
+
+	err = conf.CheckEnabledAuditors(append([]string{"myplugin"}, all.AuditorNames...))
+	require.Error(t, err)
+	assert.Equal(t, `line 3: unknown auditor "apparmr" in enabledAuditors, did you mean "apparmor"?`, err.Error())
+
+	assert.NoError(t, conf.CheckEnabledAuditors(append([]string{"myplugin", "apparmr"}, all.AuditorNames...)))
+}
+
+func TestNewEmpty(t *testing.T) {
+	_, err := config.New(strings.NewReader(""))
+	assert.NoError(t, err)
 }
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/Shopify/kubeaudit/internal/notify"
+)
+
+// SchemaID is the URL the JSON schema of the config is published at
+const SchemaID = "https://raw.githubusercontent.com/Shopify/kubeaudit/main/config/schema.json"
+
+// schemaEnums are the values accepted by the fields which only accept some values, by type and field name
+var schemaEnums = map[string][]string{
+	"config.NotificationConfig.Type": {notify.TypeWebhook, notify.TypeSlack, notify.TypePagerDuty},
+}
+
+// Schema returns the JSON schema of the config, generated from the config structs. Editors with YAML language support
+// use it to complete and check config files
+func Schema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(KubeauditConfig{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaID
+	schema["title"] = "kubeaudit config"
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return typeSchema(t.Elem())
+	case reflect.Struct:
+		properties := map[string]interface{}{}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := yamlName(field)
+			if name == "" {
+				continue
+			}
+			property := typeSchema(field.Type)
+			if enum, ok := schemaEnums[t.String()+"."+field.Name]; ok {
+				property["enum"] = enum
+			}
+			properties[name] = property
+		}
+		return map[string]interface{}{"type": "object", "properties": properties, "additionalProperties": false}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	}
+	return map[string]interface{}{}
+}
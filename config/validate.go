@@ -0,0 +1,395 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/capabilities"
+	"github.com/Shopify/kubeaudit/internal/notify"
+)
+
+// Error is a problem of a config file
+type Error struct {
+	// Line is the line of the config file the problem is at, or 0 if it is unknown
+	Line    int
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Line == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// Errors are the problems of a config file, ordered by line
+type Errors []*Error
+
+func (errs Errors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "\n")
+}
+
+func (errs Errors) sorted() Errors {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Line < errs[j].Line })
+	return errs
+}
+
+var (
+	typeErrorPattern    = regexp.MustCompile(`^line (\d+): (.*)$`)
+	unknownFieldPattern = regexp.MustCompile(`^field (\S+) not found in type (\S+)$`)
+)
+
+// newTypeErrors turns the errors of the strict decoding of the config into errors which name the unknown fields by
+// their path in the config, and suggest the known field they were probably meant to be
+func newTypeErrors(root *yaml.Node, typeErr *yaml.TypeError) Errors {
+	var errs Errors
+	for _, message := range typeErr.Errors {
+		match := typeErrorPattern.FindStringSubmatch(message)
+		if match == nil {
+			errs = append(errs, &Error{Message: message})
+			continue
+		}
+		line, _ := strconv.Atoi(match[1])
+		message = match[2]
+
+		if field := unknownFieldPattern.FindStringSubmatch(message); field != nil {
+			path := append(findKeyPath(root, field[1], line), field[1])
+			message = fmt.Sprintf("unknown field %q", strings.Join(path, "."))
+			if suggestion := suggest(field[1], fieldNames(field[2])); suggestion != "" {
+				message += fmt.Sprintf(", did you mean %q?", suggestion)
+			}
+		}
+		errs = append(errs, &Error{Line: line, Message: message})
+	}
+	return errs.sorted()
+}
+
+// findKeyPath returns the path of the mapping which has the key at the line, or nil if there is none
+func findKeyPath(node *yaml.Node, key string, line int) []string {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			if path := findKeyPath(child, key, line); path != nil {
+				return path
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			if keyNode.Value == key && keyNode.Line == line {
+				return []string{}
+			}
+			if path := findKeyPath(valueNode, key, line); path != nil {
+				return append([]string{keyNode.Value}, path...)
+			}
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			if path := findKeyPath(child, key, line); path != nil {
+				return append([]string{strconv.Itoa(i)}, path...)
+			}
+		}
+	}
+	return nil
+}
+
+// fieldNames returns the YAML names of the fields of the config struct type with the name, such as "pss.Config"
+func fieldNames(typeName string) []string {
+	var names []string
+	walkStructs(reflect.TypeOf(KubeauditConfig{}), map[reflect.Type]bool{}, func(t reflect.Type) {
+		if t.String() != typeName {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			if name := yamlName(t.Field(i)); name != "" {
+				names = append(names, name)
+			}
+		}
+	})
+	return names
+}
+
+// walkStructs calls fn for the struct type and the struct types of its fields, recursively
+func walkStructs(t reflect.Type, seen map[reflect.Type]bool, fn func(reflect.Type)) {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] {
+		return
+	}
+	seen[t] = true
+	fn(t)
+	for i := 0; i < t.NumField(); i++ {
+		if yamlName(t.Field(i)) != "" {
+			walkStructs(t.Field(i).Type, seen, fn)
+		}
+	}
+}
+
+// yamlName returns the name of the field in YAML, or an empty string if the field is not decoded
+func yamlName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+	name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+	return name
+}
+
+// suggest returns the candidate which is closest to the name, if it is close enough to be a typo of it
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if strings.EqualFold(name, candidate) {
+			return candidate
+		}
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(candidate)); distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// validate returns the values of the config which are not one of the values the field accepts
+func (conf *KubeauditConfig) validate() Errors {
+	var errs Errors
+	severities := map[string]bool{"error": true, "warn": true, "warning": true, "info": true}
+	isSeverity := func(name string) bool {
+		if severities[strings.ToLower(name)] {
+			return true
+		}
+		// Severities registered before the config was loaded, such as by a library, are known too
+		_, err := kubeaudit.ParseSeverity(name)
+		return err == nil
+	}
+	checkSeverity := func(severity string, path ...string) {
+		if severity != "" && !isSeverity(severity) {
+			errs = append(errs, &Error{
+				Line:    conf.Line(path...),
+				Message: fmt.Sprintf("invalid severity %q for %s, expected one of error, warning, info or a severity defined in severities", severity, strings.Join(path, ".")),
+			})
+		}
+	}
+
+	for i, definition := range conf.Severities {
+		index := strconv.Itoa(i)
+		switch {
+		case strings.TrimSpace(definition.Name) == "":
+			errs = append(errs, &Error{Line: conf.Line("severities", index), Message: "severity without a name"})
+			continue
+		case (definition.Above == "") == (definition.Below == ""):
+			errs = append(errs, &Error{Line: conf.Line("severities", index), Message: fmt.Sprintf("severity %q must set exactly one of above and below", definition.Name)})
+		case definition.Above != "":
+			checkSeverity(definition.Above, "severities", index, "above")
+		default:
+			checkSeverity(definition.Below, "severities", index, "below")
+		}
+		severities[strings.ToLower(strings.TrimSpace(definition.Name))] = true
+	}
+
+	for _, rule := range sortedKeys(conf.Rules) {
+		checkSeverity(conf.Rules[rule].Severity, "rules", rule, "severity")
+	}
+	classNames := map[string]bool{}
+	for i, class := range conf.NamespaceClasses {
+		index := strconv.Itoa(i)
+		switch {
+		case class.Name == "":
+			errs = append(errs, &Error{Line: conf.Line("namespaceClasses", index), Message: "namespace class without a name"})
+		case classNames[class.Name]:
+			errs = append(errs, &Error{Line: conf.Line("namespaceClasses", index, "name"), Message: fmt.Sprintf("duplicate namespace class %q", class.Name)})
+		}
+		classNames[class.Name] = true
+		for j, pattern := range class.Namespaces {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				errs = append(errs, &Error{
+					Line:    conf.Line("namespaceClasses", index, "namespaces", strconv.Itoa(j)),
+					Message: fmt.Sprintf("invalid namespace pattern %q of namespace class %q: %s", pattern, class.Name, err),
+				})
+			}
+		}
+		for _, rule := range sortedSeverityKeys(class.Severities) {
+			checkSeverity(class.Severities[rule], "namespaceClasses", index, "severities", rule)
+		}
+	}
+
+	checkSeverity(conf.AuditorConfig.Rego.Severity, "auditors", "rego", "severity")
+	checkSeverity(conf.AuditorConfig.Secrets.SecretRefSeverity, "auditors", "secrets", "secretRefSeverity")
+
+	for i, capability := range conf.AuditorConfig.Capabilities.AllowAddList {
+		if capability == "" || capabilities.IsLinuxCapability(capability) {
+			continue
+		}
+		message := fmt.Sprintf("unknown capability %q in auditors.capabilities.allowAddList", capability)
+		if trimmed := strings.TrimPrefix(strings.ToUpper(capability), "CAP_"); capabilities.IsLinuxCapability(trimmed) {
+			message += fmt.Sprintf(", did you mean %q?", trimmed)
+		}
+		errs = append(errs, &Error{Line: conf.Line("auditors", "capabilities", "allowAddList", strconv.Itoa(i)), Message: message})
+	}
+
+	for _, profile := range sortedProfileNames(conf.AuditorConfig.Capabilities.Profiles) {
+		for i, capability := range conf.AuditorConfig.Capabilities.Profiles[profile] {
+			if capabilities.IsLinuxCapability(capability) {
+				continue
+			}
+			message := fmt.Sprintf("unknown capability %q in auditors.capabilities.profiles.%s", capability, profile)
+			if trimmed := strings.TrimPrefix(strings.ToUpper(capability), "CAP_"); capabilities.IsLinuxCapability(trimmed) {
+				message += fmt.Sprintf(", did you mean %q?", trimmed)
+			}
+			errs = append(errs, &Error{Line: conf.Line("auditors", "capabilities", "profiles", profile, strconv.Itoa(i)), Message: message})
+		}
+	}
+
+	for i, notification := range conf.Notifications {
+		index := strconv.Itoa(i)
+		switch notification.Type {
+		case "", notify.TypeWebhook, notify.TypeSlack, notify.TypePagerDuty:
+		default:
+			errs = append(errs, &Error{
+				Line:    conf.Line("notifications", index, "type"),
+				Message: fmt.Sprintf("invalid notification type %q, expected one of %s, %s, %s", notification.Type, notify.TypeWebhook, notify.TypeSlack, notify.TypePagerDuty),
+			})
+		}
+		checkSeverity(notification.MinSeverity, "notifications", index, "minSeverity")
+	}
+
+	return errs.sorted()
+}
+
+// Line returns the line of the value at the path of keys and sequence indexes in the config file, such as
+// Line("enabledAuditors", "apparmor"), or 0 if it is unknown, such as for a config which was not loaded from a file
+func (conf *KubeauditConfig) Line(path ...string) int {
+	if conf == nil || conf.node == nil {
+		return 0
+	}
+
+	node := conf.node
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	line := node.Line
+	for _, key := range path {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == key {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i >= 0 && i < len(node.Content) {
+				next = node.Content[i]
+				line = next.Line
+			}
+		}
+		if next == nil {
+			return line
+		}
+		node = next
+	}
+	return line
+}
+
+func sortedKeys(rules map[string]RuleConfig) []string {
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedSeverityKeys(severities map[string]string) []string {
+	keys := make([]string, 0, len(severities))
+	for key := range severities {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedProfileNames(profiles map[string][]string) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CheckEnabledAuditors returns Errors for the auditors of enabledAuditors which are not known auditors, which are most
+// likely misspelled. The known auditors are given by the caller since they include the plugins which are installed
+func (conf *KubeauditConfig) CheckEnabledAuditors(known []string) error {
+	knownSet := make(map[string]bool, len(known))
+	for _, auditorName := range known {
+		knownSet[auditorName] = true
+	}
+
+	var errs Errors
+	for auditorName := range conf.GetEnabledAuditors() {
+		if knownSet[auditorName] {
+			continue
+		}
+		message := fmt.Sprintf("unknown auditor %q in enabledAuditors", auditorName)
+		if suggestion := suggest(auditorName, known); suggestion != "" {
+			message += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		errs = append(errs, &Error{Line: conf.Line("enabledAuditors", auditorName), Message: message})
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Message < errs[j].Message })
+	return errs.sorted()
+}
@@ -0,0 +1,110 @@
+package kubeaudit
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// ContainerImageMetadata is the metadata key of the image of the container of an audit result
+const ContainerImageMetadata = "ContainerImage"
+
+// ContainerIndexMetadata is the metadata key of the index of the container of an audit result in its list of
+// containers, init containers or ephemeral containers
+const ContainerIndexMetadata = "ContainerIndex"
+
+// ImageDigestMetadata is the metadata key of the digest of the image the container of an audit result is running. It
+// is only known for pods audited in a cluster, from their container statuses
+const ImageDigestMetadata = "ImageDigest"
+
+// IsContainerIdentityMetadata returns true if the metadata key is one of the keys which identify the container of an
+// audit result beyond its name. They change when an image is updated or containers are reordered, so they are not
+// part of the identity of findings
+func IsContainerIdentityMetadata(key string) bool {
+	switch key {
+	case ContainerImageMetadata, ContainerIndexMetadata, ImageDigestMetadata:
+		return true
+	}
+	return false
+}
+
+// setContainerMetadata adds the image, index and, for pods audited in a cluster, the image digest of the container of
+// each audit result to its metadata, so containers with similar names can be told apart and findings can be correlated
+// by image. Audit results which aren't about a container, or whose container isn't in the resource, are unchanged
+func setContainerMetadata(resource k8s.Resource, auditResults []*AuditResult) {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return
+	}
+
+	digests := getImageDigests(resource)
+	for _, auditResult := range auditResults {
+		name := auditResult.Metadata["Container"]
+		if name == "" {
+			continue
+		}
+		image, index, ok := findPodSpecContainer(podSpec, name)
+		if !ok {
+			continue
+		}
+		auditResult.Metadata[ContainerImageMetadata] = image
+		auditResult.Metadata[ContainerIndexMetadata] = strconv.Itoa(index)
+		if digest := digests[name]; digest != "" {
+			auditResult.Metadata[ImageDigestMetadata] = digest
+		}
+	}
+}
+
+// findPodSpecContainer returns the image and the index of the container, init container or ephemeral container of the
+// pod spec with the name
+func findPodSpecContainer(podSpec *k8s.PodSpecV1, name string) (image string, index int, ok bool) {
+	for i, container := range podSpec.Containers {
+		if container.Name == name {
+			return container.Image, i, true
+		}
+	}
+	for i, container := range podSpec.InitContainers {
+		if container.Name == name {
+			return container.Image, i, true
+		}
+	}
+	for i, container := range podSpec.EphemeralContainers {
+		if container.Name == name {
+			return container.Image, i, true
+		}
+	}
+	return "", 0, false
+}
+
+// getImageDigests returns the digest of the image of each container of a pod by container name, from the image IDs of
+// the container statuses of the pod. Resources other than pods, such as pods read from manifests, don't have any
+func getImageDigests(resource k8s.Resource) map[string]string {
+	pod, ok := resource.(*k8s.PodV1)
+	if !ok {
+		return nil
+	}
+
+	digests := map[string]string{}
+	for _, statuses := range [][]apiv1.ContainerStatus{
+		pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses, pod.Status.EphemeralContainerStatuses,
+	} {
+		for _, status := range statuses {
+			if digest := parseImageDigest(status.ImageID); digest != "" {
+				digests[status.Name] = digest
+			}
+		}
+	}
+	return digests
+}
+
+// parseImageDigest returns the repository digest of an image ID reported by the container runtime, such as
+// "docker-pullable://nginx@sha256:...", or an empty string if it doesn't have one. Bare image IDs are the digest of the
+// image config rather than of the image, so they can't be correlated with registries and aren't returned
+func parseImageDigest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+package kubeaudit
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestSetContainerMetadata(t *testing.T) {
+	pod := &k8s.PodV1{
+		Spec: apiv1.PodSpec{
+			InitContainers: []apiv1.Container{{Name: "init", Image: "busybox:1.36"}},
+			Containers: []apiv1.Container{
+				{Name: "app", Image: "nginx:1.25"},
+				{Name: "app-sidecar", Image: "envoy:1.28"},
+			},
+		},
+		Status: apiv1.PodStatus{
+			ContainerStatuses: []apiv1.ContainerStatus{
+				{Name: "app", ImageID: "docker-pullable://nginx@sha256:0123"},
+				{Name: "app-sidecar", ImageID: "sha256:4567"},
+			},
+		},
+	}
+
+	auditResults := []*AuditResult{
+		{Metadata: Metadata{"Container": "app"}},
+		{Metadata: Metadata{"Container": "app-sidecar"}},
+		{Metadata: Metadata{"Container": "init"}},
+		{Metadata: Metadata{"Container": "missing"}},
+		{},
+	}
+	setContainerMetadata(pod, auditResults)
+
+	assert.Equal(t, Metadata{"Container": "app", "ContainerImage": "nginx:1.25", "ContainerIndex": "0", "ImageDigest": "sha256:0123"}, auditResults[0].Metadata)
+	// Bare image IDs are not image digests
+	assert.Equal(t, Metadata{"Container": "app-sidecar", "ContainerImage": "envoy:1.28", "ContainerIndex": "1"}, auditResults[1].Metadata)
+	assert.Equal(t, Metadata{"Container": "init", "ContainerImage": "busybox:1.36", "ContainerIndex": "0"}, auditResults[2].Metadata)
+	assert.Equal(t, Metadata{"Container": "missing"}, auditResults[3].Metadata)
+	assert.Nil(t, auditResults[4].Metadata)
+}
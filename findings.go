@@ -0,0 +1,88 @@
+package kubeaudit
+
+import (
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Finding is an audit result with the identity of the resource it was reported for. Findings are the structured form
+// of the results used by the printers, for building other outputs
+type Finding struct {
+	Auditor  string
+	Rule     string
+	Severity SeverityLevel
+	Message  string
+	// GroupVersionKind, Namespace and Name identify the resource. They are empty if the resource doesn't have them
+	GroupVersionKind schema.GroupVersionKind
+	Namespace        string
+	Name             string
+	// Container is the name of the container the finding is for, or empty if it is for the whole resource
+	Container string
+	// Context is the kubeconfig context of the cluster the finding was reported for, or empty unless several clusters
+	// are audited with AuditLocalContexts()
+	Context  string
+	Metadata Metadata
+	FilePath string
+	Line     int
+	Column   int
+	// References link the rule to its documentation and to the controls of security frameworks
+	References []Reference
+
+	// Resource and AuditResult are the resource and audit result of the finding, which can be used to fix it
+	Resource    KubeResource
+	AuditResult *AuditResult
+}
+
+// Findings returns the findings of every audit result of the report, grouped by resource in the order they were
+// audited
+func (r *Report) Findings() []Finding {
+	var findings []Finding
+	for _, result := range r.Results() {
+		findings = append(findings, getFindings(result)...)
+	}
+	return findings
+}
+
+// FindingsWithMinSeverity returns the findings of the report with a minimum severity
+func (r *Report) FindingsWithMinSeverity(minSeverity SeverityLevel) []Finding {
+	var findings []Finding
+	for _, result := range r.ResultsWithMinSeverity(minSeverity) {
+		findings = append(findings, getFindings(result)...)
+	}
+	return findings
+}
+
+func getFindings(result Result) []Finding {
+	var gvk schema.GroupVersionKind
+	var namespace, name string
+	if resource := result.GetResource(); resource != nil && resource.Object() != nil {
+		gvk = resource.Object().GetObjectKind().GroupVersionKind()
+		if objectMeta := k8s.GetObjectMeta(resource.Object()); objectMeta != nil {
+			namespace = objectMeta.GetNamespace()
+			name = objectMeta.GetName()
+		}
+	}
+
+	findings := make([]Finding, 0, len(result.GetAuditResults()))
+	for _, auditResult := range result.GetAuditResults() {
+		findings = append(findings, Finding{
+			Auditor:          auditResult.Auditor,
+			Rule:             auditResult.Rule,
+			Severity:         auditResult.Severity,
+			Message:          auditResult.Message,
+			GroupVersionKind: gvk,
+			Namespace:        namespace,
+			Name:             name,
+			Container:        auditResult.Metadata["Container"],
+			Context:          auditResult.Metadata[ContextMetadata],
+			Metadata:         auditResult.Metadata,
+			FilePath:         auditResult.FilePath,
+			Line:             auditResult.Line,
+			Column:           auditResult.Column,
+			References:       auditResult.References,
+			Resource:         result.GetResource(),
+			AuditResult:      auditResult,
+		})
+	}
+	return findings
+}
@@ -0,0 +1,58 @@
+package kubeaudit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestFindings(t *testing.T) {
+	manifest := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment
+  namespace: namespace
+spec:
+  template:
+    spec:
+      containers:
+        - name: container
+          image: scratch
+          securityContext:
+            privileged: true
+`
+	limitsAuditor, err := limits.New(limits.Config{})
+	require.NoError(t, err)
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New(), limitsAuditor})
+	require.NoError(t, err)
+	report, err := auditor.AuditManifest("manifest.yml", strings.NewReader(manifest))
+	require.NoError(t, err)
+
+	findings := report.Findings()
+	require.Len(t, findings, 2)
+
+	finding := findings[0]
+	assert.Equal(t, privileged.Name, finding.Auditor)
+	assert.Equal(t, privileged.PrivilegedTrue, finding.Rule)
+	assert.Equal(t, kubeaudit.Error, finding.Severity)
+	assert.Equal(t, schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, finding.GroupVersionKind)
+	assert.Equal(t, "namespace", finding.Namespace)
+	assert.Equal(t, "deployment", finding.Name)
+	assert.Equal(t, "container", finding.Container)
+	assert.Equal(t, "container", finding.Metadata["Container"])
+	assert.Equal(t, "manifest.yml", finding.FilePath)
+	assert.Equal(t, report.Results()[0].GetAuditResults()[0], finding.AuditResult)
+	assert.NotNil(t, finding.Resource)
+
+	assert.Equal(t, limits.LimitsNotSet, findings[1].Rule)
+
+	findings = report.FindingsWithMinSeverity(kubeaudit.Error)
+	require.Len(t, findings, 1)
+	assert.Equal(t, privileged.PrivilegedTrue, findings[0].Rule)
+}
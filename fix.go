@@ -2,6 +2,8 @@ package kubeaudit
 
 import (
 	"bytes"
+	"fmt"
+	"strings"
 
 	"github.com/Shopify/kubeaudit/internal/k8sinternal"
 	"github.com/Shopify/kubeaudit/internal/yaml"
@@ -18,9 +20,7 @@ func fix(results []Result) ([]byte, error) {
 
 	// Fix all the resources
 	for _, result := range results {
-		for _, auditResult := range result.GetAuditResults() {
-			newResources = append(newResources, auditResult.Fix(result.GetResource().Object())...)
-		}
+		newResources = append(newResources, fixResource(result)...)
 	}
 
 	// Convert all the resources to bytes
@@ -58,31 +58,20 @@ func resourceToBytes(fixedResource k8s.Resource, origResourceBytes []byte) ([]by
 		return nil, err
 	}
 
-	if origResourceBytes == nil {
-		// This is a new resource (not in the original manifest)
-		// Add  a leading newline
-		fixedresourceBytes = append([]byte{'\n'}, fixedresourceBytes...)
-	} else {
-		fixedresourceBytes, err = yaml.Merge(origResourceBytes, fixedresourceBytes)
-		if err != nil {
-			return nil, err
-		}
-
-		// Add any leading and trailing whitespace that was present in the original
-		fixedresourceBytes = bytes.Replace(origResourceBytes, bytes.TrimSpace(origResourceBytes), fixedresourceBytes, 1)
-
-		// Remove the redundant trailing newline
-		if fixedresourceBytes[len(fixedresourceBytes)-1] == '\n' {
-			fixedresourceBytes = fixedresourceBytes[:len(fixedresourceBytes)-1]
-		}
-	}
-
 	fixedresourceBytes, err = cleanupManifest(origResourceBytes, fixedresourceBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	return fixedresourceBytes, nil
+	if origResourceBytes == nil {
+		// This is a new resource (not in the original manifest)
+		// Add  a leading newline
+		return append([]byte{'\n'}, fixedresourceBytes...), nil
+	}
+
+	// Only the lines of the fields changed by the fixes are edited, so the rest of the original is kept as it was
+	// written, including its comments and whitespace
+	return yaml.Patch(origResourceBytes, fixedresourceBytes)
 }
 
 // TODO do this better??
@@ -133,3 +122,192 @@ func cleanupManifest(origData, finalData []byte) ([]byte, error) {
 
 	return finalData, nil
 }
+
+// SelectFixes returns a copy of the report in which only the selected fixes are kept, so that the findings which are
+// accepted are not fixed by Fix() and the other functions which apply fixes. Each entry of fix and skip is either the
+// name of an auditor (eg. "capabilities") or a rule (eg. "CapabilityAdded"). If fix is not empty only the fixes of
+// the listed auditors and rules are kept, and the fixes of the auditors and rules listed in skip are removed. The
+// findings themselves are kept so they are still reported
+func (r *Report) SelectFixes(fix []string, skip []string) *Report {
+	results := make([]Result, 0, len(r.RawResults()))
+	for _, result := range r.RawResults() {
+		auditResults := make([]*AuditResult, 0, len(result.GetAuditResults()))
+		for _, auditResult := range result.GetAuditResults() {
+			selected := *auditResult
+			if selected.PendingFix != nil && !isFixSelected(auditResult, fix, skip) {
+				selected.PendingFix = nil
+			}
+			auditResults = append(auditResults, &selected)
+		}
+
+		results = append(results, &WorkloadResult{
+			Resource:     result.GetResource(),
+			AuditResults: auditResults,
+		})
+	}
+
+	return r.withResults(results)
+}
+
+func isFixSelected(auditResult *AuditResult, fix []string, skip []string) bool {
+	if len(fix) > 0 && !matchesFix(auditResult, fix) {
+		return false
+	}
+	return !matchesFix(auditResult, skip)
+}
+
+// matchesFix returns true if the auditor or the rule of the audit result is one of the names
+func matchesFix(auditResult *AuditResult, names []string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, auditResult.Auditor) || name == auditResult.Rule {
+			return true
+		}
+	}
+	return false
+}
+
+// FixedManifest is a manifest file audited with AuditManifestFiles() and fixed by FixManifests()
+type FixedManifest struct {
+	// Path is the path of the manifest file
+	Path string
+	// Original is the audited manifest and Fixed is the manifest with the fixes applied, followed by the resources
+	// created by the fixes
+	Original []byte
+	Fixed    []byte
+}
+
+// FixManifests fixes the resources of each manifest file audited with AuditManifestFiles(), in the order the files
+// were audited. Fixed is the same as Original for manifests without any fix. Resources created by a fix, such as
+// network policies, are added to the manifest of the resource they fix
+func (r *Report) FixManifests() ([]FixedManifest, error) {
+	paths, byPath := groupByManifestFile(r.RawResults())
+
+	fixedManifests := make([]FixedManifest, 0, len(paths))
+	for _, path := range paths {
+		// The manifest was split into the documents of the results, so joining them gives the audited manifest back
+		var documents [][]byte
+		for _, result := range byPath[path] {
+			documents = append(documents, result.GetResource().Bytes())
+		}
+		original := bytes.Join(documents, []byte("---"))
+
+		// Manifests without fixes are left as they are rather than reformatted by encoding their resources again
+		fixed := original
+		if hasPendingFix(byPath[path]) {
+			var err error
+			fixed, err = fix(byPath[path])
+			if err != nil {
+				return nil, fmt.Errorf("failed to fix manifest %s: %w", path, err)
+			}
+		}
+
+		fixedManifests = append(fixedManifests, FixedManifest{
+			Path:     path,
+			Original: original,
+			Fixed:    fixed,
+		})
+	}
+	return fixedManifests, nil
+}
+
+func hasPendingFix(results []Result) bool {
+	for _, result := range results {
+		for _, auditResult := range result.GetAuditResults() {
+			if auditResult.PendingFix != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// VerifyFix audits the manifest written by Report.Fix() again and returns a report of the findings which were not
+// resolved by their fix. A finding of a fixed resource is unresolved if its rule was targeted by a fix of the same
+// resource and container, or if it has a fix and was not reported before, since fixing the manifest again would
+// change it. Findings which were reported without a fix, such as the ones removed by SelectFixes(), are left out. An
+// empty report means the fixes resolved every finding they targeted and autofix is idempotent for the manifest
+func (a *Kubeaudit) VerifyFix(report *Report, fixedManifest []byte) (*Report, error) {
+	fixedReport, err := a.AuditManifest("", bytes.NewReader(fixedManifest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to audit the fixed manifest: %w", err)
+	}
+
+	return NewReport(getUnresolvedResults(report.RawResults(), fixedReport.RawResults())), nil
+}
+
+// VerifyFixManifests is VerifyFix() for the manifest files fixed by Report.FixManifests(). The fixed manifests are
+// audited together, like the original manifests were
+func (a *Kubeaudit) VerifyFixManifests(report *Report, fixedManifests []FixedManifest) (*Report, error) {
+	var resources []KubeResource
+	for _, fixedManifest := range fixedManifests {
+		fileResources, err := getManifestFileResources(fixedManifest.Path, fixedManifest.Fixed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to audit the fixed manifest: %w", err)
+		}
+		resources = append(resources, fileResources...)
+	}
+
+	fixedResults, err := auditResources(resources, a.auditors, a.concurrency, a.profile, a.strict)
+	if err != nil {
+		return nil, fmt.Errorf("failed to audit the fixed manifests: %w", err)
+	}
+	fixedReport := NewReport(fixedResults)
+
+	_, originalByPath := groupByManifestFile(report.RawResults())
+	paths, fixedByPath := groupByManifestFile(fixedReport.RawResults())
+	var unresolvedResults []Result
+	for _, path := range paths {
+		unresolvedResults = append(unresolvedResults, getUnresolvedResults(originalByPath[path], fixedByPath[path])...)
+	}
+
+	return NewReport(unresolvedResults), nil
+}
+
+// getUnresolvedResults returns the results of the fixed manifest with the findings which were not resolved by their
+// fix. The fixed manifest has one document for each of the original results, in the same order, followed by the
+// resources created by the fixes
+func getUnresolvedResults(originalResults, fixedResults []Result) []Result {
+	var unresolvedResults []Result
+	for i, fixedResult := range fixedResults {
+		targeted := map[fixTarget]bool{}
+		reported := map[fixTarget]bool{}
+		if i < len(originalResults) {
+			for _, auditResult := range originalResults[i].GetAuditResults() {
+				reported[newFixTarget(auditResult)] = true
+				if auditResult.PendingFix != nil {
+					targeted[newFixTarget(auditResult)] = true
+				}
+			}
+		}
+
+		var unresolved []*AuditResult
+		for _, auditResult := range fixedResult.GetAuditResults() {
+			target := newFixTarget(auditResult)
+			if targeted[target] || (auditResult.PendingFix != nil && !reported[target]) {
+				unresolved = append(unresolved, auditResult)
+			}
+		}
+		if len(unresolved) > 0 {
+			unresolvedResults = append(unresolvedResults, &WorkloadResult{
+				Resource:     fixedResult.GetResource(),
+				AuditResults: unresolved,
+			})
+		}
+	}
+	return unresolvedResults
+}
+
+// fixTarget identifies the finding a fix targets within a resource
+type fixTarget struct {
+	auditor   string
+	rule      string
+	container string
+}
+
+func newFixTarget(auditResult *AuditResult) fixTarget {
+	return fixTarget{
+		auditor:   auditResult.Auditor,
+		rule:      auditResult.Rule,
+		container: auditResult.Metadata["Container"],
+	}
+}
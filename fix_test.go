@@ -1,6 +1,7 @@
 package kubeaudit_test
 
 import (
+	"bytes"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -8,8 +9,10 @@ import (
 
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
 	"github.com/Shopify/kubeaudit/config"
 	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -52,3 +55,184 @@ func TestFix(t *testing.T) {
 		}
 	}
 }
+
+// Test that the fixes of all fixtures in auditors/* resolve the findings they target, including those of the
+// optional auditors
+func TestVerifyFix(t *testing.T) {
+	auditorDirs, err := ioutil.ReadDir("auditors")
+	require.NoError(t, err)
+
+	enabledAuditors := map[string]bool{}
+	for _, auditorName := range all.OptionalAuditorNames {
+		enabledAuditors[auditorName] = true
+	}
+	allAuditors, err := all.Auditors(config.KubeauditConfig{EnabledAuditors: enabledAuditors})
+	require.NoError(t, err)
+	auditor, err := kubeaudit.New(allAuditors)
+	require.NoError(t, err)
+
+	for _, auditorDir := range auditorDirs {
+		if !auditorDir.IsDir() {
+			continue
+		}
+
+		fixturesDirPath := filepath.Join("auditors", auditorDir.Name(), "fixtures")
+		fixtureFiles, err := ioutil.ReadDir(fixturesDirPath)
+		if os.IsNotExist(err) {
+			continue
+		}
+		require.NoError(t, err)
+
+		for _, fixture := range fixtureFiles {
+			fixturePath := filepath.Join(fixturesDirPath, fixture.Name())
+			t.Run(fixturePath, func(t *testing.T) {
+				manifest, err := os.Open(fixturePath)
+				require.NoError(t, err)
+				defer manifest.Close()
+
+				report, err := auditor.AuditManifest(fixturePath, manifest)
+				require.NoError(t, err)
+
+				fixed := bytes.NewBuffer(nil)
+				require.NoError(t, report.Fix(fixed))
+
+				unresolved, err := auditor.VerifyFix(report, fixed.Bytes())
+				require.NoError(t, err)
+				for _, result := range unresolved.Results() {
+					for _, auditResult := range result.GetAuditResults() {
+						assert.Failf(t, "finding not resolved by its fix", "%s %s", auditResult.Rule, auditResult.Metadata)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestVerifyFixUnresolved(t *testing.T) {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{&brokenFixAuditor{}})
+	require.NoError(t, err)
+
+	manifest := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\nspec:\n  containers:\n    - name: container\n      image: scratch\n"
+	report, err := auditor.AuditManifest("", bytes.NewReader([]byte(manifest)))
+	require.NoError(t, err)
+
+	fixed := bytes.NewBuffer(nil)
+	require.NoError(t, report.Fix(fixed))
+
+	unresolved, err := auditor.VerifyFix(report, fixed.Bytes())
+	require.NoError(t, err)
+	results := unresolved.Results()
+	require.Len(t, results, 1)
+	require.Len(t, results[0].GetAuditResults(), 1)
+	assert.Equal(t, "BrokenFix", results[0].GetAuditResults()[0].Rule)
+}
+
+func TestFixManifests(t *testing.T) {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	unfixed := filepath.Join(dir, "unfixed.yml")
+	fixed := filepath.Join(dir, "fixed.yml")
+	pod := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\nspec:\n  containers:\n    - name: container\n      image: scratch\n"
+	require.NoError(t, ioutil.WriteFile(unfixed, []byte(pod), 0644))
+	require.NoError(t, ioutil.WriteFile(fixed, []byte(pod+"      securityContext:\n        privileged: false\n"), 0644))
+
+	report, err := auditor.AuditManifestFiles([]string{unfixed, fixed})
+	require.NoError(t, err)
+
+	fixedManifests, err := report.FixManifests()
+	require.NoError(t, err)
+	require.Len(t, fixedManifests, 2)
+	assert.Equal(t, unfixed, fixedManifests[0].Path)
+	assert.Equal(t, pod, string(fixedManifests[0].Original))
+	assert.Contains(t, string(fixedManifests[0].Fixed), "privileged: false")
+	assert.Equal(t, fixed, fixedManifests[1].Path)
+	assert.Equal(t, string(fixedManifests[1].Original), string(fixedManifests[1].Fixed))
+
+	unresolved, err := auditor.VerifyFixManifests(report, fixedManifests)
+	require.NoError(t, err)
+	assert.Empty(t, unresolved.Results())
+}
+
+// brokenFixAuditor reports a finding for every resource with a fix which does not change anything
+type brokenFixAuditor struct{}
+
+func (a *brokenFixAuditor) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	return []*kubeaudit.AuditResult{{
+		Auditor:    "broken",
+		Rule:       "BrokenFix",
+		Severity:   kubeaudit.Error,
+		Message:    "The fix of this finding does not resolve it.",
+		PendingFix: &noopFix{},
+	}}, nil
+}
+
+type noopFix struct{}
+
+func (f *noopFix) Plan() string {
+	return "Do nothing"
+}
+
+func (f *noopFix) Apply(resource k8s.Resource) []k8s.Resource {
+	return nil
+}
+
+func TestSelectFixes(t *testing.T) {
+	allAuditors, err := all.Auditors(config.KubeauditConfig{})
+	require.NoError(t, err)
+	auditor, err := kubeaudit.New(allAuditors)
+	require.NoError(t, err)
+
+	manifest := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\n  namespace: default\nspec:\n  containers:\n    - name: container\n      image: scratch:1.0\n"
+
+	cases := []struct {
+		testName      string
+		fix           []string
+		skip          []string
+		expectedFixed []string
+	}{
+		{"Auditors", []string{"capabilities", "seccomp"}, nil, []string{"CapabilityOrSecurityContextMissing", "SeccompProfileMissing"}},
+		{"Auditor and rule", []string{"Capabilities", "ReadOnlyRootFilesystemNil"}, nil, []string{"CapabilityOrSecurityContextMissing", "ReadOnlyRootFilesystemNil"}},
+		{"Skip", []string{"capabilities", "seccomp", "limits"}, []string{"limits", "SeccompProfileMissing"}, []string{"CapabilityOrSecurityContextMissing"}},
+	}
+
+	for _, tc := range cases {
+		// These lines are needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			report, err := auditor.AuditManifest("", bytes.NewReader([]byte(manifest)))
+			require.NoError(t, err)
+
+			selected := report.SelectFixes(tc.fix, tc.skip)
+
+			// The findings are still reported, only the fixes are removed
+			require.Len(t, selected.Results(), 1)
+			assert.Len(t, selected.Results()[0].GetAuditResults(), len(report.Results()[0].GetAuditResults()))
+
+			var fixedRules []string
+			for _, auditResult := range selected.Results()[0].GetAuditResults() {
+				if auditResult.PendingFix != nil {
+					fixedRules = append(fixedRules, auditResult.Rule)
+				}
+			}
+			assert.ElementsMatch(t, tc.expectedFixed, fixedRules)
+
+			fixed := bytes.NewBuffer(nil)
+			require.NoError(t, selected.Fix(fixed))
+
+			// Only the selected findings are fixed, and the skipped ones are not reported as unresolved
+			fixedReport, err := auditor.AuditManifest("", bytes.NewReader(fixed.Bytes()))
+			require.NoError(t, err)
+			for _, auditResult := range fixedReport.Results()[0].GetAuditResults() {
+				assert.NotContains(t, tc.expectedFixed, auditResult.Rule)
+			}
+
+			unresolved, err := auditor.VerifyFix(selected, fixed.Bytes())
+			require.NoError(t, err)
+			assert.Empty(t, unresolved.Results())
+		})
+	}
+}
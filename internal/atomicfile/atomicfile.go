@@ -0,0 +1,59 @@
+// Package atomicfile writes files atomically, so an interrupted write never leaves a partially written file behind
+package atomicfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes data to a new temporary file and renames it to path once it is complete, so path either has its
+// previous content or data. An existing file keeps its permissions, and a new file is created with perm.
+//
+// The temporary file is created in tempDir, or in the directory of path if tempDir is empty. It has a unique name, so
+// concurrent writes never share a temporary file, and it must be on the same file system as path to be renamed.
+func WriteFile(path string, data []byte, perm os.FileMode, tempDir string) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if tempDir == "" {
+		tempDir = filepath.Dir(path)
+	}
+	tmp, err := os.CreateTemp(tempDir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := write(tmp, data, perm); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		if tempDir != filepath.Dir(path) {
+			return fmt.Errorf("failed to move the temporary file from %s, which must be on the same file system as %s: %w", tempDir, path, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// write writes data to the temporary file and flushes it to disk, so the renamed file is complete even after a crash
+func write(tmp *os.File, data []byte, perm os.FileMode) error {
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	return tmp.Close()
+}
@@ -0,0 +1,66 @@
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "new.yaml")
+	require.NoError(t, WriteFile(path, []byte("a: b\n"), 0644, ""))
+	assertFile(t, path, "a: b\n", 0644)
+
+	existing := filepath.Join(dir, "existing.yaml")
+	require.NoError(t, os.WriteFile(existing, []byte("a: b\nc: d\n"), 0600))
+	require.NoError(t, WriteFile(existing, []byte("a: e\n"), 0644, ""))
+	assertFile(t, existing, "a: e\n", 0600)
+
+	tempDir := filepath.Join(dir, "tmp")
+	require.NoError(t, os.Mkdir(tempDir, 0755))
+	require.NoError(t, WriteFile(path, []byte("a: f\n"), 0644, tempDir))
+	assertFile(t, path, "a: f\n", 0644)
+
+	// The temporary files are renamed, so only the written files are left
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"existing.yaml", "new.yaml", "tmp"}, names)
+	entries, err = os.ReadDir(tempDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestWriteFileError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "manifest.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("a: b\n"), 0644))
+
+	// The file is left untouched if the write fails
+	assert.Error(t, WriteFile(path, []byte("a: c\n"), 0644, filepath.Join(dir, "missing")))
+	assertFile(t, path, "a: b\n", 0644)
+
+	// A directory can't be replaced by the temporary file, which is removed
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "dir"), 0755))
+	assert.Error(t, WriteFile(filepath.Join(dir, "dir"), []byte("a: c\n"), 0644, ""))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func assertFile(t *testing.T, path, expected string, perm os.FileMode) {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, perm, info.Mode().Perm())
+}
@@ -0,0 +1,110 @@
+// Package auth authenticates the clients of the APIs of kubeaudit serve with bearer tokens
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Tokens are the bearer tokens accepted from clients. Several tokens can be accepted at once, eg. one per client or
+// the old and new token while rotating them
+type Tokens []string
+
+// LoadTokens reads the tokens of a file, one per line. Empty lines and lines starting with # are ignored
+func LoadTokens(path string) (Tokens, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var tokens Tokens
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		tokens = append(tokens, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("no tokens in %s", path)
+	}
+	return tokens, nil
+}
+
+// Valid returns true if the value of an Authorization header is "Bearer" and one of the tokens. Tokens are compared in
+// constant time so they can't be guessed from the time the comparison takes
+func (t Tokens) Valid(authorization string) bool {
+	const prefix = "bearer "
+	if len(authorization) <= len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return false
+	}
+	token := []byte(strings.TrimSpace(authorization[len(prefix):]))
+
+	valid := false
+	for _, candidate := range t {
+		if subtle.ConstantTimeCompare(token, []byte(candidate)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// Handler returns a handler which answers requests without a valid bearer token with 401 Unauthorized, and passes the
+// others to the next handler
+func (t Tokens) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !t.Valid(r.Header.Get("Authorization")) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="kubeaudit"`)
+			http.Error(w, "a valid bearer token is required", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// UnaryServerInterceptor returns a gRPC interceptor which rejects unary calls without a valid bearer token in their
+// "authorization" metadata with the Unauthenticated code
+func (t Tokens) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := t.authenticate(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a gRPC interceptor which rejects streams without a valid bearer token in their
+// "authorization" metadata with the Unauthenticated code
+func (t Tokens) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := t.authenticate(stream.Context()); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+func (t Tokens) authenticate(ctx context.Context) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, authorization := range md.Get("authorization") {
+		if t.Valid(authorization) {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "a valid bearer token is required")
+}
@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoadTokens(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "tokens")
+	require.NoError(t, ioutil.WriteFile(path, []byte("# portal\nfirst\n\n  second  \n"), 0600))
+
+	tokens, err := LoadTokens(path)
+	require.NoError(t, err)
+	assert.Equal(t, Tokens{"first", "second"}, tokens)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, ioutil.WriteFile(empty, []byte("# no tokens\n"), 0600))
+	_, err = LoadTokens(empty)
+	assert.EqualError(t, err, "no tokens in "+empty)
+
+	_, err = LoadTokens(filepath.Join(dir, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestValid(t *testing.T) {
+	tokens := Tokens{"first", "second"}
+	cases := []struct {
+		authorization string
+		expected      bool
+	}{
+		{"Bearer first", true},
+		{"bearer second", true},
+		{"Bearer  second ", true},
+		{"Bearer third", false},
+		{"Bearer ", false},
+		{"Basic first", false},
+		{"first", false},
+		{"", false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, tokens.Valid(tc.authorization), tc.authorization)
+	}
+	assert.False(t, Tokens{}.Valid("Bearer "))
+}
+
+func TestHandler(t *testing.T) {
+	handler := Tokens{"token"}.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/audit", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, `Bearer realm="kubeaudit"`, recorder.Header().Get("WWW-Authenticate"))
+
+	request := httptest.NewRequest(http.MethodPost, "/v1/audit", nil)
+	request.Header.Set("Authorization", "Bearer token")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNoContent, recorder.Code)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := Tokens{"token"}.UnaryServerInterceptor()
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "response", nil
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer token"))
+	response, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "response", response)
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	interceptor := Tokens{"token"}.StreamServerInterceptor()
+	handler := func(srv interface{}, stream grpc.ServerStream) error {
+		return nil
+	}
+
+	err := interceptor(nil, &testServerStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer wrong", "authorization", "Bearer token"))
+	assert.NoError(t, interceptor(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{}, handler))
+}
@@ -0,0 +1,187 @@
+// Package baseline snapshots the findings of an audit so that later audits only report findings which are new
+package baseline
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/blame"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// Version is the version of the baseline file format
+const Version = 1
+
+// Baseline is a snapshot of the findings of an audit
+type Baseline struct {
+	Version  int       `json:"version"`
+	Findings []Finding `json:"findings"`
+}
+
+// Finding identifies an audit result. Only the fingerprint is used to match audit results, the other fields make the
+// baseline file easier to review
+type Finding struct {
+	Fingerprint string `json:"fingerprint"`
+	Auditor     string `json:"auditor"`
+	Rule        string `json:"rule"`
+	Kind        string `json:"kind,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Name        string `json:"name,omitempty"`
+	Container   string `json:"container,omitempty"`
+}
+
+// New creates a baseline from the audit results of a report
+func New(report *kubeaudit.Report) *Baseline {
+	baseline := &Baseline{Version: Version, Findings: []Finding{}}
+	seen := map[string]bool{}
+
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			finding := NewFinding(result.GetResource(), auditResult)
+			if seen[finding.Fingerprint] {
+				continue
+			}
+			seen[finding.Fingerprint] = true
+			baseline.Findings = append(baseline.Findings, finding)
+		}
+	}
+
+	sort.SliceStable(baseline.Findings, func(i, j int) bool {
+		return Less(baseline.Findings[i], baseline.Findings[j])
+	})
+
+	return baseline
+}
+
+// Less orders findings by the namespace, kind and name of their resource, then by auditor, rule and container
+func Less(a, b Finding) bool {
+	for _, pair := range [][2]string{
+		{a.Namespace, b.Namespace}, {a.Kind, b.Kind}, {a.Name, b.Name}, {a.Auditor, b.Auditor}, {a.Rule, b.Rule},
+		{a.Container, b.Container}, {a.Fingerprint, b.Fingerprint},
+	} {
+		if pair[0] != pair[1] {
+			return pair[0] < pair[1]
+		}
+	}
+	return false
+}
+
+// Load reads a baseline file
+func Load(path string) (*Baseline, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening baseline file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	baseline, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading baseline file %s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// Read decodes a baseline
+func Read(r io.Reader) (*Baseline, error) {
+	baseline := &Baseline{}
+	if err := json.NewDecoder(r).Decode(baseline); err != nil {
+		return nil, err
+	}
+	if baseline.Version != Version {
+		return nil, fmt.Errorf("unsupported baseline version %d, expected %d", baseline.Version, Version)
+	}
+	return baseline, nil
+}
+
+// Write encodes the baseline as indented JSON
+func (b *Baseline) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(b)
+}
+
+// Filter returns a copy of the report without the audit results which are in the baseline, and the number of audit
+// results which were removed
+func (b *Baseline) Filter(report *kubeaudit.Report) (*kubeaudit.Report, int) {
+	known := make(map[string]bool, len(b.Findings))
+	for _, finding := range b.Findings {
+		known[finding.Fingerprint] = true
+	}
+
+	suppressed := 0
+	filtered := report.Suppress(kubeaudit.SuppressedByBaseline, func(result kubeaudit.Result, auditResult *kubeaudit.AuditResult) bool {
+		if known[Fingerprint(result.GetResource(), auditResult)] {
+			suppressed++
+			return true
+		}
+		return false
+	})
+
+	return filtered, suppressed
+}
+
+// Fingerprint identifies an audit result by its auditor, rule and metadata, and by the kind, namespace and name of
+// the resource. The message, severity and location are not part of the fingerprint so that rewording a message,
+// changing the severity of a rule or moving a resource within a manifest does not make a known finding new. Likewise,
+// the image and index of the container are not part of it so that updating an image does not make it new, and the
+// commit and author the lines of the result were blamed on are not part of it so that changing the lines does not
+// make it new
+func Fingerprint(resource kubeaudit.KubeResource, auditResult *kubeaudit.AuditResult) string {
+	kind, namespace, name := getResourceIdentity(resource)
+
+	parts := []string{auditResult.Auditor, auditResult.Rule, kind, namespace, name}
+	keys := make([]string, 0, len(auditResult.Metadata))
+	for k := range auditResult.Metadata {
+		if kubeaudit.IsContainerIdentityMetadata(k) || blame.IsMetadata(k) {
+			continue
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, k+"="+auditResult.Metadata[k])
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewFinding identifies the audit result of a resource
+func NewFinding(resource kubeaudit.KubeResource, auditResult *kubeaudit.AuditResult) Finding {
+	kind, namespace, name := getResourceIdentity(resource)
+	return Finding{
+		Fingerprint: Fingerprint(resource, auditResult),
+		Auditor:     auditResult.Auditor,
+		Rule:        auditResult.Rule,
+		Kind:        kind,
+		Namespace:   namespace,
+		Name:        name,
+		Container:   auditResult.Metadata["Container"],
+	}
+}
+
+// getResourceIdentity returns the kind, namespace and name of the resource. The API version is not used so that
+// migrating a resource to a new API version does not make its findings new. Jobs created by CronJobs are identified
+// by their CronJob, since they are named after the time they were scheduled at
+func getResourceIdentity(resource kubeaudit.KubeResource) (kind, namespace, name string) {
+	if resource == nil || resource.Object() == nil {
+		return "", "", ""
+	}
+
+	kind = resource.Object().GetObjectKind().GroupVersionKind().Kind
+	if objectMeta := k8s.GetObjectMeta(resource.Object()); objectMeta != nil {
+		namespace = objectMeta.GetNamespace()
+		name = objectMeta.GetName()
+	}
+	if cronJobName := k8s.GetCronJobName(resource.Object()); cronJobName != "" {
+		kind, name = "CronJob", cronJobName
+	}
+	return kind, namespace, name
+}
@@ -0,0 +1,116 @@
+package baseline
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/blame"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestNew(t *testing.T) {
+	report := getReport(t, "privileged-two-containers.yml")
+
+	baseline := New(report)
+	require.Len(t, baseline.Findings, 2)
+	assert.Equal(t, Version, baseline.Version)
+	assert.Equal(t, Finding{
+		Fingerprint: baseline.Findings[0].Fingerprint,
+		Auditor:     privileged.Name,
+		Rule:        privileged.PrivilegedTrue,
+		Kind:        "Deployment",
+		Namespace:   "baseline",
+		Name:        "deployment",
+		Container:   "container1",
+	}, baseline.Findings[0])
+	assert.Equal(t, "container2", baseline.Findings[1].Container)
+	assert.NotEqual(t, baseline.Findings[0].Fingerprint, baseline.Findings[1].Fingerprint)
+}
+
+func TestWriteRead(t *testing.T) {
+	baseline := New(getReport(t, "privileged-two-containers.yml"))
+
+	var buf bytes.Buffer
+	require.NoError(t, baseline.Write(&buf))
+
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, read)
+}
+
+func TestReadUnsupportedVersion(t *testing.T) {
+	_, err := Read(bytes.NewBufferString(`{"version": 2, "findings": []}`))
+	assert.Error(t, err)
+}
+
+func TestFilter(t *testing.T) {
+	baseline := New(getReport(t, "privileged-one-container.yml"))
+
+	// Known findings are removed
+	report, suppressed := baseline.Filter(getReport(t, "privileged-one-container.yml"))
+	assert.Equal(t, 1, suppressed)
+	assert.Empty(t, report.Results())
+	assert.False(t, report.HasErrors())
+	assert.Equal(t, map[kubeaudit.SuppressionMechanism]int{kubeaudit.SuppressedByBaseline: 1}, report.Suppressions())
+
+	// New findings are kept
+	report, suppressed = baseline.Filter(getReport(t, "privileged-two-containers.yml"))
+	assert.Equal(t, 1, suppressed)
+	require.Len(t, report.Results(), 1)
+	auditResults := report.Results()[0].GetAuditResults()
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, "container2", auditResults[0].Metadata["Container"])
+	assert.True(t, report.HasErrors())
+}
+
+func TestFingerprintIgnoresMessage(t *testing.T) {
+	report := getReport(t, "privileged-one-container.yml")
+	result := report.Results()[0]
+	auditResult := *result.GetAuditResults()[0]
+
+	fingerprint := Fingerprint(result.GetResource(), &auditResult)
+
+	auditResult.Message = "reworded"
+	auditResult.Severity = kubeaudit.Warn
+	assert.Equal(t, fingerprint, Fingerprint(result.GetResource(), &auditResult))
+
+	// Updating the image of the container does not make the finding new
+	auditResult.Metadata = kubeaudit.Metadata{}
+	for k, v := range result.GetAuditResults()[0].Metadata {
+		auditResult.Metadata[k] = v
+	}
+	auditResult.Metadata[kubeaudit.ContainerImageMetadata] = "scratch:updated"
+	assert.Equal(t, fingerprint, Fingerprint(result.GetResource(), &auditResult))
+
+	// Changing the lines of the finding does not make it new
+	auditResult.Metadata[blame.AuthorMetadata] = "bob <bob@example.com>"
+	auditResult.Metadata[blame.CommitMetadata] = "e83c5163316f89bfbde7d9ab23ca2e25604af290"
+	assert.Equal(t, fingerprint, Fingerprint(result.GetResource(), &auditResult))
+
+	auditResult.Metadata = kubeaudit.Metadata{"Container": "other"}
+	assert.NotEqual(t, fingerprint, Fingerprint(result.GetResource(), &auditResult))
+}
+
+func TestFingerprintIdentifiesJobsByCronJob(t *testing.T) {
+	report := getReport(t, "privileged-cronjob-jobs.yml")
+
+	// The Jobs of a CronJob are the same finding, so a finding of a Job isn't new when the next Job is created
+	baseline := New(report)
+	require.Len(t, baseline.Findings, 1)
+	assert.Equal(t, "CronJob", baseline.Findings[0].Kind)
+	assert.Equal(t, "backup", baseline.Findings[0].Name)
+
+	filtered, suppressed := baseline.Filter(report)
+	assert.Equal(t, 2, suppressed)
+	assert.Empty(t, filtered.Results())
+}
+
+func getReport(t *testing.T, file string) *kubeaudit.Report {
+	return test.GetReport(t, fixtureDir, file, []kubeaudit.Auditable{privileged.New()}, "", test.MANIFEST_MODE)
+}
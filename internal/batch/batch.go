@@ -0,0 +1,187 @@
+// Package batch implements a line-delimited JSON protocol to audit manifests, so editor plugins can keep a single
+// kubeaudit process running and get diagnostics for the content of a file each time it changes. Each line of the input
+// is a Request, answered by a line of the output with its Response. The diagnostics of responses are LSP diagnostics,
+// so they can be passed to the editor as they are
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/redact"
+)
+
+// maxRequestBytes is the maximum size of a request, which is a line of the input
+const maxRequestBytes = 16 * 1024 * 1024
+
+// Source is the source of the diagnostics, shown by editors next to their message
+const Source = "kubeaudit"
+
+// Severities of LSP diagnostics
+const (
+	SeverityError       = 1
+	SeverityWarning     = 2
+	SeverityInformation = 3
+)
+
+// Config configures how requests are audited
+type Config struct {
+	// MinSeverity is the lowest severity of the findings reported as diagnostics
+	MinSeverity kubeaudit.SeverityLevel
+}
+
+// Request is a manifest to audit
+type Request struct {
+	// ID is returned in the response, so clients can match responses to requests. It can be any JSON value
+	ID json.RawMessage `json:"id,omitempty"`
+	// Path is the path of the file the content is from, which diagnostics are attributed to. The file doesn't need to
+	// exist, since the content is audited rather than the file, so unsaved changes can be audited
+	Path string `json:"path"`
+	// Content is the manifest to audit, which can contain several YAML documents
+	Content string `json:"content"`
+}
+
+// Response is the result of auditing the manifest of a request
+type Response struct {
+	ID   json.RawMessage `json:"id,omitempty"`
+	Path string          `json:"path"`
+	// Diagnostics are the findings of the manifest, or an empty list if it has none
+	Diagnostics []Diagnostic `json:"diagnostics"`
+	// Error is set if the request could not be audited, in which case there are no diagnostics
+	Error string `json:"error,omitempty"`
+}
+
+// Diagnostic is a finding as an LSP diagnostic
+type Diagnostic struct {
+	Range Range `json:"range"`
+	// Severity is the LSP severity of the finding. Custom severities have the severity of the built-in severity below
+	// them
+	Severity        int              `json:"severity"`
+	Code            string           `json:"code"`
+	CodeDescription *CodeDescription `json:"codeDescription,omitempty"`
+	Source          string           `json:"source"`
+	Message         string           `json:"message"`
+	Data            DiagnosticData   `json:"data"`
+}
+
+// Range is a range of a document, from the start position to the end position, which is excluded
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Position is a position in a document. Lines and characters are numbered from 0, and characters are counted in UTF-16
+// code units, as in LSP
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// CodeDescription links the rule of a diagnostic to its documentation
+type CodeDescription struct {
+	Href string `json:"href"`
+}
+
+// DiagnosticData is the kubeaudit context of a diagnostic, which editors don't show
+type DiagnosticData struct {
+	Auditor string `json:"auditor"`
+	// Severity is the name of the kubeaudit severity of the finding, including custom severities
+	Severity string            `json:"severity"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Serve audits the manifest of each request read from in with the auditor, and writes the response to out as soon as
+// the manifest is audited. Requests which can't be decoded or audited are answered with an error, and the next requests
+// are still served. It returns when in is closed
+func Serve(auditor *kubeaudit.Kubeaudit, in io.Reader, out io.Writer, config Config) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(nil, maxRequestBytes)
+	encoder := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := encoder.Encode(handle(auditor, []byte(line), config)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// handle returns the response to a request encoded as JSON
+func handle(auditor *kubeaudit.Kubeaudit, data []byte, config Config) Response {
+	var request Request
+	if err := json.Unmarshal(data, &request); err != nil {
+		return Response{Diagnostics: []Diagnostic{}, Error: "invalid request: " + err.Error()}
+	}
+
+	response := Response{ID: request.ID, Path: request.Path, Diagnostics: []Diagnostic{}}
+	report, err := auditor.AuditManifest(request.Path, strings.NewReader(request.Content))
+	if err != nil {
+		response.Error = err.Error()
+		return response
+	}
+
+	lines := strings.Split(request.Content, "\n")
+	for _, finding := range report.FindingsWithMinSeverity(config.MinSeverity) {
+		response.Diagnostics = append(response.Diagnostics, newDiagnostic(finding, lines))
+	}
+	return response
+}
+
+// newDiagnostic returns the diagnostic of a finding of the manifest with the lines. The diagnostic spans from the
+// column of the finding to the end of its line. Findings without a location are reported on the first line
+func newDiagnostic(finding kubeaudit.Finding, lines []string) Diagnostic {
+	line, column := 0, 0
+	if finding.Line > 0 && finding.Line <= len(lines) {
+		line = finding.Line - 1
+		if finding.Column > 0 {
+			column = finding.Column - 1
+		}
+	}
+
+	text := []rune(strings.TrimSuffix(lines[line], "\r"))
+	if column > len(text) {
+		column = len(text)
+	}
+
+	diagnostic := Diagnostic{
+		Range: Range{
+			Start: Position{Line: line, Character: len(utf16.Encode(text[:column]))},
+			End:   Position{Line: line, Character: len(utf16.Encode(text))},
+		},
+		Severity: getSeverity(finding.Severity),
+		Code:     finding.Rule,
+		Source:   Source,
+		Message:  redact.String(finding.Message),
+		Data: DiagnosticData{
+			Auditor:  finding.Auditor,
+			Severity: finding.Severity.String(),
+			Metadata: redact.Map(finding.Metadata),
+		},
+	}
+	for _, reference := range finding.References {
+		if reference.Type == kubeaudit.ReferenceDocs && reference.URL != "" {
+			diagnostic.CodeDescription = &CodeDescription{Href: reference.URL}
+			break
+		}
+	}
+	return diagnostic
+}
+
+// getSeverity returns the LSP severity of a kubeaudit severity
+func getSeverity(severity kubeaudit.SeverityLevel) int {
+	switch severity.Builtin() {
+	case kubeaudit.Error:
+		return SeverityError
+	case kubeaudit.Warn:
+		return SeverityWarning
+	}
+	return SeverityInformation
+}
@@ -0,0 +1,103 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+      securityContext:
+        privileged: true
+`
+
+func newRequest(t *testing.T, id, path, content string) string {
+	data, err := json.Marshal(Request{ID: json.RawMessage(id), Path: path, Content: content})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func serve(t *testing.T, minSeverity kubeaudit.SeverityLevel, requests ...string) []Response {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, Serve(auditor, strings.NewReader(strings.Join(requests, "\n")), &out, Config{MinSeverity: minSeverity}))
+
+	var responses []Response
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var response Response
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &response))
+		responses = append(responses, response)
+	}
+	return responses
+}
+
+func TestServe(t *testing.T) {
+	unprivileged := strings.Replace(manifest, "privileged: true", "privileged: false", 1)
+	responses := serve(t, kubeaudit.Info,
+		newRequest(t, `1`, "pod.yaml", manifest),
+		"",
+		newRequest(t, `"second"`, "unprivileged.yaml", unprivileged),
+	)
+	require.Len(t, responses, 2)
+
+	assert.Equal(t, json.RawMessage(`1`), responses[0].ID)
+	assert.Equal(t, "pod.yaml", responses[0].Path)
+	assert.Empty(t, responses[0].Error)
+	require.Len(t, responses[0].Diagnostics, 1)
+	diagnostic := responses[0].Diagnostics[0]
+	// The diagnostic spans from the container of the finding to the end of the line
+	assert.Equal(t, Range{Start: Position{Line: 6, Character: 6}, End: Position{Line: 6, Character: 21}}, diagnostic.Range)
+	assert.Equal(t, SeverityError, diagnostic.Severity)
+	assert.Equal(t, privileged.PrivilegedTrue, diagnostic.Code)
+	assert.Equal(t, Source, diagnostic.Source)
+	assert.Equal(t, privileged.Name, diagnostic.Data.Auditor)
+	assert.Equal(t, "error", diagnostic.Data.Severity)
+	assert.Equal(t, "container", diagnostic.Data.Metadata["Container"])
+
+	// Documents without findings have an empty list of diagnostics
+	assert.Equal(t, json.RawMessage(`"second"`), responses[1].ID)
+	assert.NotNil(t, responses[1].Diagnostics)
+	assert.Empty(t, responses[1].Diagnostics)
+}
+
+func TestServeMinSeverity(t *testing.T) {
+	nilPrivileged := strings.Replace(manifest, "      securityContext:\n        privileged: true\n", "", 1)
+	responses := serve(t, kubeaudit.Warn, newRequest(t, `1`, "pod.yaml", nilPrivileged))
+	require.Len(t, responses, 1)
+	require.Len(t, responses[0].Diagnostics, 1)
+	assert.Equal(t, SeverityWarning, responses[0].Diagnostics[0].Severity)
+
+	responses = serve(t, kubeaudit.Error, newRequest(t, `1`, "pod.yaml", nilPrivileged))
+	require.Len(t, responses, 1)
+	assert.Empty(t, responses[0].Diagnostics)
+}
+
+func TestServeInvalidRequests(t *testing.T) {
+	// Invalid requests are answered with an error and the next requests are still served
+	responses := serve(t, kubeaudit.Info,
+		"not json",
+		newRequest(t, `2`, "invalid.yaml", "kind: [Pod"),
+		newRequest(t, `3`, "pod.yaml", manifest),
+	)
+	require.Len(t, responses, 3)
+	assert.Contains(t, responses[0].Error, "invalid request")
+	assert.Equal(t, json.RawMessage(`3`), responses[2].ID)
+	assert.Len(t, responses[2].Diagnostics, 1)
+}
@@ -0,0 +1,161 @@
+// Package blame annotates audit results with the last commit which changed the line of the manifest they are about,
+// with the git command, so findings can be routed to the people who last touched the offending lines
+package blame
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+)
+
+const (
+	// CommitMetadata is the metadata key of the SHA of the last commit which changed the line of an audit result
+	CommitMetadata = "BlameCommit"
+	// AuthorMetadata is the metadata key of the author of the commit, as "Name <email>"
+	AuthorMetadata = "BlameAuthor"
+	// DateMetadata is the metadata key of the date the commit was authored, as YYYY-MM-DD
+	DateMetadata = "BlameDate"
+)
+
+// notCommitted is the SHA git blame reports for lines which are not committed yet
+const notCommitted = "0000000000000000000000000000000000000000"
+
+// IsMetadata returns true if the metadata key is one of the keys added by Annotate. They change with every commit to
+// the lines of a finding, so they are not part of the identity of findings
+func IsMetadata(key string) bool {
+	switch key {
+	case CommitMetadata, AuthorMetadata, DateMetadata:
+		return true
+	}
+	return false
+}
+
+// Line is the last commit which changed a line
+type Line struct {
+	Commit string
+	Author string
+	Email  string
+	Time   time.Time
+}
+
+// Blamer blames the lines of manifest files. The blame of each file is cached, so each file is only blamed once
+type Blamer struct {
+	// files are the blamed lines of each file by line number. Files which could not be blamed have no lines
+	files map[string]map[int]Line
+}
+
+// New returns a blamer, or an error if the git command is not installed
+func New() (*Blamer, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, fmt.Errorf("git is needed to blame manifests: %w", err)
+	}
+	return &Blamer{files: map[string]map[int]Line{}}, nil
+}
+
+// Annotate adds the last commit which changed the line of each audit result of the report to its metadata. Audit
+// results without a file and line, and results of lines which are not committed in a Git repository, are unchanged
+func (b *Blamer) Annotate(report *kubeaudit.Report) {
+	for _, result := range report.RawResults() {
+		for _, auditResult := range result.GetAuditResults() {
+			if auditResult.FilePath == "" || auditResult.Line <= 0 {
+				continue
+			}
+			line, ok := b.Blame(auditResult.FilePath, auditResult.Line)
+			if !ok {
+				continue
+			}
+			if auditResult.Metadata == nil {
+				auditResult.Metadata = kubeaudit.Metadata{}
+			}
+			auditResult.Metadata[CommitMetadata] = line.Commit
+			auditResult.Metadata[AuthorMetadata] = fmt.Sprintf("%s <%s>", line.Author, line.Email)
+			auditResult.Metadata[DateMetadata] = line.Time.Format("2006-01-02")
+		}
+	}
+}
+
+// Blame returns the last commit which changed the line of the file, numbered from 1, or false if it is not committed
+func (b *Blamer) Blame(path string, lineNumber int) (Line, bool) {
+	lines, ok := b.files[path]
+	if !ok {
+		// Files outside of a repository or not tracked can't be blamed, so they have no lines
+		lines, _ = blameFile(path)
+		b.files[path] = lines
+	}
+	line, ok := lines[lineNumber]
+	return line, ok
+}
+
+// blameFile returns the last commit which changed each committed line of the file
+func blameFile(path string) (map[int]Line, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", filepath.Dir(path), "blame", "--porcelain", "--", filepath.Base(path))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to blame %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return parsePorcelain(&stdout)
+}
+
+// parsePorcelain parses the output of git blame --porcelain. Each line of the file is a header with the SHA of its
+// commit and its line number, followed by the details of the commit the first time it appears, and the content of the
+// line prefixed with a tab
+func parsePorcelain(output *bytes.Buffer) (map[int]Line, error) {
+	commits := map[string]*Line{}
+	lines := map[int]Line{}
+
+	var commit *Line
+	lineNumber := 0
+	scanner := bufio.NewScanner(output)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		text := scanner.Text()
+		if strings.HasPrefix(text, "\t") {
+			if commit != nil && commit.Commit != notCommitted {
+				lines[lineNumber] = *commit
+			}
+			commit = nil
+			continue
+		}
+
+		key, value, _ := strings.Cut(text, " ")
+		if commit == nil {
+			fields := strings.Fields(value)
+			if len(key) != len(notCommitted) || len(fields) < 2 {
+				return nil, fmt.Errorf("invalid blame header %q", text)
+			}
+			n, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid blame header %q", text)
+			}
+			lineNumber = n
+			if commit = commits[key]; commit == nil {
+				commit = &Line{Commit: key}
+				commits[key] = commit
+			}
+			continue
+		}
+
+		switch key {
+		case "author":
+			commit.Author = value
+		case "author-mail":
+			commit.Email = strings.TrimSuffix(strings.TrimPrefix(value, "<"), ">")
+		case "author-time":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid author time %q", value)
+			}
+			commit.Time = time.Unix(seconds, 0).UTC()
+		}
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,106 @@
+package blame
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+      securityContext:
+        privileged: true
+`
+
+// newRepository returns a repository where the manifest was committed by alice, and the container was then renamed
+// by bob
+func newRepository(t *testing.T) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	path := filepath.Join(repo, "pod.yaml")
+	commit := func(name, date string) {
+		for _, args := range [][]string{
+			{"add", "-A"},
+			{"-c", "user.name=" + name, "-c", "user.email=" + name + "@example.com", "commit", "--quiet", "--date", date, "-m", "change"},
+		} {
+			cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+			output, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(output))
+		}
+	}
+
+	require.NoError(t, exec.Command("git", "init", "--quiet", repo).Run())
+	require.NoError(t, os.WriteFile(path, bytes.Replace([]byte(manifest), []byte("name: container"), []byte("name: app"), 1), 0644))
+	commit("alice", "2023-01-02T10:00:00Z")
+	require.NoError(t, os.WriteFile(path, []byte(manifest), 0644))
+	commit("bob", "2023-03-04T10:00:00Z")
+	return repo
+}
+
+func TestAnnotate(t *testing.T) {
+	repo := newRepository(t)
+	path := filepath.Join(repo, "pod.yaml")
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+	report, err := auditor.AuditManifestFiles([]string{path})
+	require.NoError(t, err)
+
+	blamer, err := New()
+	require.NoError(t, err)
+	blamer.Annotate(report)
+
+	auditResults := report.Results()[0].GetAuditResults()
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, 7, auditResults[0].Line)
+	assert.Equal(t, "bob <bob@example.com>", auditResults[0].Metadata[AuthorMetadata])
+	assert.Equal(t, "2023-03-04", auditResults[0].Metadata[DateMetadata])
+	assert.Len(t, auditResults[0].Metadata[CommitMetadata], 40)
+
+	line, ok := blamer.Blame(path, 1)
+	require.True(t, ok)
+	assert.Equal(t, "alice", line.Author)
+	assert.Equal(t, "alice@example.com", line.Email)
+	assert.NotEqual(t, auditResults[0].Metadata[CommitMetadata], line.Commit)
+}
+
+func TestBlameNotCommitted(t *testing.T) {
+	repo := newRepository(t)
+	blamer, err := New()
+	require.NoError(t, err)
+
+	// Changed lines are not committed yet
+	path := filepath.Join(repo, "pod.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(manifest+"  hostNetwork: true\n"), 0644))
+	_, ok := blamer.Blame(path, 11)
+	assert.False(t, ok)
+	_, ok = blamer.Blame(path, 10)
+	assert.True(t, ok)
+
+	// Files which are not tracked, or not in a repository, can't be blamed
+	untracked := filepath.Join(repo, "untracked.yaml")
+	require.NoError(t, os.WriteFile(untracked, []byte(manifest), 0644))
+	_, ok = blamer.Blame(untracked, 1)
+	assert.False(t, ok)
+
+	outside := filepath.Join(t.TempDir(), "pod.yaml")
+	require.NoError(t, os.WriteFile(outside, []byte(manifest), 0644))
+	_, ok = blamer.Blame(outside, 1)
+	assert.False(t, ok)
+}
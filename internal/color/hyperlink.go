@@ -0,0 +1,52 @@
+package color
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Hyperlink returns s as an OSC 8 terminal hyperlink to url. Terminals which support hyperlinks show s as a link,
+// and terminals which don't show s as it is
+func Hyperlink(url, s string) string {
+	if url == "" {
+		return s
+	}
+	return "\033]8;;" + url + "\033\\" + s + "\033]8;;\033\\"
+}
+
+// SupportsHyperlinks returns true if f is a terminal which is known to support OSC 8 hyperlinks. The terminal is
+// detected from the environment variables it sets, which are looked up with getenv
+func SupportsHyperlinks(f *os.File, getenv func(string) string) bool {
+	if !isTerminal(f) || getenv("TERM") == "dumb" {
+		return false
+	}
+
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "ghostty", "Hyper":
+		return true
+	}
+
+	if vte, err := strconv.Atoi(getenv("VTE_VERSION")); err == nil && vte >= 5000 {
+		return true
+	}
+
+	for _, name := range []string{"WT_SESSION", "KONSOLE_VERSION", "KITTY_WINDOW_ID", "DOMTERM"} {
+		if getenv(name) != "" {
+			return true
+		}
+	}
+
+	return strings.HasPrefix(getenv("TERM"), "xterm-kitty") || getenv("TERM") == "alacritty"
+}
+
+func isTerminal(f *os.File) bool {
+	if f == nil {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,47 @@
+package color
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHyperlink(t *testing.T) {
+	assert.Equal(t, "\033]8;;https://example.com\033\\text\033]8;;\033\\", Hyperlink("https://example.com", "text"))
+	assert.Equal(t, "text", Hyperlink("", "text"))
+}
+
+func TestSupportsHyperlinks(t *testing.T) {
+	// /dev/null is a character device so it is detected as a terminal
+	terminal, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer terminal.Close()
+
+	file, err := os.CreateTemp(t.TempDir(), "output")
+	require.NoError(t, err)
+	defer file.Close()
+
+	cases := []struct {
+		testName string
+		file     *os.File
+		env      map[string]string
+		expected bool
+	}{
+		{"Supported terminal", terminal, map[string]string{"TERM_PROGRAM": "iTerm.app"}, true},
+		{"Recent VTE", terminal, map[string]string{"VTE_VERSION": "6003"}, true},
+		{"Old VTE", terminal, map[string]string{"VTE_VERSION": "4601"}, false},
+		{"Windows Terminal", terminal, map[string]string{"WT_SESSION": "id"}, true},
+		{"Kitty", terminal, map[string]string{"TERM": "xterm-kitty"}, true},
+		{"Unknown terminal", terminal, map[string]string{"TERM": "xterm-256color"}, false},
+		{"Dumb terminal", terminal, map[string]string{"TERM": "dumb", "TERM_PROGRAM": "iTerm.app"}, false},
+		{"Not a terminal", file, map[string]string{"TERM_PROGRAM": "iTerm.app"}, false},
+		{"No file", nil, map[string]string{"TERM_PROGRAM": "iTerm.app"}, false},
+	}
+
+	for _, tc := range cases {
+		getenv := func(name string) string { return tc.env[name] }
+		assert.Equal(t, tc.expected, SupportsHyperlinks(tc.file, getenv), tc.testName)
+	}
+}
@@ -0,0 +1,161 @@
+// Package compliance maps audit results to the controls of the CIS Kubernetes Benchmark and the NSA/CISA Kubernetes
+// Hardening Guide, and reports which controls pass for the audited resources
+package compliance
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/color"
+	"github.com/Shopify/kubeaudit/internal/redact"
+)
+
+// Report is the result of each control of a benchmark for the audited resources
+type Report struct {
+	Benchmark string          `json:"benchmark"`
+	Controls  []ControlResult `json:"controls"`
+	// Passed and Failed are the number of controls which passed or failed. Controls which don't apply to any of the
+	// audited resources are in neither
+	Passed     int     `json:"passed"`
+	Failed     int     `json:"failed"`
+	Percentage float64 `json:"percentage"`
+}
+
+// ControlResult is the result of a control. A resource fails the control if a warning or error is reported for one of
+// the control rules. Informational results don't fail controls
+type ControlResult struct {
+	ID         string    `json:"id"`
+	Title      string    `json:"title"`
+	Status     Status    `json:"status"`
+	Resources  int       `json:"resources"`
+	Passed     int       `json:"passed"`
+	Failed     int       `json:"failed"`
+	Percentage float64   `json:"percentage"`
+	Findings   []Finding `json:"findings,omitempty"`
+}
+
+// Finding is an audit result which fails a control
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Resource string `json:"resource"`
+	Message  string `json:"message"`
+}
+
+// Status is the outcome of a control
+type Status string
+
+const (
+	// Pass is the status of controls which every audited resource passes
+	Pass Status = "pass"
+	// Fail is the status of controls which at least one audited resource fails
+	Fail Status = "fail"
+	// NotApplicable is the status of controls which don't apply to any of the audited resources
+	NotApplicable Status = "n/a"
+)
+
+// Create checks the controls of the benchmark against the audited resources of the report
+func Create(kubeauditReport *kubeaudit.Report, benchmark Benchmark) *Report {
+	report := &Report{Benchmark: benchmark.Title}
+
+	for _, control := range benchmark.Controls {
+		controlResult := ControlResult{ID: control.ID, Title: control.Title}
+
+		for _, result := range kubeauditReport.RawResults() {
+			resource := result.GetResource()
+			if resource == nil || resource.Object() == nil || !control.appliesTo(resource.Object()) {
+				continue
+			}
+			controlResult.Resources++
+
+			failed := false
+			for _, auditResult := range result.GetAuditResults() {
+				if !auditResult.Severity.AtLeast(kubeaudit.Warn) || !control.HasRule(auditResult.Rule) {
+					continue
+				}
+				failed = true
+				controlResult.Findings = append(controlResult.Findings, Finding{
+					Rule:     auditResult.Rule,
+					Severity: auditResult.Severity.String(),
+					Resource: kubeaudit.ResourceName(resource),
+					Message:  redact.String(auditResult.Message),
+				})
+			}
+			if failed {
+				controlResult.Failed++
+			} else {
+				controlResult.Passed++
+			}
+		}
+
+		switch {
+		case controlResult.Resources == 0:
+			controlResult.Status = NotApplicable
+		case controlResult.Failed > 0:
+			controlResult.Status = Fail
+			report.Failed++
+		default:
+			controlResult.Status = Pass
+			report.Passed++
+		}
+		controlResult.Percentage = percentage(controlResult.Passed, controlResult.Resources)
+
+		report.Controls = append(report.Controls, controlResult)
+	}
+
+	report.Percentage = percentage(report.Passed, report.Passed+report.Failed)
+
+	return report
+}
+
+// Write writes the controls grouped with the findings which fail them, followed by the summary of the benchmark
+func (r *Report) Write(w io.Writer, useColor bool) error {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "%s\n\n", r.Benchmark)
+	for _, control := range r.Controls {
+		status := strings.ToUpper(string(control.Status))
+		if useColor {
+			switch control.Status {
+			case Pass:
+				status = color.Green(status)
+			case Fail:
+				status = color.Red(status)
+			default:
+				status = color.Gray(status)
+			}
+		}
+
+		fmt.Fprintf(&out, "[%s] %s %s\n", status, control.ID, control.Title)
+		if control.Status == NotApplicable {
+			continue
+		}
+		fmt.Fprintf(&out, "   %d/%d resources passed (%.0f%%)\n", control.Passed, control.Resources, control.Percentage)
+		for _, finding := range control.Findings {
+			fmt.Fprintf(&out, "   -- [%s] %s: %s\n", finding.Severity, finding.Rule, finding.Resource)
+			fmt.Fprintf(&out, "      %s\n", finding.Message)
+		}
+	}
+	fmt.Fprintf(&out, "\n%d/%d controls passed (%.0f%%)\n", r.Passed, r.Passed+r.Failed, r.Percentage)
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// WriteJSON writes the report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// percentage returns the percentage of passed out of total, which is 100 if there is nothing to pass
+func percentage(passed, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return float64(passed) * 100 / float64(total)
+}
@@ -0,0 +1,135 @@
+package compliance
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/capabilities"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+)
+
+const manifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: privileged
+  namespace: namespace
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+      securityContext:
+        privileged: true
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: unprivileged
+  namespace: namespace
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+      securityContext:
+        privileged: false
+`
+
+func auditManifest(t *testing.T) *kubeaudit.Report {
+	imageAuditor := image.New(image.Config{Image: "scratch:1.0"})
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New(), imageAuditor})
+	require.NoError(t, err)
+	report, err := auditor.AuditManifest("", strings.NewReader(manifest))
+	require.NoError(t, err)
+	return report
+}
+
+func getControl(t *testing.T, report *Report, id string) ControlResult {
+	for _, control := range report.Controls {
+		if control.ID == id {
+			return control
+		}
+	}
+	require.Fail(t, "control not found", id)
+	return ControlResult{}
+}
+
+func TestCreate(t *testing.T) {
+	benchmark, ok := GetBenchmark(CIS)
+	require.True(t, ok)
+	report := Create(auditManifest(t), benchmark)
+
+	assert.Equal(t, "CIS Kubernetes Benchmark v1.8.0", report.Benchmark)
+	require.Len(t, report.Controls, len(benchmark.Controls))
+
+	control := getControl(t, report, "5.2.2")
+	assert.Equal(t, Fail, control.Status)
+	assert.Equal(t, 2, control.Resources)
+	assert.Equal(t, 1, control.Passed)
+	assert.Equal(t, 1, control.Failed)
+	assert.Equal(t, float64(50), control.Percentage)
+	assert.Equal(t, []Finding{{
+		Rule:     privileged.PrivilegedTrue,
+		Severity: "error",
+		Resource: "Pod/namespace/privileged",
+		Message:  "privileged is set to 'true' in container SecurityContext. It should be set to 'false'.",
+	}}, control.Findings)
+
+	// No rule of the control is reported, since only the privileged and image auditors are used
+	control = getControl(t, report, "5.2.5")
+	assert.Equal(t, Pass, control.Status)
+	assert.Equal(t, 2, control.Passed)
+
+	// There are no namespaces in the manifest
+	control = getControl(t, report, "5.3.2")
+	assert.Equal(t, NotApplicable, control.Status)
+	assert.Equal(t, 0, control.Resources)
+
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, len(benchmark.Controls)-3, report.Passed)
+}
+
+func TestCreateInfoResultsDontFail(t *testing.T) {
+	benchmark, ok := GetBenchmark(NSA)
+	require.True(t, ok)
+	report := Create(auditManifest(t), benchmark)
+
+	// The image auditor reports ImageCorrect as an info result
+	control := getControl(t, report, "secure-images")
+	assert.Equal(t, Pass, control.Status)
+	assert.Empty(t, control.Findings)
+}
+
+func TestWrite(t *testing.T) {
+	benchmark, _ := GetBenchmark(CIS)
+	report := Create(auditManifest(t), benchmark)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, false))
+	assert.Contains(t, out.String(), "[FAIL] 5.2.2 Minimize the admission of privileged containers\n   1/2 resources passed (50%)\n   -- [error] PrivilegedTrue: Pod/namespace/privileged\n")
+	assert.Contains(t, out.String(), "[N/A] 5.3.2 Ensure that all Namespaces have Network Policies defined\n[")
+	assert.Contains(t, out.String(), "controls passed")
+
+	out.Reset()
+	require.NoError(t, report.WriteJSON(&out))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, *report, decoded)
+}
+
+func TestTags(t *testing.T) {
+	assert.Equal(t, []string{"cis-5.2.9", "nsa-pod-security-enforcement"}, Tags(capabilities.CapabilityAdded))
+	assert.Equal(t, []string{"cis-5.3.2", "nsa-network-policies"}, Tags(netpols.MissingDefaultDenyIngressNetworkPolicy))
+	assert.Empty(t, Tags(image.ImageCorrect))
+}
+
+func TestGetBenchmark(t *testing.T) {
+	_, ok := GetBenchmark("pci")
+	assert.False(t, ok)
+}
@@ -0,0 +1,163 @@
+package compliance
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/apparmor"
+	"github.com/Shopify/kubeaudit/auditors/asat"
+	"github.com/Shopify/kubeaudit/auditors/capabilities"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/etcd"
+	"github.com/Shopify/kubeaudit/auditors/hostnet"
+	"github.com/Shopify/kubeaudit/auditors/hostns"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/mounts"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/nonroot"
+	"github.com/Shopify/kubeaudit/auditors/privesc"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/rootfs"
+	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+const (
+	// CIS is the CIS Kubernetes Benchmark. Only the policies of section 5 can be checked from the resources, the
+	// other sections are about the configuration of the control plane and nodes
+	CIS = "cis"
+	// NSA is the NSA/CISA Kubernetes Hardening Guide
+	NSA = "nsa"
+)
+
+// Benchmark is a set of controls
+type Benchmark struct {
+	// Name is the name used with the --compliance flag, which is also the prefix of the SARIF tags of its controls
+	Name string
+	// Title is the title of the benchmark, with the version the controls are taken from
+	Title string
+	// URL is where the benchmark is published. Controls can't be linked to individually
+	URL      string
+	Controls []Control
+}
+
+// Control is a benchmark control which fails when one of its rules is reported
+type Control struct {
+	ID    string
+	Title string
+	Rules []string
+
+	// appliesTo returns true for the resources which the control is checked for
+	appliesTo func(resource k8s.Resource) bool
+}
+
+// Benchmarks are the benchmarks which audit results are mapped to
+var Benchmarks = []Benchmark{
+	{
+		Name:  CIS,
+		Title: "CIS Kubernetes Benchmark v1.8.0",
+		URL:   "https://www.cisecurity.org/benchmark/kubernetes",
+		Controls: []Control{
+			{"5.1.1", "Ensure that the cluster-admin role is only used where required", []string{rbac.ServiceAccountBoundToClusterAdmin}, workloads},
+			{"5.1.2", "Minimize access to secrets", []string{rbac.ServiceAccountCanReadSecretsInAllNamespaces}, workloads},
+			{"5.1.3", "Minimize wildcard use in Roles and ClusterRoles", []string{rbac.ServiceAccountGrantedWildcard, rbac.AggregationRuleSelectsAllClusterRoles}, anyOf(workloads, roles)},
+			{"5.1.5", "Ensure that default service accounts are not actively used", []string{asat.AutomountServiceAccountTokenTrueAndDefaultSA, asat.AutomountServiceAccountTokenTrueInNamespaceDefaultSA}, anyOf(workloads, namespaces)},
+			{"5.1.6", "Ensure that Service Account Tokens are only mounted where necessary", []string{asat.AutomountServiceAccountTokenTrueAndDefaultSA, asat.AutomountServiceAccountTokenDeprecated}, workloads},
+			{"5.1.8", "Limit use of the Bind, Impersonate and Escalate permissions in the Kubernetes cluster", []string{rbac.RoleGrantsBind, rbac.RoleGrantsImpersonate, rbac.RoleGrantsEscalate, rbac.AggregationRuleSelectsEscalatingClusterRole}, roles},
+			{"5.2.2", "Minimize the admission of privileged containers", []string{privileged.PrivilegedTrue, privileged.PrivilegedNil, pss.PSSBaselinePrivilegedContainers}, workloads},
+			{"5.2.3", "Minimize the admission of containers wishing to share the host process ID namespace", []string{hostns.NamespaceHostPIDTrue, pss.PSSBaselineHostNamespaces}, workloads},
+			{"5.2.4", "Minimize the admission of containers wishing to share the host IPC namespace", []string{hostns.NamespaceHostIPCTrue, pss.PSSBaselineHostNamespaces}, workloads},
+			{"5.2.5", "Minimize the admission of containers wishing to share the host network namespace", []string{hostns.NamespaceHostNetworkTrue, pss.PSSBaselineHostNamespaces}, workloads},
+			{"5.2.6", "Minimize the admission of containers with allowPrivilegeEscalation", []string{privesc.AllowPrivilegeEscalationNil, privesc.AllowPrivilegeEscalationTrue, pss.PSSRestrictedPrivilegeEscalation}, workloads},
+			{"5.2.7", "Minimize the admission of root containers", []string{nonroot.RunAsUserCSCRoot, nonroot.RunAsUserPSCRoot, nonroot.RunAsNonRootCSCFalse, nonroot.RunAsNonRootPSCNilCSCNil, nonroot.RunAsNonRootPSCFalseCSCNil, pss.PSSRestrictedRunningAsNonRoot, pss.PSSRestrictedRunningAsNonRootUser}, workloads},
+			{"5.2.8", "Minimize the admission of containers with the NET_RAW capability", []string{capabilities.CapabilityShouldDropAll, capabilities.CapabilityOrSecurityContextMissing}, workloads},
+			{"5.2.9", "Minimize the admission of containers with added capabilities", []string{capabilities.CapabilityAdded, pss.PSSBaselineCapabilities}, workloads},
+			{"5.2.10", "Minimize the admission of containers with capabilities assigned", []string{capabilities.CapabilityShouldDropAll, capabilities.CapabilityOrSecurityContextMissing, pss.PSSRestrictedCapabilities}, workloads},
+			{"5.2.12", "Minimize the admission of HostPath volumes", []string{mounts.SensitivePathsMounted, pss.PSSBaselineHostPathVolumes}, workloads},
+			{"5.2.13", "Minimize the admission of containers which use HostPorts", []string{hostnet.HostPortSet, pss.PSSBaselineHostPorts}, workloads},
+			{"5.3.2", "Ensure that all Namespaces have Network Policies defined", []string{netpols.MissingDefaultDenyIngressAndEgressNetworkPolicy, netpols.MissingDefaultDenyIngressNetworkPolicy, netpols.MissingDefaultDenyEgressNetworkPolicy}, namespaces},
+			{"5.7.2", "Ensure that the seccomp profile is set to docker/default in your Pod definitions", []string{seccomp.SeccompProfileMissing, seccomp.SeccompDisabledPod, seccomp.SeccompDisabledContainer, pss.PSSBaselineSeccomp, pss.PSSRestrictedSeccomp}, workloads},
+			{"5.7.3", "Apply SecurityContext to your Pods and Containers", []string{rootfs.ReadOnlyRootFilesystemFalse, rootfs.ReadOnlyRootFilesystemNil, apparmor.AppArmorDisabled, pss.PSSBaselineAppArmor, pss.PSSBaselineSELinux}, workloads},
+		},
+	},
+	{
+		Name:  NSA,
+		Title: "NSA/CISA Kubernetes Hardening Guide v1.2",
+		URL:   "https://media.defense.gov/2022/Aug/29/2003066362/-1/-1/0/CTR_KUBERNETES_HARDENING_GUIDANCE_1.2_20220829.PDF",
+		Controls: []Control{
+			{"non-root-containers", "Pod security: Use containers built to run applications as non-root users", []string{nonroot.RunAsUserCSCRoot, nonroot.RunAsUserPSCRoot, nonroot.RunAsNonRootCSCFalse, nonroot.RunAsNonRootPSCNilCSCNil, nonroot.RunAsNonRootPSCFalseCSCNil, pss.PSSRestrictedRunningAsNonRoot, pss.PSSRestrictedRunningAsNonRootUser}, workloads},
+			{"immutable-filesystems", "Pod security: Run containers with immutable file systems", []string{rootfs.ReadOnlyRootFilesystemFalse, rootfs.ReadOnlyRootFilesystemNil}, workloads},
+			{"secure-images", "Pod security: Build secure container images from trusted repositories", []string{image.ImageTagMissing, imagepolicy.ImageRegistryNotAllowed, imagepolicy.ImageTagLatest, imagepolicy.ImageDigestMissing}, workloads},
+			{"pod-security-enforcement", "Pod security: Enforce pod security to prevent privileged containers and host access", []string{privileged.PrivilegedTrue, privesc.AllowPrivilegeEscalationTrue, capabilities.CapabilityAdded, hostns.NamespaceHostPIDTrue, hostns.NamespaceHostIPCTrue, hostns.NamespaceHostNetworkTrue, hostnet.HostPortSet, mounts.SensitivePathsMounted, pss.PSSBaselineHostNamespaces, pss.PSSBaselinePrivilegedContainers, pss.PSSBaselineCapabilities, pss.PSSBaselineHostPathVolumes, pss.PSSBaselineHostPorts}, workloads},
+			{"service-account-tokens", "Pod security: Protect pod service account tokens", []string{asat.AutomountServiceAccountTokenTrueAndDefaultSA, asat.AutomountServiceAccountTokenTrueInNamespaceDefaultSA, asat.AutomountServiceAccountTokenDeprecated}, anyOf(workloads, namespaces)},
+			{"hardened-container-environments", "Pod security: Harden container environments with seccomp, AppArmor and SELinux", []string{seccomp.SeccompProfileMissing, seccomp.SeccompDisabledPod, seccomp.SeccompDisabledContainer, apparmor.AppArmorDisabled, capabilities.CapabilityShouldDropAll, capabilities.CapabilityOrSecurityContextMissing, pss.PSSBaselineSeccomp, pss.PSSBaselineAppArmor, pss.PSSBaselineSELinux}, workloads},
+			{"network-policies", "Network separation: Use network policies to deny traffic by default", []string{netpols.MissingDefaultDenyIngressAndEgressNetworkPolicy, netpols.MissingDefaultDenyIngressNetworkPolicy, netpols.MissingDefaultDenyEgressNetworkPolicy, netpols.AllowAllIngressNetworkPolicyExists, netpols.AllowAllEgressNetworkPolicyExists, egress.NamespaceEgressUnrestricted, egress.WorkloadEgressUnrestricted}, anyOf(workloads, namespaces)},
+			{"resource-policies", "Network separation: Set resource limits and requests on pods", []string{limits.LimitsNotSet, limits.LimitsCPUNotSet, limits.LimitsMemoryNotSet, requests.RequestsNotSet, requests.RequestsCPUNotSet, requests.RequestsMemoryNotSet}, workloads},
+			{"etcd-and-secrets-encryption", "Control plane hardening: Secure etcd and encrypt secrets at rest", []string{etcd.SecretsEncryptionAtRestDisabled, etcd.SecretsStoredUnencrypted, etcd.EtcdConnectionInsecure, etcd.EtcdClientCertAuthDisabled, etcd.EtcdClientURLInsecure, etcd.EtcdPeerCertAuthDisabled}, etcd.IsControlPlane},
+			{"rbac", "Authentication and authorization: Use RBAC with the least privileges", []string{rbac.RoleGrantsBind, rbac.RoleGrantsImpersonate, rbac.RoleGrantsEscalate, rbac.AggregationRuleSelectsAllClusterRoles, rbac.AggregationRuleSelectsEscalatingClusterRole, rbac.ServiceAccountBoundToClusterAdmin, rbac.ServiceAccountGrantedWildcard, rbac.ServiceAccountCanReadSecretsInAllNamespaces}, anyOf(workloads, roles)},
+		},
+	},
+}
+
+// GetBenchmark returns the benchmark with the name used by the --compliance flag
+func GetBenchmark(name string) (Benchmark, bool) {
+	for _, benchmark := range Benchmarks {
+		if benchmark.Name == name {
+			return benchmark, true
+		}
+	}
+	return Benchmark{}, false
+}
+
+// Tags returns the tags of the benchmark controls which fail when the rule is reported, in the form <benchmark>-<ID>
+func Tags(rule string) []string {
+	var tags []string
+	for _, benchmark := range Benchmarks {
+		for _, control := range benchmark.Controls {
+			if control.HasRule(rule) {
+				tags = append(tags, benchmark.Name+"-"+control.ID)
+			}
+		}
+	}
+	return tags
+}
+
+// HasRule returns true if the control fails when the rule is reported
+func (control Control) HasRule(rule string) bool {
+	for _, controlRule := range control.Rules {
+		if controlRule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func workloads(resource k8s.Resource) bool {
+	return k8s.GetPodSpec(resource) != nil
+}
+
+func namespaces(resource k8s.Resource) bool {
+	_, ok := resource.(*k8s.NamespaceV1)
+	return ok
+}
+
+func roles(resource k8s.Resource) bool {
+	switch resource.(type) {
+	case *k8s.RoleV1, *k8s.ClusterRoleV1:
+		return true
+	}
+	return false
+}
+
+func anyOf(appliesTo ...func(resource k8s.Resource) bool) func(resource k8s.Resource) bool {
+	return func(resource k8s.Resource) bool {
+		for _, applies := range appliesTo {
+			if applies(resource) {
+				return true
+			}
+		}
+		return false
+	}
+}
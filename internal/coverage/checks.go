@@ -0,0 +1,137 @@
+package coverage
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/auditors/annotations"
+	"github.com/Shopify/kubeaudit/auditors/apparmor"
+	"github.com/Shopify/kubeaudit/auditors/asat"
+	"github.com/Shopify/kubeaudit/auditors/capabilities"
+	"github.com/Shopify/kubeaudit/auditors/deprecatedapis"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	"github.com/Shopify/kubeaudit/auditors/exposure"
+	"github.com/Shopify/kubeaudit/auditors/labels"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
+	"github.com/Shopify/kubeaudit/auditors/ports"
+	"github.com/Shopify/kubeaudit/auditors/privesc"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/Shopify/kubeaudit/auditors/rego"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
+	"github.com/Shopify/kubeaudit/auditors/rootfs"
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
+	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// linuxAuditors are the auditors which only check settings of Linux containers, which Windows pods can't set
+var linuxAuditors = map[string]bool{
+	apparmor.Name:     true,
+	capabilities.Name: true,
+	privesc.Name:      true,
+	privileged.Name:   true,
+	rootfs.Name:       true,
+	seccomp.Name:      true,
+}
+
+// check returns why the auditor is skipped for the resource with the config, or nil if the auditor applies to it
+func check(auditorName string, resource k8s.Resource, conf config.KubeauditConfig) *Skip {
+	if skip := checkEnabled(auditorName, conf); skip != nil {
+		return skip
+	}
+	if supported, message := supportsKind(auditorName, resource); !supported {
+		return &Skip{Reason: UnsupportedKind, Message: message}
+	}
+	if auditorName == asat.Name && k8s.IsNamespaceV1(resource) && !conf.GetAuditorConfigs().ASAT.NamespaceDefaultServiceAccount {
+		return &Skip{Reason: Config, Message: "the namespace default service account check is not enabled"}
+	}
+	if linuxAuditors[auditorName] && k8s.IsWindowsPod(resource) {
+		return &Skip{Reason: OSMismatch, Message: "only checks Linux settings, and the pod runs on Windows"}
+	}
+	return checkConfigured(auditorName, conf)
+}
+
+// checkEnabled returns why the auditor is skipped if the config doesn't enable it
+func checkEnabled(auditorName string, conf config.KubeauditConfig) *Skip {
+	for _, enabledAuditorName := range all.EnabledAuditorNames(conf) {
+		if enabledAuditorName == auditorName {
+			return nil
+		}
+	}
+	if _, ok := conf.GetEnabledAuditors()[auditorName]; !ok {
+		return &Skip{Reason: Config, Message: "optional auditor, not enabled in enabledAuditors"}
+	}
+	return &Skip{Reason: Config, Message: "disabled in enabledAuditors"}
+}
+
+// checkConfigured returns why the auditor is skipped if it has nothing to check with the config
+func checkConfigured(auditorName string, conf config.KubeauditConfig) *Skip {
+	auditorConfig := conf.GetAuditorConfigs()
+	switch {
+	case auditorName == annotations.Name && len(auditorConfig.Annotations.Required) == 0 && len(auditorConfig.Annotations.Forbidden) == 0:
+		return &Skip{Reason: Config, Message: "no required or forbidden annotations are configured"}
+	case auditorName == labels.Name && len(auditorConfig.Labels.Required) == 0:
+		return &Skip{Reason: Config, Message: "no required labels are configured"}
+	case auditorName == nodecoverage.Name && len(auditorConfig.NodeCoverage.DaemonSets) == 0:
+		return &Skip{Reason: Config, Message: "no DaemonSets are configured"}
+	case auditorName == rego.Name && auditorConfig.Rego.PolicyDir == "":
+		return &Skip{Reason: Config, Message: "no policy directory is configured"}
+	case auditorName == runtimeclass.Name && len(auditorConfig.RuntimeClass.Allowed) == 0 && len(auditorConfig.RuntimeClass.Required) == 0:
+		return &Skip{Reason: Config, Message: "no allowed or required runtime classes are configured"}
+	}
+	return nil
+}
+
+// supportsKind returns true if the auditor audits resources of the kind of the resource, or a message describing the
+// kinds it audits otherwise
+func supportsKind(auditorName string, resource k8s.Resource) (bool, string) {
+	isWorkload := k8s.GetPodSpec(resource) != nil
+	isNamespace := k8s.IsNamespaceV1(resource)
+
+	switch auditorName {
+	case annotations.Name, asat.Name, labels.Name, pss.Name:
+		return isWorkload || isNamespace, "only audits workloads and namespaces"
+	case deprecatedapis.Name, rego.Name, secrets.Name:
+		return true, ""
+	case egress.Name:
+		_, isPodTemplate := resource.(*k8s.PodTemplateV1)
+		return (isWorkload && !isPodTemplate) || isNamespace, "only audits workloads other than pod templates, and namespaces"
+	case ephemeral.Name:
+		_, isPod := resource.(*k8s.PodV1)
+		return isPod, "only audits pods"
+	case exposure.Name:
+		switch resource.(type) {
+		case *k8s.ServiceV1, *k8s.IngressV1:
+			return true, ""
+		}
+		return false, "only audits Services and Ingresses"
+	case netpols.Name:
+		return isNamespace, "only audits namespaces"
+	case nodecoverage.Name:
+		switch resource.(type) {
+		case *k8s.DaemonSetV1, *k8s.NodeV1:
+			return true, ""
+		}
+		return false, "only audits DaemonSets and nodes"
+	case ports.Name:
+		_, isService := resource.(*k8s.ServiceV1)
+		return isWorkload || isService, "only audits workloads and services"
+	case rbac.Name:
+		switch resource.(type) {
+		case *k8s.RoleV1, *k8s.ClusterRoleV1:
+			return true, ""
+		}
+		return isWorkload, "only audits workloads, roles and cluster roles"
+	case resilience.Name:
+		switch resource.(type) {
+		case *k8s.DeploymentV1, *k8s.StatefulSetV1:
+			return true, ""
+		}
+		return false, "only audits Deployments and StatefulSets"
+	}
+	return isWorkload, "only audits workloads"
+}
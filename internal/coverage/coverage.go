@@ -0,0 +1,181 @@
+// Package coverage reports which auditors apply to each kind of resource of an audit, and why the others are skipped,
+// so blind spots of the auditors and the kubeaudit config are visible at a glance
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/color"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// Reason is the reason an auditor is skipped for a resource
+type Reason string
+
+const (
+	// UnsupportedKind means the auditor doesn't audit resources of the kind
+	UnsupportedKind Reason = "unsupported kind"
+	// OSMismatch means the auditor only checks Linux settings, and the pod runs on Windows
+	OSMismatch Reason = "os mismatch"
+	// Config means the auditor is disabled by the kubeaudit config, or has nothing to check with it
+	Config Reason = "config"
+)
+
+// Report is the coverage of the resources of an audit by the auditors, per kind
+type Report struct {
+	// Resources is the number of audited resources
+	Resources int
+	// Kinds are ordered by kind
+	Kinds []Kind
+}
+
+// Kind is the coverage of the resources of a kind
+type Kind struct {
+	Kind      string
+	Resources int
+	// Auditors are in the order of all.AuditorNames
+	Auditors []Auditor
+}
+
+// Auditor is the coverage of the resources of a kind by an auditor
+type Auditor struct {
+	Name string
+	// Applied is the number of resources of the kind the auditor applies to
+	Applied int
+	Skipped []Skip
+}
+
+// Skip is a reason an auditor is skipped for resources of a kind
+type Skip struct {
+	Reason  Reason
+	Message string
+	// Resources is the number of resources of the kind the auditor is skipped for with the reason
+	Resources int
+}
+
+// Create reports which of the auditors of kubeaudit apply to the resources of the report with the config. Resources
+// which could not be decoded are left out
+func Create(report *kubeaudit.Report, conf config.KubeauditConfig) *Report {
+	coverage := &Report{}
+	kinds := map[string]*Kind{}
+	for _, result := range report.RawResults() {
+		if result.GetResource() == nil || result.GetResource().Object() == nil {
+			continue
+		}
+		resource := result.GetResource().Object()
+		coverage.Resources++
+
+		kindName := getKind(resource)
+		kind, ok := kinds[kindName]
+		if !ok {
+			kind = &Kind{Kind: kindName}
+			for _, auditorName := range all.AuditorNames {
+				kind.Auditors = append(kind.Auditors, Auditor{Name: auditorName})
+			}
+			kinds[kindName] = kind
+		}
+		kind.Resources++
+
+		for i := range kind.Auditors {
+			kind.Auditors[i].add(check(kind.Auditors[i].Name, resource, conf))
+		}
+	}
+
+	for _, kind := range kinds {
+		coverage.Kinds = append(coverage.Kinds, *kind)
+	}
+	sort.Slice(coverage.Kinds, func(i, j int) bool { return coverage.Kinds[i].Kind < coverage.Kinds[j].Kind })
+	return coverage
+}
+
+// add counts a resource the auditor applies to, or is skipped for
+func (a *Auditor) add(skip *Skip) {
+	if skip == nil {
+		a.Applied++
+		return
+	}
+	for i := range a.Skipped {
+		if a.Skipped[i].Reason == skip.Reason && a.Skipped[i].Message == skip.Message {
+			a.Skipped[i].Resources++
+			return
+		}
+	}
+	skip.Resources = 1
+	a.Skipped = append(a.Skipped, *skip)
+}
+
+// Write writes the auditors which apply to each kind, followed by the auditors which are skipped with the reasons
+func (r *Report) Write(w io.Writer, useColor bool) error {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "%s of %s\n", plural(r.Resources, "resource"), plural(len(r.Kinds), "kind"))
+
+	applied, skipped := "applied", "skipped"
+	if useColor {
+		applied, skipped = color.Green(applied), color.Yellow(skipped)
+	}
+
+	width := 0
+	for _, auditorName := range all.AuditorNames {
+		if len(auditorName) > width {
+			width = len(auditorName)
+		}
+	}
+
+	for _, kind := range r.Kinds {
+		fmt.Fprintf(&out, "\n%s (%s)\n", kind.Kind, plural(kind.Resources, "resource"))
+
+		var appliedNames []string
+		for _, auditor := range kind.Auditors {
+			switch {
+			case auditor.Applied == kind.Resources:
+				appliedNames = append(appliedNames, auditor.Name)
+			case auditor.Applied > 0:
+				appliedNames = append(appliedNames, fmt.Sprintf("%s (%d of %d)", auditor.Name, auditor.Applied, kind.Resources))
+			}
+		}
+		if len(appliedNames) > 0 {
+			fmt.Fprintf(&out, "  %s  %s\n", applied, strings.Join(appliedNames, ", "))
+		}
+
+		label := skipped
+		for _, auditor := range kind.Auditors {
+			for _, skip := range auditor.Skipped {
+				fmt.Fprintf(&out, "  %s  %-*s  %s: %s", label, width, auditor.Name, skip.Reason, skip.Message)
+				if skip.Resources < kind.Resources {
+					fmt.Fprintf(&out, " (%d of %d)", skip.Resources, kind.Resources)
+				}
+				fmt.Fprintln(&out)
+				label = strings.Repeat(" ", len("skipped"))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func plural(count int, noun string) string {
+	if count == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", count, noun)
+}
+
+// getKind returns the kind of the resource, with its group for custom resources
+func getKind(resource k8s.Resource) string {
+	gvk := resource.GetObjectKind().GroupVersionKind()
+	if gvk.Kind == "" {
+		return "Unknown"
+	}
+	if _, ok := resource.(*k8s.CustomResource); ok {
+		return gvk.GroupKind().String()
+	}
+	return gvk.Kind
+}
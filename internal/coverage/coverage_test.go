@@ -0,0 +1,133 @@
+package coverage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/annotations"
+	"github.com/Shopify/kubeaudit/auditors/apparmor"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
+	"github.com/Shopify/kubeaudit/config"
+)
+
+const manifest = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: payments
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: linux
+  namespace: payments
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: windows
+  namespace: payments
+spec:
+  os:
+    name: windows
+  containers:
+    - name: container
+      image: scratch:1.0
+`
+
+func auditManifest(t *testing.T) *kubeaudit.Report {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+	report, err := auditor.AuditManifest("", strings.NewReader(manifest))
+	require.NoError(t, err)
+	return report
+}
+
+func getAuditor(t *testing.T, report *Report, kind, auditorName string) Auditor {
+	for _, k := range report.Kinds {
+		if k.Kind != kind {
+			continue
+		}
+		for _, auditor := range k.Auditors {
+			if auditor.Name == auditorName {
+				return auditor
+			}
+		}
+	}
+	require.Failf(t, "auditor not found", "%s for %s", auditorName, kind)
+	return Auditor{}
+}
+
+func TestCreate(t *testing.T) {
+	conf := config.KubeauditConfig{
+		EnabledAuditors: map[string]bool{privileged.Name: false},
+	}
+	report := Create(auditManifest(t), conf)
+
+	assert.Equal(t, 3, report.Resources)
+	require.Len(t, report.Kinds, 2)
+	assert.Equal(t, "Namespace", report.Kinds[0].Kind)
+	assert.Equal(t, "Pod", report.Kinds[1].Kind)
+	assert.Equal(t, 2, report.Kinds[1].Resources)
+
+	cases := []struct {
+		kind     string
+		auditor  string
+		expected Auditor
+	}{
+		{"Namespace", netpols.Name, Auditor{Name: netpols.Name, Applied: 1}},
+		{"Pod", netpols.Name, Auditor{Name: netpols.Name, Skipped: []Skip{
+			{Reason: UnsupportedKind, Message: "only audits namespaces", Resources: 2},
+		}}},
+		{"Pod", apparmor.Name, Auditor{Name: apparmor.Name, Applied: 1, Skipped: []Skip{
+			{Reason: OSMismatch, Message: "only checks Linux settings, and the pod runs on Windows", Resources: 1},
+		}}},
+		{"Pod", privileged.Name, Auditor{Name: privileged.Name, Skipped: []Skip{
+			{Reason: Config, Message: "disabled in enabledAuditors", Resources: 2},
+		}}},
+		{"Pod", requests.Name, Auditor{Name: requests.Name, Skipped: []Skip{
+			{Reason: Config, Message: "optional auditor, not enabled in enabledAuditors", Resources: 2},
+		}}},
+		{"Pod", annotations.Name, Auditor{Name: annotations.Name, Skipped: []Skip{
+			{Reason: Config, Message: "no required or forbidden annotations are configured", Resources: 2},
+		}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.kind+"/"+tc.auditor, func(t *testing.T) {
+			assert.Equal(t, tc.expected, getAuditor(t, report, tc.kind, tc.auditor))
+		})
+	}
+}
+
+func TestCreateEnabled(t *testing.T) {
+	conf := config.KubeauditConfig{
+		EnabledAuditors: map[string]bool{resilience.Name: true},
+	}
+	report := Create(auditManifest(t), conf)
+
+	assert.Equal(t, Auditor{Name: resilience.Name, Skipped: []Skip{
+		{Reason: UnsupportedKind, Message: "only audits Deployments and StatefulSets", Resources: 2},
+	}}, getAuditor(t, report, "Pod", resilience.Name))
+}
+
+func TestWrite(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Create(auditManifest(t), config.KubeauditConfig{}).Write(&out, false))
+
+	assert.True(t, strings.HasPrefix(out.String(), "3 resources of 2 kinds\n\nNamespace (1 resource)\n  applied  deprecatedapis, netpols, secrets\n"))
+	assert.Contains(t, out.String(), "\nPod (2 resources)\n  applied  apparmor (1 of 2), asat, capabilities (1 of 2),")
+	assert.Contains(t, out.String(), "\n           apparmor        os mismatch: only checks Linux settings, and the pod runs on Windows (1 of 2)\n")
+	assert.Contains(t, out.String(), "\n           netpols         unsupported kind: only audits namespaces\n")
+}
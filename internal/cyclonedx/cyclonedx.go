@@ -0,0 +1,379 @@
+// Package cyclonedx writes the inventory of the resources of an audit as a CycloneDX bill of materials: the audited
+// resources, the images and service accounts of the workloads, and the findings of each resource as properties
+package cyclonedx
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/redact"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// SpecVersion is the version of the CycloneDX specification of the documents
+const SpecVersion = "1.5"
+
+// propertyPrefix namespaces the properties of the components, following the CycloneDX property taxonomy
+const propertyPrefix = "kubeaudit:"
+
+// BOM is a CycloneDX bill of materials
+type BOM struct {
+	BOMFormat    string       `json:"bomFormat"`
+	SpecVersion  string       `json:"specVersion"`
+	SerialNumber string       `json:"serialNumber"`
+	Version      int          `json:"version"`
+	Metadata     Metadata     `json:"metadata"`
+	Components   []Component  `json:"components"`
+	Dependencies []Dependency `json:"dependencies,omitempty"`
+}
+
+// Metadata is the time the BOM was created at and the tool which created it
+type Metadata struct {
+	Timestamp string `json:"timestamp"`
+	Tools     Tools  `json:"tools"`
+}
+
+type Tools struct {
+	Components []Component `json:"components"`
+}
+
+// Component is an audited resource, an image or a service account
+type Component struct {
+	BOMRef     string     `json:"bom-ref,omitempty"`
+	Type       string     `json:"type"`
+	Group      string     `json:"group,omitempty"`
+	Name       string     `json:"name"`
+	Version    string     `json:"version,omitempty"`
+	Hashes     []Hash     `json:"hashes,omitempty"`
+	PURL       string     `json:"purl,omitempty"`
+	Properties []Property `json:"properties,omitempty"`
+}
+
+type Hash struct {
+	Algorithm string `json:"alg"`
+	Content   string `json:"content"`
+}
+
+type Property struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Dependency lists the images and service account a workload depends on
+type Dependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+// Options are the options of Create
+type Options struct {
+	// MinSeverity is the lowest severity of the findings added to the components
+	MinSeverity kubeaudit.SeverityLevel
+	// ToolVersion is the version of kubeaudit recorded in the metadata
+	ToolVersion string
+	// Timestamp is the creation time of the BOM. Defaults to now
+	Timestamp time.Time
+	// SerialNumber identifies the BOM, as a URN of a UUID. Defaults to a random UUID
+	SerialNumber string
+}
+
+// Create returns the BOM of the resources of the report. Every audited resource is a component, whether or not it has
+// findings. Workloads are application components which depend on the container components of their images and on the
+// data component of their service account
+func Create(report *kubeaudit.Report, options Options) (*BOM, error) {
+	if options.Timestamp.IsZero() {
+		options.Timestamp = time.Now()
+	}
+	if options.SerialNumber == "" {
+		serialNumber, err := newSerialNumber()
+		if err != nil {
+			return nil, err
+		}
+		options.SerialNumber = serialNumber
+	}
+
+	b := &builder{components: map[string]*Component{}, dependencies: map[string][]string{}}
+	var resources []k8s.Resource
+	for _, result := range report.RawResults() {
+		if result.GetResource() == nil || result.GetResource().Object() == nil {
+			continue
+		}
+		resources = append(resources, result.GetResource().Object())
+		b.addResource(result.GetResource().Object(), result.GetAuditResults(), options.MinSeverity)
+	}
+	// The dependencies are added once every resource is, so audited service accounts aren't added again
+	for _, resource := range resources {
+		b.addDependencies(resource)
+	}
+
+	return &BOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  SpecVersion,
+		SerialNumber: options.SerialNumber,
+		Version:      1,
+		Metadata: Metadata{
+			Timestamp: options.Timestamp.UTC().Format(time.RFC3339),
+			Tools: Tools{Components: []Component{
+				{Type: "application", Group: "Shopify", Name: "kubeaudit", Version: options.ToolVersion},
+			}},
+		},
+		Components:   b.sortedComponents(),
+		Dependencies: b.sortedDependencies(),
+	}, nil
+}
+
+// Write writes the BOM of the resources of the report as indented JSON
+func Write(w io.Writer, report *kubeaudit.Report, options Options) error {
+	bom, err := Create(report, options)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(bom)
+}
+
+type builder struct {
+	components   map[string]*Component
+	dependencies map[string][]string
+}
+
+func (b *builder) addResource(resource k8s.Resource, auditResults []*kubeaudit.AuditResult, minSeverity kubeaudit.SeverityLevel) {
+	gvk := resource.GetObjectKind().GroupVersionKind()
+	namespace, name := getNamespaceAndName(resource)
+
+	componentType := "data"
+	if k8s.GetPodSpec(resource) != nil {
+		componentType = "application"
+	}
+
+	ref := resourceRef(gvk.Kind, namespace, name)
+	component, ok := b.components[ref]
+	if !ok {
+		component = &Component{BOMRef: ref, Type: componentType, Group: namespace, Name: name}
+		component.Properties = append(component.Properties, property("kind", gvk.Kind), property("apiVersion", gvk.GroupVersion().String()))
+		if namespace != "" {
+			component.Properties = append(component.Properties, property("namespace", namespace))
+		}
+		b.components[ref] = component
+	}
+
+	for _, auditResult := range auditResults {
+		if !auditResult.Severity.AtLeast(minSeverity) {
+			continue
+		}
+		component.Properties = append(component.Properties, Property{
+			Name:  propertyPrefix + "finding:" + auditResult.Rule,
+			Value: fmt.Sprintf("%s: %s", auditResult.Severity, redact.String(auditResult.Message)),
+		})
+	}
+}
+
+// addDependencies adds the images and service account of a workload, and its dependencies on them
+func (b *builder) addDependencies(resource k8s.Resource) {
+	podSpec := k8s.GetPodSpec(resource)
+	if podSpec == nil {
+		return
+	}
+	namespace, name := getNamespaceAndName(resource)
+	ref := resourceRef(resource.GetObjectKind().GroupVersionKind().Kind, namespace, name)
+
+	digests := getImageDigests(resource)
+	var dependsOn []string
+	for _, container := range podContainers(resource) {
+		imageRef := b.addImage(container.Image, digests[container.Name])
+		if imageRef != "" {
+			dependsOn = append(dependsOn, imageRef)
+		}
+	}
+	dependsOn = append(dependsOn, b.addServiceAccount(namespace, getServiceAccountName(podSpec)))
+	b.dependencies[ref] = appendUnique(b.dependencies[ref], dependsOn...)
+}
+
+// addImage adds the container component of an image and returns its reference. The digest of the image is taken from
+// the image reference, or from the status of the pod if the reference has a tag
+func (b *builder) addImage(image, statusDigest string) string {
+	if image == "" {
+		return ""
+	}
+	repository, tag, digest := parseImage(image)
+	if digest == "" {
+		digest = statusDigest
+	}
+
+	ref := repository
+	if tag != "" {
+		ref += ":" + tag
+	}
+	if digest != "" {
+		ref += "@" + digest
+	}
+	if _, ok := b.components[ref]; ok {
+		return ref
+	}
+
+	component := &Component{BOMRef: ref, Type: "container", Name: repository, Version: tag}
+	if algorithm, content, ok := strings.Cut(digest, ":"); ok && algorithm == "sha256" {
+		component.Hashes = []Hash{{Algorithm: "SHA-256", Content: content}}
+		component.PURL = imagePURL(repository, tag, digest)
+	}
+	b.components[ref] = component
+	return ref
+}
+
+// addServiceAccount adds the data component of a service account unless it was audited, and returns its reference.
+// Service accounts which were not audited, such as the default service account, have no findings
+func (b *builder) addServiceAccount(namespace, name string) string {
+	ref := resourceRef("ServiceAccount", namespace, name)
+	if _, ok := b.components[ref]; !ok {
+		component := &Component{BOMRef: ref, Type: "data", Group: namespace, Name: name, Properties: []Property{property("kind", "ServiceAccount")}}
+		if namespace != "" {
+			component.Properties = append(component.Properties, property("namespace", namespace))
+		}
+		b.components[ref] = component
+	}
+	return ref
+}
+
+func (b *builder) sortedComponents() []Component {
+	refs := make([]string, 0, len(b.components))
+	for ref := range b.components {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	components := make([]Component, 0, len(refs))
+	for _, ref := range refs {
+		components = append(components, *b.components[ref])
+	}
+	return components
+}
+
+func (b *builder) sortedDependencies() []Dependency {
+	refs := make([]string, 0, len(b.dependencies))
+	for ref := range b.dependencies {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	dependencies := make([]Dependency, 0, len(refs))
+	for _, ref := range refs {
+		dependencies = append(dependencies, Dependency{Ref: ref, DependsOn: b.dependencies[ref]})
+	}
+	return dependencies
+}
+
+func resourceRef(kind, namespace, name string) string {
+	if namespace == "" {
+		return fmt.Sprintf("k8s:%s/%s", kind, name)
+	}
+	return fmt.Sprintf("k8s:%s/%s/%s", kind, namespace, name)
+}
+
+func getNamespaceAndName(resource k8s.Resource) (namespace, name string) {
+	if objectMeta := k8s.GetObjectMeta(resource); objectMeta != nil {
+		return objectMeta.GetNamespace(), objectMeta.GetName()
+	}
+	return "", ""
+}
+
+func property(name, value string) Property {
+	return Property{Name: propertyPrefix + name, Value: value}
+}
+
+func podContainers(resource k8s.Resource) []*k8s.ContainerV1 {
+	containers := k8s.GetInitContainers(resource)
+	containers = append(containers, k8s.GetContainers(resource)...)
+	return append(containers, k8s.GetEphemeralContainers(resource)...)
+}
+
+// getServiceAccountName returns the service account of the pod, which is the default service account of the namespace
+// if the pod doesn't set one
+func getServiceAccountName(podSpec *k8s.PodSpecV1) string {
+	switch {
+	case podSpec.ServiceAccountName != "":
+		return podSpec.ServiceAccountName
+	case podSpec.DeprecatedServiceAccount != "":
+		return podSpec.DeprecatedServiceAccount
+	}
+	return "default"
+}
+
+// getImageDigests returns the digests of the images the containers of a pod run, by container name, from the status
+// of the pod. Only pods audited in cluster or local mode have a status
+func getImageDigests(resource k8s.Resource) map[string]string {
+	pod, ok := resource.(*k8s.PodV1)
+	if !ok {
+		return nil
+	}
+
+	digests := map[string]string{}
+	statuses := append(append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...), pod.Status.EphemeralContainerStatuses...)
+	for _, status := range statuses {
+		imageID := status.ImageID
+		if i := strings.LastIndex(imageID, "@"); i >= 0 {
+			imageID = imageID[i+1:]
+		}
+		if strings.HasPrefix(imageID, "sha256:") {
+			digests[status.Name] = imageID
+		}
+	}
+	return digests
+}
+
+// parseImage splits an image reference into its repository, tag and digest
+func parseImage(image string) (repository, tag, digest string) {
+	repository = image
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository, digest = repository[:i], repository[i+1:]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	return repository, tag, digest
+}
+
+// imagePURL returns the package URL of an image with a digest, as defined by the oci purl type
+func imagePURL(repository, tag, digest string) string {
+	name := repository[strings.LastIndex(repository, "/")+1:]
+	query := url.Values{"repository_url": {repository}}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	return fmt.Sprintf("pkg:oci/%s@%s?%s", strings.ToLower(name), url.QueryEscape(digest), query.Encode())
+}
+
+// newSerialNumber returns a random version 4 UUID as a URN
+func newSerialNumber() (string, error) {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return "", err
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", uuid[0:4], uuid[4:6], uuid[6:8], uuid[8:10], uuid[10:]), nil
+}
+
+func appendUnique(values []string, more ...string) []string {
+	for _, value := range more {
+		found := false
+		for _, existing := range values {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,128 @@
+package cyclonedx
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/test"
+)
+
+var options = Options{
+	MinSeverity:  kubeaudit.Info,
+	ToolVersion:  "1.0.0",
+	Timestamp:    time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+	SerialNumber: "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79",
+}
+
+func TestCreate(t *testing.T) {
+	bom, err := Create(getReport(t), options)
+	require.NoError(t, err)
+
+	assert.Equal(t, "CycloneDX", bom.BOMFormat)
+	assert.Equal(t, SpecVersion, bom.SpecVersion)
+	assert.Equal(t, options.SerialNumber, bom.SerialNumber)
+	assert.Equal(t, "2024-06-01T12:00:00Z", bom.Metadata.Timestamp)
+	assert.Equal(t, "1.0.0", bom.Metadata.Tools.Components[0].Version)
+
+	components := map[string]Component{}
+	var refs []string
+	for _, component := range bom.Components {
+		components[component.BOMRef] = component
+		refs = append(refs, component.BOMRef)
+	}
+	assert.Equal(t, []string{
+		"busybox:1.36@sha256:9ae97d36d26566ff84e8893c64a6dc4fe8ca6d1144bf5b87b2b85a32def253c7",
+		"envoyproxy/envoy@sha256:2d05a4c5c9e4f8d8f4f5e0a1c3d1b1c2e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5",
+		"k8s:Deployment/cyclonedx/web",
+		"k8s:Pod/cyclonedx/job",
+		"k8s:ServiceAccount/cyclonedx/default",
+		"k8s:ServiceAccount/cyclonedx/web",
+		"registry.example.com/team/web:1.2.0",
+	}, refs)
+
+	// Workloads are applications with their findings as properties
+	deployment := components["k8s:Deployment/cyclonedx/web"]
+	assert.Equal(t, "application", deployment.Type)
+	assert.Equal(t, "cyclonedx", deployment.Group)
+	assert.Contains(t, deployment.Properties, Property{Name: "kubeaudit:kind", Value: "Deployment"})
+	assert.Contains(t, deployment.Properties, Property{Name: "kubeaudit:finding:PrivilegedTrue", Value: "error: privileged is set to 'true' in container SecurityContext. It should be set to 'false'."})
+	assert.Equal(t, "data", components["k8s:ServiceAccount/cyclonedx/web"].Type)
+
+	// The digest of an image comes from its reference, or from the status of the pod
+	envoy := components["envoyproxy/envoy@sha256:2d05a4c5c9e4f8d8f4f5e0a1c3d1b1c2e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5"]
+	assert.Equal(t, "container", envoy.Type)
+	assert.Equal(t, "envoyproxy/envoy", envoy.Name)
+	assert.Equal(t, []Hash{{Algorithm: "SHA-256", Content: "2d05a4c5c9e4f8d8f4f5e0a1c3d1b1c2e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5"}}, envoy.Hashes)
+	assert.Equal(t, "pkg:oci/envoy@sha256%3A2d05a4c5c9e4f8d8f4f5e0a1c3d1b1c2e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5?repository_url=envoyproxy%2Fenvoy", envoy.PURL)
+	busybox := components["busybox:1.36@sha256:9ae97d36d26566ff84e8893c64a6dc4fe8ca6d1144bf5b87b2b85a32def253c7"]
+	assert.Equal(t, "1.36", busybox.Version)
+	assert.NotEmpty(t, busybox.Hashes)
+	web := components["registry.example.com/team/web:1.2.0"]
+	assert.Equal(t, "registry.example.com/team/web", web.Name)
+	assert.Empty(t, web.Hashes)
+	assert.Empty(t, web.PURL)
+
+	assert.Equal(t, []Dependency{
+		{Ref: "k8s:Deployment/cyclonedx/web", DependsOn: []string{
+			"registry.example.com/team/web:1.2.0",
+			"envoyproxy/envoy@sha256:2d05a4c5c9e4f8d8f4f5e0a1c3d1b1c2e0f9a8b7c6d5e4f3a2b1c0d9e8f7a6b5",
+			"k8s:ServiceAccount/cyclonedx/web",
+		}},
+		{Ref: "k8s:Pod/cyclonedx/job", DependsOn: []string{
+			"busybox:1.36@sha256:9ae97d36d26566ff84e8893c64a6dc4fe8ca6d1144bf5b87b2b85a32def253c7",
+			"k8s:ServiceAccount/cyclonedx/default",
+		}},
+	}, bom.Dependencies)
+}
+
+func TestCreateMinSeverity(t *testing.T) {
+	minSeverityOptions := options
+	minSeverityOptions.MinSeverity = kubeaudit.Error + 1
+	bom, err := Create(getReport(t), minSeverityOptions)
+	require.NoError(t, err)
+
+	// Resources are in the inventory even without findings
+	for _, component := range bom.Components {
+		for _, property := range component.Properties {
+			assert.NotContains(t, property.Name, "finding", component.BOMRef)
+		}
+	}
+	assert.Len(t, bom.Components, 7)
+}
+
+func TestWrite(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, Write(&buf, getReport(t), Options{}))
+
+	var bom BOM
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &bom))
+	assert.Regexp(t, `^urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, bom.SerialNumber)
+	assert.NotEmpty(t, bom.Metadata.Timestamp)
+}
+
+func TestParseImage(t *testing.T) {
+	cases := []struct {
+		image, repository, tag, digest string
+	}{
+		{"nginx", "nginx", "", ""},
+		{"nginx:1.25", "nginx", "1.25", ""},
+		{"localhost:5000/nginx", "localhost:5000/nginx", "", ""},
+		{"localhost:5000/nginx:1.25@sha256:abc", "localhost:5000/nginx", "1.25", "sha256:abc"},
+	}
+
+	for _, tc := range cases {
+		repository, tag, digest := parseImage(tc.image)
+		assert.Equal(t, []string{tc.repository, tc.tag, tc.digest}, []string{repository, tag, digest}, tc.image)
+	}
+}
+
+func getReport(t *testing.T) *kubeaudit.Report {
+	return test.GetReport(t, "fixtures", "inventory.yml", []kubeaudit.Auditable{privileged.New()}, "", test.MANIFEST_MODE)
+}
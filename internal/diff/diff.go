@@ -0,0 +1,64 @@
+// Package diff creates unified diffs of the changes made by autofix
+package diff
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// contextLines is the number of unchanged lines shown around each change, as in `diff -u`
+const contextLines = 3
+
+// Unified returns the unified diff from a to b, or an empty string if they are equal
+func Unified(a, b []byte, fromFile, toFile string) (string, error) {
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(a)),
+		B:        splitLines(string(b)),
+		FromFile: fromFile,
+		ToFile:   toFile,
+		Context:  contextLines,
+	})
+}
+
+// splitLines splits s into lines which all end with a newline, as expected by difflib
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// ResourceYAML encodes the resource as YAML without the fields which are changed by the API server on every apply, so
+// the diff only shows the changes made by the fixes. A nil resource is encoded as an empty document
+func ResourceYAML(resource k8s.Resource) ([]byte, error) {
+	if resource == nil {
+		return nil, nil
+	}
+
+	content, err := k8sinternal.ToUnstructured(resource)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range [][]string{{"metadata", "managedFields"}, {"metadata", "resourceVersion"}, {"metadata", "generation"}, {"status"}} {
+		unstructured.RemoveNestedField(content.Object, field...)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(content.Object); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,21 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnified(t *testing.T) {
+	a := []byte("apiVersion: v1\nkind: Pod\nspec:\n  hostNetwork: true\n")
+	b := []byte("apiVersion: v1\nkind: Pod\nspec:\n  hostNetwork: false\n")
+
+	unified, err := Unified(a, b, "before", "after")
+	require.NoError(t, err)
+	assert.Equal(t, "--- before\n+++ after\n@@ -1,4 +1,4 @@\n apiVersion: v1\n kind: Pod\n spec:\n-  hostNetwork: true\n+  hostNetwork: false\n", unified)
+
+	unified, err = Unified(a, a, "before", "after")
+	require.NoError(t, err)
+	assert.Empty(t, unified)
+}
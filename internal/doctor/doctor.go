@@ -0,0 +1,311 @@
+// Package doctor diagnoses the environment kubeaudit runs in, so that problems with the kubeconfig, the API server,
+// permissions or the kubeaudit config are reported with a fix instead of as missing results
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilversion "k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/plugin"
+)
+
+// Status is the outcome of a check
+type Status int
+
+const (
+	// OK means nothing needs to be done
+	OK Status = iota
+	// Warning means kubeaudit works, but may not report everything or behave as configured
+	Warning
+	// Failure means kubeaudit can't audit the cluster, or can't run an auditor
+	Failure
+)
+
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "ok"
+	case Warning:
+		return "warning"
+	}
+	return "error"
+}
+
+// Check is the result of diagnosing one part of the environment
+type Check struct {
+	Name    string
+	Status  Status
+	Message string
+	// Fix is the action which resolves a warning or a failure
+	Fix string
+}
+
+// CheckConfig checks that the kubeaudit config file can be loaded, which rejects unknown fields and values, that it
+// only enables known auditors, and that the auditors accept their configuration. The loaded config is returned unless the check fails. An empty path
+// is the default config
+func CheckConfig(path string) (Check, *config.KubeauditConfig) {
+	check := Check{Name: "kubeaudit config"}
+	if path == "" {
+		check.Message = "No kubeaudit config set with -k/--kconfig, the default auditors and configuration are used"
+		return check, &config.KubeauditConfig{}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		check.Status = Failure
+		check.Message = fmt.Sprintf("Can't read %s: %s", path, err)
+		check.Fix = "Pass the path of an existing kubeaudit config file with -k/--kconfig"
+		return check, nil
+	}
+
+	conf, err := config.New(bytes.NewReader(data))
+	if err != nil {
+		check.Status = Failure
+		check.Message = fmt.Sprintf("%s is not a valid kubeaudit config: %s", path, err)
+		check.Fix = "Fix the YAML syntax, field names and values of the config, see the format in the Configuration File section of the README or validate it with kubeaudit config validate"
+		return check, nil
+	}
+
+	if err := all.CheckEnabledAuditors(conf, plugin.Discover()); err != nil {
+		check.Status = Failure
+		check.Message = fmt.Sprintf("%s enables unknown auditors: %s", path, err)
+		check.Fix = "Fix the names of the auditors, see the auditors listed in the README and the plugins in the PATH"
+		return check, nil
+	}
+
+	if _, err := all.Auditors(conf); err != nil {
+		check.Status = Failure
+		check.Message = fmt.Sprintf("%s has an invalid auditor configuration: %s", path, err)
+		check.Fix = "Fix the value in the config, see the docs of the auditor for the values it accepts"
+		return check, nil
+	}
+
+	check.Message = fmt.Sprintf("%s is valid", path)
+	return check, &conf
+}
+
+// CheckKubeconfig checks that the kubeconfig file for local mode can be loaded and has a usable context, and returns
+// the client config of the context unless the check fails. An empty path uses the files listed in $KUBECONFIG or
+// $HOME/.kube/config, "-" reads the kubeconfig from stdin, and an empty context uses the current context
+func CheckKubeconfig(path, context string) (Check, *rest.Config) {
+	check := Check{Name: "kubeconfig", Status: Failure}
+
+	clientConfig, err := k8sinternal.ClientConfig(path, context)
+	if errors.Is(err, k8sinternal.ErrNoReadableKubeConfig) {
+		check.Message = fmt.Sprintf("Can't read kubeconfig file %s", path)
+		check.Fix = "Pass the path of an existing kubeconfig file with --kubeconfig, or leave it out to use $KUBECONFIG or $HOME/.kube/config"
+		return check, nil
+	}
+	if err != nil {
+		check.Message = fmt.Sprintf("The kubeconfig is invalid: %s", err)
+		check.Fix = "Check the kubeconfig with 'kubectl config view'"
+		return check, nil
+	}
+
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		check.Message = fmt.Sprintf("The kubeconfig is invalid: %s", err)
+		check.Fix = "Check the kubeconfig with 'kubectl config view'"
+		return check, nil
+	}
+
+	contextName := rawConfig.CurrentContext
+	if context != "" {
+		contextName = context
+	}
+	if len(rawConfig.Contexts) == 0 {
+		check.Message = "The kubeconfig has no contexts"
+		check.Fix = "Pass the kubeconfig of the cluster to audit with --kubeconfig, or add the cluster with the login command of your cloud provider"
+		return check, nil
+	}
+	if contextName == "" {
+		check.Message = "No context set, and the kubeconfig has no current context"
+		check.Fix = fmt.Sprintf("Select a context with -c/--context or 'kubectl config use-context', one of: %s", contextNames(rawConfig.Contexts))
+		return check, nil
+	}
+	kubeContext, ok := rawConfig.Contexts[contextName]
+	if !ok {
+		check.Message = fmt.Sprintf("Context %q is not in the kubeconfig", contextName)
+		check.Fix = fmt.Sprintf("Select one of the contexts with -c/--context: %s", contextNames(rawConfig.Contexts))
+		return check, nil
+	}
+
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		check.Message = fmt.Sprintf("Context %q can't be used: %s", contextName, err)
+		check.Fix = fmt.Sprintf("Check the cluster and user of context %q with 'kubectl config view --minify --context %s'", contextName, contextName)
+		return check, nil
+	}
+
+	check.Status = OK
+	check.Message = fmt.Sprintf("Using context %q of cluster %q (%s)", contextName, kubeContext.Cluster, restConfig.Host)
+	return check, restConfig
+}
+
+func contextNames(contexts map[string]*clientcmdapi.Context) string {
+	names := make([]string, 0, len(contexts))
+	for name := range contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// CheckAPIServer checks that the API server can be reached with the credentials of the client, and returns the
+// version of the server unless the check fails
+func CheckAPIServer(client discovery.ServerVersionInterface, host string) (Check, *version.Info) {
+	check := Check{Name: "API server", Status: Failure}
+
+	serverVersion, err := client.ServerVersion()
+	switch {
+	case apierrors.IsUnauthorized(err):
+		check.Message = fmt.Sprintf("The API server at %s rejected the credentials: %s", host, err)
+		check.Fix = "Refresh the credentials of the context, eg. by logging in to the cluster again, and check them with 'kubectl auth can-i list pods'"
+		return check, nil
+	case apierrors.IsForbidden(err):
+		check.Message = fmt.Sprintf("The API server at %s doesn't allow getting its version: %s", host, err)
+		check.Fix = "Grant the system:discovery ClusterRole to the user or service account kubeaudit runs as"
+		return check, nil
+	case err != nil:
+		check.Message = fmt.Sprintf("Can't reach the API server at %s: %s", host, err)
+		check.Fix = "Check that the API server address is right and reachable from here (VPN, proxy, firewall), eg. with 'kubectl version'"
+		return check, nil
+	}
+
+	check.Status = OK
+	check.Message = fmt.Sprintf("Reached the API server at %s, running Kubernetes %s", host, serverVersion.GitVersion)
+	return check, serverVersion
+}
+
+// CheckVersionSkew checks that the Kubernetes version of the server is within one minor version of the Kubernetes
+// client libraries kubeaudit is built with (eg. "v0.24.3" for Kubernetes 1.24), which is the skew Kubernetes supports
+func CheckVersionSkew(serverVersion *version.Info, clientVersion string) Check {
+	check := Check{Name: "version skew", Status: Warning}
+
+	server, err := utilversion.ParseGeneric(serverVersion.GitVersion)
+	if err != nil {
+		check.Message = fmt.Sprintf("Can't parse the server version %q: %s", serverVersion.GitVersion, err)
+		return check
+	}
+	client, err := utilversion.ParseGeneric(clientVersion)
+	if err != nil {
+		check.Message = fmt.Sprintf("Can't determine the Kubernetes version kubeaudit is built for from %q", clientVersion)
+		return check
+	}
+
+	skew := int(server.Minor()) - int(client.Minor())
+	clientRelease := fmt.Sprintf("1.%d", client.Minor())
+	switch {
+	case skew > 1:
+		check.Message = fmt.Sprintf("The server runs Kubernetes %s, which is %d minor versions newer than the Kubernetes %s kubeaudit is built for. Fields and resource types added since %s are not audited", serverVersion.GitVersion, skew, clientRelease, clientRelease)
+		check.Fix = "Upgrade kubeaudit to a release built for a newer Kubernetes version"
+	case skew < -1:
+		check.Message = fmt.Sprintf("The server runs Kubernetes %s, which is %d minor versions older than the Kubernetes %s kubeaudit is built for", serverVersion.GitVersion, -skew, clientRelease)
+		check.Fix = "Upgrade the cluster, or use a kubeaudit release built for an older Kubernetes version"
+	default:
+		check.Status = OK
+		check.Message = fmt.Sprintf("The server runs Kubernetes %s and kubeaudit is built for Kubernetes %s", serverVersion.GitVersion, clientRelease)
+	}
+	return check
+}
+
+// CheckPermissions checks, for each auditor, that the client can list the resources the auditor audits in the
+// namespaces, or in all namespaces if none are set. Resources which can't be listed are silently left out of audits,
+// so missing permissions otherwise show up as missing results
+func CheckPermissions(reviews authorizationv1client.SelfSubjectAccessReviewInterface, auditorNames []string, namespaces []string) []Check {
+	type review struct {
+		resource  resource
+		namespace string
+	}
+	reviewed := map[review]bool{}
+	canList := func(r review) (bool, error) {
+		if allowed, ok := reviewed[r]; ok {
+			return allowed, nil
+		}
+		response, err := reviews.Create(context.Background(), &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: r.namespace,
+					Verb:      "list",
+					Group:     r.resource.group,
+					Resource:  r.resource.name,
+				},
+			},
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return false, err
+		}
+		reviewed[r] = response.Status.Allowed
+		return response.Status.Allowed, nil
+	}
+
+	var checks []Check
+	for _, auditorName := range auditorNames {
+		check := Check{Name: "permissions: " + auditorName}
+
+		var missing, missingResources []string
+		var reviewErr error
+		for _, r := range auditorResources(auditorName) {
+			scopes := []string{""}
+			if r.namespaced && len(namespaces) > 0 {
+				scopes = namespaces
+			}
+			for _, namespace := range scopes {
+				allowed, err := canList(review{resource: r, namespace: namespace})
+				if err != nil {
+					reviewErr = err
+					break
+				}
+				if !allowed {
+					missing = append(missing, r.describe(namespace))
+					missingResources = appendUnique(missingResources, r.String())
+				}
+			}
+			if reviewErr != nil {
+				break
+			}
+		}
+
+		switch {
+		case reviewErr != nil:
+			check.Status = Warning
+			check.Message = fmt.Sprintf("Can't check the permissions: %s", reviewErr)
+			check.Fix = "Grant the create verb on selfsubjectaccessreviews.authorization.k8s.io, which the system:basic-user ClusterRole includes"
+		case len(missing) > 0:
+			check.Status = Failure
+			check.Message = fmt.Sprintf("Can't %s, so they are not audited", strings.Join(missing, ", "))
+			check.Fix = fmt.Sprintf("Grant the list verb on %s to the user or service account kubeaudit runs as, eg. in the kubeaudit ClusterRole (see docs/cluster.md)", strings.Join(missingResources, ", "))
+		default:
+			check.Message = "Can list all the resources the auditor audits"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
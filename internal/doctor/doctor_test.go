@@ -0,0 +1,201 @@
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func writeFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(data), 0600))
+	return path
+}
+
+func TestCheckConfig(t *testing.T) {
+	cases := []struct {
+		testName       string
+		data           string
+		expectedStatus Status
+		expectedConfig bool
+	}{
+		{"valid", "enabledAuditors:\n  apparmor: false\nauditors:\n  limits:\n    cpu: 750m\n", OK, true},
+		{"unknown field", "enabledAuditors:\n  apparmor: false\nauditor:\n  limits:\n    cpu: 750m\n", Failure, false},
+		{"unknown auditor", "enabledAuditors:\n  apparmr: false\n", Failure, false},
+		{"invalid yaml", "enabledAuditors: [", Failure, false},
+		{"invalid auditor config", "enabledAuditors:\n  pss: true\nauditors:\n  pss:\n    level: strict\n", Failure, false},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+			check, conf := CheckConfig(writeFile(t, "kubeaudit.yaml", tc.data))
+			assert.Equal(t, tc.expectedStatus, check.Status, check.Message)
+			assert.Equal(t, tc.expectedConfig, conf != nil)
+			if tc.expectedStatus != OK {
+				assert.NotEmpty(t, check.Fix)
+			}
+		})
+	}
+}
+
+func TestCheckConfigMissingFile(t *testing.T) {
+	check, conf := CheckConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Equal(t, Failure, check.Status)
+	assert.Nil(t, conf)
+
+	check, conf = CheckConfig("")
+	assert.Equal(t, OK, check.Status)
+	assert.NotNil(t, conf)
+}
+
+const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://dev.example.com
+users:
+- name: dev
+  user:
+    token: secret
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+current-context: %s
+`
+
+func TestCheckKubeconfig(t *testing.T) {
+	cases := []struct {
+		testName       string
+		currentContext string
+		context        string
+		expectedStatus Status
+	}{
+		{"current context", "dev", "", OK},
+		{"context", "", "dev", OK},
+		{"no current context", "", "", Failure},
+		{"unknown context", "dev", "prod", Failure},
+	}
+
+	for _, tc := range cases {
+		// This line is needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+			path := writeFile(t, "kubeconfig", fmt.Sprintf(kubeconfig, tc.currentContext))
+			check, restConfig := CheckKubeconfig(path, tc.context)
+			assert.Equal(t, tc.expectedStatus, check.Status, check.Message)
+			assert.Equal(t, tc.expectedStatus == OK, restConfig != nil)
+			if restConfig != nil {
+				assert.Equal(t, "https://dev.example.com", restConfig.Host)
+			}
+		})
+	}
+}
+
+func TestCheckKubeconfigMissingFile(t *testing.T) {
+	check, restConfig := CheckKubeconfig(filepath.Join(t.TempDir(), "missing"), "")
+	assert.Equal(t, Failure, check.Status)
+	assert.Nil(t, restConfig)
+}
+
+type serverVersion struct {
+	info *version.Info
+	err  error
+}
+
+func (s serverVersion) ServerVersion() (*version.Info, error) {
+	return s.info, s.err
+}
+
+func TestCheckAPIServer(t *testing.T) {
+	cases := []struct {
+		testName       string
+		err            error
+		expectedStatus Status
+	}{
+		{"reachable", nil, OK},
+		{"unauthorized", apierrors.NewUnauthorized("invalid token"), Failure},
+		{"forbidden", apierrors.NewForbidden(authorizationv1.Resource("selfsubjectaccessreviews"), "", errors.New("no access")), Failure},
+		{"unreachable", errors.New("dial tcp: connection refused"), Failure},
+	}
+
+	for _, tc := range cases {
+		check, info := CheckAPIServer(serverVersion{info: &version.Info{GitVersion: "v1.24.3"}, err: tc.err}, "https://dev.example.com")
+		assert.Equal(t, tc.expectedStatus, check.Status, tc.testName)
+		assert.Equal(t, tc.err == nil, info != nil, tc.testName)
+	}
+}
+
+func TestCheckVersionSkew(t *testing.T) {
+	cases := []struct {
+		serverVersion  string
+		clientVersion  string
+		expectedStatus Status
+	}{
+		{"v1.24.3", "v0.24.3", OK},
+		{"v1.25.0-gke.100", "v0.24.3", OK},
+		{"v1.23.9", "v0.24.3", OK},
+		{"v1.27.1", "v0.24.3", Warning},
+		{"v1.21.0", "v0.24.3", Warning},
+		{"v1.24.3", "(devel)", Warning},
+	}
+
+	for _, tc := range cases {
+		check := CheckVersionSkew(&version.Info{GitVersion: tc.serverVersion}, tc.clientVersion)
+		assert.Equal(t, tc.expectedStatus, check.Status, tc.serverVersion+" "+tc.clientVersion)
+	}
+}
+
+func TestCheckPermissions(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	var reviewed int
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviewed++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		// Only network policies in the default namespace can't be listed
+		review.Status.Allowed = attributes.Resource != "networkpolicies" || attributes.Namespace != "default"
+		return true, review, nil
+	})
+
+	checks := CheckPermissions(clientset.AuthorizationV1().SelfSubjectAccessReviews(), []string{"apparmor", "netpols", "privileged"}, []string{"default", "kube-system"})
+	require.Len(t, checks, 3)
+	assert.Equal(t, "permissions: apparmor", checks[0].Name)
+	assert.Equal(t, OK, checks[0].Status)
+	assert.Equal(t, Failure, checks[1].Status)
+	assert.Contains(t, checks[1].Message, "list networkpolicies.networking.k8s.io in namespace default")
+	assert.NotContains(t, checks[1].Message, "kube-system")
+	assert.Contains(t, checks[1].Fix, "networkpolicies.networking.k8s.io")
+	assert.Equal(t, OK, checks[2].Status)
+
+	// Reviews are shared between the auditors: 8 workload types and network policies in 2 namespaces, and namespaces
+	assert.Equal(t, 19, reviewed)
+}
+
+func TestCheckPermissionsReviewError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(authorizationv1.Resource("selfsubjectaccessreviews"), "", errors.New("no access"))
+	})
+
+	checks := CheckPermissions(clientset.AuthorizationV1().SelfSubjectAccessReviews(), []string{"rbac"}, nil)
+	require.Len(t, checks, 1)
+	assert.Equal(t, Warning, checks[0].Status)
+}
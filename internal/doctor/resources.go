@@ -0,0 +1,89 @@
+package doctor
+
+import (
+	"github.com/Shopify/kubeaudit/auditors/asat"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
+	"github.com/Shopify/kubeaudit/auditors/ports"
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
+)
+
+// resource is a resource type an auditor audits, or audits other resources against
+type resource struct {
+	group      string
+	name       string
+	namespaced bool
+}
+
+func (r resource) String() string {
+	if r.group == "" {
+		return r.name
+	}
+	return r.name + "." + r.group
+}
+
+// describe describes listing the resource type in the namespace
+func (r resource) describe(namespace string) string {
+	switch {
+	case !r.namespaced:
+		return "list " + r.String()
+	case namespace == "":
+		return "list " + r.String() + " in all namespaces"
+	}
+	return "list " + r.String() + " in namespace " + namespace
+}
+
+var (
+	pods                   = resource{group: "", name: "pods", namespaced: true}
+	podTemplates           = resource{group: "", name: "podtemplates", namespaced: true}
+	replicationControllers = resource{group: "", name: "replicationcontrollers", namespaced: true}
+	daemonSets             = resource{group: "apps", name: "daemonsets", namespaced: true}
+	deployments            = resource{group: "apps", name: "deployments", namespaced: true}
+	statefulSets           = resource{group: "apps", name: "statefulsets", namespaced: true}
+	jobs                   = resource{group: "batch", name: "jobs", namespaced: true}
+	cronJobs               = resource{group: "batch", name: "cronjobs", namespaced: true}
+	services               = resource{group: "", name: "services", namespaced: true}
+	serviceAccounts        = resource{group: "", name: "serviceaccounts", namespaced: true}
+	networkPolicies        = resource{group: "networking.k8s.io", name: "networkpolicies", namespaced: true}
+	podDisruptionBudgets   = resource{group: "policy", name: "poddisruptionbudgets", namespaced: true}
+	roles                  = resource{group: "rbac.authorization.k8s.io", name: "roles", namespaced: true}
+	namespaces             = resource{group: "", name: "namespaces", namespaced: false}
+	nodes                  = resource{group: "", name: "nodes", namespaced: false}
+	clusterRoles           = resource{group: "rbac.authorization.k8s.io", name: "clusterroles", namespaced: false}
+	roleBindings           = resource{group: "rbac.authorization.k8s.io", name: "rolebindings", namespaced: true}
+	clusterRoleBindings    = resource{group: "rbac.authorization.k8s.io", name: "clusterrolebindings", namespaced: false}
+)
+
+// workloadResources are the resource types with a PodSpec, which most auditors audit
+var workloadResources = []resource{pods, podTemplates, replicationControllers, daemonSets, deployments, statefulSets, jobs, cronJobs}
+
+// auditorResources returns the resource types the auditor needs to list to audit the cluster
+func auditorResources(auditorName string) []resource {
+	switch auditorName {
+	case asat.Name:
+		return withWorkloads(serviceAccounts, namespaces)
+	case egress.Name:
+		return withWorkloads(networkPolicies, namespaces)
+	case ephemeral.Name:
+		return []resource{pods, namespaces}
+	case netpols.Name:
+		return []resource{namespaces, networkPolicies}
+	case nodecoverage.Name:
+		return []resource{nodes, daemonSets}
+	case ports.Name:
+		return withWorkloads(services)
+	case rbac.Name:
+		return withWorkloads(roles, clusterRoles, roleBindings, clusterRoleBindings)
+	case resilience.Name:
+		return []resource{deployments, statefulSets, podDisruptionBudgets, namespaces}
+	}
+	// Workload auditors also read namespaces for namespace-level overrides and labels
+	return withWorkloads(namespaces)
+}
+
+func withWorkloads(resources ...resource) []resource {
+	return append(append([]resource{}, workloadResources...), resources...)
+}
@@ -0,0 +1,155 @@
+// Package exclusions leaves the findings of the resources excluded by the kubeaudit config out of reports, such as the
+// resources of vendored third-party manifests which can't be changed
+package exclusions
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// Exclusions match the resources excluded by kind, name or manifest path
+type Exclusions struct {
+	kinds []string
+	names []*regexp.Regexp
+	paths []string
+}
+
+// Skipped is the number of resources skipped because of an exclusion, such as `kind Job`
+type Skipped struct {
+	Exclusion string
+	Resources int
+}
+
+// New parses the exclusions of the kubeaudit config
+func New(conf config.Exclusions) (*Exclusions, error) {
+	exclusions := &Exclusions{kinds: conf.Kinds}
+	for _, name := range conf.Names {
+		re, err := regexp.Compile("^(?:" + name + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid name exclusion %q: %w", name, err)
+		}
+		exclusions.names = append(exclusions.names, re)
+	}
+	for _, path := range conf.Paths {
+		if _, err := filepath.Match(path, ""); err != nil {
+			return nil, fmt.Errorf("invalid path exclusion %q: %w", path, err)
+		}
+		exclusions.paths = append(exclusions.paths, filepath.Clean(path))
+	}
+	return exclusions, nil
+}
+
+// IsEmpty returns true if no resources are excluded
+func (e *Exclusions) IsEmpty() bool {
+	return e == nil || len(e.kinds)+len(e.names)+len(e.paths) == 0
+}
+
+// Filter returns a report without the findings of the excluded resources, which are counted as suppressed by
+// exclusion, and the number of resources skipped because of each exclusion. Exclusions are matched by kind, then by
+// name and then by path, and a resource matching several exclusions is only counted for the first one
+func (e *Exclusions) Filter(report *kubeaudit.Report) (*kubeaudit.Report, []Skipped) {
+	if e.IsEmpty() {
+		return report, nil
+	}
+
+	counts := map[string]int{}
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			if exclusion, ok := e.match(result.GetResource(), auditResult.FilePath); ok {
+				counts[exclusion]++
+				break
+			}
+		}
+	}
+
+	filtered := report.Suppress(kubeaudit.SuppressedByExclusion, func(result kubeaudit.Result, auditResult *kubeaudit.AuditResult) bool {
+		_, ok := e.match(result.GetResource(), auditResult.FilePath)
+		return ok
+	})
+
+	var skipped []Skipped
+	for _, exclusion := range e.all() {
+		if counts[exclusion] > 0 {
+			skipped = append(skipped, Skipped{Exclusion: exclusion, Resources: counts[exclusion]})
+		}
+	}
+	return filtered, skipped
+}
+
+// match returns the first exclusion which matches the resource, whose findings are attributed to the manifest file
+// at filePath
+func (e *Exclusions) match(resource kubeaudit.KubeResource, filePath string) (string, bool) {
+	if resource != nil && resource.Object() != nil {
+		kind := resource.Object().GetObjectKind().GroupVersionKind().Kind
+		for _, excluded := range e.kinds {
+			if strings.EqualFold(kind, excluded) {
+				return kindExclusion(excluded), true
+			}
+		}
+
+		if objectMeta := k8s.GetObjectMeta(resource.Object()); objectMeta != nil {
+			for _, re := range e.names {
+				if re.MatchString(objectMeta.GetName()) {
+					return nameExclusion(re), true
+				}
+			}
+		}
+	}
+
+	if filePath != "" {
+		for _, pattern := range e.paths {
+			if matchPath(pattern, filePath) {
+				return pathExclusion(pattern), true
+			}
+		}
+	}
+	return "", false
+}
+
+// all returns the descriptions of the exclusions, in the order they are matched
+func (e *Exclusions) all() []string {
+	var all []string
+	for _, kind := range e.kinds {
+		all = append(all, kindExclusion(kind))
+	}
+	for _, re := range e.names {
+		all = append(all, nameExclusion(re))
+	}
+	for _, pattern := range e.paths {
+		all = append(all, pathExclusion(pattern))
+	}
+	return all
+}
+
+func kindExclusion(kind string) string {
+	return "kind " + kind
+}
+
+func nameExclusion(re *regexp.Regexp) string {
+	// The anchors added by New are left out
+	return "name " + strings.TrimSuffix(strings.TrimPrefix(re.String(), "^(?:"), ")$")
+}
+
+func pathExclusion(pattern string) string {
+	return "path " + pattern
+}
+
+// matchPath returns true if the pattern matches the path or one of its directories, so that "vendor" and "vendor/*"
+// exclude the manifests of the whole vendor directory
+func matchPath(pattern, path string) bool {
+	for path = filepath.Clean(path); path != "." && path != string(filepath.Separator); path = filepath.Dir(path) {
+		if matched, _ := filepath.Match(pattern, path); matched {
+			return true
+		}
+		if filepath.Dir(path) == path {
+			break
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package exclusions
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestFilter(t *testing.T) {
+	cases := []struct {
+		testName        string
+		conf            config.Exclusions
+		expectedNames   []string
+		expectedSkipped []Skipped
+	}{
+		{"No exclusions", config.Exclusions{}, []string{"app", "cert-manager", "migration"}, nil},
+		{"Kind", config.Exclusions{Kinds: []string{"job"}}, []string{"app", "cert-manager"}, []Skipped{{"kind job", 1}}},
+		{"Name", config.Exclusions{Names: []string{"cert-.*"}}, []string{"app", "migration"}, []Skipped{{"name cert-.*", 1}}},
+		{"Name matches the whole name", config.Exclusions{Names: []string{"cert"}}, []string{"app", "cert-manager", "migration"}, nil},
+		{"Path", config.Exclusions{Paths: []string{"fixtures/vendor/*"}}, []string{"app", "migration"}, []Skipped{{"path fixtures/vendor/*", 1}}},
+		{"Directory", config.Exclusions{Paths: []string{"fixtures/vendor"}}, []string{"app", "migration"}, []Skipped{{"path fixtures/vendor", 1}}},
+		{"Several exclusions", config.Exclusions{Kinds: []string{"Deployment"}, Paths: []string{"fixtures/vendor"}}, []string{"migration"}, []Skipped{{"kind Deployment", 2}}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			exclusions, err := New(tc.conf)
+			require.NoError(t, err)
+
+			report, skipped := exclusions.Filter(getReport(t))
+			assert.Equal(t, tc.expectedSkipped, skipped)
+			assert.Equal(t, tc.expectedNames, getNames(report))
+
+			excluded := 3 - len(tc.expectedNames)
+			if excluded == 0 {
+				assert.Empty(t, report.Suppressions())
+			} else {
+				assert.Equal(t, map[kubeaudit.SuppressionMechanism]int{kubeaudit.SuppressedByExclusion: excluded}, report.Suppressions())
+			}
+		})
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, conf := range []config.Exclusions{
+		{Names: []string{"cert-manager("}},
+		{Paths: []string{"vendor/["}},
+	} {
+		_, err := New(conf)
+		assert.Error(t, err)
+	}
+}
+
+func getReport(t *testing.T) *kubeaudit.Report {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+
+	report, err := auditor.AuditManifestFiles([]string{
+		filepath.Join(fixtureDir, "app.yml"),
+		filepath.Join(fixtureDir, "vendor", "cert-manager.yml"),
+	})
+	require.NoError(t, err)
+	return report
+}
+
+// getNames returns the sorted names of the resources with findings
+func getNames(report *kubeaudit.Report) []string {
+	var names []string
+	for _, result := range report.Results() {
+		names = append(names, k8s.GetObjectMeta(result.GetResource().Object()).GetName())
+	}
+	sort.Strings(names)
+	return names
+}
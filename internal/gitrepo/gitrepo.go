@@ -0,0 +1,123 @@
+// Package gitrepo fetches the manifests of a Git repository to audit with the git command, so that repositories can be
+// audited without a checkout of their own
+package gitrepo
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Checkout is a shallow checkout of a single commit of a repository
+type Checkout struct {
+	// URL is the URL of the repository
+	URL string
+	// Ref is the branch, tag or commit which was checked out. It is empty for the default branch
+	Ref string
+	// Commit is the SHA of the commit which was checked out
+	Commit string
+	// Dir is the directory of the checkout
+	Dir string
+}
+
+// Clone fetches the ref of the repository at url with a depth of 1 and checks it out in dir, which must be empty or not
+// exist. The ref is a branch, tag or commit SHA, or the default branch of the repository if empty. Fetching a commit
+// SHA requires a server which allows fetching commits which are not at the tip of a branch, which hosts such as
+// GitHub do
+func Clone(url, ref, dir string) (*Checkout, error) {
+	fetchRef := ref
+	if fetchRef == "" {
+		fetchRef = "HEAD"
+	}
+
+	// The url and ref follow "--" so they are never parsed as options, such as a url of "--upload-pack=<command>"
+	for _, args := range [][]string{
+		{"init", "--quiet", dir},
+		{"-C", dir, "remote", "add", "--", "origin", url},
+		{"-C", dir, "fetch", "--quiet", "--depth", "1", "--", "origin", fetchRef},
+		{"-C", dir, "checkout", "--quiet", "--detach", "FETCH_HEAD"},
+	} {
+		if _, err := git(args...); err != nil {
+			return nil, fmt.Errorf("failed to check out %s of %s: %w", fetchRef, url, err)
+		}
+	}
+
+	commit, err := git("-C", dir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the commit of %s: %w", url, err)
+	}
+
+	return &Checkout{URL: url, Ref: ref, Commit: commit, Dir: dir}, nil
+}
+
+// ChangedFiles returns the paths of the files changed in the Git repository of dir since the revision, joined to the
+// top-level directory of the repository. These are the files changed between the merge base of the revision and HEAD,
+// and the uncommitted and untracked files, like the changes of a pull request against the revision. A range of commits,
+// such as "main..feature" or "main...feature", is diffed as it is instead. Deleted files are not returned
+func ChangedFiles(dir, since string) ([]string, error) {
+	top, err := git("-C", dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the Git repository of %s: %w", dir, err)
+	}
+
+	var outputs []string
+	if strings.Contains(since, "..") {
+		output, err := git("-C", top, "diff", "--name-only", "-z", "--diff-filter=d", "--end-of-options", since)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", since, err)
+		}
+		outputs = append(outputs, output)
+	} else {
+		base, err := git("-C", top, "merge-base", "--", since, "HEAD")
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the merge base of %s: %w", since, err)
+		}
+		output, err := git("-C", top, "diff", "--name-only", "-z", "--diff-filter=d", "--end-of-options", base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", since, err)
+		}
+		untracked, err := git("-C", top, "ls-files", "-z", "--others", "--exclude-standard")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list untracked files: %w", err)
+		}
+		outputs = append(outputs, output, untracked)
+	}
+
+	found := map[string]bool{}
+	var files []string
+	for _, output := range outputs {
+		// Paths are separated by NUL bytes with -z, so paths with spaces or unusual characters are not quoted
+		for _, path := range strings.Split(output, "\x00") {
+			if path == "" || found[path] {
+				continue
+			}
+			found[path] = true
+			files = append(files, filepath.Join(top, filepath.FromSlash(path)))
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// git runs the git command with the arguments and returns its trimmed output. Errors include the output of the command
+// on stderr, which explains why it failed
+func git(args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Credentials are never prompted for, as the audit usually runs without a terminal
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("%w: %s", err, message)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,134 @@
+package gitrepo
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	// The repository has a commit on main and a newer commit on the prod branch
+	repo := t.TempDir()
+	writeFile := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repo, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(content), 0644))
+	}
+	run := func(args ...string) string {
+		output, err := git(append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		require.NoError(t, err)
+		return output
+	}
+	run("init", "--quiet", "--initial-branch", "main")
+	writeFile("clusters/prod/deployment.yaml", "kind: Deployment\n")
+	run("add", "-A")
+	run("commit", "--quiet", "-m", "main")
+	mainCommit := run("rev-parse", "HEAD")
+	run("checkout", "--quiet", "-b", "prod")
+	writeFile("clusters/prod/service.yaml", "kind: Service\n")
+	run("add", "-A")
+	run("commit", "--quiet", "-m", "prod")
+	prodCommit := run("rev-parse", "HEAD")
+	run("checkout", "--quiet", "main")
+
+	url := "file://" + repo
+
+	cases := []struct {
+		testName       string
+		ref            string
+		expectedCommit string
+		expectedFiles  []string
+	}{
+		{"Default branch", "", mainCommit, []string{"deployment.yaml"}},
+		{"Branch", "prod", prodCommit, []string{"deployment.yaml", "service.yaml"}},
+		{"Commit", mainCommit, mainCommit, []string{"deployment.yaml"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "checkout")
+			checkout, err := Clone(url, tc.ref, dir)
+			require.NoError(t, err)
+			assert.Equal(t, &Checkout{URL: url, Ref: tc.ref, Commit: tc.expectedCommit, Dir: dir}, checkout)
+
+			entries, err := os.ReadDir(filepath.Join(dir, "clusters", "prod"))
+			require.NoError(t, err)
+			var files []string
+			for _, entry := range entries {
+				files = append(files, entry.Name())
+			}
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+
+	_, err := Clone(url, "missing", filepath.Join(t.TempDir(), "checkout"))
+	assert.Error(t, err)
+
+	// Refs and urls are never parsed as options
+	marker := filepath.Join(t.TempDir(), "marker")
+	_, err = Clone(url, "--upload-pack=touch "+marker, filepath.Join(t.TempDir(), "checkout"))
+	assert.Error(t, err)
+	_, err = Clone("--upload-pack=touch "+marker, "", filepath.Join(t.TempDir(), "checkout"))
+	assert.Error(t, err)
+	assert.NoFileExists(t, marker)
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+	writeFile := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(repo, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(content), 0644))
+	}
+	run := func(args ...string) string {
+		output, err := git(append([]string{"-C", repo, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		require.NoError(t, err)
+		return output
+	}
+	run("init", "--quiet", "--initial-branch", "main")
+	writeFile("apps/a/deployment.yaml", "kind: Deployment\n")
+	writeFile("apps/a/service.yaml", "kind: Service\n")
+	writeFile("apps/b/deployment.yaml", "kind: Deployment\n")
+	run("add", "-A")
+	run("commit", "--quiet", "-m", "main")
+
+	// The feature branch changes a file and deletes another, and a newer commit on main is not part of its changes
+	run("checkout", "--quiet", "-b", "feature")
+	writeFile("apps/a/deployment.yaml", "kind: Deployment\nmetadata: {}\n")
+	require.NoError(t, os.Remove(filepath.Join(repo, "apps/a/service.yaml")))
+	run("add", "-A")
+	run("commit", "--quiet", "-m", "feature")
+	run("checkout", "--quiet", "main")
+	writeFile("apps/b/deployment.yaml", "kind: Deployment\nmetadata: {}\n")
+	run("commit", "--quiet", "-am", "main")
+	run("checkout", "--quiet", "feature")
+
+	// Uncommitted and untracked files are changes too
+	writeFile("apps/c/deployment with spaces.yaml", "kind: Deployment\n")
+
+	top := run("rev-parse", "--show-toplevel")
+	files, err := ChangedFiles(filepath.Join(repo, "apps"), "main")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(top, "apps", "a", "deployment.yaml"),
+		filepath.Join(top, "apps", "c", "deployment with spaces.yaml"),
+	}, files)
+
+	// Ranges are diffed as they are
+	files, err = ChangedFiles(repo, "main..feature")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(top, "apps", "a", "deployment.yaml"), filepath.Join(top, "apps", "b", "deployment.yaml")}, files)
+
+	_, err = ChangedFiles(repo, "missing")
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+// Package grpcserver implements the kubeaudit gRPC API defined in pkg/api/v1, which audits submitted manifests and
+// streams the findings of the periodic audits of kubeaudit serve
+package grpcserver
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/notify"
+	apiv1 "github.com/Shopify/kubeaudit/pkg/api/v1"
+)
+
+// watcherBuffer is the number of audits whose new findings are held for a watcher which hasn't received them yet.
+// Watchers which fall further behind are disconnected so they don't hold up the other watchers
+const watcherBuffer = 16
+
+// Server implements apiv1.KubeauditServer
+type Server struct {
+	apiv1.UnimplementedKubeauditServer
+
+	auditor     *kubeaudit.Kubeaudit
+	minSeverity kubeaudit.SeverityLevel
+	redact      func(report *kubeaudit.Report) *kubeaudit.Report
+
+	mu sync.Mutex
+	// latest holds the findings of the latest published audit, of every severity
+	latest       []*apiv1.Finding
+	fingerprints map[string]bool
+	watchers     map[*watcher]bool
+	closed       bool
+}
+
+type watcher struct {
+	findings chan []*apiv1.Finding
+	// dropped is closed when the watcher is disconnected because it fell behind or the server is closed
+	dropped chan struct{}
+}
+
+// Config configures how the requests of the server are handled
+type Config struct {
+	// MinSeverity is the lowest severity of the findings returned, unless a request sets its own minimum severity
+	MinSeverity kubeaudit.SeverityLevel
+	// Redact, if set, returns the report of each audited manifest with the names which must not leave kubeaudit
+	// redacted, such as with kubeaudit.Report.Redact. Published reports are expected to be redacted already
+	Redact func(report *kubeaudit.Report) *kubeaudit.Report
+}
+
+// New returns a server which audits manifests with the auditor
+func New(auditor *kubeaudit.Kubeaudit, config Config) *Server {
+	return &Server{
+		auditor:     auditor,
+		minSeverity: config.MinSeverity,
+		redact:      config.Redact,
+		watchers:    map[*watcher]bool{},
+	}
+}
+
+// AuditManifest audits the resources of the manifest in the request
+func (s *Server) AuditManifest(_ context.Context, request *apiv1.AuditManifestRequest) (*apiv1.AuditManifestResponse, error) {
+	if len(request.GetManifest()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "manifest is empty")
+	}
+
+	report, err := s.auditor.AuditManifest("", bytes.NewReader(request.GetManifest()))
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "error auditing manifest: %v", err)
+	}
+	if s.redact != nil {
+		report = s.redact(report)
+	}
+
+	return &apiv1.AuditManifestResponse{
+		Findings: Findings(report, s.severity(request.GetMinSeverity())),
+	}, nil
+}
+
+// WatchFindings streams the new findings of every audit published after the request, until the client cancels the
+// request or the server is closed
+func (s *Server) WatchFindings(request *apiv1.WatchFindingsRequest, stream apiv1.Kubeaudit_WatchFindingsServer) error {
+	minSeverity := s.severity(request.GetMinSeverity())
+	w := &watcher{
+		findings: make(chan []*apiv1.Finding, watcherBuffer),
+		dropped:  make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return status.Error(codes.Unavailable, "the server is shutting down")
+	}
+	if request.GetIncludeExisting() && len(s.latest) > 0 {
+		w.findings <- s.latest
+	}
+	s.watchers[w] = true
+	s.mu.Unlock()
+	defer s.removeWatcher(w)
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case findings := <-w.findings:
+			if err := sendFindings(stream, findings, minSeverity); err != nil {
+				return err
+			}
+		case <-w.dropped:
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return status.Error(codes.Unavailable, "the server is shutting down")
+			}
+			return status.Error(codes.ResourceExhausted, "the client is not receiving findings fast enough")
+		}
+	}
+}
+
+// Publish sends the findings of the report which were not in the previously published report to the watchers
+func (s *Server) Publish(report *kubeaudit.Report) {
+	findings := Findings(report, kubeaudit.Info)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var newFindings []*apiv1.Finding
+	fingerprints := map[string]bool{}
+	for _, finding := range findings {
+		if !s.fingerprints[finding.Fingerprint] && !fingerprints[finding.Fingerprint] {
+			newFindings = append(newFindings, finding)
+		}
+		fingerprints[finding.Fingerprint] = true
+	}
+	s.latest = findings
+	s.fingerprints = fingerprints
+
+	if len(newFindings) == 0 {
+		return
+	}
+	for w := range s.watchers {
+		select {
+		case w.findings <- newFindings:
+		default:
+			delete(s.watchers, w)
+			close(w.dropped)
+		}
+	}
+}
+
+// Close disconnects the watchers so the gRPC server can be stopped gracefully
+func (s *Server) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	for w := range s.watchers {
+		delete(s.watchers, w)
+		close(w.dropped)
+	}
+}
+
+func (s *Server) removeWatcher(w *watcher) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.watchers, w)
+}
+
+func (s *Server) severity(severity apiv1.Severity) kubeaudit.SeverityLevel {
+	switch severity {
+	case apiv1.Severity_SEVERITY_INFO:
+		return kubeaudit.Info
+	case apiv1.Severity_SEVERITY_WARNING:
+		return kubeaudit.Warn
+	case apiv1.Severity_SEVERITY_ERROR:
+		return kubeaudit.Error
+	}
+	return s.minSeverity
+}
+
+func sendFindings(stream apiv1.Kubeaudit_WatchFindingsServer, findings []*apiv1.Finding, minSeverity kubeaudit.SeverityLevel) error {
+	for _, finding := range findings {
+		if finding.Severity < toSeverity(minSeverity) {
+			continue
+		}
+		if err := stream.Send(finding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Findings returns the audit results in the report with at least the minimum severity
+func Findings(report *kubeaudit.Report, minSeverity kubeaudit.SeverityLevel) []*apiv1.Finding {
+	var findings []*apiv1.Finding
+	for _, finding := range notify.Findings(report, minSeverity) {
+		severity, _ := kubeaudit.ParseSeverity(finding.Severity)
+		findings = append(findings, &apiv1.Finding{
+			Fingerprint: finding.Fingerprint,
+			Auditor:     finding.Auditor,
+			Rule:        finding.Rule,
+			Severity:    toSeverity(severity),
+			Message:     finding.Message,
+			Kind:        finding.Kind,
+			Namespace:   finding.Namespace,
+			Name:        finding.Name,
+			Metadata:    finding.Metadata,
+		})
+	}
+	return findings
+}
+
+func toSeverity(severity kubeaudit.SeverityLevel) apiv1.Severity {
+	switch severity.Builtin() {
+	case kubeaudit.Warn:
+		return apiv1.Severity_SEVERITY_WARNING
+	case kubeaudit.Error:
+		return apiv1.Severity_SEVERITY_ERROR
+	}
+	return apiv1.Severity_SEVERITY_INFO
+}
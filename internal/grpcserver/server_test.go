@@ -0,0 +1,186 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	apiv1 "github.com/Shopify/kubeaudit/pkg/api/v1"
+)
+
+const manifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+  namespace: namespace
+spec:
+  containers:
+    - name: container
+      image: scratch
+      securityContext:
+        privileged: true
+`
+
+func newTestServer(t *testing.T) (*Server, apiv1.KubeauditClient) {
+	limitsAuditor, err := limits.New(limits.Config{})
+	require.NoError(t, err)
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New(), limitsAuditor})
+	require.NoError(t, err)
+	server := New(auditor, Config{MinSeverity: kubeaudit.Error})
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	apiv1.RegisterKubeauditServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return server, apiv1.NewKubeauditClient(conn)
+}
+
+func auditManifest(t *testing.T, auditor *kubeaudit.Kubeaudit) *kubeaudit.Report {
+	report, err := auditor.AuditManifest("", strings.NewReader(manifest))
+	require.NoError(t, err)
+	return report
+}
+
+// waitForWatchers waits for the watch requests of the client to be registered by the server
+func waitForWatchers(t *testing.T, server *Server, count int) {
+	require.Eventually(t, func() bool {
+		server.mu.Lock()
+		defer server.mu.Unlock()
+		return len(server.watchers) == count
+	}, 10*time.Second, 10*time.Millisecond)
+}
+
+func rules(findings []*apiv1.Finding) []string {
+	var rules []string
+	for _, finding := range findings {
+		rules = append(rules, finding.Rule)
+	}
+	return rules
+}
+
+func TestAuditManifest(t *testing.T) {
+	_, client := newTestServer(t)
+
+	// Findings below the minimum severity of the server are left out by default
+	response, err := client.AuditManifest(context.Background(), &apiv1.AuditManifestRequest{Manifest: []byte(manifest)})
+	require.NoError(t, err)
+	assert.Equal(t, []string{privileged.PrivilegedTrue}, rules(response.Findings))
+
+	finding := response.Findings[0]
+	assert.Equal(t, apiv1.Severity_SEVERITY_ERROR, finding.Severity)
+	assert.Equal(t, "Pod", finding.Kind)
+	assert.Equal(t, "namespace", finding.Namespace)
+	assert.Equal(t, "pod", finding.Name)
+	assert.Equal(t, "container", finding.Metadata["Container"])
+	assert.NotEmpty(t, finding.Fingerprint)
+
+	response, err = client.AuditManifest(context.Background(), &apiv1.AuditManifestRequest{Manifest: []byte(manifest), MinSeverity: apiv1.Severity_SEVERITY_WARNING})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{privileged.PrivilegedTrue, limits.LimitsNotSet}, rules(response.Findings))
+}
+
+func TestAuditManifestRedact(t *testing.T) {
+	server, client := newTestServer(t)
+	server.redact = func(report *kubeaudit.Report) *kubeaudit.Report { return report.Redact(kubeaudit.RedactMask) }
+
+	response, err := client.AuditManifest(context.Background(), &apiv1.AuditManifestRequest{Manifest: []byte(manifest)})
+	require.NoError(t, err)
+	require.Len(t, response.Findings, 1)
+	assert.Equal(t, "[REDACTED]", response.Findings[0].Namespace)
+	assert.Equal(t, "[REDACTED]", response.Findings[0].Name)
+}
+
+func TestAuditManifestInvalid(t *testing.T) {
+	_, client := newTestServer(t)
+
+	_, err := client.AuditManifest(context.Background(), &apiv1.AuditManifestRequest{Manifest: []byte("")})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Invalid YAML is reported as a finding
+	response, err := client.AuditManifest(context.Background(), &apiv1.AuditManifestRequest{Manifest: []byte("kind: [not yaml")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{kubeaudit.ManifestSyntaxError}, rules(response.Findings))
+}
+
+func TestWatchFindings(t *testing.T) {
+	server, client := newTestServer(t)
+	report := auditManifest(t, server.auditor)
+
+	// A first audit is published before the client watches, so its findings are only sent with include_existing
+	server.Publish(report)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	existing, err := client.WatchFindings(ctx, &apiv1.WatchFindingsRequest{IncludeExisting: true, MinSeverity: apiv1.Severity_SEVERITY_INFO})
+	require.NoError(t, err)
+	newOnly, err := client.WatchFindings(ctx, &apiv1.WatchFindingsRequest{})
+	require.NoError(t, err)
+	waitForWatchers(t, server, 2)
+
+	var existingRules []string
+	for range report.Results()[0].GetAuditResults() {
+		finding, err := existing.Recv()
+		require.NoError(t, err)
+		existingRules = append(existingRules, finding.Rule)
+	}
+	assert.ElementsMatch(t, []string{privileged.PrivilegedTrue, limits.LimitsNotSet}, existingRules)
+
+	// Publishing the same findings again doesn't send them, an audit without findings doesn't either, and the findings
+	// are sent again once they reappear. Findings below the minimum severity of the server are not sent
+	server.Publish(report)
+	server.Publish(kubeaudit.NewReport(nil))
+	server.Publish(report)
+
+	finding, err := newOnly.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, privileged.PrivilegedTrue, finding.Rule)
+
+	server.Close()
+	_, err = newOnly.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestPublishSlowWatcher(t *testing.T) {
+	server, _ := newTestServer(t)
+	report := auditManifest(t, server.auditor)
+
+	// The watcher doesn't receive the findings of any audit
+	w := &watcher{findings: make(chan []*apiv1.Finding, watcherBuffer), dropped: make(chan struct{})}
+	server.watchers[w] = true
+
+	for i := 0; i < watcherBuffer; i++ {
+		server.Publish(report)
+		server.Publish(kubeaudit.NewReport(nil))
+	}
+	assert.Len(t, server.watchers, 1)
+
+	server.Publish(report)
+	assert.Empty(t, server.watchers)
+	select {
+	case <-w.dropped:
+	default:
+		assert.Fail(t, "the watcher was not disconnected")
+	}
+}
@@ -0,0 +1,178 @@
+// Package history saves the findings of audits so that audits run at different times can be compared
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/baseline"
+	"github.com/Shopify/kubeaudit/internal/color"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// Version is the version of the saved report format
+const Version = 1
+
+// Report is the saved findings of an audit
+type Report struct {
+	Version  int       `json:"version"`
+	Created  time.Time `json:"created"`
+	Findings []Finding `json:"findings"`
+}
+
+// Finding is a saved audit result. Findings of different reports are matched by the fingerprint of the baseline
+// package, so a finding whose message or severity changes is still the same finding
+type Finding struct {
+	baseline.Finding
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Comparison is the difference between the findings of two reports
+type Comparison struct {
+	// New are the findings of the newer report which are not in the older report
+	New []Finding `json:"new"`
+	// Fixed are the findings of the older report which are not in the newer report
+	Fixed []Finding `json:"fixed"`
+	// Persisting are the findings of the newer report which are also in the older report
+	Persisting []Finding `json:"persisting"`
+}
+
+// New creates a saved report from the audit results of a report with a minimum severity
+func New(report *kubeaudit.Report, minSeverity kubeaudit.SeverityLevel, created time.Time) *Report {
+	saved := &Report{Version: Version, Created: created.UTC(), Findings: []Finding{}}
+	seen := map[string]bool{}
+
+	for _, result := range report.ResultsWithMinSeverity(minSeverity) {
+		for _, auditResult := range result.GetAuditResults() {
+			finding := Finding{
+				Finding:  baseline.NewFinding(result.GetResource(), auditResult),
+				Severity: auditResult.Severity.String(),
+				Message:  auditResult.Message,
+			}
+			if seen[finding.Fingerprint] {
+				continue
+			}
+			seen[finding.Fingerprint] = true
+			saved.Findings = append(saved.Findings, finding)
+		}
+	}
+
+	sortFindings(saved.Findings)
+	return saved
+}
+
+// Load reads a saved report file
+func Load(path string) (*Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening report file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	report, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading report file %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// Read decodes a saved report
+func Read(r io.Reader) (*Report, error) {
+	report := &Report{}
+	if err := json.NewDecoder(r).Decode(report); err != nil {
+		return nil, err
+	}
+	if report.Version != Version {
+		return nil, fmt.Errorf("unsupported report version %d, expected %d", report.Version, Version)
+	}
+	return report, nil
+}
+
+// Write encodes the saved report as indented JSON
+func (r *Report) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// Compare returns the findings which are new, fixed and persisting in the newer report compared to the older one
+func Compare(older, newer *Report) *Comparison {
+	comparison := &Comparison{New: []Finding{}, Fixed: []Finding{}, Persisting: []Finding{}}
+
+	olderFindings := make(map[string]bool, len(older.Findings))
+	for _, finding := range older.Findings {
+		olderFindings[finding.Fingerprint] = true
+	}
+	newerFindings := make(map[string]bool, len(newer.Findings))
+	for _, finding := range newer.Findings {
+		newerFindings[finding.Fingerprint] = true
+		if olderFindings[finding.Fingerprint] {
+			comparison.Persisting = append(comparison.Persisting, finding)
+		} else {
+			comparison.New = append(comparison.New, finding)
+		}
+	}
+	for _, finding := range older.Findings {
+		if !newerFindings[finding.Fingerprint] {
+			comparison.Fixed = append(comparison.Fixed, finding)
+		}
+	}
+
+	return comparison
+}
+
+// Write writes the number of new, fixed and persisting findings, followed by the findings of each group
+func (c *Comparison) Write(w io.Writer, useColor bool) error {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "%d new, %d fixed, %d persisting findings\n", len(c.New), len(c.Fixed), len(c.Persisting))
+	for _, group := range []struct {
+		name     string
+		color    func(string) string
+		findings []Finding
+	}{
+		{"New", color.Red, c.New},
+		{"Fixed", color.Green, c.Fixed},
+		{"Persisting", color.Yellow, c.Persisting},
+	} {
+		if len(group.findings) == 0 {
+			continue
+		}
+		heading := fmt.Sprintf("%s (%d)", group.name, len(group.findings))
+		if useColor {
+			heading = group.color(heading)
+		}
+		fmt.Fprintf(&out, "\n%s\n", heading)
+		for _, finding := range group.findings {
+			fmt.Fprintf(&out, "  [%s] %s: %s\n", finding.Severity, describeResource(finding), finding.Rule)
+			if finding.Message != "" {
+				fmt.Fprintf(&out, "    %s\n", finding.Message)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// describeResource returns the kind, namespace and name of the resource of the finding, and its container
+func describeResource(finding Finding) string {
+	description := k8s.DescribeResource(finding.Kind, finding.Namespace, finding.Name)
+	if finding.Container != "" {
+		description += ", container " + finding.Container
+	}
+	return description
+}
+
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return baseline.Less(findings[i].Finding, findings[j].Finding)
+	})
+}
@@ -0,0 +1,96 @@
+package history
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+var created = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNew(t *testing.T) {
+	report := New(getReport(t, "privileged-two-containers.yml"), kubeaudit.Info, created)
+
+	assert.Equal(t, Version, report.Version)
+	assert.Equal(t, created, report.Created)
+	require.Len(t, report.Findings, 2)
+	assert.Equal(t, privileged.PrivilegedTrue, report.Findings[0].Rule)
+	assert.Equal(t, "container1", report.Findings[0].Container)
+	assert.Equal(t, "error", report.Findings[0].Severity)
+	assert.NotEmpty(t, report.Findings[0].Message)
+	assert.Equal(t, "container2", report.Findings[1].Container)
+}
+
+func TestWriteRead(t *testing.T) {
+	report := New(getReport(t, "privileged-two-containers.yml"), kubeaudit.Info, created)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.Write(&buf))
+
+	read, err := Read(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, report, read)
+}
+
+func TestReadUnsupportedVersion(t *testing.T) {
+	_, err := Read(bytes.NewBufferString(`{"version": 2, "findings": []}`))
+	assert.Error(t, err)
+}
+
+func TestCompare(t *testing.T) {
+	one := New(getReport(t, "privileged-one-container.yml"), kubeaudit.Info, created)
+	two := New(getReport(t, "privileged-two-containers.yml"), kubeaudit.Info, created.AddDate(0, 0, 7))
+
+	comparison := Compare(one, two)
+	require.Len(t, comparison.New, 1)
+	assert.Equal(t, "container2", comparison.New[0].Container)
+	assert.Empty(t, comparison.Fixed)
+	require.Len(t, comparison.Persisting, 1)
+	assert.Equal(t, "container1", comparison.Persisting[0].Container)
+
+	comparison = Compare(two, one)
+	assert.Empty(t, comparison.New)
+	require.Len(t, comparison.Fixed, 1)
+	assert.Equal(t, "container2", comparison.Fixed[0].Container)
+	require.Len(t, comparison.Persisting, 1)
+
+	// A finding whose message and severity change is the same finding
+	changed := New(getReport(t, "privileged-one-container.yml"), kubeaudit.Info, created)
+	changed.Findings[0].Message = "reworded"
+	changed.Findings[0].Severity = "warning"
+	comparison = Compare(one, changed)
+	assert.Empty(t, comparison.New)
+	assert.Empty(t, comparison.Fixed)
+	require.Len(t, comparison.Persisting, 1)
+	assert.Equal(t, "reworded", comparison.Persisting[0].Message)
+}
+
+func TestComparisonWrite(t *testing.T) {
+	one := New(getReport(t, "privileged-one-container.yml"), kubeaudit.Info, created)
+	two := New(getReport(t, "privileged-two-containers.yml"), kubeaudit.Info, created)
+	one.Findings[0].Message, two.Findings[0].Message, two.Findings[1].Message = "", "", "privileged"
+
+	var buf bytes.Buffer
+	require.NoError(t, Compare(one, two).Write(&buf, false))
+	assert.Equal(t, `1 new, 0 fixed, 1 persisting findings
+
+New (1)
+  [error] Deployment history/deployment, container container2: PrivilegedTrue
+    privileged
+
+Persisting (1)
+  [error] Deployment history/deployment, container container1: PrivilegedTrue
+`, buf.String())
+}
+
+func getReport(t *testing.T, file string) *kubeaudit.Report {
+	return test.GetReport(t, fixtureDir, file, []kubeaudit.Auditable{privileged.New()}, "", test.MANIFEST_MODE)
+}
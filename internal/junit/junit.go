@@ -0,0 +1,157 @@
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/redact"
+)
+
+// TestSuites is the root element of a JUnit XML report
+type TestSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Skipped  int          `xml:"skipped,attr"`
+	Suites   []*TestSuite `xml:"testsuite"`
+}
+
+// TestSuite holds the test cases for a single auditor
+type TestSuite struct {
+	Name      string      `xml:"name,attr"`
+	Tests     int         `xml:"tests,attr"`
+	Failures  int         `xml:"failures,attr"`
+	Skipped   int         `xml:"skipped,attr"`
+	TestCases []*TestCase `xml:"testcase"`
+}
+
+// TestCase is a single audit result
+type TestCase struct {
+	Name      string   `xml:"name,attr"`
+	ClassName string   `xml:"classname,attr"`
+	File      string   `xml:"file,attr,omitempty"`
+	Line      int      `xml:"line,attr,omitempty"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+}
+
+// Failure marks a test case as failed. The type is the severity of the audit result
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Details string `xml:",cdata"`
+}
+
+// Skipped marks a test case as skipped. Informational audit results are reported as skipped since no action is
+// required
+type Skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Create generates a JUnit report in which each auditor is a test suite and each audit result is a test case. Error
+// and warning results are failed test cases and info results are skipped test cases
+func Create(kubeauditReport *kubeaudit.Report) *TestSuites {
+	report := &TestSuites{Name: "kubeaudit"}
+	suites := map[string]*TestSuite{}
+
+	for _, result := range kubeauditReport.Results() {
+		resourceName := kubeaudit.ResourceName(result.GetResource())
+
+		for _, auditResult := range result.GetAuditResults() {
+			auditor := strings.ToLower(auditResult.Auditor)
+			suite, ok := suites[auditor]
+			if !ok {
+				suite = &TestSuite{Name: auditor}
+				suites[auditor] = suite
+				report.Suites = append(report.Suites, suite)
+			}
+
+			testCase := &TestCase{
+				Name:      getTestCaseName(auditResult, resourceName),
+				ClassName: "kubeaudit." + auditor,
+				File:      auditResult.FilePath,
+				Line:      auditResult.Line,
+			}
+
+			message := redact.String(auditResult.Message)
+			if auditResult.Severity.Builtin() == kubeaudit.Info {
+				testCase.Skipped = &Skipped{Message: message}
+				suite.Skipped++
+				report.Skipped++
+			} else {
+				testCase.Failure = &Failure{
+					Message: message,
+					Type:    auditResult.Severity.String(),
+					Details: getDetails(auditResult),
+				}
+				suite.Failures++
+				report.Failures++
+			}
+
+			suite.TestCases = append(suite.TestCases, testCase)
+			suite.Tests++
+			report.Tests++
+		}
+	}
+
+	sort.SliceStable(report.Suites, func(i, j int) bool { return report.Suites[i].Name < report.Suites[j].Name })
+
+	return report
+}
+
+// Write writes the report as XML
+func (r *TestSuites) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// getTestCaseName returns the test case name in the form "[severity] Rule: kind/namespace/name (container)" so that
+// findings for different resources and containers have distinct names. Findings of several clusters end with the
+// kubeconfig context of their cluster, as in "[severity] Rule: kind/namespace/name (container) [context]"
+func getTestCaseName(auditResult *kubeaudit.AuditResult, resourceName string) string {
+	name := fmt.Sprintf("[%s] %s", auditResult.Severity, auditResult.Rule)
+	if resourceName != "" {
+		name += ": " + resourceName
+	}
+	if container := auditResult.Metadata["Container"]; container != "" {
+		name += fmt.Sprintf(" (%s)", container)
+	}
+	if context := auditResult.Metadata[kubeaudit.ContextMetadata]; context != "" {
+		name += fmt.Sprintf(" [%s]", context)
+	}
+	return name
+}
+
+func getDetails(auditResult *kubeaudit.AuditResult) string {
+	var details strings.Builder
+	fmt.Fprintf(&details, "Severity: %s\n", auditResult.Severity)
+	fmt.Fprintf(&details, "Auditor: %s\n", auditResult.Auditor)
+	fmt.Fprintf(&details, "Message: %s\n", redact.String(auditResult.Message))
+
+	keys := make([]string, 0, len(auditResult.Metadata))
+	for k := range auditResult.Metadata {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&details, "%s: %s\n", k, redact.String(auditResult.Metadata[k]))
+	}
+
+	fmt.Fprintf(&details, "Auditor docs: https://github.com/Shopify/kubeaudit/blob/main/docs/auditors/%s.md", strings.ToLower(auditResult.Auditor))
+	return details.String()
+}
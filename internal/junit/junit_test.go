@@ -0,0 +1,104 @@
+package junit
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreate(t *testing.T) {
+	limitsAuditor, err := limits.New(limits.Config{})
+	require.NoError(t, err)
+
+	auditables := []kubeaudit.Auditable{privileged.New(), limitsAuditor, image.New(image.Config{Image: "scratch:latest"})}
+	kubeauditReport := test.GetReport(t, "../../auditors/privileged/fixtures", "privileged-true.yml", auditables, "", test.MANIFEST_MODE)
+
+	report := Create(kubeauditReport)
+	assert.Equal(t, "kubeaudit", report.Name)
+	assert.Equal(t, 3, report.Tests)
+	assert.Equal(t, 3, report.Failures)
+	assert.Equal(t, 0, report.Skipped)
+
+	// Test suites are sorted by auditor name
+	require.Len(t, report.Suites, 3)
+	assert.Equal(t, image.Name, report.Suites[0].Name)
+	assert.Equal(t, limits.Name, report.Suites[1].Name)
+	assert.Equal(t, privileged.Name, report.Suites[2].Name)
+
+	imageCase := report.Suites[0].TestCases[0]
+	assert.Equal(t, "[warning] ImageTagMissing: DaemonSet/privileged-true/daemonset (container)", imageCase.Name)
+	require.NotNil(t, imageCase.Failure)
+	assert.Equal(t, "warning", imageCase.Failure.Type)
+
+	privilegedCase := report.Suites[2].TestCases[0]
+	assert.Equal(t, "[error] PrivilegedTrue: DaemonSet/privileged-true/daemonset (container)", privilegedCase.Name)
+	assert.Equal(t, "kubeaudit.privileged", privilegedCase.ClassName)
+	require.NotNil(t, privilegedCase.Failure)
+	assert.Equal(t, "error", privilegedCase.Failure.Type)
+	assert.Contains(t, privilegedCase.Failure.Details, "Container: container")
+}
+
+func TestCreateContext(t *testing.T) {
+	kubeauditReport := test.GetReport(t, "../../auditors/privileged/fixtures", "privileged-true.yml", []kubeaudit.Auditable{privileged.New()}, "", test.MANIFEST_MODE)
+	for _, result := range kubeauditReport.RawResults() {
+		for _, auditResult := range result.GetAuditResults() {
+			auditResult.Metadata[kubeaudit.ContextMetadata] = "production"
+		}
+	}
+
+	report := Create(kubeauditReport)
+	require.Len(t, report.Suites, 1)
+	assert.Equal(t, "[error] PrivilegedTrue: DaemonSet/privileged-true/daemonset (container) [production]", report.Suites[0].TestCases[0].Name)
+}
+
+func TestWrite(t *testing.T) {
+	report := Create(kubeaudit.NewReport([]kubeaudit.Result{&kubeaudit.WorkloadResult{
+		AuditResults: []*kubeaudit.AuditResult{{
+			Auditor:  limits.Name,
+			Rule:     limits.LimitsNotSet,
+			Severity: kubeaudit.Warn,
+			Message:  "Resource limits not set <token: abc123>",
+		}, {
+			Auditor:  image.Name,
+			Rule:     image.ImageCorrect,
+			Severity: kubeaudit.Info,
+			Message:  "Image tag is correct",
+		}},
+	}}))
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+	assert.True(t, bytes.HasPrefix(out.Bytes(), []byte(xml.Header)))
+	assert.NotContains(t, out.String(), "abc123")
+
+	decoded := TestSuites{}
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded.Suites, 2)
+	assert.Equal(t, 1, decoded.Failures)
+	assert.Equal(t, 1, decoded.Skipped)
+
+	// Info results are skipped test cases
+	imageCase := decoded.Suites[0].TestCases[0]
+	assert.Equal(t, "[info] ImageCorrect", imageCase.Name)
+	assert.Nil(t, imageCase.Failure)
+	require.NotNil(t, imageCase.Skipped)
+
+	limitsCase := decoded.Suites[1].TestCases[0]
+	assert.Equal(t, "[warning] LimitsNotSet", limitsCase.Name)
+	require.NotNil(t, limitsCase.Failure)
+	assert.Equal(t, "warning", limitsCase.Failure.Type)
+}
+
+func TestCreateWithNoResults(t *testing.T) {
+	report := Create(&kubeaudit.Report{})
+	assert.Equal(t, 0, report.Tests)
+	assert.Empty(t, report.Suites)
+}
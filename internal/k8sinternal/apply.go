@@ -0,0 +1,77 @@
+package k8sinternal
+
+import (
+	"context"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// FieldManager is the field manager which owns the fields applied by kubeaudit
+const FieldManager = "kubeaudit"
+
+// ApplyOptions configures how resources are applied to a cluster
+type ApplyOptions struct {
+	// ConnectionOptions configure the connection to the API server in local and cluster mode
+	ConnectionOptions
+	// DryRun applies the resources in server-side dry-run mode. The API server validates and defaults the applied
+	// resources and returns them without persisting them
+	DryRun bool
+}
+
+// Apply applies the resource to the cluster with server-side apply and returns the resource returned by the API
+// server. Conflicts are forced, so kubeaudit takes ownership of the fields of the resource which are managed by other
+// field managers
+func (kc kubeClient) Apply(resource k8s.Resource, options ApplyOptions) (k8s.Resource, error) {
+	applyConfiguration, err := newApplyConfiguration(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk := applyConfiguration.GroupVersionKind()
+	mapping, err := kc.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := applyConfiguration.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	force := true
+	patchOptions := metav1.PatchOptions{FieldManager: FieldManager, Force: &force}
+	if options.DryRun {
+		patchOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	var client dynamic.ResourceInterface = kc.dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		client = kc.dynamicClient.Resource(mapping.Resource).Namespace(applyConfiguration.GetNamespace())
+	}
+
+	applied, err := client.Patch(context.Background(), applyConfiguration.GetName(), types.ApplyPatchType, data, patchOptions)
+	if err != nil {
+		return nil, err
+	}
+	return unstructuredToObject(applied)
+}
+
+// newApplyConfiguration returns the resource as the configuration to apply. The fields which are set by the API
+// server and can't be applied are removed. The resource version is kept so the apply fails if the resource was
+// changed since it was fetched
+func newApplyConfiguration(resource k8s.Resource) (*unstructured.Unstructured, error) {
+	applyConfiguration, err := ToUnstructured(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	applyConfiguration.SetManagedFields(nil)
+	applyConfiguration.SetCreationTimestamp(metav1.Time{})
+	unstructured.RemoveNestedField(applyConfiguration.Object, "status")
+	return applyConfiguration, nil
+}
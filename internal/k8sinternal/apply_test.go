@@ -0,0 +1,82 @@
+package k8sinternal_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestApply(t *testing.T) {
+	deployment := k8s.NewDeployment()
+	deployment.Name = "deployment"
+	deployment.Namespace = "namespace"
+	deployment.ResourceVersion = "1"
+	deployment.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+	deployment.Status.Replicas = 1
+
+	namespace := k8s.NewNamespace()
+	namespace.Name = "namespace"
+
+	dynamicClient := fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+	var actions []ktesting.PatchAction
+	dynamicClient.PrependReactor("patch", "*", func(action ktesting.Action) (bool, runtime.Object, error) {
+		patchAction := action.(ktesting.PatchAction)
+		actions = append(actions, patchAction)
+		applied := &unstructured.Unstructured{}
+		err := applied.UnmarshalJSON(patchAction.GetPatch())
+		return true, applied, err
+	})
+
+	discoveryClient := &fakediscovery.FakeDiscovery{Fake: &ktesting.Fake{}}
+	discoveryClient.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Namespaced: true, Kind: "Deployment", Verbs: metav1.Verbs{"patch"}}}},
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "namespaces", Namespaced: false, Kind: "Namespace", Verbs: metav1.Verbs{"patch"}}}},
+	}
+
+	client := k8sinternal.NewKubeClient(dynamicClient, discoveryClient)
+
+	applied, err := client.Apply(deployment, k8sinternal.ApplyOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, types.ApplyPatchType, actions[0].GetPatchType())
+	assert.Equal(t, "namespace", actions[0].GetNamespace())
+	assert.Equal(t, "deployment", actions[0].GetName())
+	assert.Equal(t, "deployments", actions[0].GetResource().Resource)
+
+	// Fields set by the API server are not applied, except for the resource version which guards against
+	// overwriting concurrent changes
+	applyConfiguration := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(actions[0].GetPatch(), &applyConfiguration))
+	assert.NotContains(t, applyConfiguration, "status")
+	metadata := applyConfiguration["metadata"].(map[string]interface{})
+	assert.NotContains(t, metadata, "managedFields")
+	assert.NotContains(t, metadata, "creationTimestamp")
+	assert.Equal(t, "1", metadata["resourceVersion"])
+
+	appliedDeployment, ok := applied.(*k8s.DeploymentV1)
+	require.True(t, ok)
+	assert.Equal(t, "deployment", appliedDeployment.Name)
+
+	// Cluster-scoped resources are applied without a namespace
+	_, err = client.Apply(namespace, k8sinternal.ApplyOptions{})
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+	assert.Equal(t, "", actions[1].GetNamespace())
+	assert.Equal(t, "namespaces", actions[1].GetResource().Resource)
+
+	// Resources of kinds not served by the cluster are not applied
+	_, err = client.Apply(k8s.NewService(), k8sinternal.ApplyOptions{})
+	assert.Error(t, err)
+	assert.Len(t, actions, 2)
+}
@@ -0,0 +1,241 @@
+package k8sinternal
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// CacheOptions configures the informer cache used by a CachedKubeClient
+type CacheOptions struct {
+	// ResyncPeriod is how often the informers re-list resources from the API server. Zero disables resyncs.
+	ResyncPeriod time.Duration
+	// WarmupTimeout is the maximum time to wait for the informer caches to sync. Resource types which are not synced
+	// by the deadline are listed directly from the API server instead, or handled once they are synced when they are
+	// watched. Defaults to DefaultCacheWarmupTimeout.
+	WarmupTimeout time.Duration
+}
+
+// DefaultCacheWarmupTimeout is the default maximum time to wait for the informer caches to sync. Caches may never sync,
+// such as the cache of a resource type which can be listed but not watched, so the wait is always bounded
+const DefaultCacheWarmupTimeout = 2 * time.Minute
+
+// warmupTimeout returns the maximum time to wait for the informer caches to sync
+func (options CacheOptions) warmupTimeout() time.Duration {
+	if options.WarmupTimeout <= 0 {
+		return DefaultCacheWarmupTimeout
+	}
+	return options.WarmupTimeout
+}
+
+// CacheStats counts how often resource types were served from a warm informer cache
+type CacheStats struct {
+	// Hits is the number of times a resource type was served from an informer cache that was already synced
+	Hits uint64
+	// Misses is the number of times a resource type was served from a cold cache or directly from the API server
+	Misses uint64
+}
+
+// CachedKubeClient is a KubeClient which serves resources from informer caches. Caches are created the first time a
+// resource type is requested and are reused by subsequent calls, so repeated audits do not re-list the cluster.
+type CachedKubeClient interface {
+	KubeClient
+	// Stats returns the cache hit and miss counts
+	Stats() CacheStats
+	// Watch calls the handler each time a workload resource is created, updated or deleted, until the context is
+	// cancelled. Watch must be called at most once per client
+	Watch(ctx context.Context, options ClientOptions, handler WatchHandler) error
+	// Stop stops all informers. The client must not be used after it has been stopped
+	Stop()
+}
+
+type cachedKubeClient struct {
+	kubeClient
+	options CacheOptions
+	stopCh  chan struct{}
+
+	mu        sync.Mutex
+	factories map[factoryKey]dynamicinformer.DynamicSharedInformerFactory
+
+	hits   uint64
+	misses uint64
+}
+
+func NewCachedKubeClient(dynamic dynamic.Interface, discovery discovery.DiscoveryInterface, options CacheOptions) CachedKubeClient {
+	return &cachedKubeClient{
+		kubeClient: newKubeClient(dynamic, discovery),
+		options:    options,
+		stopCh:     make(chan struct{}),
+		factories:  map[factoryKey]dynamicinformer.DynamicSharedInformerFactory{},
+	}
+}
+
+// GetAllResources gets all supported resources from the informer caches, starting informers for any resource
+// types which have not been requested before
+func (kc *cachedKubeClient) GetAllResources(options ClientOptions) ([]k8s.Resource, error) {
+	var resources []k8s.Resource
+
+	if err := options.validateSelectors(); err != nil {
+		return nil, err
+	}
+
+	apiResources, err := kc.listableResources()
+	if err != nil {
+		return nil, err
+	}
+
+	resourceInformers := make([]informers.GenericInformer, len(apiResources))
+	warm := make([]bool, len(apiResources))
+	for i, apiResource := range apiResources {
+		if !isWatchable(apiResource) || !options.isTypeIncluded(apiResource) {
+			continue
+		}
+		resourceInformers[i] = kc.informerFor(apiResource, options)
+		warm[i] = resourceInformers[i].Informer().HasSynced()
+	}
+	kc.start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), kc.options.warmupTimeout())
+	defer cancel()
+
+	for i, apiResource := range apiResources {
+		if !options.isTypeIncluded(apiResource) {
+			continue
+		}
+		informer := resourceInformers[i]
+		if informer == nil || !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+			atomic.AddUint64(&kc.misses, 1)
+			resources = append(resources, kc.listResources(apiResource, options)...)
+			continue
+		}
+
+		if warm[i] {
+			atomic.AddUint64(&kc.hits, 1)
+		} else {
+			atomic.AddUint64(&kc.misses, 1)
+		}
+		resources = append(resources, listCachedResources(informer, apiResource, options.listNamespace())...)
+	}
+	resources = filterNamespaces(resources, options)
+
+	if !options.IncludeGenerated {
+		resources = excludeGenerated(resources, options)
+	}
+	return resources, nil
+}
+
+func (kc *cachedKubeClient) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&kc.hits),
+		Misses: atomic.LoadUint64(&kc.misses),
+	}
+}
+
+func (kc *cachedKubeClient) Stop() {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	select {
+	case <-kc.stopCh:
+	default:
+		close(kc.stopCh)
+	}
+}
+
+// factoryKey identifies the informer factory of a namespace and the selectors its informers list resources with
+type factoryKey struct {
+	namespace     string
+	labelSelector string
+	fieldSelector string
+}
+
+// informerFor returns the informer for the resource type. Namespace resources are always watched cluster-wide
+// since a namespaced informer cannot watch cluster-scoped resources. Informers of workload types only watch the
+// resources which match the selectors
+func (kc *cachedKubeClient) informerFor(apiResource listableResource, options ClientOptions) informers.GenericInformer {
+	namespace := options.listNamespace()
+	if isNamespaceResource(apiResource, namespace) {
+		namespace = ""
+	}
+	listOptions := options.listOptions(apiResource)
+	key := factoryKey{namespace: namespace, labelSelector: listOptions.LabelSelector, fieldSelector: listOptions.FieldSelector}
+
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	factory, ok := kc.factories[key]
+	if !ok {
+		factory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(kc.dynamicClient, kc.options.ResyncPeriod, namespace, func(listOptions *metav1.ListOptions) {
+			listOptions.LabelSelector = key.labelSelector
+			listOptions.FieldSelector = key.fieldSelector
+		})
+		kc.factories[key] = factory
+	}
+	return factory.ForResource(apiResource.gvr)
+}
+
+// start starts any informers which have been requested but not started yet
+func (kc *cachedKubeClient) start() {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+
+	for _, factory := range kc.factories {
+		factory.Start(kc.stopCh)
+	}
+}
+
+func listCachedResources(informer informers.GenericInformer, apiResource listableResource, namespace string) []k8s.Resource {
+	var resources []k8s.Resource
+
+	if isNamespaceResource(apiResource, namespace) {
+		obj, err := informer.Lister().Get(namespace)
+		if err != nil {
+			return nil
+		}
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			if r, err := unstructuredToObject(u.DeepCopy()); err == nil {
+				resources = append(resources, r)
+			}
+		}
+		return resources
+	}
+
+	objs, err := informer.Lister().List(labels.Everything())
+	if err != nil {
+		return nil
+	}
+	for _, obj := range objs {
+		// Objects in the cache are shared, so they are copied before being converted
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			if r, err := unstructuredToObject(u.DeepCopy()); err == nil {
+				resources = append(resources, r)
+			}
+		}
+	}
+	return resources
+}
+
+// isWatchable returns true if the resource type can be served by an informer
+func isWatchable(apiResource listableResource) bool {
+	var list, watch bool
+	for _, verb := range apiResource.resource.Verbs {
+		switch verb {
+		case "list":
+			list = true
+		case "watch":
+			watch = true
+		}
+	}
+	return list && watch
+}
@@ -0,0 +1,128 @@
+package k8sinternal_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestCachedGetAllResources(t *testing.T) {
+	resourceTemplates := []k8s.Resource{
+		k8s.NewDeployment(),
+		k8s.NewPod(),
+		k8s.NewNamespace(),
+		k8s.NewService(),
+	}
+	namespaces := []string{"foo", "bar"}
+
+	resources := make([]runtime.Object, 0, len(resourceTemplates)*len(namespaces))
+	for _, template := range resourceTemplates {
+		for _, namespace := range namespaces {
+			resource := template.DeepCopyObject()
+			setNamespace(resource, namespace)
+			resources = append(resources, resource)
+		}
+	}
+
+	dynamic, discovery := newFakeClients(nil, metav1.Verbs{"list", "watch"}, resources...)
+	client := k8sinternal.NewCachedKubeClient(dynamic, discovery, k8sinternal.CacheOptions{WarmupTimeout: 10 * time.Second})
+	defer client.Stop()
+
+	// The first audit warms up the caches
+	k8sresources, err := client.GetAllResources(k8sinternal.ClientOptions{})
+	require.NoError(t, err)
+	assert.Len(t, k8sresources, len(resourceTemplates)*len(namespaces))
+	assert.Equal(t, k8sinternal.CacheStats{Hits: 0, Misses: uint64(len(resourceTemplates))}, client.Stats())
+
+	// The second audit is served from the warm caches
+	k8sresources, err = client.GetAllResources(k8sinternal.ClientOptions{})
+	require.NoError(t, err)
+	assert.Len(t, k8sresources, len(resourceTemplates)*len(namespaces))
+	assert.Equal(t, k8sinternal.CacheStats{Hits: uint64(len(resourceTemplates)), Misses: uint64(len(resourceTemplates))}, client.Stats())
+
+	k8sresources, err = client.GetAllResources(k8sinternal.ClientOptions{Namespace: namespaces[0]})
+	require.NoError(t, err)
+	assert.Len(t, k8sresources, len(resourceTemplates))
+}
+
+func TestGetAllResourcesExcludeClusterScoped(t *testing.T) {
+	resources, templates := newNamespacesTestResources()
+	dynamic, discovery := newFakeClients(nil, metav1.Verbs{"list", "watch"}, resources...)
+	cachedClient := k8sinternal.NewCachedKubeClient(dynamic, discovery, k8sinternal.CacheOptions{WarmupTimeout: 10 * time.Second})
+	defer cachedClient.Stop()
+
+	for _, client := range []k8sinternal.KubeClient{newFakeKubeClient(resources...), cachedClient} {
+		k8sresources, err := client.GetAllResources(k8sinternal.ClientOptions{})
+		require.NoError(t, err)
+		assert.Len(t, k8sresources, len(resources))
+
+		// The namespaces are cluster-scoped, unlike the deployments and network policies
+		k8sresources, err = client.GetAllResources(k8sinternal.ClientOptions{ExcludeClusterScoped: true})
+		require.NoError(t, err)
+		assert.Len(t, k8sresources, len(resources)/templates*(templates-1))
+		for _, resource := range k8sresources {
+			assert.False(t, k8s.IsNamespaceV1(resource))
+		}
+	}
+}
+
+func TestCachedGetAllResourcesNamespaces(t *testing.T) {
+	resources, templates := newNamespacesTestResources()
+	dynamic, discovery := newFakeClients(nil, metav1.Verbs{"list", "watch"}, resources...)
+	client := k8sinternal.NewCachedKubeClient(dynamic, discovery, k8sinternal.CacheOptions{WarmupTimeout: 10 * time.Second})
+	defer client.Stop()
+
+	testNamespaces(t, client, templates)
+}
+
+func TestCachedGetAllResourcesSelector(t *testing.T) {
+	dynamic, discovery := newFakeClients(nil, metav1.Verbs{"list", "watch"}, newSelectorTestResources()...)
+	client := k8sinternal.NewCachedKubeClient(dynamic, discovery, k8sinternal.CacheOptions{WarmupTimeout: 10 * time.Second})
+	defer client.Stop()
+
+	k8sresources, err := client.GetAllResources(k8sinternal.ClientOptions{LabelSelector: "app=payments"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Deployment/payments", "Pod/payments", "Namespace/foo", "NetworkPolicy/networkpolicy"}, resourceNames(k8sresources))
+
+	// Informers are not shared between different selectors
+	k8sresources, err = client.GetAllResources(k8sinternal.ClientOptions{})
+	require.NoError(t, err)
+	assert.Len(t, k8sresources, 6)
+}
+
+func TestCachedGetAllResourcesNotWatchable(t *testing.T) {
+	dynamic, discovery := newFakeClients(nil, metav1.Verbs{"list"}, k8s.NewDeployment())
+	client := k8sinternal.NewCachedKubeClient(dynamic, discovery, k8sinternal.CacheOptions{})
+	defer client.Stop()
+
+	// Resource types which cannot be watched are always listed from the API server
+	for i := 0; i < 2; i++ {
+		k8sresources, err := client.GetAllResources(k8sinternal.ClientOptions{})
+		require.NoError(t, err)
+		assert.Len(t, k8sresources, 1)
+	}
+	assert.Equal(t, k8sinternal.CacheStats{Hits: 0, Misses: 2}, client.Stats())
+}
+
+func TestCachedGetAllResourcesWarmupTimeout(t *testing.T) {
+	dynamic, discovery := newFakeClients(nil, metav1.Verbs{"list", "watch"}, k8s.NewDeployment(), k8s.NewService())
+	// The services can never be listed, so their cache never syncs
+	dynamic.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	client := k8sinternal.NewCachedKubeClient(dynamic, discovery, k8sinternal.CacheOptions{WarmupTimeout: 100 * time.Millisecond})
+	defer client.Stop()
+
+	k8sresources, err := client.GetAllResources(k8sinternal.ClientOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deployment/"}, resourceNames(k8sresources))
+	assert.Equal(t, k8sinternal.CacheStats{Hits: 0, Misses: 2}, client.Stats())
+}
@@ -3,18 +3,30 @@ package k8sinternal
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	log "github.com/sirupsen/logrus"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 
@@ -31,6 +43,16 @@ var ErrNoReadableKubeConfig = errors.New("unable to open kubeconfig file")
 
 var DefaultClient = k8sClient{}
 
+// StdinKubeconfig is the kubeconfig path which reads the kubeconfig from stdin, for credentials which shouldn't be
+// written to disk
+const StdinKubeconfig = "-"
+
+var stdinKubeconfig struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
 // Client abstracts the API to allow testing.
 type Client interface {
 	InClusterConfig() (*rest.Config, error)
@@ -44,54 +66,228 @@ func (kc k8sClient) InClusterConfig() (*rest.Config, error) {
 	return rest.InClusterConfig()
 }
 
+// ConnectionOptions configures the connection of a client to the API server
+type ConnectionOptions struct {
+	// RequestTimeout is the maximum time of each request to the API server, like the --request-timeout flag of
+	// kubectl. Zero means no timeout.
+	RequestTimeout time.Duration
+	// QPS is the maximum number of requests per second sent to the API server. Defaults to DefaultQPS.
+	QPS float32
+	// Burst is the maximum number of requests sent to the API server at once, above QPS. Defaults to DefaultBurst.
+	Burst int
+}
+
+// DefaultQPS and DefaultBurst are the default rate limits of requests to the API server, the same as client-go
+const (
+	DefaultQPS   = rest.DefaultQPS
+	DefaultBurst = rest.DefaultBurst
+)
+
 // NewKubeClientLocal creates a new kube client for local mode
-func NewKubeClientLocal(configPath string, context string) (KubeClient, error) {
-	var kubeconfig *rest.Config
-	var err error
-
-	if configPath == "" {
-		kubeconfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-			clientcmd.NewDefaultClientConfigLoadingRules(),
-			&clientcmd.ConfigOverrides{CurrentContext: context, ClusterInfo: clientcmdapi.Cluster{Server: ""}},
-		).ClientConfig()
-	} else {
-		if _, err = os.Stat(configPath); err != nil {
-			return nil, ErrNoReadableKubeConfig
-		}
-		kubeconfig, err = clientcmd.BuildConfigFromFlags("", configPath)
+func NewKubeClientLocal(configPath string, context string, connection ConnectionOptions) (KubeClient, error) {
+	kubeconfig, err := localConfig(configPath, context, connection)
+	if err != nil {
+		return nil, err
 	}
+	return newKubeClientFromConfig(kubeconfig)
+}
 
+// NewKubeClientCluster creates a new kube client for cluster mode
+func NewKubeClientCluster(client Client, connection ConnectionOptions) (KubeClient, error) {
+	config, err := clusterConfig(client, connection)
 	if err != nil {
 		return nil, err
 	}
+	return newKubeClientFromConfig(config)
+}
 
-	// Ignore warnings from kubeclient as they are expected to be reported by the deprecatedapi auditor.
-	kubeconfig.WarningHandler = rest.NoWarnings{}
+// NewCachedKubeClientLocal creates a new cached kube client for local mode
+func NewCachedKubeClientLocal(configPath string, context string, connection ConnectionOptions, options CacheOptions) (CachedKubeClient, error) {
+	kubeconfig, err := localConfig(configPath, context, connection)
+	if err != nil {
+		return nil, err
+	}
+	dynamic, discovery, err := newClientsFromConfig(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	return NewCachedKubeClient(dynamic, discovery, options), nil
+}
 
-	return newKubeClientFromConfig(kubeconfig)
+// NewCachedKubeClientCluster creates a new cached kube client for cluster mode
+func NewCachedKubeClientCluster(client Client, connection ConnectionOptions, options CacheOptions) (CachedKubeClient, error) {
+	config, err := clusterConfig(client, connection)
+	if err != nil {
+		return nil, err
+	}
+	dynamic, discovery, err := newClientsFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewCachedKubeClient(dynamic, discovery, options), nil
 }
 
-// NewKubeClientCluster creates a new kube client for cluster mode
-func NewKubeClientCluster(client Client) (KubeClient, error) {
+// clusterConfig loads the in-cluster config for cluster mode
+func clusterConfig(client Client, connection ConnectionOptions) (*rest.Config, error) {
 	config, err := client.InClusterConfig()
 	if err != nil {
 		return nil, err
 	}
 	log.Info("Running inside cluster, using the cluster config")
-	return newKubeClientFromConfig(config)
+	configure(config, connection)
+	return config, nil
+}
+
+// localConfig loads the kubeconfig for local mode, see ClientConfig. The client config is built by clientcmd like
+// kubectl builds it, so the credentials of auth provider and exec credential plugins, such as OIDC, `aws eks get-token`
+// and gke-gcloud-auth-plugin, are refreshed by the client when they expire, including during long watch runs
+func localConfig(configPath string, context string, connection ConnectionOptions) (*rest.Config, error) {
+	clientConfig, err := ClientConfig(configPath, context)
+	if err != nil {
+		return nil, err
+	}
+
+	kubeconfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if configPath == StdinKubeconfig {
+		// Stdin was used up by the kubeconfig, so exec plugins can't prompt for input, and the credentials refreshed by
+		// auth providers can't be written back to the kubeconfig
+		if kubeconfig.ExecProvider != nil {
+			kubeconfig.ExecProvider.StdinUnavailable = true
+			kubeconfig.ExecProvider.StdinUnavailableMessage = "the kubeconfig was read from stdin"
+		}
+		if kubeconfig.AuthProvider != nil && kubeconfig.AuthConfigPersister == nil {
+			kubeconfig.AuthConfigPersister = &memoryPersister{}
+		}
+	}
+	configure(kubeconfig, connection)
+
+	return kubeconfig, nil
+}
+
+// configure applies the connection options to the client config
+func configure(config *rest.Config, connection ConnectionOptions) {
+	config.Timeout = connection.RequestTimeout
+	config.QPS = connection.QPS
+	if config.QPS == 0 {
+		config.QPS = DefaultQPS
+	}
+	config.Burst = connection.Burst
+	if config.Burst == 0 {
+		config.Burst = DefaultBurst
+	}
+	// Ignore warnings from kubeclient as they are expected to be reported by the deprecatedapi auditor.
+	config.WarningHandler = rest.NoWarnings{}
+}
+
+// memoryPersister keeps the config of an auth provider, such as the tokens refreshed by the OIDC auth provider, in
+// memory for the kubeconfigs which can't be written to
+type memoryPersister struct {
+	mu     sync.Mutex
+	config map[string]string
+}
+
+func (p *memoryPersister) Persist(config map[string]string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config = config
+	return nil
+}
+
+// ClientConfig returns the kubeconfig for local mode with the loading rules of kubectl. The kubeconfig is read from
+// configPath, or from stdin if configPath is StdinKubeconfig. If no path is provided, the files listed in the KUBECONFIG
+// environment variable are merged in order (the first file to set a value wins), or $HOME/.kube/config is used
+func ClientConfig(configPath string, context string) (clientcmd.ClientConfig, error) {
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context, ClusterInfo: clientcmdapi.Cluster{Server: ""}}
+
+	if configPath == StdinKubeconfig {
+		data, err := readStdinKubeconfig()
+		if err != nil {
+			return nil, fmt.Errorf("error reading the kubeconfig from stdin: %w", err)
+		}
+		config, err := clientcmd.Load(data)
+		if err != nil {
+			return nil, fmt.Errorf("error loading the kubeconfig from stdin: %w", err)
+		}
+		return clientcmd.NewNonInteractiveClientConfig(*config, context, overrides, nil), nil
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if configPath != "" {
+		if _, err := os.Stat(configPath); err != nil {
+			return nil, ErrNoReadableKubeConfig
+		}
+		loadingRules.ExplicitPath = configPath
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides), nil
+}
+
+// Contexts returns the names of the contexts of the kubeconfig for local mode, sorted by name. The kubeconfig is loaded
+// like ClientConfig() loads it, so the contexts of every file listed in the KUBECONFIG environment variable are included
+func Contexts(configPath string) ([]string, error) {
+	clientConfig, err := ClientConfig(configPath, "")
+	if err != nil {
+		return nil, err
+	}
+	config, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		contexts = append(contexts, name)
+	}
+	sort.Strings(contexts)
+	return contexts, nil
+}
+
+// readStdinKubeconfig reads the kubeconfig from stdin. Stdin can only be read once, so the kubeconfig is kept for the
+// clients created after the first one, such as the client which applies fixes
+func readStdinKubeconfig() ([]byte, error) {
+	stdinKubeconfig.once.Do(func() {
+		stdinKubeconfig.data, stdinKubeconfig.err = io.ReadAll(os.Stdin)
+	})
+	return stdinKubeconfig.data, stdinKubeconfig.err
+}
+
+// UseInClusterConfig returns true if the in-cluster config should be used to connect to the cluster, which is when
+// kubeaudit runs inside a cluster and no kubeconfig is found. Like kubectl, the kubeconfig set with configPath, the
+// KUBECONFIG environment variable or $HOME/.kube/config take precedence over the in-cluster config
+func UseInClusterConfig(client Client, configPath string) bool {
+	if configPath != "" {
+		return false
+	}
+	for _, path := range clientcmd.NewDefaultClientConfigLoadingRules().GetLoadingPrecedence() {
+		if _, err := os.Stat(path); err == nil {
+			return false
+		}
+	}
+	return IsRunningInCluster(client)
 }
 
 // newKubeClientFromConfig creates a new dynamic client with discovery or returns an error.
 func newKubeClientFromConfig(config *rest.Config) (KubeClient, error) {
-	discovery, err := discovery.NewDiscoveryClientForConfig(config)
+	dynamic, discovery, err := newClientsFromConfig(config)
 	if err != nil {
 		return nil, err
 	}
+	return NewKubeClient(dynamic, discovery), nil
+}
+
+func newClientsFromConfig(config *rest.Config) (dynamic.Interface, discovery.DiscoveryInterface, error) {
+	discovery, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, nil, err
+	}
 	dynamic, err := dynamic.NewForConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return NewKubeClient(dynamic, discovery), nil
+	return dynamic, discovery, nil
 }
 
 // IsRunningInCluster returns true if kubeaudit is running inside a cluster
@@ -101,10 +297,143 @@ func IsRunningInCluster(client Client) bool {
 }
 
 type ClientOptions struct {
-	// Namespace filters resources by namespace. Defaults to all namespaces.
+	// ConnectionOptions configure the connection to the API server in local and cluster mode.
+	ConnectionOptions
+	// Namespace filters resources by namespace. Multiple namespaces are separated by commas (eg. "team-a,team-b").
+	// Defaults to all namespaces.
 	Namespace string
+	// ExcludedNamespaces filters out the resources of the namespaces, and the namespaces themselves.
+	ExcludedNamespaces []string
 	// IncludeGenerated is a boolean option to include generated resources.
 	IncludeGenerated bool
+	// IncludeJobHistory includes the Jobs created by CronJobs, which are generated resources. CronJobs keep the Jobs
+	// they created, up to their successfulJobsHistoryLimit and failedJobsHistoryLimit, and the Jobs keep the spec of
+	// the jobTemplate they were created from even after the jobTemplate changes.
+	IncludeJobHistory bool
+	// ExcludeClusterScoped leaves out the resources of cluster-scoped types, such as namespaces, ClusterRoles and
+	// admission webhook configurations, so they are neither listed nor audited.
+	ExcludeClusterScoped bool
+	// LabelSelector only includes the workloads, ie. resources with a PodSpec, whose labels match the selector. The
+	// selector has the same syntax as kubectl (eg. "app=payments,tier!=frontend"). Defaults to all workloads.
+	LabelSelector string
+	// FieldSelector only includes the workloads whose fields match the selector, in the same syntax as kubectl (eg.
+	// "metadata.name=payments"). Workload types which don't support a field of the selector are left out, so for
+	// example "spec.nodeName=node-1" only includes pods. Defaults to all workloads.
+	FieldSelector string
+	// PriorityNamespaces are audited and reported first, in the order they are listed. The resources of the other
+	// namespaces are interleaved so no namespace dominates the start of the report.
+	PriorityNamespaces []string
+	// ChunkSize is the maximum number of resources returned by each list call to the API server, like the --chunk-size
+	// flag of kubectl. Large lists are fetched in chunks and the resources which are not audited, such as generated
+	// pods, are left out of each chunk, so the whole list is never held in memory. Defaults to DefaultChunkSize.
+	ChunkSize int64
+	// CacheWarmupTimeout is the maximum time to wait for the informer caches to sync when resources are watched.
+	// Defaults to DefaultCacheWarmupTimeout.
+	CacheWarmupTimeout time.Duration
+}
+
+// DefaultChunkSize is the default number of resources returned by each list call, the same as kubectl
+const DefaultChunkSize = 500
+
+// chunkSize returns the number of resources to list from the API server in each call
+func (options ClientOptions) chunkSize() int64 {
+	if options.ChunkSize <= 0 {
+		return DefaultChunkSize
+	}
+	return options.ChunkSize
+}
+
+// isIncluded returns true if the resource belongs to the audited namespaces and is not a generated resource, unless
+// generated resources are included
+func (options ClientOptions) isIncluded(resource k8s.Resource) bool {
+	if !options.isNamespaceIncluded(resource) {
+		return false
+	}
+	return options.IncludeGenerated || len(excludeGenerated([]k8s.Resource{resource}, options)) > 0
+}
+
+// isTypeIncluded returns true if the resources of the type are audited, which is the case for every type unless
+// cluster-scoped types are excluded
+func (options ClientOptions) isTypeIncluded(apiResource listableResource) bool {
+	return apiResource.resource.Namespaced || !options.ExcludeClusterScoped
+}
+
+// namespaces returns the namespaces to audit, or nil to audit all namespaces
+func (options ClientOptions) namespaces() []string {
+	var namespaces []string
+	for _, namespace := range strings.Split(options.Namespace, ",") {
+		if namespace = strings.TrimSpace(namespace); namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	return namespaces
+}
+
+// listNamespace returns the namespace to list resources from. Resources are listed from all namespaces if several
+// namespaces are audited, and the resources of the other namespaces are then left out by filterNamespaces
+func (options ClientOptions) listNamespace() string {
+	if namespaces := options.namespaces(); len(namespaces) == 1 {
+		return namespaces[0]
+	}
+	return ""
+}
+
+// isNamespaceIncluded returns true if the resource belongs to one of the audited namespaces and not to an excluded
+// namespace
+func (options ClientOptions) isNamespaceIncluded(resource k8s.Resource) bool {
+	namespace := ResourceNamespace(resource)
+	for _, excluded := range options.ExcludedNamespaces {
+		if namespace == excluded {
+			return false
+		}
+	}
+
+	namespaces := options.namespaces()
+	if len(namespaces) == 0 {
+		return true
+	}
+	for _, included := range namespaces {
+		if namespace == included {
+			return true
+		}
+	}
+	return false
+}
+
+// filterNamespaces leaves out the resources which don't belong to the audited namespaces or belong to an excluded
+// namespace
+func filterNamespaces(resources []k8s.Resource, options ClientOptions) []k8s.Resource {
+	if len(options.namespaces()) == 0 && len(options.ExcludedNamespaces) == 0 {
+		return resources
+	}
+
+	var filteredResources []k8s.Resource
+	for _, resource := range resources {
+		if options.isNamespaceIncluded(resource) {
+			filteredResources = append(filteredResources, resource)
+		}
+	}
+	return filteredResources
+}
+
+// validateSelectors returns an error if the label or field selector can't be parsed
+func (options ClientOptions) validateSelectors() error {
+	if _, err := labels.Parse(options.LabelSelector); err != nil {
+		return fmt.Errorf("invalid label selector %q: %w", options.LabelSelector, err)
+	}
+	if _, err := fields.ParseSelector(options.FieldSelector); err != nil {
+		return fmt.Errorf("invalid field selector %q: %w", options.FieldSelector, err)
+	}
+	return nil
+}
+
+// listOptions returns the options to list resources of the type with. Selectors only apply to workload types, so the
+// workloads are still audited against the other resources, such as namespaces and network policies
+func (options ClientOptions) listOptions(apiResource listableResource) metav1.ListOptions {
+	if !isWorkloadType(apiResource) {
+		return metav1.ListOptions{}
+	}
+	return metav1.ListOptions{LabelSelector: options.LabelSelector, FieldSelector: options.FieldSelector}
 }
 
 type KubeClient interface {
@@ -114,81 +443,168 @@ type KubeClient interface {
 	GetKubernetesVersion() (*version.Info, error)
 	// ServerPreferredResources returns the supported resources with the version preferred by the server.
 	ServerPreferredResources() ([]*metav1.APIResourceList, error)
+	// Apply applies the resource to the cluster with server-side apply and returns the applied resource
+	Apply(resource k8s.Resource, options ApplyOptions) (k8s.Resource, error)
 }
 
 type kubeClient struct {
 	dynamicClient   dynamic.Interface
 	discoveryClient discovery.DiscoveryInterface
+	// mapper maps the kinds of applied resources to their resource types. It is only populated once a resource is
+	// applied
+	mapper meta.RESTMapper
 }
 
 func NewKubeClient(dynamic dynamic.Interface, discovery discovery.DiscoveryInterface) KubeClient {
-	return &kubeClient{dynamicClient: dynamic, discoveryClient: discovery}
+	kubeClient := newKubeClient(dynamic, discovery)
+	return &kubeClient
+}
+
+func newKubeClient(dynamic dynamic.Interface, discovery discovery.DiscoveryInterface) kubeClient {
+	return kubeClient{
+		dynamicClient:   dynamic,
+		discoveryClient: discovery,
+		mapper:          restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discovery)),
+	}
 }
 
 // GetAllResources gets all supported resources from the cluster
 func (kc kubeClient) GetAllResources(options ClientOptions) ([]k8s.Resource, error) {
 	var resources []k8s.Resource
 
+	if err := options.validateSelectors(); err != nil {
+		return nil, err
+	}
+
+	apiResources, err := kc.listableResources()
+	if err != nil {
+		return nil, err
+	}
+	for _, apiResource := range apiResources {
+		if options.isTypeIncluded(apiResource) {
+			resources = append(resources, kc.listResources(apiResource, options)...)
+		}
+	}
+	return resources, nil
+}
+
+// listableResource is a resource type served by the cluster along with its group version resource
+type listableResource struct {
+	gvr      schema.GroupVersionResource
+	resource metav1.APIResource
+}
+
+// listableResources returns the resource types supported by the server which have at least one verb
+func (kc kubeClient) listableResources() ([]listableResource, error) {
+	var apiResources []listableResource
+
 	lists, err := kc.ServerPreferredResources()
 	if err != nil {
 		return nil, err
 	}
-	if lists != nil {
-		for _, list := range lists {
-			if len(list.APIResources) == 0 {
+	for _, list := range lists {
+		if len(list.APIResources) == 0 {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, apiresource := range list.APIResources {
+			if len(apiresource.Verbs) == 0 {
 				continue
 			}
-			gv, err := schema.ParseGroupVersion(list.GroupVersion)
-			if err != nil {
-				continue
+			gvr := schema.GroupVersionResource{Group: gv.Group, Version: gv.Version, Resource: apiresource.Name}
+			apiResources = append(apiResources, listableResource{gvr: gvr, resource: apiresource})
+		}
+	}
+
+	return apiResources, nil
+}
+
+// listResources lists all resources of the given type directly from the API server, in chunks of at most
+// options.ChunkSize resources. Only the resources included by the options are kept from each chunk. Resources that
+// fail to be listed or converted are skipped
+func (kc kubeClient) listResources(apiResource listableResource, options ClientOptions) []k8s.Resource {
+	var resources []k8s.Resource
+	namespace := options.listNamespace()
+
+	// Namespace has to be included as a resource to audit if it is specified.
+	if isNamespaceResource(apiResource, namespace) {
+		unstructured, err := kc.dynamicClient.Resource(apiResource.gvr).Get(context.Background(), namespace, metav1.GetOptions{})
+		if err == nil {
+			r, err := unstructuredToObject(unstructured)
+			if err == nil && options.isIncluded(r) {
+				resources = append(resources, r)
 			}
-			for _, apiresource := range list.APIResources {
-				if len(apiresource.Verbs) == 0 {
-					continue
-				}
-				gvr := schema.GroupVersionResource{Group: gv.Group, Version: gv.Version, Resource: apiresource.Name}
-
-				// Namespace has to be included as a resource to audit if it is specified.
-				if apiresource.Name == "namespaces" && options.Namespace != "" {
-					unstructured, err := kc.dynamicClient.Resource(gvr).Get(context.Background(), options.Namespace, metav1.GetOptions{})
-					if err == nil {
-						r, err := unstructuredToObject(unstructured)
-						if err == nil {
-							resources = append(resources, r)
-						}
-					}
-				} else {
-					unstructuredList, err := kc.dynamicClient.Resource(gvr).Namespace(options.Namespace).List(context.Background(), metav1.ListOptions{})
-					if err == nil {
-						for _, unstructured := range unstructuredList.Items {
-							r, err := unstructuredToObject(&unstructured)
-							if err == nil {
-								resources = append(resources, r)
-							}
-						}
-					}
-				}
+		}
+		return resources
+	}
+
+	listOptions := options.listOptions(apiResource)
+	listOptions.Limit = options.chunkSize()
+	for {
+		unstructuredList, err := kc.dynamicClient.Resource(apiResource.gvr).Namespace(namespace).List(context.Background(), listOptions)
+		if err != nil {
+			// The resources of the chunks listed so far are kept, as when a chunk can't be listed because its
+			// continue token expired on a busy cluster
+			if listOptions.Continue != "" {
+				log.WithError(err).Warnf("Error listing the next chunk of %s, only the %s listed so far are audited", apiResource.gvr.Resource, apiResource.gvr.Resource)
+			}
+			return resources
+		}
+		for _, unstructured := range unstructuredList.Items {
+			r, err := unstructuredToObject(&unstructured)
+			if err == nil && options.isIncluded(r) {
+				resources = append(resources, r)
 			}
 		}
+		if unstructuredList.GetContinue() == "" {
+			return resources
+		}
+		listOptions.Continue = unstructuredList.GetContinue()
 	}
+}
 
-	if !options.IncludeGenerated {
-		resources = excludeGenerated(resources)
+// isNamespaceResource returns true if the resource type is Namespace and resources are filtered by namespace, in
+// which case only the specified namespace is fetched
+func isNamespaceResource(apiResource listableResource, namespace string) bool {
+	return apiResource.resource.Name == "namespaces" && namespace != ""
+}
+
+// isWorkloadType returns true if resources of the type have a PodSpec, including custom resources of kinds
+// registered with k8s.RegisterPodSpecExtractor
+func isWorkloadType(apiResource listableResource) bool {
+	gvk := apiResource.gvr.GroupVersion().WithKind(apiResource.resource.Kind)
+	if obj, err := scheme.New(gvk); err == nil {
+		return k8s.GetPodSpec(obj) != nil
 	}
-	return resources, nil
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	_, ok, _ := k8s.NewCustomResource(u)
+	return ok
 }
 
-// unstructuredToObject unstructured to Go typed object conversions
+// unstructuredToObject unstructured to Go typed object conversions. Custom resources of kinds registered with
+// k8s.RegisterPodSpecExtractor are converted to k8s.CustomResource
 func unstructuredToObject(unstructured *unstructured.Unstructured) (k8s.Resource, error) {
 	obj, err := scheme.New(unstructured.GroupVersionKind())
+	if runtime.IsNotRegisteredError(err) {
+		if customResource, ok, err := k8s.NewCustomResource(unstructured); ok {
+			return customResource, err
+		}
+	}
 	if err == nil {
 		err = runtime.DefaultUnstructuredConverter.FromUnstructured(unstructured.UnstructuredContent(), obj)
 	}
 	return obj, err
 }
 
-// excludeGenerated filters out generated resources (eg. pods generated by deployments)
-func excludeGenerated(resources []k8s.Resource) []k8s.Resource {
+// excludeGenerated filters out generated resources (eg. pods generated by deployments). Generated pods with ephemeral
+// containers are kept since the debug containers added to them are not part of the resource they are generated from,
+// and so are the Jobs created by CronJobs if the job history is included
+func excludeGenerated(resources []k8s.Resource, options ClientOptions) []k8s.Resource {
 	var filteredResources []k8s.Resource
 	for _, resource := range resources {
 		if resource != nil {
@@ -196,7 +612,8 @@ func excludeGenerated(resources []k8s.Resource) []k8s.Resource {
 			if obj != nil {
 				meta := obj.GetObjectMeta()
 				if meta != nil {
-					if len(meta.GetOwnerReferences()) == 0 {
+					if !IsGenerated(meta) || len(k8s.GetEphemeralContainers(resource)) > 0 ||
+						(options.IncludeJobHistory && k8s.GetCronJobName(resource) != "") {
 						filteredResources = append(filteredResources, resource)
 					}
 				}
@@ -206,6 +623,37 @@ func excludeGenerated(resources []k8s.Resource) []k8s.Resource {
 	return filteredResources
 }
 
+// ResourceNamespace returns the namespace of the resource, or the name of the resource if it is a namespace
+func ResourceNamespace(resource k8s.Resource) string {
+	objectMeta := k8s.GetObjectMeta(resource)
+	if objectMeta == nil {
+		return ""
+	}
+	if k8s.IsNamespaceV1(resource) {
+		return objectMeta.GetName()
+	}
+	return objectMeta.GetNamespace()
+}
+
+// IsGenerated returns true if the resource is generated from another resource (eg. a pod generated by a deployment)
+func IsGenerated(meta metav1.Object) bool {
+	return len(meta.GetOwnerReferences()) > 0 && !isMirrorPod(meta)
+}
+
+// isMirrorPod returns true if the resource is the mirror pod of a static pod (such as the kube-apiserver or etcd).
+// Mirror pods are owned by their node but are not generated from another resource, so they are not excluded
+func isMirrorPod(meta metav1.Object) bool {
+	if _, ok := meta.GetAnnotations()[apiv1.MirrorPodAnnotationKey]; !ok {
+		return false
+	}
+	for _, ownerReference := range meta.GetOwnerReferences() {
+		if ownerReference.Kind != "Node" {
+			return false
+		}
+	}
+	return true
+}
+
 // GetKubernetesVersion returns the kubernetes client version
 func (kc kubeClient) GetKubernetesVersion() (*version.Info, error) {
 	return kc.discoveryClient.ServerVersion()
@@ -1,8 +1,17 @@
 package k8sinternal_test
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Shopify/kubeaudit/internal/k8sinternal"
 	"github.com/Shopify/kubeaudit/internal/test"
@@ -10,6 +19,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -17,6 +27,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/version"
 	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
 	fakedynamic "k8s.io/client-go/dynamic/fake"
 	fakeclientset "k8s.io/client-go/kubernetes/fake"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/azure" // auth for AKS clusters
@@ -37,27 +48,191 @@ func (kc *MockK8sClient) InClusterConfig() (*rest.Config, error) {
 func TestKubeClientConfigLocal(t *testing.T) {
 	assert := assert.New(t)
 
-	_, err := k8sinternal.NewKubeClientLocal("/notarealfile", "")
+	_, err := k8sinternal.NewKubeClientLocal("/notarealfile", "", k8sinternal.ConnectionOptions{})
 	assert.Equal(k8sinternal.ErrNoReadableKubeConfig, err)
 
-	_, err = k8sinternal.NewKubeClientLocal("client.go", "")
+	_, err = k8sinternal.NewKubeClientLocal("client.go", "", k8sinternal.ConnectionOptions{})
 	assert.NotEqual(k8sinternal.ErrNoReadableKubeConfig, err)
 	assert.NotNil(err)
 }
 
+func kubeconfig(cluster, currentContext string) string {
+	return fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: %[1]s
+  cluster:
+    server: https://%[1]s.example.com
+users:
+- name: %[1]s
+  user:
+    token: secret
+contexts:
+- name: %[1]s
+  context:
+    cluster: %[1]s
+    user: %[1]s
+current-context: %[2]s
+`, cluster, currentContext)
+}
+
+func writeKubeconfig(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestClientConfigKubeconfigList(t *testing.T) {
+	dev := writeKubeconfig(t, "dev", kubeconfig("dev", "dev"))
+	prod := writeKubeconfig(t, "prod", kubeconfig("prod", "prod"))
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("KUBECONFIG", strings.Join([]string{missing, dev, prod}, string(filepath.ListSeparator)))
+
+	// The first file to set the current context wins, the contexts of every file can be used
+	clientConfig, err := k8sinternal.ClientConfig("", "")
+	require.NoError(t, err)
+	config, err := clientConfig.ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://dev.example.com", config.Host)
+
+	clientConfig, err = k8sinternal.ClientConfig("", "prod")
+	require.NoError(t, err)
+	config, err = clientConfig.ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://prod.example.com", config.Host)
+
+	// The kubeconfig path takes precedence over KUBECONFIG
+	clientConfig, err = k8sinternal.ClientConfig(prod, "")
+	require.NoError(t, err)
+	config, err = clientConfig.ClientConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://prod.example.com", config.Host)
+}
+
+func TestContexts(t *testing.T) {
+	dev := writeKubeconfig(t, "dev", kubeconfig("dev", "dev"))
+	prod := writeKubeconfig(t, "prod", kubeconfig("prod", "prod"))
+	t.Setenv("KUBECONFIG", strings.Join([]string{prod, dev}, string(filepath.ListSeparator)))
+
+	contexts, err := k8sinternal.Contexts("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dev", "prod"}, contexts)
+
+	contexts, err = k8sinternal.Contexts(prod)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod"}, contexts)
+
+	_, err = k8sinternal.Contexts("/notarealfile")
+	assert.Equal(t, k8sinternal.ErrNoReadableKubeConfig, err)
+}
+
+func TestClientConfigStdin(t *testing.T) {
+	stdin, err := os.Open(writeKubeconfig(t, "stdin", kubeconfig("ci", "ci")))
+	require.NoError(t, err)
+	defer stdin.Close()
+	defer func(original *os.File) { os.Stdin = original }(os.Stdin)
+	os.Stdin = stdin
+
+	// Stdin is only read once, so every client uses the same kubeconfig
+	for i := 0; i < 2; i++ {
+		clientConfig, err := k8sinternal.ClientConfig(k8sinternal.StdinKubeconfig, "")
+		require.NoError(t, err)
+		config, err := clientConfig.ClientConfig()
+		require.NoError(t, err)
+		assert.Equal(t, "https://ci.example.com", config.Host)
+	}
+}
+
+func TestKubeClientLocalExecCredentials(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer exec-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(version.Info{Major: "1", Minor: "24", GitVersion: "v1.24.0"})
+	}))
+	defer server.Close()
+
+	// The credentials of exec plugins, such as `aws eks get-token`, are fetched by the client
+	plugin := writeKubeconfig(t, "plugin", `#!/bin/sh
+echo '{"apiVersion": "client.authentication.k8s.io/v1beta1", "kind": "ExecCredential", "status": {"token": "exec-token"}}'
+`)
+	require.NoError(t, os.Chmod(plugin, 0700))
+	cluster := writeKubeconfig(t, "cluster", fmt.Sprintf(`apiVersion: v1
+kind: Config
+clusters:
+- name: eks
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+contexts:
+- name: eks
+  context:
+    cluster: eks
+    user: eks
+current-context: eks
+`, server.URL))
+	user := writeKubeconfig(t, "user", fmt.Sprintf(`apiVersion: v1
+kind: Config
+users:
+- name: eks
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      command: %s
+      interactiveMode: Never
+`, plugin))
+	t.Setenv("KUBECONFIG", strings.Join([]string{cluster, user}, string(filepath.ListSeparator)))
+
+	client, err := k8sinternal.NewKubeClientLocal("", "", k8sinternal.ConnectionOptions{RequestTimeout: 10 * time.Second, QPS: 50, Burst: 100})
+	require.NoError(t, err)
+	info, err := client.GetKubernetesVersion()
+	require.NoError(t, err)
+	assert.Equal(t, "v1.24.0", info.GitVersion)
+}
+
+func TestKubeClientLocalRequestTimeout(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	path := writeKubeconfig(t, "slow", strings.Replace(kubeconfig("slow", "slow"), "https://slow.example.com", server.URL, 1))
+	client, err := k8sinternal.NewKubeClientLocal(path, "", k8sinternal.ConnectionOptions{RequestTimeout: 100 * time.Millisecond})
+	require.NoError(t, err)
+	_, err = client.GetKubernetesVersion()
+	assert.Error(t, err)
+}
+
+func TestUseInClusterConfig(t *testing.T) {
+	client := &MockK8sClient{}
+	client.On("InClusterConfig").Return(&rest.Config{}, nil)
+
+	t.Setenv("KUBECONFIG", filepath.Join(t.TempDir(), "missing"))
+	assert.True(t, k8sinternal.UseInClusterConfig(client, ""))
+	assert.False(t, k8sinternal.UseInClusterConfig(client, "kubeconfig"))
+	assert.False(t, k8sinternal.UseInClusterConfig(client, k8sinternal.StdinKubeconfig))
+
+	// Like kubectl, a kubeconfig found with KUBECONFIG takes precedence over the in-cluster config
+	t.Setenv("KUBECONFIG", writeKubeconfig(t, "dev", kubeconfig("dev", "dev")))
+	assert.False(t, k8sinternal.UseInClusterConfig(client, ""))
+}
+
 func TestKubeClientConfigCluster(t *testing.T) {
 	assert := assert.New(t)
 
 	client := &MockK8sClient{}
 	var config *rest.Config = nil
 	client.On("InClusterConfig").Return(config, errors.New("mock error"))
-	kubeclient, err := k8sinternal.NewKubeClientCluster(client)
+	kubeclient, err := k8sinternal.NewKubeClientCluster(client, k8sinternal.ConnectionOptions{})
 	assert.Nil(kubeclient)
 	assert.NotNil(err)
 
 	client = &MockK8sClient{}
 	client.On("InClusterConfig").Return(&rest.Config{}, nil)
-	kubeclient, err = k8sinternal.NewKubeClientCluster(client)
+	kubeclient, err = k8sinternal.NewKubeClientCluster(client, k8sinternal.ConnectionOptions{})
 	assert.NotNil(kubeclient)
 	assert.NoError(err)
 }
@@ -111,6 +286,111 @@ func TestGetAllResources(t *testing.T) {
 	assert.Len(t, k8sresources, len(resourceTemplates))
 }
 
+func TestGetAllResourcesNamespaces(t *testing.T) {
+	resources, templates := newNamespacesTestResources()
+
+	testNamespaces(t, newFakeKubeClient(resources...), templates)
+}
+
+// testNamespaces checks that a client only returns the resources of the audited namespaces, given resources created
+// from the templates in the foo, bar and baz namespaces
+func testNamespaces(t *testing.T, client k8sinternal.KubeClient, templates int) {
+	cases := []struct {
+		testName          string
+		options           k8sinternal.ClientOptions
+		expectedNamespace []string
+	}{
+		{"All namespaces", k8sinternal.ClientOptions{}, []string{"bar", "baz", "foo"}},
+		{"Single namespace", k8sinternal.ClientOptions{Namespace: "foo"}, []string{"foo"}},
+		{"Multiple namespaces", k8sinternal.ClientOptions{Namespace: "foo, bar"}, []string{"bar", "foo"}},
+		{"Excluded namespace", k8sinternal.ClientOptions{ExcludedNamespaces: []string{"foo"}}, []string{"bar", "baz"}},
+		{"Multiple and excluded namespaces", k8sinternal.ClientOptions{Namespace: "foo,bar", ExcludedNamespaces: []string{"bar"}}, []string{"foo"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			k8sresources, err := client.GetAllResources(tc.options)
+			require.NoError(t, err)
+			assert.Len(t, k8sresources, templates*len(tc.expectedNamespace))
+
+			namespaces := map[string]bool{}
+			for _, resource := range k8sresources {
+				namespaces[k8sinternal.ResourceNamespace(resource)] = true
+			}
+			assert.Len(t, namespaces, len(tc.expectedNamespace))
+			for _, namespace := range tc.expectedNamespace {
+				assert.True(t, namespaces[namespace], namespace)
+			}
+		})
+	}
+}
+
+// newNamespacesTestResources returns a namespace, a deployment and a network policy in each of the foo, bar and baz
+// namespaces, and the number of resources per namespace
+func newNamespacesTestResources() ([]runtime.Object, int) {
+	resourceTemplates := []k8s.Resource{k8s.NewNamespace(), k8s.NewDeployment(), k8s.NewNetworkPolicy()}
+
+	var resources []runtime.Object
+	for _, template := range resourceTemplates {
+		for _, namespace := range []string{"foo", "bar", "baz"} {
+			resource := template.DeepCopyObject()
+			setNamespace(resource, namespace)
+			resources = append(resources, resource)
+		}
+	}
+	return resources, len(resourceTemplates)
+}
+
+func TestGetAllResourcesSelector(t *testing.T) {
+	client := newFakeKubeClient(newSelectorTestResources()...)
+
+	// Only the workloads are filtered, the namespace and the network policy are audited against
+	k8sresources, err := client.GetAllResources(k8sinternal.ClientOptions{LabelSelector: "app=payments"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Deployment/payments", "Pod/payments", "Namespace/foo", "NetworkPolicy/networkpolicy"}, resourceNames(k8sresources))
+
+	_, err = client.GetAllResources(k8sinternal.ClientOptions{LabelSelector: "app in (payments"})
+	assert.Error(t, err)
+	_, err = client.GetAllResources(k8sinternal.ClientOptions{FieldSelector: "metadata.name"})
+	assert.Error(t, err)
+}
+
+// newSelectorTestResources returns a deployment and a pod of two apps, and a namespace and a network policy without
+// labels
+func newSelectorTestResources() []runtime.Object {
+	var resources []runtime.Object
+	for _, app := range []string{"payments", "other"} {
+		deployment := k8s.NewDeployment()
+		deployment.Name = app
+		deployment.Namespace = "foo"
+		deployment.Labels = map[string]string{"app": app}
+
+		pod := k8s.NewPod()
+		pod.Name = app
+		pod.Namespace = "foo"
+		pod.Labels = map[string]string{"app": app}
+
+		resources = append(resources, deployment, pod)
+	}
+
+	namespace := k8s.NewNamespace()
+	namespace.Name = "foo"
+
+	networkPolicy := k8s.NewNetworkPolicy()
+	networkPolicy.Name = "networkpolicy"
+	networkPolicy.Namespace = "foo"
+
+	return append(resources, namespace, networkPolicy)
+}
+
+func resourceNames(resources []k8s.Resource) []string {
+	var names []string
+	for _, resource := range resources {
+		names = append(names, resource.GetObjectKind().GroupVersionKind().Kind+"/"+k8s.GetObjectMeta(resource).GetName())
+	}
+	return names
+}
+
 func setNamespace(resource k8s.Resource, namespace string) {
 	if _, ok := resource.(*k8s.NamespaceV1); ok {
 		k8s.GetObjectMeta(resource).SetName(namespace)
@@ -119,6 +399,48 @@ func setNamespace(resource k8s.Resource, namespace string) {
 	}
 }
 
+func TestGetAllResourcesChunks(t *testing.T) {
+	deployment := k8s.NewDeployment()
+	_, fakeDiscovery := newFakeClients(nil, metav1.Verbs{"list"}, deployment)
+
+	// The fake dynamic client doesn't support chunks, so the chunks are served by the API of a test server
+	chunks := [][]string{{"deployment1", "generated1"}, {"generated2"}, {"deployment2"}}
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+		chunk, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Query().Get("continue"), "chunk"))
+
+		list := &unstructured.UnstructuredList{}
+		list.SetAPIVersion("apps/v1")
+		list.SetKind("DeploymentList")
+		for _, name := range chunks[chunk] {
+			item := unstructured.Unstructured{}
+			item.SetGroupVersionKind(deployment.GroupVersionKind())
+			item.SetName(name)
+			item.SetNamespace("foo")
+			if strings.HasPrefix(name, "generated") {
+				item.SetOwnerReferences([]metav1.OwnerReference{{Kind: "Deployment", Name: "owner"}})
+			}
+			list.Items = append(list.Items, item)
+		}
+		if chunk < len(chunks)-1 {
+			list.SetContinue(fmt.Sprintf("chunk%d", chunk+1))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(list))
+	}))
+	defer server.Close()
+
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	client := k8sinternal.NewKubeClient(dynamicClient, fakeDiscovery)
+
+	resources, err := client.GetAllResources(k8sinternal.ClientOptions{ChunkSize: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deployment/deployment1", "Deployment/deployment2"}, resourceNames(resources))
+	assert.Equal(t, []string{"limit=2", "continue=chunk1&limit=2", "continue=chunk2&limit=2"}, requests)
+}
+
 func TestGetKubernetesVersion(t *testing.T) {
 	serverVersion := &version.Info{
 		Major:     "0",
@@ -144,7 +466,7 @@ func TestIncludeGenerated(t *testing.T) {
 	test.CreateNamespace(t, namespace)
 	test.ApplyManifest(t, "./fixtures/include-generated.yml", namespace)
 
-	client, err := k8sinternal.NewKubeClientLocal("", "")
+	client, err := k8sinternal.NewKubeClientLocal("", "", k8sinternal.ConnectionOptions{})
 	require.NoError(t, err)
 
 	// Test IncludeGenerated = false
@@ -169,6 +491,87 @@ func TestIncludeGenerated(t *testing.T) {
 	assert.True(t, hasPod(resources), "Expected pods for IncludeGenerated=true")
 }
 
+func TestExcludeGeneratedKeepsMirrorPods(t *testing.T) {
+	mirrorPod := k8s.NewPod()
+	mirrorPod.Name = "kube-apiserver"
+	mirrorPod.Namespace = "kube-system"
+	mirrorPod.Annotations = map[string]string{"kubernetes.io/config.mirror": "hash"}
+	mirrorPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "v1", Kind: "Node", Name: "node"}}
+
+	generatedPod := k8s.NewPod()
+	generatedPod.Name = "generated"
+	generatedPod.Namespace = "kube-system"
+	generatedPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "replicaset"}}
+
+	client := newFakeKubeClient(mirrorPod, generatedPod)
+	resources, err := client.GetAllResources(k8sinternal.ClientOptions{})
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "kube-apiserver", k8s.GetObjectMeta(resources[0]).GetName())
+}
+
+func TestExcludeGeneratedKeepsPodsWithEphemeralContainers(t *testing.T) {
+	debuggedPod := k8s.NewPod()
+	debuggedPod.Name = "debugged"
+	debuggedPod.Namespace = "default"
+	debuggedPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "replicaset"}}
+	debuggedPod.Spec.EphemeralContainers = []apiv1.EphemeralContainer{
+		{EphemeralContainerCommon: apiv1.EphemeralContainerCommon{Name: "debugger", Image: "busybox"}},
+	}
+
+	generatedPod := k8s.NewPod()
+	generatedPod.Name = "generated"
+	generatedPod.Namespace = "default"
+	generatedPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "replicaset"}}
+
+	client := newFakeKubeClient(debuggedPod, generatedPod)
+	resources, err := client.GetAllResources(k8sinternal.ClientOptions{})
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "debugged", k8s.GetObjectMeta(resources[0]).GetName())
+}
+
+func TestIncludeJobHistory(t *testing.T) {
+	cronJob := &k8s.CronJobV1{TypeMeta: metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1"}}
+	cronJob.Name = "backup"
+	cronJob.Namespace = "default"
+	cronJob.Spec.JobTemplate.Spec.Template = k8s.NewJob().Spec.Template
+
+	cronJobJob := k8s.NewJob()
+	cronJobJob.Name = "backup-27812345"
+	cronJobJob.Namespace = "default"
+	cronJobJob.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", Controller: k8s.NewTrue()}}
+
+	generatedPod := k8s.NewPod()
+	generatedPod.Name = "backup-27812345-abcde"
+	generatedPod.Namespace = "default"
+	generatedPod.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "Job", Name: "backup-27812345", Controller: k8s.NewTrue()}}
+
+	cases := []struct {
+		testName      string
+		options       k8sinternal.ClientOptions
+		expectedNames []string
+	}{
+		{"Default", k8sinternal.ClientOptions{}, []string{"backup"}},
+		{"Job history", k8sinternal.ClientOptions{IncludeJobHistory: true}, []string{"backup", "backup-27812345"}},
+		{"Generated", k8sinternal.ClientOptions{IncludeGenerated: true}, []string{"backup", "backup-27812345", "backup-27812345-abcde"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			client := newFakeKubeClient(cronJob, cronJobJob, generatedPod)
+			resources, err := client.GetAllResources(tc.options)
+			require.NoError(t, err)
+
+			var names []string
+			for _, resource := range resources {
+				names = append(names, k8s.GetObjectMeta(resource).GetName())
+			}
+			assert.ElementsMatch(t, tc.expectedNames, names)
+		})
+	}
+}
+
 func hasPod(resources []k8s.Resource) bool {
 	for _, resource := range resources {
 		if k8s.IsPodV1(resource) {
@@ -183,6 +586,11 @@ func newFakeKubeClient(resources ...runtime.Object) k8sinternal.KubeClient {
 }
 
 func newFakeKubeClientWithServerVersion(serverversion *version.Info, resources ...runtime.Object) k8sinternal.KubeClient {
+	fakedynamic, fakeDiscovery := newFakeClients(serverversion, metav1.Verbs{"list"}, resources...)
+	return k8sinternal.NewKubeClient(fakedynamic, fakeDiscovery)
+}
+
+func newFakeClients(serverversion *version.Info, verbs metav1.Verbs, resources ...runtime.Object) (*fakedynamic.FakeDynamicClient, *fakediscovery.FakeDiscovery) {
 	clientset := fakeclientset.NewSimpleClientset()
 	fakeDiscovery, _ := clientset.Discovery().(*fakediscovery.FakeDiscovery)
 	if serverversion != nil {
@@ -196,15 +604,19 @@ func newFakeKubeClientWithServerVersion(serverversion *version.Info, resources .
 		listGVK := gvk
 		listGVK.Kind += "List"
 
-		u := unstructured.Unstructured{}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(r)
+		if err != nil {
+			panic(err)
+		}
+		u := unstructured.Unstructured{Object: content}
 		u.SetGroupVersionKind(r.GetObjectKind().GroupVersionKind())
-		u.SetName(k8s.GetObjectMeta(r).GetName())
-		u.SetNamespace(k8s.GetObjectMeta(r).GetNamespace())
 		unstructuredresources = (append(unstructuredresources, &u))
 
 		kind := r.GetObjectKind().GroupVersionKind().Kind
 		plural, _ := meta.UnsafeGuessKindToResource(r.GetObjectKind().GroupVersionKind())
-		apiresource := metav1.APIResource{Name: plural.Resource, Namespaced: false, Group: gvk.Group, Version: gvk.Version, Kind: kind, Verbs: metav1.Verbs{"list"}}
+		// Types are namespaced if their resources have a namespace
+		namespaced := u.GetNamespace() != ""
+		apiresource := metav1.APIResource{Name: plural.Resource, Namespaced: namespaced, Group: gvk.Group, Version: gvk.Version, Kind: kind, Verbs: verbs}
 		gvr := schema.GroupVersionResource{Group: apiresource.Group, Version: apiresource.Version, Resource: apiresource.Name}
 		if _, ok := gvrToListKind[gvr]; !ok {
 			gvrToListKind[gvr] = kind + "List"
@@ -217,6 +629,5 @@ func newFakeKubeClientWithServerVersion(serverversion *version.Info, resources .
 			GroupVersion: gv,
 			APIResources: apiresources})
 	}
-	fakedynamic := fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, unstructuredresources...)
-	return k8sinternal.NewKubeClient(fakedynamic, fakeDiscovery)
+	return fakedynamic.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvrToListKind, unstructuredresources...), fakeDiscovery
 }
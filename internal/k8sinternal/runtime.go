@@ -2,18 +2,71 @@ package k8sinternal
 
 import (
 	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
 func DecodeResource(b []byte) (k8s.Resource, error) {
 	decoder := codecs.UniversalDeserializer()
-	return k8sRuntime.Decode(decoder, b)
+	obj, err := k8sRuntime.Decode(decoder, b)
+	if k8sRuntime.IsNotRegisteredError(err) {
+		if resource, ok := decodeCustomResource(b); ok {
+			return resource, nil
+		}
+	}
+	return obj, err
+}
+
+// decodeCustomResource decodes a resource of a kind registered with k8s.RegisterPodSpecExtractor
+func decodeCustomResource(b []byte) (k8s.Resource, bool) {
+	jsonBytes, err := yaml.ToJSON(b)
+	if err != nil {
+		return nil, false
+	}
+
+	obj := &unstructured.Unstructured{}
+	if err := obj.UnmarshalJSON(jsonBytes); err != nil {
+		return nil, false
+	}
+
+	resource, ok, err := k8s.NewCustomResource(obj)
+	if !ok || err != nil {
+		return nil, false
+	}
+	return resource, true
 }
 
 func EncodeResource(resource k8s.Resource) ([]byte, error) {
 	info, _ := k8sRuntime.SerializerInfoForMediaType(codecs.SupportedMediaTypes(), "application/yaml")
+
+	// Custom resources are not in the scheme so they are encoded as is rather than converted to a version
+	if customResource, ok := resource.(*k8s.CustomResource); ok {
+		if err := customResource.SyncPodSpec(); err != nil {
+			return nil, err
+		}
+		return k8sRuntime.Encode(info.Serializer, &customResource.Unstructured)
+	}
+
 	groupVersion := schema.GroupVersion{Group: resource.GetObjectKind().GroupVersionKind().Group, Version: resource.GetObjectKind().GroupVersionKind().Version}
 	encoder := codecs.EncoderForVersion(info.Serializer, groupVersion)
 	return k8sRuntime.Encode(encoder, resource)
 }
+
+// ToUnstructured converts the resource to an unstructured resource. The changes made by fixes to the PodSpec of
+// custom resources are included
+func ToUnstructured(resource k8s.Resource) (*unstructured.Unstructured, error) {
+	if customResource, ok := resource.(*k8s.CustomResource); ok {
+		if err := customResource.SyncPodSpec(); err != nil {
+			return nil, err
+		}
+		return customResource.Unstructured.DeepCopy(), nil
+	}
+
+	content, err := k8sRuntime.DefaultUnstructuredConverter.ToUnstructured(resource)
+	if err != nil {
+		return nil, err
+	}
+	return &unstructured.Unstructured{Object: content}, nil
+}
@@ -43,6 +43,67 @@ func TestEncodeDecode(t *testing.T) {
 	assert.Equal(deployment, decoded)
 }
 
+func TestDecodeCustomResource(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	manifest, err := ioutil.ReadFile(path.Join(fixtureDir, "custom_resource_rollout.yml"))
+	require.NoError(err)
+
+	decoded, err := k8sinternal.DecodeResource(manifest)
+	require.NoError(err)
+	require.IsType(&k8s.CustomResource{}, decoded)
+
+	containers := k8s.GetContainers(decoded)
+	require.Len(containers, 1)
+	assert.Equal("container", containers[0].Name)
+	assert.Equal(map[string]string{"app": "rollout"}, k8s.GetLabels(decoded))
+	assert.Equal("rollout", k8s.GetObjectMeta(decoded).GetName())
+
+	// Kinds which are not registered cannot be decoded
+	_, err = k8sinternal.DecodeResource([]byte("apiVersion: example.com/v1\nkind: Unregistered\nmetadata:\n  name: foo\n"))
+	assert.Error(err)
+}
+
+func TestEncodeCustomResourcePreservesUnknownFields(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	extractor, err := k8s.ParsePodSpecExtractor("Service.serving.knative.dev={.spec.template.spec}")
+	require.NoError(err)
+	require.NoError(k8s.RegisterPodSpecExtractor(extractor))
+
+	manifest, err := ioutil.ReadFile("fixtures/custom-resource-unknown-fields.yml")
+	require.NoError(err)
+
+	decoded, err := k8sinternal.DecodeResource(manifest)
+	require.NoError(err)
+
+	containers := k8s.GetContainers(decoded)
+	require.Len(containers, 1)
+	containers[0].SecurityContext.Privileged = k8s.NewFalse()
+
+	encoded, err := k8sinternal.EncodeResource(decoded)
+	require.NoError(err)
+	assert.Contains(string(encoded), "containerConcurrency: 10")
+	assert.Contains(string(encoded), "privileged: false")
+	assert.Contains(string(encoded), "successThreshold: 1")
+}
+
+func TestParsePodSpecExtractor(t *testing.T) {
+	extractor, err := k8s.ParsePodSpecExtractor("PipelineRun.tekton.dev=.spec.podTemplate")
+	require.NoError(t, err)
+	assert.Equal(t, k8s.PodSpecExtractor{Group: "tekton.dev", Kind: "PipelineRun", PodSpecPath: ".spec.podTemplate"}, extractor)
+
+	for _, value := range []string{"PipelineRun.tekton.dev", "PipelineRun.tekton.dev=.spec.tasks[*].podTemplate", "=.spec"} {
+		extractor, err := k8s.ParsePodSpecExtractor(value)
+		if err == nil {
+			err = k8s.RegisterPodSpecExtractor(extractor)
+		}
+		assert.ErrorIs(t, err, k8s.ErrInvalidPodSpecExtractor, value)
+	}
+}
+
 func TestGetContainers(t *testing.T) {
 	for _, resource := range getAllResources(t) {
 		containers := k8s.GetContainers(resource)
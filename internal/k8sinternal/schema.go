@@ -0,0 +1,279 @@
+package k8sinternal
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SchemaErrorReason is the reason a resource document is invalid
+type SchemaErrorReason string
+
+const (
+	// SyntaxError is the reason of documents which are not valid YAML
+	SyntaxError SchemaErrorReason = "SyntaxError"
+	// UnknownField is the reason of fields which the kind of the resource does not have
+	UnknownField SchemaErrorReason = "UnknownField"
+	// InvalidType is the reason of fields with a value of the wrong type, such as a string for a boolean
+	InvalidType SchemaErrorReason = "InvalidType"
+)
+
+// SchemaError is a problem of a resource document which the Kubernetes API would reject the resource for
+type SchemaError struct {
+	Reason SchemaErrorReason
+	// Field is the path of the field, such as "spec.containers[0].securityContext.privileged", or empty for syntax
+	// errors
+	Field string
+	// Line and Column are the position of the problem in the document, or 0 if it is unknown
+	Line   int
+	Column int
+	// Message describes the problem, such as "is a string but it should be a boolean" for invalid types or the
+	// parser error for syntax errors
+	Message string
+}
+
+// yamlErrorLine matches the line of the errors of the YAML parser, eg. "yaml: line 3: mapping values are not allowed"
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// yamlParserProblems are the problems of the parser errors of the YAML parser, whose line is zero-based unlike the line
+// of its scanner errors
+var yamlParserProblems = map[string]bool{
+	"did not find expected ',' or ']'":       true,
+	"did not find expected ',' or '}'":       true,
+	"did not find expected '-' indicator":    true,
+	"did not find expected <document start>": true,
+	"did not find expected key":              true,
+	"did not find expected node content":     true,
+	"found undefined tag handle":             true,
+	"found incompatible YAML document":       true,
+}
+
+// yaml11Bools are the plain scalars which are strings in YAML 1.2 but booleans in the YAML 1.1 parser the Kubernetes
+// API uses, so `privileged: yes` is a boolean and a label value of `on` is not a string
+var yaml11Bools = map[string]bool{
+	"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "n": true, "N": true, "no": true, "No": true, "NO": true,
+	"on": true, "On": true, "ON": true, "off": true, "Off": true, "OFF": true,
+}
+
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// ValidateResource returns every problem of a resource document which the Kubernetes API would reject the resource
+// for, rather than only the first one, so that they can all be fixed at once. Only resources of kinds in the scheme
+// are validated, other documents only have syntax errors
+func ValidateResource(b []byte) []SchemaError {
+	var document yaml.Node
+	if err := yaml.Unmarshal(b, &document); err != nil {
+		return []SchemaError{newSyntaxError(err)}
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	root := document.Content[0]
+
+	var typeMeta struct {
+		APIVersion string `yaml:"apiVersion"`
+		Kind       string `yaml:"kind"`
+	}
+	if err := root.Decode(&typeMeta); err != nil || typeMeta.Kind == "" {
+		return nil
+	}
+	groupVersion, err := schema.ParseGroupVersion(typeMeta.APIVersion)
+	if err != nil {
+		return nil
+	}
+	obj, err := scheme.New(groupVersion.WithKind(typeMeta.Kind))
+	if err != nil {
+		return nil
+	}
+
+	var errs []SchemaError
+	validateNode(root, nil, reflect.TypeOf(obj), "", &errs)
+	return errs
+}
+
+func newSyntaxError(err error) SchemaError {
+	schemaErr := SchemaError{Reason: SyntaxError, Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+	if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		schemaErr.Line, _ = strconv.Atoi(match[1])
+		schemaErr.Message = match[2]
+		if yamlParserProblems[schemaErr.Message] {
+			schemaErr.Line++
+		}
+	}
+	return schemaErr
+}
+
+// validateNode validates the value of a field against the Go type it is decoded into, the same way the JSON decoding
+// of the Kubernetes API does. The key is the key of the field in its mapping, or nil for list items, and positions
+// the problems of the field
+func validateNode(node, key *yaml.Node, t reflect.Type, field string, errs *[]SchemaError) {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	position := node
+	if key != nil {
+		position = key
+	}
+	invalid := func(message string) {
+		*errs = append(*errs, SchemaError{
+			Reason:  InvalidType,
+			Field:   field,
+			Line:    position.Line,
+			Column:  position.Column,
+			Message: message,
+		})
+	}
+
+	// Types with their own decoding, such as quantities and int-or-strings, are validated by decoding them
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		var value interface{}
+		if err := node.Decode(&value); err != nil {
+			return
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return
+		}
+		if err := reflect.New(t).Interface().(json.Unmarshaler).UnmarshalJSON(data); err != nil {
+			invalid("is invalid: " + err.Error())
+		}
+		return
+	}
+
+	expected := describeType(t)
+	actual := describeNode(node)
+	if expected != "" && actual != expected && !(expected == "a number" && actual == "an integer") {
+		invalid(fmt.Sprintf("is %s but it should be %s", actual, expected))
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := map[string]reflect.Type{}
+		addStructFields(t, fields)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+			// Merge keys are resolved by the YAML parser
+			if name == "<<" {
+				continue
+			}
+			fieldType, ok := fields[name]
+			if !ok {
+				*errs = append(*errs, SchemaError{
+					Reason: UnknownField,
+					Field:  joinField(field, name),
+					Line:   node.Content[i].Line,
+					Column: node.Content[i].Column,
+				})
+				continue
+			}
+			validateNode(node.Content[i+1], node.Content[i], fieldType, joinField(field, name), errs)
+		}
+	case reflect.Map:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			name := node.Content[i].Value
+			validateNode(node.Content[i+1], node.Content[i], t.Elem(), joinField(field, name), errs)
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i, item := range node.Content {
+			validateNode(item, nil, t.Elem(), fmt.Sprintf("%s[%d]", field, i), errs)
+		}
+	}
+}
+
+// addStructFields adds the JSON names of the fields of a struct to fields, including the fields of inlined structs
+func addStructFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		tag := structField.Tag.Get("json")
+		name := strings.Split(tag, ",")[0]
+		if name == "-" || (structField.PkgPath != "" && !structField.Anonymous) {
+			continue
+		}
+		if name == "" && structField.Anonymous {
+			embedded := structField.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				addStructFields(embedded, fields)
+				continue
+			}
+		}
+		if name == "" {
+			name = structField.Name
+		}
+		fields[name] = structField.Type
+	}
+}
+
+// describeType returns the kind of YAML value a type is decoded from, or an empty string if any value is accepted
+func describeType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Struct, reflect.Map:
+		return "a mapping"
+	case reflect.Slice, reflect.Array:
+		// Bytes are base64 encoded strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "a string"
+		}
+		return "a list"
+	}
+	return ""
+}
+
+// describeNode returns the kind of value of a node, as it is decoded by the YAML 1.1 parser of the Kubernetes API
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a mapping"
+	case yaml.SequenceNode:
+		return "a list"
+	}
+
+	switch node.Tag {
+	case "!!bool":
+		return "a boolean"
+	case "!!int":
+		return "an integer"
+	case "!!float":
+		return "a number"
+	case "!!str":
+		if node.Style == 0 && yaml11Bools[node.Value] {
+			return "a boolean"
+		}
+	}
+	return "a string"
+}
+
+func joinField(field, name string) string {
+	if field == "" {
+		return name
+	}
+	return field + "." + name
+}
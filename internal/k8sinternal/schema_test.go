@@ -0,0 +1,60 @@
+package k8sinternal_test
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateResource(t *testing.T) {
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment
+  labels:
+    enabled: on
+spec:
+  replicas: "3"
+  selector:
+    matchLabels:
+      app: deployment
+  template:
+    metadata:
+      labels:
+        app: deployment
+    spec:
+      containers:
+        - name: container
+          image: scratch
+          imagePullPolicy: Always
+          securityContext:
+            privileged: "false"
+            readOnlyRootFilesystem: yes
+          resources:
+            limits:
+              cpu: lots
+              memory: 128Mi
+          ports:
+            - containerPort: 8080
+              protcol: TCP
+      securityContext: []
+`
+
+	assert.Equal(t, []k8sinternal.SchemaError{
+		{Reason: k8sinternal.InvalidType, Field: "metadata.labels.enabled", Line: 6, Column: 5, Message: "is a boolean but it should be a string"},
+		{Reason: k8sinternal.InvalidType, Field: "spec.replicas", Line: 8, Column: 3, Message: "is a string but it should be an integer"},
+		{Reason: k8sinternal.InvalidType, Field: "spec.template.spec.containers[0].securityContext.privileged", Line: 22, Column: 13, Message: "is a string but it should be a boolean"},
+		{Reason: k8sinternal.InvalidType, Field: "spec.template.spec.containers[0].resources.limits.cpu", Line: 26, Column: 15, Message: "is invalid: quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'"},
+		{Reason: k8sinternal.UnknownField, Field: "spec.template.spec.containers[0].ports[0].protcol", Line: 30, Column: 15},
+		{Reason: k8sinternal.InvalidType, Field: "spec.template.spec.securityContext", Line: 31, Column: 7, Message: "is a list but it should be a mapping"},
+	}, k8sinternal.ValidateResource([]byte(deployment)))
+
+	assert.Equal(t, []k8sinternal.SchemaError{
+		{Reason: k8sinternal.SyntaxError, Line: 3, Message: "did not find expected ',' or ']'"},
+	}, k8sinternal.ValidateResource([]byte("kind: Pod\nmetadata:\n  name: [pod\n")))
+
+	// Documents of kinds which are not in the scheme are not validated
+	assert.Empty(t, k8sinternal.ValidateResource([]byte("apiVersion: example.com/v1\nkind: Unknown\nspec:\n  foo: bar\n")))
+	assert.Empty(t, k8sinternal.ValidateResource([]byte("# only a comment\n")))
+}
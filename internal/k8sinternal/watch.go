@@ -0,0 +1,258 @@
+package k8sinternal
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// WatchEvent is a change to a watched workload resource
+type WatchEvent struct {
+	// Key identifies the resource in the form <resource>.<group>/<namespace>/<name>, eg. "deployments.apps/default/web"
+	Key string
+	// Resource is the created or updated resource. It is nil if the resource was deleted
+	Resource k8s.Resource
+	// Resources are all the cached resources at the time of the event. They are used as context to audit Resource. The
+	// same slice is passed to consecutive events as long as no cached resource changes, so it must not be modified
+	Resources []k8s.Resource
+	// ContextVersion changes each time Resources changes, so the resources can be processed once per version
+	ContextVersion uint64
+}
+
+// WatchHandler handles a watch event. Returning an error stops the watch
+type WatchHandler func(event WatchEvent) error
+
+// watchItem identifies a resource queued to be handled
+type watchItem struct {
+	informer int
+	key      string
+}
+
+// watchedInformer is an informer started by Watch along with the resource type it serves
+type watchedInformer struct {
+	informer    informers.GenericInformer
+	apiResource listableResource
+}
+
+// watchContext holds the cached resources converted to objects, which are the context the watched workloads are
+// audited with. It is updated by the event handlers of the informers, so each resource is only converted when it
+// changes, and the list of resources is only rebuilt for an event if a resource changed since the previous event
+type watchContext struct {
+	mu        sync.Mutex
+	objects   map[watchItem]k8s.Resource
+	resources []k8s.Resource
+	version   uint64
+	// dirty is true if a resource changed since the list of resources was built
+	dirty bool
+}
+
+func newWatchContext() *watchContext {
+	return &watchContext{objects: map[watchItem]k8s.Resource{}, dirty: true}
+}
+
+// set converts and stores the cached object of the item
+func (c *watchContext) set(item watchItem, obj interface{}) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return
+	}
+	// Objects in the cache are shared, so they are copied before being converted
+	resource, err := unstructuredToObject(u.DeepCopy())
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.objects[item] = resource
+	c.dirty = true
+}
+
+func (c *watchContext) delete(item watchItem) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.objects, item)
+	c.dirty = true
+}
+
+// list returns the resources of the context which are included by the options, and the version of the list
+func (c *watchContext) list(options ClientOptions) ([]k8s.Resource, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.dirty {
+		return c.resources, c.version
+	}
+
+	resources := make([]k8s.Resource, 0, len(c.objects))
+	for _, resource := range c.objects {
+		resources = append(resources, resource)
+	}
+	resources = filterNamespaces(resources, options)
+	if !options.IncludeGenerated {
+		resources = excludeGenerated(resources, options)
+	}
+
+	c.resources = resources
+	c.version++
+	c.dirty = false
+	return c.resources, c.version
+}
+
+// Watch starts informers for all the watchable resource types and calls the handler each time a workload resource,
+// ie. a resource with a PodSpec or a namespace, is created, updated or deleted. Resources which existed before the
+// watch started are handled once the caches are synced, so the handler always sees complete context. Watch blocks
+// until the context is cancelled or the handler returns an error.
+func (kc *cachedKubeClient) Watch(ctx context.Context, options ClientOptions, handler WatchHandler) error {
+	if err := options.validateSelectors(); err != nil {
+		return err
+	}
+
+	apiResources, err := kc.listableResources()
+	if err != nil {
+		return err
+	}
+
+	// The queue holds each resource at most once, so a burst of updates to a resource is only handled once
+	queue := workqueue.New()
+	defer queue.ShutDown()
+
+	watchCtx := newWatchContext()
+	var watched []watchedInformer
+	var synced []cache.InformerSynced
+	for _, apiResource := range apiResources {
+		if !isWatchable(apiResource) || !options.isTypeIncluded(apiResource) {
+			continue
+		}
+
+		informer := kc.informerFor(apiResource, options)
+		index := len(watched)
+		watched = append(watched, watchedInformer{informer: informer, apiResource: apiResource})
+		synced = append(synced, informer.Informer().HasSynced)
+
+		handle := func(obj interface{}, deleted bool) {
+			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				return
+			}
+			item := watchItem{informer: index, key: key}
+			if deleted {
+				watchCtx.delete(item)
+			} else {
+				watchCtx.set(item, obj)
+			}
+			queue.Add(item)
+		}
+		informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) { handle(obj, false) },
+			UpdateFunc: func(oldObj, obj interface{}) {
+				// Resyncs don't change the resources, so they are only queued to be audited again
+				if isResync(oldObj, obj) {
+					if key, err := cache.MetaNamespaceKeyFunc(obj); err == nil {
+						queue.Add(watchItem{informer: index, key: key})
+					}
+					return
+				}
+				handle(obj, false)
+			},
+			DeleteFunc: func(obj interface{}) { handle(obj, true) },
+		})
+	}
+	kc.start()
+
+	// Resources of the types whose caches are not synced by the deadline are handled once their caches are synced
+	warmupCtx, cancel := context.WithTimeout(ctx, kc.options.warmupTimeout())
+	defer cancel()
+	if !cache.WaitForCacheSync(warmupCtx.Done(), synced...) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var unsynced []string
+		for _, w := range watched {
+			if !w.informer.Informer().HasSynced() {
+				unsynced = append(unsynced, w.apiResource.gvr.GroupResource().String())
+			}
+		}
+		log.Warnf("The caches of %s were not synced within %s, so their resources are handled once they are synced", strings.Join(unsynced, ", "), kc.options.warmupTimeout())
+	}
+
+	go func() {
+		<-ctx.Done()
+		queue.ShutDown()
+	}()
+
+	// handled holds the keys of the resources passed to the handler, so deletions of other resources are ignored
+	handled := map[string]bool{}
+	for {
+		item, shutdown := queue.Get()
+		if shutdown {
+			return ctx.Err()
+		}
+
+		err := kc.handleWatchItem(item.(watchItem), watched, watchCtx, options, handled, handler)
+		queue.Done(item)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (kc *cachedKubeClient) handleWatchItem(item watchItem, watched []watchedInformer, watchCtx *watchContext, options ClientOptions, handled map[string]bool, handler WatchHandler) error {
+	w := watched[item.informer]
+	gvr := w.apiResource.gvr
+	key := gvr.GroupResource().String() + "/" + item.key
+
+	obj, exists, err := w.informer.Informer().GetIndexer().GetByKey(item.key)
+	if err != nil {
+		return nil
+	}
+
+	if !exists {
+		if !handled[key] {
+			return nil
+		}
+		delete(handled, key)
+		return handler(WatchEvent{Key: key})
+	}
+
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil
+	}
+	// Objects in the cache are shared, so they are copied before being converted
+	resource, err := unstructuredToObject(u.DeepCopy())
+	if err != nil || !isWorkload(resource) {
+		return nil
+	}
+	// Namespaces are watched cluster-wide, and all namespaces are watched if several are audited, so resources of
+	// other namespaces are skipped
+	if !options.isIncluded(resource) {
+		return nil
+	}
+
+	resources, version := watchCtx.list(options)
+	handled[key] = true
+	return handler(WatchEvent{Key: key, Resource: resource, Resources: resources, ContextVersion: version})
+}
+
+// isResync returns true if the update of a cached object is a resync, which doesn't change the object
+func isResync(oldObj, obj interface{}) bool {
+	oldU, ok := oldObj.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	u, ok := obj.(*unstructured.Unstructured)
+	return ok && u.GetResourceVersion() != "" && oldU.GetResourceVersion() == u.GetResourceVersion()
+}
+
+// isWorkload returns true if the resource has a PodSpec or is a namespace. Changes to other resource types, such as
+// network policies, are picked up the next time the workloads they apply to are handled
+func isWorkload(resource k8s.Resource) bool {
+	return k8s.GetPodSpec(resource) != nil || k8s.IsNamespaceV1(resource)
+}
@@ -0,0 +1,122 @@
+package k8sinternal_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWatch(t *testing.T) {
+	deployment := k8s.NewDeployment()
+	deployment.Name = "existing"
+	deployment.Namespace = "foo"
+	service := k8s.NewService()
+	service.Name = "service"
+	service.Namespace = "foo"
+
+	dynamic, discovery := newFakeClients(nil, metav1.Verbs{"list", "watch"}, deployment, service)
+	client := k8sinternal.NewCachedKubeClient(dynamic, discovery, k8sinternal.CacheOptions{})
+	defer client.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan k8sinternal.WatchEvent)
+	done := make(chan error)
+	go func() {
+		done <- client.Watch(ctx, k8sinternal.ClientOptions{}, func(event k8sinternal.WatchEvent) error {
+			events <- event
+			return nil
+		})
+	}()
+
+	// Existing workloads are handled once the caches are synced, with all the cached resources as context
+	event := nextWatchEvent(t, events)
+	assert.Equal(t, "deployments.apps/foo/existing", event.Key)
+	require.NotNil(t, event.Resource)
+	assert.Equal(t, "existing", k8s.GetObjectMeta(event.Resource).GetName())
+	assert.Len(t, event.Resources, 2)
+	existingVersion := event.ContextVersion
+
+	// Created workloads are handled
+	deployments := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	created := &unstructured.Unstructured{}
+	created.SetGroupVersionKind(deployment.GroupVersionKind())
+	created.SetName("created")
+	created.SetNamespace("foo")
+	_, err := dynamic.Resource(deployments).Namespace("foo").Create(ctx, created, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	event = nextWatchEvent(t, events)
+	assert.Equal(t, "deployments.apps/foo/created", event.Key)
+	require.NotNil(t, event.Resource)
+	assert.Len(t, event.Resources, 3)
+	assert.NotEqual(t, existingVersion, event.ContextVersion)
+
+	// Deleted workloads are handled with a nil resource
+	err = dynamic.Resource(deployments).Namespace("foo").Delete(ctx, "existing", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	event = nextWatchEvent(t, events)
+	assert.Equal(t, "deployments.apps/foo/existing", event.Key)
+	assert.Nil(t, event.Resource)
+
+	// Other resource types are only used as context
+	services := schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	err = dynamic.Resource(services).Namespace("foo").Delete(ctx, "service", metav1.DeleteOptions{})
+	require.NoError(t, err)
+
+	select {
+	case event := <-events:
+		t.Fatalf("unexpected watch event for %s", event.Key)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestWatchSharesContext(t *testing.T) {
+	first := k8s.NewDeployment()
+	first.Name = "first"
+	second := k8s.NewDeployment()
+	second.Name = "second"
+
+	dynamic, discovery := newFakeClients(nil, metav1.Verbs{"list", "watch"}, first, second)
+	client := k8sinternal.NewCachedKubeClient(dynamic, discovery, k8sinternal.CacheOptions{})
+	defer client.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events := make(chan k8sinternal.WatchEvent)
+	go client.Watch(ctx, k8sinternal.ClientOptions{}, func(event k8sinternal.WatchEvent) error {
+		events <- event
+		return nil
+	})
+
+	// The context is only built once for the existing workloads, since no resource changes in between
+	event := nextWatchEvent(t, events)
+	other := nextWatchEvent(t, events)
+	assert.Len(t, event.Resources, 2)
+	assert.Equal(t, event.ContextVersion, other.ContextVersion)
+	assert.Equal(t, event.Resources, other.Resources)
+}
+
+func nextWatchEvent(t *testing.T, events <-chan k8sinternal.WatchEvent) k8sinternal.WatchEvent {
+	select {
+	case event := <-events:
+		return event
+	case <-time.After(10 * time.Second):
+		t.Fatal("timed out waiting for a watch event")
+	}
+	return k8sinternal.WatchEvent{}
+}
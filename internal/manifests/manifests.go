@@ -0,0 +1,132 @@
+// Package manifests finds the manifest files to audit from the paths passed with -f/--manifest, which can be files,
+// directories and glob patterns
+package manifests
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Extensions are the extensions of the manifest files found in directories and by glob patterns
+var Extensions = []string{".yaml", ".yml", ".json"}
+
+// File is a manifest file to audit
+type File struct {
+	// Path is the path of the file
+	Path string
+	// Rel is the path of the file relative to the directory or to the base directory of the glob pattern it was found
+	// in, such as "app/deployment.yaml" for "manifests/app/deployment.yaml" found in "manifests". It is the base name
+	// of files passed directly. Fixed manifests are written to Rel within the output directory
+	Rel string
+}
+
+// Find returns the manifest files of the paths, in the order of the paths. Directories are walked recursively for files
+// with one of the Extensions, and patterns match with the syntax of filepath.Match. Files passed directly are returned
+// regardless of their extension. A file found through several paths is only returned once
+func Find(paths []string) ([]File, error) {
+	var files []File
+	found := map[string]bool{}
+	add := func(path, base string) error {
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		if !found[path] {
+			found[path] = true
+			files = append(files, File{Path: path, Rel: rel})
+		}
+		return nil
+	}
+
+	for _, path := range paths {
+		if isPattern(path) {
+			matches, err := filepath.Glob(path)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", path, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no manifest files match %q", path)
+			}
+			base := patternBase(path)
+			for _, match := range matches {
+				if err := walk(match, func(file string) error { return add(file, base) }); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if err := add(path, filepath.Dir(path)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := walk(path, func(file string) error { return add(file, path) }); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no manifest files with extension %s found in %s", strings.Join(Extensions, ", "), strings.Join(paths, ", "))
+	}
+	return files, nil
+}
+
+// walk calls fn with the manifest files of the directory tree at root in lexical order, or with root if it is a
+// manifest file
+func walk(root string, fn func(file string) error) error {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && isManifest(path) {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err := fn(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isManifest(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+	for _, manifestExtension := range Extensions {
+		if extension == manifestExtension {
+			return true
+		}
+	}
+	return false
+}
+
+func isPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+// patternBase returns the directory of the pattern up to its first element with a wildcard, eg. "manifests" for
+// "manifests/*/deployment.yaml"
+func patternBase(pattern string) string {
+	base := filepath.Dir(pattern)
+	for isPattern(base) {
+		base = filepath.Dir(base)
+	}
+	return base
+}
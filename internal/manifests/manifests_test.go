@@ -0,0 +1,95 @@
+package manifests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFind(t *testing.T) {
+	dir := t.TempDir()
+	for _, file := range []string{
+		"deployment.yaml",
+		"README.md",
+		"app/service.yml",
+		"app/config/configmap.json",
+		"other/pod.yaml",
+		"other/pod.txt",
+	} {
+		path := filepath.Join(dir, file)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+	path := func(file string) string {
+		return filepath.Join(dir, filepath.FromSlash(file))
+	}
+
+	cases := []struct {
+		testName string
+		paths    []string
+		expected []File
+	}{
+		{
+			testName: "Directory",
+			paths:    []string{path("app")},
+			expected: []File{
+				{Path: path("app/config/configmap.json"), Rel: filepath.FromSlash("config/configmap.json")},
+				{Path: path("app/service.yml"), Rel: "service.yml"},
+			},
+		},
+		{
+			testName: "File",
+			paths:    []string{path("other/pod.txt")},
+			expected: []File{{Path: path("other/pod.txt"), Rel: "pod.txt"}},
+		},
+		{
+			testName: "Pattern",
+			paths:    []string{path("*/pod.*")},
+			expected: []File{{Path: path("other/pod.yaml"), Rel: filepath.FromSlash("other/pod.yaml")}},
+		},
+		{
+			testName: "Pattern matching directories",
+			paths:    []string{path("a*")},
+			expected: []File{
+				{Path: path("app/config/configmap.json"), Rel: filepath.FromSlash("app/config/configmap.json")},
+				{Path: path("app/service.yml"), Rel: filepath.FromSlash("app/service.yml")},
+			},
+		},
+		{
+			testName: "Multiple paths found once",
+			paths:    []string{path("deployment.yaml"), dir},
+			expected: []File{
+				{Path: path("deployment.yaml"), Rel: "deployment.yaml"},
+				{Path: path("app/config/configmap.json"), Rel: filepath.FromSlash("app/config/configmap.json")},
+				{Path: path("app/service.yml"), Rel: filepath.FromSlash("app/service.yml")},
+				{Path: path("other/pod.yaml"), Rel: filepath.FromSlash("other/pod.yaml")},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			files, err := Find(tc.paths)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, files)
+		})
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), nil, 0644))
+
+	for _, paths := range [][]string{
+		{filepath.Join(dir, "missing.yaml")},
+		{filepath.Join(dir, "*.yaml")},
+		{filepath.Join(dir, "[")},
+		{dir},
+	} {
+		_, err := Find(paths)
+		assert.Error(t, err, paths)
+	}
+}
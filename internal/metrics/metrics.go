@@ -0,0 +1,287 @@
+// Package metrics exposes the findings of kubeaudit reports as Prometheus metrics
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+const namespace = "kubeaudit"
+
+var (
+	findingsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "findings"),
+		"Number of findings in the latest audit.",
+		[]string{"auditor", "rule", "severity", "namespace", "resource"}, nil,
+	)
+	suppressedFindingsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "suppressed_findings"),
+		"Number of findings in the latest audit which were suppressed, by suppression mechanism.",
+		[]string{"mechanism"}, nil,
+	)
+	lastAuditDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "last_audit_timestamp_seconds"),
+		"Time the latest successful audit finished, in seconds since the Unix epoch.",
+		nil, nil,
+	)
+	auditDurationDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "audit_duration_seconds"),
+		"Duration of the latest successful audit.",
+		nil, nil,
+	)
+	auditErrorsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "audit_errors_total"),
+		"Number of audits which failed.",
+		nil, nil,
+	)
+	firstSeenDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "finding_first_seen_timestamp_seconds"),
+		"Time the finding was first reported, in seconds since the Unix epoch.",
+		[]string{"auditor", "rule", "severity", "namespace", "resource"}, nil,
+	)
+	lastSeenDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "finding_last_seen_timestamp_seconds"),
+		"Time the finding was last reported, in seconds since the Unix epoch.",
+		[]string{"auditor", "rule", "severity", "namespace", "resource"}, nil,
+	)
+	cacheHitsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "cache_hits_total"),
+		"Number of audits of a resource type which were served from a warm informer cache.",
+		nil, nil,
+	)
+	cacheMissesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "cache_misses_total"),
+		"Number of audits of a resource type which waited for its informer cache to sync or listed it from the API server.",
+		nil, nil,
+	)
+	resolvedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "finding_resolved_timestamp_seconds"),
+		"Time of the first audit which no longer reported the finding, in seconds since the Unix epoch.",
+		[]string{"auditor", "rule", "severity", "namespace", "resource"}, nil,
+	)
+)
+
+// resolutionBuckets are the buckets of the resolution time histogram, from an hour to 90 days
+var resolutionBuckets = []float64{
+	time.Hour.Seconds(),
+	6 * time.Hour.Seconds(),
+	24 * time.Hour.Seconds(),
+	3 * 24 * time.Hour.Seconds(),
+	7 * 24 * time.Hour.Seconds(),
+	14 * 24 * time.Hour.Seconds(),
+	30 * 24 * time.Hour.Seconds(),
+	90 * 24 * time.Hour.Seconds(),
+}
+
+// now returns the current time. It is a variable so tests can control the time of audits
+var now = time.Now
+
+// finding identifies the series of the findings metric
+type finding struct {
+	auditor   string
+	rule      string
+	severity  string
+	namespace string
+	resource  string
+}
+
+// history records when a finding was first and last reported, and when it stopped being reported
+type history struct {
+	firstSeen time.Time
+	lastSeen  time.Time
+	// resolved is zero while the finding is still reported
+	resolved time.Time
+}
+
+// Exporter is a Prometheus collector which exposes the findings of the latest audit. The findings are replaced each
+// time the exporter is updated, so findings which are fixed stop being exported.
+//
+// The exporter also tracks when each finding was first and last reported. A finding which is no longer reported is
+// resolved: the time since it was first reported is observed in the resolution histogram, and its timestamps are still
+// exported for the resolved TTL. A finding which is reported again after being resolved is tracked as a new finding.
+// The history is only kept in memory, so it starts over when kubeaudit restarts
+type Exporter struct {
+	mu            sync.RWMutex
+	findings      map[finding]int
+	history       map[finding]*history
+	resolvedTTL   time.Duration
+	resolutions   *prometheus.HistogramVec
+	suppressions  map[kubeaudit.SuppressionMechanism]int
+	lastAudit     time.Time
+	auditDuration time.Duration
+	auditErrors   int
+	// cacheStats are only exported once they are set, since clusters are not always audited from a cache
+	cacheStats *kubeaudit.CacheStats
+}
+
+// NewExporter returns an exporter with no findings. No audit metrics are exported until the first update. The
+// timestamps of resolved findings are exported for resolvedTTL after they are resolved
+func NewExporter(resolvedTTL time.Duration) *Exporter {
+	return &Exporter{
+		findings:    map[finding]int{},
+		history:     map[finding]*history{},
+		resolvedTTL: resolvedTTL,
+		resolutions: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "finding_resolution_seconds",
+			Help:      "Time between the first audit which reported a finding and the first audit which no longer reported it.",
+			Buckets:   resolutionBuckets,
+		}, []string{"auditor", "rule", "severity", "namespace"}),
+	}
+}
+
+// Update replaces the exported findings with the results in the report of at least the minimum severity, and the
+// suppressed findings with the suppressions of the report. Findings of the previous update which are not in the report
+// are resolved
+func (e *Exporter) Update(report *kubeaudit.Report, minSeverity kubeaudit.SeverityLevel, duration time.Duration) {
+	findings := map[finding]int{}
+	for _, result := range report.ResultsWithMinSeverity(minSeverity) {
+		resourceNamespace, resourceName := getResourceLabels(result.GetResource())
+		for _, auditResult := range result.GetAuditResults() {
+			findings[finding{
+				auditor:   strings.ToLower(auditResult.Auditor),
+				rule:      auditResult.Rule,
+				severity:  auditResult.Severity.String(),
+				namespace: resourceNamespace,
+				resource:  resourceName,
+			}]++
+		}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.lastAudit = now()
+	e.updateHistory(findings)
+	e.findings = findings
+	e.suppressions = report.Suppressions()
+	e.auditDuration = duration
+}
+
+// updateHistory records the findings of the latest audit as seen, resolves the findings which are no longer reported
+// and forgets the findings which were resolved more than the resolved TTL ago
+func (e *Exporter) updateHistory(findings map[finding]int) {
+	for f := range findings {
+		h, ok := e.history[f]
+		if !ok || !h.resolved.IsZero() {
+			h = &history{firstSeen: e.lastAudit}
+			e.history[f] = h
+		}
+		h.lastSeen = e.lastAudit
+	}
+
+	for f, h := range e.history {
+		if _, ok := findings[f]; ok {
+			continue
+		}
+		if h.resolved.IsZero() {
+			h.resolved = e.lastAudit
+			e.resolutions.WithLabelValues(f.auditor, f.rule, f.severity, f.namespace).Observe(h.resolved.Sub(h.firstSeen).Seconds())
+		} else if e.lastAudit.Sub(h.resolved) >= e.resolvedTTL {
+			delete(e.history, f)
+		}
+	}
+}
+
+// AuditFailed records an audit which failed. The findings of the latest successful audit are still exported
+func (e *Exporter) AuditFailed() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.auditErrors++
+}
+
+// SetCacheStats sets the cache hits and misses of the audits of the cluster cache
+func (e *Exporter) SetCacheStats(stats kubeaudit.CacheStats) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cacheStats = &stats
+}
+
+// Describe implements prometheus.Collector
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- findingsDesc
+	ch <- suppressedFindingsDesc
+	ch <- lastAuditDesc
+	ch <- auditDurationDesc
+	ch <- auditErrorsDesc
+	ch <- firstSeenDesc
+	ch <- lastSeenDesc
+	ch <- resolvedDesc
+	ch <- cacheHitsDesc
+	ch <- cacheMissesDesc
+	e.resolutions.Describe(ch)
+}
+
+// Collect implements prometheus.Collector
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for f, count := range e.findings {
+		ch <- prometheus.MustNewConstMetric(findingsDesc, prometheus.GaugeValue, float64(count), f.auditor, f.rule, f.severity, f.namespace, f.resource)
+	}
+	if !e.lastAudit.IsZero() {
+		// Every mechanism is exported so that suppressions dropping to 0 are graphed
+		for _, mechanism := range kubeaudit.SuppressionMechanisms {
+			ch <- prometheus.MustNewConstMetric(suppressedFindingsDesc, prometheus.GaugeValue, float64(e.suppressions[mechanism]), string(mechanism))
+		}
+		ch <- prometheus.MustNewConstMetric(lastAuditDesc, prometheus.GaugeValue, float64(e.lastAudit.Unix()))
+		ch <- prometheus.MustNewConstMetric(auditDurationDesc, prometheus.GaugeValue, e.auditDuration.Seconds())
+	}
+	ch <- prometheus.MustNewConstMetric(auditErrorsDesc, prometheus.CounterValue, float64(e.auditErrors))
+	if e.cacheStats != nil {
+		ch <- prometheus.MustNewConstMetric(cacheHitsDesc, prometheus.CounterValue, float64(e.cacheStats.Hits))
+		ch <- prometheus.MustNewConstMetric(cacheMissesDesc, prometheus.CounterValue, float64(e.cacheStats.Misses))
+	}
+
+	for f, h := range e.history {
+		labels := []string{f.auditor, f.rule, f.severity, f.namespace, f.resource}
+		ch <- prometheus.MustNewConstMetric(firstSeenDesc, prometheus.GaugeValue, float64(h.firstSeen.Unix()), labels...)
+		ch <- prometheus.MustNewConstMetric(lastSeenDesc, prometheus.GaugeValue, float64(h.lastSeen.Unix()), labels...)
+		if !h.resolved.IsZero() {
+			ch <- prometheus.MustNewConstMetric(resolvedDesc, prometheus.GaugeValue, float64(h.resolved.Unix()), labels...)
+		}
+	}
+	e.resolutions.Collect(ch)
+}
+
+// Handler returns an HTTP handler which serves the metrics of the exporter, along with the Go runtime and process
+// metrics of kubeaudit
+func (e *Exporter) Handler() http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		e,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// getResourceLabels returns the namespace of the resource and its identity in the form kind/name. Namespaces are
+// labelled with their own name so namespace-level findings are grouped with the findings of the resources within them
+func getResourceLabels(resource kubeaudit.KubeResource) (string, string) {
+	if resource == nil || resource.Object() == nil {
+		return "", ""
+	}
+
+	object := resource.Object()
+	kind := object.GetObjectKind().GroupVersionKind().Kind
+	objectMeta := k8s.GetObjectMeta(object)
+	if objectMeta == nil {
+		return "", kind
+	}
+
+	resourceNamespace := objectMeta.GetNamespace()
+	if k8s.IsNamespaceV1(object) {
+		resourceNamespace = objectMeta.GetName()
+	}
+	return resourceNamespace, kind + "/" + objectMeta.GetName()
+}
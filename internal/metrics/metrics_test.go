@@ -0,0 +1,197 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/test"
+)
+
+func TestUpdate(t *testing.T) {
+	limitsAuditor, err := limits.New(limits.Config{})
+	require.NoError(t, err)
+
+	exporter := NewExporter(time.Hour)
+	assert.Equal(t, 0, testutil.CollectAndCount(exporter, "kubeaudit_last_audit_timestamp_seconds"))
+
+	auditables := []kubeaudit.Auditable{privileged.New(), limitsAuditor}
+	report := test.GetReport(t, "../../auditors/privileged/fixtures", "privileged-true.yml", auditables, "", test.MANIFEST_MODE)
+	exporter.Update(report, kubeaudit.Info, time.Second)
+
+	expected := `
+# HELP kubeaudit_findings Number of findings in the latest audit.
+# TYPE kubeaudit_findings gauge
+kubeaudit_findings{auditor="limits",namespace="privileged-true",resource="DaemonSet/daemonset",rule="LimitsNotSet",severity="warning"} 1
+kubeaudit_findings{auditor="privileged",namespace="privileged-true",resource="DaemonSet/daemonset",rule="PrivilegedTrue",severity="error"} 1
+# HELP kubeaudit_audit_duration_seconds Duration of the latest successful audit.
+# TYPE kubeaudit_audit_duration_seconds gauge
+kubeaudit_audit_duration_seconds 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_findings", "kubeaudit_audit_duration_seconds"))
+	assert.Equal(t, 1, testutil.CollectAndCount(exporter, "kubeaudit_last_audit_timestamp_seconds"))
+
+	// Findings below the minimum severity are not exported, and findings which are no longer reported are removed
+	exporter.Update(report, kubeaudit.Error, time.Second)
+	expected = `
+# HELP kubeaudit_findings Number of findings in the latest audit.
+# TYPE kubeaudit_findings gauge
+kubeaudit_findings{auditor="privileged",namespace="privileged-true",resource="DaemonSet/daemonset",rule="PrivilegedTrue",severity="error"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_findings"))
+}
+
+func TestUpdateNamespace(t *testing.T) {
+	exporter := NewExporter(time.Hour)
+	report := test.GetReport(t, "../../auditors/netpols/fixtures", "namespace-missing-default-deny-netpol.yml", []kubeaudit.Auditable{netpols.New()}, "", test.MANIFEST_MODE)
+	exporter.Update(report, kubeaudit.Info, time.Second)
+
+	// Namespaces are labelled with their own name
+	expected := `
+# HELP kubeaudit_findings Number of findings in the latest audit.
+# TYPE kubeaudit_findings gauge
+kubeaudit_findings{auditor="netpols",namespace="namespace-missing-default-deny-netpol",resource="Namespace/namespace-missing-default-deny-netpol",rule="MissingDefaultDenyIngressAndEgressNetworkPolicy",severity="error"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_findings"))
+}
+
+func TestAuditFailed(t *testing.T) {
+	exporter := NewExporter(time.Hour)
+	exporter.AuditFailed()
+	exporter.AuditFailed()
+
+	expected := `
+# HELP kubeaudit_audit_errors_total Number of audits which failed.
+# TYPE kubeaudit_audit_errors_total counter
+kubeaudit_audit_errors_total 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_audit_errors_total"))
+}
+
+func TestSetCacheStats(t *testing.T) {
+	exporter := NewExporter(time.Hour)
+	// The cache metrics are only exported once the cache stats are set
+	assert.Equal(t, 0, testutil.CollectAndCount(exporter, "kubeaudit_cache_hits_total", "kubeaudit_cache_misses_total"))
+
+	exporter.SetCacheStats(kubeaudit.CacheStats{Hits: 12, Misses: 4})
+	expected := `
+# HELP kubeaudit_cache_hits_total Number of audits of a resource type which were served from a warm informer cache.
+# TYPE kubeaudit_cache_hits_total counter
+kubeaudit_cache_hits_total 12
+# HELP kubeaudit_cache_misses_total Number of audits of a resource type which waited for its informer cache to sync or listed it from the API server.
+# TYPE kubeaudit_cache_misses_total counter
+kubeaudit_cache_misses_total 4
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_cache_hits_total", "kubeaudit_cache_misses_total"))
+}
+
+func TestHandler(t *testing.T) {
+	exporter := NewExporter(time.Hour)
+	exporter.AuditFailed()
+
+	recorder := httptest.NewRecorder()
+	exporter.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "kubeaudit_audit_errors_total 1")
+	assert.Contains(t, recorder.Body.String(), "go_goroutines")
+}
+
+func TestUpdateSuppressions(t *testing.T) {
+	exporter := NewExporter(time.Hour)
+	report := test.GetReport(t, "../../auditors/privileged/fixtures", "privileged-true-allowed.yml", []kubeaudit.Auditable{privileged.New()}, "", test.MANIFEST_MODE)
+	exporter.Update(report, kubeaudit.Info, time.Second)
+
+	// Every mechanism is exported, including those which suppressed no findings
+	expected := `
+# HELP kubeaudit_suppressed_findings Number of findings in the latest audit which were suppressed, by suppression mechanism.
+# TYPE kubeaudit_suppressed_findings gauge
+kubeaudit_suppressed_findings{mechanism="baseline"} 0
+kubeaudit_suppressed_findings{mechanism="config"} 0
+kubeaudit_suppressed_findings{mechanism="exclusion"} 0
+kubeaudit_suppressed_findings{mechanism="override"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_suppressed_findings"))
+}
+
+func TestUpdateHistory(t *testing.T) {
+	start := time.Unix(1000000, 0)
+	defer func() { now = time.Now }()
+	setNow := func(d time.Duration) { now = func() time.Time { return start.Add(d) } }
+
+	exporter := NewExporter(time.Hour)
+	limitsAuditor, err := limits.New(limits.Config{})
+	require.NoError(t, err)
+	report := test.GetReport(t, "../../auditors/privileged/fixtures", "privileged-true.yml", []kubeaudit.Auditable{privileged.New(), limitsAuditor}, "", test.MANIFEST_MODE)
+
+	setNow(0)
+	exporter.Update(report, kubeaudit.Info, time.Second)
+	setNow(time.Minute)
+	exporter.Update(report, kubeaudit.Info, time.Second)
+
+	expected := `
+# HELP kubeaudit_finding_first_seen_timestamp_seconds Time the finding was first reported, in seconds since the Unix epoch.
+# TYPE kubeaudit_finding_first_seen_timestamp_seconds gauge
+kubeaudit_finding_first_seen_timestamp_seconds{auditor="limits",namespace="privileged-true",resource="DaemonSet/daemonset",rule="LimitsNotSet",severity="warning"} 1e+06
+kubeaudit_finding_first_seen_timestamp_seconds{auditor="privileged",namespace="privileged-true",resource="DaemonSet/daemonset",rule="PrivilegedTrue",severity="error"} 1e+06
+# HELP kubeaudit_finding_last_seen_timestamp_seconds Time the finding was last reported, in seconds since the Unix epoch.
+# TYPE kubeaudit_finding_last_seen_timestamp_seconds gauge
+kubeaudit_finding_last_seen_timestamp_seconds{auditor="limits",namespace="privileged-true",resource="DaemonSet/daemonset",rule="LimitsNotSet",severity="warning"} 1.00006e+06
+kubeaudit_finding_last_seen_timestamp_seconds{auditor="privileged",namespace="privileged-true",resource="DaemonSet/daemonset",rule="PrivilegedTrue",severity="error"} 1.00006e+06
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_finding_first_seen_timestamp_seconds", "kubeaudit_finding_last_seen_timestamp_seconds"))
+	assert.Equal(t, 0, testutil.CollectAndCount(exporter, "kubeaudit_finding_resolved_timestamp_seconds", "kubeaudit_finding_resolution_seconds"))
+
+	// The finding which is no longer reported is resolved 2 hours after it was first reported
+	setNow(2 * time.Hour)
+	exporter.Update(report, kubeaudit.Error, time.Second)
+	expected = `
+# HELP kubeaudit_finding_resolved_timestamp_seconds Time of the first audit which no longer reported the finding, in seconds since the Unix epoch.
+# TYPE kubeaudit_finding_resolved_timestamp_seconds gauge
+kubeaudit_finding_resolved_timestamp_seconds{auditor="limits",namespace="privileged-true",resource="DaemonSet/daemonset",rule="LimitsNotSet",severity="warning"} 1.0072e+06
+# HELP kubeaudit_finding_resolution_seconds Time between the first audit which reported a finding and the first audit which no longer reported it.
+# TYPE kubeaudit_finding_resolution_seconds histogram
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="3600"} 0
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="21600"} 1
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="86400"} 1
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="259200"} 1
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="604800"} 1
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="1.2096e+06"} 1
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="2.592e+06"} 1
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="7.776e+06"} 1
+kubeaudit_finding_resolution_seconds_bucket{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning",le="+Inf"} 1
+kubeaudit_finding_resolution_seconds_sum{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning"} 7200
+kubeaudit_finding_resolution_seconds_count{auditor="limits",namespace="privileged-true",rule="LimitsNotSet",severity="warning"} 1
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_finding_resolved_timestamp_seconds", "kubeaudit_finding_resolution_seconds"))
+	assert.Equal(t, 2, testutil.CollectAndCount(exporter, "kubeaudit_finding_first_seen_timestamp_seconds"))
+
+	// A finding reported again after being resolved is a new finding
+	setNow(3 * time.Hour)
+	exporter.Update(report, kubeaudit.Info, time.Second)
+	assert.Equal(t, 0, testutil.CollectAndCount(exporter, "kubeaudit_finding_resolved_timestamp_seconds"))
+	expected = `
+# HELP kubeaudit_finding_first_seen_timestamp_seconds Time the finding was first reported, in seconds since the Unix epoch.
+# TYPE kubeaudit_finding_first_seen_timestamp_seconds gauge
+kubeaudit_finding_first_seen_timestamp_seconds{auditor="limits",namespace="privileged-true",resource="DaemonSet/daemonset",rule="LimitsNotSet",severity="warning"} 1.0108e+06
+kubeaudit_finding_first_seen_timestamp_seconds{auditor="privileged",namespace="privileged-true",resource="DaemonSet/daemonset",rule="PrivilegedTrue",severity="error"} 1e+06
+`
+	assert.NoError(t, testutil.CollectAndCompare(exporter, strings.NewReader(expected), "kubeaudit_finding_first_seen_timestamp_seconds"))
+
+	// Resolved findings are forgotten after the resolved TTL
+	setNow(4 * time.Hour)
+	exporter.Update(report, kubeaudit.Error, time.Second)
+	assert.Equal(t, 2, testutil.CollectAndCount(exporter, "kubeaudit_finding_first_seen_timestamp_seconds"))
+	setNow(5 * time.Hour)
+	exporter.Update(report, kubeaudit.Error, time.Second)
+	assert.Equal(t, 1, testutil.CollectAndCount(exporter, "kubeaudit_finding_first_seen_timestamp_seconds"))
+}
@@ -0,0 +1,120 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// maxPagerDutySummary is the longest summary the PagerDuty Events API v2 accepts
+const maxPagerDutySummary = 1024
+
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string  `json:"summary"`
+	Source        string  `json:"source"`
+	Severity      string  `json:"severity"`
+	CustomDetails Payload `json:"custom_details"`
+}
+
+func parseTemplate(text string) (*template.Template, error) {
+	return template.New("notification").Funcs(template.FuncMap{
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}).Parse(text)
+}
+
+// render returns the body of the request which sends the findings, in the format of the type of the notifier unless
+// the config sets a template
+func (n *Notifier) render(findings []Finding) ([]byte, error) {
+	payload := Payload{Findings: findings}
+	if n.template != nil {
+		var buf bytes.Buffer
+		if err := n.template.Execute(&buf, payload); err != nil {
+			return nil, fmt.Errorf("error executing the notification template: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+
+	switch n.config.Type {
+	case TypeSlack:
+		return json.Marshal(newSlackMessage(findings))
+	case TypePagerDuty:
+		return json.Marshal(newPagerDutyEvent(n.config.RoutingKey, findings))
+	}
+	return json.Marshal(payload)
+}
+
+// slackEscaper escapes the characters Slack uses for links and mentions
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// newSlackMessage lists the findings in a message, one per line
+func newSlackMessage(findings []Finding) slackMessage {
+	var text strings.Builder
+	fmt.Fprintf(&text, "*kubeaudit found %d findings*", len(findings))
+	for _, finding := range findings {
+		fmt.Fprintf(&text, "\n• [%s] `%s` in %s: %s", finding.Severity, finding.Rule, k8s.DescribeResource(finding.Kind, finding.Namespace, finding.Name), slackEscaper.Replace(finding.Message))
+	}
+	return slackMessage{Text: text.String()}
+}
+
+// newPagerDutyEvent triggers an alert for the findings, with the severity of the most severe finding. The findings are
+// the custom details of the alert
+func newPagerDutyEvent(routingKey string, findings []Finding) pagerDutyEvent {
+	rules := make([]string, 0, len(findings))
+	for _, finding := range findings {
+		rules = append(rules, fmt.Sprintf("%s in %s", finding.Rule, k8s.DescribeResource(finding.Kind, finding.Namespace, finding.Name)))
+	}
+	summary := fmt.Sprintf("kubeaudit found %d findings: %s", len(findings), strings.Join(rules, ", "))
+	if len(summary) > maxPagerDutySummary {
+		summary = summary[:maxPagerDutySummary-3] + "..."
+	}
+
+	return pagerDutyEvent{
+		RoutingKey:  routingKey,
+		EventAction: "trigger",
+		Payload: pagerDutyPayload{
+			Summary:       summary,
+			Source:        "kubeaudit",
+			Severity:      pagerDutySeverity(findings),
+			CustomDetails: Payload{Findings: findings},
+		},
+	}
+}
+
+// pagerDutySeverity maps the most severe finding to a PagerDuty severity. Custom severities above error are critical
+func pagerDutySeverity(findings []Finding) string {
+	highest, found := kubeaudit.Info, false
+	for _, finding := range findings {
+		severity, err := kubeaudit.ParseSeverity(finding.Severity)
+		if err == nil && (!found || severity.Compare(highest) > 0) {
+			highest, found = severity, true
+		}
+	}
+
+	switch {
+	case highest.Compare(kubeaudit.Error) > 0:
+		return "critical"
+	case highest == kubeaudit.Error:
+		return "error"
+	case highest.AtLeast(kubeaudit.Warn):
+		return "warning"
+	}
+	return "info"
+}
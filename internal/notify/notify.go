@@ -0,0 +1,485 @@
+// Package notify sends findings to an HTTP endpoint, such as a generic webhook, Slack or PagerDuty. Findings are sent
+// in batches, batches which fail to be sent are retried with exponential backoff, and batches can be spooled to disk so
+// transient outages of the receiver don't lose findings
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/template"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/baseline"
+)
+
+const (
+	// DefaultBatchSize is the maximum number of findings sent in a request if the config doesn't set one
+	DefaultBatchSize = 100
+	// DefaultFlushInterval is the longest time findings are held before being sent if the config doesn't set one
+	DefaultFlushInterval = 10 * time.Second
+
+	initialBackoff = time.Second
+	maxBackoff     = time.Minute
+	requestTimeout = 30 * time.Second
+	// shutdownTimeout is the time the notifier has to send the remaining findings once it is stopped
+	shutdownTimeout = 10 * time.Second
+	// maxQueuedBatches is the maximum number of batches held in memory while the receiver is down and no spool
+	// directory is set. The oldest batches are dropped beyond this
+	maxQueuedBatches   = 1000
+	spoolFileExtension = ".json"
+)
+
+const (
+	// TypeWebhook sends the findings as a JSON Payload
+	TypeWebhook = "webhook"
+	// TypeSlack sends the findings as the message of a Slack incoming webhook
+	TypeSlack = "slack"
+	// TypePagerDuty sends the findings as an alert of the PagerDuty Events API v2
+	TypePagerDuty = "pagerduty"
+
+	// DefaultPagerDutyURL is the endpoint of the PagerDuty Events API v2, used if the config doesn't set a URL
+	DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+)
+
+// errRejected is returned when a batch can't be sent as it is, because the receiver rejects it with a client error or
+// the template fails, in which case retrying won't help
+var errRejected = errors.New("the receiver rejected the findings")
+
+// Config configures where and how findings are sent
+type Config struct {
+	// Type is the format of the requests, one of "webhook" (the default), "slack" or "pagerduty"
+	Type string
+	// URL is the endpoint the findings are POSTed to
+	URL string
+	// RoutingKey is the integration key of the PagerDuty service the alerts are sent to. Only used with the pagerduty
+	// type
+	RoutingKey string
+	// MinSeverity is the lowest severity of the findings which are sent. All findings are sent if it is empty
+	MinSeverity string
+	// Template replaces the body of the requests. It is a Go template with the Findings of the batch, and a json
+	// function which encodes a value as JSON, such as {"text": {{printf "%d findings" (len .Findings) | json}}}
+	Template string
+	// BatchSize is the maximum number of findings sent in a request. Findings are sent as soon as a batch is full
+	BatchSize int
+	// FlushInterval is the longest time findings are held before being sent
+	FlushInterval time.Duration
+	// Retries is the number of times a batch is retried, with exponential backoff, before it is kept for the next flush
+	Retries int
+	// SpoolDir is a directory batches are written to until they are sent, so they survive restarts of kubeaudit. If it
+	// is empty, batches are only held in memory
+	SpoolDir string
+}
+
+// Finding is an audit result sent to the receiver. The fingerprint identifies the finding across audits (see
+// baseline.Fingerprint), so receivers can drop the duplicates of a batch which was retried
+type Finding struct {
+	Fingerprint string            `json:"fingerprint"`
+	Auditor     string            `json:"auditor"`
+	Rule        string            `json:"rule"`
+	Severity    string            `json:"severity"`
+	Message     string            `json:"message"`
+	Kind        string            `json:"kind,omitempty"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+// Payload is the body of the requests sent to the receiver
+type Payload struct {
+	Findings []Finding `json:"findings"`
+}
+
+// Findings returns the audit results in the report with at least the minimum severity
+func Findings(report *kubeaudit.Report, minSeverity kubeaudit.SeverityLevel) []Finding {
+	var findings []Finding
+	for _, finding := range report.FindingsWithMinSeverity(minSeverity) {
+		findings = append(findings, Finding{
+			Fingerprint: baseline.Fingerprint(finding.Resource, finding.AuditResult),
+			Auditor:     finding.Auditor,
+			Rule:        finding.Rule,
+			Severity:    finding.Severity.String(),
+			Message:     finding.Message,
+			Kind:        finding.GroupVersionKind.Kind,
+			Namespace:   finding.Namespace,
+			Name:        finding.Name,
+			Metadata:    finding.Metadata,
+		})
+	}
+	return findings
+}
+
+// Notifier sends findings to the receiver in the background while it runs
+type Notifier struct {
+	config   Config
+	client   *http.Client
+	backoff  time.Duration
+	template *template.Template
+	// minSeverity is only used if the config sets a minimum severity
+	minSeverity kubeaudit.SeverityLevel
+
+	mu      sync.Mutex
+	pending []Finding
+	full    chan struct{}
+
+	// queue holds the batches waiting to be sent, oldest first. It is only used by Run
+	queue []*batch
+	seq   int
+}
+
+type batch struct {
+	findings []Finding
+	// path is the spool file of the batch, or empty if the batch is only held in memory
+	path string
+}
+
+// New returns a notifier for the config. Batches spooled by a previous notifier with the same spool directory are sent
+// first once the notifier runs
+func New(config Config) (*Notifier, error) {
+	switch config.Type {
+	case "":
+		config.Type = TypeWebhook
+	case TypeWebhook, TypeSlack:
+	case TypePagerDuty:
+		if config.URL == "" {
+			config.URL = DefaultPagerDutyURL
+		}
+		if config.RoutingKey == "" && config.Template == "" {
+			return nil, errors.New("the pagerduty notification type requires a routing key")
+		}
+	default:
+		return nil, fmt.Errorf("invalid notification type %q, expected one of %q, %q, %q", config.Type, TypeWebhook, TypeSlack, TypePagerDuty)
+	}
+
+	endpoint, err := url.Parse(config.URL)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid notification URL %q, expected an http or https URL", config.URL)
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+	if config.Retries < 0 {
+		config.Retries = 0
+	}
+
+	n := &Notifier{
+		config:  config,
+		client:  &http.Client{Timeout: requestTimeout},
+		backoff: initialBackoff,
+		full:    make(chan struct{}, 1),
+	}
+
+	if config.MinSeverity != "" {
+		if n.minSeverity, err = kubeaudit.ParseSeverity(config.MinSeverity); err != nil {
+			return nil, err
+		}
+	}
+	if config.Template != "" {
+		if n.template, err = parseTemplate(config.Template); err != nil {
+			return nil, fmt.Errorf("invalid notification template: %w", err)
+		}
+	}
+
+	if config.SpoolDir != "" {
+		if err := os.MkdirAll(config.SpoolDir, 0700); err != nil {
+			return nil, fmt.Errorf("failed to create the spool directory: %w", err)
+		}
+		if err := n.loadSpool(); err != nil {
+			return nil, err
+		}
+	}
+
+	return n, nil
+}
+
+// Notify queues findings to be sent. It never blocks on the receiver. A nil notifier drops the findings, so callers
+// don't need to check whether notifications are enabled
+func (n *Notifier) Notify(findings ...Finding) {
+	if n == nil {
+		return
+	}
+	if n.config.MinSeverity != "" {
+		findings = n.filter(findings)
+	}
+	if len(findings) == 0 {
+		return
+	}
+
+	n.mu.Lock()
+	n.pending = append(n.pending, findings...)
+	full := len(n.pending) >= n.config.BatchSize
+	n.mu.Unlock()
+
+	if full {
+		select {
+		case n.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run sends the queued findings whenever a batch is full or the flush interval passes, until the context is done. The
+// remaining findings are then sent one last time, and spooled if they still can't be sent
+func (n *Notifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(n.config.FlushInterval)
+	defer ticker.Stop()
+
+	n.send(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			n.shutdown()
+			return
+		case <-ticker.C:
+		case <-n.full:
+		}
+		n.send(ctx)
+	}
+}
+
+// filter returns the findings with at least the minimum severity of the notifier. Findings whose severity is unknown,
+// such as a custom severity of a spooled batch which is no longer defined, are kept
+func (n *Notifier) filter(findings []Finding) []Finding {
+	var filtered []Finding
+	for _, finding := range findings {
+		severity, err := kubeaudit.ParseSeverity(finding.Severity)
+		if err != nil || severity.AtLeast(n.minSeverity) {
+			filtered = append(filtered, finding)
+		}
+	}
+	return filtered
+}
+
+// Flush sends the queued findings once, and spools them if they can't be sent. It is used instead of Run to send the
+// findings of a single audit
+func (n *Notifier) Flush() {
+	if n == nil {
+		return
+	}
+	n.shutdown()
+}
+
+func (n *Notifier) shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	n.send(ctx)
+
+	if len(n.queue) == 0 {
+		return
+	}
+	if n.config.SpoolDir != "" {
+		log.Warnf("%d batches of findings could not be sent and are spooled in %s", len(n.queue), n.config.SpoolDir)
+		return
+	}
+	count := 0
+	for _, b := range n.queue {
+		count += len(b.findings)
+	}
+	log.Warnf("%d findings could not be sent and are lost", count)
+}
+
+// send moves the pending findings into batches and sends the queued batches in order. It stops at the first batch
+// which can't be sent so the order of the findings is kept
+func (n *Notifier) send(ctx context.Context) {
+	n.enqueuePending()
+
+	for len(n.queue) > 0 {
+		b := n.queue[0]
+		err := n.deliver(ctx, b)
+		if err != nil && !errors.Is(err, errRejected) {
+			if ctx.Err() == nil {
+				log.WithError(err).Warnf("Error sending %d findings, they will be sent again later", len(b.findings))
+			}
+			return
+		}
+		if err != nil {
+			log.WithError(err).Errorf("Error sending %d findings, they are dropped", len(b.findings))
+		}
+		n.dequeue()
+	}
+}
+
+func (n *Notifier) enqueuePending() {
+	n.mu.Lock()
+	pending := n.pending
+	n.pending = nil
+	n.mu.Unlock()
+
+	for len(pending) > 0 {
+		size := n.config.BatchSize
+		if size > len(pending) {
+			size = len(pending)
+		}
+		b := &batch{findings: pending[:size]}
+		pending = pending[size:]
+
+		if n.config.SpoolDir != "" {
+			if err := n.spool(b); err != nil {
+				log.WithError(err).Warn("Error spooling findings, they are only held in memory")
+			}
+		}
+		n.queue = append(n.queue, b)
+	}
+
+	if n.config.SpoolDir == "" && len(n.queue) > maxQueuedBatches {
+		dropped := len(n.queue) - maxQueuedBatches
+		log.Warnf("Too many findings waiting to be sent, dropping the %d oldest batches", dropped)
+		n.queue = n.queue[dropped:]
+	}
+}
+
+func (n *Notifier) dequeue() {
+	b := n.queue[0]
+	n.queue = n.queue[1:]
+	if b.path != "" {
+		if err := os.Remove(b.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.WithError(err).Warn("Error removing spooled findings which were sent")
+		}
+	}
+}
+
+// deliver sends a batch, retrying transient failures with exponential backoff
+func (n *Notifier) deliver(ctx context.Context, b *batch) error {
+	body, err := n.render(b.findings)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errRejected, err)
+	}
+
+	backoff := n.backoff
+	for attempt := 0; ; attempt++ {
+		err := n.post(ctx, body)
+		if err == nil || errors.Is(err, errRejected) || attempt >= n.config.Retries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := n.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return nil
+	case response.StatusCode == http.StatusRequestTimeout || response.StatusCode == http.StatusTooManyRequests ||
+		response.StatusCode >= 500:
+		return fmt.Errorf("unexpected response status %s", response.Status)
+	}
+	return fmt.Errorf("%w: %s", errRejected, response.Status)
+}
+
+// spool writes a batch to a new file of the spool directory. Files are named after the time they are written so they
+// are sent in order after a restart, and are renamed into place so a partially written file is never sent
+func (n *Notifier) spool(b *batch) error {
+	data, err := json.Marshal(Payload{Findings: b.findings})
+	if err != nil {
+		return err
+	}
+
+	n.seq++
+	path := filepath.Join(n.config.SpoolDir, fmt.Sprintf("%019d-%06d%s", time.Now().UnixNano(), n.seq, spoolFileExtension))
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	b.path = path
+	return nil
+}
+
+func (n *Notifier) loadSpool() error {
+	paths, err := filepath.Glob(filepath.Join(n.config.SpoolDir, "*"+spoolFileExtension))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read spooled findings: %w", err)
+		}
+		var payload Payload
+		if err := json.Unmarshal(data, &payload); err != nil {
+			log.WithError(err).Warnf("Skipping malformed spooled findings %s", path)
+			continue
+		}
+		n.queue = append(n.queue, &batch{findings: payload.Findings, path: path})
+	}
+	return nil
+}
+
+// Notifiers sends findings to several receivers, each with its own notifier
+type Notifiers []*Notifier
+
+// Notify queues findings to be sent by every notifier
+func (ns Notifiers) Notify(findings ...Finding) {
+	for _, n := range ns {
+		n.Notify(findings...)
+	}
+}
+
+// Run runs every notifier until the context is done, and returns once they have all stopped
+func (ns Notifiers) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, n := range ns {
+		wg.Add(1)
+		go func(n *Notifier) {
+			defer wg.Done()
+			n.Run(ctx)
+		}(n)
+	}
+	wg.Wait()
+}
+
+// Flush flushes every notifier concurrently, so a receiver which is down doesn't delay the others
+func (ns Notifiers) Flush() {
+	var wg sync.WaitGroup
+	for _, n := range ns {
+		wg.Add(1)
+		go func(n *Notifier) {
+			defer wg.Done()
+			n.Flush()
+		}(n)
+	}
+	wg.Wait()
+}
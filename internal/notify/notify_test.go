@@ -0,0 +1,335 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/test"
+)
+
+// receiver records the findings it receives, answering the first failures requests with the failure status
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	failures int
+	requests int
+	findings []string
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests++
+	if r.requests <= r.failures {
+		w.WriteHeader(r.status)
+		return
+	}
+
+	var payload Payload
+	if err := json.NewDecoder(req.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	for _, finding := range payload.Findings {
+		r.findings = append(r.findings, finding.Fingerprint)
+	}
+}
+
+func (r *receiver) received() ([]string, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.findings...), r.requests
+}
+
+func newFindings(names ...string) []Finding {
+	var findings []Finding
+	for _, name := range names {
+		findings = append(findings, Finding{Fingerprint: name, Auditor: "test", Rule: "Test"})
+	}
+	return findings
+}
+
+// start runs the notifier until the returned function is called, which waits for the notifier to stop
+func start(t *testing.T, config Config) (*Notifier, func()) {
+	n, err := New(config)
+	require.NoError(t, err)
+	n.backoff = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		n.Run(ctx)
+		close(done)
+	}()
+	return n, func() {
+		cancel()
+		<-done
+	}
+}
+
+func spooled(t *testing.T, dir string) []string {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+spoolFileExtension))
+	require.NoError(t, err)
+	return paths
+}
+
+func TestNotifyBatches(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	n, stop := start(t, Config{URL: server.URL, BatchSize: 2, FlushInterval: time.Hour})
+	defer stop()
+
+	// A full batch is sent without waiting for the flush interval, along with the findings queued before it
+	n.Notify(newFindings("a")...)
+	n.Notify(newFindings("b", "c")...)
+	assert.Eventually(t, func() bool {
+		findings, requests := r.received()
+		return assert.ObjectsAreEqual([]string{"a", "b", "c"}, findings) && requests == 2
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNotifyFlushInterval(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	n, stop := start(t, Config{URL: server.URL, BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer stop()
+
+	n.Notify(newFindings("a")...)
+	assert.Eventually(t, func() bool {
+		findings, _ := r.received()
+		return assert.ObjectsAreEqual([]string{"a"}, findings)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNotifyRetries(t *testing.T) {
+	cases := []struct {
+		testName         string
+		status           int
+		failures         int
+		retries          int
+		expectedFindings []string
+		expectedRequests int
+	}{
+		{"Server errors are retried", http.StatusServiceUnavailable, 2, 5, []string{"a"}, 3},
+		{"Too many requests are retried", http.StatusTooManyRequests, 1, 5, []string{"a"}, 2},
+		{"Rejected findings are not retried", http.StatusBadRequest, 1, 5, nil, 1},
+	}
+
+	for _, tc := range cases {
+		// These lines are needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			r := &receiver{status: tc.status, failures: tc.failures}
+			server := httptest.NewServer(r)
+			defer server.Close()
+
+			spoolDir := t.TempDir()
+			n, stop := start(t, Config{URL: server.URL, BatchSize: 1, FlushInterval: time.Hour, Retries: tc.retries, SpoolDir: spoolDir})
+			defer stop()
+
+			n.Notify(newFindings("a")...)
+			assert.Eventually(t, func() bool {
+				_, requests := r.received()
+				return requests == tc.expectedRequests && len(spooled(t, spoolDir)) == 0
+			}, 5*time.Second, 10*time.Millisecond)
+
+			findings, _ := r.received()
+			assert.Equal(t, tc.expectedFindings, findings)
+		})
+	}
+}
+
+func TestNotifySpool(t *testing.T) {
+	r := &receiver{status: http.StatusServiceUnavailable, failures: 1000}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	spoolDir := t.TempDir()
+	n, stop := start(t, Config{URL: server.URL, BatchSize: 2, FlushInterval: time.Hour, SpoolDir: spoolDir})
+	n.Notify(newFindings("a", "b", "c")...)
+	assert.Eventually(t, func() bool {
+		_, requests := r.received()
+		return requests > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	stop()
+
+	// The batches which couldn't be sent are kept on disk and sent in order once the receiver is back
+	require.Len(t, spooled(t, spoolDir), 2)
+	r.mu.Lock()
+	r.failures = 0
+	r.mu.Unlock()
+
+	n, stop = start(t, Config{URL: server.URL, BatchSize: 2, FlushInterval: time.Hour, SpoolDir: spoolDir})
+	defer stop()
+	n.Notify(newFindings("d", "e")...)
+	assert.Eventually(t, func() bool {
+		findings, _ := r.received()
+		return assert.ObjectsAreEqual([]string{"a", "b", "c", "d", "e"}, findings) && len(spooled(t, spoolDir)) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewSkipsMalformedSpool(t *testing.T) {
+	spoolDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(spoolDir, "1"+spoolFileExtension), []byte("{"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(spoolDir, "2"+spoolFileExtension), []byte(`{"findings":[{"fingerprint":"a"}]}`), 0600))
+
+	n, err := New(Config{URL: "http://localhost", SpoolDir: spoolDir})
+	require.NoError(t, err)
+	require.Len(t, n.queue, 1)
+	assert.Equal(t, "a", n.queue[0].findings[0].Fingerprint)
+}
+
+func TestNewInvalidURL(t *testing.T) {
+	for _, notifyURL := range []string{"", "localhost:8080", "ftp://localhost", "http://"} {
+		_, err := New(Config{URL: notifyURL})
+		assert.Error(t, err, notifyURL)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	for _, config := range []Config{
+		{URL: "http://localhost", Type: "teams"},
+		{Type: TypePagerDuty},
+		{URL: "http://localhost", MinSeverity: "urgent"},
+		{URL: "http://localhost", Template: "{{.Findings"},
+	} {
+		_, err := New(config)
+		assert.Error(t, err, config)
+	}
+}
+
+func TestNotifyMinSeverity(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	n, stop := start(t, Config{URL: server.URL, BatchSize: 1, FlushInterval: time.Hour, MinSeverity: "warning"})
+	defer stop()
+
+	n.Notify(
+		Finding{Fingerprint: "info", Severity: "info"},
+		Finding{Fingerprint: "warning", Severity: "warning"},
+		Finding{Fingerprint: "error", Severity: "error"},
+	)
+	assert.Eventually(t, func() bool {
+		findings, _ := r.received()
+		return assert.ObjectsAreEqual([]string{"warning", "error"}, findings)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestRender(t *testing.T) {
+	findings := []Finding{
+		{Fingerprint: "a", Rule: "PrivilegedTrue", Severity: "warning", Message: "a <b>", Kind: "Deployment", Namespace: "default", Name: "web"},
+		{Fingerprint: "b", Rule: "AutomountServiceAccountTokenTrueAndDefaultSA", Severity: "error", Message: "b", Kind: "Pod", Name: "job"},
+	}
+
+	cases := []struct {
+		testName string
+		config   Config
+		expected string
+	}{
+		{
+			"Webhook",
+			Config{URL: "http://localhost"},
+			`{"findings":[{"fingerprint":"a","auditor":"","rule":"PrivilegedTrue","severity":"warning","message":"a \u003cb\u003e","kind":"Deployment","namespace":"default","name":"web"},{"fingerprint":"b","auditor":"","rule":"AutomountServiceAccountTokenTrueAndDefaultSA","severity":"error","message":"b","kind":"Pod","name":"job"}]}`,
+		},
+		{
+			"Slack",
+			Config{URL: "http://localhost", Type: TypeSlack},
+			`{"text":"*kubeaudit found 2 findings*\n• [warning] ` + "`PrivilegedTrue`" + ` in Deployment default/web: a \u0026lt;b\u0026gt;\n• [error] ` + "`AutomountServiceAccountTokenTrueAndDefaultSA`" + ` in Pod job: b"}`,
+		},
+		{
+			"Template",
+			Config{URL: "http://localhost", Template: `{"count": {{len .Findings}}, "first": {{(index .Findings 0).Rule | json}}}`},
+			`{"count": 2, "first": "PrivilegedTrue"}`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			n, err := New(tc.config)
+			require.NoError(t, err)
+			body, err := n.render(findings)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(body))
+		})
+	}
+}
+
+func TestRenderPagerDuty(t *testing.T) {
+	n, err := New(Config{Type: TypePagerDuty, RoutingKey: "key"})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultPagerDutyURL, n.config.URL)
+
+	body, err := n.render([]Finding{
+		{Fingerprint: "a", Rule: "PrivilegedTrue", Severity: "warning", Kind: "Deployment", Namespace: "default", Name: "web"},
+		{Fingerprint: "b", Rule: "ImageTagMissing", Severity: "error", Kind: "Pod", Name: "job"},
+	})
+	require.NoError(t, err)
+
+	var event pagerDutyEvent
+	require.NoError(t, json.Unmarshal(body, &event))
+	assert.Equal(t, "key", event.RoutingKey)
+	assert.Equal(t, "trigger", event.EventAction)
+	assert.Equal(t, "kubeaudit found 2 findings: PrivilegedTrue in Deployment default/web, ImageTagMissing in Pod job", event.Payload.Summary)
+	assert.Equal(t, "error", event.Payload.Severity)
+	assert.Len(t, event.Payload.CustomDetails.Findings, 2)
+}
+
+func TestFlush(t *testing.T) {
+	r := &receiver{}
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	var notifiers Notifiers
+	for i := 0; i < 2; i++ {
+		n, err := New(Config{URL: server.URL, BatchSize: 2})
+		require.NoError(t, err)
+		notifiers = append(notifiers, n)
+	}
+
+	// The findings of a single audit are sent without running the notifiers
+	notifiers.Notify(newFindings("a", "b", "c")...)
+	notifiers.Flush()
+	findings, requests := r.received()
+	assert.ElementsMatch(t, []string{"a", "a", "b", "b", "c", "c"}, findings)
+	assert.Equal(t, 4, requests)
+}
+
+func TestNilNotifier(t *testing.T) {
+	var n *Notifier
+	assert.NotPanics(t, func() { n.Notify(newFindings("a")...) })
+	assert.NotPanics(t, n.Flush)
+}
+
+func TestFindings(t *testing.T) {
+	report := test.GetReport(t, "../../auditors/privileged/fixtures", "privileged-true.yml", []kubeaudit.Auditable{privileged.New()}, "", test.MANIFEST_MODE)
+
+	findings := Findings(report, kubeaudit.Info)
+	require.NotEmpty(t, findings)
+	for _, finding := range findings {
+		assert.Equal(t, "privileged", finding.Auditor)
+		assert.NotEmpty(t, finding.Fingerprint)
+		assert.NotEmpty(t, finding.Kind)
+		assert.Equal(t, "privileged-true", finding.Namespace)
+	}
+}
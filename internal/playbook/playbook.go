@@ -0,0 +1,168 @@
+// Package playbook groups the findings of a report by remediation and writes step-by-step fix instructions, with the
+// change to the YAML of each affected resource, to hand to the teams which own the resources
+package playbook
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/diff"
+	"github.com/Shopify/kubeaudit/internal/redact"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+const (
+	// FormatMarkdown writes the playbook as Markdown
+	FormatMarkdown = "markdown"
+	// FormatHTML writes the playbook as a standalone HTML page
+	FormatHTML = "html"
+)
+
+// Playbook is the remediations of the findings of a report, from the most to the least severe
+type Playbook struct {
+	Remediations []Remediation
+	// Findings and Resources are the number of findings in the playbook and of resources they were reported for
+	Findings  int
+	Resources int
+}
+
+// Remediation is the fix of the findings of a rule, with a step for each finding
+type Remediation struct {
+	Rule     string
+	Auditor  string
+	Severity string
+	// Docs is the URL of the documentation of the rule, if any
+	Docs  string
+	Steps []Step
+	// severity is the highest severity of the findings, to sort remediations
+	severity kubeaudit.SeverityLevel
+}
+
+// Step is the fix of a finding of a resource
+type Step struct {
+	// Resource identifies the resource in the form kind/namespace/name
+	Resource string
+	// FilePath is the manifest file of the resource, in manifest mode
+	FilePath string
+	Message  string
+	// Fix describes the change made by autofix, or is empty if the finding has to be fixed manually
+	Fix string
+	// Example is the unified diff of the YAML of the resource made by the fix, followed by the YAML of the resources
+	// created by the fix, if any
+	Example string
+}
+
+// Create groups the findings of the report with the minimum severity by rule. Overridden findings are left out. The
+// fixes of the findings are applied to the resources of the report to show the change they make, so the report must
+// not be fixed afterwards
+func Create(report *kubeaudit.Report, minSeverity kubeaudit.SeverityLevel) (*Playbook, error) {
+	playbook := &Playbook{}
+	remediations := map[string]*Remediation{}
+
+	for _, result := range report.Results() {
+		resource := result.GetResource()
+		affected := false
+		for _, auditResult := range result.GetAuditResults() {
+			if !auditResult.Severity.AtLeast(minSeverity) || auditResult.SuppressedBy != "" {
+				continue
+			}
+
+			step, err := newStep(resource, auditResult)
+			if err != nil {
+				return nil, err
+			}
+
+			remediation, ok := remediations[auditResult.Rule]
+			if !ok {
+				remediation = &Remediation{Rule: auditResult.Rule, Auditor: auditResult.Auditor, Docs: getDocs(auditResult)}
+				remediations[auditResult.Rule] = remediation
+			}
+			if len(remediation.Steps) == 0 || auditResult.Severity.Compare(remediation.severity) > 0 {
+				remediation.severity = auditResult.Severity
+				remediation.Severity = auditResult.Severity.String()
+			}
+			remediation.Steps = append(remediation.Steps, step)
+			playbook.Findings++
+			affected = true
+		}
+		if affected {
+			playbook.Resources++
+		}
+	}
+
+	for _, remediation := range remediations {
+		playbook.Remediations = append(playbook.Remediations, *remediation)
+	}
+	sort.Slice(playbook.Remediations, func(i, j int) bool {
+		a, b := playbook.Remediations[i], playbook.Remediations[j]
+		if a.severity != b.severity {
+			return a.severity.Compare(b.severity) > 0
+		}
+		if len(a.Steps) != len(b.Steps) {
+			return len(a.Steps) > len(b.Steps)
+		}
+		return a.Rule < b.Rule
+	})
+	return playbook, nil
+}
+
+// newStep returns the step which fixes the finding of the resource. The fix is applied to the resource
+func newStep(resource kubeaudit.KubeResource, auditResult *kubeaudit.AuditResult) (Step, error) {
+	step := Step{
+		Resource: kubeaudit.ResourceName(resource),
+		FilePath: auditResult.FilePath,
+		Message:  redact.String(auditResult.Message),
+	}
+
+	ok, plan := auditResult.FixPlan()
+	if !ok || resource == nil || resource.Object() == nil {
+		return step, nil
+	}
+	step.Fix = plan
+
+	before, err := diff.ResourceYAML(resource.Object())
+	if err != nil {
+		return Step{}, err
+	}
+	newResources := auditResult.Fix(resource.Object())
+	after, err := diff.ResourceYAML(resource.Object())
+	if err != nil {
+		return Step{}, err
+	}
+
+	name := step.Resource
+	example, err := diff.Unified(before, after, "a/"+name, "b/"+name)
+	if err != nil {
+		return Step{}, err
+	}
+	for _, newResource := range newResources {
+		created, err := diff.ResourceYAML(newResource)
+		if err != nil {
+			return Step{}, err
+		}
+		newName := k8s.GetResourceName(newResource)
+		createdDiff, err := diff.Unified(nil, created, "/dev/null", "b/"+newName)
+		if err != nil {
+			return Step{}, err
+		}
+		example += createdDiff
+	}
+	step.Example = redact.String(example)
+	return step, nil
+}
+
+// getDocs returns the URL of the documentation of the rule of the audit result, if any
+func getDocs(auditResult *kubeaudit.AuditResult) string {
+	for _, reference := range auditResult.References {
+		if reference.Type == kubeaudit.ReferenceDocs {
+			return reference.URL
+		}
+	}
+	return ""
+}
+
+// Title returns the heading of the remediation, with its severity and number of findings
+func (r Remediation) Title() string {
+	return fmt.Sprintf("%s (%s, %s)", r.Rule, r.Severity, plural(len(r.Steps), "finding"))
+}
@@ -0,0 +1,77 @@
+package playbook
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtureDir = "fixtures"
+
+func TestCreate(t *testing.T) {
+	playbook, err := Create(getReport(t), kubeaudit.Info)
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, playbook.Findings)
+	assert.Equal(t, 1, playbook.Resources)
+	require.Len(t, playbook.Remediations, 2)
+
+	// Errors come before warnings
+	privilegedTrue := playbook.Remediations[0]
+	assert.Equal(t, privileged.PrivilegedTrue, privilegedTrue.Rule)
+	assert.Equal(t, privileged.Name, privilegedTrue.Auditor)
+	assert.Equal(t, "error", privilegedTrue.Severity)
+	assert.Equal(t, "PrivilegedTrue (error, 2 findings)", privilegedTrue.Title())
+	require.Len(t, privilegedTrue.Steps, 2)
+	for _, step := range privilegedTrue.Steps {
+		assert.Equal(t, "Deployment/playbook/deployment", step.Resource)
+		assert.NotEmpty(t, step.Fix)
+		assert.Contains(t, step.Example, "-            privileged: true\n+            privileged: false\n")
+	}
+
+	// Findings which can't be fixed automatically have no example
+	imageTagMissing := playbook.Remediations[1]
+	assert.Equal(t, image.ImageTagMissing, imageTagMissing.Rule)
+	require.Len(t, imageTagMissing.Steps, 1)
+	assert.Empty(t, imageTagMissing.Steps[0].Fix)
+	assert.Empty(t, imageTagMissing.Steps[0].Example)
+}
+
+func TestCreateMinSeverity(t *testing.T) {
+	playbook, err := Create(getReport(t), kubeaudit.Error)
+	require.NoError(t, err)
+
+	require.Len(t, playbook.Remediations, 1)
+	assert.Equal(t, privileged.PrivilegedTrue, playbook.Remediations[0].Rule)
+	assert.Equal(t, 2, playbook.Findings)
+}
+
+func TestWrite(t *testing.T) {
+	playbook, err := Create(getReport(t), kubeaudit.Info)
+	require.NoError(t, err)
+
+	var markdown bytes.Buffer
+	require.NoError(t, playbook.Write(&markdown, FormatMarkdown))
+	assert.Contains(t, markdown.String(), "3 findings in 1 resource, grouped by remediation")
+	assert.Contains(t, markdown.String(), "## 1. PrivilegedTrue (error, 2 findings)\n")
+	assert.Contains(t, markdown.String(), "```diff\n--- a/Deployment/playbook/deployment\n")
+	assert.Contains(t, markdown.String(), "Fix: "+manualFix+"\n")
+
+	var html bytes.Buffer
+	require.NoError(t, playbook.Write(&html, FormatHTML))
+	assert.Contains(t, html.String(), "<h2 class=\"severity-error\">1. PrivilegedTrue (error, 2 findings)</h2>")
+	assert.Contains(t, html.String(), "&#43;            privileged: false")
+
+	assert.Error(t, playbook.Write(&bytes.Buffer{}, "pdf"))
+}
+
+func getReport(t *testing.T) *kubeaudit.Report {
+	imageAuditor := image.New(image.Config{})
+	return test.GetReport(t, fixtureDir, "playbook.yml", []kubeaudit.Auditable{privileged.New(), imageAuditor}, "", test.MANIFEST_MODE)
+}
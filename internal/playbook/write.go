@@ -0,0 +1,98 @@
+package playbook
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	texttemplate "text/template"
+)
+
+// manualFix is the instruction of the findings which autofix can't fix
+const manualFix = "No automatic fix is available. Change the resource as described by the finding."
+
+var funcs = map[string]interface{}{
+	"inc":       func(i int) int { return i + 1 },
+	"plural":    plural,
+	"manualFix": func() string { return manualFix },
+	// fence returns a code fence longer than any run of backticks in s, so the code block can't be closed early
+	"fence": func(s string) string {
+		fence := "```"
+		for strings.Contains(s, fence) {
+			fence += "`"
+		}
+		return fence
+	},
+}
+
+var markdownTemplate = texttemplate.Must(texttemplate.New("markdown").Funcs(funcs).Parse(`# Remediation playbook
+
+{{plural .Findings "finding"}} in {{plural .Resources "resource"}}, grouped by remediation from the most to the least severe.
+{{range $i, $remediation := .Remediations}}
+## {{inc $i}}. {{$remediation.Title}}
+
+Auditor: ` + "`{{$remediation.Auditor}}`" + `{{if $remediation.Docs}}. Documentation: {{$remediation.Docs}}{{end}}
+{{range $j, $step := $remediation.Steps}}
+### Step {{inc $j}}: {{$step.Resource}}
+{{if $step.FilePath}}
+File: ` + "`{{$step.FilePath}}`" + `
+{{end}}
+Finding: {{$step.Message}}
+
+Fix: {{if $step.Fix}}{{$step.Fix}}{{else}}{{manualFix}}{{end}}
+{{if $step.Example}}{{$fence := fence $step.Example}}
+{{$fence}}diff
+{{$step.Example}}{{$fence}}
+{{end}}{{end}}{{end}}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(funcs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Remediation playbook</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; }
+pre { background: #f6f8fa; padding: 1em; overflow-x: auto; }
+.severity-error { color: #cf222e; }
+.severity-warning { color: #9a6700; }
+</style>
+</head>
+<body>
+<h1>Remediation playbook</h1>
+<p>{{plural .Findings "finding"}} in {{plural .Resources "resource"}}, grouped by remediation from the most to the least severe.</p>
+{{range $i, $remediation := .Remediations}}
+<section>
+<h2 class="severity-{{$remediation.Severity}}">{{inc $i}}. {{$remediation.Title}}</h2>
+<p>Auditor: <code>{{$remediation.Auditor}}</code>{{if $remediation.Docs}}. Documentation: <a href="{{$remediation.Docs}}">{{$remediation.Docs}}</a>{{end}}</p>
+<ol>
+{{range $step := $remediation.Steps}}<li>
+<h3>{{$step.Resource}}</h3>
+{{if $step.FilePath}}<p>File: <code>{{$step.FilePath}}</code></p>
+{{end}}<p>Finding: {{$step.Message}}</p>
+<p>Fix: {{if $step.Fix}}{{$step.Fix}}{{else}}{{manualFix}}{{end}}</p>
+{{if $step.Example}}<pre><code>{{$step.Example}}</code></pre>
+{{end}}</li>
+{{end}}</ol>
+</section>
+{{end}}</body>
+</html>
+`))
+
+// plural returns the count with the word, in the plural unless the count is 1
+func plural(count int, word string) string {
+	if count == 1 {
+		return "1 " + word
+	}
+	return fmt.Sprintf("%d %ss", count, word)
+}
+
+// Write writes the playbook in the format, one of FormatMarkdown or FormatHTML
+func (p *Playbook) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatMarkdown:
+		return markdownTemplate.Execute(w, p)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, p)
+	}
+	return fmt.Errorf("invalid format %q, expected one of %q, %q", format, FormatMarkdown, FormatHTML)
+}
@@ -0,0 +1,178 @@
+// Package references is the registry of the documentation and security framework controls of the rules, which are
+// attached to audit results so that every output can link findings to them
+package references
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/annotations"
+	"github.com/Shopify/kubeaudit/auditors/apparmor"
+	"github.com/Shopify/kubeaudit/auditors/asat"
+	"github.com/Shopify/kubeaudit/auditors/capabilities"
+	"github.com/Shopify/kubeaudit/auditors/deprecatedapis"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	"github.com/Shopify/kubeaudit/auditors/etcd"
+	"github.com/Shopify/kubeaudit/auditors/exposure"
+	"github.com/Shopify/kubeaudit/auditors/hostnet"
+	"github.com/Shopify/kubeaudit/auditors/hostns"
+	"github.com/Shopify/kubeaudit/auditors/hostprocess"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	"github.com/Shopify/kubeaudit/auditors/labels"
+	"github.com/Shopify/kubeaudit/auditors/lifecycle"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/mounts"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
+	"github.com/Shopify/kubeaudit/auditors/nonroot"
+	"github.com/Shopify/kubeaudit/auditors/ports"
+	"github.com/Shopify/kubeaudit/auditors/privesc"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/Shopify/kubeaudit/auditors/rego"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
+	"github.com/Shopify/kubeaudit/auditors/rootfs"
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
+	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	"github.com/Shopify/kubeaudit/auditors/volumes"
+	"github.com/Shopify/kubeaudit/auditors/vulns"
+	"github.com/Shopify/kubeaudit/internal/compliance"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+const (
+	docsBaseURL  = "https://github.com/Shopify/kubeaudit/blob/main/docs/auditors/"
+	pssURL       = "https://kubernetes.io/docs/concepts/security/pod-security-standards/"
+	cweURLFormat = "https://cwe.mitre.org/data/definitions/%d.html"
+)
+
+// auditorReferences are the references shared by the rules of an auditor
+type auditorReferences struct {
+	// kubernetes is the Kubernetes documentation of the feature the auditor checks
+	kubernetes string
+	// cwes are the weaknesses the rules of the auditor report
+	cwes []int
+}
+
+var auditors = map[string]auditorReferences{
+	annotations.Name:    {"https://kubernetes.io/docs/concepts/overview/working-with-objects/annotations/", nil},
+	apparmor.Name:       {"https://kubernetes.io/docs/tutorials/security/apparmor/", []int{693}},
+	asat.Name:           {"https://kubernetes.io/docs/tasks/configure-pod-container/configure-service-account/", []int{668}},
+	capabilities.Name:   {"https://kubernetes.io/docs/tasks/configure-pod-container/security-context/#set-capabilities-for-a-container", []int{250}},
+	deprecatedapis.Name: {"https://kubernetes.io/docs/reference/using-api/deprecation-guide/", []int{477}},
+	egress.Name:         {"https://kubernetes.io/docs/concepts/services-networking/network-policies/", []int{284}},
+	ephemeral.Name:      {"https://kubernetes.io/docs/concepts/workloads/pods/ephemeral-containers/", []int{489}},
+	etcd.Name:           {"https://kubernetes.io/docs/tasks/administer-cluster/encrypt-data/", []int{311}},
+	exposure.Name:       {"https://kubernetes.io/docs/concepts/services-networking/service/#publishing-services-service-types", []int{668}},
+	hostnet.Name:        {"https://kubernetes.io/docs/concepts/configuration/overview/#services", []int{653}},
+	hostns.Name:         {"https://kubernetes.io/docs/concepts/security/pod-security-standards/#baseline", []int{653}},
+	hostprocess.Name:    {"https://kubernetes.io/docs/tasks/configure-pod-container/create-hostprocess-pod/", []int{250}},
+	image.Name:          {"https://kubernetes.io/docs/concepts/containers/images/", []int{1104}},
+	imagepolicy.Name:    {"https://kubernetes.io/docs/concepts/containers/images/", []int{494}},
+	labels.Name:         {"https://kubernetes.io/docs/concepts/overview/working-with-objects/labels/", nil},
+	lifecycle.Name:      {"https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle/", nil},
+	limits.Name:         {"https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/", []int{770}},
+	mounts.Name:         {"https://kubernetes.io/docs/concepts/storage/volumes/#hostpath", []int{668}},
+	netpols.Name:        {"https://kubernetes.io/docs/concepts/services-networking/network-policies/", []int{284}},
+	nodecoverage.Name:   {"https://kubernetes.io/docs/concepts/workloads/controllers/daemonset/", nil},
+	nonroot.Name:        {"https://kubernetes.io/docs/tasks/configure-pod-container/security-context/", []int{250}},
+	ports.Name:          {"https://kubernetes.io/docs/concepts/services-networking/service/", nil},
+	privesc.Name:        {"https://kubernetes.io/docs/tasks/configure-pod-container/security-context/", []int{269}},
+	privileged.Name:     {"https://kubernetes.io/docs/tasks/configure-pod-container/security-context/", []int{250}},
+	pss.Name:            {pssURL, nil},
+	rbac.Name:           {"https://kubernetes.io/docs/reference/access-authn-authz/rbac/", []int{269}},
+	rego.Name:           {"", nil},
+	requests.Name:       {"https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/", []int{770}},
+	resilience.Name:     {"https://kubernetes.io/docs/concepts/scheduling-eviction/topology-spread-constraints/", nil},
+	rootfs.Name:         {"https://kubernetes.io/docs/tasks/configure-pod-container/security-context/", []int{732}},
+	runtimeclass.Name:   {"https://kubernetes.io/docs/concepts/containers/runtime-class/", []int{653}},
+	seccomp.Name:        {"https://kubernetes.io/docs/tutorials/security/seccomp/", []int{693}},
+	secrets.Name:        {"https://kubernetes.io/docs/concepts/security/secrets-good-practices/", []int{798}},
+	volumes.Name:        {"https://kubernetes.io/docs/concepts/storage/projected-volumes/#serviceaccounttoken", []int{732}},
+	vulns.Name:          {"https://kubernetes.io/docs/concepts/containers/images/", []int{1395}},
+}
+
+// pssControls maps the rules of the other auditors to the Pod Security Standards control they check. The rules of the
+// pss auditor are mapped to their own control
+var pssControls = map[string]string{
+	privileged.PrivilegedTrue:                       pss.PSSBaselinePrivilegedContainers,
+	privileged.PrivilegedSuspended:                  pss.PSSBaselinePrivilegedContainers,
+	hostns.NamespaceHostNetworkTrue:                 pss.PSSBaselineHostNamespaces,
+	hostns.NamespaceHostIPCTrue:                     pss.PSSBaselineHostNamespaces,
+	hostns.NamespaceHostPIDTrue:                     pss.PSSBaselineHostNamespaces,
+	hostprocess.HostProcessTruePod:                  pss.PSSBaselineHostProcess,
+	hostprocess.HostProcessTrueContainer:            pss.PSSBaselineHostProcess,
+	hostnet.HostPortSet:                             pss.PSSBaselineHostPorts,
+	mounts.SensitivePathsMounted:                    pss.PSSBaselineHostPathVolumes,
+	apparmor.AppArmorDisabled:                       pss.PSSBaselineAppArmor,
+	seccomp.SeccompDisabledPod:                      pss.PSSBaselineSeccomp,
+	seccomp.SeccompDisabledContainer:                pss.PSSBaselineSeccomp,
+	seccomp.SeccompProfileMissing:                   pss.PSSRestrictedSeccomp,
+	privesc.AllowPrivilegeEscalationNil:             pss.PSSRestrictedPrivilegeEscalation,
+	privesc.AllowPrivilegeEscalationTrue:            pss.PSSRestrictedPrivilegeEscalation,
+	nonroot.RunAsNonRootCSCFalse:                    pss.PSSRestrictedRunningAsNonRoot,
+	nonroot.RunAsNonRootPSCNilCSCNil:                pss.PSSRestrictedRunningAsNonRoot,
+	nonroot.RunAsNonRootPSCFalseCSCNil:              pss.PSSRestrictedRunningAsNonRoot,
+	nonroot.RunAsUserCSCRoot:                        pss.PSSRestrictedRunningAsNonRootUser,
+	nonroot.RunAsUserPSCRoot:                        pss.PSSRestrictedRunningAsNonRootUser,
+	capabilities.CapabilityShouldDropAll:            pss.PSSRestrictedCapabilities,
+	capabilities.CapabilityAdded:                    pss.PSSBaselineCapabilities,
+	capabilities.CapabilityOrSecurityContextMissing: pss.PSSRestrictedCapabilities,
+}
+
+// Get returns the references of a rule reported by an auditor, in the order: kubeaudit documentation, Kubernetes
+// documentation, Pod Security Standards, CIS, NSA and CWE. Overridden rules have the references of the rule they
+// override. Rules of unknown auditors, such as custom auditors, only have the references of their rule
+func Get(auditor, rule string) []kubeaudit.Reference {
+	auditor = strings.ToLower(auditor)
+	rule = strings.TrimSuffix(rule, override.GetOverriddenResultName(""))
+
+	var references []kubeaudit.Reference
+	auditorRefs, known := auditors[auditor]
+	if known {
+		references = append(references, kubeaudit.Reference{Type: kubeaudit.ReferenceDocs, URL: docsBaseURL + auditor + ".md"})
+		if auditorRefs.kubernetes != "" {
+			references = append(references, kubeaudit.Reference{Type: kubeaudit.ReferenceKubernetes, URL: auditorRefs.kubernetes})
+		}
+	}
+
+	pssRule, ok := pssControls[rule]
+	if !ok {
+		pssRule = rule
+	}
+	if control, ok := pss.GetControl(pssRule); ok {
+		references = append(references, kubeaudit.Reference{
+			Type: kubeaudit.ReferencePSS,
+			ID:   control.Level + "/" + control.Name,
+			URL:  pssURL + "#" + control.Level,
+		})
+	}
+
+	for _, benchmark := range compliance.Benchmarks {
+		for _, control := range benchmark.Controls {
+			if control.HasRule(rule) {
+				references = append(references, kubeaudit.Reference{
+					Type: kubeaudit.ReferenceType(benchmark.Name),
+					ID:   control.ID,
+					URL:  benchmark.URL,
+				})
+			}
+		}
+	}
+
+	for _, cwe := range auditorRefs.cwes {
+		references = append(references, kubeaudit.Reference{
+			Type: kubeaudit.ReferenceCWE,
+			ID:   fmt.Sprintf("CWE-%d", cwe),
+			URL:  fmt.Sprintf(cweURLFormat, cwe),
+		})
+	}
+
+	return references
+}
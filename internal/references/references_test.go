@@ -0,0 +1,41 @@
+package references
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/capabilities"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+func TestGet(t *testing.T) {
+	expected := []kubeaudit.Reference{
+		{Type: kubeaudit.ReferenceDocs, URL: "https://github.com/Shopify/kubeaudit/blob/main/docs/auditors/capabilities.md"},
+		{Type: kubeaudit.ReferenceKubernetes, URL: "https://kubernetes.io/docs/tasks/configure-pod-container/security-context/#set-capabilities-for-a-container"},
+		{Type: kubeaudit.ReferencePSS, ID: "baseline/Capabilities", URL: "https://kubernetes.io/docs/concepts/security/pod-security-standards/#baseline"},
+		{Type: kubeaudit.ReferenceCIS, ID: "5.2.9", URL: "https://www.cisecurity.org/benchmark/kubernetes"},
+		{Type: kubeaudit.ReferenceNSA, ID: "pod-security-enforcement", URL: "https://media.defense.gov/2022/Aug/29/2003066362/-1/-1/0/CTR_KUBERNETES_HARDENING_GUIDANCE_1.2_20220829.PDF"},
+		{Type: kubeaudit.ReferenceCWE, ID: "CWE-250", URL: "https://cwe.mitre.org/data/definitions/250.html"},
+	}
+	assert.Equal(t, expected, Get("Capabilities", capabilities.CapabilityAdded))
+
+	// Overridden rules have the references of the rule they override
+	assert.Equal(t, expected, Get(capabilities.Name, override.GetOverriddenResultName(capabilities.CapabilityAdded)))
+}
+
+func TestGetPodSecurityStandardsRule(t *testing.T) {
+	references := Get(pss.Name, pss.PSSRestrictedRunningAsNonRoot)
+	assert.Contains(t, references, kubeaudit.Reference{
+		Type: kubeaudit.ReferencePSS,
+		ID:   "restricted/Running as Non-root",
+		URL:  "https://kubernetes.io/docs/concepts/security/pod-security-standards/#restricted",
+	})
+	assert.Contains(t, references, kubeaudit.Reference{Type: kubeaudit.ReferenceCIS, ID: "5.2.7", URL: "https://www.cisecurity.org/benchmark/kubernetes"})
+}
+
+func TestGetUnknownAuditor(t *testing.T) {
+	assert.Empty(t, Get("custom", "CustomRule"))
+}
@@ -0,0 +1,85 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	dockerHubDomain   = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// Reference is a parsed image reference, eg. "gcr.io/distroless/static:nonroot"
+type Reference struct {
+	// Registry is the host of the registry API, with its port if it has one
+	Registry   string
+	Repository string
+	// Tag is empty if the reference has a digest
+	Tag    string
+	Digest string
+}
+
+// ParseReference parses an image reference the way container runtimes do: references without a registry are images
+// of Docker Hub, Docker Hub images without a namespace are in the "library" namespace, and references without a tag
+// or digest have the "latest" tag
+func ParseReference(image string) (Reference, error) {
+	var ref Reference
+	name := image
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+		if !strings.Contains(ref.Digest, ":") {
+			return Reference{}, fmt.Errorf("invalid image reference %q: invalid digest", image)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+	}
+	if name == "" || (ref.Tag == "" && strings.HasSuffix(image, ":")) {
+		return Reference{}, fmt.Errorf("invalid image reference %q", image)
+	}
+
+	ref.Registry = dockerHubDomain
+	ref.Repository = name
+	if i := strings.Index(name, "/"); i >= 0 && isDomain(name[:i]) {
+		ref.Registry, ref.Repository = name[:i], name[i+1:]
+	}
+	if ref.Registry == dockerHubDomain || ref.Registry == "index.docker.io" {
+		ref.Registry = dockerHubRegistry
+		if !strings.Contains(ref.Repository, "/") {
+			ref.Repository = "library/" + ref.Repository
+		}
+	}
+	if ref.Repository != strings.ToLower(ref.Repository) {
+		return Reference{}, fmt.Errorf("invalid image reference %q: repository names must be lowercase", image)
+	}
+
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = "latest"
+	}
+	return ref, nil
+}
+
+// isDomain returns true if the first component of an image name is a registry rather than a Docker Hub namespace
+func isDomain(component string) bool {
+	return strings.ContainsAny(component, ".:") || component == "localhost"
+}
+
+// manifestReference returns the digest of the reference, or its tag if it has no digest
+func (r Reference) manifestReference() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+func (r Reference) String() string {
+	s := r.Registry + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
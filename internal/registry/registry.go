@@ -0,0 +1,483 @@
+// Package registry inspects images with the registry HTTP API, so auditors can check what an image runs as and which
+// platforms it is built for rather than only its reference. Images are cached by digest, since the content of a
+// digest never changes
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// manifestAccept are the manifest media types kubeaudit can inspect, for the Accept header of manifest requests
+var manifestAccept = strings.Join([]string{mediaTypeOCIIndex, mediaTypeDockerManifestList, mediaTypeOCIManifest, mediaTypeDockerManifest}, ", ")
+
+// maxDocumentSize is the maximum size of the manifests and configs read from registries
+const maxDocumentSize = 4 << 20
+
+// defaultTimeout is the timeout of each request to a registry
+const defaultTimeout = 30 * time.Second
+
+// ErrUnauthorized is returned when the registry requires credentials which are not in the Docker config, or rejects them
+var ErrUnauthorized = errors.New("unauthorized")
+
+// Image is the configuration of an image
+type Image struct {
+	Reference Reference
+	// Digest is the digest of the manifest or index the reference resolves to
+	Digest string
+	// Platforms are the platforms of a multi-arch image, or the platform of the config of a single-arch image
+	Platforms []Platform
+	// User is the user the image runs as, eg. "nonroot" or "65532:65532". It is empty if the image runs as root
+	User string
+	// Healthcheck is the test of the HEALTHCHECK of the image, eg. ["CMD", "curl", "-f", "http://localhost/"], or nil if
+	// it doesn't have one
+	Healthcheck []string
+}
+
+// Platform is an operating system and architecture an image is built for
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// Client inspects images. It is safe for concurrent use
+type Client struct {
+	httpClient  *http.Client
+	credentials map[string]credential
+
+	mu sync.Mutex
+	// tokens are the bearer tokens of the repositories
+	tokens map[string]string
+	// digests are the digests tags resolve to
+	digests map[string]string
+	// images are the inspected images by digest
+	images map[string]*Image
+	// failures are the errors of references which could not be inspected, so they are not requested again
+	failures map[string]error
+}
+
+type credential struct {
+	username string
+	password string
+}
+
+// NewClient returns a client which authenticates with the credentials of the Docker config file, at
+// $DOCKER_CONFIG/config.json or ~/.docker/config.json, or anonymously to registries without credentials
+func NewClient() *Client {
+	return newClient(&http.Client{Timeout: defaultTimeout}, loadCredentials())
+}
+
+func newClient(httpClient *http.Client, credentials map[string]credential) *Client {
+	return &Client{
+		httpClient:  httpClient,
+		credentials: credentials,
+		tokens:      map[string]string{},
+		digests:     map[string]string{},
+		images:      map[string]*Image{},
+		failures:    map[string]error{},
+	}
+}
+
+// Inspect returns the configuration of the image. The configuration of multi-arch images is the one of the linux/amd64
+// image, or of the first image if there isn't one
+func (c *Client) Inspect(ctx context.Context, image string) (*Image, error) {
+	ref, err := ParseReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	err = c.failures[ref.String()]
+	c.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	inspected, err := c.inspect(ctx, ref)
+	if err != nil && ctx.Err() == nil {
+		c.mu.Lock()
+		c.failures[ref.String()] = err
+		c.mu.Unlock()
+	}
+	return inspected, err
+}
+
+func (c *Client) inspect(ctx context.Context, ref Reference) (*Image, error) {
+	digest := ref.Digest
+	if digest == "" {
+		c.mu.Lock()
+		digest = c.digests[ref.String()]
+		c.mu.Unlock()
+	}
+	if cached := c.cachedImage(ref, digest); cached != nil {
+		return cached, nil
+	}
+
+	body, mediaType, digest, err := c.getManifest(ctx, ref, ref.manifestReference())
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.digests[ref.String()] = digest
+	c.mu.Unlock()
+	if cached := c.cachedImage(ref, digest); cached != nil {
+		return cached, nil
+	}
+
+	inspected := &Image{Reference: ref, Digest: digest}
+	configDigest := ""
+	switch mediaType {
+	case mediaTypeOCIIndex, mediaTypeDockerManifestList:
+		var index struct {
+			Manifests []struct {
+				Digest   string    `json:"digest"`
+				Platform *Platform `json:"platform"`
+			} `json:"manifests"`
+		}
+		if err := json.Unmarshal(body, &index); err != nil {
+			return nil, fmt.Errorf("error parsing index of %s: %w", ref, err)
+		}
+
+		manifestDigest := ""
+		for _, manifest := range index.Manifests {
+			// Attestations and signatures are stored as manifests with an unknown platform
+			if manifest.Platform == nil || manifest.Platform.OS == "unknown" || manifest.Platform.Architecture == "unknown" {
+				continue
+			}
+			inspected.Platforms = append(inspected.Platforms, *manifest.Platform)
+			if manifestDigest == "" || (manifest.Platform.OS == "linux" && manifest.Platform.Architecture == "amd64") {
+				manifestDigest = manifest.Digest
+			}
+		}
+		if manifestDigest == "" {
+			return nil, fmt.Errorf("index of %s has no images", ref)
+		}
+
+		body, mediaType, _, err = c.getManifest(ctx, ref, manifestDigest)
+		if err != nil {
+			return nil, err
+		}
+		if configDigest, err = getConfigDigest(body); err != nil {
+			return nil, fmt.Errorf("error parsing manifest of %s: %w", ref, err)
+		}
+	case mediaTypeOCIManifest, mediaTypeDockerManifest:
+		if configDigest, err = getConfigDigest(body); err != nil {
+			return nil, fmt.Errorf("error parsing manifest of %s: %w", ref, err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported manifest media type %q of %s", mediaType, ref)
+	}
+
+	configBody, err := c.get(ctx, ref, "blobs/"+configDigest, "")
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Platform
+		Config struct {
+			User        string `json:"User"`
+			Healthcheck *struct {
+				Test []string `json:"Test"`
+			} `json:"Healthcheck"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(configBody, &config); err != nil {
+		return nil, fmt.Errorf("error parsing config of %s: %w", ref, err)
+	}
+	if inspected.Platforms == nil {
+		inspected.Platforms = []Platform{config.Platform}
+	}
+	inspected.User = config.Config.User
+	// A HEALTHCHECK NONE disables the healthcheck of the base image
+	if healthcheck := config.Config.Healthcheck; healthcheck != nil && len(healthcheck.Test) > 0 && healthcheck.Test[0] != "NONE" {
+		inspected.Healthcheck = healthcheck.Test
+	}
+
+	c.mu.Lock()
+	c.images[digest] = inspected
+	c.mu.Unlock()
+	return inspected, nil
+}
+
+// cachedImage returns the inspected image of the digest with the reference, or nil if it wasn't inspected yet
+func (c *Client) cachedImage(ref Reference, digest string) *Image {
+	if digest == "" {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cached, ok := c.images[digest]
+	if !ok {
+		return nil
+	}
+	image := *cached
+	image.Reference = ref
+	return &image
+}
+
+func getConfigDigest(manifestBody []byte) (string, error) {
+	var manifest struct {
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal(manifestBody, &manifest); err != nil {
+		return "", err
+	}
+	if manifest.Config.Digest == "" {
+		return "", errors.New("no config")
+	}
+	return manifest.Config.Digest, nil
+}
+
+// getManifest returns the manifest of the tag or digest, with its media type and digest
+func (c *Client) getManifest(ctx context.Context, ref Reference, reference string) (body []byte, mediaType, digest string, err error) {
+	resp, body, err := c.do(ctx, ref, "manifests/"+reference, manifestAccept)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	mediaType = strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	if mediaType == "" || mediaType == "application/json" {
+		var manifest struct {
+			MediaType string `json:"mediaType"`
+		}
+		if err := json.Unmarshal(body, &manifest); err == nil {
+			mediaType = manifest.MediaType
+		}
+	}
+
+	digest = resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		digest = fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+	}
+	return body, mediaType, digest, nil
+}
+
+func (c *Client) get(ctx context.Context, ref Reference, path, accept string) ([]byte, error) {
+	_, body, err := c.do(ctx, ref, path, accept)
+	return body, err
+}
+
+// do sends a GET request to the API of the repository of the reference. If the registry requires authentication, the
+// request is retried with a bearer token or basic authentication
+func (c *Client) do(ctx context.Context, ref Reference, path, accept string) (*http.Response, []byte, error) {
+	endpoint := fmt.Sprintf("%s://%s/v2/%s/%s", scheme(ref.Registry), ref.Registry, ref.Repository, path)
+	scope := ref.Registry + "/" + ref.Repository
+
+	c.mu.Lock()
+	token := c.tokens[scope]
+	c.mu.Unlock()
+
+	resp, body, err := c.send(ctx, endpoint, accept, func(req *http.Request) {
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		authorize, err := c.authorize(ctx, ref, scope, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return nil, nil, err
+		}
+		if resp, body, err = c.send(ctx, endpoint, accept, authorize); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return nil, nil, fmt.Errorf("%w: %s returned %s for %s", ErrUnauthorized, ref.Registry, resp.Status, ref)
+	case resp.StatusCode != http.StatusOK:
+		return nil, nil, fmt.Errorf("%s returned %s for %s", ref.Registry, resp.Status, ref)
+	}
+	return resp, body, nil
+}
+
+func (c *Client) send(ctx context.Context, endpoint, accept string, authorize func(*http.Request)) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// authorize returns a function which authorizes requests as required by the challenge of the registry
+func (c *Client) authorize(ctx context.Context, ref Reference, scope, challenge string) (func(*http.Request), error) {
+	cred, hasCredential := c.credentials[ref.Registry]
+	scheme, params := parseChallenge(challenge)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if !hasCredential {
+			return nil, fmt.Errorf("%w: %s requires credentials for %s", ErrUnauthorized, ref.Registry, ref)
+		}
+		return func(req *http.Request) { req.SetBasicAuth(cred.username, cred.password) }, nil
+	case "bearer":
+		tokenURL, err := url.Parse(params["realm"])
+		if err != nil || params["realm"] == "" {
+			return nil, fmt.Errorf("invalid authentication challenge of %s: %q", ref.Registry, challenge)
+		}
+		query := tokenURL.Query()
+		if service := params["service"]; service != "" {
+			query.Set("service", service)
+		}
+		query.Set("scope", "repository:"+ref.Repository+":pull")
+		tokenURL.RawQuery = query.Encode()
+
+		resp, body, err := c.send(ctx, tokenURL.String(), "", func(req *http.Request) {
+			if hasCredential {
+				req.SetBasicAuth(cred.username, cred.password)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%w: token service of %s returned %s for %s", ErrUnauthorized, ref.Registry, resp.Status, ref)
+		}
+		var tokenResponse struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		if err := json.Unmarshal(body, &tokenResponse); err != nil {
+			return nil, fmt.Errorf("invalid token of %s: %w", ref.Registry, err)
+		}
+		token := tokenResponse.Token
+		if token == "" {
+			token = tokenResponse.AccessToken
+		}
+
+		c.mu.Lock()
+		c.tokens[scope] = token
+		c.mu.Unlock()
+		return func(req *http.Request) { req.Header.Set("Authorization", "Bearer "+token) }, nil
+	}
+	return nil, fmt.Errorf("unsupported authentication challenge of %s: %q", ref.Registry, challenge)
+}
+
+// parseChallenge parses a WWW-Authenticate header, eg. `Bearer realm="https://auth.docker.io/token",service="x"`
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		var key, value string
+		key, rest, _ = strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if strings.HasPrefix(rest, `"`) {
+			value, rest, _ = strings.Cut(rest[1:], `"`)
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		params[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return scheme, params
+}
+
+// scheme returns http for registries on the local host, which usually don't have TLS, and https otherwise
+func scheme(registry string) string {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	if host == "localhost" || net.ParseIP(host).IsLoopback() {
+		return "http"
+	}
+	return "https"
+}
+
+// loadCredentials returns the credentials of the auths of the Docker config file by registry. Credential helpers are
+// not supported
+func loadCredentials() map[string]credential {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil
+	}
+	return parseCredentials(data)
+}
+
+func parseCredentials(data []byte) map[string]credential {
+	var config struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+
+	credentials := map[string]credential{}
+	for server, auth := range config.Auths {
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			continue
+		}
+		username, password, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			continue
+		}
+
+		// Servers are hosts or URLs, and Docker Hub is "https://index.docker.io/v1/"
+		host := strings.TrimPrefix(strings.TrimPrefix(server, "https://"), "http://")
+		host, _, _ = strings.Cut(host, "/")
+		if host == dockerHubDomain || host == "index.docker.io" {
+			host = dockerHubRegistry
+		}
+		credentials[host] = credential{username: username, password: password}
+	}
+	return credentials
+}
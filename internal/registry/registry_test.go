@@ -0,0 +1,168 @@
+package registry
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	cases := []struct {
+		image    string
+		expected Reference
+	}{
+		{"nginx", Reference{Registry: dockerHubRegistry, Repository: "library/nginx", Tag: "latest"}},
+		{"nginx:1.25", Reference{Registry: dockerHubRegistry, Repository: "library/nginx", Tag: "1.25"}},
+		{"docker.io/bitnami/redis:7", Reference{Registry: dockerHubRegistry, Repository: "bitnami/redis", Tag: "7"}},
+		{"gcr.io/distroless/static:nonroot", Reference{Registry: "gcr.io", Repository: "distroless/static", Tag: "nonroot"}},
+		{"localhost:5000/app", Reference{Registry: "localhost:5000", Repository: "app", Tag: "latest"}},
+		{"quay.io/app@sha256:abc", Reference{Registry: "quay.io", Repository: "app", Digest: "sha256:abc"}},
+		{"quay.io/app:1.0@sha256:abc", Reference{Registry: "quay.io", Repository: "app", Tag: "1.0", Digest: "sha256:abc"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.image, func(t *testing.T) {
+			ref, err := ParseReference(tc.image)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, ref)
+		})
+	}
+
+	for _, image := range []string{"", "nginx:", "Nginx", "nginx@abc"} {
+		_, err := ParseReference(image)
+		assert.Error(t, err, image)
+	}
+}
+
+// registry is a fake registry with a multi-arch "app" image, which requires a bearer token
+type registry struct {
+	server   *httptest.Server
+	requests int32
+}
+
+func newRegistry(t *testing.T) *registry {
+	r := &registry{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, req *http.Request) {
+		if username, password, ok := req.BasicAuth(); !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "repository:team/app:pull", req.URL.Query().Get("scope"))
+		fmt.Fprint(w, `{"token":"token"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, req *http.Request) {
+		atomic.AddInt32(&r.requests, 1)
+		if req.Header.Get("Authorization") != "Bearer token" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, r.server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch req.URL.Path {
+		case "/v2/team/app/manifests/1.0", "/v2/team/app/manifests/sha256:index":
+			w.Header().Set("Content-Type", mediaTypeOCIIndex)
+			w.Header().Set("Docker-Content-Digest", "sha256:index")
+			fmt.Fprint(w, `{"manifests":[
+				{"digest":"sha256:arm64","platform":{"os":"linux","architecture":"arm64","variant":"v8"}},
+				{"digest":"sha256:amd64","platform":{"os":"linux","architecture":"amd64"}},
+				{"digest":"sha256:attestation","platform":{"os":"unknown","architecture":"unknown"}}
+			]}`)
+		case "/v2/team/app/manifests/sha256:amd64":
+			w.Header().Set("Content-Type", mediaTypeOCIManifest)
+			fmt.Fprint(w, `{"config":{"digest":"sha256:config"}}`)
+		case "/v2/team/app/blobs/sha256:config":
+			fmt.Fprint(w, `{"os":"linux","architecture":"amd64","config":{"User":"65532","Healthcheck":{"Test":["CMD","/healthz"]}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	r.server = httptest.NewServer(mux)
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *registry) client() *Client {
+	host := strings.TrimPrefix(r.server.URL, "http://")
+	return newClient(r.server.Client(), map[string]credential{host: {username: "user", password: "secret"}})
+}
+
+func (r *registry) image(reference string) string {
+	return strings.TrimPrefix(r.server.URL, "http://") + "/team/app" + reference
+}
+
+func TestInspect(t *testing.T) {
+	r := newRegistry(t)
+
+	image, err := r.client().Inspect(context.Background(), r.image(":1.0"))
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:index", image.Digest)
+	assert.Equal(t, []Platform{{OS: "linux", Architecture: "arm64", Variant: "v8"}, {OS: "linux", Architecture: "amd64"}}, image.Platforms)
+	assert.Equal(t, "65532", image.User)
+	assert.Equal(t, []string{"CMD", "/healthz"}, image.Healthcheck)
+}
+
+func TestInspectCache(t *testing.T) {
+	r := newRegistry(t)
+	client := r.client()
+
+	_, err := client.Inspect(context.Background(), r.image(":1.0"))
+	require.NoError(t, err)
+	requests := atomic.LoadInt32(&r.requests)
+
+	// The tag was resolved to the digest, and the image of the digest was inspected
+	for _, reference := range []string{":1.0", "@sha256:index"} {
+		image, err := client.Inspect(context.Background(), r.image(reference))
+		require.NoError(t, err)
+		assert.Equal(t, "65532", image.User)
+		assert.Equal(t, r.image(reference), image.Reference.String())
+	}
+	assert.Equal(t, requests, atomic.LoadInt32(&r.requests))
+}
+
+func TestInspectErrors(t *testing.T) {
+	r := newRegistry(t)
+
+	_, err := newClient(r.server.Client(), nil).Inspect(context.Background(), r.image(":1.0"))
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	client := r.client()
+	_, err = client.Inspect(context.Background(), r.image(":missing"))
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	// Failures are cached too
+	requests := atomic.LoadInt32(&r.requests)
+	_, err = client.Inspect(context.Background(), r.image(":missing"))
+	assert.ErrorContains(t, err, "404 Not Found")
+	assert.Equal(t, requests, atomic.LoadInt32(&r.requests))
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/nginx:pull"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:library/nginx:pull",
+	}, params)
+}
+
+func TestParseCredentials(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	credentials := parseCredentials([]byte(`{"auths":{
+		"https://index.docker.io/v1/":{"auth":"` + auth + `"},
+		"ghcr.io":{"auth":"` + auth + `"},
+		"invalid.io":{"auth":"invalid"}
+	}}`))
+	assert.Equal(t, map[string]credential{
+		dockerHubRegistry: {username: "user", password: "secret"},
+		"ghcr.io":         {username: "user", password: "secret"},
+	}, credentials)
+}
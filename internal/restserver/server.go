@@ -0,0 +1,160 @@
+// Package restserver implements the HTTP API of kubeaudit serve, which audits manifests on demand for developer portals
+// and bots, with the same auditors and config as the periodic audits
+package restserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/notify"
+	"github.com/Shopify/kubeaudit/internal/sarif"
+)
+
+// MaxManifestBytes is the maximum size of the manifest of a request
+const MaxManifestBytes = 10 * 1024 * 1024
+
+const (
+	// FormatJSON returns the findings as a JSON object, the same as the payload of notifications
+	FormatJSON = "json"
+	// FormatSARIF returns the findings as a SARIF log
+	FormatSARIF = "sarif"
+
+	sarifContentType = "application/sarif+json"
+)
+
+// Config configures how audit requests are handled
+type Config struct {
+	// MinSeverity is the lowest severity of the findings returned, unless a request sets its own with the minSeverity
+	// query parameter
+	MinSeverity kubeaudit.SeverityLevel
+	// MaxConcurrentAudits is the number of manifests audited at the same time. Requests beyond it wait for an audit to
+	// finish, so a burst of requests doesn't take all the CPU and memory. Defaults to 1
+	MaxConcurrentAudits int
+	// Redact, if set, returns the report of each audit with the names which must not leave kubeaudit redacted, such as
+	// with kubeaudit.Report.Redact. It is applied before the findings are returned in any format
+	Redact func(report *kubeaudit.Report) *kubeaudit.Report
+}
+
+// Response is the body of the response to an audit request in the JSON format
+type Response struct {
+	Findings []notify.Finding `json:"findings"`
+}
+
+// Handler is an HTTP handler which audits the manifests POSTed to it
+type Handler struct {
+	auditor     *kubeaudit.Kubeaudit
+	minSeverity kubeaudit.SeverityLevel
+	redact      func(report *kubeaudit.Report) *kubeaudit.Report
+	// audits holds a value for each audit in progress, to limit the number of concurrent audits
+	audits chan struct{}
+}
+
+// NewHandler returns a handler which audits manifests with the auditor
+func NewHandler(auditor *kubeaudit.Kubeaudit, config Config) *Handler {
+	if config.MaxConcurrentAudits < 1 {
+		config.MaxConcurrentAudits = 1
+	}
+	return &Handler{
+		auditor:     auditor,
+		minSeverity: config.MinSeverity,
+		redact:      config.Redact,
+		audits:      make(chan struct{}, config.MaxConcurrentAudits),
+	}
+}
+
+// ServeHTTP implements http.Handler. The body of the request is one or more YAML or JSON documents. The findings are
+// returned as JSON, or as SARIF if the format query parameter is "sarif" or the request accepts application/sarif+json
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := getFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	minSeverity := h.minSeverity
+	if value := r.URL.Query().Get("minSeverity"); value != "" {
+		if minSeverity, err = kubeaudit.ParseSeverity(value); err != nil {
+			http.Error(w, fmt.Sprintf("invalid minSeverity: %s", err), http.StatusBadRequest)
+			return
+		}
+	}
+
+	manifest, err := io.ReadAll(io.LimitReader(r.Body, MaxManifestBytes+1))
+	if err != nil {
+		http.Error(w, "error reading the manifest", http.StatusBadRequest)
+		return
+	}
+	if len(manifest) > MaxManifestBytes {
+		http.Error(w, fmt.Sprintf("the manifest is larger than %d bytes", MaxManifestBytes), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if len(bytes.TrimSpace(manifest)) == 0 {
+		http.Error(w, "the manifest is empty", http.StatusBadRequest)
+		return
+	}
+
+	select {
+	case h.audits <- struct{}{}:
+		defer func() { <-h.audits }()
+	case <-r.Context().Done():
+		// The client is gone, so there is no one to answer
+		return
+	}
+
+	report, err := h.auditor.AuditManifest("", bytes.NewReader(manifest))
+	if err != nil {
+		// Manifests which can't be audited are invalid, such as manifests which are not YAML
+		http.Error(w, fmt.Sprintf("error auditing the manifest: %s", err), http.StatusUnprocessableEntity)
+		return
+	}
+	if h.redact != nil {
+		report = h.redact(report)
+	}
+
+	switch format {
+	case FormatSARIF:
+		w.Header().Set("Content-Type", sarifContentType)
+		err = sarif.Write(w, kubeaudit.NewReport(report.ResultsWithMinSeverity(minSeverity)), sarif.WriteOptions{})
+	default:
+		response := Response{Findings: notify.Findings(report, minSeverity)}
+		if response.Findings == nil {
+			response.Findings = []notify.Finding{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(response)
+	}
+	if err != nil {
+		log.WithError(err).Error("Error writing the findings of an audit request")
+	}
+}
+
+// getFormat returns the format of the findings requested with the format query parameter, or with the Accept header
+func getFormat(r *http.Request) (string, error) {
+	switch format := r.URL.Query().Get("format"); format {
+	case FormatJSON, FormatSARIF:
+		return format, nil
+	case "":
+	default:
+		return "", fmt.Errorf("invalid format %q, expected one of %q, %q", format, FormatJSON, FormatSARIF)
+	}
+
+	for _, accepted := range strings.Split(r.Header.Get("Accept"), ",") {
+		if mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted)); err == nil && mediaType == sarifContentType {
+			return FormatSARIF, nil
+		}
+	}
+	return FormatJSON, nil
+}
@@ -0,0 +1,164 @@
+package restserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+)
+
+const privilegedPod = `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+  namespace: default
+spec:
+  containers:
+  - name: container
+    image: scratch
+    securityContext:
+      privileged: true
+`
+
+func newTestHandler(t *testing.T) *Handler {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New(), image.New(image.Config{})})
+	require.NoError(t, err)
+	return NewHandler(auditor, Config{MinSeverity: kubeaudit.Info, MaxConcurrentAudits: 2})
+}
+
+func TestServeHTTP(t *testing.T) {
+	cases := []struct {
+		testName      string
+		query         string
+		minSeverity   kubeaudit.SeverityLevel
+		expectedRules []string
+	}{
+		{"All findings", "", kubeaudit.Info, []string{"PrivilegedTrue", "ImageTagMissing"}},
+		{"Findings of the minSeverity of the request", "?minSeverity=error", kubeaudit.Info, []string{"PrivilegedTrue"}},
+		{"Findings of the minSeverity of the handler", "", kubeaudit.Error, []string{"PrivilegedTrue"}},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			handler := newTestHandler(t)
+			handler.minSeverity = tc.minSeverity
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/audit"+tc.query, strings.NewReader(privilegedPod)))
+			require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+			assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+			var response Response
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			var rules []string
+			for _, finding := range response.Findings {
+				rules = append(rules, finding.Rule)
+				assert.Equal(t, "Pod", finding.Kind)
+				assert.Equal(t, "default", finding.Namespace)
+				assert.Equal(t, "pod", finding.Name)
+			}
+			assert.ElementsMatch(t, tc.expectedRules, rules)
+		})
+	}
+}
+
+func TestServeHTTPSARIF(t *testing.T) {
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodPost, "/v1/audit?format=sarif", strings.NewReader(privilegedPod)),
+		httptest.NewRequest(http.MethodPost, "/v1/audit", strings.NewReader(privilegedPod)),
+	} {
+		if request.URL.RawQuery == "" {
+			request.Header.Set("Accept", "application/json, application/sarif+json; q=0.9")
+		}
+		recorder := httptest.NewRecorder()
+		newTestHandler(t).ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Equal(t, "application/sarif+json", recorder.Header().Get("Content-Type"))
+		assert.Contains(t, recorder.Body.String(), `"ruleId": "PrivilegedTrue"`)
+	}
+}
+
+func TestServeHTTPRedact(t *testing.T) {
+	handler := newTestHandler(t)
+	handler.redact = func(report *kubeaudit.Report) *kubeaudit.Report { return report.Redact(kubeaudit.RedactMask) }
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/audit", strings.NewReader(privilegedPod)))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	var response Response
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.NotEmpty(t, response.Findings)
+	for _, finding := range response.Findings {
+		assert.Equal(t, "[REDACTED]", finding.Namespace)
+		assert.Equal(t, "[REDACTED]", finding.Name)
+	}
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/audit?format=sarif", strings.NewReader(privilegedPod)))
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.NotContains(t, recorder.Body.String(), "scratch")
+}
+
+func TestServeHTTPNoFindings(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	newTestHandler(t).ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/audit", strings.NewReader("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: default\n")))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `{"findings": []}`, recorder.Body.String())
+}
+
+func TestServeHTTPErrors(t *testing.T) {
+	cases := []struct {
+		testName     string
+		request      *http.Request
+		expectedCode int
+	}{
+		{"GET is not allowed", httptest.NewRequest(http.MethodGet, "/v1/audit", nil), http.StatusMethodNotAllowed},
+		{"Empty manifest", httptest.NewRequest(http.MethodPost, "/v1/audit", strings.NewReader("  \n")), http.StatusBadRequest},
+		{"Invalid format", httptest.NewRequest(http.MethodPost, "/v1/audit?format=xml", strings.NewReader(privilegedPod)), http.StatusBadRequest},
+		{"Invalid minSeverity", httptest.NewRequest(http.MethodPost, "/v1/audit?minSeverity=critical", strings.NewReader(privilegedPod)), http.StatusBadRequest},
+		{"Manifest too large", httptest.NewRequest(http.MethodPost, "/v1/audit", strings.NewReader(strings.Repeat("#", MaxManifestBytes+1))), http.StatusRequestEntityTooLarge},
+	}
+
+	handler := newTestHandler(t)
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, tc.request)
+			assert.Equal(t, tc.expectedCode, recorder.Code, recorder.Body.String())
+		})
+	}
+}
+
+func TestServeHTTPConcurrentAudits(t *testing.T) {
+	handler := newTestHandler(t)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	errs := make(chan error, 10)
+	for i := 0; i < cap(errs); i++ {
+		go func() {
+			response, err := http.Post(server.URL+"/v1/audit", "application/yaml", strings.NewReader(privilegedPod))
+			if err == nil {
+				response.Body.Close()
+				if response.StatusCode != http.StatusOK {
+					err = assert.AnError
+				}
+			}
+			errs <- err
+		}()
+	}
+	for i := 0; i < cap(errs); i++ {
+		assert.NoError(t, <-errs)
+	}
+	assert.Len(t, handler.audits, 0)
+}
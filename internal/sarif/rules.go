@@ -1,35 +1,77 @@
 package sarif
 
 import (
+	"github.com/Shopify/kubeaudit/auditors/annotations"
 	"github.com/Shopify/kubeaudit/auditors/apparmor"
 	"github.com/Shopify/kubeaudit/auditors/asat"
 	"github.com/Shopify/kubeaudit/auditors/capabilities"
 	"github.com/Shopify/kubeaudit/auditors/deprecatedapis"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	"github.com/Shopify/kubeaudit/auditors/etcd"
+	"github.com/Shopify/kubeaudit/auditors/exposure"
+	"github.com/Shopify/kubeaudit/auditors/hostnet"
 	"github.com/Shopify/kubeaudit/auditors/hostns"
+	"github.com/Shopify/kubeaudit/auditors/hostprocess"
 	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	"github.com/Shopify/kubeaudit/auditors/labels"
+	"github.com/Shopify/kubeaudit/auditors/lifecycle"
 	"github.com/Shopify/kubeaudit/auditors/limits"
 	"github.com/Shopify/kubeaudit/auditors/mounts"
 	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
 	"github.com/Shopify/kubeaudit/auditors/nonroot"
+	"github.com/Shopify/kubeaudit/auditors/ports"
 	"github.com/Shopify/kubeaudit/auditors/privesc"
 	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/Shopify/kubeaudit/auditors/rego"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
 	"github.com/Shopify/kubeaudit/auditors/rootfs"
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
 	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	"github.com/Shopify/kubeaudit/auditors/volumes"
+	"github.com/Shopify/kubeaudit/auditors/vulns"
 )
 
 var allAuditors = map[string]string{
+	annotations.Name:    "Finds workloads and namespaces which are missing required annotations or have forbidden annotations",
 	apparmor.Name:       "Finds containers that do not have AppArmor enabled",
-	asat.Name:           "Finds containers where the deprecated SA field is used or with a mounted default SA",
+	asat.Name:           "Finds containers where the deprecated SA field is used or with a mounted default SA, and namespaces whose default SA automounts its token",
 	capabilities.Name:   "Finds containers that do not drop the recommended capabilities or add new ones",
 	deprecatedapis.Name: "Finds any resource defined with a deprecated API version",
+	egress.Name:         "Finds namespaces and workloads without a network policy restricting egress traffic",
+	ephemeral.Name:      "Finds ephemeral debug containers running in pods in production namespaces",
+	etcd.Name:           "Finds clusters where secrets are not encrypted at rest or etcd is exposed to unauthenticated clients",
+	exposure.Name:       "Finds Services exposed outside of the cluster to any client, and Ingresses serving hosts without TLS",
+	hostnet.Name:        "Finds containers that bind host ports, and pods that set hostAliases or the host network DNS policy without HostNetwork",
 	hostns.Name:         "Finds containers that have HostPID, HostIPC or HostNetwork enabled",
+	hostprocess.Name:    "Finds Windows pods and containers running as HostProcess containers",
 	image.Name:          "Finds containers which do not use the desired version of an image (via the tag) or use an image without a tag",
+	imagepolicy.Name:    "Finds containers pulling images from unapproved registries or not pinned to a digest",
+	labels.Name:         "Finds workloads and namespaces which are missing required labels or have invalid label values",
+	lifecycle.Name:      "Finds workloads with lifecycle anti-patterns which cause abrupt kills or pods which are never cleaned up",
 	limits.Name:         "Finds containers which exceed the specified CPU and memory limits or do not specify any",
 	mounts.Name:         "Finds containers that have sensitive host paths mounted",
 	netpols.Name:        "Finds namespaces that do not have a default-deny network policy",
+	nodecoverage.Name:   "Finds nodes which are not covered by the configured security-critical DaemonSets",
 	nonroot.Name:        "Finds containers allowed to run as root",
+	ports.Name:          "Finds containers exposing privileged or forbidden ports, and Services targeting undeclared container ports",
 	privesc.Name:        "Finds containers that allow privilege escalation",
 	privileged.Name:     "Finds containers running as privileged",
+	pss.Name:            "Finds workloads which fail Pod Security Standards controls, and namespaces which don't enforce the required level",
+	rbac.Name:           "Finds roles which allow privilege escalation through RBAC and workloads whose service account has dangerous RBAC grants",
+	rego.Name:           "Finds resources which violate the deny and violation rules of user-supplied Rego policies",
+	requests.Name:       "Finds containers which don't request CPU and memory, or whose requests are inconsistent with their limits",
+	resilience.Name:     "Finds replicated workloads which are not spread across nodes and zones or lack a PodDisruptionBudget, single-replica workloads in production, and containers without probes",
 	rootfs.Name:         "Finds containers which do not have a read-only filesystem",
+	runtimeclass.Name:   "Finds pods using a runtime class which is not allowed, or not one of the runtime classes required in their namespace",
 	seccomp.Name:        "Finds containers running without seccomp",
+	secrets.Name:        "Finds secrets injected into environment variables or set as literal values of environment variables and annotations",
+	volumes.Name:        "Finds pods mounting legacy service account token secrets, long-lived projected tokens, inline CSI volumes of drivers which are not allowed and world-readable secret volumes",
+	vulns.Name:          "Finds containers whose images have known vulnerabilities, as reported by Trivy or Grype",
 }
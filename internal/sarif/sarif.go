@@ -1,17 +1,43 @@
 package sarif
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"strings"
 
 	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/internal/baseline"
+	"github.com/Shopify/kubeaudit/internal/compliance"
+	"github.com/Shopify/kubeaudit/internal/redact"
 	"github.com/owenrumney/go-sarif/v2/sarif"
 )
 
 const repoURL = "https://github.com/Shopify/kubeaudit"
 
+// fingerprintName is the name of the partial fingerprint of results, which code scanning tools use to match results
+// across runs instead of opening new alerts when a resource moves within a manifest
+const fingerprintName = "kubeaudit/v1"
+
+// AddVersionControlProvenance records the repository and commit the audited manifests were checked out from in the
+// runs of the report, so code scanning tools can link the results to the revision they were found in. The ref is
+// recorded as the branch if it is set
+func AddVersionControlProvenance(report *sarif.Report, repositoryURI, ref, commit string) {
+	for _, run := range report.Runs {
+		run.AddVersionControlProvenance(NewVersionControlProvenance(repositoryURI, ref, commit))
+	}
+}
+
+// NewVersionControlProvenance returns the details of the repository and commit the audited manifests were checked
+// out from, to record in the run of a report written with Write. The ref is recorded as the branch if it is set
+func NewVersionControlProvenance(repositoryURI, ref, commit string) *sarif.VersionControlDetails {
+	details := sarif.NewVersionControlDetails().WithRepositoryURI(repositoryURI).WithRevisionID(commit)
+	if ref != "" {
+		details = details.WithBranch(ref)
+	}
+	return details
+}
+
 // Create generates new sarif Report or returns an error
 func Create(kubeauditReport *kubeaudit.Report) (*sarif.Report, error) {
 	// create a new report object
@@ -21,84 +47,170 @@ func Create(kubeauditReport *kubeaudit.Report) (*sarif.Report, error) {
 	}
 
 	// create a run for kubeaudit
+	run := newRun(kubeauditReport)
+	report.AddRun(run)
+
+	for _, finding := range kubeauditReport.Findings() {
+		// we only add rules to the report based on the result findings
+		setRule(run.AddRule(finding.AuditResult.Rule), finding)
+		run.AddResult(newResult(finding))
+	}
+
+	return report, nil
+}
+
+// newRun returns a run for kubeaudit without results
+func newRun(kubeauditReport *kubeaudit.Report) *sarif.Run {
 	run := sarif.NewRunWithInformationURI("kubeaudit", repoURL)
 
-	report.AddRun(run)
+	// The number of findings suppressed by each mechanism, so exceptions can be tracked over time
+	if suppressions := kubeauditReport.Suppressions(); len(suppressions) > 0 {
+		counts := make(map[string]int, len(suppressions))
+		for mechanism, count := range suppressions {
+			counts[string(mechanism)] = count
+		}
+		run.AttachPropertyBag(&sarif.PropertyBag{Properties: sarif.Properties{"suppressions": counts}})
+	}
 
-	var results []*kubeaudit.AuditResult
+	return run
+}
+
+// setRule sets the description of the rule of a finding. The help of the rule includes the metadata of the finding
+func setRule(rule *sarif.ReportingDescriptor, finding kubeaudit.Finding) {
+	result := finding.AuditResult
 
-	for _, reportResult := range kubeauditReport.Results() {
-		r := reportResult.GetAuditResults()
-		results = append(results, r...)
+	var metadataTxt string
+	if len(result.Metadata) > 0 {
+		metadata, jsonErr := json.Marshal(redact.Map(result.Metadata))
+		if jsonErr != nil {
+			metadata = []byte(jsonErr.Error())
+		}
+
+		metadataTxt = fmt.Sprintf("Metadata: %s\n", string(metadata))
 	}
 
-	for _, result := range results {
-		severityLevel := result.Severity.String()
+	docsURL := getDocsURL(result)
+	description := getDescription(result.Auditor)
 
-		auditor := strings.ToLower(result.Auditor)
+	helpText := fmt.Sprintf("Type: kubernetes\nAuditor Docs: To find out more about the issue and how to fix it, follow [this link](%s)\nDescription: %s\n%s\n\n Note: These audit results are generated with `kubeaudit`, a command line tool and a Go package that checks for potential security concerns in kubernetes manifest specs. You can read more about it at https://github.com/Shopify/kubeaudit ", docsURL, description, metadataTxt)
 
-		var metadataTxt string
-		if len(result.Metadata) > 0 {
-			formattedMap := make(map[string]string)
+	helpMarkdown := fmt.Sprintf("**Type**: kubernetes\n**Auditor Docs**: To find out more about the issue and how to fix it, follow [this link](%s)\n**Description:** %s\n **Metadata**: %s\n\n *Note*: These audit results are generated with `kubeaudit`, a command line tool and a Go package that checks for potential security concerns in kubernetes manifest specs. You can read more about it at https://github.com/Shopify/kubeaudit ",
+		docsURL, description, metadataTxt)
 
-			for k, v := range result.Metadata {
-				formattedMap[k] = v
-			}
+	shortDescription := result.Rule
+	tags := []string{
+		"security",
+		"kubernetes",
+		"infrastructure",
+	}
 
-			metadata, jsonErr := json.Marshal(formattedMap)
-			if jsonErr != nil {
-				metadata = []byte(jsonErr.Error())
-			}
+	// Pod Security Standards findings are mapped to the official control names
+	if control, ok := pss.GetControl(result.Rule); ok {
+		shortDescription = fmt.Sprintf("Pod Security Standards (%s): %s", control.Level, control.Name)
+		tags = append(tags, "pod-security-standards", "pss-"+control.Level)
+	}
+
+	// The CIS Kubernetes Benchmark and NSA/CISA Kubernetes Hardening Guide controls the rule fails, eg. cis-5.2.2
+	tags = append(tags, compliance.Tags(result.Rule)...)
 
-			metadataTxt = fmt.Sprintf("Metadata: %s\n", string(metadata))
+	// CWE references are tagged the way GitHub code scanning expects, eg. external/cwe/cwe-250
+	for _, reference := range result.References {
+		if reference.Type == kubeaudit.ReferenceCWE {
+			tags = append(tags, "external/cwe/"+strings.ToLower(reference.ID))
 		}
+	}
 
-		docsURL := "https://github.com/Shopify/kubeaudit/blob/main/docs/auditors/" + auditor + ".md"
+	properties := sarif.Properties{
+		"tags": tags,
+	}
+	if len(result.References) > 0 {
+		properties["references"] = result.References
+	}
 
-		helpText := fmt.Sprintf("Type: kubernetes\nAuditor Docs: To find out more about the issue and how to fix it, follow [this link](%s)\nDescription: %s\n%s\n\n Note: These audit results are generated with `kubeaudit`, a command line tool and a Go package that checks for potential security concerns in kubernetes manifest specs. You can read more about it at https://github.com/Shopify/kubeaudit ", docsURL, allAuditors[auditor], metadataTxt)
+	rule.WithName(result.Auditor).
+		WithHelpURI(docsURL).
+		WithHelp(&sarif.MultiformatMessageString{Text: &helpText, Markdown: &helpMarkdown}).
+		WithShortDescription(&sarif.MultiformatMessageString{Text: &shortDescription}).
+		WithProperties(properties)
+}
 
-		helpMarkdown := fmt.Sprintf("**Type**: kubernetes\n**Auditor Docs**: To find out more about the issue and how to fix it, follow [this link](%s)\n**Description:** %s\n **Metadata**: %s\n\n *Note*: These audit results are generated with `kubeaudit`, a command line tool and a Go package that checks for potential security concerns in kubernetes manifest specs. You can read more about it at https://github.com/Shopify/kubeaudit ",
-			docsURL, allAuditors[auditor], metadataTxt)
+// newResult returns the result of a finding
+func newResult(finding kubeaudit.Finding) *sarif.Result {
+	result := finding.AuditResult
+
+	// SARIF specifies the following severity levels: warning, error, note and none
+	// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+	// so we're converting info to note here so we get valid SARIF output. Custom severities are reported as the
+	// built-in severity below them
+	severityLevel := result.Severity.Builtin().String()
+	if result.Severity.Builtin() == kubeaudit.Info {
+		severityLevel = "note"
+	}
 
-		// we only add rules to the report based on the result findings
-		run.AddRule(result.Rule).
-			WithName(result.Auditor).
-			WithHelp(&sarif.MultiformatMessageString{Text: &helpText, Markdown: &helpMarkdown}).
-			WithShortDescription(&sarif.MultiformatMessageString{Text: &result.Rule}).
-			WithProperties(sarif.Properties{
-				"tags": []string{
-					"security",
-					"kubernetes",
-					"infrastructure",
-				},
-			})
-
-		// SARIF specifies the following severity levels: warning, error, note and none
-		// https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
-		// so we're converting info to note here so we get valid SARIF output
-		if result.Severity.String() == kubeaudit.Info.String() {
-			severityLevel = "note"
-		}
+	details := fmt.Sprintf("Details: %s\n Auditor: %s\nDescription: %s\nAuditor docs: %s ",
+		redact.String(result.Message), result.Auditor, getDescription(result.Auditor), getDocsURL(result))
+
+	startLine := 1
+	if result.Line > 0 {
+		startLine = result.Line
+	}
 
-		details := fmt.Sprintf("Details: %s\n Auditor: %s\nDescription: %s\nAuditor docs: %s ",
-			result.Message, result.Auditor, allAuditors[auditor], docsURL)
+	region := sarif.NewRegion().WithStartLine(startLine)
+	if result.Column > 0 {
+		region.WithStartColumn(result.Column)
+	}
 
-		location := sarif.NewPhysicalLocation().
-			WithArtifactLocation(sarif.NewSimpleArtifactLocation(result.FilePath).WithUriBaseId("ROOTPATH")).
-			WithRegion(sarif.NewRegion().WithStartLine(1))
-		result := sarif.NewRuleResult(result.Rule).
-			WithMessage(sarif.NewTextMessage(details)).
-			WithLevel(severityLevel).
-			WithLocations([]*sarif.Location{sarif.NewLocation().WithPhysicalLocation(location)})
-		run.AddResult(result)
+	location := sarif.NewPhysicalLocation().
+		WithArtifactLocation(sarif.NewSimpleArtifactLocation(result.FilePath).WithUriBaseId("ROOTPATH")).
+		WithRegion(region)
+	sarifResult := sarif.NewRuleResult(result.Rule).
+		WithMessage(sarif.NewTextMessage(details)).
+		WithLevel(severityLevel).
+		WithLocations([]*sarif.Location{sarif.NewLocation().WithPhysicalLocation(location)}).
+		WithPartialFingerPrints(map[string]interface{}{
+			fingerprintName: baseline.Fingerprint(finding.Resource, result),
+		})
+
+	// Overridden results are suppressed in the manifest by their override label, with its value as the justification
+	if result.SuppressedBy == kubeaudit.SuppressedByOverride {
+		suppression := sarif.NewSuppression("inSource")
+		if reason := result.Metadata["OverrideReason"]; reason != "" {
+			suppression.WithJustifcation(redact.String(reason))
+		}
+		sarifResult.AddSuppression(suppression)
 	}
 
-	var reportBytes bytes.Buffer
+	properties := sarif.Properties{}
+	// The name of custom severities is kept, since the level only has the built-in severity
+	if !result.Severity.IsBuiltin() {
+		properties["severity"] = result.Severity.String()
+	}
+	// Results of several clusters are told apart by the kubeconfig context of their cluster
+	if finding.Context != "" {
+		properties["context"] = finding.Context
+	}
+	if len(properties) > 0 {
+		sarifResult.AttachPropertyBag(&sarif.PropertyBag{Properties: properties})
+	}
+	return sarifResult
+}
 
-	err = report.Write(&reportBytes)
-	if err != nil {
-		return nil, nil
+// getDocsURL returns the documentation of the rule of the audit result, which is the documentation of its auditor
+// unless the result has a documentation reference, such as the results of plugins
+func getDocsURL(result *kubeaudit.AuditResult) string {
+	for _, reference := range result.References {
+		if reference.Type == kubeaudit.ReferenceDocs && reference.URL != "" {
+			return reference.URL
+		}
 	}
+	return "https://github.com/Shopify/kubeaudit/blob/main/docs/auditors/" + strings.ToLower(result.Auditor) + ".md"
+}
 
-	return report, nil
+// getDescription returns the description of an auditor. Auditors kubeaudit doesn't know, such as plugins, have a
+// generic description
+func getDescription(auditor string) string {
+	if description, ok := allAuditors[strings.ToLower(auditor)]; ok {
+		return description
+	}
+	return fmt.Sprintf("Finds the issues checked by the %s auditor", auditor)
 }
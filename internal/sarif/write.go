@@ -0,0 +1,137 @@
+package sarif
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// batchSize is the number of results generated before they are written, which bounds the memory used by Write
+const batchSize = 1024
+
+// resultsIndent is the indentation of the results of the run in the report, for the output to match PrettyWrite
+const resultsIndent = "        "
+
+// WriteOptions are the options of Write
+type WriteOptions struct {
+	// Concurrency is the number of results generated in parallel. Defaults to the number of CPUs
+	Concurrency int
+	// VersionControlProvenance is recorded in the run if it is set. See NewVersionControlProvenance
+	VersionControlProvenance *sarif.VersionControlDetails
+}
+
+// Write writes the report as indented SARIF, the same as the PrettyWrite of the report returned by Create. The results
+// are generated in batches in parallel and streamed to the writer, so the whole document is never held in memory
+func Write(w io.Writer, kubeauditReport *kubeaudit.Report, options WriteOptions) error {
+	findings := kubeauditReport.Findings()
+
+	// The rules are written before the results, so they are added first. Like Create, the rule of the last finding of
+	// each rule is the one reported
+	run := newRun(kubeauditReport)
+	ruleIndexes := map[string]int{}
+	lastFindings := map[string]kubeaudit.Finding{}
+	for _, finding := range findings {
+		if _, ok := ruleIndexes[finding.AuditResult.Rule]; !ok {
+			ruleIndexes[finding.AuditResult.Rule] = len(ruleIndexes)
+			run.AddRule(finding.AuditResult.Rule)
+		}
+		lastFindings[finding.AuditResult.Rule] = finding
+	}
+	for _, rule := range run.Tool.Driver.Rules {
+		setRule(rule, lastFindings[rule.ID])
+	}
+	if options.VersionControlProvenance != nil {
+		run.AddVersionControlProvenance(options.VersionControlProvenance)
+	}
+
+	// Everything but the results is marshalled as a whole, and split where the results go
+	report, err := sarif.New(sarif.Version210)
+	if err != nil {
+		return err
+	}
+	report.AddRun(run)
+	run.Results = []*sarif.Result{}
+	document, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	marker := []byte(`"results": [`)
+	split := bytes.Index(document, []byte(`"results": []`))
+	if split < 0 {
+		return errors.New("results not found in the SARIF run")
+	}
+	head, tail := document[:split+len(marker)], document[split+len(marker):]
+
+	out := bufio.NewWriter(w)
+	if _, err := out.Write(head); err != nil {
+		return err
+	}
+	if len(findings) > 0 {
+		if err := writeResults(out, findings, ruleIndexes, options.Concurrency); err != nil {
+			return err
+		}
+		if _, err := out.WriteString("\n      "); err != nil {
+			return err
+		}
+	}
+	if _, err := out.Write(tail); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// writeResults generates the results of the findings in batches, each in parallel, and writes them in order
+func writeResults(out *bufio.Writer, findings []kubeaudit.Finding, ruleIndexes map[string]int, concurrency int) error {
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	results := make([][]byte, batchSize)
+	errs := make([]error, batchSize)
+	for start := 0; start < len(findings); start += batchSize {
+		batch := findings[start:]
+		if len(batch) > batchSize {
+			batch = batch[:batchSize]
+		}
+
+		indexes := make(chan int, len(batch))
+		for i := range batch {
+			indexes <- i
+		}
+		close(indexes)
+
+		var wg sync.WaitGroup
+		for w := 0; w < concurrency && w < len(batch); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := range indexes {
+					result := newResult(batch[i]).WithRuleIndex(ruleIndexes[batch[i].AuditResult.Rule])
+					results[i], errs[i] = json.MarshalIndent(result, resultsIndent, "  ")
+				}
+			}()
+		}
+		wg.Wait()
+
+		for i := range batch {
+			if errs[i] != nil {
+				return errs[i]
+			}
+			if start+i > 0 {
+				out.WriteByte(',')
+			}
+			out.WriteString("\n" + resultsIndent)
+			if _, err := out.Write(results[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+// Package signature signs reports with detached JSON Web Signatures (RFC 7515, appendix F) so that archived reports
+// are tamper-evident
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256" // registers SHA-256 for crypto.Hash
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for crypto.Hash
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Type is the type of the signed content set in the header of the signature
+const Type = "kubeaudit-report"
+
+// ErrInvalidSignature is returned when a signature does not match the report or the key
+var ErrInvalidSignature = errors.New("invalid signature")
+
+// Header is the protected header of a signature
+type Header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+}
+
+// ParsePrivateKey parses a PEM encoded ECDSA, Ed25519 or RSA private key, in PKCS #8, SEC 1 or PKCS #1 form
+func ParsePrivateKey(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var key interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q, expected a private key", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+	if _, err := algorithm(signer.Public()); err != nil {
+		return nil, err
+	}
+	return signer, nil
+}
+
+// ParsePublicKey parses a PEM encoded ECDSA, Ed25519 or RSA public key, in PKIX or PKCS #1 form, or the public key
+// of a PEM encoded certificate
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var key crypto.PublicKey
+	var err error
+	switch block.Type {
+	case "PUBLIC KEY":
+		key, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		key, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			key = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q, expected a public key or certificate", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := algorithm(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Sign returns a detached JSON Web Signature of the report, in compact serialization with the payload left out
+// ("<header>..<signature>"). ECDSA keys sign with ES256, ES384 or ES512 depending on their curve, Ed25519 keys with
+// EdDSA and RSA keys with RS256
+func Sign(report []byte, key crypto.Signer) ([]byte, error) {
+	alg, err := algorithm(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := json.Marshal(Header{Algorithm: alg, Type: Type})
+	if err != nil {
+		return nil, err
+	}
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+
+	signature, err := sign(key, alg, signingInput(encodedHeader, report))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign the report: %w", err)
+	}
+
+	return []byte(encodedHeader + ".." + base64.RawURLEncoding.EncodeToString(signature)), nil
+}
+
+// Verify checks that a detached JSON Web Signature created by Sign matches the report and the public key. The
+// algorithm of the signature must be the one used by Sign for the key, so a signature can't be verified with a
+// different algorithm than the key is meant for. ErrInvalidSignature is returned if the report or the signature
+// were changed
+func Verify(report []byte, signature []byte, key crypto.PublicKey) error {
+	parts := strings.Split(strings.TrimSpace(string(signature)), ".")
+	if len(parts) != 3 {
+		return errors.New("malformed signature: expected a compact JSON Web Signature")
+	}
+	if parts[1] != "" {
+		return errors.New("malformed signature: expected a detached JSON Web Signature without a payload")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("malformed signature header: %w", err)
+	}
+	var header Header
+	if err := json.Unmarshal(headerBytes, &header); err != nil {
+		return fmt.Errorf("malformed signature header: %w", err)
+	}
+
+	alg, err := algorithm(key)
+	if err != nil {
+		return err
+	}
+	if header.Algorithm != alg {
+		return fmt.Errorf("signature algorithm %q does not match the %s key", header.Algorithm, alg)
+	}
+
+	signatureBytes, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("malformed signature: %w", err)
+	}
+
+	if !verify(key, alg, signingInput(parts[0], report), signatureBytes) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signingInput(encodedHeader string, report []byte) []byte {
+	return []byte(encodedHeader + "." + base64.RawURLEncoding.EncodeToString(report))
+}
+
+// algorithm returns the JSON Web Algorithm used to sign with the key
+func algorithm(key crypto.PublicKey) (string, error) {
+	switch key := key.(type) {
+	case *ecdsa.PublicKey:
+		switch key.Curve.Params().BitSize {
+		case 256:
+			return "ES256", nil
+		case 384:
+			return "ES384", nil
+		case 521:
+			return "ES512", nil
+		}
+		return "", fmt.Errorf("unsupported ECDSA curve %s", key.Curve.Params().Name)
+	case ed25519.PublicKey:
+		return "EdDSA", nil
+	case *rsa.PublicKey:
+		return "RS256", nil
+	}
+	return "", fmt.Errorf("unsupported key type %T", key)
+}
+
+// hash returns the hash function of the algorithm and the digest of the input
+func hash(alg string, input []byte) (crypto.Hash, []byte) {
+	h := crypto.SHA256
+	switch alg {
+	case "ES384":
+		h = crypto.SHA384
+	case "ES512":
+		h = crypto.SHA512
+	}
+
+	hasher := h.New()
+	hasher.Write(input)
+	return h, hasher.Sum(nil)
+}
+
+func sign(key crypto.Signer, alg string, input []byte) ([]byte, error) {
+	if alg == "EdDSA" {
+		return key.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+
+	h, digest := hash(alg, input)
+	signature, err := key.Sign(rand.Reader, digest, h)
+	if err != nil || !strings.HasPrefix(alg, "ES") {
+		return signature, err
+	}
+
+	// ECDSA signatures are ASN.1 encoded by crypto.Signer, while JSON Web Signatures use the concatenated R and S
+	// values padded to the size of the curve
+	var ecSignature struct{ R, S *big.Int }
+	if _, err := asn1.Unmarshal(signature, &ecSignature); err != nil {
+		return nil, err
+	}
+	size := (key.Public().(*ecdsa.PublicKey).Curve.Params().BitSize + 7) / 8
+	raw := make([]byte, 2*size)
+	ecSignature.R.FillBytes(raw[:size])
+	ecSignature.S.FillBytes(raw[size:])
+	return raw, nil
+}
+
+func verify(key crypto.PublicKey, alg string, input, signature []byte) bool {
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, input, signature)
+	case *rsa.PublicKey:
+		h, digest := hash(alg, input)
+		return rsa.VerifyPKCS1v15(key, h, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		_, digest := hash(alg, input)
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package signature
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var report = []byte(`{"level":"error","msg":"AppArmor annotation missing."}` + "\n")
+
+func TestSignVerify(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ec384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	cases := []struct {
+		testName  string
+		key       crypto.Signer
+		algorithm string
+	}{
+		{"ECDSA P-256", ecKey, "ES256"},
+		{"ECDSA P-384", ec384Key, "ES384"},
+		{"Ed25519", edKey, "EdDSA"},
+		{"RSA", rsaKey, "RS256"},
+	}
+
+	for _, tc := range cases {
+		// These lines are needed because of how scopes work with parallel tests (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			signature, err := Sign(report, tc.key)
+			require.NoError(t, err)
+
+			parts := strings.Split(string(signature), ".")
+			require.Len(t, parts, 3)
+			assert.Empty(t, parts[1], "the payload is detached")
+
+			require.NoError(t, Verify(report, signature, tc.key.Public()))
+
+			// A trailing newline added to the signature file is ignored
+			require.NoError(t, Verify(report, append(signature, '\n'), tc.key.Public()))
+
+			tampered := []byte(strings.Replace(string(report), "error", "info", 1))
+			assert.ErrorIs(t, Verify(tampered, signature, tc.key.Public()), ErrInvalidSignature)
+
+			otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			require.NoError(t, err)
+			assert.Error(t, Verify(report, signature, otherKey.Public()))
+		})
+	}
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signature, err := Sign(report, key)
+	require.NoError(t, err)
+	parts := strings.Split(string(signature), ".")
+
+	for _, signature := range []string{
+		"",
+		parts[0] + "." + parts[2],
+		parts[0] + ".cGF5bG9hZA." + parts[2],
+		"!!.." + parts[2],
+		parts[0] + "..!!",
+	} {
+		assert.Error(t, Verify(report, []byte(signature), key.Public()), signature)
+	}
+}
+
+func TestParseKeys(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	require.NoError(t, err)
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+	pkix, err := x509.MarshalPKIXPublicKey(ecKey.Public())
+	require.NoError(t, err)
+
+	for _, block := range []*pem.Block{
+		{Type: "PRIVATE KEY", Bytes: pkcs8},
+		{Type: "EC PRIVATE KEY", Bytes: sec1},
+		{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+	} {
+		_, err := ParsePrivateKey(pem.EncodeToMemory(block))
+		assert.NoError(t, err, block.Type)
+	}
+
+	for _, block := range []*pem.Block{
+		{Type: "PUBLIC KEY", Bytes: pkix},
+		{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)},
+	} {
+		_, err := ParsePublicKey(pem.EncodeToMemory(block))
+		assert.NoError(t, err, block.Type)
+	}
+
+	_, err = ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pkix}))
+	assert.Error(t, err)
+	_, err = ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}))
+	assert.Error(t, err)
+	_, err = ParsePrivateKey([]byte("not a key"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,250 @@
+// Package summary counts the findings of a report per auditor, severity and namespace, for a compact overview of an
+// audit such as in nightly emails
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/internal/color"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// ClusterScoped is the namespace the findings of cluster-scoped resources, and of manifest resources without a
+// namespace, are counted under
+const ClusterScoped = "(none)"
+
+// Verdicts of workloads
+const (
+	VerdictPass = "pass"
+	VerdictFail = "fail"
+)
+
+// Report is the number of findings of an audit per auditor, severity and namespace, and the verdict of each workload
+type Report struct {
+	// Resources is the number of audited resources, including the ones without findings
+	Resources int           `json:"resources"`
+	Duration  time.Duration `json:"-"`
+	Findings  int           `json:"findings"`
+	// Auditors and Namespaces are ordered by decreasing number of findings, and Severities from the highest severity
+	// to the lowest
+	Auditors   []Count `json:"auditors"`
+	Severities []Count `json:"severities"`
+	Namespaces []Count `json:"namespaces"`
+	// Contexts are the counts per kubeconfig context when several clusters are audited, ordered by decreasing number
+	// of findings. They are empty when a single cluster or manifests are audited
+	Contexts []Count `json:"contexts,omitempty"`
+	// Workloads are the verdicts of the audited resources which run pods, in the order they were audited. Verdicts is
+	// the number of workloads which pass and fail, and Levels the number of workloads which achieve each Pod Security
+	// Standards level, from the most restrictive level to the least. Both are empty if no workloads were audited
+	Workloads []Workload `json:"workloads,omitempty"`
+	Verdicts  []Count    `json:"verdicts,omitempty"`
+	Levels    []Count    `json:"levels,omitempty"`
+}
+
+// Count is the number of findings of an auditor, severity, namespace or context, or the number of workloads with a
+// verdict or Pod Security Standards level
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Workload is the verdict of a workload. A workload fails if it has findings with the fail-on severity or higher,
+// including the findings of its containers, and passes otherwise. Level is the most restrictive Pod Security Standards
+// level it satisfies, whether or not the pss auditor is enabled
+type Workload struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Context   string `json:"context,omitempty"`
+	Verdict   string `json:"verdict"`
+	Level     string `json:"level"`
+	// Findings is the number of findings of the workload with the minimum severity
+	Findings int `json:"findings"`
+}
+
+// Create counts the findings of the report with the minimum severity, and gives a verdict to each workload with the
+// fail-on severity. The duration is the time the audit took
+func Create(report *kubeaudit.Report, minSeverity, failOn kubeaudit.SeverityLevel, duration time.Duration) *Report {
+	summary := &Report{Resources: len(report.RawResults()), Duration: duration}
+
+	auditors := map[string]int{}
+	severities := map[kubeaudit.SeverityLevel]int{}
+	namespaces := map[string]int{}
+	contexts := map[string]int{}
+	for _, finding := range report.FindingsWithMinSeverity(minSeverity) {
+		summary.Findings++
+		auditors[finding.Auditor]++
+		severities[finding.Severity]++
+		namespaces[getNamespace(finding)]++
+		if finding.Context != "" {
+			contexts[finding.Context]++
+		}
+	}
+
+	summary.Auditors = sortCounts(auditors)
+	summary.Namespaces = sortCounts(namespaces)
+	summary.Contexts = sortCounts(contexts)
+	for _, severity := range kubeaudit.Severities() {
+		if severity.AtLeast(minSeverity) {
+			summary.Severities = append(summary.Severities, Count{Name: severity.String(), Count: severities[severity]})
+		}
+	}
+
+	summary.Workloads = getWorkloads(report, minSeverity, failOn)
+	if len(summary.Workloads) > 0 {
+		verdicts := map[string]int{}
+		levels := map[string]int{}
+		for _, workload := range summary.Workloads {
+			verdicts[workload.Verdict]++
+			levels[workload.Level]++
+		}
+		for _, verdict := range []string{VerdictPass, VerdictFail} {
+			summary.Verdicts = append(summary.Verdicts, Count{Name: verdict, Count: verdicts[verdict]})
+		}
+		for _, level := range []string{pss.LevelRestricted, pss.LevelBaseline, pss.LevelPrivileged} {
+			summary.Levels = append(summary.Levels, Count{Name: level, Count: levels[level]})
+		}
+	}
+
+	return summary
+}
+
+// getWorkloads returns the verdicts of the resources of the report which run pods
+func getWorkloads(report *kubeaudit.Report, minSeverity, failOn kubeaudit.SeverityLevel) []Workload {
+	var workloads []Workload
+	for _, result := range report.RawResults() {
+		resource := result.GetResource()
+		if resource == nil || resource.Object() == nil || k8s.GetPodSpec(resource.Object()) == nil {
+			continue
+		}
+
+		object := resource.Object()
+		workload := Workload{
+			Kind:    object.GetObjectKind().GroupVersionKind().Kind,
+			Verdict: VerdictPass,
+			Level:   pss.SatisfiedLevel(object),
+		}
+		if objectMeta := k8s.GetObjectMeta(object); objectMeta != nil {
+			workload.Namespace = objectMeta.GetNamespace()
+			workload.Name = objectMeta.GetName()
+		}
+		for _, auditResult := range result.GetAuditResults() {
+			workload.Context = auditResult.Metadata[kubeaudit.ContextMetadata]
+			if auditResult.Severity.AtLeast(minSeverity) {
+				workload.Findings++
+			}
+			if auditResult.Severity.AtLeast(failOn) {
+				workload.Verdict = VerdictFail
+			}
+		}
+		workloads = append(workloads, workload)
+	}
+	return workloads
+}
+
+// Write writes the totals of the audit followed by a table of the counts per severity, context, auditor and namespace,
+// and of the number of workloads per verdict and Pod Security Standards level
+func (r *Report) Write(w io.Writer, useColor bool) error {
+	var out strings.Builder
+
+	fmt.Fprintf(&out, "%d findings in %d resources, audited in %s\n", r.Findings, r.Resources, r.Duration.Round(time.Millisecond))
+
+	// The names are padded before they are colored, so the escape codes don't misalign the counts
+	width := len("NAMESPACE")
+	for _, counts := range [][]Count{r.Severities, r.Contexts, r.Auditors, r.Namespaces, r.Verdicts, r.Levels} {
+		for _, count := range counts {
+			if len(count.Name) > width {
+				width = len(count.Name)
+			}
+		}
+	}
+
+	colorSeverity := func(name string) string {
+		if !useColor {
+			return name
+		}
+		// Custom severities have the color of the built-in severity below them
+		severity, _ := kubeaudit.ParseSeverity(strings.TrimSpace(name))
+		switch severity.Builtin() {
+		case kubeaudit.Error:
+			return color.Red(name)
+		case kubeaudit.Warn:
+			return color.Yellow(name)
+		}
+		return color.Cyan(name)
+	}
+	writeCounts(&out, "SEVERITY", "FINDINGS", r.Severities, width, 0, colorSeverity)
+	writeCounts(&out, "CONTEXT", "FINDINGS", r.Contexts, width, 0, nil)
+	writeCounts(&out, "AUDITOR", "FINDINGS", r.Auditors, width, 0, nil)
+	writeCounts(&out, "NAMESPACE", "FINDINGS", r.Namespaces, width, 0, nil)
+	writeCounts(&out, "VERDICT", "WORKLOADS", r.Verdicts, width, len(r.Workloads), nil)
+	writeCounts(&out, "PSS LEVEL", "WORKLOADS", r.Levels, width, len(r.Workloads), nil)
+
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// WriteJSON writes the report as a JSON object, with the duration of the audit in seconds
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		*Report
+		DurationSeconds float64 `json:"durationSeconds"`
+	}{r, r.Duration.Seconds()})
+}
+
+// writeCounts writes a table of the counts. If total is not 0, the counts are followed by their rounded percentage of
+// it
+func writeCounts(out io.Writer, header, unit string, counts []Count, width, total int, format func(string) string) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "\n%-*s  %s\n", width, header, unit)
+	for _, count := range counts {
+		name := fmt.Sprintf("%-*s", width, count.Name)
+		if format != nil {
+			name = format(name)
+		}
+		if total != 0 {
+			fmt.Fprintf(out, "%s  %d (%d%%)\n", name, count.Count, (count.Count*200+total)/(2*total))
+		} else {
+			fmt.Fprintf(out, "%s  %d\n", name, count.Count)
+		}
+	}
+}
+
+// getNamespace returns the namespace of the resource of a finding, which is the namespace itself for namespaces
+func getNamespace(finding kubeaudit.Finding) string {
+	namespace := finding.Namespace
+	if finding.Resource != nil && finding.Resource.Object() != nil {
+		namespace = k8sinternal.ResourceNamespace(finding.Resource.Object())
+	}
+	if namespace == "" {
+		return ClusterScoped
+	}
+	return namespace
+}
+
+// sortCounts returns the counts ordered by decreasing count, and by name for equal counts
+func sortCounts(counts map[string]int) []Count {
+	sorted := make([]Count, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, Count{Name: name, Count: count})
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
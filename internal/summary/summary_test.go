@@ -0,0 +1,171 @@
+package summary
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+)
+
+const manifest = `apiVersion: v1
+kind: Pod
+metadata:
+  name: privileged
+  namespace: payments
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+      securityContext:
+        privileged: true
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: unprivileged
+  namespace: payments
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+      securityContext:
+        privileged: false
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: default
+spec:
+  containers:
+    - name: container
+      image: scratch:2.0
+`
+
+func auditManifest(t *testing.T) *kubeaudit.Report {
+	imageAuditor := image.New(image.Config{Image: "scratch:1.0"})
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New(), imageAuditor})
+	require.NoError(t, err)
+	report, err := auditor.AuditManifest("", strings.NewReader(manifest))
+	require.NoError(t, err)
+	return report
+}
+
+func TestCreate(t *testing.T) {
+	report := Create(auditManifest(t), kubeaudit.Info, kubeaudit.Error, 1500*time.Millisecond)
+
+	assert.Equal(t, 3, report.Resources)
+	assert.Equal(t, 1500*time.Millisecond, report.Duration)
+	assert.Equal(t, 5, report.Findings)
+	assert.Equal(t, []Count{{"error", 2}, {"warning", 1}, {"info", 2}}, report.Severities)
+	assert.Equal(t, []Count{{image.Name, 3}, {privileged.Name, 2}}, report.Auditors)
+	assert.Equal(t, []Count{{"payments", 3}, {ClusterScoped, 2}}, report.Namespaces)
+
+	// Results below the minimum severity are not counted
+	report = Create(auditManifest(t), kubeaudit.Error, kubeaudit.Error, 0)
+	assert.Equal(t, 3, report.Resources)
+	assert.Equal(t, 2, report.Findings)
+	assert.Equal(t, []Count{{"error", 2}}, report.Severities)
+	assert.Equal(t, []Count{{image.Name, 1}, {privileged.Name, 1}}, report.Auditors)
+}
+
+func TestWrite(t *testing.T) {
+	report := Create(auditManifest(t), kubeaudit.Warn, kubeaudit.Error, 1500*time.Millisecond)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, false))
+	assert.Equal(t, `3 findings in 3 resources, audited in 1.5s
+
+SEVERITY    FINDINGS
+error       2
+warning     1
+
+AUDITOR     FINDINGS
+privileged  2
+image       1
+
+NAMESPACE   FINDINGS
+(none)      2
+payments    1
+
+VERDICT     WORKLOADS
+pass        1 (33%)
+fail        2 (67%)
+
+PSS LEVEL   WORKLOADS
+restricted  0 (0%)
+baseline    2 (67%)
+privileged  1 (33%)
+`, out.String())
+}
+
+func TestCreateWorkloads(t *testing.T) {
+	report := Create(auditManifest(t), kubeaudit.Info, kubeaudit.Error, 0)
+	assert.Equal(t, []Workload{
+		{Kind: "Pod", Namespace: "payments", Name: "privileged", Verdict: VerdictFail, Level: pss.LevelPrivileged, Findings: 2},
+		{Kind: "Pod", Namespace: "payments", Name: "unprivileged", Verdict: VerdictPass, Level: pss.LevelBaseline, Findings: 1},
+		{Kind: "Pod", Name: "default", Verdict: VerdictFail, Level: pss.LevelBaseline, Findings: 2},
+	}, report.Workloads)
+	assert.Equal(t, []Count{{VerdictPass, 1}, {VerdictFail, 2}}, report.Verdicts)
+	assert.Equal(t, []Count{{pss.LevelRestricted, 0}, {pss.LevelBaseline, 2}, {pss.LevelPrivileged, 1}}, report.Levels)
+
+	// Workloads fail with findings of the fail-on severity or higher
+	report = Create(auditManifest(t), kubeaudit.Info, kubeaudit.Info, 0)
+	assert.Equal(t, []Count{{VerdictPass, 0}, {VerdictFail, 3}}, report.Verdicts)
+}
+
+func TestWriteJSON(t *testing.T) {
+	report := Create(auditManifest(t), kubeaudit.Error, kubeaudit.Error, 1500*time.Millisecond)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteJSON(&out))
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, 1.5, decoded["durationSeconds"])
+	assert.Equal(t, float64(2), decoded["findings"])
+	assert.Contains(t, out.String(), `"verdicts": [
+    {
+      "name": "pass",
+      "count": 1
+    },`)
+	assert.Contains(t, out.String(), `{
+      "kind": "Pod",
+      "namespace": "payments",
+      "name": "privileged",
+      "verdict": "fail",
+      "level": "privileged",
+      "findings": 1
+    }`)
+}
+
+func TestCreateContexts(t *testing.T) {
+	// Findings of several clusters are counted per context, and manifest findings have no context
+	assert.Empty(t, Create(auditManifest(t), kubeaudit.Info, kubeaudit.Error, 0).Contexts)
+
+	report := auditManifest(t)
+	for i, result := range report.RawResults() {
+		context := "production"
+		if i == 0 {
+			context = "staging"
+		}
+		for _, auditResult := range result.GetAuditResults() {
+			auditResult.Metadata[kubeaudit.ContextMetadata] = context
+		}
+	}
+
+	summary := Create(report, kubeaudit.Warn, kubeaudit.Error, 1500*time.Millisecond)
+	assert.Equal(t, []Count{{"production", 2}, {"staging", 1}}, summary.Contexts)
+
+	var out bytes.Buffer
+	require.NoError(t, summary.Write(&out, false))
+	assert.Contains(t, out.String(), "\nCONTEXT     FINDINGS\nproduction  2\nstaging     1\n")
+}
@@ -0,0 +1,3 @@
+{{- define "chart.labels" -}}
+name: {{ .Chart.Name }}
+{{- end }}
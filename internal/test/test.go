@@ -7,6 +7,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"testing"
 
 	"github.com/Shopify/kubeaudit"
@@ -19,6 +20,13 @@ import (
 // SharedFixturesDir contains fixtures used by multiple tests
 const SharedFixturesDir = "../../internal/test/fixtures"
 
+// InitAndEphemeralContainersFixture is a shared fixture of a pod with an init container, a container and an ephemeral
+// container, none of which set a security context. Security context auditors must audit and fix all of them
+const InitAndEphemeralContainersFixture = "init-and-ephemeral-containers.yml"
+
+// InitAndEphemeralContainers are the names of the containers of InitAndEphemeralContainersFixture, sorted
+var InitAndEphemeralContainers = []string{"container", "ephemeral-container", "init-container"}
+
 const MANIFEST_MODE = "manifest"
 const LOCAL_MODE = "local"
 
@@ -54,6 +62,20 @@ func AuditMultiple(t *testing.T, fixtureDir, fixture string, auditables []kubeau
 	return report
 }
 
+// AuditedContainers returns the sorted names of the containers which have an audit result of the rule in the report
+func AuditedContainers(report *kubeaudit.Report, rule string) []string {
+	var containers []string
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			if auditResult.Rule == rule {
+				containers = append(containers, auditResult.Metadata["Container"])
+			}
+		}
+	}
+	sort.Strings(containers)
+	return containers
+}
+
 func FixSetup(t *testing.T, fixtureDir, fixture string, auditable kubeaudit.Auditable) (fixedResources []k8s.Resource, report *kubeaudit.Report) {
 	return FixSetupMultiple(t, fixtureDir, fixture, []kubeaudit.Auditable{auditable})
 }
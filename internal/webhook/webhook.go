@@ -0,0 +1,149 @@
+// Package webhook implements a validating admission webhook which audits workloads as they are admitted to the
+// cluster
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/internal/redact"
+)
+
+// maxRequestBytes is the maximum size of an admission review. The API server limits objects to 3MiB, and the review
+// can contain both the new and the old object
+const maxRequestBytes = 7 * 1024 * 1024
+
+// Config configures how admission requests are handled
+type Config struct {
+	// RejectSeverity is the lowest severity of the findings which cause a resource to be rejected
+	RejectSeverity kubeaudit.SeverityLevel
+	// AuditMode admits resources with findings and returns the findings as warnings instead of rejecting them
+	AuditMode bool
+	// IncludeGenerated audits generated resources (eg. pods generated by deployments). Generated resources are
+	// admitted without being audited by default since the resources they are generated from are audited
+	IncludeGenerated bool
+}
+
+// Handler is an HTTP handler which serves admission reviews
+type Handler struct {
+	auditor *kubeaudit.Kubeaudit
+	config  Config
+}
+
+// NewHandler returns a handler which audits the resources in admission reviews with the auditor
+func NewHandler(auditor *kubeaudit.Kubeaudit, config Config) *Handler {
+	return &Handler{auditor: auditor, config: config}
+}
+
+// ServeHTTP implements http.Handler. Malformed reviews are answered with a client error, and reviews which could not
+// be audited are answered with a server error so the failure policy of the webhook applies
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxRequestBytes))
+	if err != nil {
+		http.Error(w, "error reading the admission review", http.StatusBadRequest)
+		return
+	}
+
+	review := &admissionv1.AdmissionReview{}
+	if err := json.Unmarshal(body, review); err != nil || review.Request == nil {
+		http.Error(w, "expected an admission review with a request", http.StatusBadRequest)
+		return
+	}
+
+	response, err := h.Review(review.Request)
+	if err != nil {
+		log.WithError(err).Error("Error auditing admission request")
+		http.Error(w, "error auditing the resource", http.StatusInternalServerError)
+		return
+	}
+
+	review.Request = nil
+	review.Response = response
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		log.WithError(err).Error("Error writing admission response")
+	}
+}
+
+// Review audits the resource in an admission request. The resource is audited on its own, so auditors which need
+// other resources as context, such as the netpols auditor, do not report findings for it. Only creates and updates
+// are audited
+func (h *Handler) Review(request *admissionv1.AdmissionRequest) (*admissionv1.AdmissionResponse, error) {
+	response := &admissionv1.AdmissionResponse{UID: request.UID, Allowed: true}
+	if request.Operation != admissionv1.Create && request.Operation != admissionv1.Update {
+		return response, nil
+	}
+	if len(request.Object.Raw) == 0 {
+		return response, nil
+	}
+
+	if !h.config.IncludeGenerated {
+		meta := &metav1.PartialObjectMetadata{}
+		if err := json.Unmarshal(request.Object.Raw, meta); err != nil {
+			return nil, fmt.Errorf("error decoding the metadata of %s %s: %w", request.Kind.Kind, request.Name, err)
+		}
+		if k8sinternal.IsGenerated(meta) {
+			return response, nil
+		}
+	}
+
+	report, err := h.auditor.AuditResource(request.Object.Raw)
+	if err != nil {
+		return nil, fmt.Errorf("error auditing %s %s: %w", request.Kind.Kind, request.Name, err)
+	}
+
+	var findings []string
+	for _, result := range report.ResultsWithMinSeverity(h.config.RejectSeverity) {
+		for _, auditResult := range result.GetAuditResults() {
+			findings = append(findings, formatFinding(auditResult))
+		}
+	}
+	if len(findings) == 0 {
+		return response, nil
+	}
+
+	log.WithFields(log.Fields{
+		"kind":      request.Kind.Kind,
+		"namespace": request.Namespace,
+		"name":      request.Name,
+		"findings":  len(findings),
+		"auditMode": h.config.AuditMode,
+	}).Info("Resource has findings")
+
+	if h.config.AuditMode {
+		response.Warnings = findings
+		return response, nil
+	}
+
+	response.Allowed = false
+	response.Result = &metav1.Status{
+		Status:  metav1.StatusFailure,
+		Reason:  metav1.StatusReasonForbidden,
+		Code:    http.StatusForbidden,
+		Message: fmt.Sprintf("kubeaudit rejected the resource: %s", strings.Join(findings, "; ")),
+	}
+	return response, nil
+}
+
+// formatFinding returns a finding in the form "[severity] Rule (container): message"
+func formatFinding(auditResult *kubeaudit.AuditResult) string {
+	finding := fmt.Sprintf("[%s] %s", auditResult.Severity, auditResult.Rule)
+	if container := auditResult.Metadata["Container"]; container != "" {
+		finding += fmt.Sprintf(" (%s)", container)
+	}
+	return finding + ": " + redact.String(auditResult.Message)
+}
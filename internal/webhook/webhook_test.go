@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+)
+
+const (
+	privilegedPod = `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"default"},"spec":{"containers":[{"name":"container","image":"scratch","securityContext":{"privileged":true}}]}}`
+	generatedPod  = `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"default","ownerReferences":[{"apiVersion":"apps/v1","kind":"ReplicaSet","name":"rs","uid":"1"}]},"spec":{"containers":[{"name":"container","image":"scratch","securityContext":{"privileged":true}}]}}`
+	untaggedPod   = `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","namespace":"default"},"spec":{"containers":[{"name":"container","image":"scratch","securityContext":{"privileged":false}}]}}`
+)
+
+func TestReview(t *testing.T) {
+	cases := []struct {
+		testName        string
+		object          string
+		operation       admissionv1.Operation
+		config          Config
+		expectedAllowed bool
+		expectWarnings  bool
+	}{
+		{"Privileged pod is rejected", privilegedPod, admissionv1.Create, Config{RejectSeverity: kubeaudit.Error}, false, false},
+		{"Privileged pod update is rejected", privilegedPod, admissionv1.Update, Config{RejectSeverity: kubeaudit.Error}, false, false},
+		{"Privileged pod is warned on in audit mode", privilegedPod, admissionv1.Create, Config{RejectSeverity: kubeaudit.Error, AuditMode: true}, true, true},
+		{"Deletes are not audited", privilegedPod, admissionv1.Delete, Config{RejectSeverity: kubeaudit.Error}, true, false},
+		{"Findings below the reject severity are allowed", untaggedPod, admissionv1.Create, Config{RejectSeverity: kubeaudit.Error}, true, false},
+		{"Findings at the reject severity are rejected", untaggedPod, admissionv1.Create, Config{RejectSeverity: kubeaudit.Warn}, false, false},
+		{"Generated pod is not audited", generatedPod, admissionv1.Create, Config{RejectSeverity: kubeaudit.Error}, true, false},
+		{"Generated pod is audited if generated resources are included", generatedPod, admissionv1.Create, Config{RejectSeverity: kubeaudit.Error, IncludeGenerated: true}, false, false},
+	}
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New(), image.New(image.Config{})})
+	require.NoError(t, err)
+
+	for _, tc := range cases {
+		// This is needed for tests running in parallel (see https://gist.github.com/posener/92a55c4cd441fc5e5e85f27bca008721)
+		tc := tc
+		t.Run(tc.testName, func(t *testing.T) {
+			t.Parallel()
+
+			handler := NewHandler(auditor, tc.config)
+			response, err := handler.Review(&admissionv1.AdmissionRequest{
+				UID:       "uid",
+				Operation: tc.operation,
+				Object:    runtime.RawExtension{Raw: []byte(tc.object)},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, "uid", string(response.UID))
+			assert.Equal(t, tc.expectedAllowed, response.Allowed)
+			assert.Equal(t, tc.expectWarnings, len(response.Warnings) > 0)
+			if !tc.expectedAllowed {
+				require.NotNil(t, response.Result)
+				assert.Equal(t, int32(http.StatusForbidden), response.Result.Code)
+			}
+		})
+	}
+}
+
+func TestReviewMessage(t *testing.T) {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+
+	request := &admissionv1.AdmissionRequest{Operation: admissionv1.Create, Object: runtime.RawExtension{Raw: []byte(privilegedPod)}}
+
+	response, err := NewHandler(auditor, Config{RejectSeverity: kubeaudit.Error}).Review(request)
+	require.NoError(t, err)
+	assert.Contains(t, response.Result.Message, "[error] PrivilegedTrue (container): ")
+
+	response, err = NewHandler(auditor, Config{RejectSeverity: kubeaudit.Error, AuditMode: true}).Review(request)
+	require.NoError(t, err)
+	require.Len(t, response.Warnings, 1)
+	assert.Contains(t, response.Warnings[0], "[error] PrivilegedTrue (container): ")
+}
+
+func TestServeHTTP(t *testing.T) {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+	handler := NewHandler(auditor, Config{RejectSeverity: kubeaudit.Error})
+
+	review := admissionv1.AdmissionReview{Request: &admissionv1.AdmissionRequest{
+		UID:       "uid",
+		Operation: admissionv1.Create,
+		Object:    runtime.RawExtension{Raw: []byte(privilegedPod)},
+	}}
+	review.APIVersion = "admission.k8s.io/v1"
+	review.Kind = "AdmissionReview"
+	body, err := json.Marshal(review)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader(body)))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	response := admissionv1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, "admission.k8s.io/v1", response.APIVersion)
+	assert.Equal(t, "AdmissionReview", response.Kind)
+	assert.Nil(t, response.Request)
+	require.NotNil(t, response.Response)
+	assert.Equal(t, "uid", string(response.Response.UID))
+	assert.False(t, response.Response.Allowed)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/validate", bytes.NewReader([]byte("{}"))))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/validate", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
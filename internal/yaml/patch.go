@@ -0,0 +1,642 @@
+package yaml
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	goyaml "gopkg.in/yaml.v3"
+)
+
+const (
+	nullTag  = "!!null"
+	mergeTag = "!!merge"
+)
+
+// errReplace is returned when a node can't be changed in place, so the mapping entry or sequence item which contains
+// it has to be replaced as a whole
+var errReplace = errors.New("node can't be changed in place")
+
+// itemPrefix matches the text before a block sequence item which starts on the same line as its dash
+var itemPrefix = regexp.MustCompile(`^ *- +$`)
+
+// Patch applies the changes between the original YAML and the fixed YAML to the original YAML by editing only the
+// lines of the fields which changed. The formatting, comments, blank lines and anchors of the rest of
+// the original are kept, so the diff between the original and the patched YAML only shows the fixed fields.
+//
+// Fields which the fixed YAML adds with an empty value, such as "status: {}" or "creationTimestamp: null", are left
+// out as they are added by encoding the resource rather than by a fix. If a change can't be made by editing the lines
+// of the original, the merged YAML is returned with the leading and trailing whitespace of the original instead.
+func Patch(origData, fixedData []byte) ([]byte, error) {
+	origYaml, err := unmarshal(origData)
+	if err != nil {
+		return nil, err
+	}
+
+	fixedYaml, err := unmarshal(fixedData)
+	if err != nil {
+		return nil, err
+	}
+
+	// The merge changes the nodes of the original, so it's done on a copy of them. The positions of the fixed nodes
+	// are cleared so that the nodes of the merged YAML which come from the original can be told apart by their position
+	mergeBase, err := unmarshal(origData)
+	if err != nil {
+		return nil, err
+	}
+	clearPositions(fixedYaml)
+	pruneEmpty(mergeBase.Content[0], fixedYaml.Content[0])
+
+	mergedYaml := shallowCopyNode(mergeBase)
+	mergedYaml.Content = []*goyaml.Node{
+		mergeMaps(mergeBase.Content[0], fixedYaml.Content[0]),
+	}
+	mergedData, err := marshal(mergedYaml)
+	if err != nil {
+		return nil, err
+	}
+
+	p := newPatcher(origData, origYaml.Content[0])
+	if err := p.diffMap(origYaml.Content[0], mergedYaml.Content[0], len(p.lines)); err == nil {
+		patched := p.apply()
+		if sameContent(patched, mergedData) {
+			return patched, nil
+		}
+	}
+
+	mergedData = bytes.TrimSuffix(mergedData, []byte("\n"))
+	return bytes.Replace(origData, bytes.TrimSpace(origData), mergedData, 1), nil
+}
+
+// lineEdit replaces the lines [start, end) of the original YAML with lines. Lines are inserted if start and end are
+// the same
+type lineEdit struct {
+	start int
+	end   int
+	lines []string
+}
+
+// scalarEdit replaces the text [start, end) of a line of the original YAML with text
+type scalarEdit struct {
+	line  int
+	start int
+	end   int
+	text  string
+}
+
+// patcher records the edits which change the original YAML into the merged YAML
+type patcher struct {
+	lines       []string
+	lineEnd     string
+	indent      int
+	compactSeqs bool
+	lineEdits   []lineEdit
+	scalarEdits []scalarEdit
+}
+
+func newPatcher(data []byte, root *goyaml.Node) *patcher {
+	p := &patcher{lines: strings.Split(string(data), "\n"), indent: 2}
+	if bytes.Contains(data, []byte("\r\n")) {
+		p.lineEnd = "\r"
+	}
+
+	// New fields are indented like the original. Only the first nested mapping and sequence are looked at, as the
+	// indentation is usually the same throughout a manifest
+	var foundIndent, foundSeq bool
+	var detect func(node *goyaml.Node)
+	detect = func(node *goyaml.Node) {
+		if node.Kind == goyaml.MappingNode {
+			for i := 0; i < len(node.Content); i += 2 {
+				key, value := node.Content[i], node.Content[i+1]
+				if value.Style&goyaml.FlowStyle == 0 && len(value.Content) > 0 {
+					if !foundIndent && value.Kind == goyaml.MappingNode && value.Column-key.Column >= 2 {
+						p.indent, foundIndent = value.Column-key.Column, true
+					}
+					if !foundSeq && value.Kind == goyaml.SequenceNode {
+						p.compactSeqs, foundSeq = value.Column == key.Column, true
+					}
+				}
+			}
+		}
+		for _, child := range node.Content {
+			detect(child)
+		}
+	}
+	detect(root)
+
+	return p
+}
+
+// diffMap records the edits which change the original mapping into the merged mapping. The mapping ends before the
+// line limit
+func (p *patcher) diffMap(orig, merged *goyaml.Node, limit int) error {
+	if orig.Style&goyaml.FlowStyle != 0 || len(orig.Content) == 0 || len(merged.Content) == 0 {
+		if equalContent(orig, merged) {
+			return nil
+		}
+		return errReplace
+	}
+
+	column := orig.Content[0].Column - 1
+	inherited := map[*goyaml.Node]bool{}
+	end := limit
+	for i := 0; i < len(orig.Content); i += 2 {
+		key, value := orig.Content[i], orig.Content[i+1]
+		start := key.Line - 1
+		end = limit
+		if i+2 < len(orig.Content) {
+			end = orig.Content[i+2].Line - 1
+		}
+		end = p.trimEnd(start, end, column)
+
+		if key.Tag == mergeTag {
+			if err := checkMergeKey(orig, value, merged, inherited); err != nil {
+				return err
+			}
+			continue
+		}
+
+		mergedIndex := findNodeAt(key, merged.Content)
+		if mergedIndex == -1 {
+			if err := p.deleteEntry(start, end, column); err != nil {
+				return err
+			}
+			continue
+		}
+
+		mergedKey, mergedValue := merged.Content[mergedIndex], merged.Content[mergedIndex+1]
+		if err := p.diffChild(key.Value, value, mergedValue, end); err == errReplace {
+			lines, err := p.render(entryNode(mergedKey, mergedValue))
+			if err != nil {
+				return err
+			}
+			if err := p.replaceEntry(start, end, column, lines); err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+
+	var added []string
+	for i := 0; i < len(merged.Content); i += 2 {
+		key := merged.Content[i]
+		if key.Line != 0 || inherited[key] {
+			continue
+		}
+		lines, err := p.render(entryNode(key, merged.Content[i+1]))
+		if err != nil {
+			return err
+		}
+		added = append(added, lines...)
+	}
+	if len(added) > 0 {
+		p.lineEdits = append(p.lineEdits, lineEdit{start: end, end: end, lines: p.indentLines(added, column)})
+	}
+	return nil
+}
+
+// diffSequence records the edits which change the original sequence into the merged sequence. The sequence ends
+// before the line limit
+func (p *patcher) diffSequence(sequenceKey string, orig, merged *goyaml.Node, limit int) error {
+	if orig.Style&goyaml.FlowStyle != 0 || len(orig.Content) == 0 || len(merged.Content) == 0 {
+		if equalContent(orig, merged) {
+			return nil
+		}
+		return errReplace
+	}
+
+	var prefix string
+	end := limit
+	for i, item := range orig.Content {
+		start := item.Line - 1
+		itemPrefix, ok := p.itemPrefix(item)
+		if !ok {
+			return errReplace
+		}
+		if prefix == "" {
+			prefix = itemPrefix
+		}
+		end = limit
+		if i+1 < len(orig.Content) {
+			end = orig.Content[i+1].Line - 1
+		}
+		end = p.trimEnd(start, end, strings.Index(itemPrefix, "-"))
+
+		mergedIndex := findNodeAt(item, merged.Content)
+		if mergedIndex == -1 {
+			p.lineEdits = append(p.lineEdits, lineEdit{start: start, end: end})
+			continue
+		}
+
+		if err := p.diffChild(sequenceKey, item, merged.Content[mergedIndex], end); err == errReplace {
+			lines, err := p.renderItem(itemPrefix, merged.Content[mergedIndex])
+			if err != nil {
+				return err
+			}
+			p.lineEdits = append(p.lineEdits, lineEdit{start: start, end: end, lines: lines})
+		} else if err != nil {
+			return err
+		}
+	}
+
+	for _, item := range merged.Content {
+		if item.Line != 0 {
+			continue
+		}
+		lines, err := p.renderItem(prefix, item)
+		if err != nil {
+			return err
+		}
+		p.lineEdits = append(p.lineEdits, lineEdit{start: end, end: end, lines: lines})
+	}
+	return nil
+}
+
+// diffChild records the edits which change the original value of a mapping entry or sequence item into the merged
+// value. If it returns errReplace, the edits recorded for the value are dropped so the caller can replace it
+func (p *patcher) diffChild(sequenceKey string, orig, merged *goyaml.Node, limit int) error {
+	lineEdits, scalarEdits := len(p.lineEdits), len(p.scalarEdits)
+	err := p.diffValue(sequenceKey, orig, merged, limit)
+	if err == errReplace {
+		p.lineEdits, p.scalarEdits = p.lineEdits[:lineEdits], p.scalarEdits[:scalarEdits]
+	}
+	return err
+}
+
+func (p *patcher) diffValue(sequenceKey string, orig, merged *goyaml.Node, limit int) error {
+	// Values which were replaced by the merge come from the fixed YAML, which has no positions. The original may be an
+	// alias with the same content, which is kept
+	if merged.Line == 0 {
+		if equalContent(orig, merged) {
+			return nil
+		}
+		return errReplace
+	}
+
+	switch orig.Kind {
+	case goyaml.ScalarNode:
+		if orig.Value == merged.Value && orig.Tag == merged.Tag {
+			return nil
+		}
+		return p.replaceScalar(orig, merged)
+	case goyaml.MappingNode:
+		return p.diffMap(orig, merged, limit)
+	case goyaml.SequenceNode:
+		return p.diffSequence(sequenceKey, orig, merged, limit)
+	}
+	return nil
+}
+
+// replaceScalar records the edit which replaces the text of the original scalar with the merged scalar, written in the
+// same style
+func (p *patcher) replaceScalar(orig, merged *goyaml.Node) error {
+	if orig.Style&(goyaml.LiteralStyle|goyaml.FoldedStyle|goyaml.TaggedStyle) != 0 || strings.Contains(orig.Value, "\n") {
+		return errReplace
+	}
+
+	line := p.lines[orig.Line-1]
+	start := orig.Column - 1
+	end := scalarEnd(line, start, orig)
+	if end == -1 {
+		return errReplace
+	}
+
+	text, err := goyaml.Marshal(&goyaml.Node{
+		Kind:  goyaml.ScalarNode,
+		Style: orig.Style & (goyaml.DoubleQuotedStyle | goyaml.SingleQuotedStyle),
+		Tag:   merged.Tag,
+		Value: merged.Value,
+	})
+	if err != nil {
+		return err
+	}
+	text = bytes.TrimSuffix(text, []byte("\n"))
+	if bytes.Contains(text, []byte("\n")) {
+		return errReplace
+	}
+
+	p.scalarEdits = append(p.scalarEdits, scalarEdit{line: orig.Line - 1, start: start, end: end, text: string(text)})
+	return nil
+}
+
+// scalarEnd returns the index after the end of the scalar in the line, or -1 if the scalar isn't found where expected
+func scalarEnd(line string, start int, scalar *goyaml.Node) int {
+	if start >= len(line) {
+		return -1
+	}
+
+	switch {
+	case scalar.Style&goyaml.DoubleQuotedStyle != 0:
+		if line[start] != '"' {
+			return -1
+		}
+		for i := start + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+	case scalar.Style&goyaml.SingleQuotedStyle != 0:
+		if line[start] != '\'' {
+			return -1
+		}
+		for i := start + 1; i < len(line); i++ {
+			if line[i] != '\'' {
+				continue
+			}
+			if i+1 < len(line) && line[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	case strings.HasPrefix(line[start:], scalar.Value):
+		return start + len(scalar.Value)
+	}
+	return -1
+}
+
+// deleteEntry records the edit which deletes the mapping entry on the lines [start, end), along with the comment
+// lines right above it
+func (p *patcher) deleteEntry(start, end, column int) error {
+	if !p.startsLine(start, column) {
+		return errReplace
+	}
+	for start > 0 && isComment(p.lines[start-1]) && indentation(p.lines[start-1]) == column {
+		start--
+	}
+	p.lineEdits = append(p.lineEdits, lineEdit{start: start, end: end})
+	return nil
+}
+
+// replaceEntry records the edit which replaces the mapping entry on the lines [start, end) with the lines
+func (p *patcher) replaceEntry(start, end, column int, lines []string) error {
+	if !p.startsLine(start, column) {
+		return errReplace
+	}
+	p.lineEdits = append(p.lineEdits, lineEdit{start: start, end: end, lines: p.indentLines(lines, column)})
+	return nil
+}
+
+// startsLine returns true if only spaces come before the column in the line, so that the node at the column can be
+// edited by editing whole lines
+func (p *patcher) startsLine(line, column int) bool {
+	return len(p.lines[line]) >= column && strings.TrimLeft(p.lines[line][:column], " ") == ""
+}
+
+// itemPrefix returns the text before the block sequence item, which is its indentation and dash
+func (p *patcher) itemPrefix(item *goyaml.Node) (string, bool) {
+	line := p.lines[item.Line-1]
+	if item.Column-1 > len(line) || !itemPrefix.MatchString(line[:item.Column-1]) {
+		return "", false
+	}
+	return line[:item.Column-1], true
+}
+
+// trimEnd returns the end of the lines [start, end) without the trailing blank lines and the trailing comments which
+// aren't indented more than the column, as those belong to the following node
+func (p *patcher) trimEnd(start, end, column int) int {
+	for end > start+1 {
+		line := p.lines[end-1]
+		if strings.TrimSpace(line) != "" && !(isComment(line) && indentation(line) <= column) {
+			break
+		}
+		end--
+	}
+	return end
+}
+
+// render returns the lines of the node encoded with the indentation of the original
+func (p *patcher) render(node *goyaml.Node) ([]string, error) {
+	data := bytes.NewBuffer(nil)
+	encoder := goyaml.NewEncoder(data)
+	encoder.SetIndent(p.indent)
+	if err := encoder.Encode(node); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimSuffix(data.String(), "\n"), "\n")
+	if p.compactSeqs {
+		lines = compactSequences(lines, p.indent)
+	}
+	return lines, nil
+}
+
+// renderItem returns the lines of the node as an item of a block sequence, where prefix is the indentation and dash of
+// the item
+func (p *patcher) renderItem(prefix string, node *goyaml.Node) ([]string, error) {
+	lines, err := p.render(node)
+	if err != nil {
+		return nil, err
+	}
+	for i, line := range lines {
+		if i == 0 {
+			lines[i] = prefix + line + p.lineEnd
+		} else if line != "" {
+			lines[i] = strings.Repeat(" ", len(prefix)) + line + p.lineEnd
+		}
+	}
+	return lines, nil
+}
+
+func (p *patcher) indentLines(lines []string, column int) []string {
+	indented := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line != "" {
+			line = strings.Repeat(" ", column) + line
+		}
+		indented = append(indented, line+p.lineEnd)
+	}
+	return indented
+}
+
+// apply returns the original YAML with the recorded edits applied
+func (p *patcher) apply() []byte {
+	lines := append([]string{}, p.lines...)
+
+	// Edits of the same line are applied from the last to the first so the columns of the others stay valid
+	sort.Slice(p.scalarEdits, func(i, j int) bool {
+		if p.scalarEdits[i].line != p.scalarEdits[j].line {
+			return p.scalarEdits[i].line < p.scalarEdits[j].line
+		}
+		return p.scalarEdits[i].start > p.scalarEdits[j].start
+	})
+	for _, edit := range p.scalarEdits {
+		line := lines[edit.line]
+		lines[edit.line] = line[:edit.start] + edit.text + line[edit.end:]
+	}
+
+	// Line edits are applied from the last to the first so the lines of the others stay valid. Edits which start on
+	// the same line are applied in reverse, so lines inserted at the same place end up in the order they were recorded
+	sort.SliceStable(p.lineEdits, func(i, j int) bool {
+		return p.lineEdits[i].start < p.lineEdits[j].start
+	})
+	for i := len(p.lineEdits) - 1; i >= 0; i-- {
+		edit := p.lineEdits[i]
+		edited := make([]string, 0, len(lines)-(edit.end-edit.start)+len(edit.lines))
+		edited = append(edited, lines[:edit.start]...)
+		edited = append(edited, edit.lines...)
+		lines = append(edited, lines[edit.end:]...)
+	}
+
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// checkMergeKey checks that the entries which the mapping inherits with a merge key ("<<") have the same value in the
+// merged mapping, and marks them as inherited so they aren't added to the mapping. Entries whose merged value is
+// different are added to the mapping, which overrides the inherited value
+func checkMergeKey(orig, value, merged *goyaml.Node, inherited map[*goyaml.Node]bool) error {
+	sources := []*goyaml.Node{value}
+	if value.Kind == goyaml.SequenceNode {
+		sources = value.Content
+	}
+
+	for _, source := range sources {
+		if source.Kind == goyaml.AliasNode {
+			source = source.Alias
+		}
+		if source.Kind != goyaml.MappingNode {
+			return errReplace
+		}
+		for i := 0; i < len(source.Content); i += 2 {
+			key := source.Content[i]
+			if isKeyInMap(key, orig) {
+				continue
+			}
+			mergedIndex := findKeyInMap(key, merged)
+			if mergedIndex == -1 {
+				return errReplace
+			}
+			if equalContent(source.Content[i+1], merged.Content[mergedIndex+1]) {
+				inherited[merged.Content[mergedIndex]] = true
+			}
+		}
+	}
+	return nil
+}
+
+// compactSequences removes the indentation the encoder adds to block sequences which are the value of a mapping entry,
+// for originals whose sequences are written at the same indentation as their key:
+//
+//	containers:
+//	- name: container
+func compactSequences(lines []string, indent int) []string {
+	var keyColumns []int
+	compacted := make([]string, 0, len(lines))
+	for i, line := range lines {
+		lineIndent := indentation(line)
+		for len(keyColumns) > 0 && strings.TrimSpace(line) != "" && lineIndent <= keyColumns[len(keyColumns)-1] {
+			keyColumns = keyColumns[:len(keyColumns)-1]
+		}
+
+		shift := len(keyColumns) * indent
+		if shift > lineIndent {
+			shift = lineIndent
+		}
+		compacted = append(compacted, line[shift:])
+
+		if strings.HasSuffix(line, ":") && i+1 < len(lines) {
+			keyColumn := len(line) - len(strings.TrimLeft(line, " -"))
+			next := lines[i+1]
+			if indentation(next) == keyColumn+indent && strings.HasPrefix(strings.TrimLeft(next, " "), "-") {
+				keyColumns = append(keyColumns, keyColumn)
+			}
+		}
+	}
+	return compacted
+}
+
+// pruneEmpty removes the entries of the fixed mapping which aren't in the original mapping and have an empty value
+func pruneEmpty(orig, fixed *goyaml.Node) {
+	content := make([]*goyaml.Node, 0, len(fixed.Content))
+	for i := 0; i < len(fixed.Content); i += 2 {
+		key, value := fixed.Content[i], fixed.Content[i+1]
+		if origIndex := findKeyInMap(key, orig); origIndex != -1 {
+			pruneEmptyValue(key.Value, orig.Content[origIndex+1], value)
+		} else if isEmpty(value) {
+			continue
+		}
+		content = append(content, key, value)
+	}
+	fixed.Content = content
+}
+
+func pruneEmptyValue(sequenceKey string, orig, fixed *goyaml.Node) {
+	if orig.Kind == goyaml.AliasNode {
+		orig = orig.Alias
+	}
+	switch {
+	case orig.Kind == goyaml.MappingNode && fixed.Kind == goyaml.MappingNode:
+		pruneEmpty(orig, fixed)
+	case orig.Kind == goyaml.SequenceNode && fixed.Kind == goyaml.SequenceNode:
+		for _, item := range fixed.Content {
+			if origIndex := findItemInSequence(sequenceKey, item, orig); origIndex != -1 {
+				pruneEmptyValue(sequenceKey, orig.Content[origIndex], item)
+			}
+		}
+	}
+}
+
+func isEmpty(node *goyaml.Node) bool {
+	return (node.Kind == goyaml.MappingNode && len(node.Content) == 0) || (node.Kind == goyaml.ScalarNode && node.Tag == nullTag)
+}
+
+// clearPositions sets the line and column of the node and its children to 0
+func clearPositions(node *goyaml.Node) {
+	node.Line, node.Column = 0, 0
+	for _, child := range node.Content {
+		clearPositions(child)
+	}
+}
+
+// findNodeAt returns the index of the node in nodes which is at the same position as node, or -1 if there is none
+func findNodeAt(node *goyaml.Node, nodes []*goyaml.Node) int {
+	for i, other := range nodes {
+		if other.Line == node.Line && other.Column == node.Column {
+			return i
+		}
+	}
+	return -1
+}
+
+func entryNode(key, value *goyaml.Node) *goyaml.Node {
+	// The head comment of the key is above the lines of the entry, which are replaced, so it's left out
+	entryKey := *key
+	entryKey.HeadComment = ""
+	return &goyaml.Node{Kind: goyaml.MappingNode, Tag: mapTag, Content: []*goyaml.Node{&entryKey, value}}
+}
+
+// equalContent returns true if the nodes have the same content once aliases and merge keys are resolved
+func equalContent(node1, node2 *goyaml.Node) bool {
+	var content1, content2 interface{}
+	if node1.Decode(&content1) != nil || node2.Decode(&content2) != nil {
+		return false
+	}
+	return reflect.DeepEqual(content1, content2)
+}
+
+// sameContent returns true if the YAML documents have the same content
+func sameContent(data1, data2 []byte) bool {
+	var content1, content2 interface{}
+	if goyaml.Unmarshal(data1, &content1) != nil || goyaml.Unmarshal(data2, &content2) != nil {
+		return false
+	}
+	return reflect.DeepEqual(content1, content2)
+}
+
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
@@ -0,0 +1,130 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatch(t *testing.T) {
+	cases := []struct {
+		testName string
+		orig     string
+		fixed    string
+		expected string
+	}{
+		{
+			"Update keeps comments and style",
+			"\n# Pod\nkind: Pod   # kind\n\nspec:\n    hostNetwork: true   # why?\n    name: 'p'\n",
+			"kind: Pod\nspec:\n  hostNetwork: false\n  name: p\n",
+			"\n# Pod\nkind: Pod   # kind\n\nspec:\n    hostNetwork: false   # why?\n    name: 'p'\n",
+		},
+		{
+			"Update keeps quotes",
+			"a: \"b\" # c\n",
+			"a: d\n",
+			"a: \"d\" # c\n",
+		},
+		{
+			"Add uses the indentation of the original",
+			"spec:\n    containers:\n    - name: c\n      image: i\n\n    volumes: []\n",
+			"spec:\n  containers:\n  - name: c\n    image: i\n    securityContext:\n      capabilities:\n        drop:\n        - ALL\n  volumes: []\n",
+			"spec:\n    containers:\n    - name: c\n      image: i\n      securityContext:\n          capabilities:\n              drop:\n              - ALL\n\n    volumes: []\n",
+		},
+		{
+			"Add sequence item",
+			"list:\n  - a\n  - b\nother: c\n",
+			"list:\n- a\n- b\n- c\nother: c\n",
+			"list:\n  - a\n  - b\n  - c\nother: c\n",
+		},
+		{
+			"Delete entry and its comment",
+			"a: b\n# the capabilities\ncapabilities:\n  add:\n    - NET_ADMIN\nc: d\n",
+			"a: b\nc: d\n",
+			"a: b\nc: d\n",
+		},
+		{
+			"Delete sequence item",
+			"add:\n  - NET_ADMIN\n  - CHOWN\n",
+			"add:\n- CHOWN\n",
+			"add:\n  - CHOWN\n",
+		},
+		{
+			"Replace flow mapping",
+			"a: b\nsecurityContext: {privileged: true}  \nc: d\n",
+			"a: b\nsecurityContext:\n  privileged: false\nc: d\n",
+			"a: b\nsecurityContext: {privileged: false}\nc: d\n",
+		},
+		{
+			"Replace mapping which is emptied",
+			"metadata:\n  annotations:\n    a: b\n  name: n\n",
+			"metadata:\n  annotations: {}\n  name: n\n",
+			"metadata:\n  annotations: {}\n  name: n\n",
+		},
+		{
+			"Alias is kept",
+			"labels: &labels\n  app: a\nselector: *labels\nreplicas: 1\n",
+			"labels:\n  app: a\nselector:\n  app: a\nreplicas: 2\n",
+			"labels: &labels\n  app: a\nselector: *labels\nreplicas: 2\n",
+		},
+		{
+			"Merge key is kept",
+			"base: &base\n  a: b\nmap:\n  <<: *base\n  c: d\n",
+			"base:\n  a: b\nmap:\n  a: b\n  c: e\n",
+			"base: &base\n  a: b\nmap:\n  <<: *base\n  c: e\n",
+		},
+		{
+			"Empty fields are not added",
+			"metadata:\n  name: n\nspec:\n  a: b\n",
+			"metadata:\n  creationTimestamp: null\n  name: n\nspec:\n  a: c\n  strategy: {}\nstatus: {}\n",
+			"metadata:\n  name: n\nspec:\n  a: c\n",
+		},
+		{
+			"Unchanged",
+			"a:   b # c\n",
+			"a: b\n",
+			"a:   b # c\n",
+		},
+		{
+			"Flow root is merged",
+			"\n{a: b, c: d}\n",
+			"a: e\nc: d\n",
+			"\n{a: e, c: d}\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			patched, err := Patch([]byte(tc.orig), []byte(tc.fixed))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, string(patched))
+		})
+	}
+
+	_, err := Patch([]byte("a: b: c"), []byte("a: b"))
+	assert.Error(t, err)
+	_, err = Patch([]byte("a: b"), []byte("- a"))
+	assert.Error(t, err)
+}
+
+func TestCompactSequences(t *testing.T) {
+	lines := []string{
+		"containers:",
+		"  - name: c",
+		"    capabilities:",
+		"      drop:",
+		"        - ALL",
+		"    image: i",
+		"volumes: []",
+	}
+	assert.Equal(t, []string{
+		"containers:",
+		"- name: c",
+		"  capabilities:",
+		"    drop:",
+		"    - ALL",
+		"  image: i",
+		"volumes: []",
+	}, compactSequences(lines, 2))
+}
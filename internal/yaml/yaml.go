@@ -15,28 +15,6 @@ const (
 	mapTag = "!!map"
 )
 
-// Merge merges the original YAML with the fixed YAML such that the resulting YAML is autofixed but with the
-// same order and comments as the original.
-func Merge(origData, fixedData []byte) ([]byte, error) {
-	origYaml, err := unmarshal(origData)
-	if err != nil {
-		return nil, err
-	}
-
-	fixedYaml, err := unmarshal(fixedData)
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a new document node that contains the merged maps for the original and fixed yaml
-	mergedYaml := shallowCopyNode(origYaml)
-	mergedYaml.Content = []*goyaml.Node{
-		mergeMaps(origYaml.Content[0], fixedYaml.Content[0]),
-	}
-
-	return marshal(mergedYaml)
-}
-
 func unmarshal(data []byte) (*goyaml.Node, error) {
 	var node goyaml.Node
 
@@ -317,6 +295,7 @@ var identifyingKey = map[string]string{
 	"containers":           "name",             // PodSpec.containers : Container.name
 	"egress":               "ports",            // NetworkPolicySpec.egress : NetworkPolicyEgressRule.ports
 	"env":                  "name",             // Container.env : EnvVar.name
+	"ephemeralContainers":  "name",             // PodSpec.ephemeralContainers : EphemeralContainer.name
 	"hostAliases":          "ip",               // PodSpec.hostAliases : HostAlias.ip
 	// Assumes it is not possible to add multiple values for the same header, ie.
 	//     httpHeaders:
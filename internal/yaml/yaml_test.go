@@ -7,37 +7,6 @@ import (
 	yaml "gopkg.in/yaml.v3"
 )
 
-func TestMerge(t *testing.T) {
-	assert := assert.New(t)
-
-	// empty yaml
-	_, err := Merge(nil, nil)
-	assert.NotNil(err)
-	_, err = Merge([]byte{}, []byte{})
-	assert.NotNil(err)
-	_, err = Merge([]byte(""), []byte(""))
-	assert.NotNil(err)
-
-	// invalid yaml
-	_, err = Merge([]byte("a: b: c"), nil)
-	assert.NotNil(err)
-	_, err = Merge([]byte("a:"), nil)
-	assert.NotNil(err)
-	_, err = Merge([]byte("a: b"), []byte("a: b: c"))
-	assert.NotNil(err)
-
-	// non-map root node
-	_, err = Merge([]byte("- a"), nil)
-	assert.NotNil(err)
-	_, err = Merge([]byte("a: b"), []byte("- a"))
-	assert.NotNil(err)
-
-	// valid yaml
-	merged, err := Merge([]byte("a: b"), []byte("a: b"))
-	assert.NoError(err)
-	assert.Equal("a: b\n", string(merged))
-}
-
 func TestMergeMaps(t *testing.T) {
 	cases := []struct {
 		testName string
@@ -0,0 +1,23 @@
+package kubeaudit
+
+import "github.com/Shopify/kubeaudit/pkg/k8s"
+
+// CronJobMetadata is the metadata key of the CronJob which created the Job of an audit result. The Jobs of a CronJob
+// are named after the time they were scheduled at, so their audit results are attributed to the CronJob to identify
+// them across audits
+const CronJobMetadata = "CronJob"
+
+// setCronJobMetadata adds the CronJob which created the resource to the metadata of its audit results, if the
+// resource is a Job created by a CronJob
+func setCronJobMetadata(resource k8s.Resource, auditResults []*AuditResult) {
+	cronJobName := k8s.GetCronJobName(resource)
+	if cronJobName == "" {
+		return
+	}
+	for _, auditResult := range auditResults {
+		if auditResult.Metadata == nil {
+			auditResult.Metadata = Metadata{}
+		}
+		auditResult.Metadata[CronJobMetadata] = cronJobName
+	}
+}
@@ -0,0 +1,26 @@
+package kubeaudit
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetCronJobMetadata(t *testing.T) {
+	job := k8s.NewJob()
+	job.Name = "backup-27812345"
+	job.OwnerReferences = []metav1.OwnerReference{{APIVersion: "batch/v1", Kind: "CronJob", Name: "backup", Controller: k8s.NewTrue()}}
+
+	auditResults := []*AuditResult{{Metadata: Metadata{"Container": "container"}}, {}}
+	setCronJobMetadata(job, auditResults)
+	assert.Equal(t, Metadata{"Container": "container", "CronJob": "backup"}, auditResults[0].Metadata)
+	assert.Equal(t, Metadata{"CronJob": "backup"}, auditResults[1].Metadata)
+
+	// Jobs which are not created by a CronJob, and other resources, are unchanged
+	auditResults = []*AuditResult{{Metadata: Metadata{"Container": "container"}}}
+	setCronJobMetadata(k8s.NewJob(), auditResults)
+	setCronJobMetadata(k8s.NewCronJob(), auditResults)
+	assert.Equal(t, Metadata{"Container": "container"}, auditResults[0].Metadata)
+}
@@ -1,6 +1,9 @@
 package kubeaudit
 
-import "github.com/Shopify/kubeaudit/pkg/k8s"
+import (
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
 
 // ErrorUnsupportedResource occurs when Kubeaudit doesn't know how to audit the resource
 const ErrorUnsupportedResource = "Unsupported resource"
@@ -9,6 +12,32 @@ const ErrorUnsupportedResource = "Unsupported resource"
 // but that auditor found no security issues so the label is redundant
 const RedundantAuditorOverride = "RedundantAuditorOverride"
 
+// ExpiredAuditorOverride is the audit result name given when an override label has an expiry date which has passed.
+// The override is ignored, so the results it overrode are reported again
+const ExpiredAuditorOverride = "ExpiredAuditorOverride"
+
+// AuditorPanic is the audit result name given when an auditor panics while auditing a resource. The panic is
+// recovered so the remaining auditors and resources are still audited
+const AuditorPanic = "AuditorPanic"
+
+// AuditorError is the audit result name given when an auditor returns an error while auditing a resource, such as a
+// resource it can't decode. The remaining auditors and resources are still audited, unless the audit is strict
+const AuditorError = "AuditorError"
+
+// ManifestAuditor is the auditor name of the audit results for the schema errors of manifests
+const ManifestAuditor = "Manifest"
+
+// ManifestSyntaxError is the audit result name given when a document of a manifest is not valid YAML
+const ManifestSyntaxError = "ManifestSyntaxError"
+
+// ManifestUnknownField is the audit result name given when a resource of a manifest has a field which its kind does
+// not have, such as a misspelled or wrongly indented field
+const ManifestUnknownField = "ManifestUnknownField"
+
+// ManifestInvalidType is the audit result name given when a field of a resource of a manifest has a value of the
+// wrong type, such as a string for a boolean
+const ManifestInvalidType = "ManifestInvalidType"
+
 // KubeResource is a wrapper around a Kubernetes object
 type KubeResource interface {
 	// Object is a pointer to a Kubernetes resource. The resource may be modified by multiple auditors
@@ -17,10 +46,23 @@ type KubeResource interface {
 	Bytes() []byte
 }
 
+// ResourceName returns the identity of the resource in the form kind/namespace/name, or an empty string if the resource
+// or its object is nil
+func ResourceName(resource KubeResource) string {
+	if resource == nil {
+		return ""
+	}
+	return k8s.GetResourceName(resource.Object())
+}
+
 // Implements KubeResource
 type kubeResource struct {
 	object k8s.Resource
 	bytes  []byte
+	// filePath is the manifest file the resource was read from by AuditManifestFiles
+	filePath string
+	// schemaErrors are the problems of the document of the resource which the Kubernetes API would reject it for
+	schemaErrors []k8sinternal.SchemaError
 }
 
 func (k *kubeResource) Object() k8s.Resource {
@@ -30,3 +72,11 @@ func (k *kubeResource) Object() k8s.Resource {
 func (k *kubeResource) Bytes() []byte {
 	return k.bytes
 }
+
+// manifestFilePath returns the manifest file the resource was read from by AuditManifestFiles, or an empty string
+func manifestFilePath(resource KubeResource) string {
+	if k, ok := resource.(*kubeResource); ok {
+		return k.filePath
+	}
+	return ""
+}
@@ -56,6 +56,18 @@
 //
 //   report, err := kubeAuditor.AuditManifest(manifest)
 //
+// Or, to run the audit on a kustomization directory:
+//
+//   report, err := kubeAuditor.AuditKustomize("/path/to/overlays/prod")
+//
+// Or, to run the audit on a Helm chart:
+//
+//   report, err := kubeAuditor.AuditHelmChart("/path/to/chart", []string{"/path/to/values.yaml"})
+//
+// Or, to audit a single resource encoded as YAML or JSON, such as the object of an admission request:
+//
+//   report, err := kubeAuditor.AuditResource(resourceBytes)
+//
 // Or, to run the audit in local mode:
 //
 //   report, err := kubeAuditor.AuditLocal("/path/to/kubeconfig.yml", kubeaudit.AuditOptions{})
@@ -64,6 +76,15 @@
 //
 //   report, err := auditor.AuditCluster(kubeaudit.AuditOptions{})
 //
+// Or, to keep watching the cluster and audit each workload as it is created or updated (WatchCluster and WatchLocal
+// block until the context is cancelled):
+//
+//   err := kubeAuditor.WatchLocal(ctx, "/path/to/kubeconfig.yml", "", kubeaudit.AuditOptions{}, func(key string, report *kubeaudit.Report) {
+//     if report != nil {
+//       report.PrintResults()
+//     }
+//   })
+//
 // Get the results
 //
 // To print the results in a human readable way:
@@ -80,9 +101,16 @@
 //
 //   results := report.Results()
 //
+// Or, to get each result with the identity of the resource it was reported for, to build your own output:
+//
+//   for _, finding := range report.Findings() {
+//     fmt.Println(finding.Severity, finding.Rule, finding.GroupVersionKind.Kind, finding.Namespace, finding.Name, finding.Message)
+//   }
+//
 // Autofix
 //
-// Note that autofixing is only supported in manifest mode.
+// Note that autofixing manifests is only supported in manifest mode. For Helm charts, the fixed output is the rendered manifest
+// rather than the chart templates.
 //
 // To print the plan (what will be fixed):
 //
@@ -92,6 +120,11 @@
 //
 //   err = report.Fix(os.Stdout)
 //
+// To fix the resources of a report created in local or cluster mode and apply them back to the cluster with
+// server-side apply (set DryRun to preview the changes without persisting them):
+//
+//   appliedFixes, err := report.ApplyFixesLocal("/path/to/kubeconfig.yml", "", kubeaudit.ApplyOptions{DryRun: true})
+//
 // Override Errors
 //
 // Overrides can be used to ignore specific auditors for specific containers or pods.
@@ -101,23 +134,43 @@
 //
 // Kubeaudit supports custom auditors. See the Custom Auditor example.
 //
+// Custom Resources
+//
+// Custom resources which embed a PodSpec are audited like the built-in workload types once their kind is registered.
+// Argo Rollouts and OpenKruise CloneSets are registered by default. To register another kind:
+//
+//   err := k8s.RegisterPodSpecExtractor(k8s.PodSpecExtractor{
+//     Group:       "tekton.dev",
+//     Kind:        "TaskRun",
+//     PodSpecPath: ".spec.podTemplate",
+//   })
+//
 package kubeaudit
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"path/filepath"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/Shopify/kubeaudit/internal/k8sinternal"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
 )
 
 // Kubeaudit provides functions to audit and fix Kubernetes manifests
 type Kubeaudit struct {
 	auditors []Auditable
+	// concurrency is the number of resources audited at the same time
+	concurrency int
+	// profile records the time the auditors take, if profiling is enabled with WithProfile()
+	profile *Profile
+	// strict fails audits on the first error of an auditor, set with WithStrict()
+	strict bool
 }
 
 type AuditOptions = k8sinternal.ClientOptions
@@ -129,7 +182,8 @@ func New(auditors []Auditable, opts ...Option) (*Kubeaudit, error) {
 	}
 
 	auditor := &Kubeaudit{
-		auditors: auditors,
+		auditors:    auditors,
+		concurrency: 1,
 	}
 
 	if err := auditor.parseOptions(opts); err != nil {
@@ -139,7 +193,10 @@ func New(auditors []Auditable, opts ...Option) (*Kubeaudit, error) {
 	return auditor, nil
 }
 
-// AuditManifest audits the Kubernetes resources in the provided manifest
+// AuditManifest audits the Kubernetes resources in the provided manifest. The manifest can be a stream concatenated
+// from the output of several tools, such as `helm template` and `kustomize build`. Results of resources rendered by Helm
+// are attributed to the template named by the "# Source:" comment of their document, and results of resources built by
+// Kustomize with origin annotations to the file they originated from. Other results are attributed to manifestPath
 func (a *Kubeaudit) AuditManifest(manifestPath string, manifest io.Reader) (*Report, error) {
 	manifestBytes, err := ioutil.ReadAll(manifest)
 	if err != nil {
@@ -151,20 +208,156 @@ func (a *Kubeaudit) AuditManifest(manifestPath string, manifest io.Reader) (*Rep
 		return nil, fmt.Errorf("failed to get resources from manifest: %w", err)
 	}
 
-	results, err := auditResources(resources, a.auditors)
+	results, err := auditResources(resources, a.auditors, a.concurrency, a.profile, a.strict)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range resources {
+		resource.(*kubeResource).filePath = manifestPath
+	}
+	sources := getManifestSources(resources)
+	for i, result := range results {
+		filePath := cleanFilePath(sources[i].path)
+		for _, ar := range result.GetAuditResults() {
+			ar.FilePath = filePath
+		}
+		if sources[i].lines != nil {
+			setTemplateLocation(result, sources[i].lines)
+		} else {
+			setLocation(result, sources[i].line)
+		}
+	}
+
+	report := NewReport(results)
+
+	return report, nil
+}
+
+// AuditManifestFiles audits the Kubernetes resources in the provided manifest files, such as the manifests of a
+// directory tree. The resources of all the files are audited together, so resources are audited against the resources
+// of the other files (eg. a namespace against the network policies of another file). Results are attributed to the
+// file of their resource, or to its Helm template or Kustomize origin like the results of AuditManifest(). The fixes of the report are written with FixManifests()
+func (a *Kubeaudit) AuditManifestFiles(manifestPaths []string) (*Report, error) {
+	return a.AuditManifestFilesWithContext(manifestPaths, nil)
+}
+
+// AuditManifestFilesWithContext audits the Kubernetes resources of the manifest files like AuditManifestFiles(). The
+// resources of the context files are loaded and seen by the auditors, such as the namespaces and service accounts of
+// the audited resources, but they are not audited. This audits the manifest files changed in a repository without
+// auditing the rest of it
+func (a *Kubeaudit) AuditManifestFilesWithContext(manifestPaths, contextPaths []string) (*Report, error) {
+	resources, err := getResourcesFromManifestFiles(manifestPaths)
+	if err != nil {
+		return nil, err
+	}
+	contextResources, err := getResourcesFromManifestFiles(contextPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	context := append(append([]KubeResource(nil), resources...), contextResources...)
+	results, err := auditResourcesInContext(resources, context, a.auditors, a.concurrency, a.profile, a.strict)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := getManifestSources(resources)
+	for i, result := range results {
+		filePath := cleanFilePath(sources[i].path)
+		for _, ar := range result.GetAuditResults() {
+			ar.FilePath = filePath
+		}
+		if sources[i].lines != nil {
+			setTemplateLocation(result, sources[i].lines)
+		} else {
+			setLocation(result, sources[i].line)
+		}
+	}
+
+	return NewReport(results), nil
+}
+
+// AuditResource audits a single Kubernetes resource encoded as YAML or JSON, eg. the object of an admission request.
+// The resource is audited on its own, so auditors which need other resources as context only see the resource itself.
+// Resources of kinds kubeaudit does not know about are not audited
+func (a *Kubeaudit) AuditResource(resource []byte) (*Report, error) {
+	obj, err := k8sinternal.DecodeResource(resource)
+	if k8sRuntime.IsNotRegisteredError(err) {
+		obj = nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to decode resource: %w", err)
+	}
+
+	results, err := auditResources([]KubeResource{&kubeResource{object: obj, bytes: resource}}, a.auditors, a.concurrency, a.profile, a.strict)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, result := range results {
-		auditResults := result.GetAuditResults()
+	return NewReport(results), nil
+}
+
+// AuditKustomize renders the kustomization in the provided directory and audits the resulting Kubernetes resources.
+// If the kustomization enables the "originAnnotations" build metadata option, results are attributed to the base or
+// overlay file each resource came from. Otherwise, results are attributed to the kustomization directory
+func (a *Kubeaudit) AuditKustomize(kustomizationDir string) (*Report, error) {
+	resources, paths, err := getResourcesFromKustomization(kustomizationDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build kustomization %s: %w", kustomizationDir, err)
+	}
+
+	results, err := auditResources(resources, a.auditors, a.concurrency, a.profile, a.strict)
+	if err != nil {
+		return nil, err
+	}
 
-		if !filepath.IsAbs(manifestPath) {
-			manifestPath = strings.TrimPrefix(filepath.Clean("/"+manifestPath), "/")
+	for i, result := range results {
+		filePath := cleanFilePath(paths[i])
+		for _, ar := range result.GetAuditResults() {
+			ar.FilePath = filePath
 		}
+	}
 
-		for _, ar := range auditResults {
-			ar.FilePath = manifestPath
+	report := NewReport(results)
+
+	return report, nil
+}
+
+// AuditHelmChart renders the Helm chart at the provided path using the provided values files, in the default
+// namespace, and audits the rendered Kubernetes resources. Results are attributed to the template file and line each
+// resource was rendered from. The line is mapped to the template source when the chart is a directory, and otherwise
+// refers to the rendered template
+func (a *Kubeaudit) AuditHelmChart(chartPath string, valueFiles []string) (*Report, error) {
+	return a.AuditHelmChartWithNamespace(chartPath, valueFiles, metav1.NamespaceDefault)
+}
+
+// AuditHelmChartWithNamespace audits the Helm chart at the provided path like AuditHelmChart(), with the release
+// rendered in the provided namespace
+func (a *Kubeaudit) AuditHelmChartWithNamespace(chartPath string, valueFiles []string, namespace string) (*Report, error) {
+	helmResources, err := getResourcesFromHelmChart(chartPath, valueFiles, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render helm chart %s: %w", chartPath, err)
+	}
+
+	resources := make([]KubeResource, 0, len(helmResources))
+	for _, helmResource := range helmResources {
+		resources = append(resources, helmResource.resource)
+	}
+
+	results, err := auditResources(resources, a.auditors, a.concurrency, a.profile, a.strict)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, result := range results {
+		filePath := cleanFilePath(helmResources[i].path)
+		for _, ar := range result.GetAuditResults() {
+			ar.FilePath = filePath
+		}
+		if helmResources[i].lines != nil {
+			setTemplateLocation(result, helmResources[i].lines)
+		} else {
+			setLocation(result, helmResources[i].line)
 		}
 	}
 
@@ -179,7 +372,7 @@ func (a *Kubeaudit) AuditCluster(options AuditOptions) (*Report, error) {
 		return nil, errors.New("failed to audit resources in cluster mode: not running in cluster")
 	}
 
-	client, err := k8sinternal.NewKubeClientCluster(k8sinternal.DefaultClient)
+	client, err := k8sinternal.NewKubeClientCluster(k8sinternal.DefaultClient, options.ConnectionOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -188,7 +381,7 @@ func (a *Kubeaudit) AuditCluster(options AuditOptions) (*Report, error) {
 	if err != nil {
 		return nil, err
 	}
-	results, err := auditResources(resources, a.auditors)
+	results, err := auditResources(resources, a.auditors, a.concurrency, a.profile, a.strict)
 	if err != nil {
 		return nil, err
 	}
@@ -200,7 +393,7 @@ func (a *Kubeaudit) AuditCluster(options AuditOptions) (*Report, error) {
 
 // AuditLocal audits the Kubernetes resources found in the provided Kubernetes config file
 func (a *Kubeaudit) AuditLocal(configpath string, context string, options AuditOptions) (*Report, error) {
-	client, err := k8sinternal.NewKubeClientLocal(configpath, context)
+	client, err := k8sinternal.NewKubeClientLocal(configpath, context, options.ConnectionOptions)
 	if err == k8sinternal.ErrNoReadableKubeConfig {
 		return nil, fmt.Errorf("failed to open kubeconfig file %s", configpath)
 	} else if err != nil {
@@ -211,7 +404,7 @@ func (a *Kubeaudit) AuditLocal(configpath string, context string, options AuditO
 	if err != nil {
 		return nil, err
 	}
-	results, err := auditResources(resources, a.auditors)
+	results, err := auditResources(resources, a.auditors, a.concurrency, a.profile, a.strict)
 	if err != nil {
 		return nil, err
 	}
@@ -221,13 +414,206 @@ func (a *Kubeaudit) AuditLocal(configpath string, context string, options AuditO
 	return report, nil
 }
 
+// ContextMetadata is the metadata key of the kubeconfig context of the cluster an audit result was reported for, when
+// several clusters are audited with AuditLocalContexts()
+const ContextMetadata = "Context"
+
+// AuditLocalContexts audits the Kubernetes resources of the clusters of several contexts of the provided Kubernetes
+// config file, or of every context of the config if none are provided. The clusters are audited concurrently, and the
+// results are reported in the order of the contexts with the name of their context in their ContextMetadata. The audit
+// fails if any of the clusters can't be audited
+func (a *Kubeaudit) AuditLocalContexts(configpath string, contexts []string, options AuditOptions) (*Report, error) {
+	if len(contexts) == 0 {
+		var err error
+		contexts, err = k8sinternal.Contexts(configpath)
+		if err == k8sinternal.ErrNoReadableKubeConfig {
+			return nil, fmt.Errorf("failed to open kubeconfig file %s", configpath)
+		} else if err != nil {
+			return nil, err
+		}
+		if len(contexts) == 0 {
+			return nil, errors.New("failed to audit contexts: the kubeconfig has no contexts")
+		}
+	}
+
+	reports := make([]*Report, len(contexts))
+	errs := make([]error, len(contexts))
+	var wg sync.WaitGroup
+	for i, kubecontext := range contexts {
+		wg.Add(1)
+		go func(i int, kubecontext string) {
+			defer wg.Done()
+			reports[i], errs[i] = a.AuditLocal(configpath, kubecontext, options)
+		}(i, kubecontext)
+	}
+	wg.Wait()
+
+	var results []Result
+	for i, kubecontext := range contexts {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("failed to audit context %s: %w", kubecontext, errs[i])
+		}
+		for _, result := range reports[i].RawResults() {
+			for _, auditResult := range result.GetAuditResults() {
+				if auditResult.Metadata == nil {
+					auditResult.Metadata = Metadata{}
+				}
+				auditResult.Metadata[ContextMetadata] = kubecontext
+			}
+			results = append(results, result)
+		}
+	}
+
+	return NewReport(results), nil
+}
+
+// ClusterCache caches the Kubernetes resources of a cluster with informers, so the cluster can be audited repeatedly,
+// such as on an interval, with AuditClusterCache() without listing all of its resources each time. The informers
+// are started by the first audit and keep watching the cluster until the cache is closed with Close()
+type ClusterCache struct {
+	client k8sinternal.CachedKubeClient
+}
+
+// CacheStats are the number of audits of each resource type which were served from a warm cache (Hits) and which had
+// to wait for the cache to sync or list the resources from the API server (Misses)
+type CacheStats = k8sinternal.CacheStats
+
+// NewClusterCache returns a cache of the Kubernetes resources of the cluster in which Kubeaudit is running
+func NewClusterCache(options AuditOptions) (*ClusterCache, error) {
+	if !k8sinternal.IsRunningInCluster(k8sinternal.DefaultClient) {
+		return nil, errors.New("failed to cache resources in cluster mode: not running in cluster")
+	}
+
+	client, err := k8sinternal.NewCachedKubeClientCluster(k8sinternal.DefaultClient, watchConnection(options), k8sinternal.CacheOptions{WarmupTimeout: options.CacheWarmupTimeout})
+	if err != nil {
+		return nil, err
+	}
+	return &ClusterCache{client: client}, nil
+}
+
+// NewLocalClusterCache returns a cache of the Kubernetes resources found in the provided Kubernetes config file
+func NewLocalClusterCache(configpath string, kubecontext string, options AuditOptions) (*ClusterCache, error) {
+	client, err := k8sinternal.NewCachedKubeClientLocal(configpath, kubecontext, watchConnection(options), k8sinternal.CacheOptions{WarmupTimeout: options.CacheWarmupTimeout})
+	if err == k8sinternal.ErrNoReadableKubeConfig {
+		return nil, fmt.Errorf("failed to open kubeconfig file %s", configpath)
+	} else if err != nil {
+		return nil, err
+	}
+	return &ClusterCache{client: client}, nil
+}
+
+// Stats returns the number of cache hits and misses of the audits of the cache so far
+func (c *ClusterCache) Stats() CacheStats {
+	return c.client.Stats()
+}
+
+// Close stops the informers of the cache
+func (c *ClusterCache) Close() {
+	c.client.Stop()
+}
+
+// AuditClusterCache audits the Kubernetes resources of the cached cluster. Resource types which can't be watched are
+// listed from the API server on every audit
+func (a *Kubeaudit) AuditClusterCache(cache *ClusterCache, options AuditOptions) (*Report, error) {
+	resources, err := getResourcesFromClient(cache.client, options)
+	if err != nil {
+		return nil, err
+	}
+	results, err := auditResources(resources, a.auditors, a.concurrency, a.profile, a.strict)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewReport(results), nil
+}
+
+// WatchResyncPeriod is how often watched resources are handled again even if they have not changed
+const WatchResyncPeriod = 10 * time.Minute
+
+// WatchHandler is called with the key and the report of a single resource each time the resource is created or
+// updated, and when the informers resync. The report is nil when the resource is deleted. The key identifies the
+// resource in the form <resource>.<group>/<namespace>/<name>
+type WatchHandler func(key string, report *Report)
+
+// WatchCluster watches the Kubernetes resources in the cluster in which Kubeaudit is running and audits each workload
+// as it is created or updated. It blocks until the context is cancelled
+func (a *Kubeaudit) WatchCluster(ctx context.Context, options AuditOptions, handler WatchHandler) error {
+	if !k8sinternal.IsRunningInCluster(k8sinternal.DefaultClient) {
+		return errors.New("failed to watch resources in cluster mode: not running in cluster")
+	}
+
+	client, err := k8sinternal.NewCachedKubeClientCluster(k8sinternal.DefaultClient, watchConnection(options), k8sinternal.CacheOptions{ResyncPeriod: WatchResyncPeriod, WarmupTimeout: options.CacheWarmupTimeout})
+	if err != nil {
+		return err
+	}
+	defer client.Stop()
+
+	return a.watch(ctx, client, options, handler)
+}
+
+// WatchLocal watches the Kubernetes resources found in the provided Kubernetes config file and audits each workload
+// as it is created or updated. It blocks until the context is cancelled
+func (a *Kubeaudit) WatchLocal(ctx context.Context, configpath string, kubecontext string, options AuditOptions, handler WatchHandler) error {
+	client, err := k8sinternal.NewCachedKubeClientLocal(configpath, kubecontext, watchConnection(options), k8sinternal.CacheOptions{ResyncPeriod: WatchResyncPeriod, WarmupTimeout: options.CacheWarmupTimeout})
+	if err == k8sinternal.ErrNoReadableKubeConfig {
+		return fmt.Errorf("failed to open kubeconfig file %s", configpath)
+	} else if err != nil {
+		return err
+	}
+	defer client.Stop()
+
+	return a.watch(ctx, client, options, handler)
+}
+
+// watchConnection returns the connection options of the audit options for watching resources. The request timeout
+// doesn't apply since the informers keep their watch requests open
+func watchConnection(options AuditOptions) k8sinternal.ConnectionOptions {
+	connection := options.ConnectionOptions
+	connection.RequestTimeout = 0
+	return connection
+}
+
+func (a *Kubeaudit) watch(ctx context.Context, client k8sinternal.CachedKubeClient, options AuditOptions, handler WatchHandler) error {
+	// The context resources are only wrapped again when they change
+	var resources []KubeResource
+	var contextVersion uint64
+	err := client.Watch(ctx, options, func(event k8sinternal.WatchEvent) error {
+		if event.Resource == nil {
+			handler(event.Key, nil)
+			return nil
+		}
+
+		if resources == nil || event.ContextVersion != contextVersion {
+			resources = make([]KubeResource, 0, len(event.Resources))
+			for _, resource := range event.Resources {
+				resources = append(resources, &kubeResource{object: resource})
+			}
+			contextVersion = event.ContextVersion
+		}
+		result, err := auditResource(&kubeResource{object: event.Resource}, resources, a.auditors, a.profile, a.strict)
+		if err != nil {
+			return err
+		}
+
+		handler(event.Key, NewReport([]Result{result}))
+		return nil
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
 // Report contains the results after auditing
 type Report struct {
-	results []Result
+	results      []Result
+	suppressions map[SuppressionMechanism]int
 }
 
+// NewReport returns a report of the results. Audit results suppressed by a mechanism other than an override are left
+// out of the report and counted in its suppressions
 func NewReport(results []Result) *Report {
-	return &Report{results}
+	return newReport(results, map[SuppressionMechanism]int{})
 }
 
 // RawResults returns all of the results for each Kubernetes resource, including ones that had no audit results.
@@ -253,7 +639,7 @@ func (r *Report) ResultsWithMinSeverity(minSeverity SeverityLevel) []Result {
 	for _, result := range r.RawResults() {
 		var filteredAuditResults []*AuditResult
 		for _, auditResult := range result.GetAuditResults() {
-			if auditResult.Severity >= minSeverity {
+			if auditResult.Severity.AtLeast(minSeverity) {
 				filteredAuditResults = append(filteredAuditResults, auditResult)
 			}
 		}
@@ -269,9 +655,14 @@ func (r *Report) ResultsWithMinSeverity(minSeverity SeverityLevel) []Result {
 
 // HasErrors returns true if any findings have the level of Error
 func (r *Report) HasErrors() (errorsFound bool) {
+	return r.HasResultsWithMinSeverity(Error)
+}
+
+// HasResultsWithMinSeverity returns true if any findings have the specified severity level or a higher one
+func (r *Report) HasResultsWithMinSeverity(minSeverity SeverityLevel) bool {
 	for _, workloadResult := range r.Results() {
 		for _, auditResult := range workloadResult.GetAuditResults() {
-			if auditResult.Severity >= Error {
+			if auditResult.Severity.AtLeast(minSeverity) {
 				return true
 			}
 		}
@@ -310,6 +701,24 @@ func (r *Report) PrintPlan(writer io.Writer) {
 	}
 }
 
+// WritePatches writes the kubectl commands which apply the patches of the fixes which support them to the provided
+// writer. Unlike Fix(), it applies when audit was performed on a cluster (local or cluster mode). Fixes which do not
+// support patches are skipped
+func (r *Report) WritePatches(writer io.Writer) error {
+	for _, result := range r.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			patch := auditResult.FixPatch()
+			if patch == nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(writer, "# %s: %s\n%s\n", auditResult.Rule, auditResult.PendingFix.Plan(), patch.Command()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // Auditable is an interface which is implemented by auditors
 type Auditable interface {
 	Audit(resource k8s.Resource, resources []k8s.Resource) ([]*AuditResult, error)
@@ -1,13 +1,21 @@
 package kubeaudit_test
 
 import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
 	"github.com/Shopify/kubeaudit/config"
 	"github.com/Shopify/kubeaudit/internal/k8sinternal"
 	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -50,6 +58,30 @@ func TestAuditLocal(t *testing.T) {
 	require.NotNil(err)
 }
 
+func TestAuditLocalContexts(t *testing.T) {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+
+	_, err = auditor.AuditLocalContexts("invalid_path", nil, k8sinternal.ClientOptions{})
+	require.NotNil(t, err)
+
+	if !test.UseKind() {
+		return
+	}
+
+	_, err = auditor.AuditLocalContexts("", []string{"invalid_context"}, k8sinternal.ClientOptions{})
+	require.NotNil(t, err)
+
+	// Every context of the kubeconfig is audited if none are provided, and results are tagged with their context
+	report, err := auditor.AuditLocalContexts("", nil, k8sinternal.ClientOptions{})
+	require.NoError(t, err)
+	contexts, err := k8sinternal.Contexts("")
+	require.NoError(t, err)
+	for _, finding := range report.Findings() {
+		assert.Contains(t, contexts, finding.Context)
+	}
+}
+
 func TestAuditCluster(t *testing.T) {
 	require := require.New(t)
 
@@ -82,3 +114,297 @@ func TestUnknownResource(t *testing.T) {
 		})
 	}
 }
+
+func TestAuditResource(t *testing.T) {
+	require := require.New(t)
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(err)
+
+	// JSON containing a YAML document separator is audited as a single resource
+	resource := `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod","annotations":{"note":"---"}},"spec":{"containers":[{"name":"container","image":"scratch","securityContext":{"privileged":true}}]}}`
+	report, err := auditor.AuditResource([]byte(resource))
+	require.NoError(err)
+
+	results := report.Results()
+	require.Len(results, 1)
+	auditResults := results[0].GetAuditResults()
+	require.Len(auditResults, 1)
+	assert.Equal(t, privileged.PrivilegedTrue, auditResults[0].Rule)
+
+	// Resources of unknown kinds are not audited
+	report, err = auditor.AuditResource([]byte(`{"apiVersion":"example.com/v1","kind":"Unknown","metadata":{"name":"unknown"}}`))
+	require.NoError(err)
+	assert.Empty(t, report.Results())
+
+	_, err = auditor.AuditResource([]byte(`{"kind":`))
+	require.Error(err)
+}
+
+func TestHasResultsWithMinSeverity(t *testing.T) {
+	require := require.New(t)
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(err)
+
+	// privileged is not set, which is a warning
+	report, err := auditor.AuditResource([]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod"},"spec":{"containers":[{"name":"container","image":"scratch"}]}}`))
+	require.NoError(err)
+
+	assert.True(t, report.HasResultsWithMinSeverity(kubeaudit.Info))
+	assert.True(t, report.HasResultsWithMinSeverity(kubeaudit.Warn))
+	assert.False(t, report.HasResultsWithMinSeverity(kubeaudit.Error))
+	assert.False(t, report.HasErrors())
+}
+
+func TestAuditKustomize(t *testing.T) {
+	require := require.New(t)
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(err)
+
+	report, err := auditor.AuditKustomize("internal/test/fixtures/kustomize/overlay")
+	require.NoError(err)
+
+	results := report.Results()
+	require.Len(results, 1)
+	assert.Equal(t, "kustomize-overlay", results[0].GetResource().Object().(*k8s.DeploymentV1).Namespace)
+	for _, auditResult := range results[0].GetAuditResults() {
+		assert.Equal(t, privileged.PrivilegedNil, auditResult.Rule)
+		assert.Equal(t, "internal/test/fixtures/kustomize/base/deployment.yml", auditResult.FilePath)
+	}
+
+	_, err = auditor.AuditKustomize("internal/test/fixtures/kustomize/missing")
+	require.Error(err)
+}
+
+func TestAuditManifestSources(t *testing.T) {
+	require := require.New(t)
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(err)
+
+	pod := "apiVersion: v1\nkind: Pod\nmetadata:\n  name: %s\n%sspec:\n  containers:\n    - name: container\n      image: scratch\n"
+	origin := "  annotations:\n    config.kubernetes.io/origin: |\n      path: base/pod.yaml\n"
+	manifest := "---\n# Source: chart/templates/pods.yaml\n" + fmt.Sprintf(pod, "first", "") +
+		"---\n# Source: chart/templates/pods.yaml\n" + fmt.Sprintf(pod, "second", "") +
+		"---\n" + fmt.Sprintf(pod, "kustomize", origin) +
+		"---\n" + fmt.Sprintf(pod, "plain", "")
+
+	report, err := auditor.AuditManifest("stream.yaml", strings.NewReader(manifest))
+	require.NoError(err)
+
+	results := report.Results()
+	require.Len(results, 4)
+	// Results are located at their container, as the resources don't have a securityContext. The line is unknown for
+	// resources with a kustomize origin, as the line of the resource in its source is not recorded
+	for i, expected := range []struct {
+		filePath string
+		line     int
+		column   int
+	}{
+		{"chart/templates/pods.yaml", 7, 7},
+		{"chart/templates/pods.yaml", 16, 7},
+		{"base/pod.yaml", 0, 0},
+		{"stream.yaml", 40, 7},
+	} {
+		auditResult := results[i].GetAuditResults()[0]
+		assert.Equal(t, expected.filePath, auditResult.FilePath)
+		assert.Equal(t, expected.line, auditResult.Line)
+		assert.Equal(t, expected.column, auditResult.Column)
+	}
+
+	// The source comments are kept in the fixed manifest
+	fixed := bytes.NewBuffer(nil)
+	require.NoError(report.Fix(fixed))
+	assert.Equal(t, 2, strings.Count(fixed.String(), "# Source: chart/templates/pods.yaml\n"))
+}
+
+func TestAuditManifestFiles(t *testing.T) {
+	require := require.New(t)
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(err)
+
+	report, err := auditor.AuditManifestFiles([]string{
+		"auditors/privileged/fixtures/privileged-nil.yml",
+		"auditors/privileged/fixtures/privileged-true.yml",
+	})
+	require.NoError(err)
+
+	results := report.Results()
+	require.Len(results, 2)
+	assert.Equal(t, "auditors/privileged/fixtures/privileged-nil.yml", results[0].GetAuditResults()[0].FilePath)
+	assert.Equal(t, "auditors/privileged/fixtures/privileged-true.yml", results[1].GetAuditResults()[0].FilePath)
+
+	_, err = auditor.AuditManifestFiles([]string{"auditors/privileged/fixtures/missing.yml"})
+	require.Error(err)
+}
+
+func TestAuditManifestFilesWithContext(t *testing.T) {
+	require := require.New(t)
+
+	// The namespace and its default deny network policy are in separate files
+	dir := t.TempDir()
+	namespacePath, netpolPath := filepath.Join(dir, "namespace.yml"), filepath.Join(dir, "netpol.yml")
+	require.NoError(os.WriteFile(namespacePath, []byte("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: payments\n"), 0644))
+	require.NoError(os.WriteFile(netpolPath, []byte(`apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: default-deny
+  namespace: payments
+spec:
+  podSelector: {}
+  policyTypes:
+    - Ingress
+    - Egress
+`), 0644))
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{netpols.New()})
+	require.NoError(err)
+
+	// Without the network policy as context, the namespace has no default deny network policy
+	report, err := auditor.AuditManifestFilesWithContext([]string{namespacePath}, nil)
+	require.NoError(err)
+	assert.True(t, report.HasErrors())
+
+	// The network policy is seen by the auditor but not audited
+	report, err = auditor.AuditManifestFilesWithContext([]string{namespacePath}, []string{netpolPath})
+	require.NoError(err)
+	assert.False(t, report.HasErrors())
+	for _, result := range report.RawResults() {
+		assert.Equal(t, "Namespace", result.GetResource().Object().GetObjectKind().GroupVersionKind().Kind)
+	}
+}
+
+func TestAuditManifestSchemaErrors(t *testing.T) {
+	require := require.New(t)
+
+	auditor, err := kubeaudit.New(all.WithFields([]kubeaudit.Auditable{privileged.New()}))
+	require.NoError(err)
+
+	manifest := `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+spec:
+  containers:
+    - name: container
+      image: scratch
+      securityContext:
+        privileged: "true"
+        runAsNonRot: true
+---
+kind: [pod
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: other
+spec:
+  containers:
+    - name: container
+      image: scratch
+      securityContext:
+        privileged: true
+`
+	report, err := auditor.AuditManifest("manifest.yml", strings.NewReader(manifest))
+	require.NoError(err)
+
+	// Every problem of the manifest is reported, and the valid resources are still audited
+	type location struct {
+		rule string
+		line int
+	}
+	var locations []location
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			locations = append(locations, location{auditResult.Rule, auditResult.Line})
+		}
+	}
+	assert.Equal(t, []location{
+		{kubeaudit.ManifestInvalidType, 10},
+		{kubeaudit.ManifestUnknownField, 11},
+		{kubeaudit.ManifestSyntaxError, 13},
+		{privileged.PrivilegedTrue, 24},
+	}, locations)
+	assert.True(t, report.HasErrors())
+}
+
+func TestAuditManifestSchemaErrorsStrict(t *testing.T) {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()}, kubeaudit.WithStrict())
+	require.NoError(t, err)
+
+	for _, manifest := range []string{
+		"kind: [pod\n",
+		"apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod\nspec:\n  containers:\n    - name: container\n      image: scratch\n      imagePullPolcy: Always\n",
+	} {
+		_, err := auditor.AuditManifest("manifest.yml", strings.NewReader(manifest))
+		assert.Error(t, err, manifest)
+	}
+}
+
+func TestAuditCustomResource(t *testing.T) {
+	require := require.New(t)
+
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(err)
+
+	manifest, err := os.Open("internal/test/fixtures/custom_resource_rollout.yml")
+	require.NoError(err)
+	defer manifest.Close()
+
+	report, err := auditor.AuditManifest("", manifest)
+	require.NoError(err)
+
+	results := report.Results()
+	require.Len(results, 1)
+	assert.IsType(t, &k8s.CustomResource{}, results[0].GetResource().Object())
+	auditResults := results[0].GetAuditResults()
+	require.Len(auditResults, 1)
+	assert.Equal(t, privileged.PrivilegedTrue, auditResults[0].Rule)
+
+	var fixed bytes.Buffer
+	require.NoError(report.Fix(&fixed))
+	assert.Contains(t, fixed.String(), "privileged: false")
+	assert.Contains(t, fixed.String(), "setWeight: 20")
+}
+
+func TestAuditHelmChart(t *testing.T) {
+	require := require.New(t)
+
+	auditor, err := kubeaudit.New(all.WithFields([]kubeaudit.Auditable{privileged.New()}))
+	require.NoError(err)
+
+	cases := []struct {
+		valueFiles   []string
+		expectedRule string
+	}{
+		{nil, ""},
+		{[]string{"internal/test/fixtures/helm/values-privileged.yaml"}, privileged.PrivilegedTrue},
+	}
+
+	for _, tc := range cases {
+		report, err := auditor.AuditHelmChartWithNamespace("internal/test/fixtures/helm/chart", tc.valueFiles, "payments")
+		require.NoError(err)
+
+		results := report.Results()
+		if tc.expectedRule == "" {
+			assert.Empty(t, results)
+			continue
+		}
+
+		require.Len(results, 1)
+		auditResults := results[0].GetAuditResults()
+		require.Len(auditResults, 1)
+		assert.Equal(t, tc.expectedRule, auditResults[0].Rule)
+		assert.Equal(t, "internal/test/fixtures/helm/chart/templates/deployment.yaml", auditResults[0].FilePath)
+		assert.Equal(t, "payments", k8s.GetObjectMeta(results[0].GetResource().Object()).GetNamespace())
+		// The result is located at the privileged field of the template source, rather than of the rendered template
+		assert.Equal(t, 27, auditResults[0].Line)
+		assert.Equal(t, 13, auditResults[0].Column)
+	}
+
+	_, err = auditor.AuditHelmChart("internal/test/fixtures/helm/missing", nil)
+	require.Error(err)
+}
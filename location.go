@@ -0,0 +1,172 @@
+package kubeaudit
+
+import (
+	"bytes"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ContainerFieldPrefix is the prefix of the fields of audit results which are within the container of the result,
+// eg. "container.securityContext.privileged" for the privileged field of the container named by the Container metadata
+const ContainerFieldPrefix = "container."
+
+// containerListKeys are the keys of the lists of containers of a pod spec
+var containerListKeys = []string{"containers", "initContainers", "ephemeralContainers"}
+
+// setLocation sets the line and column of the audit results of a resource of a manifest to the position of the field
+// each audit result is about. The line is the line where the resource starts in the manifest, or 0 if it is unknown.
+// Audit results are positioned at the closest parent of their field which is set, such as the container when its
+// securityContext is missing, and at the start of the resource if they don't have a field. Audit results which already
+// have a line, such as the schema errors of the document, are positioned relative to the start of the document
+func setLocation(result Result, line int) {
+	if line <= 0 {
+		return
+	}
+
+	document := result.GetResource().Bytes()
+	root := parseDocument(document)
+	for _, auditResult := range result.GetAuditResults() {
+		if auditResult.Line > 0 {
+			auditResult.Line = line + auditResult.Line - 1 - startLineOffset(document)
+			continue
+		}
+		if root == nil {
+			auditResult.Line = line
+			continue
+		}
+		position := locateField(root, auditResult.Metadata["Container"], auditResult.Field)
+		auditResult.Line = line + position.Line - root.Line
+		auditResult.Column = position.Column
+	}
+}
+
+// setTemplateLocation sets the lines of the audit results of the result, with lines mapping each line of the resource,
+// starting at the line where the resource starts, to the line of the template the resource was rendered from
+func setTemplateLocation(result Result, lines []int) {
+	setLocation(result, 1)
+	for _, auditResult := range result.GetAuditResults() {
+		line := auditResult.Line
+		if line < 1 {
+			line = 1
+		}
+		if line > len(lines) {
+			line = len(lines)
+		}
+		auditResult.Line = lines[line-1]
+	}
+}
+
+// parseDocument returns the root mapping of a YAML or JSON document, or nil if the document is not a mapping
+func parseDocument(document []byte) *yaml.Node {
+	var node yaml.Node
+	if err := yaml.Unmarshal(document, &node); err != nil || len(node.Content) == 0 {
+		return nil
+	}
+	if root := node.Content[0]; root.Kind == yaml.MappingNode {
+		return root
+	}
+	return nil
+}
+
+// locateField returns the node of the closest parent of the field which is set, or the key of the field if it is set.
+// Fields with the ContainerFieldPrefix are within the container, and other fields are relative to the pod spec, or to
+// the resource if it does not have pods
+func locateField(root *yaml.Node, container, field string) *yaml.Node {
+	position := root
+	scope := root
+	if podSpec := findPodSpec(root); podSpec != nil {
+		scope = podSpec
+	}
+
+	if strings.HasPrefix(field, ContainerFieldPrefix) || (field == "" && container != "") {
+		containerNode := findContainer(scope, container)
+		if containerNode == nil {
+			return position
+		}
+		position = containerNode
+		scope = containerNode
+		field = strings.TrimPrefix(field, ContainerFieldPrefix)
+	} else if field != "" && scope != root {
+		position = scope
+	}
+
+	if field == "" {
+		return position
+	}
+	for _, element := range strings.Split(field, ".") {
+		key, value := getMappingValue(scope, element)
+		if key == nil {
+			break
+		}
+		position = key
+		scope = value
+	}
+	return position
+}
+
+// findPodSpec returns the first mapping with a list of containers, which is the pod spec of workloads and pods
+func findPodSpec(node *yaml.Node) *yaml.Node {
+	switch node.Kind {
+	case yaml.MappingNode:
+		if _, value := getMappingValue(node, "containers"); value != nil && value.Kind == yaml.SequenceNode {
+			return node
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if podSpec := findPodSpec(node.Content[i]); podSpec != nil {
+				return podSpec
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if podSpec := findPodSpec(item); podSpec != nil {
+				return podSpec
+			}
+		}
+	}
+	return nil
+}
+
+// findContainer returns the container, init container or ephemeral container of the pod spec with the name
+func findContainer(podSpec *yaml.Node, name string) *yaml.Node {
+	for _, listKey := range containerListKeys {
+		_, list := getMappingValue(podSpec, listKey)
+		if list == nil || list.Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range list.Content {
+			if _, containerName := getMappingValue(item, "name"); containerName != nil && containerName.Value == name {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// getMappingValue returns the key and value nodes of the key of a mapping, or nil if the node is not a mapping or does
+// not have the key
+func getMappingValue(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// startLineOffset returns the number of lines of the document before the line where the resource starts, which are
+// blank lines and comments
+func startLineOffset(document []byte) int {
+	offset := 0
+	for _, line := range bytes.Split(document, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		if len(trimmed) > 0 && trimmed[0] != '#' {
+			return offset
+		}
+		offset++
+	}
+	return 0
+}
@@ -0,0 +1,69 @@
+package kubeaudit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocateField(t *testing.T) {
+	deployment := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment
+spec:
+  template:
+    spec:
+      hostNetwork: true
+      initContainers:
+        - name: init
+          image: scratch
+      containers:
+        - name: container
+          image: scratch
+          securityContext:
+            privileged: true
+`
+	namespace := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: namespace
+`
+
+	cases := []struct {
+		testName       string
+		document       string
+		container      string
+		field          string
+		expectedLine   int
+		expectedColumn int
+	}{
+		{"Pod spec field", deployment, "", "hostNetwork", 8, 7},
+		{"Container field", deployment, "container", ContainerFieldPrefix + "securityContext.privileged", 16, 13},
+		{"Missing container field", deployment, "init", ContainerFieldPrefix + "securityContext.privileged", 10, 11},
+		{"Container without field", deployment, "container", "", 13, 11},
+		{"Missing container", deployment, "missing", ContainerFieldPrefix + "image", 1, 1},
+		{"Missing pod spec field", deployment, "", "hostPID", 8, 7},
+		{"Resource without field", deployment, "", "", 1, 1},
+		{"Resource without pods", namespace, "", "metadata.name", 4, 3},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			root := parseDocument([]byte(tc.document))
+			require.NotNil(t, root)
+			position := locateField(root, tc.container, tc.field)
+			assert.Equal(t, tc.expectedLine, position.Line)
+			assert.Equal(t, tc.expectedColumn, position.Column)
+		})
+	}
+
+	assert.Nil(t, parseDocument([]byte("- item\n")))
+}
+
+func TestStartLineOffset(t *testing.T) {
+	assert.Equal(t, 0, startLineOffset([]byte("kind: Pod\n")))
+	assert.Equal(t, 3, startLineOffset([]byte("# comment\n\n  # indented comment\nkind: Pod\n")))
+	assert.Equal(t, 0, startLineOffset([]byte("# only a comment\n")))
+}
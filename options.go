@@ -1,6 +1,9 @@
 package kubeaudit
 
 import (
+	"errors"
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -15,6 +18,43 @@ func WithLogger(formatter log.Formatter) Option {
 	}
 }
 
+// WithConcurrency sets the number of resources audited at the same time, which defaults to 1. The auditors of a
+// resource always run concurrently, with a concurrency greater than 1 the same auditor also audits different resources
+// at the same time, so custom auditors must be safe for concurrent use. Results are reported in the same order regardless
+// of the concurrency
+func WithConcurrency(concurrency int) Option {
+	return func(a *Kubeaudit) error {
+		if concurrency < 1 {
+			return fmt.Errorf("invalid concurrency %d, it must be at least 1", concurrency)
+		}
+		a.concurrency = concurrency
+		return nil
+	}
+}
+
+// WithProfile records how long each auditor takes per resource and overall in the profile, which is created with
+// NewProfile(). The auditors run with the "auditor" pprof label while profiling, so CPU profiles taken with the
+// runtime/pprof package can be broken down by auditor
+func WithProfile(profile *Profile) Option {
+	return func(a *Kubeaudit) error {
+		if profile == nil {
+			return errors.New("profile is nil, create it with NewProfile()")
+		}
+		a.profile = profile
+		return nil
+	}
+}
+
+// WithStrict fails audits on the first error returned by an auditor, or on the first manifest document which is not
+// valid YAML or not a valid resource. By default, they are reported as AuditorError and Manifest results of the
+// resource and the remaining auditors and resources are still audited
+func WithStrict() Option {
+	return func(a *Kubeaudit) error {
+		a.strict = true
+		return nil
+	}
+}
+
 func (a *Kubeaudit) parseOptions(opts []Option) error {
 	for _, opt := range opts {
 		if err := opt(a); err != nil {
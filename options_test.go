@@ -29,3 +29,25 @@ func TestWithLogger(t *testing.T) {
 	assert.NoError(err)
 	assert.Equal(formatter, logrus.StandardLogger().Formatter)
 }
+
+func TestWithConcurrency(t *testing.T) {
+	allAuditors, err := all.Auditors(config.KubeauditConfig{})
+	require.NoError(t, err)
+
+	_, err = kubeaudit.New(allAuditors, kubeaudit.WithConcurrency(4))
+	assert.NoError(t, err)
+
+	_, err = kubeaudit.New(allAuditors, kubeaudit.WithConcurrency(0))
+	assert.EqualError(t, err, "invalid concurrency 0, it must be at least 1")
+}
+
+func TestWithProfile(t *testing.T) {
+	allAuditors, err := all.Auditors(config.KubeauditConfig{})
+	require.NoError(t, err)
+
+	_, err = kubeaudit.New(allAuditors, kubeaudit.WithProfile(kubeaudit.NewProfile()))
+	assert.NoError(t, err)
+
+	_, err = kubeaudit.New(allAuditors, kubeaudit.WithProfile(nil))
+	assert.EqualError(t, err, "profile is nil, create it with NewProfile()")
+}
@@ -0,0 +1,187 @@
+package kubeaudit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OverriddenRuleSuffix ends the rule of audit results which are overridden, such as "PrivilegedTrueAllowed"
+const OverriddenRuleSuffix = "Allowed"
+
+// OverrideInheritedFromMetadata is the metadata key of the owner, as kind/namespace/name, whose override an audit
+// result of a generated resource inherited
+const OverrideInheritedFromMetadata = "OverrideInheritedFrom"
+
+// ExpiredOverride is an override label or annotation whose expiry date has passed, so it no longer overrides results
+type ExpiredOverride struct {
+	// Label is the key of the label or annotation, such as "kubeaudit.io/allow-privileged"
+	Label string
+	// Container is the name of the container of container overrides, or empty for pod overrides
+	Container string
+	// Expiry is the date the override expired on, as YYYY-MM-DD
+	Expiry string
+	// Reason is the reason given for the override after its expiry date, if any, such as a ticket
+	Reason string
+}
+
+// NewExpiredOverrideResult returns an audit result at warning level telling the user that the override of the
+// auditor expired, so the results it overrode are reported again
+func NewExpiredOverrideResult(auditorName string, override ExpiredOverride) *AuditResult {
+	metadata := Metadata{
+		"OverrideLabel":  override.Label,
+		"OverrideExpiry": override.Expiry,
+	}
+	if override.Container != "" {
+		metadata["Container"] = override.Container
+	}
+	if override.Reason != "" {
+		metadata["OverrideReason"] = override.Reason
+	}
+
+	return &AuditResult{
+		Auditor:  auditorName,
+		Rule:     ExpiredAuditorOverride,
+		Severity: Warn,
+		Message:  fmt.Sprintf("Override %s expired on %s so it is ignored. The exception should be renewed with a new expiry date, or the override removed.", override.Label, override.Expiry),
+		Metadata: metadata,
+	}
+}
+
+// appendExpiredOverrideResults adds an ExpiredAuditorOverride result for each expired override of the audit results,
+// so exceptions which are no longer valid are noticed. Each expired override is only reported once per auditor, even
+// if it expired for several results or the auditor reported it itself
+func appendExpiredOverrideResults(auditResults []*AuditResult) []*AuditResult {
+	type key struct{ auditor, label string }
+	seen := map[key]bool{}
+
+	deduplicated := make([]*AuditResult, 0, len(auditResults))
+	for _, auditResult := range auditResults {
+		if auditResult.Rule == ExpiredAuditorOverride {
+			k := key{auditResult.Auditor, auditResult.Metadata["OverrideLabel"]}
+			if seen[k] {
+				continue
+			}
+			seen[k] = true
+		}
+		deduplicated = append(deduplicated, auditResult)
+	}
+
+	for _, auditResult := range auditResults {
+		if auditResult.ExpiredOverride == nil {
+			continue
+		}
+		k := key{auditResult.Auditor, auditResult.ExpiredOverride.Label}
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		deduplicated = append(deduplicated, NewExpiredOverrideResult(auditResult.Auditor, *auditResult.ExpiredOverride))
+	}
+	return deduplicated
+}
+
+// inheritOverrides overrides the audit results of generated resources, such as the ReplicaSets and Pods of a
+// Deployment, when the same audit result of one of their owners is overridden, so overrides don't have to be set on
+// every layer of generated resources. Owners are found among the audited resources by the UID of the controller
+// owner reference, up to the top-level workload, so overrides are only inherited from owners audited along with the
+// generated resources
+func inheritOverrides(results []Result) {
+	byUID := map[string]Result{}
+	for _, result := range results {
+		if objectMeta := getResultObjectMeta(result); objectMeta != nil && objectMeta.GetUID() != "" {
+			byUID[string(objectMeta.GetUID())] = result
+		}
+	}
+	if len(byUID) == 0 {
+		return
+	}
+
+	for _, result := range results {
+		for _, auditResult := range result.GetAuditResults() {
+			if auditResult.SuppressedBy != "" {
+				continue
+			}
+			visited := map[string]bool{}
+			for owner := getOwner(result, byUID); owner != nil && !visited[getResourceKey(owner)]; owner = getOwner(owner, byUID) {
+				visited[getResourceKey(owner)] = true
+				if overridden := findOverridden(owner, auditResult); overridden != nil {
+					inheritOverride(auditResult, overridden, getResourceKey(owner))
+					break
+				}
+			}
+		}
+	}
+}
+
+// getResultObjectMeta returns the metadata of the resource of the result, or nil if it has none
+func getResultObjectMeta(result Result) metav1.Object {
+	if result.GetResource() == nil || result.GetResource().Object() == nil {
+		return nil
+	}
+	return k8s.GetObjectMeta(result.GetResource().Object())
+}
+
+// getOwner returns the result of the controller of the resource of the result, or nil if it has none or the
+// controller wasn't audited
+func getOwner(result Result, byUID map[string]Result) Result {
+	objectMeta := getResultObjectMeta(result)
+	if objectMeta == nil {
+		return nil
+	}
+	for _, ownerReference := range objectMeta.GetOwnerReferences() {
+		if ownerReference.Controller != nil && *ownerReference.Controller {
+			return byUID[string(ownerReference.UID)]
+		}
+	}
+	return nil
+}
+
+// getResourceKey returns the identity of the resource of the result in the form kind/namespace/name
+func getResourceKey(result Result) string {
+	parts := []string{result.GetResource().Object().GetObjectKind().GroupVersionKind().Kind}
+	if objectMeta := getResultObjectMeta(result); objectMeta != nil {
+		if objectMeta.GetNamespace() != "" {
+			parts = append(parts, objectMeta.GetNamespace())
+		}
+		parts = append(parts, objectMeta.GetName())
+	}
+	return strings.Join(parts, "/")
+}
+
+// findOverridden returns the audit result of the owner which is the overridden form of the audit result, for the same
+// auditor and container, or nil if there is none
+func findOverridden(owner Result, auditResult *AuditResult) *AuditResult {
+	for _, ownerResult := range owner.GetAuditResults() {
+		if ownerResult.SuppressedBy == SuppressedByOverride &&
+			ownerResult.Auditor == auditResult.Auditor &&
+			ownerResult.Rule == auditResult.Rule+OverriddenRuleSuffix &&
+			ownerResult.Metadata["Container"] == auditResult.Metadata["Container"] {
+			return ownerResult
+		}
+	}
+	return nil
+}
+
+// inheritOverride overrides the audit result like its overridden counterpart of the owner, recording the owner it
+// inherited the override from in its metadata
+func inheritOverride(auditResult, overridden *AuditResult, owner string) {
+	auditResult.Rule = overridden.Rule
+	auditResult.PendingFix = nil
+	auditResult.Severity = overridden.Severity
+	auditResult.SuppressedBy = SuppressedByOverride
+	auditResult.OverrideLabel = overridden.OverrideLabel
+	auditResult.Message = "Audit result overridden: " + auditResult.Message
+
+	if auditResult.Metadata == nil {
+		auditResult.Metadata = Metadata{}
+	}
+	for _, key := range []string{"OverrideReason", "OverrideExpiry"} {
+		if value := overridden.Metadata[key]; value != "" {
+			auditResult.Metadata[key] = value
+		}
+	}
+	auditResult.Metadata[OverrideInheritedFromMetadata] = owner
+}
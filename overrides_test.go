@@ -0,0 +1,149 @@
+package kubeaudit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpiredOverrides(t *testing.T) {
+	// The expired override is reported once per auditor, even if it expired for several of its results
+	report := test.GetReport(t, privilegedFixtureDir, "privileged-true-allowed-expired.yml",
+		[]kubeaudit.Auditable{privileged.New(), privileged.New()}, "", test.MANIFEST_MODE)
+
+	var expired []*kubeaudit.AuditResult
+	for _, auditResult := range report.Results()[0].GetAuditResults() {
+		if auditResult.Rule == kubeaudit.ExpiredAuditorOverride {
+			expired = append(expired, auditResult)
+			continue
+		}
+		assert.Equal(t, privileged.PrivilegedTrue, auditResult.Rule)
+	}
+	require.Len(t, expired, 1)
+	assert.Equal(t, kubeaudit.Warn, expired[0].Severity)
+	assert.Equal(t, privileged.Name, expired[0].Auditor)
+	assert.Equal(t, "Override kubeaudit.io/allow-privileged expired on 2020-01-31 so it is ignored. The exception should be renewed with a new expiry date, or the override removed.", expired[0].Message)
+	assert.Equal(t, kubeaudit.Metadata{
+		"OverrideLabel":  "kubeaudit.io/allow-privileged",
+		"OverrideExpiry": "2020-01-31",
+		"OverrideReason": "JIRA-1234",
+	}, expired[0].Metadata)
+	assert.Empty(t, report.Overrides())
+}
+
+const generatedManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: deployment
+  namespace: default
+  uid: 5f0c3b9e-0000-0000-0000-000000000001
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+        kubeaudit.io/allow-privileged: JIRA-1234
+    spec:
+      containers:
+        - name: container
+          image: scratch:1.0
+          securityContext:
+            privileged: true
+---
+apiVersion: apps/v1
+kind: ReplicaSet
+metadata:
+  name: deployment-1234
+  namespace: default
+  uid: 5f0c3b9e-0000-0000-0000-000000000002
+  ownerReferences:
+    - apiVersion: apps/v1
+      kind: Deployment
+      name: deployment
+      uid: 5f0c3b9e-0000-0000-0000-000000000001
+      controller: true
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    metadata:
+      labels:
+        app: app
+    spec:
+      containers:
+        - name: container
+          image: scratch:1.0
+          securityContext:
+            privileged: true
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: deployment-1234-abcde
+  namespace: default
+  uid: 5f0c3b9e-0000-0000-0000-000000000003
+  ownerReferences:
+    - apiVersion: apps/v1
+      kind: ReplicaSet
+      name: deployment-1234
+      uid: 5f0c3b9e-0000-0000-0000-000000000002
+      controller: true
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+      securityContext:
+        privileged: true
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: standalone
+  namespace: default
+  uid: 5f0c3b9e-0000-0000-0000-000000000004
+spec:
+  containers:
+    - name: container
+      image: scratch:1.0
+      securityContext:
+        privileged: true
+`
+
+func TestInheritedOverrides(t *testing.T) {
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{privileged.New()})
+	require.NoError(t, err)
+	report, err := auditor.AuditManifest("", strings.NewReader(generatedManifest))
+	require.NoError(t, err)
+
+	results := report.RawResults()
+	require.Len(t, results, 4)
+
+	// The Pod inherits the override of the Deployment through its ReplicaSet, which the auditor doesn't audit
+	assert.Empty(t, results[1].GetAuditResults())
+	for i, inheritedFrom := range map[int]string{0: "", 2: "Deployment/default/deployment"} {
+		auditResults := results[i].GetAuditResults()
+		require.Len(t, auditResults, 1)
+		assert.Equal(t, privileged.PrivilegedTrue+kubeaudit.OverriddenRuleSuffix, auditResults[0].Rule)
+		assert.Equal(t, kubeaudit.Info, auditResults[0].Severity)
+		assert.Equal(t, kubeaudit.SuppressedByOverride, auditResults[0].SuppressedBy)
+		assert.Equal(t, "kubeaudit.io/allow-privileged", auditResults[0].OverrideLabel)
+		assert.Equal(t, "JIRA-1234", auditResults[0].Metadata["OverrideReason"])
+		assert.Equal(t, inheritedFrom, auditResults[0].Metadata[kubeaudit.OverrideInheritedFromMetadata])
+		assert.Nil(t, auditResults[0].PendingFix)
+	}
+
+	// Resources without an overridden owner are not overridden
+	auditResults := results[3].GetAuditResults()
+	require.Len(t, auditResults, 1)
+	assert.Equal(t, privileged.PrivilegedTrue, auditResults[0].Rule)
+	assert.Empty(t, auditResults[0].SuppressedBy)
+}
@@ -0,0 +1,175 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.4
+// source: pkg/api/v1/kubeaudit.proto
+
+package apiv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// KubeauditClient is the client API for Kubeaudit service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type KubeauditClient interface {
+	// AuditManifest audits the resources of a manifest and returns the findings.
+	AuditManifest(ctx context.Context, in *AuditManifestRequest, opts ...grpc.CallOption) (*AuditManifestResponse, error)
+	// WatchFindings streams the findings of the periodic audits of the cluster which were not found by the previous
+	// audit. The stream stays open until the client cancels it or the server shuts down.
+	WatchFindings(ctx context.Context, in *WatchFindingsRequest, opts ...grpc.CallOption) (Kubeaudit_WatchFindingsClient, error)
+}
+
+type kubeauditClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewKubeauditClient(cc grpc.ClientConnInterface) KubeauditClient {
+	return &kubeauditClient{cc}
+}
+
+func (c *kubeauditClient) AuditManifest(ctx context.Context, in *AuditManifestRequest, opts ...grpc.CallOption) (*AuditManifestResponse, error) {
+	out := new(AuditManifestResponse)
+	err := c.cc.Invoke(ctx, "/kubeaudit.v1.Kubeaudit/AuditManifest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *kubeauditClient) WatchFindings(ctx context.Context, in *WatchFindingsRequest, opts ...grpc.CallOption) (Kubeaudit_WatchFindingsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Kubeaudit_ServiceDesc.Streams[0], "/kubeaudit.v1.Kubeaudit/WatchFindings", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &kubeauditWatchFindingsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Kubeaudit_WatchFindingsClient interface {
+	Recv() (*Finding, error)
+	grpc.ClientStream
+}
+
+type kubeauditWatchFindingsClient struct {
+	grpc.ClientStream
+}
+
+func (x *kubeauditWatchFindingsClient) Recv() (*Finding, error) {
+	m := new(Finding)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// KubeauditServer is the server API for Kubeaudit service.
+// All implementations must embed UnimplementedKubeauditServer
+// for forward compatibility
+type KubeauditServer interface {
+	// AuditManifest audits the resources of a manifest and returns the findings.
+	AuditManifest(context.Context, *AuditManifestRequest) (*AuditManifestResponse, error)
+	// WatchFindings streams the findings of the periodic audits of the cluster which were not found by the previous
+	// audit. The stream stays open until the client cancels it or the server shuts down.
+	WatchFindings(*WatchFindingsRequest, Kubeaudit_WatchFindingsServer) error
+	mustEmbedUnimplementedKubeauditServer()
+}
+
+// UnimplementedKubeauditServer must be embedded to have forward compatible implementations.
+type UnimplementedKubeauditServer struct {
+}
+
+func (UnimplementedKubeauditServer) AuditManifest(context.Context, *AuditManifestRequest) (*AuditManifestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AuditManifest not implemented")
+}
+func (UnimplementedKubeauditServer) WatchFindings(*WatchFindingsRequest, Kubeaudit_WatchFindingsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchFindings not implemented")
+}
+func (UnimplementedKubeauditServer) mustEmbedUnimplementedKubeauditServer() {}
+
+// UnsafeKubeauditServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to KubeauditServer will
+// result in compilation errors.
+type UnsafeKubeauditServer interface {
+	mustEmbedUnimplementedKubeauditServer()
+}
+
+func RegisterKubeauditServer(s grpc.ServiceRegistrar, srv KubeauditServer) {
+	s.RegisterService(&Kubeaudit_ServiceDesc, srv)
+}
+
+func _Kubeaudit_AuditManifest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditManifestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(KubeauditServer).AuditManifest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/kubeaudit.v1.Kubeaudit/AuditManifest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(KubeauditServer).AuditManifest(ctx, req.(*AuditManifestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Kubeaudit_WatchFindings_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchFindingsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(KubeauditServer).WatchFindings(m, &kubeauditWatchFindingsServer{stream})
+}
+
+type Kubeaudit_WatchFindingsServer interface {
+	Send(*Finding) error
+	grpc.ServerStream
+}
+
+type kubeauditWatchFindingsServer struct {
+	grpc.ServerStream
+}
+
+func (x *kubeauditWatchFindingsServer) Send(m *Finding) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Kubeaudit_ServiceDesc is the grpc.ServiceDesc for Kubeaudit service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Kubeaudit_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "kubeaudit.v1.Kubeaudit",
+	HandlerType: (*KubeauditServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AuditManifest",
+			Handler:    _Kubeaudit_AuditManifest_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchFindings",
+			Handler:       _Kubeaudit_WatchFindings_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "pkg/api/v1/kubeaudit.proto",
+}
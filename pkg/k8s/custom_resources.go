@@ -0,0 +1,271 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ErrInvalidPodSpecExtractor is returned when a PodSpec extractor cannot be parsed or registered
+var ErrInvalidPodSpecExtractor = errors.New("invalid custom resource")
+
+// PodSpecExtractor declares where the PodSpec is embedded in a custom resource kind so that custom resources of that
+// kind can be audited like the built-in workload types. All versions of the kind are matched.
+type PodSpecExtractor struct {
+	// Group is the API group of the custom resource, eg. "argoproj.io"
+	Group string `yaml:"group"`
+	// Kind is the kind of the custom resource, eg. "Rollout"
+	Kind string `yaml:"kind"`
+	// PodSpecPath is the JSONPath of the PodSpec, eg. ".spec.template.spec". Only field paths are supported. If the
+	// last field is "spec" the parent field is treated as a PodTemplateSpec and its metadata is used as the pod
+	// metadata
+	PodSpecPath string `yaml:"podSpecPath"`
+}
+
+// DefaultPodSpecExtractors are the custom resource kinds which are registered by default
+var DefaultPodSpecExtractors = []PodSpecExtractor{
+	{Group: "argoproj.io", Kind: "Rollout", PodSpecPath: ".spec.template.spec"},
+	{Group: "apps.kruise.io", Kind: "CloneSet", PodSpecPath: ".spec.template.spec"},
+}
+
+var podSpecExtractors = struct {
+	sync.RWMutex
+	paths map[schema.GroupKind][]string
+}{paths: map[schema.GroupKind][]string{}}
+
+func init() {
+	for _, extractor := range DefaultPodSpecExtractors {
+		if err := RegisterPodSpecExtractor(extractor); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ParsePodSpecExtractor parses a PodSpec extractor of the form "<kind>.<group>=<path>", eg.
+// "Rollout.argoproj.io=.spec.template.spec"
+func ParsePodSpecExtractor(value string) (PodSpecExtractor, error) {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return PodSpecExtractor{}, fmt.Errorf("%w %q: expected <kind>.<group>=<path>", ErrInvalidPodSpecExtractor, value)
+	}
+
+	groupKind := schema.ParseGroupKind(parts[0])
+	return PodSpecExtractor{Group: groupKind.Group, Kind: groupKind.Kind, PodSpecPath: parts[1]}, nil
+}
+
+// RegisterPodSpecExtractor registers a custom resource kind which embeds a PodSpec. Registering a kind which is
+// already registered replaces its PodSpec path
+func RegisterPodSpecExtractor(extractor PodSpecExtractor) error {
+	if extractor.Kind == "" {
+		return fmt.Errorf("%w: kind is required", ErrInvalidPodSpecExtractor)
+	}
+
+	path, err := parsePodSpecPath(extractor.PodSpecPath)
+	if err != nil {
+		return err
+	}
+
+	podSpecExtractors.Lock()
+	defer podSpecExtractors.Unlock()
+	podSpecExtractors.paths[schema.GroupKind{Group: extractor.Group, Kind: extractor.Kind}] = path
+	return nil
+}
+
+// parsePodSpecPath splits a JSONPath field path such as "{.spec.template.spec}" into its fields
+func parsePodSpecPath(podSpecPath string) ([]string, error) {
+	trimmed := strings.TrimSpace(podSpecPath)
+	trimmed = strings.TrimSuffix(strings.TrimPrefix(trimmed, "{"), "}")
+	trimmed = strings.TrimPrefix(strings.TrimPrefix(trimmed, "$"), ".")
+	if trimmed == "" || strings.ContainsAny(trimmed, "[]*@?()'\" ") {
+		return nil, fmt.Errorf("%w: unsupported PodSpec path %q, only field paths such as .spec.template.spec are supported", ErrInvalidPodSpecExtractor, podSpecPath)
+	}
+
+	fields := strings.Split(trimmed, ".")
+	for _, field := range fields {
+		if field == "" {
+			return nil, fmt.Errorf("%w: unsupported PodSpec path %q, only field paths such as .spec.template.spec are supported", ErrInvalidPodSpecExtractor, podSpecPath)
+		}
+	}
+	return fields, nil
+}
+
+func getPodSpecPath(groupKind schema.GroupKind) ([]string, bool) {
+	podSpecExtractors.RLock()
+	defer podSpecExtractors.RUnlock()
+	path, ok := podSpecExtractors.paths[groupKind]
+	return path, ok
+}
+
+// CustomResource is a custom resource of a kind registered with RegisterPodSpecExtractor. The embedded PodSpec is
+// decoded so that it can be audited and fixed like the PodSpec of a built-in workload type
+type CustomResource struct {
+	unstructured.Unstructured
+
+	// podTemplate is the decoded pod template. It is nil if the resource does not have a PodSpec at the path
+	podTemplate *PodTemplateSpecV1
+	// original is a copy of podTemplate as it was decoded, used to tell which fields are unknown to the PodSpec type
+	original *PodTemplateSpecV1
+	path     []string
+	// hasTemplate is true if the PodSpec is the spec of a PodTemplateSpec
+	hasTemplate bool
+}
+
+// NewCustomResource decodes the PodSpec embedded in an unstructured resource. The returned boolean is false if the
+// kind of the resource is not registered with RegisterPodSpecExtractor
+func NewCustomResource(obj *unstructured.Unstructured) (*CustomResource, bool, error) {
+	path, ok := getPodSpecPath(obj.GroupVersionKind().GroupKind())
+	if !ok {
+		return nil, false, nil
+	}
+
+	resource := &CustomResource{
+		Unstructured: *obj,
+		path:         path,
+		hasTemplate:  len(path) > 1 && path[len(path)-1] == "spec",
+	}
+
+	templatePath := path
+	if resource.hasTemplate {
+		templatePath = path[:len(path)-1]
+	}
+
+	raw, found, err := unstructured.NestedMap(obj.Object, templatePath...)
+	if err != nil {
+		return nil, true, fmt.Errorf("error decoding the PodSpec of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	if !found {
+		return resource, true, nil
+	}
+
+	podTemplate := &PodTemplateSpecV1{}
+	if resource.hasTemplate {
+		err = k8sRuntime.DefaultUnstructuredConverter.FromUnstructured(raw, podTemplate)
+	} else {
+		err = k8sRuntime.DefaultUnstructuredConverter.FromUnstructured(raw, &podTemplate.Spec)
+	}
+	if err != nil {
+		return nil, true, fmt.Errorf("error decoding the PodSpec of %s %s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+
+	resource.podTemplate = podTemplate
+	resource.original = podTemplate.DeepCopy()
+	return resource, true, nil
+}
+
+// GetObjectMeta implements metav1.ObjectMetaAccessor
+func (c *CustomResource) GetObjectMeta() metav1.Object {
+	return &c.Unstructured
+}
+
+// DeepCopyObject implements runtime.Object
+func (c *CustomResource) DeepCopyObject() k8sRuntime.Object {
+	resource := &CustomResource{
+		Unstructured: *c.Unstructured.DeepCopy(),
+		path:         append([]string(nil), c.path...),
+		hasTemplate:  c.hasTemplate,
+	}
+	if c.podTemplate != nil {
+		resource.podTemplate = c.podTemplate.DeepCopy()
+		resource.original = c.original.DeepCopy()
+	}
+	return resource
+}
+
+// podTemplateSpec returns the pod template if the PodSpec is embedded in a PodTemplateSpec
+func (c *CustomResource) podTemplateSpec() *PodTemplateSpecV1 {
+	if !c.hasTemplate {
+		return nil
+	}
+	return c.podTemplate
+}
+
+func (c *CustomResource) podSpec() *PodSpecV1 {
+	if c.podTemplate == nil {
+		return nil
+	}
+	return &c.podTemplate.Spec
+}
+
+// SyncPodSpec writes the decoded PodSpec, including any changes made by fixes, back into the unstructured content.
+// Fields which are not known to the PodSpec type are preserved
+func (c *CustomResource) SyncPodSpec() error {
+	if c.podTemplate == nil {
+		return nil
+	}
+
+	var fixed, known map[string]interface{}
+	var err error
+	templatePath := c.path
+	if c.hasTemplate {
+		templatePath = c.path[:len(c.path)-1]
+		fixed, err = k8sRuntime.DefaultUnstructuredConverter.ToUnstructured(c.podTemplate)
+		if err == nil {
+			known, err = k8sRuntime.DefaultUnstructuredConverter.ToUnstructured(c.original)
+		}
+	} else {
+		fixed, err = k8sRuntime.DefaultUnstructuredConverter.ToUnstructured(&c.podTemplate.Spec)
+		if err == nil {
+			known, err = k8sRuntime.DefaultUnstructuredConverter.ToUnstructured(&c.original.Spec)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	orig, _, err := unstructured.NestedMap(c.Object, templatePath...)
+	if err != nil {
+		return err
+	}
+	preserveUnknownFields(fixed, orig, known)
+
+	return unstructured.SetNestedMap(c.Object, fixed, templatePath...)
+}
+
+// preserveUnknownFields copies the fields of orig which were dropped when decoding it, ie. the fields missing from
+// known, into fixed
+func preserveUnknownFields(fixed, orig, known map[string]interface{}) {
+	for key, origValue := range orig {
+		knownValue, isKnown := known[key]
+		if !isKnown {
+			fixed[key] = origValue
+			continue
+		}
+
+		fixedValue, ok := fixed[key]
+		if !ok {
+			continue
+		}
+
+		switch origValue := origValue.(type) {
+		case map[string]interface{}:
+			fixedMap, fixedOk := fixedValue.(map[string]interface{})
+			knownMap, knownOk := knownValue.(map[string]interface{})
+			if fixedOk && knownOk {
+				preserveUnknownFields(fixedMap, origValue, knownMap)
+			}
+		case []interface{}:
+			fixedList, fixedOk := fixedValue.([]interface{})
+			knownList, knownOk := knownValue.([]interface{})
+			if !fixedOk || !knownOk {
+				continue
+			}
+			for i := range origValue {
+				if i >= len(fixedList) || i >= len(knownList) {
+					break
+				}
+				origItem, origOk := origValue[i].(map[string]interface{})
+				fixedItem, fixedOk := fixedList[i].(map[string]interface{})
+				knownItem, knownOk := knownList[i].(map[string]interface{})
+				if origOk && fixedOk && knownOk {
+					preserveUnknownFields(fixedItem, origItem, knownItem)
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package k8s
 
 import (
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -29,6 +32,9 @@ func GetContainers(resource Resource) []*ContainerV1 {
 	if len(podSpec.InitContainers) > 0 {
 		containers = append(containers, GetInitContainers(resource)...)
 	}
+	if len(podSpec.EphemeralContainers) > 0 {
+		containers = append(containers, GetEphemeralContainers(resource)...)
+	}
 	return containers
 }
 
@@ -45,6 +51,33 @@ func GetInitContainers(resource Resource) []*ContainerV1 {
 	return containers
 }
 
+// GetEphemeralContainers returns the ephemeral containers of the pod spec of the resource, such as the debug containers
+// added by kubectl debug. Ephemeral containers have the same fields as containers, so they are returned as containers
+// and are audited and fixed like them. Fields which ephemeral containers don't allow, such as ports and resources, must
+// not be set on them
+func GetEphemeralContainers(resource Resource) []*ContainerV1 {
+	podSpec := GetPodSpec(resource)
+	if podSpec == nil {
+		return nil
+	}
+
+	containers := make([]*ContainerV1, len(podSpec.EphemeralContainers))
+	for i := range podSpec.EphemeralContainers {
+		containers[i] = (*ContainerV1)(&podSpec.EphemeralContainers[i].EphemeralContainerCommon)
+	}
+	return containers
+}
+
+// IsEphemeralContainer returns true if the container is one of the ephemeral containers of the resource
+func IsEphemeralContainer(resource Resource, container *ContainerV1) bool {
+	for _, ephemeralContainer := range GetEphemeralContainers(resource) {
+		if ephemeralContainer == container {
+			return true
+		}
+	}
+	return false
+}
+
 // GetAnnotations returns the annotations at the pod level. If the resource does not have pods, then it returns
 // the least-nested annotations
 func GetAnnotations(resource Resource) map[string]string {
@@ -98,6 +131,8 @@ func GetPodSpec(resource Resource) *PodSpecV1 {
 	switch kubeType := resource.(type) {
 	case *PodV1:
 		return &kubeType.Spec
+	case *CustomResource:
+		return kubeType.podSpec()
 	case *NamespaceV1, *ServiceAccountV1:
 		return nil
 	}
@@ -105,10 +140,42 @@ func GetPodSpec(resource Resource) *PodSpecV1 {
 	return nil
 }
 
+// IsWindowsPod returns true if the pod of the resource runs on Windows nodes, as set by its OS or its node selector
+func IsWindowsPod(resource Resource) bool {
+	podSpec := GetPodSpec(resource)
+	if podSpec == nil {
+		return false
+	}
+	if podSpec.OS != nil {
+		return podSpec.OS.Name == apiv1.Windows
+	}
+	return podSpec.NodeSelector[apiv1.LabelOSStable] == string(apiv1.Windows)
+}
+
+// HasWindowsOptions returns true if the pod of the resource or one of its containers sets windowsOptions in its
+// SecurityContext. The options are only used on Windows nodes, so the pod is likely to run on Windows
+func HasWindowsOptions(resource Resource) bool {
+	podSpec := GetPodSpec(resource)
+	if podSpec == nil {
+		return false
+	}
+	if podSpec.SecurityContext != nil && podSpec.SecurityContext.WindowsOptions != nil {
+		return true
+	}
+	for _, container := range GetContainers(resource) {
+		if container.SecurityContext != nil && container.SecurityContext.WindowsOptions != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // GetPodTemplateSpec gets the PodTemplateSpec for a resource. Avoid using this function if you need support for
 // Pod, Namespace, or ServiceAccount resources, and write a helper functions in this package instead
 func GetPodTemplateSpec(resource Resource) *PodTemplateSpecV1 {
 	switch kubeType := resource.(type) {
+	case *CronJobV1:
+		return &kubeType.Spec.JobTemplate.Spec.Template
 	case *CronJobV1Beta1:
 		return &kubeType.Spec.JobTemplate.Spec.Template
 	case *DaemonSetV1:
@@ -123,9 +190,70 @@ func GetPodTemplateSpec(resource Resource) *PodTemplateSpecV1 {
 		return kubeType.Spec.Template
 	case *StatefulSetV1:
 		return &kubeType.Spec.Template
+	case *CustomResource:
+		return kubeType.podTemplateSpec()
 	case *PodV1, *NamespaceV1:
 		return nil
 	}
 
 	return nil
 }
+
+// IsSuspended returns true if the resource is a suspended CronJob or Job. Suspended CronJobs don't create Jobs and
+// suspended Jobs don't create pods, until they are resumed
+func IsSuspended(resource Resource) bool {
+	var suspend *bool
+	switch kubeType := resource.(type) {
+	case *CronJobV1:
+		suspend = kubeType.Spec.Suspend
+	case *CronJobV1Beta1:
+		suspend = kubeType.Spec.Suspend
+	case *JobV1:
+		suspend = kubeType.Spec.Suspend
+	}
+	return suspend != nil && *suspend
+}
+
+// GetCronJobName returns the name of the CronJob which created the resource, or an empty string if the resource is
+// not a Job created by a CronJob. The Jobs of a CronJob are named after the time they were scheduled at, so the
+// CronJob identifies them across audits
+func GetCronJobName(resource Resource) string {
+	job, ok := resource.(*JobV1)
+	if !ok {
+		return ""
+	}
+	for _, ownerReference := range job.GetOwnerReferences() {
+		if ownerReference.Kind == "CronJob" && ownerReference.Controller != nil && *ownerReference.Controller {
+			return ownerReference.Name
+		}
+	}
+	return ""
+}
+
+// GetResourceName returns the identity of the resource in the form kind/namespace/name, leaving out the namespace and
+// name if they are not set, or an empty string if the resource is nil
+func GetResourceName(resource Resource) string {
+	if resource == nil {
+		return ""
+	}
+
+	parts := []string{resource.GetObjectKind().GroupVersionKind().Kind}
+	if objectMeta := GetObjectMeta(resource); objectMeta != nil {
+		if objectMeta.GetNamespace() != "" {
+			parts = append(parts, objectMeta.GetNamespace())
+		}
+		if objectMeta.GetName() != "" {
+			parts = append(parts, objectMeta.GetName())
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// DescribeResource returns the kind, namespace and name of a resource in the form "kind namespace/name" for messages,
+// leaving out the kind and namespace if they are empty
+func DescribeResource(kind, namespace, name string) string {
+	if namespace != "" {
+		name = namespace + "/" + name
+	}
+	return strings.TrimSpace(kind + " " + name)
+}
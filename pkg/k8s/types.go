@@ -6,6 +6,8 @@ import (
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	apiv1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sRuntime "k8s.io/apimachinery/pkg/runtime"
 )
@@ -16,9 +18,21 @@ type CapabilitiesV1 = apiv1.Capabilities
 // CapabilityV1 is a type alias for the v1 version of the k8s API.
 type CapabilityV1 = apiv1.Capability
 
+// ClusterRoleV1 is a type alias for the v1 version of the k8s rbac API.
+type ClusterRoleV1 = rbacv1.ClusterRole
+
+// ClusterRoleBindingV1 is a type alias for the v1 version of the k8s rbac API.
+type ClusterRoleBindingV1 = rbacv1.ClusterRoleBinding
+
 // ContainerV1 is a type alias for the v1 version of the k8s API.
 type ContainerV1 = apiv1.Container
 
+// CronJobV1 is a type alias for the v1 version of the k8s batch API.
+type CronJobV1 = batchv1.CronJob
+
+// CronJobSpecV1 is a type alias for the v1 version of the k8s batch API.
+type CronJobSpecV1 = batchv1.CronJobSpec
+
 // CronJobV1Beta1 is a type alias for the v1beta1 version of the k8s batch API.
 type CronJobV1Beta1 = batchv1beta1.CronJob
 
@@ -37,6 +51,12 @@ type DeploymentSpecV1 = appsv1.DeploymentSpec
 // DeploymentV1 is a type alias for the v1 version of the k8s apps API.
 type DeploymentV1 = appsv1.Deployment
 
+// IngressV1 is a type alias for the v1 version of the k8s networking API.
+type IngressV1 = networkingv1.Ingress
+
+// JobTemplateSpecV1 is a type alias for the v1 version of the k8s batch API.
+type JobTemplateSpecV1 = batchv1.JobTemplateSpec
+
 // JobTemplateSpecV1Beta1 is a type alias for the v1beta1 version of the k8s batch API.
 type JobTemplateSpecV1Beta1 = batchv1beta1.JobTemplateSpec
 
@@ -46,6 +66,9 @@ type JobSpecV1 = batchv1.JobSpec
 // JobV1 is a type alias for the v1 version of the k8s batch API.
 type JobV1 = batchv1.Job
 
+// LabelSelectorV1 is a type alias for the v1 version of the k8s meta API.
+type LabelSelectorV1 = metav1.LabelSelector
+
 // ListOptionsV1 is a type alias for the v1 version of the k8s meta API.
 type ListOptionsV1 = metav1.ListOptions
 
@@ -55,15 +78,30 @@ type NamespaceV1 = apiv1.Namespace
 // NamespaceSpecV1 is a type alias for the v1 version of the k8s API.
 type NamespaceSpecV1 = apiv1.NamespaceSpec
 
+// NetworkPolicyEgressRuleV1 is a type alias for the v1 version of the k8s networking API.
+type NetworkPolicyEgressRuleV1 = networkingv1.NetworkPolicyEgressRule
+
+// NetworkPolicyPeerV1 is a type alias for the v1 version of the k8s networking API.
+type NetworkPolicyPeerV1 = networkingv1.NetworkPolicyPeer
+
+// NetworkPolicyPortV1 is a type alias for the v1 version of the k8s networking API.
+type NetworkPolicyPortV1 = networkingv1.NetworkPolicyPort
+
 // NetworkPolicySpecV1 is a type alias for the v1 version of the k8s networking API.
 type NetworkPolicySpecV1 = networkingv1.NetworkPolicySpec
 
 // NetworkPolicyV1 is a type alias for the v1 version of the k8s networking API.
 type NetworkPolicyV1 = networkingv1.NetworkPolicy
 
+// NodeV1 is a type alias for the v1 version of the k8s API.
+type NodeV1 = apiv1.Node
+
 // ObjectMetaV1 is a type alias for the v1 version of the k8s meta API.
 type ObjectMetaV1 = metav1.ObjectMeta
 
+// PodDisruptionBudgetV1 is a type alias for the v1 version of the k8s policy API.
+type PodDisruptionBudgetV1 = policyv1.PodDisruptionBudget
+
 // PodSpecV1 is a type alias for the v1 version of the k8s API.
 type PodSpecV1 = apiv1.PodSpec
 
@@ -76,9 +114,15 @@ type PodTemplateV1 = apiv1.PodTemplate
 // PodV1 is a type alias for the v1 version of the k8s API.
 type PodV1 = apiv1.Pod
 
+// PolicyRuleV1 is a type alias for the v1 version of the k8s rbac API.
+type PolicyRuleV1 = rbacv1.PolicyRule
+
 // PolicyTypeV1 is a type alias for the v1 version of the k8s networking API.
 type PolicyTypeV1 = networkingv1.PolicyType
 
+// ProtocolV1 is a type alias for the v1 version of the k8s API.
+type ProtocolV1 = apiv1.Protocol
+
 // ReplicationControllerSpecV1 is a type alias for the v1 version of the k8s API.
 type ReplicationControllerSpecV1 = apiv1.ReplicationControllerSpec
 
@@ -88,6 +132,15 @@ type ReplicationControllerV1 = apiv1.ReplicationController
 // Resource is a type alias for a runtime.Object
 type Resource k8sRuntime.Object
 
+// RoleBindingV1 is a type alias for the v1 version of the k8s rbac API.
+type RoleBindingV1 = rbacv1.RoleBinding
+
+// RoleRefV1 is a type alias for the v1 version of the k8s rbac API.
+type RoleRefV1 = rbacv1.RoleRef
+
+// RoleV1 is a type alias for the v1 version of the k8s rbac API.
+type RoleV1 = rbacv1.Role
+
 // SecurityContextV1 is a type alias for the v1 version of the k8s API.
 type SecurityContextV1 = apiv1.SecurityContext
 
@@ -106,6 +159,9 @@ type StatefulSetSpecV1 = appsv1.StatefulSetSpec
 // StatefulSetV1 is a type alias for the v1 version of the k8s apps API.
 type StatefulSetV1 = appsv1.StatefulSet
 
+// SubjectV1 is a type alias for the v1 version of the k8s rbac API.
+type SubjectV1 = rbacv1.Subject
+
 // TypeMetaV1 is a type alias for the v1 version of the k8s meta API.
 type TypeMetaV1 = metav1.TypeMeta
 
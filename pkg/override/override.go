@@ -2,6 +2,7 @@ package override
 
 import (
 	"strings"
+	"time"
 
 	"github.com/Shopify/kubeaudit"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
@@ -26,10 +27,16 @@ const (
 	OverrideLabelPrefix = "kubeaudit.io/"
 )
 
+// expiryLayout is the layout of the expiry date which can start the value of an override
+const expiryLayout = "2006-01-02"
+
+// now returns the current time. It is replaced in tests
+var now = time.Now
+
 // GetOverriddenResultName takes an audit result name and modifies it to indicate that the security issue was
 // ignored by an override label
 func GetOverriddenResultName(resultName string) string {
-	return resultName + "Allowed"
+	return resultName + kubeaudit.OverriddenRuleSuffix
 }
 
 // NewRedundantOverrideResult creates a new AuditResult at warning level telling the user to remove the override
@@ -48,14 +55,38 @@ func NewRedundantOverrideResult(auditorName, containerName, overrideReason, over
 }
 
 // ApplyOverride checks if hasOverride is true. If it is, it changes the severity of the audit result from error to
-// info, adds the override reason to the metadata and removes the pending fix
+// info, adds the override reason to the metadata and removes the pending fix. The label which overrode the audit
+// result is recorded in its OverrideLabel, so reports can list why findings were overridden.
+//
+// If no container name is given, the container of the audit result, if any, is used so that container overrides
+// apply to the results of auditors which audit the whole pod.
+//
+// Overrides whose expiry date has passed are ignored. The audit result is returned unchanged apart from its
+// ExpiredOverride, for which an ExpiredAuditorOverride result is reported
 func ApplyOverride(auditResult *kubeaudit.AuditResult, auditorName, containerName string, resource k8s.Resource, overrideLabel string) *kubeaudit.AuditResult {
-	hasOverride, overrideReason := GetContainerOverrideReason(containerName, resource, overrideLabel)
+	if containerName == "" && auditResult != nil {
+		containerName = auditResult.Metadata["Container"]
+	}
+
+	label, value, hasOverride := getContainerOverride(containerName, resource, overrideLabel)
 
 	if !hasOverride {
 		return auditResult
 	}
 
+	expiry, overrideReason := parseOverrideValue(value)
+	if !expiry.IsZero() && isExpired(expiry) {
+		expired := kubeaudit.ExpiredOverride{Label: label, Expiry: expiry.Format(expiryLayout), Reason: overrideReason}
+		if isContainerOverrideLabel(label, containerName, overrideLabel) {
+			expired.Container = containerName
+		}
+		if auditResult == nil {
+			return kubeaudit.NewExpiredOverrideResult(auditorName, expired)
+		}
+		auditResult.ExpiredOverride = &expired
+		return auditResult
+	}
+
 	if auditResult == nil {
 		return NewRedundantOverrideResult(auditorName, containerName, overrideReason, overrideLabel)
 	}
@@ -63,6 +94,8 @@ func ApplyOverride(auditResult *kubeaudit.AuditResult, auditorName, containerNam
 	auditResult.Rule = GetOverriddenResultName(auditResult.Rule)
 	auditResult.PendingFix = nil
 	auditResult.Severity = kubeaudit.Info
+	auditResult.SuppressedBy = kubeaudit.SuppressedByOverride
+	auditResult.OverrideLabel = label
 	auditResult.Message = "Audit result overridden: " + auditResult.Message
 
 	if overrideReason != "" && strings.ToLower(overrideReason) != "true" {
@@ -71,32 +104,81 @@ func ApplyOverride(auditResult *kubeaudit.AuditResult, auditorName, containerNam
 		}
 		auditResult.Metadata["OverrideReason"] = overrideReason
 	}
+	if !expiry.IsZero() {
+		if auditResult.Metadata == nil {
+			auditResult.Metadata = make(kubeaudit.Metadata)
+		}
+		auditResult.Metadata["OverrideExpiry"] = expiry.Format(expiryLayout)
+	}
 
 	return auditResult
 }
 
+// parseOverrideValue returns the expiry date of the value of an override, or the zero time if it has none, and the
+// reason for the override. Overrides which expire have values of the form "2025-09-30;JIRA-1234", where the reason
+// after the date is optional. Since label values can't contain semicolons, the date of override labels can also be
+// separated from the reason by an underscore, as in "2025-09-30_JIRA-1234"
+func parseOverrideValue(value string) (expiry time.Time, reason string) {
+	if len(value) < len(expiryLayout) {
+		return time.Time{}, value
+	}
+	date, rest := value[:len(expiryLayout)], value[len(expiryLayout):]
+	if rest != "" && rest[0] != ';' && rest[0] != '_' {
+		return time.Time{}, value
+	}
+	expiry, err := time.Parse(expiryLayout, date)
+	if err != nil {
+		return time.Time{}, value
+	}
+	if rest != "" {
+		rest = strings.TrimSpace(rest[1:])
+	}
+	return expiry, rest
+}
+
+// isExpired returns true if the expiry date has passed, in UTC. Overrides are valid until the end of their expiry date
+func isExpired(expiry time.Time) bool {
+	return !now().Before(expiry.AddDate(0, 0, 1))
+}
+
+// isContainerOverrideLabel returns true if the label is one of the container override labels of the container
+func isContainerOverrideLabel(label, containerName, overrideLabel string) bool {
+	return containerName != "" && (label == GetDeprecatedContainerOverrideLabel(containerName, overrideLabel) ||
+		label == GetContainerOverrideLabel(containerName, overrideLabel) ||
+		label == GetContainerScopedOverrideLabel(containerName, overrideLabel))
+}
+
 // GetContainerOverrideReason returns true if the resource has a pod-level label disabling a given auditor and the
 // value of the label which is meant to represent the reason for overriding the auditor
 //
-// Container override labels disable the auditor for that specific container and have the following format:
+// Container override labels disable the auditor for that specific container and have either of the following formats,
+// and can also be set as annotations:
 //
 //	container.kubeaudit.io/[container name].[auditor override label]
+//	kubeaudit.io/[auditor override label].[container name]
 //
 // If there is no container override label, it calls GetResourceOverrideReason()
+//
+// Overrides whose expiry date has passed are ignored, and the expiry date is not part of the reason
 func GetContainerOverrideReason(containerName string, resource k8s.Resource, overrideLabel string) (hasOverride bool, reason string) {
-	labels := k8s.GetLabels(resource)
+	_, value, hasOverride := getContainerOverride(containerName, resource, overrideLabel)
+	return getValidOverrideReason(value, hasOverride)
+}
 
+// getContainerOverride returns the label overriding the auditor for the container or the whole resource, and its value
+func getContainerOverride(containerName string, resource k8s.Resource, overrideLabel string) (label, value string, hasOverride bool) {
 	if containerName != "" {
-		if reason, hasOverride = labels[GetDeprecatedContainerOverrideLabel(containerName, overrideLabel)]; hasOverride {
-			return
-		}
-
-		if reason, hasOverride = labels[GetContainerOverrideLabel(containerName, overrideLabel)]; hasOverride {
+		label, value, hasOverride = findOverride(resource, []string{
+			GetDeprecatedContainerOverrideLabel(containerName, overrideLabel),
+			GetContainerOverrideLabel(containerName, overrideLabel),
+			GetContainerScopedOverrideLabel(containerName, overrideLabel),
+		})
+		if hasOverride {
 			return
 		}
 	}
 
-	return GetResourceOverrideReason(resource, overrideLabel)
+	return getResourceOverride(resource, overrideLabel)
 }
 
 // GetResourceOverrideReason returns true if the resource has a label disabling a given auditor and the value of the
@@ -109,21 +191,46 @@ func GetContainerOverrideReason(containerName string, resource k8s.Resource, ove
 // Namespace override labels disable the auditor for the namespace resource and have the following format:
 //
 // kubeaudit.io/[auditor override label]
+//
+// Overrides whose expiry date has passed are ignored, and the expiry date is not part of the reason
 func GetResourceOverrideReason(resource k8s.Resource, auditorOverrideLabel string) (hasOverride bool, reason string) {
-	labelFuncs := []func(overrideLabel string) string{
-		GetOverrideLabel,
-		GetDeprecatedPodOverrideLabel,
-		GetDeprecatedNamespaceOverrideLabel,
+	_, value, hasOverride := getResourceOverride(resource, auditorOverrideLabel)
+	return getValidOverrideReason(value, hasOverride)
+}
+
+// getValidOverrideReason returns the reason of the value of an override, unless it expired
+func getValidOverrideReason(value string, hasOverride bool) (bool, string) {
+	if !hasOverride {
+		return false, ""
+	}
+	expiry, reason := parseOverrideValue(value)
+	if !expiry.IsZero() && isExpired(expiry) {
+		return false, ""
 	}
+	return true, reason
+}
 
-	labels := k8s.GetLabels(resource)
-	for _, getLabel := range labelFuncs {
-		if reason, hasOverride = labels[getLabel(auditorOverrideLabel)]; hasOverride {
-			return
+// getResourceOverride returns the label overriding the auditor for the whole resource, and its value
+func getResourceOverride(resource k8s.Resource, auditorOverrideLabel string) (label, value string, hasOverride bool) {
+	return findOverride(resource, []string{
+		GetOverrideLabel(auditorOverrideLabel),
+		GetDeprecatedPodOverrideLabel(auditorOverrideLabel),
+		GetDeprecatedNamespaceOverrideLabel(auditorOverrideLabel),
+	})
+}
+
+// findOverride returns the first of the override labels which the resource has, and its value. Overrides can be set
+// as labels or, so that their value isn't restricted to the characters of label values, as annotations. Labels take
+// precedence over annotations
+func findOverride(resource k8s.Resource, overrideLabels []string) (label, value string, hasOverride bool) {
+	for _, values := range []map[string]string{k8s.GetLabels(resource), k8s.GetAnnotations(resource)} {
+		for _, label := range overrideLabels {
+			if value, hasOverride = values[label]; hasOverride {
+				return label, value, true
+			}
 		}
 	}
-
-	return false, ""
+	return "", "", false
 }
 
 // TODO: remove deprecated getters
@@ -146,3 +253,9 @@ func GetOverrideLabel(overrideLabel string) string {
 func GetContainerOverrideLabel(containerName, overrideLabel string) string {
 	return ContainerOverrideLabelPrefix + containerName + "." + overrideLabel
 }
+
+// GetContainerScopedOverrideLabel returns the override label for a container in the format of pod override labels,
+// which keeps the container name out of the prefix of the label
+func GetContainerScopedOverrideLabel(containerName, overrideLabel string) string {
+	return OverrideLabelPrefix + overrideLabel + "." + containerName
+}
@@ -0,0 +1,92 @@
+package override
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestParseOverrideValue(t *testing.T) {
+	cases := []struct {
+		value          string
+		expectedExpiry string
+		expectedReason string
+	}{
+		{"SomeReason", "", "SomeReason"},
+		{"", "", ""},
+		{"2025-09-30;JIRA-1234", "2025-09-30", "JIRA-1234"},
+		{"2025-09-30_JIRA-1234", "2025-09-30", "JIRA-1234"},
+		{"2025-09-30", "2025-09-30", ""},
+		{"2025-09-30; JIRA-1234", "2025-09-30", "JIRA-1234"},
+		{"2025-09-30-JIRA-1234", "", "2025-09-30-JIRA-1234"},
+		{"2025-13-30;JIRA-1234", "", "2025-13-30;JIRA-1234"},
+	}
+
+	for _, tc := range cases {
+		expiry, reason := parseOverrideValue(tc.value)
+		if tc.expectedExpiry == "" {
+			assert.True(t, expiry.IsZero(), tc.value)
+		} else {
+			assert.Equal(t, tc.expectedExpiry, expiry.Format(expiryLayout), tc.value)
+		}
+		assert.Equal(t, tc.expectedReason, reason, tc.value)
+	}
+}
+
+func TestApplyOverrideExpiry(t *testing.T) {
+	defer func() { now = time.Now }()
+	newResult := func() *kubeaudit.AuditResult {
+		return &kubeaudit.AuditResult{Rule: "PrivilegedTrue", Severity: kubeaudit.Error, Metadata: kubeaudit.Metadata{"Container": "container"}}
+	}
+	pod := &k8s.PodV1{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{
+		"container.kubeaudit.io/container.allow-privileged": "2025-09-30_JIRA-1234",
+	}}}
+
+	// Overrides are valid until the end of their expiry date
+	now = func() time.Time { return time.Date(2025, 9, 30, 23, 59, 0, 0, time.UTC) }
+	auditResult := ApplyOverride(newResult(), "privileged", "", pod, "allow-privileged")
+	assert.Equal(t, "PrivilegedTrueAllowed", auditResult.Rule)
+	assert.Equal(t, kubeaudit.Metadata{"Container": "container", "OverrideReason": "JIRA-1234", "OverrideExpiry": "2025-09-30"}, auditResult.Metadata)
+	assert.Nil(t, auditResult.ExpiredOverride)
+
+	// Expired overrides are ignored
+	now = func() time.Time { return time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC) }
+	auditResult = ApplyOverride(newResult(), "privileged", "", pod, "allow-privileged")
+	assert.Equal(t, "PrivilegedTrue", auditResult.Rule)
+	assert.Equal(t, kubeaudit.Error, auditResult.Severity)
+	require.NotNil(t, auditResult.ExpiredOverride)
+	assert.Equal(t, kubeaudit.ExpiredOverride{
+		Label:     "container.kubeaudit.io/container.allow-privileged",
+		Container: "container",
+		Expiry:    "2025-09-30",
+		Reason:    "JIRA-1234",
+	}, *auditResult.ExpiredOverride)
+	hasOverride, _ := GetContainerOverrideReason("container", pod, "allow-privileged")
+	assert.False(t, hasOverride)
+
+	// Expired overrides of auditors which found no issues are reported instead of being redundant
+	auditResult = ApplyOverride(nil, "privileged", "container", pod, "allow-privileged")
+	assert.Equal(t, kubeaudit.ExpiredAuditorOverride, auditResult.Rule)
+	assert.Equal(t, "container", auditResult.Metadata["Container"])
+}
+
+func TestApplyOverrideAnnotation(t *testing.T) {
+	pod := &k8s.PodV1{ObjectMeta: metav1.ObjectMeta{
+		Labels:      map[string]string{"kubeaudit.io/allow-privileged": "LabelReason"},
+		Annotations: map[string]string{"kubeaudit.io/allow-privileged": "AnnotationReason", "kubeaudit.io/allow-run-as-root": "2099-12-31;JIRA-1234"},
+	}}
+
+	// Labels take precedence over annotations
+	hasOverride, reason := GetResourceOverrideReason(pod, "allow-privileged")
+	assert.True(t, hasOverride)
+	assert.Equal(t, "LabelReason", reason)
+
+	hasOverride, reason = GetResourceOverrideReason(pod, "allow-run-as-root")
+	assert.True(t, hasOverride)
+	assert.Equal(t, "JIRA-1234", reason)
+}
@@ -0,0 +1,191 @@
+// Package plugin runs external auditors, so organizations can add their own checks without forking kubeaudit. A
+// plugin is an executable named kubeaudit-auditor-[name] in the PATH, like kubectl plugins. It is run once for each
+// audited resource, reads a Request as JSON from its standard input, and writes a Response as JSON to its standard
+// output. Plugins written in Go can use Serve to implement the protocol
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/internal/k8sinternal"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// Prefix is the prefix of the names of plugin executables. The rest of the name is the name of the auditor
+const Prefix = "kubeaudit-auditor-"
+
+// DefaultTimeout is the time a plugin has to audit a resource
+const DefaultTimeout = 30 * time.Second
+
+// ErrInvalidResponse is returned when a plugin writes a response which is not valid
+var ErrInvalidResponse = errors.New("invalid plugin response")
+
+// Request is the input of a plugin
+type Request struct {
+	// Resource is the audited resource, as a Kubernetes object with its apiVersion and kind
+	Resource json.RawMessage `json:"resource"`
+}
+
+// Response is the output of a plugin
+type Response struct {
+	// Results are the findings of the plugin for the resource. A resource without findings has no results
+	Results []Result `json:"results"`
+}
+
+// Result is a finding of a plugin. It is reported with the name of the plugin as its auditor
+type Result struct {
+	// Rule uniquely identifies the type of finding, eg. "CostCenterMissing"
+	Rule string `json:"rule"`
+	// Severity is one of "error", "warning", "info" or a custom severity of the kubeaudit config
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// Metadata is additional context, such as the "Container" the finding is about
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Field is the path of the field the finding is about, used to locate it in manifests. See kubeaudit.AuditResult
+	Field string `json:"field,omitempty"`
+	// References link the rule to its documentation and to the controls of security frameworks
+	References []kubeaudit.Reference `json:"references,omitempty"`
+}
+
+// Plugin is an auditor which runs a plugin executable. It implements kubeaudit.Auditable
+type Plugin struct {
+	// Name is the name of the auditor, which is the name of the executable without the prefix
+	Name string
+	// Path is the path of the executable
+	Path    string
+	Timeout time.Duration
+}
+
+// New returns a plugin running the executable at the path
+func New(path string) *Plugin {
+	name := strings.TrimPrefix(filepath.Base(path), Prefix)
+	if ext := filepath.Ext(name); strings.EqualFold(ext, ".exe") {
+		name = strings.TrimSuffix(name, ext)
+	}
+	return &Plugin{Name: name, Path: path, Timeout: DefaultTimeout}
+}
+
+// Discover finds the plugin executables in the directories of the PATH environment variable. If there are several
+// executables for the same name, the one in the first directory is used. The plugins are ordered by name
+func Discover() []*Plugin {
+	return discover(filepath.SplitList(os.Getenv("PATH")))
+}
+
+func discover(dirs []string) []*Plugin {
+	plugins := map[string]*Plugin{}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !strings.HasPrefix(entry.Name(), Prefix) || entry.Name() == Prefix || !isExecutable(dir, entry) {
+				continue
+			}
+			plugin := New(filepath.Join(dir, entry.Name()))
+			if _, ok := plugins[plugin.Name]; !ok {
+				plugins[plugin.Name] = plugin
+			}
+		}
+	}
+
+	discovered := make([]*Plugin, 0, len(plugins))
+	for _, plugin := range plugins {
+		discovered = append(discovered, plugin)
+	}
+	sort.Slice(discovered, func(i, j int) bool { return discovered[i].Name < discovered[j].Name })
+	return discovered
+}
+
+func isExecutable(dir string, entry os.DirEntry) bool {
+	info, err := os.Stat(filepath.Join(dir, entry.Name()))
+	if err != nil || info.IsDir() {
+		return false
+	}
+	// Windows doesn't have an executable permission
+	return info.Mode()&0111 != 0 || strings.EqualFold(filepath.Ext(entry.Name()), ".exe")
+}
+
+// String returns the name of the plugin, which identifies it in profiles
+func (p *Plugin) String() string {
+	return p.Name
+}
+
+// Audit runs the plugin on the resource and returns its findings. The plugin fails if it exits with a non-zero code,
+// takes longer than its timeout or writes an invalid response
+func (p *Plugin) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*kubeaudit.AuditResult, error) {
+	request, err := newRequest(resource)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding resource for plugin %s: %w", p.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Path)
+	cmd.Stdin = bytes.NewReader(request)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("plugin %s did not finish within %s", p.Name, p.Timeout)
+		}
+		return nil, fmt.Errorf("plugin %s failed: %w: %s", p.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var response Response
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("%w from plugin %s: %s", ErrInvalidResponse, p.Name, err)
+	}
+	return p.auditResults(response)
+}
+
+func newRequest(resource k8s.Resource) ([]byte, error) {
+	obj, err := k8sinternal.ToUnstructured(resource)
+	if err != nil {
+		return nil, err
+	}
+	resourceJSON, err := obj.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(Request{Resource: resourceJSON})
+}
+
+func (p *Plugin) auditResults(response Response) ([]*kubeaudit.AuditResult, error) {
+	auditResults := make([]*kubeaudit.AuditResult, 0, len(response.Results))
+	for _, result := range response.Results {
+		if result.Rule == "" {
+			return nil, fmt.Errorf("%w from plugin %s: a result has no rule", ErrInvalidResponse, p.Name)
+		}
+		severity, err := kubeaudit.ParseSeverity(result.Severity)
+		if err != nil {
+			return nil, fmt.Errorf("%w from plugin %s: rule %s: %s", ErrInvalidResponse, p.Name, result.Rule, err)
+		}
+		auditResults = append(auditResults, &kubeaudit.AuditResult{
+			Auditor:    p.Name,
+			Rule:       result.Rule,
+			Severity:   severity,
+			Message:    result.Message,
+			Metadata:   result.Metadata,
+			Field:      result.Field,
+			References: result.References,
+		})
+	}
+	return auditResults, nil
+}
@@ -0,0 +1,109 @@
+package plugin
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// writePlugin writes a shell script plugin to the directory and returns its path
+func writePlugin(t *testing.T, dir, name, script string) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	path := filepath.Join(dir, Prefix+name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0755))
+	return path
+}
+
+func newPod() k8s.Resource {
+	pod := k8s.NewPod()
+	pod.Name = "pod"
+	pod.Spec.Containers = []k8s.ContainerV1{{Name: "container", Image: "scratch:1.0"}}
+	return pod
+}
+
+func TestDiscover(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	costCenter := writePlugin(t, first, "costcenter", "")
+	writePlugin(t, second, "costcenter", "")
+	teams := writePlugin(t, second, "teams", "")
+	require.NoError(t, os.WriteFile(filepath.Join(first, Prefix+"notexecutable"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(first, "kubeaudit-other"), nil, 0755))
+
+	plugins := discover([]string{first, "", filepath.Join(first, "missing"), second})
+	assert.Equal(t, []*Plugin{
+		{Name: "costcenter", Path: costCenter, Timeout: DefaultTimeout},
+		{Name: "teams", Path: teams, Timeout: DefaultTimeout},
+	}, plugins)
+}
+
+func TestAudit(t *testing.T) {
+	dir := t.TempDir()
+	path := writePlugin(t, dir, "costcenter", `
+grep -q '"resource":{"apiVersion":"v1","kind":"Pod"' || exit 1
+echo '{"results":[{"rule":"CostCenterMissing","severity":"warning","message":"The cost-center label is missing.","metadata":{"Container":"container"},"references":[{"type":"docs","url":"https://docs.example.com/costcenter"}]}]}'
+`)
+
+	auditResults, err := New(path).Audit(newPod(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []*kubeaudit.AuditResult{{
+		Auditor:    "costcenter",
+		Rule:       "CostCenterMissing",
+		Severity:   kubeaudit.Warn,
+		Message:    "The cost-center label is missing.",
+		Metadata:   kubeaudit.Metadata{"Container": "container"},
+		References: []kubeaudit.Reference{{Type: kubeaudit.ReferenceDocs, URL: "https://docs.example.com/costcenter"}},
+	}}, auditResults)
+}
+
+func TestAuditErrors(t *testing.T) {
+	cases := []struct {
+		testName string
+		script   string
+		expected string
+	}{
+		{"Failure", "echo 'no access' >&2; exit 3", "plugin costcenter failed: exit status 3: no access"},
+		{"Invalid JSON", "echo 'results'", "invalid plugin response from plugin costcenter"},
+		{"Missing rule", `echo '{"results":[{"severity":"error"}]}'`, "invalid plugin response from plugin costcenter: a result has no rule"},
+		{"Invalid severity", `echo '{"results":[{"rule":"CostCenterMissing","severity":"fatal"}]}'`, "invalid plugin response from plugin costcenter: rule CostCenterMissing"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			path := writePlugin(t, t.TempDir(), "costcenter", tc.script)
+			_, err := New(path).Audit(newPod(), nil)
+			assert.ErrorContains(t, err, tc.expected)
+		})
+	}
+}
+
+func TestServe(t *testing.T) {
+	in := strings.NewReader(`{"resource":{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod"}}}`)
+	var out bytes.Buffer
+	err := serve(in, &out, func(resource *unstructured.Unstructured) ([]Result, error) {
+		if resource.GetLabels()["cost-center"] != "" {
+			return nil, nil
+		}
+		return []Result{{Rule: "CostCenterMissing", Severity: "warning", Message: "Pod " + resource.GetName() + " has no cost-center label."}}, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"results":[{"rule":"CostCenterMissing","severity":"warning","message":"Pod pod has no cost-center label."}]}`+"\n", out.String())
+
+	out.Reset()
+	err = serve(strings.NewReader(`{"resource":{"apiVersion":"v1","kind":"Pod"}}`), &out, func(*unstructured.Unstructured) ([]Result, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `{"results":[]}`+"\n", out.String())
+}
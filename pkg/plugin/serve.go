@@ -0,0 +1,40 @@
+package plugin
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AuditFunc audits a resource for Serve, and returns the findings
+type AuditFunc func(resource *unstructured.Unstructured) ([]Result, error)
+
+// Serve reads the request of kubeaudit from standard input, audits its resource with the function and writes the
+// response to standard output. Plugins written in Go call it from main, and exit with a non-zero code if it returns
+// an error
+func Serve(audit AuditFunc) error {
+	return serve(os.Stdin, os.Stdout, audit)
+}
+
+func serve(in io.Reader, out io.Writer, audit AuditFunc) error {
+	var request Request
+	if err := json.NewDecoder(in).Decode(&request); err != nil {
+		return err
+	}
+
+	resource := &unstructured.Unstructured{}
+	if err := resource.UnmarshalJSON(request.Resource); err != nil {
+		return err
+	}
+
+	results, err := audit(resource)
+	if err != nil {
+		return err
+	}
+	if results == nil {
+		results = []Result{}
+	}
+	return json.NewEncoder(out).Encode(Response{Results: results})
+}
@@ -0,0 +1,560 @@
+// Package rules is the registry of the rules reported by the built-in auditors. Rules are exported as typed constants
+// so that programs using kubeaudit as a library can filter and suppress audit results by rule without hardcoding rule
+// names, which would silently stop matching if a rule was renamed
+package rules
+
+import (
+	"sort"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/annotations"
+	"github.com/Shopify/kubeaudit/auditors/apparmor"
+	"github.com/Shopify/kubeaudit/auditors/asat"
+	"github.com/Shopify/kubeaudit/auditors/capabilities"
+	"github.com/Shopify/kubeaudit/auditors/deprecatedapis"
+	"github.com/Shopify/kubeaudit/auditors/egress"
+	"github.com/Shopify/kubeaudit/auditors/ephemeral"
+	"github.com/Shopify/kubeaudit/auditors/etcd"
+	"github.com/Shopify/kubeaudit/auditors/exposure"
+	"github.com/Shopify/kubeaudit/auditors/hostnet"
+	"github.com/Shopify/kubeaudit/auditors/hostns"
+	"github.com/Shopify/kubeaudit/auditors/hostprocess"
+	"github.com/Shopify/kubeaudit/auditors/image"
+	"github.com/Shopify/kubeaudit/auditors/imagepolicy"
+	"github.com/Shopify/kubeaudit/auditors/labels"
+	"github.com/Shopify/kubeaudit/auditors/lifecycle"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/mounts"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/nodecoverage"
+	"github.com/Shopify/kubeaudit/auditors/nonroot"
+	"github.com/Shopify/kubeaudit/auditors/ports"
+	"github.com/Shopify/kubeaudit/auditors/privesc"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/pss"
+	"github.com/Shopify/kubeaudit/auditors/rbac"
+	"github.com/Shopify/kubeaudit/auditors/requests"
+	"github.com/Shopify/kubeaudit/auditors/resilience"
+	"github.com/Shopify/kubeaudit/auditors/rootfs"
+	"github.com/Shopify/kubeaudit/auditors/runtimeclass"
+	"github.com/Shopify/kubeaudit/auditors/seccomp"
+	"github.com/Shopify/kubeaudit/auditors/secrets"
+	"github.com/Shopify/kubeaudit/auditors/volumes"
+	"github.com/Shopify/kubeaudit/auditors/vulns"
+	"github.com/Shopify/kubeaudit/pkg/override"
+)
+
+// ID is the name of a rule, as reported in the Rule of audit results
+type ID string
+
+// String returns the name of the rule
+func (id ID) String() string {
+	return string(id)
+}
+
+// Allowed returns the ID audit results of the rule are reported with when they are overridden by a label
+func (id ID) Allowed() ID {
+	return ID(override.GetOverriddenResultName(string(id)))
+}
+
+// Matches returns true if the audit result was reported for the rule, whether or not it was overridden
+func (id ID) Matches(auditResult *kubeaudit.AuditResult) bool {
+	return auditResult != nil && (auditResult.Rule == string(id) || auditResult.Rule == string(id.Allowed()))
+}
+
+// Rules which can be reported by any auditor
+const (
+	RedundantAuditorOverride ID = kubeaudit.RedundantAuditorOverride
+	ExpiredAuditorOverride   ID = kubeaudit.ExpiredAuditorOverride
+	AuditorPanic             ID = kubeaudit.AuditorPanic
+	AuditorError             ID = kubeaudit.AuditorError
+)
+
+// Rules of the manifest parser, which are reported by the Manifest auditor
+const (
+	ManifestSyntaxError  ID = kubeaudit.ManifestSyntaxError
+	ManifestUnknownField ID = kubeaudit.ManifestUnknownField
+	ManifestInvalidType  ID = kubeaudit.ManifestInvalidType
+)
+
+// Rules of the annotations auditor
+const (
+	AnnotationMissing      ID = annotations.AnnotationMissing
+	AnnotationValueInvalid ID = annotations.AnnotationValueInvalid
+	AnnotationForbidden    ID = annotations.AnnotationForbidden
+)
+
+// Rules of the apparmor auditor
+const (
+	AppArmorAnnotationMissing ID = apparmor.AppArmorAnnotationMissing
+	AppArmorDisabled          ID = apparmor.AppArmorDisabled
+	AppArmorBadValue          ID = apparmor.AppArmorBadValue
+	AppArmorInvalidAnnotation ID = apparmor.AppArmorInvalidAnnotation
+)
+
+// Rules of the asat auditor
+const (
+	AutomountServiceAccountTokenDeprecated               ID = asat.AutomountServiceAccountTokenDeprecated
+	AutomountServiceAccountTokenTrueAndDefaultSA         ID = asat.AutomountServiceAccountTokenTrueAndDefaultSA
+	AutomountServiceAccountTokenTrueInNamespaceDefaultSA ID = asat.AutomountServiceAccountTokenTrueInNamespaceDefaultSA
+)
+
+// Rules of the capabilities auditor
+const (
+	CapabilityAdded                    ID = capabilities.CapabilityAdded
+	CapabilityShouldDropAll            ID = capabilities.CapabilityShouldDropAll
+	CapabilityOrSecurityContextMissing ID = capabilities.CapabilityOrSecurityContextMissing
+	CapabilityProfileUnknown           ID = capabilities.CapabilityProfileUnknown
+)
+
+// Rules of the deprecatedapis auditor
+const (
+	DeprecatedAPIUsed ID = deprecatedapis.DeprecatedAPIUsed
+)
+
+// Rules of the egress auditor
+const (
+	NamespaceEgressUnrestricted ID = egress.NamespaceEgressUnrestricted
+	WorkloadEgressUnrestricted  ID = egress.WorkloadEgressUnrestricted
+)
+
+// Rules of the ephemeral auditor
+const (
+	EphemeralContainerInProduction ID = ephemeral.EphemeralContainerInProduction
+)
+
+// Rules of the etcd auditor
+const (
+	SecretsEncryptionAtRestDisabled ID = etcd.SecretsEncryptionAtRestDisabled
+	SecretsStoredUnencrypted        ID = etcd.SecretsStoredUnencrypted
+	EtcdConnectionInsecure          ID = etcd.EtcdConnectionInsecure
+	EtcdClientCertAuthDisabled      ID = etcd.EtcdClientCertAuthDisabled
+	EtcdClientURLInsecure           ID = etcd.EtcdClientURLInsecure
+	EtcdPeerCertAuthDisabled        ID = etcd.EtcdPeerCertAuthDisabled
+)
+
+// Rules of the exposure auditor
+const (
+	ServiceNodePortExposed     ID = exposure.ServiceNodePortExposed
+	ServiceSourceRangesMissing ID = exposure.ServiceSourceRangesMissing
+	IngressTLSMissing          ID = exposure.IngressTLSMissing
+)
+
+// Rules of the hostnet auditor
+const (
+	HostPortSet                        ID = hostnet.HostPortSet
+	HostAliasesSet                     ID = hostnet.HostAliasesSet
+	DNSPolicyHostNetWithoutHostNetwork ID = hostnet.DNSPolicyHostNetWithoutHostNetwork
+)
+
+// Rules of the hostns auditor
+const (
+	NamespaceHostNetworkTrue ID = hostns.NamespaceHostNetworkTrue
+	NamespaceHostIPCTrue     ID = hostns.NamespaceHostIPCTrue
+	NamespaceHostPIDTrue     ID = hostns.NamespaceHostPIDTrue
+)
+
+// Rules of the hostprocess auditor
+const (
+	HostProcessTruePod       ID = hostprocess.HostProcessTruePod
+	HostProcessTrueContainer ID = hostprocess.HostProcessTrueContainer
+)
+
+// Rules of the image auditor
+const (
+	ImageTagMissing           ID = image.ImageTagMissing
+	ImageTagIncorrect         ID = image.ImageTagIncorrect
+	ImageCorrect              ID = image.ImageCorrect
+	ImageRunsAsRoot           ID = image.ImageRunsAsRoot
+	ImageRunAsNonRootConflict ID = image.ImageRunAsNonRootConflict
+	ImageHealthcheckIgnored   ID = image.ImageHealthcheckIgnored
+	ImageShellMissing         ID = image.ImageShellMissing
+	ImageArchitectureMismatch ID = image.ImageArchitectureMismatch
+	ImageInspectionFailed     ID = image.ImageInspectionFailed
+	ImageInspectionSkipped    ID = image.ImageInspectionSkipped
+)
+
+// Rules of the imagepolicy auditor
+const (
+	ImageRegistryNotAllowed ID = imagepolicy.ImageRegistryNotAllowed
+	ImageTagLatest          ID = imagepolicy.ImageTagLatest
+	ImageDigestMissing      ID = imagepolicy.ImageDigestMissing
+)
+
+// Rules of the labels auditor
+const (
+	LabelMissing          ID = labels.LabelMissing
+	LabelValueInvalid     ID = labels.LabelValueInvalid
+	LabelPlaceholderValue ID = labels.LabelPlaceholderValue
+)
+
+// Rules of the lifecycle auditor
+const (
+	TerminationGracePeriodZero    ID = lifecycle.TerminationGracePeriodZero
+	RestartPolicyNotAlways        ID = lifecycle.RestartPolicyNotAlways
+	ActiveDeadlineSecondsSet      ID = lifecycle.ActiveDeadlineSecondsSet
+	JobTTLSecondsAfterFinishedNil ID = lifecycle.JobTTLSecondsAfterFinishedNil
+)
+
+// Rules of the limits auditor
+const (
+	LimitsNotSet         ID = limits.LimitsNotSet
+	LimitsCPUNotSet      ID = limits.LimitsCPUNotSet
+	LimitsMemoryNotSet   ID = limits.LimitsMemoryNotSet
+	LimitsCPUExceeded    ID = limits.LimitsCPUExceeded
+	LimitsMemoryExceeded ID = limits.LimitsMemoryExceeded
+)
+
+// Rules of the mounts auditor
+const (
+	SensitivePathsMounted ID = mounts.SensitivePathsMounted
+)
+
+// Rules of the netpols auditor
+const (
+	MissingDefaultDenyIngressAndEgressNetworkPolicy ID = netpols.MissingDefaultDenyIngressAndEgressNetworkPolicy
+	MissingDefaultDenyIngressNetworkPolicy          ID = netpols.MissingDefaultDenyIngressNetworkPolicy
+	MissingDefaultDenyEgressNetworkPolicy           ID = netpols.MissingDefaultDenyEgressNetworkPolicy
+	AllowAllIngressNetworkPolicyExists              ID = netpols.AllowAllIngressNetworkPolicyExists
+	AllowAllEgressNetworkPolicyExists               ID = netpols.AllowAllEgressNetworkPolicyExists
+)
+
+// Rules of the nodecoverage auditor
+const (
+	SecurityAgentMissing                ID = nodecoverage.SecurityAgentMissing
+	NodeNotCoveredBySecurityAgent       ID = nodecoverage.NodeNotCoveredBySecurityAgent
+	SecurityAgentNotToleratingAllTaints ID = nodecoverage.SecurityAgentNotToleratingAllTaints
+)
+
+// Rules of the nonroot auditor
+const (
+	RunAsUserCSCRoot           ID = nonroot.RunAsUserCSCRoot
+	RunAsUserPSCRoot           ID = nonroot.RunAsUserPSCRoot
+	RunAsNonRootCSCFalse       ID = nonroot.RunAsNonRootCSCFalse
+	RunAsNonRootPSCNilCSCNil   ID = nonroot.RunAsNonRootPSCNilCSCNil
+	RunAsNonRootPSCFalseCSCNil ID = nonroot.RunAsNonRootPSCFalseCSCNil
+)
+
+// Rules of the ports auditor
+const (
+	PrivilegedPortExposed       ID = ports.PrivilegedPortExposed
+	ForbiddenPortExposed        ID = ports.ForbiddenPortExposed
+	ServiceTargetPortUndeclared ID = ports.ServiceTargetPortUndeclared
+)
+
+// Rules of the privesc auditor
+const (
+	AllowPrivilegeEscalationNil  ID = privesc.AllowPrivilegeEscalationNil
+	AllowPrivilegeEscalationTrue ID = privesc.AllowPrivilegeEscalationTrue
+)
+
+// Rules of the privileged auditor
+const (
+	PrivilegedTrue      ID = privileged.PrivilegedTrue
+	PrivilegedNil       ID = privileged.PrivilegedNil
+	PrivilegedSuspended ID = privileged.PrivilegedSuspended
+)
+
+// Rules of the pss auditor
+const (
+	PSSBaselineHostProcess            ID = pss.PSSBaselineHostProcess
+	PSSBaselineHostNamespaces         ID = pss.PSSBaselineHostNamespaces
+	PSSBaselinePrivilegedContainers   ID = pss.PSSBaselinePrivilegedContainers
+	PSSBaselineCapabilities           ID = pss.PSSBaselineCapabilities
+	PSSBaselineHostPathVolumes        ID = pss.PSSBaselineHostPathVolumes
+	PSSBaselineHostPorts              ID = pss.PSSBaselineHostPorts
+	PSSBaselineAppArmor               ID = pss.PSSBaselineAppArmor
+	PSSBaselineSELinux                ID = pss.PSSBaselineSELinux
+	PSSBaselineProcMount              ID = pss.PSSBaselineProcMount
+	PSSBaselineSeccomp                ID = pss.PSSBaselineSeccomp
+	PSSBaselineSysctls                ID = pss.PSSBaselineSysctls
+	PSSRestrictedVolumeTypes          ID = pss.PSSRestrictedVolumeTypes
+	PSSRestrictedPrivilegeEscalation  ID = pss.PSSRestrictedPrivilegeEscalation
+	PSSRestrictedRunningAsNonRoot     ID = pss.PSSRestrictedRunningAsNonRoot
+	PSSRestrictedRunningAsNonRootUser ID = pss.PSSRestrictedRunningAsNonRootUser
+	PSSRestrictedSeccomp              ID = pss.PSSRestrictedSeccomp
+	PSSRestrictedCapabilities         ID = pss.PSSRestrictedCapabilities
+	PodSecurityStandardLevel          ID = pss.PodSecurityStandardLevel
+	PSSNamespaceEnforceMissing        ID = pss.PSSNamespaceEnforceMissing
+	PSSNamespaceEnforceLevelTooLow    ID = pss.PSSNamespaceEnforceLevelTooLow
+)
+
+// Rules of the rbac auditor
+const (
+	AggregationRuleSelectsAllClusterRoles       ID = rbac.AggregationRuleSelectsAllClusterRoles
+	AggregationRuleSelectsEscalatingClusterRole ID = rbac.AggregationRuleSelectsEscalatingClusterRole
+	RoleGrantsBind                              ID = rbac.RoleGrantsBind
+	RoleGrantsEscalate                          ID = rbac.RoleGrantsEscalate
+	RoleGrantsImpersonate                       ID = rbac.RoleGrantsImpersonate
+	ServiceAccountBoundToClusterAdmin           ID = rbac.ServiceAccountBoundToClusterAdmin
+	ServiceAccountGrantedWildcard               ID = rbac.ServiceAccountGrantedWildcard
+	ServiceAccountCanReadSecretsInAllNamespaces ID = rbac.ServiceAccountCanReadSecretsInAllNamespaces
+)
+
+// Rules of the requests auditor
+const (
+	RequestsNotSet              ID = requests.RequestsNotSet
+	RequestsCPUNotSet           ID = requests.RequestsCPUNotSet
+	RequestsMemoryNotSet        ID = requests.RequestsMemoryNotSet
+	RequestsCPUExceedsLimit     ID = requests.RequestsCPUExceedsLimit
+	RequestsMemoryExceedsLimit  ID = requests.RequestsMemoryExceedsLimit
+	RequestsCPURatioExceeded    ID = requests.RequestsCPURatioExceeded
+	RequestsMemoryRatioExceeded ID = requests.RequestsMemoryRatioExceeded
+)
+
+// Rules of the resilience auditor
+const (
+	SingleReplicaInProduction        ID = resilience.SingleReplicaInProduction
+	ReplicasPinnedToSingleNode       ID = resilience.ReplicasPinnedToSingleNode
+	ReplicasPinnedToSingleZone       ID = resilience.ReplicasPinnedToSingleZone
+	TopologySpreadConstraintsMissing ID = resilience.TopologySpreadConstraintsMissing
+	PodDisruptionBudgetMissing       ID = resilience.PodDisruptionBudgetMissing
+	LivenessProbeMissing             ID = resilience.LivenessProbeMissing
+	ReadinessProbeMissing            ID = resilience.ReadinessProbeMissing
+)
+
+// Rules of the rootfs auditor
+const (
+	ReadOnlyRootFilesystemFalse ID = rootfs.ReadOnlyRootFilesystemFalse
+	ReadOnlyRootFilesystemNil   ID = rootfs.ReadOnlyRootFilesystemNil
+)
+
+// Rules of the runtimeclass auditor
+const (
+	RuntimeClassNotAllowed ID = runtimeclass.RuntimeClassNotAllowed
+	RuntimeClassRequired   ID = runtimeclass.RuntimeClassRequired
+)
+
+// Rules of the seccomp auditor
+const (
+	SeccompDeprecatedAnnotations ID = seccomp.SeccompDeprecatedAnnotations
+	SeccompProfileMissing        ID = seccomp.SeccompProfileMissing
+	SeccompDisabledPod           ID = seccomp.SeccompDisabledPod
+	SeccompDisabledContainer     ID = seccomp.SeccompDisabledContainer
+)
+
+// Rules of the secrets auditor
+const (
+	SecretEnvVarRef     ID = secrets.SecretEnvVarRef
+	SecretEnvFromRef    ID = secrets.SecretEnvFromRef
+	SecretEnvVarLiteral ID = secrets.SecretEnvVarLiteral
+	SecretInAnnotation  ID = secrets.SecretInAnnotation
+)
+
+// Rules of the volumes auditor
+const (
+	LegacyServiceAccountTokenMounted     ID = volumes.LegacyServiceAccountTokenMounted
+	ServiceAccountTokenExpirationTooLong ID = volumes.ServiceAccountTokenExpirationTooLong
+	CSIInlineVolumeNotAllowed            ID = volumes.CSIInlineVolumeNotAllowed
+	SecretVolumeWorldReadable            ID = volumes.SecretVolumeWorldReadable
+)
+
+// Rules of the vulns auditor
+const (
+	CriticalVulnerability ID = vulns.CriticalVulnerability
+	HighVulnerability     ID = vulns.HighVulnerability
+	MediumVulnerability   ID = vulns.MediumVulnerability
+	LowVulnerability      ID = vulns.LowVulnerability
+	ImageScanFailed       ID = vulns.ImageScanFailed
+	ImageScanSkipped      ID = vulns.ImageScanSkipped
+)
+
+// Rule is a rule and the auditor which reports it
+type Rule struct {
+	ID ID
+	// Auditor is the name of the auditor which reports the rule, or empty if any auditor can report it
+	Auditor string
+	// Severity is the severity the rule is reported with by default. Some rules are reported with another severity
+	// depending on the config of their auditor or on the resource
+	Severity kubeaudit.SeverityLevel
+	// Description is what the rule reports
+	Description string
+	// OverrideLabel is the label which overrides the rule, without the "kubeaudit.io/" or
+	// "container.kubeaudit.io/<container>." prefix, or empty if the rule can't be overridden. Placeholders in angle
+	// brackets are replaced by the name of what is allowed, such as "allow-capability-<capability>"
+	OverrideLabel string
+	// Fixable is true if autofix fixes the rule, at least for some resources
+	Fixable bool
+}
+
+var registry = []Rule{
+	{ID: RedundantAuditorOverride, Severity: kubeaudit.Warn, Description: "An override label is set but the auditor it disables found no issue"},
+	{ID: ExpiredAuditorOverride, Severity: kubeaudit.Warn, Description: "The expiry date of an override label has passed, so it no longer overrides the results of the auditor"},
+	{ID: AuditorPanic, Severity: kubeaudit.Error, Description: "An auditor panicked while auditing the resource, so the resource was not fully audited"},
+	{ID: AuditorError, Severity: kubeaudit.Error, Description: "An auditor failed to audit the resource, so the resource was not fully audited"},
+	{ID: ManifestSyntaxError, Auditor: kubeaudit.ManifestAuditor, Severity: kubeaudit.Error, Description: "A document of the manifest is not valid YAML"},
+	{ID: ManifestUnknownField, Auditor: kubeaudit.ManifestAuditor, Severity: kubeaudit.Error, Description: "A field of the resource is unknown to its schema"},
+	{ID: ManifestInvalidType, Auditor: kubeaudit.ManifestAuditor, Severity: kubeaudit.Error, Description: "A field of the resource has the wrong type for its schema"},
+	{ID: AnnotationMissing, Auditor: annotations.Name, Severity: kubeaudit.Error, Description: "A required annotation is missing", OverrideLabel: annotations.OverrideLabel},
+	{ID: AnnotationValueInvalid, Auditor: annotations.Name, Severity: kubeaudit.Error, Description: "An annotation has a value which doesn't match the configured pattern", OverrideLabel: annotations.OverrideLabel},
+	{ID: AnnotationForbidden, Auditor: annotations.Name, Severity: kubeaudit.Error, Description: "A forbidden annotation is set", OverrideLabel: annotations.OverrideLabel},
+	{ID: AppArmorAnnotationMissing, Auditor: apparmor.Name, Severity: kubeaudit.Error, Description: "The AppArmor profile of a container is not set", OverrideLabel: apparmor.OverrideLabel, Fixable: true},
+	{ID: AppArmorDisabled, Auditor: apparmor.Name, Severity: kubeaudit.Error, Description: "AppArmor is disabled for a container", OverrideLabel: apparmor.OverrideLabel, Fixable: true},
+	{ID: AppArmorBadValue, Auditor: apparmor.Name, Severity: kubeaudit.Error, Description: "The AppArmor profile of a container has an invalid value", OverrideLabel: apparmor.OverrideLabel, Fixable: true},
+	{ID: AppArmorInvalidAnnotation, Auditor: apparmor.Name, Severity: kubeaudit.Error, Description: "An AppArmor annotation refers to a container which doesn't exist", OverrideLabel: apparmor.OverrideLabel, Fixable: true},
+	{ID: AutomountServiceAccountTokenDeprecated, Auditor: asat.Name, Severity: kubeaudit.Warn, Description: "The deprecated serviceAccount field is used instead of serviceAccountName", OverrideLabel: asat.OverrideLabel, Fixable: true},
+	{ID: AutomountServiceAccountTokenTrueAndDefaultSA, Auditor: asat.Name, Severity: kubeaudit.Error, Description: "The token of the default service account is mounted in the pod", OverrideLabel: asat.OverrideLabel, Fixable: true},
+	{ID: AutomountServiceAccountTokenTrueInNamespaceDefaultSA, Auditor: asat.Name, Severity: kubeaudit.Warn, Description: "The default service account of a namespace automounts its token", OverrideLabel: asat.OverrideLabel, Fixable: true},
+	{ID: CapabilityAdded, Auditor: capabilities.Name, Severity: kubeaudit.Error, Description: "A capability is added to a container", OverrideLabel: "allow-capability-<capability>", Fixable: true},
+	{ID: CapabilityShouldDropAll, Auditor: capabilities.Name, Severity: kubeaudit.Error, Description: "A container doesn't drop all capabilities", Fixable: true},
+	{ID: CapabilityOrSecurityContextMissing, Auditor: capabilities.Name, Severity: kubeaudit.Error, Description: "A container has no security context or capabilities, so it doesn't drop all capabilities", Fixable: true},
+	{ID: CapabilityProfileUnknown, Auditor: capabilities.Name, Severity: kubeaudit.Error, Description: "A container selects a capability profile which is not in the config"},
+	{ID: DeprecatedAPIUsed, Auditor: deprecatedapis.Name, Severity: kubeaudit.Warn, Description: "The resource uses a deprecated API version", OverrideLabel: deprecatedapis.OverrideLabel},
+	{ID: NamespaceEgressUnrestricted, Auditor: egress.Name, Severity: kubeaudit.Error, Description: "No network policy restricts the egress traffic of a namespace", OverrideLabel: egress.OverrideLabel, Fixable: true},
+	{ID: WorkloadEgressUnrestricted, Auditor: egress.Name, Severity: kubeaudit.Warn, Description: "No network policy restricts the egress traffic of a workload", OverrideLabel: egress.OverrideLabel},
+	{ID: EphemeralContainerInProduction, Auditor: ephemeral.Name, Severity: kubeaudit.Warn, Description: "An ephemeral debug container runs in a production namespace", OverrideLabel: ephemeral.OverrideLabel},
+	{ID: SecretsEncryptionAtRestDisabled, Auditor: etcd.Name, Severity: kubeaudit.Error, Description: "The kube-apiserver doesn't encrypt secrets at rest", OverrideLabel: etcd.OverrideLabel},
+	{ID: SecretsStoredUnencrypted, Auditor: etcd.Name, Severity: kubeaudit.Error, Description: "The encryption config of the kube-apiserver stores secrets unencrypted", OverrideLabel: etcd.OverrideLabel},
+	{ID: EtcdConnectionInsecure, Auditor: etcd.Name, Severity: kubeaudit.Error, Description: "The kube-apiserver connects to etcd without TLS client certificates", OverrideLabel: etcd.OverrideLabel},
+	{ID: EtcdClientCertAuthDisabled, Auditor: etcd.Name, Severity: kubeaudit.Error, Description: "etcd doesn't authenticate clients with certificates", OverrideLabel: etcd.OverrideLabel},
+	{ID: EtcdClientURLInsecure, Auditor: etcd.Name, Severity: kubeaudit.Error, Description: "etcd accepts client connections without TLS", OverrideLabel: etcd.OverrideLabel},
+	{ID: EtcdPeerCertAuthDisabled, Auditor: etcd.Name, Severity: kubeaudit.Warn, Description: "etcd doesn't authenticate peers with certificates", OverrideLabel: etcd.OverrideLabel},
+	{ID: ServiceNodePortExposed, Auditor: exposure.Name, Severity: kubeaudit.Warn, Description: "The Service of type NodePort exposes its ports on every node", OverrideLabel: exposure.OverrideLabel},
+	{ID: ServiceSourceRangesMissing, Auditor: exposure.Name, Severity: kubeaudit.Warn, Description: "The Service of type LoadBalancer accepts clients from any address", OverrideLabel: exposure.OverrideLabel},
+	{ID: IngressTLSMissing, Auditor: exposure.Name, Severity: kubeaudit.Warn, Description: "The Ingress serves hosts over plain HTTP", OverrideLabel: exposure.OverrideLabel},
+	{ID: HostPortSet, Auditor: hostnet.Name, Severity: kubeaudit.Error, Description: "A container binds a port of the host", OverrideLabel: hostnet.HostPortOverrideLabel, Fixable: true},
+	{ID: HostAliasesSet, Auditor: hostnet.Name, Severity: kubeaudit.Warn, Description: "The pod adds entries to its hosts file with hostAliases", OverrideLabel: hostnet.HostAliasesOverrideLabel},
+	{ID: DNSPolicyHostNetWithoutHostNetwork, Auditor: hostnet.Name, Severity: kubeaudit.Warn, Description: "The pod uses the ClusterFirstWithHostNet DNS policy without the host network", OverrideLabel: hostnet.DNSPolicyOverrideLabel, Fixable: true},
+	{ID: NamespaceHostNetworkTrue, Auditor: hostns.Name, Severity: kubeaudit.Error, Description: "The pod uses the network namespace of the host", OverrideLabel: hostns.HostNetworkOverrideLabel, Fixable: true},
+	{ID: NamespaceHostIPCTrue, Auditor: hostns.Name, Severity: kubeaudit.Error, Description: "The pod uses the IPC namespace of the host", OverrideLabel: hostns.HostIPCOverrideLabel, Fixable: true},
+	{ID: NamespaceHostPIDTrue, Auditor: hostns.Name, Severity: kubeaudit.Error, Description: "The pod uses the PID namespace of the host", OverrideLabel: hostns.HostPIDOverrideLabel, Fixable: true},
+	{ID: HostProcessTruePod, Auditor: hostprocess.Name, Severity: kubeaudit.Error, Description: "The Windows pod runs as a HostProcess container on the host", OverrideLabel: hostprocess.OverrideLabel, Fixable: true},
+	{ID: HostProcessTrueContainer, Auditor: hostprocess.Name, Severity: kubeaudit.Error, Description: "A Windows container runs as a HostProcess container on the host", OverrideLabel: hostprocess.OverrideLabel, Fixable: true},
+	{ID: ImageTagMissing, Auditor: image.Name, Severity: kubeaudit.Warn, Description: "The image of a container has no tag", OverrideLabel: image.OverrideLabel},
+	{ID: ImageTagIncorrect, Auditor: image.Name, Severity: kubeaudit.Error, Description: "The image of a container doesn't have the configured tag", OverrideLabel: image.OverrideLabel},
+	{ID: ImageCorrect, Auditor: image.Name, Severity: kubeaudit.Info, Description: "The image of a container has the configured tag", OverrideLabel: image.OverrideLabel},
+	{ID: ImageRunsAsRoot, Auditor: image.Name, Severity: kubeaudit.Warn, Description: "The image of a container runs as root by default", OverrideLabel: image.OverrideLabel},
+	{ID: ImageRunAsNonRootConflict, Auditor: image.Name, Severity: kubeaudit.Error, Description: "A container requires a non-root user but its image runs as root, so it won't start", OverrideLabel: image.OverrideLabel},
+	{ID: ImageHealthcheckIgnored, Auditor: image.Name, Severity: kubeaudit.Warn, Description: "The image of a container has a healthcheck which Kubernetes ignores, and the container has no probe", OverrideLabel: image.OverrideLabel},
+	{ID: ImageShellMissing, Auditor: image.Name, Severity: kubeaudit.Warn, Description: "A container runs a shell command but its image has no shell", OverrideLabel: image.OverrideLabel},
+	{ID: ImageArchitectureMismatch, Auditor: image.Name, Severity: kubeaudit.Error, Description: "The image of a container isn't built for the architecture of the nodes it is scheduled on", OverrideLabel: image.OverrideLabel},
+	{ID: ImageInspectionFailed, Auditor: image.Name, Severity: kubeaudit.Info, Description: "The image of a container could not be inspected", OverrideLabel: image.OverrideLabel},
+	{ID: ImageInspectionSkipped, Auditor: image.Name, Severity: kubeaudit.Info, Description: "The image of a container was not inspected since kubeaudit runs offline", OverrideLabel: image.OverrideLabel},
+	{ID: ImageRegistryNotAllowed, Auditor: imagepolicy.Name, Severity: kubeaudit.Error, Description: "The image of a container is pulled from a registry which is not allowed", OverrideLabel: imagepolicy.OverrideLabel},
+	{ID: ImageTagLatest, Auditor: imagepolicy.Name, Severity: kubeaudit.Error, Description: "The image of a container uses the latest tag", OverrideLabel: imagepolicy.OverrideLabel},
+	{ID: ImageDigestMissing, Auditor: imagepolicy.Name, Severity: kubeaudit.Warn, Description: "The image of a container is not pinned to a digest", OverrideLabel: imagepolicy.OverrideLabel},
+	{ID: LabelMissing, Auditor: labels.Name, Severity: kubeaudit.Error, Description: "A required label is missing", OverrideLabel: labels.OverrideLabel, Fixable: true},
+	{ID: LabelValueInvalid, Auditor: labels.Name, Severity: kubeaudit.Error, Description: "A label has a value which doesn't match the configured pattern", OverrideLabel: labels.OverrideLabel},
+	{ID: LabelPlaceholderValue, Auditor: labels.Name, Severity: kubeaudit.Warn, Description: "A label has the placeholder value set by autofix", OverrideLabel: labels.OverrideLabel},
+	{ID: TerminationGracePeriodZero, Auditor: lifecycle.Name, Severity: kubeaudit.Warn, Description: "The pod is killed without a grace period", OverrideLabel: lifecycle.OverrideLabel, Fixable: true},
+	{ID: RestartPolicyNotAlways, Auditor: lifecycle.Name, Severity: kubeaudit.Error, Description: "A long-running workload doesn't restart its containers always", OverrideLabel: lifecycle.OverrideLabel, Fixable: true},
+	{ID: ActiveDeadlineSecondsSet, Auditor: lifecycle.Name, Severity: kubeaudit.Error, Description: "A long-running workload sets activeDeadlineSeconds, so its pods are killed", OverrideLabel: lifecycle.OverrideLabel, Fixable: true},
+	{ID: JobTTLSecondsAfterFinishedNil, Auditor: lifecycle.Name, Severity: kubeaudit.Warn, Description: "A job is never cleaned up after it finishes", OverrideLabel: lifecycle.OverrideLabel},
+	{ID: LimitsNotSet, Auditor: limits.Name, Severity: kubeaudit.Warn, Description: "A container has no resource limits", OverrideLabel: limits.OverrideLabel},
+	{ID: LimitsCPUNotSet, Auditor: limits.Name, Severity: kubeaudit.Warn, Description: "A container has no CPU limit", OverrideLabel: limits.OverrideLabel},
+	{ID: LimitsMemoryNotSet, Auditor: limits.Name, Severity: kubeaudit.Warn, Description: "A container has no memory limit", OverrideLabel: limits.OverrideLabel},
+	{ID: LimitsCPUExceeded, Auditor: limits.Name, Severity: kubeaudit.Warn, Description: "The CPU limit of a container exceeds the configured maximum", OverrideLabel: limits.OverrideLabel},
+	{ID: LimitsMemoryExceeded, Auditor: limits.Name, Severity: kubeaudit.Warn, Description: "The memory limit of a container exceeds the configured maximum", OverrideLabel: limits.OverrideLabel},
+	{ID: SensitivePathsMounted, Auditor: mounts.Name, Severity: kubeaudit.Error, Description: "A sensitive path of the host is mounted in a container", OverrideLabel: "allow-host-path-mount-<volume>"},
+	{ID: MissingDefaultDenyIngressAndEgressNetworkPolicy, Auditor: netpols.Name, Severity: kubeaudit.Error, Description: "A namespace has no default-deny network policy for ingress and egress traffic", OverrideLabel: netpols.IngressOverrideLabel, Fixable: true},
+	{ID: MissingDefaultDenyIngressNetworkPolicy, Auditor: netpols.Name, Severity: kubeaudit.Error, Description: "A namespace has no default-deny network policy for ingress traffic", OverrideLabel: netpols.IngressOverrideLabel, Fixable: true},
+	{ID: MissingDefaultDenyEgressNetworkPolicy, Auditor: netpols.Name, Severity: kubeaudit.Error, Description: "A namespace has no default-deny network policy for egress traffic", OverrideLabel: netpols.EgressOverrideLabel, Fixable: true},
+	{ID: AllowAllIngressNetworkPolicyExists, Auditor: netpols.Name, Severity: kubeaudit.Warn, Description: "A network policy of a namespace allows all ingress traffic", OverrideLabel: netpols.IngressOverrideLabel},
+	{ID: AllowAllEgressNetworkPolicyExists, Auditor: netpols.Name, Severity: kubeaudit.Warn, Description: "A network policy of a namespace allows all egress traffic", OverrideLabel: netpols.EgressOverrideLabel},
+	{ID: SecurityAgentMissing, Auditor: nodecoverage.Name, Severity: kubeaudit.Error, Description: "A configured security-critical DaemonSet doesn't exist", OverrideLabel: nodecoverage.OverrideLabel},
+	{ID: NodeNotCoveredBySecurityAgent, Auditor: nodecoverage.Name, Severity: kubeaudit.Error, Description: "A node doesn't run a pod of a configured security-critical DaemonSet", OverrideLabel: nodecoverage.OverrideLabel},
+	{ID: SecurityAgentNotToleratingAllTaints, Auditor: nodecoverage.Name, Severity: kubeaudit.Warn, Description: "A security-critical DaemonSet doesn't tolerate all taints, so it may not run on every node"},
+	{ID: RunAsUserCSCRoot, Auditor: nonroot.Name, Severity: kubeaudit.Error, Description: "The security context of a container runs it as the root user", OverrideLabel: nonroot.OverrideLabel, Fixable: true},
+	{ID: RunAsUserPSCRoot, Auditor: nonroot.Name, Severity: kubeaudit.Error, Description: "The security context of the pod runs its containers as the root user", OverrideLabel: nonroot.OverrideLabel, Fixable: true},
+	{ID: RunAsNonRootCSCFalse, Auditor: nonroot.Name, Severity: kubeaudit.Error, Description: "The security context of a container allows it to run as root", OverrideLabel: nonroot.OverrideLabel, Fixable: true},
+	{ID: RunAsNonRootPSCNilCSCNil, Auditor: nonroot.Name, Severity: kubeaudit.Error, Description: "Neither the pod nor a container requires a non-root user", OverrideLabel: nonroot.OverrideLabel, Fixable: true},
+	{ID: RunAsNonRootPSCFalseCSCNil, Auditor: nonroot.Name, Severity: kubeaudit.Error, Description: "The security context of the pod allows its containers to run as root", OverrideLabel: nonroot.OverrideLabel, Fixable: true},
+	{ID: PrivilegedPortExposed, Auditor: ports.Name, Severity: kubeaudit.Warn, Description: "A container exposes a privileged port, below 1024", OverrideLabel: ports.OverrideLabel},
+	{ID: ForbiddenPortExposed, Auditor: ports.Name, Severity: kubeaudit.Error, Description: "A container exposes a forbidden port", OverrideLabel: ports.OverrideLabel},
+	{ID: ServiceTargetPortUndeclared, Auditor: ports.Name, Severity: kubeaudit.Warn, Description: "A service targets a port which no container of its pods declares", OverrideLabel: ports.OverrideLabel},
+	{ID: AllowPrivilegeEscalationNil, Auditor: privesc.Name, Severity: kubeaudit.Error, Description: "A container doesn't disallow privilege escalation", OverrideLabel: privesc.OverrideLabel, Fixable: true},
+	{ID: AllowPrivilegeEscalationTrue, Auditor: privesc.Name, Severity: kubeaudit.Error, Description: "A container allows privilege escalation", OverrideLabel: privesc.OverrideLabel, Fixable: true},
+	{ID: PrivilegedTrue, Auditor: privileged.Name, Severity: kubeaudit.Error, Description: "A container runs as privileged", OverrideLabel: privileged.OverrideLabel, Fixable: true},
+	{ID: PrivilegedNil, Auditor: privileged.Name, Severity: kubeaudit.Warn, Description: "A container doesn't set privileged to false", OverrideLabel: privileged.OverrideLabel, Fixable: true},
+	{ID: PrivilegedSuspended, Auditor: privileged.Name, Severity: kubeaudit.Warn, Description: "A suspended CronJob or Job runs a privileged container once it is resumed", OverrideLabel: privileged.OverrideLabel},
+	{ID: PSSBaselineHostProcess, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the HostProcess control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineHostNamespaces, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Host Namespaces control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselinePrivilegedContainers, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Privileged Containers control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineCapabilities, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Capabilities control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineHostPathVolumes, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the HostPath Volumes control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineHostPorts, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Host Ports control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineAppArmor, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the AppArmor control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineSELinux, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the SELinux control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineProcMount, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the /proc Mount Type control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineSeccomp, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Seccomp control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSBaselineSysctls, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Sysctls control of the baseline Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSRestrictedVolumeTypes, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Volume Types control of the restricted Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSRestrictedPrivilegeEscalation, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Privilege Escalation control of the restricted Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSRestrictedRunningAsNonRoot, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Running as Non-root control of the restricted Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSRestrictedRunningAsNonRootUser, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Running as Non-root user control of the restricted Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSRestrictedSeccomp, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Seccomp control of the restricted Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSRestrictedCapabilities, Auditor: pss.Name, Severity: kubeaudit.Error, Description: "The pod fails the Capabilities control of the restricted Pod Security Standards level", OverrideLabel: pss.OverrideLabel},
+	{ID: PodSecurityStandardLevel, Auditor: pss.Name, Severity: kubeaudit.Info, Description: "The highest Pod Security Standards level the workload satisfies"},
+	{ID: PSSNamespaceEnforceMissing, Auditor: pss.Name, Severity: kubeaudit.Warn, Description: "The namespace doesn't enforce a Pod Security Standards level with Pod Security admission", OverrideLabel: pss.OverrideLabel},
+	{ID: PSSNamespaceEnforceLevelTooLow, Auditor: pss.Name, Severity: kubeaudit.Warn, Description: "The namespace enforces a less restrictive Pod Security Standards level than required", OverrideLabel: pss.OverrideLabel},
+	{ID: AggregationRuleSelectsAllClusterRoles, Auditor: rbac.Name, Severity: kubeaudit.Error, Description: "The aggregation rule of a ClusterRole selects all ClusterRoles", OverrideLabel: rbac.OverrideLabel},
+	{ID: AggregationRuleSelectsEscalatingClusterRole, Auditor: rbac.Name, Severity: kubeaudit.Error, Description: "The aggregation rule of a ClusterRole selects a ClusterRole which allows privilege escalation", OverrideLabel: rbac.OverrideLabel},
+	{ID: RoleGrantsBind, Auditor: rbac.Name, Severity: kubeaudit.Error, Description: "A role grants the bind verb, which allows binding roles with more permissions", OverrideLabel: rbac.OverrideLabel},
+	{ID: RoleGrantsEscalate, Auditor: rbac.Name, Severity: kubeaudit.Error, Description: "A role grants the escalate verb, which allows granting more permissions", OverrideLabel: rbac.OverrideLabel},
+	{ID: RoleGrantsImpersonate, Auditor: rbac.Name, Severity: kubeaudit.Error, Description: "A role grants the impersonate verb, which allows acting as other users", OverrideLabel: rbac.OverrideLabel},
+	{ID: ServiceAccountBoundToClusterAdmin, Auditor: rbac.Name, Severity: kubeaudit.Error, Description: "The service account of a workload is bound to cluster-admin", OverrideLabel: rbac.ServiceAccountOverrideLabel},
+	{ID: ServiceAccountGrantedWildcard, Auditor: rbac.Name, Severity: kubeaudit.Error, Description: "The service account of a workload is granted all verbs or resources", OverrideLabel: rbac.ServiceAccountOverrideLabel},
+	{ID: ServiceAccountCanReadSecretsInAllNamespaces, Auditor: rbac.Name, Severity: kubeaudit.Error, Description: "The service account of a workload can read the secrets of all namespaces", OverrideLabel: rbac.ServiceAccountOverrideLabel},
+	{ID: RequestsNotSet, Auditor: requests.Name, Severity: kubeaudit.Warn, Description: "A container doesn't request CPU and memory", OverrideLabel: requests.OverrideLabel, Fixable: true},
+	{ID: RequestsCPUNotSet, Auditor: requests.Name, Severity: kubeaudit.Warn, Description: "A container doesn't request CPU", OverrideLabel: requests.OverrideLabel, Fixable: true},
+	{ID: RequestsMemoryNotSet, Auditor: requests.Name, Severity: kubeaudit.Warn, Description: "A container doesn't request memory", OverrideLabel: requests.OverrideLabel, Fixable: true},
+	{ID: RequestsCPUExceedsLimit, Auditor: requests.Name, Severity: kubeaudit.Error, Description: "The CPU request of a container exceeds its limit", OverrideLabel: requests.OverrideLabel, Fixable: true},
+	{ID: RequestsMemoryExceedsLimit, Auditor: requests.Name, Severity: kubeaudit.Error, Description: "The memory request of a container exceeds its limit", OverrideLabel: requests.OverrideLabel, Fixable: true},
+	{ID: RequestsCPURatioExceeded, Auditor: requests.Name, Severity: kubeaudit.Warn, Description: "The CPU limit of a container is more than the configured ratio of its request", OverrideLabel: requests.OverrideLabel},
+	{ID: RequestsMemoryRatioExceeded, Auditor: requests.Name, Severity: kubeaudit.Warn, Description: "The memory limit of a container is more than the configured ratio of its request", OverrideLabel: requests.OverrideLabel},
+	{ID: SingleReplicaInProduction, Auditor: resilience.Name, Severity: kubeaudit.Warn, Description: "A workload in a production namespace has a single replica", OverrideLabel: resilience.OverrideLabel},
+	{ID: ReplicasPinnedToSingleNode, Auditor: resilience.Name, Severity: kubeaudit.Warn, Description: "The replicas of a workload are pinned to a single node", OverrideLabel: resilience.OverrideLabel},
+	{ID: ReplicasPinnedToSingleZone, Auditor: resilience.Name, Severity: kubeaudit.Warn, Description: "The replicas of a workload are pinned to a single zone", OverrideLabel: resilience.OverrideLabel},
+	{ID: TopologySpreadConstraintsMissing, Auditor: resilience.Name, Severity: kubeaudit.Warn, Description: "A replicated workload doesn't spread its replicas across nodes and zones", OverrideLabel: resilience.OverrideLabel},
+	{ID: PodDisruptionBudgetMissing, Auditor: resilience.Name, Severity: kubeaudit.Warn, Description: "No PodDisruptionBudget selects the pods of a replicated workload", OverrideLabel: resilience.OverrideLabel},
+	{ID: LivenessProbeMissing, Auditor: resilience.Name, Severity: kubeaudit.Warn, Description: "A container has no liveness probe", OverrideLabel: resilience.OverrideLabel},
+	{ID: ReadinessProbeMissing, Auditor: resilience.Name, Severity: kubeaudit.Warn, Description: "A container has no readiness probe", OverrideLabel: resilience.OverrideLabel},
+	{ID: ReadOnlyRootFilesystemFalse, Auditor: rootfs.Name, Severity: kubeaudit.Error, Description: "The root filesystem of a container is writable", OverrideLabel: rootfs.OverrideLabel, Fixable: true},
+	{ID: ReadOnlyRootFilesystemNil, Auditor: rootfs.Name, Severity: kubeaudit.Error, Description: "A container doesn't set its root filesystem read-only", OverrideLabel: rootfs.OverrideLabel, Fixable: true},
+	{ID: RuntimeClassNotAllowed, Auditor: runtimeclass.Name, Severity: kubeaudit.Error, Description: "The pod uses a runtime class which is not allowed", OverrideLabel: runtimeclass.OverrideLabel},
+	{ID: RuntimeClassRequired, Auditor: runtimeclass.Name, Severity: kubeaudit.Error, Description: "The pod doesn't use one of the runtime classes required in its namespace", OverrideLabel: runtimeclass.OverrideLabel},
+	{ID: SeccompDeprecatedAnnotations, Auditor: seccomp.Name, Severity: kubeaudit.Warn, Description: "The seccomp profile is set with deprecated annotations", OverrideLabel: seccomp.OverrideLabel, Fixable: true},
+	{ID: SeccompProfileMissing, Auditor: seccomp.Name, Severity: kubeaudit.Error, Description: "The pod has no seccomp profile", OverrideLabel: seccomp.OverrideLabel, Fixable: true},
+	{ID: SeccompDisabledPod, Auditor: seccomp.Name, Severity: kubeaudit.Error, Description: "Seccomp is disabled for the pod", OverrideLabel: seccomp.OverrideLabel, Fixable: true},
+	{ID: SeccompDisabledContainer, Auditor: seccomp.Name, Severity: kubeaudit.Error, Description: "Seccomp is disabled for a container", OverrideLabel: seccomp.OverrideLabel, Fixable: true},
+	{ID: SecretEnvVarRef, Auditor: secrets.Name, Severity: kubeaudit.Warn, Description: "A secret is injected into an environment variable of a container", OverrideLabel: secrets.SecretRefOverrideLabel},
+	{ID: SecretEnvFromRef, Auditor: secrets.Name, Severity: kubeaudit.Warn, Description: "All the keys of a secret are injected into the environment of a container", OverrideLabel: secrets.SecretRefOverrideLabel},
+	{ID: SecretEnvVarLiteral, Auditor: secrets.Name, Severity: kubeaudit.Error, Description: "An environment variable of a container has a literal value which looks like a secret", OverrideLabel: secrets.SecretLiteralOverrideLabel},
+	{ID: SecretInAnnotation, Auditor: secrets.Name, Severity: kubeaudit.Error, Description: "An annotation has a value which looks like a secret", OverrideLabel: secrets.SecretAnnotationOverrideLabel},
+	{ID: LegacyServiceAccountTokenMounted, Auditor: volumes.Name, Severity: kubeaudit.Error, Description: "The long-lived token Secret of a service account is mounted in the pod", OverrideLabel: "allow-volume-<volume>", Fixable: true},
+	{ID: ServiceAccountTokenExpirationTooLong, Auditor: volumes.Name, Severity: kubeaudit.Warn, Description: "A projected service account token expires after more than the maximum expiration", OverrideLabel: "allow-volume-<volume>", Fixable: true},
+	{ID: CSIInlineVolumeNotAllowed, Auditor: volumes.Name, Severity: kubeaudit.Warn, Description: "The pod has an inline CSI volume of a driver which is not allowed", OverrideLabel: "allow-volume-<volume>"},
+	{ID: SecretVolumeWorldReadable, Auditor: volumes.Name, Severity: kubeaudit.Warn, Description: "The files of a Secret volume can be read by any user", OverrideLabel: "allow-volume-<volume>", Fixable: true},
+	{ID: CriticalVulnerability, Auditor: vulns.Name, Severity: kubeaudit.Error, Description: "The image of a container has a critical vulnerability", OverrideLabel: vulns.OverrideLabel},
+	{ID: HighVulnerability, Auditor: vulns.Name, Severity: kubeaudit.Warn, Description: "The image of a container has a high severity vulnerability", OverrideLabel: vulns.OverrideLabel},
+	{ID: MediumVulnerability, Auditor: vulns.Name, Severity: kubeaudit.Info, Description: "The image of a container has a medium severity vulnerability", OverrideLabel: vulns.OverrideLabel},
+	{ID: LowVulnerability, Auditor: vulns.Name, Severity: kubeaudit.Info, Description: "The image of a container has a low severity vulnerability", OverrideLabel: vulns.OverrideLabel},
+	{ID: ImageScanFailed, Auditor: vulns.Name, Severity: kubeaudit.Info, Description: "The image of a container could not be scanned", OverrideLabel: vulns.OverrideLabel},
+	{ID: ImageScanSkipped, Auditor: vulns.Name, Severity: kubeaudit.Info, Description: "The image of a container was not scanned since kubeaudit runs offline", OverrideLabel: vulns.OverrideLabel},
+}
+
+// Rules returns the rules of the built-in auditors, sorted by auditor and then by ID. The rules of the rego auditor are
+// named after the packages of the Rego policies, and the rules of plugins are defined by the plugins, so neither are
+// part of the registry
+func Rules() []Rule {
+	rules := make([]Rule, len(registry))
+	copy(rules, registry)
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Auditor != rules[j].Auditor {
+			return rules[i].Auditor < rules[j].Auditor
+		}
+		return rules[i].ID < rules[j].ID
+	})
+	return rules
+}
+
+// Lookup returns the rule with the name, or false if it is not a rule of the built-in auditors. Overridden rule names,
+// ending in "Allowed", are looked up as the rule they override
+func Lookup(name string) (Rule, bool) {
+	for _, rule := range registry {
+		if name == string(rule.ID) || name == string(rule.ID.Allowed()) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
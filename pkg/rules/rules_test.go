@@ -0,0 +1,122 @@
+package rules
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/auditors/rego"
+	"github.com/Shopify/kubeaudit/auditors/vulns"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRules(t *testing.T) {
+	ids := make(map[ID]bool)
+	auditors := make(map[string]bool)
+	for _, rule := range Rules() {
+		assert.False(t, ids[rule.ID], "rule %s is registered twice", rule.ID)
+		ids[rule.ID] = true
+		auditors[rule.Auditor] = true
+	}
+
+	for _, auditorName := range append(all.AuditorNames, all.OptionalAuditorNames...) {
+		if auditorName == rego.Name {
+			continue
+		}
+		assert.True(t, auditors[auditorName], "auditor %s has no registered rules", auditorName)
+	}
+
+	for _, rule := range Rules() {
+		assert.NotEmpty(t, rule.Description, "rule %s has no description", rule.ID)
+		assert.True(t, rule.Severity.IsBuiltin(), "rule %s has no severity", rule.ID)
+	}
+
+	// The registry can't be changed through the returned rules
+	rules := Rules()
+	rules[0].ID = "Changed"
+	assert.NotEqual(t, ID("Changed"), Rules()[0].ID)
+}
+
+func TestLookup(t *testing.T) {
+	rule, ok := Lookup(privileged.PrivilegedTrue)
+	require.True(t, ok)
+	assert.Equal(t, PrivilegedTrue, rule.ID)
+	assert.Equal(t, privileged.Name, rule.Auditor)
+	assert.Equal(t, kubeaudit.Error, rule.Severity)
+	assert.Equal(t, privileged.OverrideLabel, rule.OverrideLabel)
+	assert.True(t, rule.Fixable)
+
+	rule, ok = Lookup("PrivilegedTrueAllowed")
+	require.True(t, ok)
+	assert.Equal(t, PrivilegedTrue, rule.ID)
+
+	rule, ok = Lookup(kubeaudit.ManifestSyntaxError)
+	require.True(t, ok)
+	assert.Equal(t, kubeaudit.ManifestAuditor, rule.Auditor)
+
+	_, ok = Lookup("NotARule")
+	assert.False(t, ok)
+}
+
+// TestRulesMatchResults checks the registry against the results of the fixtures of the auditors: every rule reported is
+// registered, and rules reported with a fix are fixable
+func TestRulesMatchResults(t *testing.T) {
+	enabled := map[string]bool{vulns.Name: false, rego.Name: false}
+	for _, auditorName := range all.OptionalAuditorNames {
+		if _, ok := enabled[auditorName]; !ok {
+			enabled[auditorName] = true
+		}
+	}
+	auditables, err := all.Auditors(config.KubeauditConfig{EnabledAuditors: enabled})
+	require.NoError(t, err)
+	auditor, err := kubeaudit.New(auditables)
+	require.NoError(t, err)
+
+	files, err := filepath.Glob("../../auditors/*/fixtures/*.yml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		report, err := auditor.AuditManifestFiles([]string{file})
+		if err != nil {
+			continue
+		}
+		for _, result := range report.RawResults() {
+			for _, auditResult := range result.GetAuditResults() {
+				rule, ok := Lookup(auditResult.Rule)
+				if !assert.True(t, ok, "rule %s reported for %s is not registered", auditResult.Rule, file) {
+					continue
+				}
+				if auditResult.PendingFix != nil {
+					assert.True(t, rule.Fixable, "rule %s is fixed for %s but is not fixable", rule.ID, file)
+				}
+			}
+		}
+	}
+}
+
+func TestMatches(t *testing.T) {
+	auditable := privileged.New()
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{auditable})
+	require.NoError(t, err)
+	report, err := auditor.AuditManifestFiles([]string{"../../auditors/privileged/fixtures/privileged-true.yml"})
+	require.NoError(t, err)
+
+	var matched []*kubeaudit.AuditResult
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			if PrivilegedTrue.Matches(auditResult) {
+				matched = append(matched, auditResult)
+			}
+		}
+	}
+	assert.NotEmpty(t, matched)
+
+	assert.True(t, PrivilegedTrue.Matches(&kubeaudit.AuditResult{Rule: "PrivilegedTrueAllowed"}))
+	assert.False(t, PrivilegedNil.Matches(&kubeaudit.AuditResult{Rule: privileged.PrivilegedTrue}))
+	assert.False(t, PrivilegedTrue.Matches(nil))
+}
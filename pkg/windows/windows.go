@@ -0,0 +1,30 @@
+// Package windows adjusts the audit results of the auditors which only check Linux settings to the OS of the audited
+// pods. Windows containers ignore settings such as seccomp profiles and capabilities, so missing settings are not
+// issues for them
+package windows
+
+import (
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// LinuxOnly returns the audit results of an auditor which only checks Linux settings for the resource. Pods which run
+// on Windows, as set by their OS or node selector, have no results. Pods which set windowsOptions but don't select an
+// OS may run on either, so their errors are downgraded to warnings
+func LinuxOnly(resource k8s.Resource, auditResults []*kubeaudit.AuditResult) []*kubeaudit.AuditResult {
+	if k8s.IsWindowsPod(resource) {
+		return nil
+	}
+	if !k8s.HasWindowsOptions(resource) {
+		return auditResults
+	}
+
+	for _, auditResult := range auditResults {
+		if auditResult.Severity != kubeaudit.Error {
+			continue
+		}
+		auditResult.Severity = kubeaudit.Warn
+		auditResult.Message += " The pod sets windowsOptions and may run on Windows, where this setting is ignored. Set spec.os.name to the OS of the pod."
+	}
+	return auditResults
+}
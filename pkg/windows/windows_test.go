@@ -0,0 +1,38 @@
+package windows
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestLinuxOnly(t *testing.T) {
+	windowsOptions := &apiv1.WindowsSecurityContextOptions{RunAsUserName: new(string)}
+
+	cases := []struct {
+		testName           string
+		podSpec            apiv1.PodSpec
+		expectedSeverities []kubeaudit.SeverityLevel
+	}{
+		{"Linux pod", apiv1.PodSpec{}, []kubeaudit.SeverityLevel{kubeaudit.Error, kubeaudit.Warn}},
+		{"Windows OS", apiv1.PodSpec{OS: &apiv1.PodOS{Name: apiv1.Windows}}, nil},
+		{"Windows node selector", apiv1.PodSpec{NodeSelector: map[string]string{apiv1.LabelOSStable: "windows"}}, nil},
+		{"Linux OS with Windows node selector", apiv1.PodSpec{OS: &apiv1.PodOS{Name: apiv1.Linux}, NodeSelector: map[string]string{apiv1.LabelOSStable: "windows"}}, []kubeaudit.SeverityLevel{kubeaudit.Error, kubeaudit.Warn}},
+		{"Pod windowsOptions", apiv1.PodSpec{SecurityContext: &apiv1.PodSecurityContext{WindowsOptions: windowsOptions}}, []kubeaudit.SeverityLevel{kubeaudit.Warn, kubeaudit.Warn}},
+		{"Container windowsOptions", apiv1.PodSpec{Containers: []apiv1.Container{{SecurityContext: &apiv1.SecurityContext{WindowsOptions: windowsOptions}}}}, []kubeaudit.SeverityLevel{kubeaudit.Warn, kubeaudit.Warn}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			auditResults := []*kubeaudit.AuditResult{{Severity: kubeaudit.Error}, {Severity: kubeaudit.Warn}}
+			var severities []kubeaudit.SeverityLevel
+			for _, auditResult := range LinuxOnly(&k8s.PodV1{Spec: tc.podSpec}, auditResults) {
+				severities = append(severities, auditResult.Severity)
+			}
+			assert.Equal(t, tc.expectedSeverities, severities)
+		})
+	}
+}
@@ -4,17 +4,29 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/Shopify/kubeaudit/internal/color"
+	"github.com/Shopify/kubeaudit/internal/redact"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	docsBaseURL               = "https://github.com/Shopify/kubeaudit/blob/main/docs/"
+	kubernetesAPIReferenceURL = "https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.24/"
+)
+
 type Printer struct {
-	writer      io.Writer
-	minSeverity SeverityLevel
-	formatter   log.Formatter
-	color       bool
+	writer        io.Writer
+	minSeverity   SeverityLevel
+	formatter     log.Formatter
+	color         bool
+	hyperlinks    bool
+	samplePerRule int
+	groupBy       GroupBy
+	width         int
 }
 
 type PrintOption func(p *Printer)
@@ -48,6 +60,40 @@ func WithColor(color bool) PrintOption {
 	}
 }
 
+// WithHyperlinks specifies whether or not to link rules to the documentation of their auditor, and the kinds of
+// resources to their reference in the Kubernetes API documentation, in pretty output. Links are written as OSC 8
+// terminal hyperlinks, so you will likely want to set this to false if not writing to a terminal which supports them.
+func WithHyperlinks(hyperlinks bool) PrintOption {
+	return func(p *Printer) {
+		p.hyperlinks = hyperlinks
+	}
+}
+
+// WithSamplePerRule caps the number of resources reported for each rule. Results beyond the cap are still counted
+// and a summary of the totals is printed at the end. A value of 0 or less reports all results.
+func WithSamplePerRule(samplePerRule int) PrintOption {
+	return func(p *Printer) {
+		p.samplePerRule = samplePerRule
+	}
+}
+
+// WithGroupBy prints the findings of pretty output as one table per resource, auditor, namespace or severity, with
+// the groups and the findings of each group sorted. By default, the findings are printed per resource with their
+// metadata.
+func WithGroupBy(groupBy GroupBy) PrintOption {
+	return func(p *Printer) {
+		p.groupBy = groupBy
+	}
+}
+
+// WithWidth sets the width of the terminal, to which the messages of pretty output are wrapped. A value of 0 or less
+// doesn't wrap messages.
+func WithWidth(width int) PrintOption {
+	return func(p *Printer) {
+		p.width = width
+	}
+}
+
 func (p *Printer) parseOptions(opts ...PrintOption) {
 	for _, opt := range opts {
 		opt(p)
@@ -72,20 +118,93 @@ func (p *Printer) PrintReport(report *Report) {
 	}
 }
 
+// ruleSample holds the number of results found for a rule when results are sampled
+type ruleSample struct {
+	rule     string
+	total    int
+	reported int
+}
+
+// SamplePerRule returns a copy of the report with at most samplePerRule audit results for each rule, for the output
+// formats which are not printed by the printer. The report is returned as is if samplePerRule is 0 or less
+func (r *Report) SamplePerRule(samplePerRule int) *Report {
+	if samplePerRule <= 0 {
+		return r
+	}
+	results, _ := sampleResults(r.Results(), samplePerRule)
+	return r.withResults(results)
+}
+
+// sampleResults returns the results to report, keeping at most samplePerRule results for each rule, and the totals
+// for each rule which had results left out
+func sampleResults(results []Result, samplePerRule int) ([]Result, []ruleSample) {
+	if samplePerRule <= 0 {
+		return results, nil
+	}
+
+	counts := map[string]*ruleSample{}
+	var sampledResults []Result
+	for _, result := range results {
+		var auditResults []*AuditResult
+		for _, auditResult := range result.GetAuditResults() {
+			count, ok := counts[auditResult.Rule]
+			if !ok {
+				count = &ruleSample{rule: auditResult.Rule}
+				counts[auditResult.Rule] = count
+			}
+			count.total++
+			if count.reported < samplePerRule {
+				count.reported++
+				auditResults = append(auditResults, auditResult)
+			}
+		}
+		if len(auditResults) > 0 {
+			sampledResults = append(sampledResults, &WorkloadResult{
+				Resource:     result.GetResource(),
+				AuditResults: auditResults,
+			})
+		}
+	}
+
+	var samples []ruleSample
+	for _, count := range counts {
+		if count.total > count.reported {
+			samples = append(samples, *count)
+		}
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i].rule < samples[j].rule })
+
+	return sampledResults, samples
+}
+
 func (p *Printer) prettyPrintReport(report *Report) {
+	defer p.printOverrides(report.Overrides())
+	defer p.printSuppressions(report.Suppressions())
+
 	if len(report.ResultsWithMinSeverity(p.minSeverity)) < 1 {
 		p.printColor(color.GreenColor, "All checks completed. 0 high-risk vulnerabilities found\n")
 		return
 	}
 
-	for _, workloadResult := range report.ResultsWithMinSeverity(p.minSeverity) {
+	results, samples := sampleResults(report.ResultsWithMinSeverity(p.minSeverity), p.samplePerRule)
+	defer p.printSamples(samples)
+
+	if p.groupBy != "" {
+		p.printGroupedResults(results)
+		return
+	}
+
+	for _, workloadResult := range results {
 		resource := workloadResult.GetResource().Object()
 		objectMeta := k8s.GetObjectMeta(resource)
-		resouceApiVersion, resourceKind := resource.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
 
 		p.printColor(color.CyanColor, "\n---------------- Results for ---------------\n\n")
-		p.printColor(color.CyanColor, "  apiVersion: "+resouceApiVersion+"\n")
-		p.printColor(color.CyanColor, "  kind: "+resourceKind+"\n")
+		// Documents of manifests which could not be decoded, such as invalid YAML, are only identified by their source
+		if resource != nil {
+			resouceApiVersion, resourceKind := resource.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
+			p.printColor(color.CyanColor, "  apiVersion: "+resouceApiVersion+"\n")
+			p.printColor(color.CyanColor, "  kind: "+p.link(kindReferenceURL(resouceApiVersion, resourceKind), resourceKind)+"\n")
+		}
 		if objectMeta != nil && (objectMeta.GetName() != "" || objectMeta.GetNamespace() != "") {
 			p.printColor(color.CyanColor, "  metadata:\n")
 			if objectMeta.GetName() != "" {
@@ -95,33 +214,130 @@ func (p *Printer) prettyPrintReport(report *Report) {
 				p.printColor(color.CyanColor, "    namespace: "+objectMeta.GetNamespace()+"\n")
 			}
 		}
+		if filePath := resultFilePath(workloadResult); filePath != "" {
+			p.printColor(color.CyanColor, "  source: "+filePath+"\n")
+		}
+		if context := resultContext(workloadResult); context != "" {
+			p.printColor(color.CyanColor, "  context: "+context+"\n")
+		}
 		p.printColor(color.CyanColor, "\n--------------------------------------------\n\n")
 
-		for _, auditResult := range workloadResult.GetAuditResults() {
-			severityColor := color.YellowColor
-			switch auditResult.Severity {
-			case Info:
-				severityColor = color.CyanColor
-			case Warn:
-				severityColor = color.YellowColor
-			case Error:
-				severityColor = color.RedColor
-			}
+		for _, finding := range getFindings(workloadResult) {
 			p.print("-- ")
-			p.printColor(severityColor, "["+auditResult.Severity.String()+"] ")
-			p.print(auditResult.Rule + "\n")
-			p.print("   Message: " + auditResult.Message + "\n")
-			if len(auditResult.Metadata) > 0 {
+			p.printColor(severityColor(finding.Severity), "["+finding.Severity.String()+"] ")
+			p.print(p.link(auditorDocsURL(finding.Auditor), finding.Rule) + "\n")
+			const messagePrefix = "   Message: "
+			lines := wrapText(redact.String(finding.Message), p.messageWidth(len(messagePrefix)))
+			p.print(messagePrefix + strings.Join(lines, "\n"+strings.Repeat(" ", len(messagePrefix))) + "\n")
+			if finding.Line > 0 {
+				p.print(fmt.Sprintf("   Location: %s:%d:%d\n", finding.FilePath, finding.Line, finding.Column))
+			}
+			if len(finding.Metadata) > 0 {
 				p.print("   Metadata:\n")
 			}
-			for k, v := range auditResult.Metadata {
-				p.print(fmt.Sprintf("      %s: %s\n", k, v))
+			for k, v := range finding.Metadata {
+				p.print(fmt.Sprintf("      %s: %s\n", k, redact.String(v)))
 			}
 			p.print("\n")
 		}
 	}
 }
 
+// severityColor returns the color severities are printed in, the color of the built-in severity below them
+func severityColor(severity SeverityLevel) string {
+	switch severity.Builtin() {
+	case Info:
+		return color.CyanColor
+	case Error:
+		return color.RedColor
+	}
+	return color.YellowColor
+}
+
+// resultFilePath returns the file the audit results of the resource are attributed to, such as the Helm template it
+// was rendered from
+func resultFilePath(result Result) string {
+	for _, auditResult := range result.GetAuditResults() {
+		if auditResult.FilePath != "" {
+			return auditResult.FilePath
+		}
+	}
+	return ""
+}
+
+// resultContext returns the kubeconfig context of the cluster the resource was audited in, when several clusters are
+// audited
+func resultContext(result Result) string {
+	for _, auditResult := range result.GetAuditResults() {
+		if context := auditResult.Metadata[ContextMetadata]; context != "" {
+			return context
+		}
+	}
+	return ""
+}
+
+func (p *Printer) printSamples(samples []ruleSample) {
+	if len(samples) == 0 {
+		return
+	}
+
+	p.printColor(color.CyanColor, "\n---------------- Sampled rules ---------------\n\n")
+	for _, sample := range samples {
+		p.print(fmt.Sprintf("-- %s: showing %d of %d results\n", sample.rule, sample.reported, sample.total))
+	}
+	p.print("\n")
+}
+
+// printSuppressions prints the number of findings suppressed by each mechanism, so exceptions can be tracked over time
+func (p *Printer) printSuppressions(suppressions map[SuppressionMechanism]int) {
+	if len(suppressions) == 0 {
+		return
+	}
+
+	p.printColor(color.CyanColor, "\n---------------- Suppressed findings ---------------\n\n")
+	for _, mechanism := range SuppressionMechanisms {
+		if count, ok := suppressions[mechanism]; ok {
+			p.print(fmt.Sprintf("-- %s: %d\n", mechanism, count))
+		}
+	}
+	p.print("\n")
+}
+
+// printOverrides prints the findings overridden by a label, with the label and the reason given for the override
+func (p *Printer) printOverrides(overrides []Finding) {
+	if len(overrides) == 0 {
+		return
+	}
+
+	p.printColor(color.CyanColor, "\n---------------- Overridden findings ---------------\n\n")
+	for _, finding := range overrides {
+		resource := finding.GroupVersionKind.Kind
+		if finding.Context != "" {
+			resource = finding.Context + ": " + resource
+		}
+		for _, part := range []string{finding.Namespace, finding.Name} {
+			if part != "" {
+				resource += "/" + part
+			}
+		}
+		if finding.Container != "" {
+			resource += " (" + finding.Container + ")"
+		}
+		p.print(fmt.Sprintf("-- %s: %s\n", resource, finding.Rule))
+		p.print("   Label: " + finding.AuditResult.OverrideLabel + "\n")
+		if reason := finding.Metadata["OverrideReason"]; reason != "" {
+			p.print("   Reason: " + redact.String(reason) + "\n")
+		}
+		if expiry := finding.Metadata["OverrideExpiry"]; expiry != "" {
+			p.print("   Expires: " + expiry + "\n")
+		}
+		if owner := finding.Metadata[OverrideInheritedFromMetadata]; owner != "" {
+			p.print("   Inherited from: " + owner + "\n")
+		}
+	}
+	p.print("\n")
+}
+
 func (p *Printer) print(s string) {
 	fmt.Fprint(p.writer, s)
 }
@@ -134,54 +350,155 @@ func (p *Printer) printColor(c string, s string) {
 	}
 }
 
+// link returns s as a hyperlink to url if hyperlinks are enabled
+func (p *Printer) link(url, s string) string {
+	if !p.hyperlinks {
+		return s
+	}
+	return color.Hyperlink(url, s)
+}
+
+// auditorDocsURL returns the URL of the documentation of an auditor, or an empty string for results without an
+// auditor (eg. results of custom auditors which don't set it)
+func auditorDocsURL(auditor string) string {
+	if auditor == "" {
+		return ""
+	}
+	return docsBaseURL + "auditors/" + strings.ToLower(auditor) + ".md"
+}
+
+// kindReferenceURL returns the URL of the reference of a kind in the Kubernetes API documentation, which is the
+// reference shown by "kubectl explain". Kinds of API groups which are not part of Kubernetes, such as custom resources,
+// have no reference so an empty string is returned
+func kindReferenceURL(apiVersion, kind string) string {
+	if kind == "" {
+		return ""
+	}
+
+	group, version := "core", apiVersion
+	if i := strings.Index(apiVersion, "/"); i >= 0 {
+		group, version = apiVersion[:i], apiVersion[i+1:]
+		if strings.Contains(group, ".") && !strings.HasSuffix(group, ".k8s.io") {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("%s#%s-%s-%s", kubernetesAPIReferenceURL, strings.ToLower(kind), version, group)
+}
+
 func (p *Printer) logReport(report *Report) {
 	resultLogger := log.New()
 	resultLogger.SetOutput(p.writer)
 	resultLogger.SetFormatter(p.formatter)
+	resultLogger.AddHook(redact.Hook{})
 
 	// We manually manage what severity levels to log, logrus should let everything through
 	resultLogger.SetLevel(log.DebugLevel)
 
-	for _, workloadResult := range report.ResultsWithMinSeverity(p.minSeverity) {
-		for _, auditResult := range workloadResult.GetAuditResults() {
-			p.logAuditResult(workloadResult.GetResource().Object(), auditResult, resultLogger)
+	results, samples := sampleResults(report.ResultsWithMinSeverity(p.minSeverity), p.samplePerRule)
+	for _, workloadResult := range results {
+		for _, finding := range getFindings(workloadResult) {
+			p.logFinding(finding, resultLogger)
+		}
+	}
+
+	for _, sample := range samples {
+		resultLogger.WithFields(log.Fields{
+			"AuditResultName": sample.rule,
+			"Reported":        sample.reported,
+			"Total":           sample.total,
+		}).Info("Results sampled")
+	}
+
+	suppressions := report.Suppressions()
+	for _, mechanism := range SuppressionMechanisms {
+		if count, ok := suppressions[mechanism]; ok {
+			resultLogger.WithFields(log.Fields{
+				"Mechanism":  string(mechanism),
+				"Suppressed": count,
+			}).Info("Findings suppressed")
 		}
 	}
+
+	for _, finding := range report.Overrides() {
+		fields := log.Fields{
+			"AuditResultName":   finding.Rule,
+			"ResourceKind":      finding.GroupVersionKind.Kind,
+			"ResourceNamespace": finding.Namespace,
+			"ResourceName":      finding.Name,
+			"OverrideLabel":     finding.AuditResult.OverrideLabel,
+		}
+		if finding.Container != "" {
+			fields["Container"] = finding.Container
+		}
+		if finding.Context != "" {
+			fields[ContextMetadata] = finding.Context
+		}
+		if reason := finding.Metadata["OverrideReason"]; reason != "" {
+			fields["OverrideReason"] = reason
+		}
+		if expiry := finding.Metadata["OverrideExpiry"]; expiry != "" {
+			fields["OverrideExpiry"] = expiry
+		}
+		if owner := finding.Metadata[OverrideInheritedFromMetadata]; owner != "" {
+			fields[OverrideInheritedFromMetadata] = owner
+		}
+		resultLogger.WithFields(fields).Info("Finding overridden")
+	}
 }
 
-func (p *Printer) logAuditResult(resource k8s.Resource, result *AuditResult, baseLogger *log.Logger) {
-	logger := baseLogger.WithFields(p.getLogFieldsForResult(resource, result))
-	switch result.Severity {
+func (p *Printer) logFinding(finding Finding, baseLogger *log.Logger) {
+	logger := baseLogger.WithFields(p.getLogFieldsForFinding(finding))
+	switch finding.Severity.Builtin() {
 	case Info:
-		logger.Info(result.Message)
+		logger.Info(finding.Message)
 	case Warn:
-		logger.Warn(result.Message)
+		logger.Warn(finding.Message)
 	case Error:
-		logger.Error(result.Message)
+		logger.Error(finding.Message)
 	}
 }
 
-func (p *Printer) getLogFieldsForResult(resource k8s.Resource, result *AuditResult) log.Fields {
-	apiVersion, kind := resource.GetObjectKind().GroupVersionKind().ToAPIVersionAndKind()
-	objectMeta := k8s.GetObjectMeta(resource)
+func (p *Printer) getLogFieldsForFinding(finding Finding) log.Fields {
+	apiVersion, kind := finding.GroupVersionKind.ToAPIVersionAndKind()
 
 	fields := log.Fields{
-		"AuditResultName":    result.Rule,
+		"AuditResultName":    finding.Rule,
 		"ResourceKind":       kind,
 		"ResourceApiVersion": apiVersion,
 	}
 
-	if objectMeta != nil {
-		if objectMeta.GetNamespace() != "" {
-			fields["ResourceNamespace"] = objectMeta.GetNamespace()
-		}
+	if finding.Namespace != "" {
+		fields["ResourceNamespace"] = finding.Namespace
+	}
 
-		if objectMeta.GetName() != "" {
-			fields["ResourceName"] = objectMeta.GetName()
-		}
+	if finding.Name != "" {
+		fields["ResourceName"] = finding.Name
+	}
+
+	// Custom severities are logged at the level of the built-in severity below them, so the name is added
+	if !finding.Severity.IsBuiltin() {
+		fields["Severity"] = finding.Severity.String()
+	}
+
+	if finding.FilePath != "" {
+		fields["FilePath"] = finding.FilePath
+	}
+
+	if finding.Line > 0 {
+		fields["Line"] = finding.Line
+	}
+
+	if finding.Column > 0 {
+		fields["Column"] = finding.Column
+	}
+
+	// References are only structured in JSON, they would drown the message of the text formats
+	if _, isJSON := p.formatter.(*log.JSONFormatter); isJSON && len(finding.References) > 0 {
+		fields["References"] = finding.References
 	}
 
-	for k, v := range result.Metadata {
+	for k, v := range finding.Metadata {
 		fields[k] = v
 	}
 
@@ -0,0 +1,320 @@
+package kubeaudit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Shopify/kubeaudit/internal/color"
+	"github.com/Shopify/kubeaudit/internal/redact"
+)
+
+// GroupBy is how pretty output groups the findings of a report into tables
+type GroupBy string
+
+const (
+	GroupByResource  GroupBy = "resource"
+	GroupByAuditor   GroupBy = "auditor"
+	GroupByNamespace GroupBy = "namespace"
+	GroupBySeverity  GroupBy = "severity"
+)
+
+// GroupBys are the groupings of WithGroupBy()
+var GroupBys = []GroupBy{GroupByResource, GroupByAuditor, GroupByNamespace, GroupBySeverity}
+
+// minMessageWidth is the narrowest the message column of a table is wrapped to. In terminals too narrow for it, the
+// lines of the table are wrapped by the terminal instead
+const minMessageWidth = 30
+
+// tableColumn is a column of the tables of grouped pretty output
+type tableColumn struct {
+	header string
+	value  func(finding Finding) string
+	// style colors or links the value of a cell, after it is aligned
+	style func(p *Printer, finding Finding, s string) string
+	// optional columns are left out of tables which would otherwise not leave enough room for the messages
+	optional bool
+}
+
+var (
+	severityColumn = tableColumn{
+		header: "SEVERITY",
+		value:  func(finding Finding) string { return finding.Severity.String() },
+		style: func(p *Printer, finding Finding, s string) string {
+			if !p.color {
+				return s
+			}
+			return color.Colored(severityColor(finding.Severity), s)
+		},
+	}
+	ruleColumn = tableColumn{
+		header: "RULE",
+		value:  func(finding Finding) string { return finding.Rule },
+		style: func(p *Printer, finding Finding, s string) string {
+			// Only the rule is linked, not the padding which aligns the next column
+			rule := strings.TrimRight(s, " ")
+			return p.link(auditorDocsURL(finding.Auditor), rule) + s[len(rule):]
+		},
+	}
+	resourceColumn = tableColumn{
+		header: "RESOURCE",
+		value:  findingResourceName,
+	}
+	containerColumn = tableColumn{
+		header:   "CONTAINER",
+		value:    func(finding Finding) string { return finding.Container },
+		optional: true,
+	}
+	locationColumn = tableColumn{
+		header: "LOCATION",
+		value: func(finding Finding) string {
+			if finding.Line == 0 {
+				return ""
+			}
+			return fmt.Sprintf("%s:%d:%d", finding.FilePath, finding.Line, finding.Column)
+		},
+		optional: true,
+	}
+	messageColumn = tableColumn{
+		header: "MESSAGE",
+		value:  func(finding Finding) string { return redact.String(finding.Message) },
+	}
+)
+
+// findingGroup is the findings of a group of grouped pretty output
+type findingGroup struct {
+	name     string
+	severity SeverityLevel
+	findings []Finding
+}
+
+// groupFindings returns the findings of the results grouped as set by groupBy. The groups are sorted by name, or from
+// the highest severity for GroupBySeverity, and their findings from the highest severity, then by rule and resource
+func groupFindings(results []Result, groupBy GroupBy) []*findingGroup {
+	groups := map[string]*findingGroup{}
+	for _, result := range results {
+		for _, finding := range getFindings(result) {
+			name := findingGroupName(finding, groupBy)
+			group, ok := groups[name]
+			if !ok {
+				group = &findingGroup{name: name, severity: finding.Severity}
+				groups[name] = group
+			}
+			group.findings = append(group.findings, finding)
+		}
+	}
+
+	sorted := make([]*findingGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.SliceStable(group.findings, func(i, j int) bool {
+			a, b := group.findings[i], group.findings[j]
+			if a.Severity != b.Severity {
+				return a.Severity.Compare(b.Severity) > 0
+			}
+			if a.Rule != b.Rule {
+				return a.Rule < b.Rule
+			}
+			if findingResourceName(a) != findingResourceName(b) {
+				return findingResourceName(a) < findingResourceName(b)
+			}
+			return a.Container < b.Container
+		})
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if groupBy == GroupBySeverity {
+			return sorted[i].severity.Compare(sorted[j].severity) > 0
+		}
+		return sorted[i].name < sorted[j].name
+	})
+	return sorted
+}
+
+func findingGroupName(finding Finding, groupBy GroupBy) string {
+	switch groupBy {
+	case GroupByAuditor:
+		if finding.Auditor == "" {
+			return "(no auditor)"
+		}
+		return finding.Auditor
+	case GroupByNamespace:
+		// Namespaces are grouped with the resources in them
+		if finding.GroupVersionKind.Kind == "Namespace" && finding.Namespace == "" {
+			return finding.Name
+		}
+		if finding.Namespace == "" {
+			return "(no namespace)"
+		}
+		return finding.Namespace
+	case GroupBySeverity:
+		return finding.Severity.String()
+	}
+	return findingResourceName(finding)
+}
+
+// findingResourceName returns the kind, namespace and name of the resource of a finding, eg. "Deployment
+// default/payments", prefixed with its context when several clusters are audited. Documents of manifests which could
+// not be decoded are named after their source
+func findingResourceName(finding Finding) string {
+	name := finding.GroupVersionKind.Kind
+	switch {
+	case finding.Namespace != "":
+		name += " " + finding.Namespace + "/" + finding.Name
+	case finding.Name != "":
+		name += " " + finding.Name
+	}
+	if name == "" {
+		name = finding.FilePath
+	}
+	if finding.Context != "" {
+		name = finding.Context + ": " + name
+	}
+	return name
+}
+
+// printGroupedResults prints the findings of the results as one table per group, with the columns aligned and the
+// messages wrapped to the width of the terminal
+func (p *Printer) printGroupedResults(results []Result) {
+	for _, group := range groupFindings(results, p.groupBy) {
+		count := fmt.Sprintf("%d findings", len(group.findings))
+		if len(group.findings) == 1 {
+			count = "1 finding"
+		}
+		title := strings.ToUpper(string(p.groupBy[:1])) + string(p.groupBy[1:])
+		p.printColor(color.CyanColor, fmt.Sprintf("\n---------------- %s: %s (%s) ---------------\n\n", title, group.name, count))
+		p.printTable(p.tableColumns(group.findings), group.findings)
+	}
+	p.print("\n")
+}
+
+// tableColumns returns the columns of the table of a group. The column the findings are grouped by is left out, and
+// so are the container and location columns if none of the findings have them
+func (p *Printer) tableColumns(findings []Finding) []tableColumn {
+	var columns []tableColumn
+	if p.groupBy != GroupBySeverity {
+		columns = append(columns, severityColumn)
+	}
+	columns = append(columns, ruleColumn)
+	if p.groupBy != GroupByResource {
+		columns = append(columns, resourceColumn)
+	}
+	for _, column := range []tableColumn{containerColumn, locationColumn} {
+		for _, finding := range findings {
+			if column.value(finding) != "" {
+				columns = append(columns, column)
+				break
+			}
+		}
+	}
+	return append(columns, messageColumn)
+}
+
+// printTable prints the findings as rows of the columns, separated by two spaces. The last column is wrapped so the
+// rows fit in the width of the printer, if it is set, and the optional columns are left out from the right until the
+// last column is at least minMessageWidth wide
+func (p *Printer) printTable(columns []tableColumn, findings []Finding) {
+	const separator = "  "
+
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = utf8.RuneCountInString(column.header)
+		for _, finding := range findings {
+			if width := utf8.RuneCountInString(column.value(finding)); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	indent := 0
+	for _, width := range widths[:len(columns)-1] {
+		indent += width + len(separator)
+	}
+	for i := len(columns) - 2; i >= 0 && p.width > 0 && p.width-indent < minMessageWidth; i-- {
+		if columns[i].optional {
+			indent -= widths[i] + len(separator)
+			columns = append(columns[:i:i], columns[i+1:]...)
+			widths = append(widths[:i:i], widths[i+1:]...)
+		}
+	}
+	last := len(columns) - 1
+	messageWidth := p.messageWidth(indent)
+
+	var header strings.Builder
+	for i, column := range columns[:last] {
+		header.WriteString(pad(column.header, widths[i]) + separator)
+	}
+	p.print(header.String() + columns[last].header + "\n")
+
+	for _, finding := range findings {
+		var row strings.Builder
+		for i, column := range columns[:last] {
+			cell := pad(column.value(finding), widths[i])
+			if column.style != nil {
+				cell = column.style(p, finding, cell)
+			}
+			row.WriteString(cell + separator)
+		}
+		lines := wrapText(columns[last].value(finding), messageWidth)
+		row.WriteString(lines[0] + "\n")
+		for _, line := range lines[1:] {
+			row.WriteString(strings.Repeat(" ", indent) + line + "\n")
+		}
+		p.print(row.String())
+	}
+}
+
+// messageWidth returns the width messages are wrapped to when they are indented by indent characters, or 0 if the
+// width of the printer isn't set
+func (p *Printer) messageWidth(indent int) int {
+	if p.width <= 0 {
+		return 0
+	}
+	if p.width-indent < minMessageWidth {
+		return minMessageWidth
+	}
+	return p.width - indent
+}
+
+// pad returns s followed by spaces up to the width
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// wrapText splits s into lines of at most width characters, breaking lines between words. Words longer than the width
+// are broken. A width of 0 or less doesn't wrap s
+func wrapText(s string, width int) []string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return []string{s}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for utf8.RuneCountInString(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			runes := []rune(word)
+			lines = append(lines, string(runes[:width]))
+			word = string(runes[width:])
+		}
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,104 @@
+package kubeaudit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newGroupTestReport() *Report {
+	var results []Result
+	for _, namespace := range []string{"payments", "default"} {
+		deployment := k8s.NewDeployment()
+		deployment.Namespace = namespace
+		deployment.Name = "web"
+		results = append(results, &WorkloadResult{
+			Resource: &kubeResource{object: deployment},
+			AuditResults: []*AuditResult{
+				{Auditor: "Limits", Rule: "LimitsNotSet", Severity: Warn, Message: "Resource limits not set.", Metadata: Metadata{"Container": "app"}},
+				{Auditor: "Privileged", Rule: "PrivilegedTrue", Severity: Error, Message: "privileged is set to 'true' in container SecurityContext. It should be set to 'false'.", Metadata: Metadata{"Container": "app"}},
+				{Auditor: "Image", Rule: "ImageTagMissing", Severity: Info, Message: "Image tag is missing.", FilePath: "deployment.yaml", Line: 20, Column: 9},
+			},
+		})
+	}
+	return &Report{results: results}
+}
+
+func TestGroupFindings(t *testing.T) {
+	report := newGroupTestReport()
+
+	cases := []struct {
+		groupBy  GroupBy
+		expected []string
+	}{
+		{GroupByResource, []string{"Deployment default/web", "Deployment payments/web"}},
+		{GroupByAuditor, []string{"Image", "Limits", "Privileged"}},
+		{GroupByNamespace, []string{"default", "payments"}},
+		{GroupBySeverity, []string{"error", "warning", "info"}},
+	}
+	for _, tc := range cases {
+		t.Run(string(tc.groupBy), func(t *testing.T) {
+			var names []string
+			for _, group := range groupFindings(report.Results(), tc.groupBy) {
+				names = append(names, group.name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+
+	// The findings of a group are sorted from the highest severity
+	groups := groupFindings(report.Results(), GroupByNamespace)
+	var rules []string
+	for _, finding := range groups[0].findings {
+		rules = append(rules, finding.Rule)
+	}
+	assert.Equal(t, []string{"PrivilegedTrue", "LimitsNotSet", "ImageTagMissing"}, rules)
+
+	// The findings of the same rule are sorted by resource
+	groups = groupFindings(report.Results(), GroupByAuditor)
+	require.Len(t, groups[2].findings, 2)
+	assert.Equal(t, "default", groups[2].findings[0].Namespace)
+	assert.Equal(t, "payments", groups[2].findings[1].Namespace)
+}
+
+func TestPrintGroupedResults(t *testing.T) {
+	report := newGroupTestReport()
+	out := bytes.NewBuffer(nil)
+	report.PrintResults(WithWriter(out), WithColor(false), WithGroupBy(GroupBySeverity), WithWidth(120))
+
+	lines := strings.Split(out.String(), "\n")
+	assert.Contains(t, lines, "---------------- Severity: error (2 findings) ---------------")
+	assert.Contains(t, lines, "RULE            RESOURCE                 CONTAINER  MESSAGE")
+	// The messages are wrapped to the width and aligned with their column
+	assert.Contains(t, lines, "PrivilegedTrue  Deployment default/web   app        privileged is set to 'true' in container SecurityContext. It should")
+	assert.Contains(t, lines, "                                                    be set to 'false'.")
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 120)
+	}
+
+	// The optional columns are left out when the terminal is too narrow for them
+	out.Reset()
+	report.PrintResults(WithWriter(out), WithColor(false), WithGroupBy(GroupBySeverity), WithWidth(60), WithMinSeverity(Error))
+	assert.Contains(t, out.String(), "RULE            RESOURCE                 MESSAGE\n")
+	assert.NotContains(t, out.String(), "CONTAINER")
+}
+
+func TestWrapText(t *testing.T) {
+	cases := []struct {
+		s        string
+		width    int
+		expected []string
+	}{
+		{"short message", 0, []string{"short message"}},
+		{"short message", 20, []string{"short message"}},
+		{"a longer message to wrap", 10, []string{"a longer", "message to", "wrap"}},
+		{"abcdefghijklmnop qr", 5, []string{"abcde", "fghij", "klmno", "p qr"}},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, wrapText(tc.s, tc.width), tc.s)
+	}
+}
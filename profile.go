@@ -0,0 +1,227 @@
+package kubeaudit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// maxProfiledResources is the number of slowest resources a profile keeps
+const maxProfiledResources = 100
+
+// Profile records how long each auditor takes per resource and overall, to find the auditors which dominate the
+// duration of audits. A profile is safe for concurrent use and can record several audits
+type Profile struct {
+	mu        sync.Mutex
+	duration  time.Duration
+	count     int
+	auditors  map[string]*AuditorProfile
+	resources []ResourceProfile
+}
+
+// AuditorProfile is the time an auditor took to audit the resources
+type AuditorProfile struct {
+	Auditor string
+	// Total is the time the auditor took for all the resources. As the auditors of a resource run concurrently, the
+	// totals of the auditors add up to more than the duration of the audit
+	Total     time.Duration
+	Resources int
+	// Max is the time the auditor took for its slowest resource, SlowestResource
+	Max             time.Duration
+	SlowestResource string
+}
+
+// Average returns the average time the auditor took per resource
+func (p AuditorProfile) Average() time.Duration {
+	if p.Resources == 0 {
+		return 0
+	}
+	return p.Total / time.Duration(p.Resources)
+}
+
+// ResourceProfile is the time a resource took to audit
+type ResourceProfile struct {
+	// Resource is the kind, namespace and name of the resource, eg. "Deployment default/payments"
+	Resource string
+	Duration time.Duration
+	// SlowestAuditor is the auditor which took the longest for the resource, which took SlowestAuditorDuration
+	SlowestAuditor         string
+	SlowestAuditorDuration time.Duration
+}
+
+// NewProfile returns an empty profile, to pass to WithProfile()
+func NewProfile() *Profile {
+	return &Profile{auditors: map[string]*AuditorProfile{}}
+}
+
+// Duration returns the total duration of the profiled audits and the number of resources they audited
+func (p *Profile) Duration() (time.Duration, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.duration, p.count
+}
+
+// Auditors returns the profiles of the auditors, slowest first
+func (p *Profile) Auditors() []AuditorProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	auditors := make([]AuditorProfile, 0, len(p.auditors))
+	for _, auditor := range p.auditors {
+		auditors = append(auditors, *auditor)
+	}
+	sort.Slice(auditors, func(i, j int) bool {
+		if auditors[i].Total != auditors[j].Total {
+			return auditors[i].Total > auditors[j].Total
+		}
+		return auditors[i].Auditor < auditors[j].Auditor
+	})
+	return auditors
+}
+
+// Resources returns the profiles of the slowest resources, slowest first. Only the 100 slowest resources are kept
+func (p *Profile) Resources() []ResourceProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ResourceProfile(nil), p.resources...)
+}
+
+// Write writes the top auditors and resources of the profile to the writer as tables
+func (p *Profile) Write(w io.Writer, top int) error {
+	duration, count := p.Duration()
+	auditors := p.Auditors()
+	resources := p.Resources()
+	if top > 0 && len(auditors) > top {
+		auditors = auditors[:top]
+	}
+	if top > 0 && len(resources) > top {
+		resources = resources[:top]
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "Audited %d resources in %s\n\n", count, formatProfileDuration(duration))
+	fmt.Fprintln(table, "AUDITOR\tTOTAL\tAVERAGE\tMAX\tSLOWEST RESOURCE")
+	for _, auditor := range auditors {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\n", auditor.Auditor, formatProfileDuration(auditor.Total),
+			formatProfileDuration(auditor.Average()), formatProfileDuration(auditor.Max), auditor.SlowestResource)
+	}
+	fmt.Fprintln(table)
+	fmt.Fprintln(table, "RESOURCE\tDURATION\tSLOWEST AUDITOR")
+	for _, resource := range resources {
+		fmt.Fprintf(table, "%s\t%s\t%s (%s)\n", resource.Resource, formatProfileDuration(resource.Duration),
+			resource.SlowestAuditor, formatProfileDuration(resource.SlowestAuditorDuration))
+	}
+	return table.Flush()
+}
+
+func formatProfileDuration(duration time.Duration) string {
+	switch {
+	case duration >= time.Second:
+		return duration.Round(time.Millisecond).String()
+	case duration >= time.Millisecond:
+		return duration.Round(10 * time.Microsecond).String()
+	default:
+		return duration.Round(time.Microsecond).String()
+	}
+}
+
+// recordAudit adds the duration of an audit of the resources to the profile
+func (p *Profile) recordAudit(duration time.Duration, count int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.duration += duration
+	p.count += count
+}
+
+// recordResource adds the times the auditors took to audit a resource to the profile
+func (p *Profile) recordResource(resource k8s.Resource, duration time.Duration, auditorNames []string, durations []time.Duration) {
+	if p == nil {
+		return
+	}
+	name := profileResourceName(resource)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	resourceProfile := ResourceProfile{Resource: name, Duration: duration}
+	for i, auditorName := range auditorNames {
+		auditor, ok := p.auditors[auditorName]
+		if !ok {
+			auditor = &AuditorProfile{Auditor: auditorName}
+			p.auditors[auditorName] = auditor
+		}
+		auditor.Total += durations[i]
+		auditor.Resources++
+		if durations[i] > auditor.Max {
+			auditor.Max = durations[i]
+			auditor.SlowestResource = name
+		}
+		if durations[i] > resourceProfile.SlowestAuditorDuration {
+			resourceProfile.SlowestAuditor = auditorName
+			resourceProfile.SlowestAuditorDuration = durations[i]
+		}
+	}
+
+	// The resources are kept sorted, slowest first, so the fastest one is dropped once there are too many
+	i := sort.Search(len(p.resources), func(i int) bool { return p.resources[i].Duration < duration })
+	if i == maxProfiledResources {
+		return
+	}
+	p.resources = append(p.resources, ResourceProfile{})
+	copy(p.resources[i+1:], p.resources[i:])
+	p.resources[i] = resourceProfile
+	if len(p.resources) > maxProfiledResources {
+		p.resources = p.resources[:maxProfiledResources]
+	}
+}
+
+// runAuditor runs an auditor with runAuditor() and returns how long it took. The auditor runs with the "auditor"
+// pprof label, so CPU profiles can be broken down by auditor
+func (p *Profile) runAuditor(auditorName string, auditable Auditable, resource k8s.Resource, resources []k8s.Resource) (auditResults []*AuditResult, duration time.Duration, err error) {
+	pprof.Do(context.Background(), pprof.Labels("auditor", auditorName), func(context.Context) {
+		start := time.Now()
+		auditResults, err = runAuditor(auditable, resource, resources)
+		duration = time.Since(start)
+	})
+	return auditResults, duration, err
+}
+
+func profileResourceName(resource k8s.Resource) string {
+	name := resource.GetObjectKind().GroupVersionKind().Kind
+	if objectMeta := k8s.GetObjectMeta(resource); objectMeta != nil {
+		if objectMeta.GetNamespace() != "" {
+			name += " " + objectMeta.GetNamespace() + "/" + objectMeta.GetName()
+		} else {
+			name += " " + objectMeta.GetName()
+		}
+	}
+	return name
+}
+
+// auditorName returns the name of an auditor in profiles: the String() of auditors which implement fmt.Stringer,
+// such as plugins, or else the type of the auditor, eg. "apparmor.AppArmor". Auditors which wrap other auditors, such
+// as to filter their audit results, are named after the auditor returned by their Unwrap() method
+func auditorName(auditable Auditable) string {
+	for {
+		wrapper, ok := auditable.(interface{ Unwrap() Auditable })
+		if !ok {
+			break
+		}
+		auditable = wrapper.Unwrap()
+	}
+	if stringer, ok := auditable.(fmt.Stringer); ok {
+		return stringer.String()
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", auditable), "*")
+}
@@ -0,0 +1,103 @@
+package kubeaudit
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowAuditor sleeps for the duration set in the "sleep" annotation of the resources it audits
+type slowAuditor struct{}
+
+func (a *slowAuditor) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*AuditResult, error) {
+	sleep, _ := time.ParseDuration(k8s.GetObjectMeta(resource).GetAnnotations()["sleep"])
+	time.Sleep(sleep)
+	return nil, nil
+}
+
+type namedAuditor struct{}
+
+func (a *namedAuditor) Audit(_ k8s.Resource, _ []k8s.Resource) ([]*AuditResult, error) {
+	return nil, nil
+}
+
+func (a *namedAuditor) String() string {
+	return "named"
+}
+
+type wrappingAuditor struct {
+	Auditable
+}
+
+func (a *wrappingAuditor) Unwrap() Auditable {
+	return a.Auditable
+}
+
+func TestProfile(t *testing.T) {
+	var resources []KubeResource
+	for i, sleep := range []string{"1ms", "20ms", "5ms"} {
+		pod := k8s.NewPod()
+		pod.Namespace = "default"
+		pod.Name = fmt.Sprintf("pod%d", i)
+		pod.Annotations = map[string]string{"sleep": sleep}
+		resources = append(resources, &kubeResource{object: pod})
+	}
+
+	profile := NewProfile()
+	auditor, err := New([]Auditable{&wrappingAuditor{&slowAuditor{}}, &namedAuditor{}}, WithProfile(profile))
+	require.NoError(t, err)
+	for _, resource := range resources {
+		_, err = auditResources([]KubeResource{resource}, auditor.auditors, auditor.concurrency, auditor.profile, auditor.strict)
+		require.NoError(t, err)
+	}
+
+	duration, count := profile.Duration()
+	assert.Equal(t, 3, count)
+	assert.GreaterOrEqual(t, duration, 26*time.Millisecond)
+
+	auditors := profile.Auditors()
+	require.Len(t, auditors, 2)
+	assert.Equal(t, "kubeaudit.slowAuditor", auditors[0].Auditor)
+	assert.Equal(t, 3, auditors[0].Resources)
+	assert.GreaterOrEqual(t, auditors[0].Total, 26*time.Millisecond)
+	assert.GreaterOrEqual(t, auditors[0].Max, 20*time.Millisecond)
+	assert.Equal(t, "Pod default/pod1", auditors[0].SlowestResource)
+	assert.Equal(t, "named", auditors[1].Auditor)
+
+	resourceProfiles := profile.Resources()
+	require.Len(t, resourceProfiles, 3)
+	for i, name := range []string{"Pod default/pod1", "Pod default/pod2", "Pod default/pod0"} {
+		assert.Equal(t, name, resourceProfiles[i].Resource)
+		assert.Equal(t, "kubeaudit.slowAuditor", resourceProfiles[i].SlowestAuditor)
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, profile.Write(&buf, 1))
+	output := buf.String()
+	assert.Contains(t, output, "Audited 3 resources in ")
+	assert.Contains(t, output, "AUDITOR")
+	assert.Contains(t, output, "kubeaudit.slowAuditor")
+	assert.NotContains(t, output, "named")
+	assert.Contains(t, output, "Pod default/pod1")
+	assert.NotContains(t, output, "Pod default/pod2")
+}
+
+func TestProfileKeepsSlowestResources(t *testing.T) {
+	profile := NewProfile()
+	for i := 0; i < maxProfiledResources+50; i++ {
+		pod := k8s.NewPod()
+		pod.Name = fmt.Sprintf("pod%d", i)
+		profile.recordResource(pod, time.Duration(i), []string{"auditor"}, []time.Duration{time.Duration(i)})
+	}
+
+	resources := profile.Resources()
+	require.Len(t, resources, maxProfiledResources)
+	assert.Equal(t, fmt.Sprintf("Pod pod%d", maxProfiledResources+49), resources[0].Resource)
+	assert.Equal(t, "Pod pod50", resources[len(resources)-1].Resource)
+	assert.Equal(t, maxProfiledResources+50, profile.Auditors()[0].Resources)
+}
@@ -0,0 +1,240 @@
+package kubeaudit
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Shopify/kubeaudit/internal/redact"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// RedactMode is how Redact() replaces the names of a report
+type RedactMode string
+
+const (
+	// RedactHash replaces names with a hash. The same name always has the same hash, so results for a resource can
+	// still be correlated across reports
+	RedactHash RedactMode = "hash"
+	// RedactMask replaces names with "[REDACTED]", so not even the hashes of names can be compared
+	RedactMask RedactMode = "mask"
+)
+
+// RedactModes are the modes of Redact()
+var RedactModes = []RedactMode{RedactHash, RedactMask}
+
+// RedactNames returns a copy of the report in which resource names and namespaces are replaced with a hash, so that
+// the report can be shared externally. Names are also replaced in the audit result metadata which holds them. The
+// resources in the returned report must not be fixed
+func (r *Report) RedactNames() *Report {
+	return r.redact(redactor{mode: RedactHash})
+}
+
+// Redact returns a copy of the report in which namespaces, resource names, image repositories and label values are
+// replaced as set by the mode, so that the report can be shared externally. They are also replaced in the audit result
+// metadata which holds them, while the auditors, rules, severities and messages of the audit results are kept. The
+// tags and digests of images are kept, so findings about them still apply. The resources in the returned report must
+// not be fixed
+func (r *Report) Redact(mode RedactMode) *Report {
+	return r.redact(redactor{mode: mode, imagesAndLabels: true})
+}
+
+// nameMetadataKeys are the keys of the audit result metadata whose values are names of resources or namespaces
+var nameMetadataKeys = map[string]bool{
+	"Namespace":         true,
+	"ResourceName":      true,
+	"ResourceNamespace": true,
+	"Pod":               true,
+	"PolicyName":        true,
+	"DaemonSet":         true,
+	"Secret":            true,
+	"ServiceAccount":    true,
+	CronJobMetadata:     true,
+}
+
+// kindNameMetadataKeys are the keys of the audit result metadata whose values are resources in the form
+// "<kind>/<name>"
+var kindNameMetadataKeys = map[string]bool{
+	"Binding":                     true,
+	"Role":                        true,
+	OverrideInheritedFromMetadata: true,
+}
+
+// imageMetadataKeys are the keys of the audit result metadata whose values are images
+var imageMetadataKeys = map[string]bool{
+	ContainerImageMetadata: true,
+	"Image":                true,
+}
+
+// redactor replaces the names of a report
+type redactor struct {
+	mode RedactMode
+	// imagesAndLabels also replaces image repositories and label values, on top of namespaces and resource names
+	imagesAndLabels bool
+}
+
+// name returns the replacement of a name, or of an image repository
+func (rd redactor) name(name string) string {
+	if name == "" {
+		return ""
+	}
+	if rd.mode == RedactMask {
+		return redact.Redacted
+	}
+	return redact.Name(name)
+}
+
+// image returns the image with its repository, including its registry, replaced
+func (rd redactor) image(image string) string {
+	repository, suffix := splitImageRepository(image)
+	return rd.name(repository) + suffix
+}
+
+func (r *Report) redact(rd redactor) *Report {
+	results := make([]Result, 0, len(r.RawResults()))
+	for _, result := range r.RawResults() {
+		auditResults := make([]*AuditResult, 0, len(result.GetAuditResults()))
+		for _, auditResult := range result.GetAuditResults() {
+			redacted := *auditResult
+			redacted.Metadata = rd.redactMetadata(auditResult.Metadata)
+			auditResults = append(auditResults, &redacted)
+		}
+
+		results = append(results, &WorkloadResult{
+			Resource:     rd.redactResource(result.GetResource()),
+			AuditResults: auditResults,
+		})
+	}
+
+	return r.withResults(results)
+}
+
+// redactMetadata returns a copy of the metadata with the values of the keys which hold names, images and label values
+// replaced. Messages and the other metadata are free text, in which names can't be told apart from other words, so
+// they are kept as they are
+func (rd redactor) redactMetadata(metadata Metadata) Metadata {
+	if metadata == nil {
+		return nil
+	}
+
+	redacted := make(Metadata, len(metadata))
+	for key, value := range metadata {
+		switch {
+		case nameMetadataKeys[key]:
+			value = rd.name(value)
+		case kindNameMetadataKeys[key]:
+			if i := strings.LastIndex(value, "/"); i >= 0 {
+				value = value[:i+1] + rd.name(value[i+1:])
+			} else {
+				value = rd.name(value)
+			}
+		case !rd.imagesAndLabels:
+		case imageMetadataKeys[key]:
+			value = rd.image(value)
+		case key == "Repository":
+			value = rd.name(value)
+		// The value of a label is reported with its key
+		case key == "Value" && metadata["Label"] != "" && !isCommonLabelValue(value):
+			value = rd.name(value)
+		}
+		redacted[key] = value
+	}
+	return redacted
+}
+
+// redactResource returns a copy of the resource with its name and namespace replaced, and with the images of its
+// containers and its label values replaced if they are redacted. The original bytes are not kept since they contain
+// the names
+func (rd redactor) redactResource(resource KubeResource) KubeResource {
+	if resource == nil {
+		return nil
+	}
+	if resource.Object() == nil {
+		return &kubeResource{}
+	}
+
+	object := resource.Object().DeepCopyObject()
+	if objectMeta := k8s.GetObjectMeta(object); objectMeta != nil {
+		objectMeta.SetName(rd.name(objectMeta.GetName()))
+		objectMeta.SetNamespace(rd.name(objectMeta.GetNamespace()))
+	}
+	if !rd.imagesAndLabels {
+		return &kubeResource{object: object}
+	}
+
+	for _, container := range k8s.GetContainers(object) {
+		container.Image = rd.image(container.Image)
+	}
+	if podSpec := k8s.GetPodSpec(object); podSpec != nil {
+		podSpec.ServiceAccountName = rd.name(podSpec.ServiceAccountName)
+		podSpec.DeprecatedServiceAccount = rd.name(podSpec.DeprecatedServiceAccount)
+	}
+	if pod, ok := object.(*k8s.PodV1); ok {
+		for _, statuses := range [][]apiv1.ContainerStatus{pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses, pod.Status.EphemeralContainerStatuses} {
+			for i := range statuses {
+				statuses[i].Image = rd.image(statuses[i].Image)
+				statuses[i].ImageID = rd.redactImageID(statuses[i].ImageID)
+			}
+		}
+	}
+
+	objectMetas := []interface {
+		GetLabels() map[string]string
+		SetLabels(map[string]string)
+	}{k8s.GetObjectMeta(object)}
+	if podObjectMeta := k8s.GetPodObjectMeta(object); podObjectMeta != nil && podObjectMeta != k8s.GetObjectMeta(object) {
+		objectMetas = append(objectMetas, podObjectMeta)
+	}
+	for _, objectMeta := range objectMetas {
+		if objectMeta == nil || len(objectMeta.GetLabels()) == 0 {
+			continue
+		}
+		labels := make(map[string]string, len(objectMeta.GetLabels()))
+		for key, value := range objectMeta.GetLabels() {
+			if isCommonLabelValue(value) {
+				labels[key] = value
+				continue
+			}
+			labels[key] = rd.name(value)
+		}
+		objectMeta.SetLabels(labels)
+	}
+
+	return &kubeResource{object: object}
+}
+
+// redactImageID replaces the repository of an image ID reported by the container runtime, such as
+// "docker-pullable://nginx@sha256:...", and keeps its digest
+func (rd redactor) redactImageID(imageID string) string {
+	i := strings.LastIndex(imageID, "@")
+	if i < 0 {
+		return imageID
+	}
+	prefix, repository := "", imageID[:i]
+	if j := strings.Index(repository, "://"); j >= 0 {
+		prefix, repository = repository[:j+3], repository[j+3:]
+	}
+	return prefix + rd.name(repository) + imageID[i:]
+}
+
+// splitImageRepository splits an image into its repository, including its registry, and the rest of the image: its
+// tag and digest, with their separators
+func splitImageRepository(image string) (repository, suffix string) {
+	repository = image
+	if i := strings.Index(repository, "@"); i >= 0 {
+		repository = repository[:i]
+	}
+	if i := strings.LastIndex(repository, ":"); i > strings.LastIndex(repository, "/") {
+		repository = repository[:i]
+	}
+	return repository, image[len(repository):]
+}
+
+// isCommonLabelValue returns true if the label value is a boolean or a number, which doesn't reveal anything
+func isCommonLabelValue(value string) bool {
+	if _, err := strconv.ParseBool(value); err == nil {
+		return true
+	}
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
@@ -0,0 +1,72 @@
+package kubeaudit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/netpols"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/internal/redact"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactDefaultNamespace(t *testing.T) {
+	manifest := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: default
+  namespace: default
+  labels:
+    app: default
+spec:
+  selector:
+    matchLabels:
+      app: default
+  template:
+    metadata:
+      labels:
+        app: default
+    spec:
+      containers:
+        - name: container
+          image: scratch
+          securityContext:
+            privileged: true
+`
+	auditor, err := kubeaudit.New([]kubeaudit.Auditable{netpols.New(), privileged.New()})
+	require.NoError(t, err)
+	report, err := auditor.AuditManifest("", strings.NewReader(manifest))
+	require.NoError(t, err)
+
+	results := report.Results()
+	redactedResults := report.Redact(kubeaudit.RedactHash).Results()
+	require.Len(t, redactedResults, len(results))
+	require.Len(t, results, 2)
+
+	messages := map[string]string{}
+	for i, result := range redactedResults {
+		objectMeta := k8s.GetObjectMeta(result.GetResource().Object())
+		assert.Equal(t, redact.Name("default"), objectMeta.GetName())
+		assert.NotEqual(t, "default", objectMeta.GetNamespace())
+
+		// The messages, in which "default" is also a word, are kept as they are
+		auditResults := result.GetAuditResults()
+		for j, auditResult := range auditResults {
+			assert.Equal(t, results[i].GetAuditResults()[j].Message, auditResult.Message)
+			if namespace, ok := auditResult.Metadata["Namespace"]; ok {
+				assert.Equal(t, redact.Name("default"), namespace)
+			}
+			messages[auditResult.Rule] = auditResult.Message
+		}
+	}
+	assert.Equal(t, "Namespace is missing a default deny ingress and egress NetworkPolicy.", messages[netpols.MissingDefaultDenyIngressAndEgressNetworkPolicy])
+	assert.Contains(t, messages, privileged.PrivilegedTrue)
+}
@@ -1,8 +1,15 @@
 package kubeaudit
 
-import "github.com/Shopify/kubeaudit/pkg/k8s"
+import (
+	"fmt"
+	"strings"
 
-// AuditResult severity levels. They also correspond to log levels
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+)
+
+// AuditResult severity levels. They also correspond to log levels. Custom severity levels registered with
+// RegisterSeverity are ordered between and around them, so severities are compared with SeverityLevel.Compare
+// rather than by their value
 const (
 	// Info is used for informational audit results where no action is required
 	Info SeverityLevel = 0
@@ -30,20 +37,100 @@ func (s SeverityLevel) String() string {
 		return "warning"
 	case Error:
 		return "error"
-	default:
-		return "unknown"
 	}
+	if name, ok := getSeverityName(s); ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Builtin returns the highest of Error, Warn and Info which is not above the severity, or Info for severities below
+// Info. Outputs which only support the built-in levels, such as SARIF and log levels, report custom severities as it
+func (s SeverityLevel) Builtin() SeverityLevel {
+	switch {
+	case s.AtLeast(Error):
+		return Error
+	case s.AtLeast(Warn):
+		return Warn
+	}
+	return Info
+}
+
+// IsBuiltin returns true if the severity is Error, Warn or Info
+func (s SeverityLevel) IsBuiltin() bool {
+	return s == Error || s == Warn || s == Info
+}
+
+// ParseSeverity parses a severity level, one of "error", "warning" (or "warn"), "info" and the names of the custom
+// severity levels registered with RegisterSeverity
+func ParseSeverity(s string) (SeverityLevel, error) {
+	switch strings.ToLower(s) {
+	case "info":
+		return Info, nil
+	case "warn", "warning":
+		return Warn, nil
+	case "error":
+		return Error, nil
+	}
+	if severity, ok := getSeverityLevel(strings.ToLower(s)); ok {
+		return severity, nil
+	}
+	return Info, fmt.Errorf("invalid severity %q, expected one of %s", s, severityNames())
 }
 
 // AuditResult represents a potential security issue. There may be multiple AuditResults per resource and audit
 type AuditResult struct {
 	Auditor    string        // Auditor name
 	Rule       string        // Rule uniquely identifies a type of violation
-	Severity   SeverityLevel // Severity is one of Error, Warn, or Info
+	Severity   SeverityLevel // Severity is one of Error, Warn, Info or a custom severity level
 	Message    string        // Message is a human-readable description of the audit result
 	PendingFix PendingFix    // PendingFix is the fix that will be applied to automatically fix the security issue
 	Metadata   Metadata      // Metadata includes additional context for an audit result
 	FilePath   string        // Manifest file path
+	Line       int           // Line in the manifest file of the field the result is about, or 0 if unknown
+	Column     int           // Column in the manifest file of the field the result is about, or 0 if unknown
+	// Field is the path of the field the result is about, relative to the pod spec of the resource, or to the resource
+	// if it does not have pods, such as "hostNetwork". Fields of the container named by the Container metadata start
+	// with ContainerFieldPrefix, such as "container.securityContext.privileged". It is used to find the line and
+	// column of the result in manifests, and results without a field are located at the start of their resource
+	Field string
+	// SuppressedBy is the mechanism which suppressed the result, if any. Reports leave suppressed results out and
+	// count them, except for overridden results which are still reported (see Report.Suppressions())
+	SuppressedBy SuppressionMechanism
+	// OverrideLabel is the label which overrode the result, if it is overridden, such as
+	// "container.kubeaudit.io/my-container.allow-privileged"
+	OverrideLabel string
+	// ExpiredOverride is the override which would have overridden the result if its expiry date had not passed, if
+	// any. An ExpiredAuditorOverride result is added for it
+	ExpiredOverride *ExpiredOverride
+	// References link the rule to its documentation and to the controls of security frameworks
+	References []Reference
+}
+
+// ReferenceType is the kind of resource a reference points to
+type ReferenceType string
+
+const (
+	// ReferenceDocs is the kubeaudit documentation of the rule
+	ReferenceDocs ReferenceType = "docs"
+	// ReferenceKubernetes is the Kubernetes documentation of the feature the rule audits
+	ReferenceKubernetes ReferenceType = "kubernetes"
+	// ReferencePSS is a Pod Security Standards control
+	ReferencePSS ReferenceType = "pss"
+	// ReferenceCIS is a control of the CIS Kubernetes Benchmark
+	ReferenceCIS ReferenceType = "cis"
+	// ReferenceNSA is a control of the NSA/CISA Kubernetes Hardening Guide
+	ReferenceNSA ReferenceType = "nsa"
+	// ReferenceCWE is a Common Weakness Enumeration entry
+	ReferenceCWE ReferenceType = "cwe"
+)
+
+// Reference is a link from an audit result to documentation or a framework control, such as CIS 5.2.2 or CWE-250.
+// ID is empty for documentation references and URL is empty for controls which can't be linked to
+type Reference struct {
+	Type ReferenceType `json:"type"`
+	ID   string        `json:"id,omitempty"`
+	URL  string        `json:"url,omitempty"`
 }
 
 func (result *AuditResult) Fix(resource k8s.Resource) (newResources []k8s.Resource) {
@@ -71,6 +158,47 @@ type PendingFix interface {
 	Apply(k8s.Resource) []k8s.Resource
 }
 
+// FixPatch returns the patch which applies the fix to a live cluster, or nil if the fix does not support patches
+func (result *AuditResult) FixPatch() *Patch {
+	if patchable, ok := result.PendingFix.(PatchableFix); ok {
+		return patchable.Patch()
+	}
+	return nil
+}
+
+// PatchableFix is a PendingFix which can also be applied to the resources of a live cluster, which cannot be fixed in
+// place like a manifest
+type PatchableFix interface {
+	PendingFix
+	// Patch returns the patch which applies the fix to the cluster
+	Patch() *Patch
+}
+
+// Patch is a JSON merge patch of a resource in a cluster
+type Patch struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	// MergePatch is the JSON merge patch, eg. {"automountServiceAccountToken":false}
+	MergePatch string
+}
+
+// Command returns the kubectl command which applies the patch
+func (p *Patch) Command() string {
+	resourceType := strings.ToLower(p.Kind)
+	if gv := strings.SplitN(p.APIVersion, "/", 2); len(gv) == 2 {
+		// Kinds from API groups are fully qualified in case another group has a kind with the same name
+		resourceType = fmt.Sprintf("%s.%s.%s", resourceType, gv[1], gv[0])
+	}
+
+	command := fmt.Sprintf("kubectl patch %s %s", resourceType, p.Name)
+	if p.Namespace != "" {
+		command += " --namespace " + p.Namespace
+	}
+	return command + " --type merge --patch '" + p.MergePatch + "'"
+}
+
 // Metadata holds metadata for a potential security issue
 type Metadata = map[string]string
 
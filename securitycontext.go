@@ -0,0 +1,133 @@
+package kubeaudit
+
+import (
+	"reflect"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// fixResource applies the fixes of the audit results of the result to its resource and returns the resources created
+// by the fixes. The fixes set runAsNonRoot and seccompProfile in the SecurityContext of every container, so once the
+// resource is fixed they are moved to the PodSecurityContext where the containers agree (see mergeSecurityContexts)
+func fixResource(result Result) []k8s.Resource {
+	var newResources []k8s.Resource
+	fixed := false
+	for _, auditResult := range result.GetAuditResults() {
+		if auditResult.PendingFix == nil {
+			continue
+		}
+		newResources = append(newResources, auditResult.Fix(result.GetResource().Object())...)
+		fixed = true
+	}
+
+	if fixed {
+		mergeSecurityContexts(result.GetResource().Object())
+	}
+	return newResources
+}
+
+// mergeSecurityContexts moves runAsNonRoot and seccompProfile from the SecurityContext of the containers of the
+// resource to its PodSecurityContext when every container sets them to the same value. Containers inherit them from
+// the PodSecurityContext, so the pod runs the same, and the values are set once instead of in every container. When the
+// pod then runs as non-root and mounts persistent volumes, fsGroup is set to the group the containers run as so the
+// volumes stay writable
+func mergeSecurityContexts(resource k8s.Resource) {
+	podSpec := k8s.GetPodSpec(resource)
+	containers := k8s.GetContainers(resource)
+	if podSpec == nil || len(containers) == 0 {
+		return
+	}
+
+	if runAsNonRoot, ok := getCommonValue(containers, func(sc *apiv1.SecurityContext) interface{} {
+		return sc.RunAsNonRoot
+	}); ok {
+		getPodSecurityContext(podSpec).RunAsNonRoot = runAsNonRoot.(*bool)
+		for _, container := range containers {
+			container.SecurityContext.RunAsNonRoot = nil
+		}
+	}
+
+	if seccompProfile, ok := getCommonValue(containers, func(sc *apiv1.SecurityContext) interface{} {
+		return sc.SeccompProfile
+	}); ok {
+		getPodSecurityContext(podSpec).SeccompProfile = seccompProfile.(*apiv1.SeccompProfile)
+		for _, container := range containers {
+			container.SecurityContext.SeccompProfile = nil
+		}
+	}
+
+	for _, container := range containers {
+		if container.SecurityContext != nil && reflect.DeepEqual(*container.SecurityContext, apiv1.SecurityContext{}) {
+			container.SecurityContext = nil
+		}
+	}
+
+	setFSGroup(podSpec, containers)
+}
+
+// getCommonValue returns the value of a field of the SecurityContext of the containers if all of them set it to the
+// same value
+func getCommonValue(containers []*k8s.ContainerV1, field func(*apiv1.SecurityContext) interface{}) (interface{}, bool) {
+	var common interface{}
+	for i, container := range containers {
+		if container.SecurityContext == nil || reflect.ValueOf(field(container.SecurityContext)).IsNil() {
+			return nil, false
+		}
+		value := field(container.SecurityContext)
+		if i > 0 && !reflect.DeepEqual(value, common) {
+			return nil, false
+		}
+		common = value
+	}
+	return common, true
+}
+
+// setFSGroup sets fsGroup in the PodSecurityContext to the group the containers run as, if the pod runs as non-root,
+// mounts persistent volumes and doesn't set fsGroup yet. Volumes are owned by root unless fsGroup is set, so containers
+// running as non-root can't write to them
+func setFSGroup(podSpec *k8s.PodSpecV1, containers []*k8s.ContainerV1) {
+	if podSpec.SecurityContext == nil || podSpec.SecurityContext.FSGroup != nil || !hasPersistentVolume(podSpec) {
+		return
+	}
+	if podSpec.SecurityContext.RunAsNonRoot == nil || !*podSpec.SecurityContext.RunAsNonRoot {
+		return
+	}
+
+	var group *int64
+	for _, container := range containers {
+		if container.SecurityContext != nil && container.SecurityContext.RunAsNonRoot != nil && !*container.SecurityContext.RunAsNonRoot {
+			return
+		}
+		containerGroup := podSpec.SecurityContext.RunAsGroup
+		if container.SecurityContext != nil && container.SecurityContext.RunAsGroup != nil {
+			containerGroup = container.SecurityContext.RunAsGroup
+		}
+		if containerGroup == nil || (group != nil && *group != *containerGroup) {
+			return
+		}
+		group = containerGroup
+	}
+
+	if *group == 0 {
+		return
+	}
+	fsGroup := *group
+	podSpec.SecurityContext.FSGroup = &fsGroup
+}
+
+func hasPersistentVolume(podSpec *k8s.PodSpecV1) bool {
+	for _, volume := range podSpec.Volumes {
+		if volume.PersistentVolumeClaim != nil || volume.Ephemeral != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func getPodSecurityContext(podSpec *k8s.PodSpecV1) *apiv1.PodSecurityContext {
+	if podSpec.SecurityContext == nil {
+		podSpec.SecurityContext = &apiv1.PodSecurityContext{}
+	}
+	return podSpec.SecurityContext
+}
@@ -0,0 +1,112 @@
+package kubeaudit
+
+import (
+	"testing"
+
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+func TestMergeSecurityContexts(t *testing.T) {
+	runtimeDefault := &apiv1.SeccompProfile{Type: apiv1.SeccompProfileTypeRuntimeDefault}
+	localhost := &apiv1.SeccompProfile{Type: apiv1.SeccompProfileTypeLocalhost, LocalhostProfile: stringPtr("profile.json")}
+	pvc := apiv1.Volume{Name: "data", VolumeSource: apiv1.VolumeSource{PersistentVolumeClaim: &apiv1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}
+
+	cases := []struct {
+		testName           string
+		podSecurityContext *apiv1.PodSecurityContext
+		securityContexts   []*apiv1.SecurityContext
+		volumes            []apiv1.Volume
+		expectedPod        *apiv1.PodSecurityContext
+		expectedContainers []*apiv1.SecurityContext
+	}{
+		{
+			testName:           "Containers agree",
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue(), SeccompProfile: runtimeDefault}, {RunAsNonRoot: k8s.NewTrue(), SeccompProfile: runtimeDefault}},
+			expectedPod:        &apiv1.PodSecurityContext{RunAsNonRoot: k8s.NewTrue(), SeccompProfile: runtimeDefault},
+			expectedContainers: []*apiv1.SecurityContext{nil, nil},
+		},
+		{
+			testName:           "Containers agree with other fields",
+			podSecurityContext: &apiv1.PodSecurityContext{RunAsNonRoot: k8s.NewFalse()},
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue(), Privileged: k8s.NewFalse()}, {RunAsNonRoot: k8s.NewTrue()}},
+			expectedPod:        &apiv1.PodSecurityContext{RunAsNonRoot: k8s.NewTrue()},
+			expectedContainers: []*apiv1.SecurityContext{{Privileged: k8s.NewFalse()}, nil},
+		},
+		{
+			testName:           "Containers disagree",
+			podSecurityContext: &apiv1.PodSecurityContext{SeccompProfile: runtimeDefault},
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue(), SeccompProfile: localhost}, {RunAsNonRoot: k8s.NewFalse()}},
+			expectedPod:        &apiv1.PodSecurityContext{SeccompProfile: runtimeDefault},
+			expectedContainers: []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue(), SeccompProfile: localhost}, {RunAsNonRoot: k8s.NewFalse()}},
+		},
+		{
+			testName:           "Container without SecurityContext",
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue()}, nil},
+			expectedPod:        nil,
+			expectedContainers: []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue()}, nil},
+		},
+		{
+			testName:           "fsGroup is set to the group of the pod",
+			podSecurityContext: &apiv1.PodSecurityContext{RunAsGroup: int64Ptr(1000)},
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue()}},
+			volumes:            []apiv1.Volume{pvc},
+			expectedPod:        &apiv1.PodSecurityContext{RunAsNonRoot: k8s.NewTrue(), RunAsGroup: int64Ptr(1000), FSGroup: int64Ptr(1000)},
+			expectedContainers: []*apiv1.SecurityContext{nil},
+		},
+		{
+			testName:           "fsGroup is set to the group of the containers",
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue(), RunAsGroup: int64Ptr(2000)}, {RunAsNonRoot: k8s.NewTrue(), RunAsGroup: int64Ptr(2000)}},
+			volumes:            []apiv1.Volume{pvc},
+			expectedPod:        &apiv1.PodSecurityContext{RunAsNonRoot: k8s.NewTrue(), FSGroup: int64Ptr(2000)},
+			expectedContainers: []*apiv1.SecurityContext{{RunAsGroup: int64Ptr(2000)}, {RunAsGroup: int64Ptr(2000)}},
+		},
+		{
+			testName:           "fsGroup is not set if the containers run as different groups",
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue(), RunAsGroup: int64Ptr(2000)}, {RunAsNonRoot: k8s.NewTrue()}},
+			volumes:            []apiv1.Volume{pvc},
+			expectedPod:        &apiv1.PodSecurityContext{RunAsNonRoot: k8s.NewTrue()},
+			expectedContainers: []*apiv1.SecurityContext{{RunAsGroup: int64Ptr(2000)}, nil},
+		},
+		{
+			testName:           "fsGroup is not set without persistent volumes",
+			podSecurityContext: &apiv1.PodSecurityContext{RunAsGroup: int64Ptr(1000)},
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue()}},
+			expectedPod:        &apiv1.PodSecurityContext{RunAsNonRoot: k8s.NewTrue(), RunAsGroup: int64Ptr(1000)},
+			expectedContainers: []*apiv1.SecurityContext{nil},
+		},
+		{
+			testName:           "fsGroup is not changed",
+			podSecurityContext: &apiv1.PodSecurityContext{RunAsGroup: int64Ptr(1000), FSGroup: int64Ptr(3000)},
+			securityContexts:   []*apiv1.SecurityContext{{RunAsNonRoot: k8s.NewTrue()}},
+			volumes:            []apiv1.Volume{pvc},
+			expectedPod:        &apiv1.PodSecurityContext{RunAsNonRoot: k8s.NewTrue(), RunAsGroup: int64Ptr(1000), FSGroup: int64Ptr(3000)},
+			expectedContainers: []*apiv1.SecurityContext{nil},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.testName, func(t *testing.T) {
+			pod := &k8s.PodV1{Spec: apiv1.PodSpec{SecurityContext: tc.podSecurityContext, Volumes: tc.volumes}}
+			for i, securityContext := range tc.securityContexts {
+				pod.Spec.Containers = append(pod.Spec.Containers, apiv1.Container{Name: string(rune('a' + i)), SecurityContext: securityContext})
+			}
+
+			mergeSecurityContexts(pod)
+
+			assert.Equal(t, tc.expectedPod, pod.Spec.SecurityContext)
+			for i, container := range pod.Spec.Containers {
+				assert.Equal(t, tc.expectedContainers[i], container.SecurityContext, container.Name)
+			}
+		})
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,182 @@
+package kubeaudit
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrInvalidSeverity is returned when a custom severity level cannot be registered
+var ErrInvalidSeverity = errors.New("invalid severity")
+
+// firstCustomSeverity is the value of the first custom severity level which is registered. Custom levels are given
+// the next free value from it, in the order they are registered, so their values don't collide with the built-in
+// levels. The values of custom levels aren't ordered, they are compared with SeverityLevel.Compare
+const firstCustomSeverity SeverityLevel = 100
+
+// SeverityDefinition declares a custom severity level, for organizations whose vulnerability management tiers don't
+// match error, warning and info. Exactly one of Above and Below must be set
+type SeverityDefinition struct {
+	// Name is the name of the severity level, eg. "critical". It is case-insensitive
+	Name string `yaml:"name"`
+	// Above is the name of the severity level the custom level is directly above, eg. "error"
+	Above string `yaml:"above"`
+	// Below is the name of the severity level the custom level is directly below, eg. "warning"
+	Below string `yaml:"below"`
+}
+
+// customSeverities is the registry of custom severity levels. It is safe for concurrent use, although levels are
+// usually only registered when the config is loaded
+var customSeverities = struct {
+	sync.RWMutex
+	levels map[string]SeverityLevel
+	names  map[SeverityLevel]string
+	// order has the built-in and custom levels from the lowest to the highest, and ranks the index of each level in it
+	order []SeverityLevel
+	ranks map[SeverityLevel]int
+	next  SeverityLevel
+}{
+	levels: map[string]SeverityLevel{},
+	names:  map[SeverityLevel]string{},
+	order:  []SeverityLevel{Info, Warn, Error},
+	ranks:  map[SeverityLevel]int{Info: 0, Warn: 1, Error: 2},
+	next:   firstCustomSeverity,
+}
+
+// RegisterSeverity registers a custom severity level directly above or below a built-in level or a custom level which
+// is already registered. A level placed next to a level which already has a neighbour on that side goes between them.
+// Registering a level which is already registered above or below the same level is a no-op, so the same config can be
+// loaded again
+func RegisterSeverity(definition SeverityDefinition) (SeverityLevel, error) {
+	name := strings.ToLower(strings.TrimSpace(definition.Name))
+	if name == "" {
+		return 0, fmt.Errorf("%w: name is required", ErrInvalidSeverity)
+	}
+	if (definition.Above == "") == (definition.Below == "") {
+		return 0, fmt.Errorf("%w %q: exactly one of above and below is required", ErrInvalidSeverity, name)
+	}
+
+	relativeTo := definition.Above
+	if relativeTo == "" {
+		relativeTo = definition.Below
+	}
+	relativeLevel, err := ParseSeverity(relativeTo)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %s", ErrInvalidSeverity, name, err)
+	}
+
+	customSeverities.Lock()
+	defer customSeverities.Unlock()
+
+	if existing, ok := customSeverities.levels[name]; ok {
+		order := compareSeverityLevels(existing, relativeLevel)
+		if (definition.Above != "" && order > 0) || (definition.Below != "" && order < 0) {
+			return existing, nil
+		}
+		return 0, fmt.Errorf("%w %q: already registered at a different level", ErrInvalidSeverity, name)
+	}
+	switch name {
+	case "error", "warn", "warning", "info":
+		return 0, fmt.Errorf("%w %q: conflicts with a built-in severity", ErrInvalidSeverity, name)
+	}
+
+	level := customSeverities.next
+	customSeverities.next++
+
+	index := customSeverities.ranks[relativeLevel]
+	if definition.Above != "" {
+		index++
+	}
+	order := append([]SeverityLevel{}, customSeverities.order[:index]...)
+	order = append(order, level)
+	order = append(order, customSeverities.order[index:]...)
+
+	customSeverities.order = order
+	customSeverities.ranks = make(map[SeverityLevel]int, len(order))
+	for rank, other := range order {
+		customSeverities.ranks[other] = rank
+	}
+	customSeverities.levels[name] = level
+	customSeverities.names[level] = name
+	return level, nil
+}
+
+// Severities returns the built-in and custom severity levels, from the highest to the lowest
+func Severities() []SeverityLevel {
+	customSeverities.RLock()
+	defer customSeverities.RUnlock()
+
+	levels := make([]SeverityLevel, 0, len(customSeverities.order))
+	for i := len(customSeverities.order) - 1; i >= 0; i-- {
+		levels = append(levels, customSeverities.order[i])
+	}
+	return levels
+}
+
+// Compare returns a negative number if the severity is lower than the other severity, zero if they are the same and a
+// positive number if it is higher, counting the built-in and custom levels. Severities which aren't registered are
+// compared by their value
+func (s SeverityLevel) Compare(other SeverityLevel) int {
+	customSeverities.RLock()
+	defer customSeverities.RUnlock()
+	return compareSeverityLevels(s, other)
+}
+
+// AtLeast returns true if the severity is the same as or higher than the minimum severity
+func (s SeverityLevel) AtLeast(min SeverityLevel) bool {
+	return s.Compare(min) >= 0
+}
+
+// Adjust returns the severity level the number of levels above the level, or below it for negative numbers, counting
+// the built-in and custom levels. The highest and lowest levels are returned for numbers which go past them
+func (s SeverityLevel) Adjust(levels int) SeverityLevel {
+	customSeverities.RLock()
+	defer customSeverities.RUnlock()
+
+	rank, ok := customSeverities.ranks[s]
+	if !ok {
+		return s
+	}
+	rank += levels
+	if rank < 0 {
+		rank = 0
+	}
+	if last := len(customSeverities.order) - 1; rank > last {
+		rank = last
+	}
+	return customSeverities.order[rank]
+}
+
+// compareSeverityLevels compares two severity levels by their rank. The caller must hold the lock of customSeverities
+func compareSeverityLevels(a, b SeverityLevel) int {
+	rankA, okA := customSeverities.ranks[a]
+	rankB, okB := customSeverities.ranks[b]
+	if !okA || !okB {
+		rankA, rankB = int(a), int(b)
+	}
+	return rankA - rankB
+}
+
+func getSeverityName(level SeverityLevel) (string, bool) {
+	customSeverities.RLock()
+	defer customSeverities.RUnlock()
+	name, ok := customSeverities.names[level]
+	return name, ok
+}
+
+func getSeverityLevel(name string) (SeverityLevel, bool) {
+	customSeverities.RLock()
+	defer customSeverities.RUnlock()
+	level, ok := customSeverities.levels[name]
+	return level, ok
+}
+
+// severityNames returns the quoted names of the severity levels, from the highest to the lowest, for error messages
+func severityNames() string {
+	var names []string
+	for _, level := range Severities() {
+		names = append(names, fmt.Sprintf("%q", level.String()))
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
@@ -0,0 +1,112 @@
+package kubeaudit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterSeverity(t *testing.T) {
+	critical, err := RegisterSeverity(SeverityDefinition{Name: "Critical", Above: "error"})
+	require.NoError(t, err)
+	high, err := RegisterSeverity(SeverityDefinition{Name: "high", Below: "critical"})
+	require.NoError(t, err)
+	low, err := RegisterSeverity(SeverityDefinition{Name: "low", Below: "warning"})
+	require.NoError(t, err)
+	debug, err := RegisterSeverity(SeverityDefinition{Name: "debug", Below: "info"})
+	require.NoError(t, err)
+
+	var severities []SeverityLevel
+	for _, severity := range Severities() {
+		switch severity {
+		case critical, high, Error, Warn, low, Info, debug:
+			severities = append(severities, severity)
+		}
+	}
+	assert.Equal(t, []SeverityLevel{critical, high, Error, Warn, low, Info, debug}, severities)
+
+	for _, tc := range []struct {
+		severity SeverityLevel
+		name     string
+		builtin  SeverityLevel
+	}{
+		{critical, "critical", Error},
+		{high, "high", Error},
+		{low, "low", Info},
+		{debug, "debug", Info},
+		{Warn, "warning", Warn},
+	} {
+		assert.Equal(t, tc.name, tc.severity.String())
+		assert.Equal(t, tc.builtin, tc.severity.Builtin(), tc.name)
+		assert.Equal(t, tc.severity == Warn, tc.severity.IsBuiltin(), tc.name)
+
+		parsed, err := ParseSeverity(tc.name)
+		require.NoError(t, err)
+		assert.Equal(t, tc.severity, parsed)
+	}
+
+	// Registering a severity again with the same placement is a no-op
+	again, err := RegisterSeverity(SeverityDefinition{Name: "critical", Above: "error"})
+	require.NoError(t, err)
+	assert.Equal(t, critical, again)
+
+	_, err = ParseSeverity("blocker")
+	assert.ErrorContains(t, err, `invalid severity "blocker", expected one of "critical", "high", "error", "warning"`)
+}
+
+func TestRegisterSeverityInvalid(t *testing.T) {
+	_, err := RegisterSeverity(SeverityDefinition{Name: "moderate", Above: "warning"})
+	require.NoError(t, err)
+
+	for _, definition := range []SeverityDefinition{
+		{Name: "", Above: "error"},
+		{Name: "urgent"},
+		{Name: "urgent", Above: "error", Below: "error"},
+		{Name: "urgent", Above: "unknown"},
+		{Name: "warn", Above: "error"},
+		{Name: "moderate", Below: "info"},
+	} {
+		_, err := RegisterSeverity(definition)
+		assert.ErrorIs(t, err, ErrInvalidSeverity, definition.Name)
+	}
+
+}
+
+func TestRegisterSeverityBetween(t *testing.T) {
+	// Levels registered next to the same level go between it and the levels registered before them, without running
+	// out of room between the built-in levels
+	var levels []SeverityLevel
+	for i := 0; i < 10; i++ {
+		level, err := RegisterSeverity(SeverityDefinition{Name: "elevated-" + string(rune('a'+i)), Above: "warning"})
+		require.NoError(t, err)
+		assert.True(t, level.Compare(Warn) > 0)
+		assert.True(t, level.Compare(Error) < 0)
+		for _, previous := range levels {
+			assert.True(t, level.Compare(previous) < 0)
+		}
+		assert.Equal(t, Warn, level.Builtin())
+		levels = append(levels, level)
+	}
+
+	// The built-in levels keep their values
+	assert.Equal(t, SeverityLevel(0), Info)
+	assert.Equal(t, SeverityLevel(1), Warn)
+	assert.Equal(t, SeverityLevel(2), Error)
+}
+
+func TestSeverityAdjust(t *testing.T) {
+	// Other tests may have registered custom severities, which are counted as levels too
+	levels := Severities()
+	for i, level := range levels {
+		assert.Equal(t, level, level.Adjust(0))
+		if i > 0 {
+			assert.Equal(t, levels[i-1], level.Adjust(1), level.String())
+		}
+		if i+1 < len(levels) {
+			assert.Equal(t, levels[i+1], level.Adjust(-1), level.String())
+		}
+	}
+	assert.Equal(t, levels[0], Info.Adjust(100))
+	assert.Equal(t, levels[len(levels)-1], Error.Adjust(-100))
+}
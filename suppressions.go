@@ -0,0 +1,120 @@
+package kubeaudit
+
+// SuppressionMechanism is a way findings can be suppressed, so that they are not reported as security issues
+type SuppressionMechanism string
+
+const (
+	// SuppressedByOverride is set on the results of resources with an override label for the auditor. Overridden
+	// results are still reported as info, with the rule name ending in "Allowed"
+	SuppressedByOverride SuppressionMechanism = "override"
+	// SuppressedByConfig is set on the results of rules disabled in the kubeaudit config
+	SuppressedByConfig SuppressionMechanism = "config"
+	// SuppressedByExclusion is set on the results of resources excluded by the exclusions of the kubeaudit config, such
+	// as the resources of vendored manifests
+	SuppressedByExclusion SuppressionMechanism = "exclusion"
+	// SuppressedByBaseline is set on the results which are in the baseline the report is compared to
+	SuppressedByBaseline SuppressionMechanism = "baseline"
+)
+
+// SuppressionMechanisms are the suppression mechanisms in the order they are reported
+var SuppressionMechanisms = []SuppressionMechanism{SuppressedByOverride, SuppressedByConfig, SuppressedByExclusion, SuppressedByBaseline}
+
+func newReport(results []Result, suppressions map[SuppressionMechanism]int) *Report {
+	report := &Report{results: make([]Result, 0, len(results)), suppressions: suppressions}
+	for _, result := range results {
+		report.results = append(report.results, report.removeSuppressed(result))
+	}
+	return report
+}
+
+// removeSuppressed returns the result without its suppressed audit results and counts them. The result is returned
+// as is if none of its audit results are removed
+func (r *Report) removeSuppressed(result Result) Result {
+	removed := false
+	for _, auditResult := range result.GetAuditResults() {
+		if isRemoved(auditResult) {
+			removed = true
+			break
+		}
+	}
+	if !removed {
+		return result
+	}
+
+	auditResults := make([]*AuditResult, 0, len(result.GetAuditResults()))
+	for _, auditResult := range result.GetAuditResults() {
+		if isRemoved(auditResult) {
+			r.suppressions[auditResult.SuppressedBy]++
+			continue
+		}
+		auditResults = append(auditResults, auditResult)
+	}
+	return &WorkloadResult{Resource: result.GetResource(), AuditResults: auditResults}
+}
+
+func isRemoved(auditResult *AuditResult) bool {
+	return auditResult.SuppressedBy != "" && auditResult.SuppressedBy != SuppressedByOverride
+}
+
+// withResults returns a report of the results which keeps the suppression counts of r
+func (r *Report) withResults(results []Result) *Report {
+	suppressions := make(map[SuppressionMechanism]int, len(r.suppressions))
+	for mechanism, count := range r.suppressions {
+		suppressions[mechanism] = count
+	}
+	return newReport(results, suppressions)
+}
+
+// Suppress returns a report without the audit results which the mechanism suppresses, as decided by the suppressed
+// function. The suppressed results are counted in the suppressions of the report, along with the ones of r
+func (r *Report) Suppress(mechanism SuppressionMechanism, suppressed func(result Result, auditResult *AuditResult) bool) *Report {
+	results := make([]Result, 0, len(r.results))
+	for _, result := range r.results {
+		auditResults := make([]*AuditResult, 0, len(result.GetAuditResults()))
+		for _, auditResult := range result.GetAuditResults() {
+			if suppressed(result, auditResult) {
+				// The audit result is copied so that it stays in the results of r
+				copied := *auditResult
+				copied.SuppressedBy = mechanism
+				auditResult = &copied
+			}
+			auditResults = append(auditResults, auditResult)
+		}
+		results = append(results, &WorkloadResult{Resource: result.GetResource(), AuditResults: auditResults})
+	}
+	return r.withResults(results)
+}
+
+// Suppressions returns the number of findings suppressed by each mechanism, regardless of their severity. Overridden
+// findings are counted although they are still in the results. Mechanisms which suppressed no findings are left out
+func (r *Report) Suppressions() map[SuppressionMechanism]int {
+	suppressions := make(map[SuppressionMechanism]int, len(r.suppressions)+1)
+	for mechanism, count := range r.suppressions {
+		if count > 0 {
+			suppressions[mechanism] = count
+		}
+	}
+	for _, result := range r.results {
+		for _, auditResult := range result.GetAuditResults() {
+			if auditResult.SuppressedBy == SuppressedByOverride {
+				suppressions[SuppressedByOverride]++
+			}
+		}
+	}
+	return suppressions
+}
+
+// Overrides returns the findings which were overridden by a label on their resource, so suppressions are visible
+// rather than silent. The OverrideLabel of their audit result is the label which overrode them, and the value of the
+// label is in the OverrideReason metadata unless it is empty or "true"
+func (r *Report) Overrides() []Finding {
+	var overrides []Finding
+	for _, result := range r.results {
+		for _, finding := range getFindings(result) {
+			if finding.AuditResult.SuppressedBy == SuppressedByOverride {
+				overrides = append(overrides, finding)
+			}
+		}
+	}
+	return overrides
+}
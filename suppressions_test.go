@@ -0,0 +1,118 @@
+package kubeaudit_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Shopify/kubeaudit"
+	"github.com/Shopify/kubeaudit/auditors/all"
+	"github.com/Shopify/kubeaudit/auditors/limits"
+	"github.com/Shopify/kubeaudit/auditors/privileged"
+	"github.com/Shopify/kubeaudit/config"
+	"github.com/Shopify/kubeaudit/internal/test"
+	"github.com/Shopify/kubeaudit/pkg/k8s"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const privilegedFixtureDir = "auditors/privileged/fixtures"
+
+func getSuppressionsReport(t *testing.T, fixture string) *kubeaudit.Report {
+	enabledAuditors := map[string]bool{}
+	for _, auditorName := range all.AuditorNames {
+		enabledAuditors[auditorName] = auditorName == privileged.Name || auditorName == limits.Name
+	}
+	auditables, err := all.Auditors(config.KubeauditConfig{
+		EnabledAuditors: enabledAuditors,
+		Rules:           map[string]config.RuleConfig{limits.LimitsNotSet: {Enabled: k8s.NewFalse()}},
+	})
+	require.NoError(t, err)
+	return test.GetReport(t, privilegedFixtureDir, fixture, auditables, "", test.MANIFEST_MODE)
+}
+
+func getRules(report *kubeaudit.Report) []string {
+	var rules []string
+	for _, result := range report.Results() {
+		for _, auditResult := range result.GetAuditResults() {
+			rules = append(rules, auditResult.Rule)
+		}
+	}
+	return rules
+}
+
+func TestSuppressions(t *testing.T) {
+	// Results of disabled rules are left out and counted
+	report := getSuppressionsReport(t, "privileged-true.yml")
+	assert.Equal(t, []string{privileged.PrivilegedTrue}, getRules(report))
+	assert.Equal(t, map[kubeaudit.SuppressionMechanism]int{kubeaudit.SuppressedByConfig: 1}, report.Suppressions())
+
+	// Overridden results are still reported, and counted
+	report = getSuppressionsReport(t, "privileged-true-allowed.yml")
+	assert.Equal(t, []string{privileged.PrivilegedTrue + "Allowed"}, getRules(report))
+	assert.Equal(t, map[kubeaudit.SuppressionMechanism]int{
+		kubeaudit.SuppressedByOverride: 1,
+		kubeaudit.SuppressedByConfig:   1,
+	}, report.Suppressions())
+}
+
+func TestSuppress(t *testing.T) {
+	report := getSuppressionsReport(t, "privileged-true.yml")
+	suppressed := report.Suppress(kubeaudit.SuppressedByBaseline, func(_ kubeaudit.Result, auditResult *kubeaudit.AuditResult) bool {
+		return auditResult.Rule == privileged.PrivilegedTrue
+	})
+
+	assert.Empty(t, getRules(suppressed))
+	assert.Equal(t, map[kubeaudit.SuppressionMechanism]int{
+		kubeaudit.SuppressedByConfig:   1,
+		kubeaudit.SuppressedByBaseline: 1,
+	}, suppressed.Suppressions())
+
+	// The original report is unchanged
+	assert.Equal(t, []string{privileged.PrivilegedTrue}, getRules(report))
+	assert.Equal(t, map[kubeaudit.SuppressionMechanism]int{kubeaudit.SuppressedByConfig: 1}, report.Suppressions())
+
+	// The suppressions are kept by the reports derived from the report
+	assert.Equal(t, suppressed.Suppressions(), suppressed.RedactNames().Suppressions())
+	assert.Equal(t, suppressed.Suppressions(), suppressed.SelectFixes(nil, nil).Suppressions())
+}
+
+func TestPrintSuppressions(t *testing.T) {
+	report := getSuppressionsReport(t, "privileged-true-allowed.yml")
+
+	out := bytes.Buffer{}
+	report.PrintResults(kubeaudit.WithWriter(&out), kubeaudit.WithColor(false), kubeaudit.WithMinSeverity(kubeaudit.Error))
+	assert.Contains(t, out.String(), "All checks completed")
+	assert.Contains(t, out.String(), "---------------- Suppressed findings ---------------\n\n-- override: 1\n-- config: 1\n")
+
+	// Reports without suppressed findings have no suppressions footer
+	out.Reset()
+	kubeaudit.NewReport(nil).PrintResults(kubeaudit.WithWriter(&out), kubeaudit.WithColor(false))
+	assert.NotContains(t, out.String(), "Suppressed findings")
+	assert.NotContains(t, out.String(), "Overridden findings")
+}
+
+func TestOverrides(t *testing.T) {
+	report := getSuppressionsReport(t, "privileged-true.yml")
+	assert.Empty(t, report.Overrides())
+
+	report = getSuppressionsReport(t, "privileged-true-allowed-container-scoped.yml")
+	overrides := report.Overrides()
+	require.Len(t, overrides, 1)
+	assert.Equal(t, privileged.PrivilegedTrue+"Allowed", overrides[0].Rule)
+	assert.Equal(t, "container2", overrides[0].Container)
+	assert.Equal(t, "kubeaudit.io/allow-privileged.container2", overrides[0].AuditResult.OverrideLabel)
+	assert.Equal(t, "SomeReason", overrides[0].Metadata["OverrideReason"])
+
+	out := bytes.Buffer{}
+	report.PrintResults(kubeaudit.WithWriter(&out), kubeaudit.WithColor(false))
+	assert.Contains(t, out.String(), "---------------- Overridden findings ---------------\n\n"+
+		"-- DaemonSet/privileged-true-allowed-container-scoped/daemonset (container2): PrivilegedTrueAllowed\n"+
+		"   Label: kubeaudit.io/allow-privileged.container2\n"+
+		"   Reason: SomeReason\n")
+
+	out.Reset()
+	report.PrintResults(kubeaudit.WithWriter(&out), kubeaudit.WithFormatter(&log.JSONFormatter{}))
+	assert.Contains(t, out.String(), `"OverrideLabel":"kubeaudit.io/allow-privileged.container2","OverrideReason":"SomeReason",`+
+		`"ResourceKind":"DaemonSet","ResourceName":"daemonset","ResourceNamespace":"privileged-true-allowed-container-scoped","level":"info","msg":"Finding overridden"`)
+}
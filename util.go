@@ -3,10 +3,22 @@ package kubeaudit
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/Shopify/kubeaudit/internal/k8sinternal"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 func getResourcesFromClient(client k8sinternal.KubeClient, options k8sinternal.ClientOptions) ([]KubeResource, error) {
@@ -20,66 +32,635 @@ func getResourcesFromClient(client k8sinternal.KubeClient, options k8sinternal.C
 		resources = append(resources, &kubeResource{object: resource})
 	}
 
+	return orderByNamespace(resources, options.PriorityNamespaces), nil
+}
+
+// orderByNamespace orders cluster resources so that they are audited and reported fairly across namespaces. The
+// resources of the priority namespaces come first, in the order the namespaces are listed, and the resources of the
+// other namespaces are interleaved so no namespace dominates the start of the report. Resources of the same namespace
+// keep their order, and cluster-scoped resources are interleaved as if they were a namespace
+func orderByNamespace(resources []KubeResource, priorityNamespaces []string) []KubeResource {
+	byNamespace := map[string][]KubeResource{}
+	var namespaces []string
+	for _, resource := range resources {
+		namespace := k8sinternal.ResourceNamespace(resource.Object())
+		if _, ok := byNamespace[namespace]; !ok {
+			namespaces = append(namespaces, namespace)
+		}
+		byNamespace[namespace] = append(byNamespace[namespace], resource)
+	}
+
+	ordered := make([]KubeResource, 0, len(resources))
+	for _, namespace := range priorityNamespaces {
+		ordered = append(ordered, byNamespace[namespace]...)
+		delete(byNamespace, namespace)
+	}
+
+	for i := 0; len(ordered) < len(resources); i++ {
+		for _, namespace := range namespaces {
+			if namespaceResources, ok := byNamespace[namespace]; ok && i < len(namespaceResources) {
+				ordered = append(ordered, namespaceResources[i])
+			}
+		}
+	}
+
+	return ordered
+}
+
+// getResourcesFromManifestFiles reads the resources of the manifest files, keeping the file each resource was read from
+func getResourcesFromManifestFiles(manifestPaths []string) ([]KubeResource, error) {
+	var resources []KubeResource
+	for _, manifestPath := range manifestPaths {
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			return nil, err
+		}
+		fileResources, err := getManifestFileResources(manifestPath, data)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, fileResources...)
+	}
 	return resources, nil
 }
 
+// getManifestFileResources returns the resources of a manifest file with the path of the file
+func getManifestFileResources(manifestPath string, data []byte) ([]KubeResource, error) {
+	resources, err := getResourcesFromManifest(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources from manifest %s: %w", manifestPath, err)
+	}
+	for _, resource := range resources {
+		resource.(*kubeResource).filePath = manifestPath
+	}
+	return resources, nil
+}
+
+// groupByManifestFile groups the results by the manifest file of their resource, in the order the files were read
+func groupByManifestFile(results []Result) ([]string, map[string][]Result) {
+	var paths []string
+	byPath := map[string][]Result{}
+	for _, result := range results {
+		path := manifestFilePath(result.GetResource())
+		if _, ok := byPath[path]; !ok {
+			paths = append(paths, path)
+		}
+		byPath[path] = append(byPath[path], result)
+	}
+	return paths, byPath
+}
+
 func getResourcesFromManifest(data []byte) ([]KubeResource, error) {
 	var resources []KubeResource
 	bufSlice := bytes.Split(data, []byte("---"))
 
+	// Documents which are not valid YAML or not valid resources are kept with their schema errors, so that every
+	// problem of the manifest is reported rather than only the first one
 	for _, b := range bufSlice {
+		schemaErrors := k8sinternal.ValidateResource(b)
 		obj, err := k8sinternal.DecodeResource(b)
 		if err == nil && obj != nil {
 			source := &kubeResource{
-				object: obj,
-				bytes:  b,
+				object:       obj,
+				bytes:        b,
+				schemaErrors: schemaErrors,
 			}
 			resources = append(resources, source)
-		} else if err := yaml.Unmarshal(data, &yaml.Node{}); err != nil {
-			return nil, fmt.Errorf("Invalid yaml: %w", err)
 		} else {
-			resources = append(resources, &kubeResource{bytes: b})
+			resources = append(resources, &kubeResource{bytes: b, schemaErrors: schemaErrors})
+		}
+	}
+
+	return resources, nil
+}
+
+// getResourcesFromKustomization renders the kustomization in the given directory and returns the resulting resources
+// along with the path of the file each resource originated from. Origin paths are only known if the kustomization
+// enables the "originAnnotations" build metadata option, otherwise the kustomization directory is used
+func getResourcesFromKustomization(kustomizationDir string) ([]KubeResource, []string, error) {
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := kustomizer.Run(filesys.MakeFsOnDisk(), kustomizationDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var resources []KubeResource
+	var paths []string
+	for _, res := range resMap.Resources() {
+		b, err := res.AsYAML()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		path := kustomizationDir
+		if origin, err := res.GetOrigin(); err == nil && origin != nil && origin.Path != "" && origin.Repo == "" {
+			path = filepath.Join(kustomizationDir, origin.Path)
+		}
+
+		obj, err := k8sinternal.DecodeResource(b)
+		if err != nil {
+			obj = nil
+		}
+		resources = append(resources, &kubeResource{object: obj, bytes: b})
+		paths = append(paths, path)
+	}
+
+	return resources, paths, nil
+}
+
+// helmResource is a resource rendered from a Helm chart along with the template it was rendered from
+type helmResource struct {
+	resource KubeResource
+	// path is the path of the template file the resource was rendered from
+	path string
+	// line is the line where the resource starts in the rendered template
+	line int
+	// lines maps the lines of the resource to the lines of the template source, or is nil if the template source could
+	// not be read
+	lines []int
+}
+
+// getResourcesFromHelmChart renders the Helm chart at the given path in the given release namespace, the same way
+// `helm template` does, and returns the rendered resources including hooks
+func getResourcesFromHelmChart(chartPath string, valueFiles []string, namespace string) ([]helmResource, error) {
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, err
+	}
+
+	valueOpts := &values.Options{ValueFiles: valueFiles}
+	vals, err := valueOpts.MergeValues(getter.Providers{})
+	if err != nil {
+		return nil, err
+	}
+
+	install := action.NewInstall(&action.Configuration{Log: func(string, ...interface{}) {}})
+	install.DryRun = true
+	install.ClientOnly = true
+	install.Replace = true
+	install.IncludeCRDs = true
+	install.ReleaseName = chart.Name()
+	install.Namespace = namespace
+
+	release, err := install.Run(chart, vals)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests := []string{release.Manifest}
+	for _, hook := range release.Hooks {
+		manifests = append(manifests, fmt.Sprintf("# Source: %s\n%s", hook.Path, hook.Manifest))
+	}
+
+	var resources []helmResource
+	nextLine := map[string]int{}
+	templates := newHelmTemplates()
+	for _, manifest := range manifests {
+		manifest = strings.TrimPrefix(manifest, "---")
+		for _, doc := range bytes.Split([]byte(manifest), []byte("\n---")) {
+			source, body := splitHelmSource(doc)
+			if len(bytes.TrimSpace(body)) == 0 {
+				continue
+			}
+
+			// The source path starts with the chart name rather than the chart directory
+			path := chartPath
+			if parts := strings.SplitN(source, "/", 2); len(parts) == 2 {
+				path = filepath.Join(chartPath, parts[1])
+			}
+
+			line, ok := nextLine[path]
+			if !ok {
+				line = 1
+			}
+			nextLine[path] = line + bytes.Count(bytes.TrimSpace(body), []byte("\n")) + 2
+
+			// Restore the newline removed by splitting so the fixed documents are joined correctly
+			if !bytes.HasSuffix(doc, []byte("\n")) {
+				doc = append(doc, '\n')
+			}
+
+			obj, err := k8sinternal.DecodeResource(body)
+			if err != nil {
+				obj = nil
+			}
+			resources = append(resources, helmResource{
+				resource: &kubeResource{object: obj, bytes: doc},
+				path:     path,
+				line:     line,
+				lines:    templates.lines(path, body),
+			})
 		}
 	}
 
 	return resources, nil
 }
 
-func auditResources(resources []KubeResource, auditable []Auditable) ([]Result, error) {
-	var results []Result
+// splitHelmSource splits a rendered Helm document into the template path from its "# Source:" comment and the rest
+// of the document
+func splitHelmSource(doc []byte) (string, []byte) {
+	const sourcePrefix = "# Source: "
+
+	trimmed := bytes.TrimLeft(doc, "\n")
+	if !bytes.HasPrefix(trimmed, []byte(sourcePrefix)) {
+		return "", doc
+	}
+
+	lineEnd := bytes.IndexByte(trimmed, '\n')
+	if lineEnd < 0 {
+		return strings.TrimPrefix(string(trimmed), sourcePrefix), nil
+	}
+	return strings.TrimPrefix(string(trimmed[:lineEnd]), sourcePrefix), trimmed[lineEnd+1:]
+}
+
+// helmTemplates maps the resources rendered from Helm templates to the lines of the template sources
+type helmTemplates struct {
+	// documents are the documents of each template source, or nil if the template source could not be read
+	documents map[string][]templateDocument
+	// rendered is the number of resources rendered so far per template and kind
+	rendered map[[2]string]int
+}
+
+// templateDocument is a YAML document of a template source
+type templateDocument struct {
+	// start is the line of the template where the document starts
+	start int
+	lines []string
+}
 
+func newHelmTemplates() *helmTemplates {
+	return &helmTemplates{documents: map[string][]templateDocument{}, rendered: map[[2]string]int{}}
+}
+
+// lines maps each line of a resource rendered from the template at the given path, starting at the line where the
+// resource starts, to the line of the template source it was rendered from. The resource is rendered from the
+// document of the template with the same kind, in the order the resources of this kind are rendered, or from the last
+// such document if there are more resources than documents, such as resources rendered in a range. Each rendered line
+// is matched to the next line of the document with the same text before its first action, so lines rendered by
+// actions only, such as includes, are mapped to the closest line before them. Returns nil if the template source
+// can't be read or has no document of the kind of the resource
+func (t *helmTemplates) lines(path string, body []byte) []int {
+	documents, ok := t.documents[path]
+	if !ok {
+		if source, err := os.ReadFile(path); err == nil {
+			documents = splitTemplateDocuments(source)
+		}
+		t.documents[path] = documents
+	}
+
+	rendered := strings.Split(string(body), "\n")[startLineOffset(body):]
+	kind := documentKind(rendered)
+	var candidates []templateDocument
+	for _, document := range documents {
+		if documentKind(document.lines) == kind {
+			candidates = append(candidates, document)
+		}
+	}
+	if kind == "" || len(candidates) == 0 {
+		return nil
+	}
+
+	key := [2]string{path, kind}
+	index := t.rendered[key]
+	t.rendered[key]++
+	if index >= len(candidates) {
+		index = len(candidates) - 1
+	}
+	document := candidates[index]
+
+	lines := make([]int, len(rendered))
+	next := 0
+	last := document.start
+	for i, renderedLine := range rendered {
+		for j := next; j < len(document.lines); j++ {
+			literal := document.lines[j]
+			if action := strings.Index(literal, "{{"); action >= 0 {
+				literal = literal[:action]
+			}
+			literal = strings.TrimRight(literal, " \t")
+			if strings.TrimSpace(literal) != "" && strings.HasPrefix(renderedLine, literal) {
+				last = document.start + j
+				next = j + 1
+				break
+			}
+		}
+		lines[i] = last
+	}
+	return lines
+}
+
+// splitTemplateDocuments splits the source of a Helm template into its YAML documents
+func splitTemplateDocuments(source []byte) []templateDocument {
+	var documents []templateDocument
+	document := templateDocument{start: 1}
+	for i, line := range strings.Split(string(source), "\n") {
+		if strings.HasPrefix(line, "---") {
+			documents = append(documents, document)
+			document = templateDocument{start: i + 2}
+			continue
+		}
+		document.lines = append(document.lines, line)
+	}
+	return append(documents, document)
+}
+
+// documentKind returns the kind of the top-level "kind" field of the lines of a document, or an empty string if the
+// document has no kind
+func documentKind(lines []string) string {
+	for _, line := range lines {
+		if kind := strings.TrimPrefix(line, "kind:"); kind != line {
+			return strings.Trim(strings.TrimSpace(kind), `"'`)
+		}
+	}
+	return ""
+}
+
+// kustomizeOriginAnnotation is the annotation `kustomize build` adds to each resource with the file it originated from
+// when the kustomization enables the "originAnnotations" build metadata option
+const kustomizeOriginAnnotation = "config.kubernetes.io/origin"
+
+// sourceLocation is the file and line a resource of a manifest was generated from
+type sourceLocation struct {
+	path string
+	line int
+	// lines maps the lines of the resource to the lines of its Helm template source, if the template source could be
+	// read
+	lines []int
+}
+
+// getManifestSources returns the source location of each resource of a manifest concatenated from the output of
+// other tools, such as `helm template` and `kustomize build` piped to stdin. Documents rendered by Helm start with a
+// "# Source:" comment with the template path. Their lines are mapped to the template source if it can be read from
+// the template path, relative to the working directory, and otherwise their line is the line where the resource
+// starts in the rendered template. Resources built by Kustomize with origin annotations are attributed to the file the annotation names,
+// without a line. Other resources are attributed to the manifest file they were read from, at the line where they
+// start in the file
+func getManifestSources(resources []KubeResource) []sourceLocation {
+	locations := make([]sourceLocation, 0, len(resources))
+	// Templates are counted per manifest file, as several files may be rendered from the same chart
+	nextLine := map[[2]string]int{}
+	// The number of lines of each manifest file before the current resource
+	fileLines := map[string]int{}
+	templates := newHelmTemplates()
 	for _, resource := range resources {
-		result, err := auditResource(resource, resources, auditable)
+		manifestPath := manifestFilePath(resource)
+		startLine := fileLines[manifestPath] + startLineOffset(resource.Bytes()) + 1
+		fileLines[manifestPath] += bytes.Count(resource.Bytes(), []byte("\n"))
+
+		if source, body := splitHelmSource(resource.Bytes()); source != "" {
+			template := [2]string{manifestPath, source}
+			line, ok := nextLine[template]
+			if !ok {
+				line = 1
+			}
+			nextLine[template] = line + bytes.Count(bytes.TrimSpace(body), []byte("\n")) + 2
+			locations = append(locations, sourceLocation{path: source, line: line, lines: templates.lines(source, body)})
+			continue
+		}
+
+		if origin := getKustomizeOrigin(resource.Object()); origin != "" {
+			locations = append(locations, sourceLocation{path: origin})
+			continue
+		}
+
+		locations = append(locations, sourceLocation{path: manifestPath, line: startLine})
+	}
+	return locations
+}
+
+// getKustomizeOrigin returns the local file path of the kustomize origin annotation of the resource, or an empty string
+// if the resource has no origin annotation or originated from a remote repository
+func getKustomizeOrigin(resource k8s.Resource) string {
+	if resource == nil {
+		return ""
+	}
+	objectMeta := k8s.GetObjectMeta(resource)
+	if objectMeta == nil {
+		return ""
+	}
+	annotation, ok := objectMeta.GetAnnotations()[kustomizeOriginAnnotation]
+	if !ok {
+		return ""
+	}
+
+	var origin struct {
+		Path string `yaml:"path"`
+		Repo string `yaml:"repo"`
+	}
+	if err := yaml.Unmarshal([]byte(annotation), &origin); err != nil || origin.Repo != "" {
+		return ""
+	}
+	return origin.Path
+}
+
+// cleanFilePath makes relative manifest paths consistent so they can be used as SARIF artifact locations
+func cleanFilePath(path string) string {
+	if !filepath.IsAbs(path) {
+		return strings.TrimPrefix(filepath.Clean("/"+path), "/")
+	}
+	return path
+}
+
+// auditResources audits the resources with at most concurrency resources audited at the same time. Results are
+// collected by index so they are in the order of the resources regardless of which one finishes first. The auditors
+// are run concurrently on different resources if concurrency is greater than 1, so they must not keep state between
+// audits
+func auditResources(resources []KubeResource, auditable []Auditable, concurrency int, profile *Profile, strict bool) ([]Result, error) {
+	return auditResourcesInContext(resources, resources, auditable, concurrency, profile, strict)
+}
+
+// auditResourcesInContext audits the resources with the context resources as the resources the auditors see, such as
+// the namespaces and network policies of the resources. The context must include the resources. The errors of the
+// auditors and the schema errors of manifest documents are reported as audit results, unless the audit is strict in
+// which case the audit fails on the first error
+func auditResourcesInContext(resources, context []KubeResource, auditable []Auditable, concurrency int, profile *Profile, strict bool) ([]Result, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	start := time.Now()
+
+	unwrappedResources := unwrapResources(context)
+	results := make([]Result, len(resources))
+	errs := make([]error, len(resources))
+
+	// No more resources are audited once one fails, as the audit fails anyway
+	var failed int32
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < concurrency && worker < len(resources); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i], errs[i] = auditUnwrappedResource(resources[i], unwrappedResources, auditable, profile, strict)
+				if errs[i] != nil {
+					atomic.StoreInt32(&failed, 1)
+				}
+			}
+		}()
+	}
+	for i := range resources {
+		if atomic.LoadInt32(&failed) == 1 {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
 		if err != nil {
 			return nil, err
 		}
-		results = append(results, result)
 	}
 
+	inheritOverrides(results)
+	profile.recordAudit(time.Since(start), len(resources))
+
 	return results, nil
 }
 
-func auditResource(resource KubeResource, resources []KubeResource, auditables []Auditable) (Result, error) {
+func auditResource(resource KubeResource, resources []KubeResource, auditables []Auditable, profile *Profile, strict bool) (Result, error) {
+	return auditUnwrappedResource(resource, unwrapResources(resources), auditables, profile, strict)
+}
+
+func auditUnwrappedResource(resource KubeResource, unwrappedResources []k8s.Resource, auditables []Auditable, profile *Profile, strict bool) (Result, error) {
 	result := &WorkloadResult{
 		Resource:     resource,
-		AuditResults: []*AuditResult{},
+		AuditResults: newSchemaErrorResults(resource),
+	}
+	if strict && len(result.AuditResults) > 0 {
+		return nil, fmt.Errorf("invalid manifest document: %s", result.AuditResults[0].Message)
 	}
 
 	if resource.Object() == nil {
 		return result, nil
 	}
 
-	for _, auditable := range auditables {
-		auditResults, err := auditable.Audit(resource.Object(), unwrapResources(resources))
-		if err != nil {
-			return nil, err
+	// Each auditor runs in its own goroutine. Results are collected by index so the output order matches the
+	// order of the auditors regardless of which one finishes first
+	auditResults := make([][]*AuditResult, len(auditables))
+	errs := make([]error, len(auditables))
+
+	// The auditors are only timed when profiling, so audits don't pay for it otherwise
+	var auditorNames []string
+	var durations []time.Duration
+	if profile != nil {
+		auditorNames = make([]string, len(auditables))
+		durations = make([]time.Duration, len(auditables))
+		for i, auditable := range auditables {
+			auditorNames[i] = auditorName(auditable)
+		}
+	}
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i, auditable := range auditables {
+		wg.Add(1)
+		go func(i int, auditable Auditable) {
+			defer wg.Done()
+			if profile != nil {
+				auditResults[i], durations[i], errs[i] = profile.runAuditor(auditorNames[i], auditable, resource.Object(), unwrappedResources)
+				return
+			}
+			auditResults[i], errs[i] = runAuditor(auditable, resource.Object(), unwrappedResources)
+		}(i, auditable)
+	}
+	wg.Wait()
+	profile.recordResource(resource.Object(), time.Since(start), auditorNames, durations)
+
+	for i, auditable := range auditables {
+		if errs[i] != nil {
+			if strict {
+				return nil, errs[i]
+			}
+			result.AuditResults = append(result.AuditResults, newAuditorErrorResult(auditable, errs[i]))
+			continue
 		}
-		result.AuditResults = append(result.AuditResults, auditResults...)
+		result.AuditResults = append(result.AuditResults, auditResults[i]...)
 	}
+	result.AuditResults = appendExpiredOverrideResults(result.AuditResults)
+	setContainerMetadata(resource.Object(), result.AuditResults)
+	setCronJobMetadata(resource.Object(), result.AuditResults)
 
 	return result, nil
 }
 
+// runAuditor runs a single auditor against a resource. If the auditor panics, the panic is recovered and reported
+// as an audit result so that one misbehaving auditor does not crash the whole run
+func runAuditor(auditable Auditable, resource k8s.Resource, resources []k8s.Resource) (auditResults []*AuditResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			auditResults = []*AuditResult{newAuditorPanicResult(auditable, r)}
+			err = nil
+		}
+	}()
+
+	return auditable.Audit(resource, resources)
+}
+
+func newAuditorPanicResult(auditable Auditable, recovered interface{}) *AuditResult {
+	name := auditorName(auditable)
+	return &AuditResult{
+		Auditor:  name,
+		Rule:     AuditorPanic,
+		Severity: Error,
+		Message:  fmt.Sprintf("Auditor %s panicked while auditing the resource. The resource was not fully audited.", name),
+		Metadata: Metadata{
+			"Panic": fmt.Sprint(recovered),
+		},
+	}
+}
+
+// newAuditorErrorResult returns the result reporting the error of an auditor which failed to audit a resource, so the
+// other auditors and resources are still audited
+func newAuditorErrorResult(auditable Auditable, err error) *AuditResult {
+	name := auditorName(auditable)
+	return &AuditResult{
+		Auditor:  name,
+		Rule:     AuditorError,
+		Severity: Error,
+		Message:  fmt.Sprintf("Auditor %s failed to audit the resource. The resource was not fully audited.", name),
+		Metadata: Metadata{
+			"Error": err.Error(),
+		},
+	}
+}
+
+// newSchemaErrorResults returns an audit result for each schema error of the document of a resource read from a
+// manifest. The results are positioned within the document, and setLocation positions them within the manifest
+func newSchemaErrorResults(resource KubeResource) []*AuditResult {
+	auditResults := []*AuditResult{}
+	kubeResource, ok := resource.(*kubeResource)
+	if !ok {
+		return auditResults
+	}
+
+	for _, schemaErr := range kubeResource.schemaErrors {
+		auditResult := &AuditResult{
+			Auditor:  ManifestAuditor,
+			Severity: Error,
+			Line:     schemaErr.Line,
+			Column:   schemaErr.Column,
+		}
+		switch schemaErr.Reason {
+		case k8sinternal.SyntaxError:
+			auditResult.Rule = ManifestSyntaxError
+			auditResult.Message = fmt.Sprintf("The document is not valid YAML: %s. It should be fixed so the resource can be audited.", schemaErr.Message)
+		case k8sinternal.UnknownField:
+			auditResult.Rule = ManifestUnknownField
+			auditResult.Message = fmt.Sprintf("Field %s is unknown. It should be removed, or renamed or indented if it is misspelled or at the wrong level.", schemaErr.Field)
+			auditResult.Metadata = Metadata{"Field": schemaErr.Field}
+		case k8sinternal.InvalidType:
+			auditResult.Rule = ManifestInvalidType
+			auditResult.Message = fmt.Sprintf("Field %s %s.", schemaErr.Field, schemaErr.Message)
+			auditResult.Metadata = Metadata{"Field": schemaErr.Field}
+		}
+		auditResults = append(auditResults, auditResult)
+	}
+	return auditResults
+}
+
 func unwrapResources(resources []KubeResource) []k8s.Resource {
 	unwrappedResources := make([]k8s.Resource, 0, len(resources))
 	for _, resource := range resources {
@@ -3,11 +3,18 @@ package kubeaudit
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/Shopify/kubeaudit/internal/redact"
 	"github.com/Shopify/kubeaudit/pkg/k8s"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type logEntry struct {
@@ -18,6 +25,9 @@ type logEntry struct {
 	ResourceApiVersion string
 	ResourceName       string
 	ResourceNamespace  string
+	FilePath           string
+	Line               int
+	Column             int
 }
 
 func TestPrintResults(t *testing.T) {
@@ -72,6 +82,9 @@ func TestLogAuditResult(t *testing.T) {
 		resource.Namespace = "mynamespace"
 
 		auditResult := newTestAuditResult(severity)
+		auditResult.FilePath = "mychart/templates/deployment.yaml"
+		auditResult.Line = 12
+		auditResult.Column = 7
 		report := &Report{
 			results: []Result{
 				&WorkloadResult{
@@ -93,6 +106,9 @@ func TestLogAuditResult(t *testing.T) {
 			ResourceApiVersion: expectedApiVersion,
 			ResourceName:       resource.GetName(),
 			ResourceNamespace:  resource.GetNamespace(),
+			FilePath:           auditResult.FilePath,
+			Line:               auditResult.Line,
+			Column:             auditResult.Column,
 		}
 
 		// This writes the log to the variable out, parses the JSON into the logEntry struct, and checks the struct
@@ -105,3 +121,319 @@ func TestLogAuditResult(t *testing.T) {
 		out.Reset()
 	}
 }
+
+type panicAuditor struct{}
+
+func (a *panicAuditor) Audit(_ k8s.Resource, _ []k8s.Resource) ([]*AuditResult, error) {
+	panic("something went wrong")
+}
+
+type ruleAuditor struct {
+	rule string
+}
+
+func (a *ruleAuditor) Audit(_ k8s.Resource, _ []k8s.Resource) ([]*AuditResult, error) {
+	return []*AuditResult{{Auditor: a.rule, Rule: a.rule, Severity: Warn}}, nil
+}
+
+func TestAuditResourcePanicIsolation(t *testing.T) {
+	resource := &kubeResource{object: k8s.NewPod()}
+	auditables := []Auditable{
+		&ruleAuditor{rule: "First"},
+		&panicAuditor{},
+		&ruleAuditor{rule: "Last"},
+	}
+
+	result, err := auditResource(resource, []KubeResource{resource}, auditables, nil, false)
+	assert.NoError(t, err)
+
+	auditResults := result.GetAuditResults()
+	if assert.Len(t, auditResults, 3) {
+		assert.Equal(t, "First", auditResults[0].Rule)
+		assert.Equal(t, AuditorPanic, auditResults[1].Rule)
+		assert.Equal(t, "kubeaudit.panicAuditor", auditResults[1].Auditor)
+		assert.Equal(t, Error, auditResults[1].Severity)
+		assert.Equal(t, "something went wrong", auditResults[1].Metadata["Panic"])
+		assert.Equal(t, "Last", auditResults[2].Rule)
+	}
+}
+
+type nameAuditor struct{}
+
+func (a *nameAuditor) Audit(resource k8s.Resource, _ []k8s.Resource) ([]*AuditResult, error) {
+	name := k8s.GetObjectMeta(resource).GetName()
+	if name == "fail" {
+		return nil, errors.New("audit failed")
+	}
+	return []*AuditResult{{Auditor: "Name", Rule: name, Severity: Warn}}, nil
+}
+
+func TestAuditResourcesConcurrency(t *testing.T) {
+	var resources []KubeResource
+	for i := 0; i < 50; i++ {
+		pod := k8s.NewPod()
+		pod.Name = fmt.Sprintf("pod%d", i)
+		resources = append(resources, &kubeResource{object: pod})
+	}
+
+	for _, concurrency := range []int{0, 1, 4, 100} {
+		results, err := auditResources(resources, []Auditable{&nameAuditor{}}, concurrency, nil, false)
+		require.NoError(t, err)
+		require.Len(t, results, len(resources))
+		for i, result := range results {
+			assert.Equal(t, resources[i], result.GetResource())
+			assert.Equal(t, fmt.Sprintf("pod%d", i), result.GetAuditResults()[0].Rule)
+		}
+	}
+
+	pod := k8s.NewPod()
+	pod.Name = "fail"
+	resources = append(resources[:10:10], &kubeResource{object: pod})
+
+	// The error is reported and the other resources are still audited, unless the audit is strict
+	results, err := auditResources(resources, []Auditable{&nameAuditor{}}, 4, nil, false)
+	require.NoError(t, err)
+	require.Len(t, results, 11)
+	auditResult := results[10].GetAuditResults()[0]
+	assert.Equal(t, AuditorError, auditResult.Rule)
+	assert.Equal(t, "kubeaudit.nameAuditor", auditResult.Auditor)
+	assert.Equal(t, Error, auditResult.Severity)
+	assert.Equal(t, "audit failed", auditResult.Metadata["Error"])
+
+	_, err = auditResources(resources, []Auditable{&nameAuditor{}}, 4, nil, true)
+	assert.EqualError(t, err, "audit failed")
+}
+
+func TestPrintResultsSamplePerRule(t *testing.T) {
+	var results []Result
+	for i := 0; i < 5; i++ {
+		results = append(results, &WorkloadResult{
+			AuditResults: []*AuditResult{newTestAuditResult(Error)},
+			Resource:     &kubeResource{object: k8s.NewPod()},
+		})
+	}
+	report := NewReport(results)
+
+	out := bytes.NewBuffer(nil)
+	report.PrintResults(WithWriter(out), WithFormatter(&log.JSONFormatter{}), WithSamplePerRule(2))
+
+	// Two sampled results and one summary entry
+	assert.Equal(t, 3, bytes.Count(out.Bytes(), []byte{'\n'}))
+	assert.Contains(t, out.String(), `"Reported":2`)
+	assert.Contains(t, out.String(), `"Total":5`)
+	out.Reset()
+
+	report.PrintResults(WithWriter(out), WithColor(false), WithSamplePerRule(2))
+	assert.Equal(t, 2, bytes.Count(out.Bytes(), []byte("[error] MyAuditResult")))
+	assert.Contains(t, out.String(), "MyAuditResult: showing 2 of 5 results")
+	out.Reset()
+
+	// Without sampling all results are reported
+	report.PrintResults(WithWriter(out), WithFormatter(&log.JSONFormatter{}))
+	assert.Equal(t, 5, bytes.Count(out.Bytes(), []byte{'\n'}))
+
+	// The report is sampled the same way for the formats which are not printed
+	assert.Len(t, report.SamplePerRule(2).Results(), 2)
+	assert.Len(t, report.SamplePerRule(0).Results(), 5)
+	assert.Len(t, report.Results(), 5)
+}
+
+func TestPrintResultsRedactsSecrets(t *testing.T) {
+	report := NewReport([]Result{&WorkloadResult{
+		AuditResults: []*AuditResult{{
+			Rule:     "MyAuditResult",
+			Severity: Error,
+			Message:  "Container command includes --token=abc123",
+			Metadata: Metadata{"Kubeconfig": "client-key-data: abc123"},
+		}},
+		Resource: &kubeResource{object: k8s.NewPod()},
+	}})
+
+	out := bytes.NewBuffer(nil)
+	report.PrintResults(WithWriter(out), WithColor(false))
+	assert.NotContains(t, out.String(), "abc123")
+	assert.Contains(t, out.String(), "--token=[REDACTED]")
+	out.Reset()
+
+	report.PrintResults(WithWriter(out), WithFormatter(&log.JSONFormatter{}))
+	assert.NotContains(t, out.String(), "abc123")
+	assert.Contains(t, out.String(), "client-key-data: [REDACTED]")
+}
+
+func TestRedactNames(t *testing.T) {
+	pod := k8s.NewPod()
+	pod.Name = "payments"
+	pod.Namespace = "billing"
+
+	report := NewReport([]Result{&WorkloadResult{
+		AuditResults: []*AuditResult{{
+			Rule:     "MyAuditResult",
+			Severity: Error,
+			Message:  "Pod payments in namespace billing is not covered. See billing/payments.",
+			Metadata: Metadata{"Pod": "payments", "Container": "container"},
+		}},
+		Resource: &kubeResource{object: pod, bytes: []byte("name: payments")},
+	}})
+
+	redacted := report.RedactNames()
+	results := redacted.Results()
+	require.Len(t, results, 1)
+
+	objectMeta := k8s.GetObjectMeta(results[0].GetResource().Object())
+	assert.Equal(t, redact.Name("payments"), objectMeta.GetName())
+	assert.Equal(t, redact.Name("billing"), objectMeta.GetNamespace())
+	assert.Nil(t, results[0].GetResource().Bytes())
+
+	// Messages are free text, so they are kept as they are
+	auditResult := results[0].GetAuditResults()[0]
+	assert.Equal(t, "Pod payments in namespace billing is not covered. See billing/payments.", auditResult.Message)
+	assert.Equal(t, Metadata{"Pod": redact.Name("payments"), "Container": "container"}, auditResult.Metadata)
+
+	// The original report is not modified
+	assert.Equal(t, "payments", pod.Name)
+	assert.Equal(t, "payments", report.Results()[0].GetAuditResults()[0].Metadata["Pod"])
+}
+
+func TestRedact(t *testing.T) {
+	image := "registry.internal.example.com/billing/payments-api:1.4.2"
+	pod := k8s.NewPod()
+	pod.Name = "payments"
+	pod.Namespace = "billing"
+	pod.Labels = map[string]string{"team": "payments-team", "canary": "true"}
+	pod.Spec.ServiceAccountName = "payments-sa"
+	pod.Spec.Containers = []apiv1.Container{{Name: "container", Image: image}}
+	pod.Status.ContainerStatuses = []apiv1.ContainerStatus{{Name: "container", Image: image, ImageID: "docker-pullable://registry.internal.example.com/billing/payments-api@sha256:0123"}}
+
+	report := NewReport([]Result{&WorkloadResult{
+		AuditResults: []*AuditResult{{
+			Rule:     "MyAuditResult",
+			Severity: Error,
+			Message:  "Image " + image + " of pod payments is owned by payments-team, which is not true.",
+			Metadata: Metadata{"Container": "container", ContainerImageMetadata: image, "Label": "team", "Value": "payments-team"},
+		}},
+		Resource: &kubeResource{object: pod},
+	}})
+
+	redactedImage := redact.Name("registry.internal.example.com/billing/payments-api") + ":1.4.2"
+	redacted := report.Redact(RedactHash)
+	results := redacted.Results()
+	require.Len(t, results, 1)
+
+	redactedPod := results[0].GetResource().Object().(*k8s.PodV1)
+	assert.Equal(t, redact.Name("payments"), redactedPod.Name)
+	assert.Equal(t, redact.Name("billing"), redactedPod.Namespace)
+	assert.Equal(t, map[string]string{"team": redact.Name("payments-team"), "canary": "true"}, redactedPod.Labels)
+	assert.Equal(t, redact.Name("payments-sa"), redactedPod.Spec.ServiceAccountName)
+	assert.Equal(t, redactedImage, redactedPod.Spec.Containers[0].Image)
+	assert.Equal(t, redactedImage, redactedPod.Status.ContainerStatuses[0].Image)
+	assert.Equal(t, "docker-pullable://"+redact.Name("registry.internal.example.com/billing/payments-api")+"@sha256:0123", redactedPod.Status.ContainerStatuses[0].ImageID)
+
+	auditResult := results[0].GetAuditResults()[0]
+	assert.Equal(t, "MyAuditResult", auditResult.Rule)
+	assert.Equal(t, Error, auditResult.Severity)
+	assert.Equal(t, "Image "+image+" of pod payments is owned by payments-team, which is not true.", auditResult.Message)
+	assert.Equal(t, Metadata{"Container": "container", ContainerImageMetadata: redactedImage, "Label": "team", "Value": redact.Name("payments-team")}, auditResult.Metadata)
+
+	// Masked names can't be compared
+	redacted = report.Redact(RedactMask)
+	redactedPod = redacted.Results()[0].GetResource().Object().(*k8s.PodV1)
+	assert.Equal(t, redact.Redacted, redactedPod.Name)
+	assert.Equal(t, redact.Redacted+":1.4.2", redactedPod.Spec.Containers[0].Image)
+	assert.Equal(t, redact.Redacted+":1.4.2", redacted.Results()[0].GetAuditResults()[0].Metadata[ContainerImageMetadata])
+
+	// The original report is not modified
+	assert.Equal(t, image, pod.Spec.Containers[0].Image)
+	assert.Equal(t, "payments-team", pod.Labels["team"])
+	assert.Equal(t, image, report.Results()[0].GetAuditResults()[0].Metadata[ContainerImageMetadata])
+}
+
+func TestPrintResultsHyperlinks(t *testing.T) {
+	deployment := k8s.NewDeployment()
+	deployment.Name = "deployment"
+	report := NewReport([]Result{&WorkloadResult{
+		AuditResults: []*AuditResult{
+			{Auditor: "apparmor", Rule: "AppArmorAnnotationMissing", Severity: Error},
+			newTestAuditResult(Error),
+		},
+		Resource: &kubeResource{object: deployment},
+	}})
+
+	out := bytes.NewBuffer(nil)
+	report.PrintResults(WithWriter(out), WithColor(false), WithHyperlinks(true))
+	assert.Contains(t, out.String(), "\033]8;;https://github.com/Shopify/kubeaudit/blob/main/docs/auditors/apparmor.md\033\\AppArmorAnnotationMissing\033]8;;\033\\")
+	assert.Contains(t, out.String(), "kind: \033]8;;https://kubernetes.io/docs/reference/generated/kubernetes-api/v1.24/#deployment-v1-apps\033\\Deployment\033]8;;\033\\")
+	// Results without an auditor are not linked
+	assert.Contains(t, out.String(), "[error] MyAuditResult\n")
+	out.Reset()
+
+	report.PrintResults(WithWriter(out), WithColor(false))
+	assert.NotContains(t, out.String(), "\033]8;;")
+}
+
+func TestKindReferenceURL(t *testing.T) {
+	cases := []struct {
+		apiVersion string
+		kind       string
+		expected   string
+	}{
+		{"v1", "Pod", kubernetesAPIReferenceURL + "#pod-v1-core"},
+		{"batch/v1", "CronJob", kubernetesAPIReferenceURL + "#cronjob-v1-batch"},
+		{"networking.k8s.io/v1", "NetworkPolicy", kubernetesAPIReferenceURL + "#networkpolicy-v1-networking.k8s.io"},
+		{"argoproj.io/v1alpha1", "Rollout", ""},
+		{"", "", ""},
+	}
+
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, kindReferenceURL(tc.apiVersion, tc.kind), tc.kind)
+	}
+}
+
+func TestOrderByNamespace(t *testing.T) {
+	newResource := func(kind, namespace, name string) KubeResource {
+		var resource k8s.Resource
+		switch kind {
+		case "Namespace":
+			namespace := k8s.NewNamespace()
+			namespace.Name = name
+			resource = namespace
+		case "ClusterRole":
+			clusterRole := &rbacv1.ClusterRole{TypeMeta: metav1.TypeMeta{Kind: "ClusterRole", APIVersion: "rbac.authorization.k8s.io/v1"}}
+			clusterRole.Name = name
+			resource = clusterRole
+		default:
+			pod := k8s.NewPod()
+			pod.Name = name
+			pod.Namespace = namespace
+			resource = pod
+		}
+		return &kubeResource{object: resource}
+	}
+
+	resources := []KubeResource{
+		newResource("Pod", "kube-system", "system-1"),
+		newResource("Pod", "kube-system", "system-2"),
+		newResource("Pod", "kube-system", "system-3"),
+		newResource("Pod", "payments", "payments-1"),
+		newResource("Pod", "payments", "payments-2"),
+		newResource("Pod", "default", "default-1"),
+		newResource("Namespace", "", "payments"),
+		newResource("ClusterRole", "", "cluster-role"),
+	}
+
+	names := func(resources []KubeResource) []string {
+		var names []string
+		for _, resource := range resources {
+			names = append(names, k8s.GetObjectMeta(resource.Object()).GetName())
+		}
+		return names
+	}
+
+	assert.Equal(t,
+		[]string{"system-1", "payments-1", "default-1", "cluster-role", "system-2", "payments-2", "system-3", "payments"},
+		names(orderByNamespace(resources, nil)),
+	)
+	assert.Equal(t,
+		[]string{"payments-1", "payments-2", "payments", "default-1", "system-1", "cluster-role", "system-2", "system-3"},
+		names(orderByNamespace(resources, []string{"payments", "missing", "default"})),
+	)
+}